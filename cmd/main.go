@@ -13,6 +13,10 @@ import (
 const appVersion = "1.0.0"
 
 func main() {
+	if code, ok := bootstrap.RunCommand(appVersion, os.Args[1:]); ok {
+		os.Exit(code)
+	}
+
 	app := bootstrap.NewApp(appVersion)
 
 	if err := app.Initialize(); err != nil {
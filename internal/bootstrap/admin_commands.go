@@ -0,0 +1,717 @@
+package bootstrap
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"pixelpunk/internal/controllers/user/dto"
+	ai "pixelpunk/internal/services/ai"
+	filesvc "pixelpunk/internal/services/file"
+	"pixelpunk/internal/services/setting"
+	"pixelpunk/internal/services/stats"
+	"pixelpunk/internal/services/storage"
+	"pixelpunk/internal/services/user"
+	vectorSvc "pixelpunk/internal/services/vector"
+	"pixelpunk/migrations"
+	"pixelpunk/pkg/cache"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/migrate"
+	"pixelpunk/pkg/vector"
+)
+
+// initCommandServices 在 initCommandEnv 基础上初始化缓存和设置服务，供需要调用业务服务的子命令使用
+func initCommandServices() error {
+	if err := initCommandEnv(); err != nil {
+		return err
+	}
+	cache.InitCache()
+	setting.InitSettingService()
+	return nil
+}
+
+// runSubcommands 分发 "pixelpunk <命令> <动作>" 形式的二级子命令
+func runSubcommands(name string, args []string, subs map[string]command) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "help" {
+		names := make([]string, 0, len(subs))
+		for sub := range subs {
+			names = append(names, sub)
+		}
+		sort.Strings(names)
+		fmt.Printf("用法: pixelpunk %s <动作> [参数]\n\n", name)
+		for _, sub := range names {
+			fmt.Printf("  %-14s %s\n", sub, subs[sub].summary)
+		}
+		if len(args) == 0 {
+			return fmt.Errorf("未指定动作")
+		}
+		return flag.ErrHelp
+	}
+	sub, ok := subs[args[0]]
+	if !ok {
+		return fmt.Errorf("未知动作 %q，使用 pixelpunk %s help 查看可用动作", args[0], name)
+	}
+	return sub.run(args[1:])
+}
+
+func runAdminCommand(args []string) error {
+	return runSubcommands("admin", args, map[string]command{
+		"create": {summary: "创建管理员账号", run: runAdminCreate},
+		"reset":  {summary: "重置管理员密码，恢复账号状态并吊销全部会话", run: runAdminReset},
+	})
+}
+
+func runAdminCreate(args []string) error {
+	fs := flag.NewFlagSet("admin create", flag.ContinueOnError)
+	username := fs.String("u", "", "用户名")
+	password := fs.String("p", "", "密码，至少 6 位")
+	email := fs.String("email", "", "邮箱，默认为 <用户名>@pixelpunk.local")
+	role := fs.String("role", "super", "角色：super 超级管理员，admin 管理员")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *username == "" || len(*password) < 6 {
+		return fmt.Errorf("需要指定用户名和至少 6 位的密码")
+	}
+	roles := map[string]int{"super": common.UserRoleSuperAdmin, "admin": common.UserRoleAdmin}
+	roleValue, ok := roles[*role]
+	if !ok {
+		return fmt.Errorf("不支持的角色 %q", *role)
+	}
+	if *email == "" {
+		*email = fmt.Sprintf("%s@pixelpunk.local", *username)
+	}
+	if err := initCommandServices(); err != nil {
+		return err
+	}
+
+	created, err := user.AdminCreateUser(&dto.AdminCreateUserDTO{
+		Username: *username,
+		Email:    *email,
+		Password: *password,
+		Role:     roleValue,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("已创建管理员 %s (ID %d, 邮箱 %s)\n", created.Username, created.ID, created.Email)
+	return nil
+}
+
+func runAdminReset(args []string) error {
+	fs := flag.NewFlagSet("admin reset", flag.ContinueOnError)
+	username := fs.String("u", "", "管理员用户名")
+	password := fs.String("p", "", "新密码，不指定时生成随机密码")
+	resetTwoFactor := fs.Bool("reset-2fa", false, "同时关闭两步验证")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *username == "" {
+		return fmt.Errorf("需要指定用户名")
+	}
+	if *password != "" && len(*password) < 6 {
+		return fmt.Errorf("密码至少 6 位")
+	}
+	if err := initCommandServices(); err != nil {
+		return err
+	}
+
+	admin, newPassword, err := user.RecoverAdminAccount(*username, *password, *resetTwoFactor)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("已重置管理员 %s (ID %d) 的密码，全部登录会话已失效\n", admin.Username, admin.ID)
+	if *password == "" {
+		fmt.Printf("新密码: %s\n", newPassword)
+	}
+	if *resetTwoFactor {
+		fmt.Println("两步验证已关闭")
+	}
+	return nil
+}
+
+func runMigrateCommand(args []string) error {
+	// 不带动作时与早期版本一致，执行全部待执行的迁移
+	if len(args) == 0 || (strings.HasPrefix(args[0], "-") && args[0] != "-h") {
+		return runMigrateUp(args)
+	}
+	return runSubcommands("migrate", args, map[string]command{
+		"up":     {summary: "同步表结构并执行待执行的迁移（默认动作）", run: runMigrateUp},
+		"down":   {summary: "回滚最近执行的迁移", run: runMigrateDown},
+		"status": {summary: "查看迁移状态", run: runMigrateStatus},
+		"repair": {summary: "把已执行迁移的校验和更新为当前程序中的内容", run: runMigrateRepair},
+	})
+}
+
+// initMigrateEnv 连接数据库但不自动同步表结构，同步和预览由迁移命令自己控制
+func initMigrateEnv() error {
+	database.DisableAutoMigrate()
+	if err := initCommandEnv(); err != nil {
+		return err
+	}
+	cache.InitCache()
+	return nil
+}
+
+func runMigrateUp(args []string) error {
+	fs := flag.NewFlagSet("migrate up", flag.ContinueOnError)
+	target := fs.Int64("to", 0, "执行到指定版本为止，默认执行全部")
+	dryRun := fs.Bool("dry-run", false, "只输出将要执行的 SQL，不修改数据库")
+	skipSchema := fs.Bool("skip-schema", false, "不按模型同步表结构，只执行版本迁移")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := initMigrateEnv(); err != nil {
+		return err
+	}
+
+	result, err := migrations.Registry().Up(database.GetDB(), migrate.Options{
+		Target: *target, DryRun: *dryRun, SyncSchema: !*skipSchema,
+	})
+	printMigrateResult(result, "执行")
+	if err != nil {
+		return err
+	}
+	if !*dryRun {
+		fmt.Println("数据库迁移完成")
+	}
+	return nil
+}
+
+func runMigrateDown(args []string) error {
+	fs := flag.NewFlagSet("migrate down", flag.ContinueOnError)
+	steps := fs.Int("steps", 1, "回滚的迁移个数")
+	target := fs.Int64("to", -1, "回滚到指定版本（保留该版本），0 表示回滚全部；指定后忽略 -steps")
+	dryRun := fs.Bool("dry-run", false, "只输出将要执行的 SQL，不修改数据库")
+	yes := fs.Bool("yes", false, "不再确认，直接回滚")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	opts := migrate.Options{Steps: *steps, DryRun: *dryRun}
+	if *target >= 0 {
+		opts.Steps, opts.Target = 0, *target
+	}
+	if err := initMigrateEnv(); err != nil {
+		return err
+	}
+	if !*dryRun && !*yes && !confirm("回滚会撤销迁移中的数据和表结构变更") {
+		return fmt.Errorf("已取消")
+	}
+
+	result, err := migrations.Registry().Down(database.GetDB(), opts)
+	printMigrateResult(result, "回滚")
+	return err
+}
+
+func runMigrateStatus(args []string) error {
+	fs := flag.NewFlagSet("migrate status", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "以 JSON 格式输出")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := initMigrateEnv(); err != nil {
+		return err
+	}
+
+	status, err := migrations.Registry().Status(database.GetDB(), true)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		data, err := json.MarshalIndent(status, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Printf("数据库: %s  当前版本: %d  最新版本: %d\n\n", status.Dialect, status.Current, status.Latest)
+	fmt.Printf("  %-6s %-32s %-8s %-8s %s\n", "版本", "名称", "状态", "可回滚", "执行时间")
+	for _, m := range status.Migrations {
+		appliedAt := "-"
+		if m.AppliedAt != nil {
+			appliedAt = m.AppliedAt.Local().Format("2006-01-02 15:04:05")
+		}
+		reversible := "否"
+		if m.Reversible {
+			reversible = "是"
+		}
+		fmt.Printf("  %-6d %-32s %-8s %-8s %s\n", m.Version, m.Name, migrateStateLabels[m.State], reversible, appliedAt)
+	}
+	fmt.Println()
+	fmt.Printf("待执行 %d 个，已修改 %d 个，程序中不存在 %d 个\n", status.Pending, status.Modified, status.Unknown)
+	if len(status.SchemaChanges) > 0 {
+		fmt.Printf("表结构与模型不一致，同步需要执行 %d 条语句，使用 pixelpunk migrate up -dry-run 查看\n", len(status.SchemaChanges))
+	}
+	return nil
+}
+
+func runMigrateRepair(args []string) error {
+	fs := flag.NewFlagSet("migrate repair", flag.ContinueOnError)
+	yes := fs.Bool("yes", false, "不再确认，直接更新")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := initMigrateEnv(); err != nil {
+		return err
+	}
+	if !*yes && !confirm("已修改的迁移不会重新执行，只更新记录中的校验和") {
+		return fmt.Errorf("已取消")
+	}
+	updated, err := migrations.Registry().Repair(database.GetDB())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("已更新 %d 条迁移记录\n", updated)
+	return nil
+}
+
+var migrateStateLabels = map[string]string{
+	migrate.StateApplied:  "已执行",
+	migrate.StatePending:  "待执行",
+	migrate.StateModified: "已修改",
+	migrate.StateUnknown:  "未知",
+}
+
+// printMigrateResult 输出执行结果，预览时输出将要执行的 SQL
+func printMigrateResult(result *migrate.Result, action string) {
+	if result == nil {
+		return
+	}
+	if result.DryRun {
+		if len(result.Schema) > 0 {
+			fmt.Println("-- 表结构同步")
+			printStatements(result.Schema)
+		}
+		for _, m := range result.Migrations {
+			fmt.Printf("-- %d_%s\n", m.Version, m.Name)
+			printStatements(m.SQL)
+		}
+		if len(result.Schema) == 0 && len(result.Migrations) == 0 {
+			fmt.Printf("-- 没有需要%s的迁移\n", action)
+		}
+		return
+	}
+	for _, m := range result.Migrations {
+		fmt.Printf("已%s %d_%s (%dms)\n", action, m.Version, m.Name, m.DurationMs)
+	}
+}
+
+func printStatements(statements []string) {
+	for _, stmt := range statements {
+		if strings.HasPrefix(stmt, "--") {
+			fmt.Println(stmt)
+		} else {
+			fmt.Println(strings.TrimRight(stmt, "; \n") + ";")
+		}
+	}
+}
+
+func runDBTransferCommand(args []string) error {
+	fs := flag.NewFlagSet("db-transfer", flag.ContinueOnError)
+	dbType := fs.String("type", database.TypePostgres, "目标数据库类型: postgres、mysql 或 sqlite")
+	host := fs.String("host", "localhost", "目标数据库地址")
+	port := fs.Int("port", 0, "目标数据库端口，默认按类型使用 5432 或 3306")
+	username := fs.String("user", "", "目标数据库用户名")
+	password := fs.String("password", "", "目标数据库密码，也可以通过环境变量 PIXELPUNK_TRANSFER_PASSWORD 指定")
+	name := fs.String("name", "", "目标数据库名")
+	path := fs.String("path", "", "目标 SQLite 数据库文件路径")
+	batch := fs.Int("batch", 500, "每批复制的行数")
+	yes := fs.Bool("yes", false, "不再确认，直接复制")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "用法: pixelpunk db-transfer [参数]")
+		fmt.Fprintln(fs.Output(), "把当前配置的数据库中的全部数据复制到一个新建的空数据库，完成后修改 configs/config.yaml 切换过去")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *password == "" {
+		*password = os.Getenv("PIXELPUNK_TRANSFER_PASSWORD")
+	}
+	if *batch <= 0 {
+		return fmt.Errorf("每批行数必须大于 0")
+	}
+	if *port == 0 && *dbType == database.TypeMySQL {
+		*port = 3306
+	}
+	if err := initCommandEnv(); err != nil {
+		return err
+	}
+
+	src := database.GetDB()
+	dst, err := database.OpenTarget(*dbType, *host, *port, *username, *password, *name, *path)
+	if err != nil {
+		return err
+	}
+	if sqlDB, err := dst.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+
+	if !*yes && !confirm(fmt.Sprintf("将把 %s 中的数据复制到目标 %s 数据库", src.Dialector.Name(), *dbType)) {
+		return fmt.Errorf("已取消")
+	}
+
+	fmt.Println("正在创建目标数据库表结构...")
+	if err := database.MigrateModels(dst); err != nil {
+		return err
+	}
+	if err := migrations.EnsureMigrationTable(dst); err != nil {
+		return fmt.Errorf("创建迁移记录表失败: %w", err)
+	}
+
+	// 迁移记录一起复制，目标库启动时不会重复执行已完成的数据迁移
+	tables := append(database.Models(), &migrations.MigrationRecord{})
+	err = database.TransferData(src, dst, tables, *batch, func(table string, rows, total int64) {
+		if total == 0 {
+			fmt.Printf("  %-32s 空表\n", table)
+			return
+		}
+		fmt.Printf("\r  %-32s %d/%d", table, rows, total)
+		if rows == total {
+			fmt.Println()
+		}
+	})
+	if err != nil {
+		fmt.Println()
+		return err
+	}
+
+	fmt.Println("数据复制完成，请停止服务后把 configs/config.yaml 中的 database 配置改为目标数据库，再启动服务")
+	return nil
+}
+
+func runStorageCommand(args []string) error {
+	return runSubcommands("storage", args, map[string]command{
+		"list": {summary: "列出存储渠道", run: runStorageList},
+		"test": {summary: "上传测试文件检查存储渠道连通性", run: runStorageTest},
+	})
+}
+
+func runStorageList(args []string) error {
+	fs := flag.NewFlagSet("storage list", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := initCommandServices(); err != nil {
+		return err
+	}
+
+	channels, err := storage.GetAllChannels()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%-36s  %-8s  %-6s  %-8s  %s\n", "ID", "类型", "状态", "文件数", "名称")
+	for _, ch := range channels {
+		status := "启用"
+		if ch.Status != 1 {
+			status = "停用"
+		}
+		name := ch.Name
+		if ch.IsDefault {
+			name += " (默认)"
+		}
+		fmt.Printf("%-36s  %-8s  %-6s  %-8d  %s\n", ch.ID, ch.Type, status, ch.FileCount, name)
+	}
+	return nil
+}
+
+func runStorageTest(args []string) error {
+	fs := flag.NewFlagSet("storage test", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "用法: pixelpunk storage test [渠道ID]...，不指定时测试全部启用的渠道")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := initCommandServices(); err != nil {
+		return err
+	}
+
+	ids := fs.Args()
+	if len(ids) == 0 {
+		channels, err := storage.GetAllChannels()
+		if err != nil {
+			return err
+		}
+		for _, ch := range channels {
+			if ch.Status == 1 {
+				ids = append(ids, ch.ID)
+			}
+		}
+	}
+
+	failed := 0
+	for _, id := range ids {
+		if err := storage.TestConnection(id); err != nil {
+			failed++
+			fmt.Printf("%s  失败: %v\n", id, err)
+			continue
+		}
+		fmt.Printf("%s  正常\n", id)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d 个渠道测试未通过", failed)
+	}
+	return nil
+}
+
+func runRequeueCommand(args []string) error {
+	fs := flag.NewFlagSet("requeue", flag.ContinueOnError)
+	onlyAI := fs.Bool("ai", false, "只重新入队 AI 打标任务")
+	onlyVector := fs.Bool("vector", false, "只重新入队向量任务")
+	limit := fs.Int("limit", 1000, "每批扫描的文件数")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	doAI, doVector := !*onlyVector || *onlyAI, !*onlyAI || *onlyVector
+	if err := initCommandServices(); err != nil {
+		return err
+	}
+
+	if doAI {
+		if err := ai.InitTaggingQueueClient(); err != nil {
+			return err
+		}
+		recovered, err := ai.RecoverPendingOnStartup(*limit)
+		if err != nil {
+			return fmt.Errorf("恢复AI打标任务失败: %w", err)
+		}
+		enqueued, err := ai.EnqueueAllPending(*limit)
+		if err != nil {
+			return fmt.Errorf("AI打标任务入队失败: %w", err)
+		}
+		fmt.Printf("AI打标: 恢复 %d 个积压的待处理任务，新入队 %d 个\n", recovered, enqueued)
+	}
+	if doVector && !*onlyVector && !setting.GetBoolDirectFromDB("vector", "vector_enabled", false) {
+		fmt.Println("向量: 功能未启用，跳过")
+		doVector = false
+	}
+	if doVector {
+		svc, err := initVectorClient(false)
+		if err != nil {
+			return err
+		}
+		enqueued, err := svc.EnqueueAllPending(*limit)
+		if err != nil {
+			return fmt.Errorf("向量任务入队失败: %w", err)
+		}
+		fmt.Printf("向量: 入队 %d 个\n", enqueued)
+	}
+	return nil
+}
+
+func runVectorCommand(args []string) error {
+	return runSubcommands("vector", args, map[string]command{
+		"reconcile":     {summary: "为有AI描述但缺少向量的文件补齐向量任务", run: runVectorReconcile},
+		"clean-orphans": {summary: "删除文件已不存在的向量", run: runVectorCleanOrphans},
+	})
+}
+
+func runVectorReconcile(args []string) error {
+	fs := flag.NewFlagSet("vector reconcile", flag.ContinueOnError)
+	limit := fs.Int("limit", 1000, "最多处理的文件数")
+	dryRun := fs.Bool("dry-run", false, "只统计，不入队")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := initCommandServices(); err != nil {
+		return err
+	}
+
+	svc, err := initVectorClient(false)
+	if err != nil {
+		return err
+	}
+	found, enqueued, err := svc.ReconcileMissing(*limit, *dryRun)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("缺失向量: %d 个，已入队 %d 个\n", found, enqueued)
+	return nil
+}
+
+func runVectorCleanOrphans(args []string) error {
+	fs := flag.NewFlagSet("vector clean-orphans", flag.ContinueOnError)
+	limit := fs.Int("limit", 1000, "最多检查的向量数")
+	dryRun := fs.Bool("dry-run", false, "只统计，不删除")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := initCommandServices(); err != nil {
+		return err
+	}
+
+	svc, err := initVectorClient(true)
+	if err != nil {
+		return err
+	}
+	found, removed, err := svc.CleanOrphans(*limit, *dryRun)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("孤儿向量: %d 个，已删除 %d 个\n", found, removed)
+	return nil
+}
+
+// initVectorClient 连接向量队列，needEngine 为 true 时还需要连接 Qdrant
+func initVectorClient(needEngine bool) (*vectorSvc.VectorQueueService, error) {
+	if !setting.GetBoolDirectFromDB("vector", "vector_enabled", false) {
+		return nil, fmt.Errorf("向量功能未启用")
+	}
+	if needEngine {
+		qdrantURL := setting.GetStringDirectFromDB("vector", "qdrant_url", "")
+		if qdrantURL == "" {
+			return nil, fmt.Errorf("未配置 qdrant_url")
+		}
+		qdrantTimeout := setting.GetIntDirectFromDB("vector", "qdrant_timeout", 30)
+		if err := vector.InitQdrantVectorEngine(qdrantURL, qdrantTimeout); err != nil {
+			return nil, fmt.Errorf("连接向量引擎失败: %w", err)
+		}
+	}
+	vectorSvc.InitVectorQueueClient()
+	return vectorSvc.GetGlobalVectorQueueService(), nil
+}
+
+func runRecalcStatsCommand(args []string) error {
+	fs := flag.NewFlagSet("recalc-stats", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := initCommandServices(); err != nil {
+		return err
+	}
+
+	if err := stats.NewGlobalStatsService(database.GetDB()).ReconcileAllStats(); err != nil {
+		return err
+	}
+	fmt.Println("全站统计已重新计算")
+	return nil
+}
+
+func runPurgeExpiredCommand(args []string) error {
+	fs := flag.NewFlagSet("purge-expired", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "只列出过期文件，不删除")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := initCommandServices(); err != nil {
+		return err
+	}
+
+	if *dryRun {
+		files, err := filesvc.GetExpiredFiles()
+		if err != nil {
+			return err
+		}
+		for _, f := range files {
+			fmt.Printf("%s  %s  过期于 %s\n", f.ID, f.OriginalName, f.ExpiresAt.Format("2006-01-02 15:04:05"))
+		}
+		fmt.Printf("过期文件: %d 个\n", len(files))
+		return nil
+	}
+
+	// 删除文件时一并删除其向量，连接失败只影响向量清理
+	if setting.GetBoolDirectFromDB("vector", "vector_enabled", false) {
+		if _, err := initVectorClient(true); err != nil {
+			fmt.Printf("注意: %v，已删除文件的向量需稍后用 vector clean-orphans 清理\n", err)
+		}
+	}
+	success, failed, err := filesvc.CleanupExpiredFiles()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("已删除过期文件 %d 个，失败 %d 个\n", success, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d 个文件删除失败，详见日志", failed)
+	}
+	return nil
+}
+
+func runSettingsCommand(args []string) error {
+	return runSubcommands("settings", args, map[string]command{
+		"export": {summary: "导出系统设置为 JSON 文件", run: runSettingsExport},
+		"import": {summary: "从导出的 JSON 文件导入系统设置", run: runSettingsImport},
+	})
+}
+
+func runSettingsExport(args []string) error {
+	fs := flag.NewFlagSet("settings export", flag.ContinueOnError)
+	output := fs.String("o", "", "输出文件路径，默认输出到标准输出")
+	groups := fs.String("group", "", "只导出指定分组，多个分组用逗号分隔")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := initCommandServices(); err != nil {
+		return err
+	}
+
+	var groupList []string
+	for _, g := range strings.Split(*groups, ",") {
+		if g = strings.TrimSpace(g); g != "" {
+			groupList = append(groupList, g)
+		}
+	}
+	export, err := setting.ExportSettings(groupList)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		fmt.Println(string(data))
+		return nil
+	}
+	if err := os.WriteFile(*output, data, 0600); err != nil {
+		return err
+	}
+	fmt.Printf("已导出 %d 项设置到 %s\n", len(export.Settings), *output)
+	return nil
+}
+
+func runSettingsImport(args []string) error {
+	fs := flag.NewFlagSet("settings import", flag.ContinueOnError)
+	yes := fs.Bool("yes", false, "不再确认，直接导入")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "用法: pixelpunk settings import [参数] <设置文件>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("需要指定一个设置文件")
+	}
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if err := initCommandServices(); err != nil {
+		return err
+	}
+	if !*yes && !confirm("文件中的设置将覆盖当前同名设置") {
+		return fmt.Errorf("已取消")
+	}
+
+	result, err := setting.ImportSettings(data)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("已导入 %d 项设置，失败 %d 项\n", len(result.Success), len(result.Failed))
+	for _, item := range result.Failed {
+		fmt.Printf("  %s: %s\n", item.Key, item.Message)
+	}
+	if len(result.Failed) > 0 {
+		return fmt.Errorf("部分设置导入失败")
+	}
+	return nil
+}
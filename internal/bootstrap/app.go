@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"time"
 
+	"pixelpunk/internal/cluster"
 	"pixelpunk/internal/controllers/websocket"
 	"pixelpunk/internal/cron"
 	middlewareInternal "pixelpunk/internal/middleware"
@@ -17,6 +18,8 @@ import (
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/email"
 	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/geoip"
+	"pixelpunk/pkg/health"
 	"pixelpunk/pkg/logger"
 	"pixelpunk/pkg/vector"
 
@@ -50,6 +53,9 @@ func (app *App) Initialize() error {
 
 	logger.InitWithConfig(&logger.Config{LogLevel: gormLogger.Info, Colorful: true})
 	config.InitConfig()
+	if err := cluster.SetRoles(config.GetConfig().App.Role); err != nil {
+		return fmt.Errorf("进程角色配置错误: %v", err)
+	}
 	database.InitDB()
 
 	installManager := common.GetInstallManager()
@@ -61,12 +67,22 @@ func (app *App) Initialize() error {
 	}
 
 	cache.InitCache()
-	RunMigrations()
-	storage.CheckAndInitDefaultChannel()
+	// 多进程部署时由 API 进程执行迁移，worker 和 cron 进程使用已迁移的表结构
+	if cluster.ServesAPI() {
+		RunMigrations()
+		storage.CheckAndInitDefaultChannel()
+	}
 	email.Init()
 	websocket.InitWebSocketManager()
 	InitAllServices(app.Version)
-	cron.InitCronManager()
+	if cluster.RunsCron() {
+		cron.InitCronManager()
+	}
+	if cluster.ServesAPI() {
+		cron.InitNodeTasks()
+	}
+	cluster.StartNode(app.Version)
+	cluster.RegisterHealthChecker()
 
 	if err := app.initializeHTTPServer(); err != nil {
 		return fmt.Errorf("HTTP服务器初始化失败: %v", err)
@@ -79,10 +95,23 @@ func (app *App) initializeHTTPServer() error {
 	gin.SetMode(config.GetConfig().App.Mode)
 	app.Engine = gin.New()
 	app.configureMiddleware()
+	if !cluster.ServesAPI() && !common.GetInstallManager().IsInstallMode() {
+		registerHealthRoutes(app.Engine)
+		return nil
+	}
 	routes.RegisterRoutes(app.Engine)
 	return nil
 }
 
+// registerHealthRoutes 不提供 API 的进程只开放健康检查接口，供容器编排探测
+func registerHealthRoutes(r *gin.Engine) {
+	r.GET("/health", health.SimpleHealthHandler)
+	version := r.Group("/api/v1")
+	version.GET("/health", health.SimpleHealthHandler)
+	version.GET("/health/basic", health.BasicHealthHandler)
+	version.GET("/health/complete", health.CompleteHealthHandler)
+}
+
 func (app *App) configureMiddleware() {
 	app.Engine.Use(middlewareInternal.CORSMiddleware())
 	app.Engine.Use(gin.Recovery())
@@ -114,6 +143,7 @@ func (app *App) Shutdown(ctx context.Context) error {
 
 	app.cancel()
 	cron.Stop()
+	cluster.StopNode()
 
 	if vectorEngine := vector.GetGlobalVectorEngine(); vectorEngine != nil {
 		if err := vectorEngine.Close(); err != nil {
@@ -121,6 +151,10 @@ func (app *App) Shutdown(ctx context.Context) error {
 		}
 	}
 
+	if err := geoip.Close(); err != nil {
+		logger.Error("关闭GeoIP数据库失败: %v", err)
+	}
+
 	if err := database.Close(); err != nil {
 		logger.Error("关闭数据库连接失败: %v", err)
 	}
@@ -0,0 +1,231 @@
+package bootstrap
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"pixelpunk/internal/services/backup"
+	"pixelpunk/pkg/cache"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/config"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/logger"
+	"pixelpunk/pkg/utils"
+
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// command 命令行子命令，只初始化配置和数据库，不启动 HTTP 服务和后台任务
+type command struct {
+	summary string
+	run     func(args []string) error
+}
+
+var commands = map[string]command{
+	"backup":        {summary: "备份数据库、配置、本地存储文件和向量索引", run: runBackupCommand},
+	"restore":       {summary: "从备份恢复（需先停止服务）", run: runRestoreCommand},
+	"admin":         {summary: "创建管理员或重置管理员密码", run: runAdminCommand},
+	"migrate":       {summary: "执行、回滚或查看数据库迁移", run: runMigrateCommand},
+	"db-transfer":   {summary: "把数据复制到另一个数据库（如从 SQLite/MySQL 迁移到 PostgreSQL）", run: runDBTransferCommand},
+	"storage":       {summary: "列出或测试存储渠道", run: runStorageCommand},
+	"requeue":       {summary: "将待处理的AI打标和向量任务重新入队", run: runRequeueCommand},
+	"vector":        {summary: "补齐缺失向量或清理孤儿向量", run: runVectorCommand},
+	"recalc-stats":  {summary: "重新计算全站统计数据", run: runRecalcStatsCommand},
+	"purge-expired": {summary: "删除已过期的文件", run: runPurgeExpiredCommand},
+	"settings":      {summary: "导出或导入系统设置", run: runSettingsCommand},
+}
+
+// RunCommand 执行命令行子命令，args[0] 不是子命令时返回 false，程序按原方式启动服务
+func RunCommand(version string, args []string) (int, bool) {
+	if len(args) == 0 {
+		return 0, false
+	}
+	if args[0] == "help" {
+		printUsage()
+		return 0, true
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return 0, false
+	}
+	backup.SetAppVersion(version)
+	if err := cmd.run(args[1:]); err != nil {
+		if err != flag.ErrHelp {
+			fmt.Fprintf(os.Stderr, "%s 失败: %v\n", args[0], err)
+		}
+		return 1, true
+	}
+	return 0, true
+}
+
+// initCommandEnv 初始化子命令运行所需的时区、日志、配置和数据库
+func initCommandEnv() error {
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		return fmt.Errorf("设置时区失败: %v", err)
+	}
+	time.Local = loc
+
+	logger.InitWithConfig(&logger.Config{LogLevel: gormLogger.Warn, Colorful: true})
+	config.InitConfig()
+	database.InitDB()
+	if common.GetInstallManager().IsInstallMode() || database.DB == nil {
+		return fmt.Errorf("系统尚未安装或数据库配置不完整，请检查 configs/config.yaml")
+	}
+	return nil
+}
+
+func printUsage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Println("用法: pixelpunk [子命令] [参数]，不带子命令时启动服务")
+	fmt.Println()
+	for _, name := range names {
+		fmt.Printf("  %-14s %s\n", name, commands[name].summary)
+	}
+	fmt.Println()
+	fmt.Println("使用 pixelpunk <子命令> -h 查看子命令的参数")
+}
+
+func runBackupCommand(args []string) error {
+	fs := flag.NewFlagSet("backup", flag.ContinueOnError)
+	output := fs.String("o", "", "备份文件路径（以 .tar.gz 结尾）或目录，默认为配置的备份目录")
+	incrementalFrom := fs.String("incremental-from", "", "基于指定的备份做增量备份，只包含之后有变化的存储文件")
+	skipVector := fs.Bool("skip-vector", false, "不备份向量索引")
+	skipFiles := fs.Bool("skip-files", false, "不备份本地存储文件")
+	prune := fs.Bool("prune", false, "完成后按配置的 keep 清理备份目录中旧的备份链")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := initCommandEnv(); err != nil {
+		return err
+	}
+
+	result, err := backup.Create(backup.Options{
+		Output:          *output,
+		IncrementalFrom: *incrementalFrom,
+		SkipVector:      *skipVector,
+		SkipFiles:       *skipFiles,
+	})
+	if err != nil {
+		return err
+	}
+	m := result.Manifest
+	kind := "全量"
+	if m.IsIncremental() {
+		kind = fmt.Sprintf("增量（基于 %s）", m.BaseID)
+	}
+	fmt.Printf("备份文件: %s (%s)\n", result.Path, utils.FormatBytes(result.Size))
+	fmt.Printf("备份类型: %s\n", kind)
+	fmt.Printf("数据表:   %d 个\n", len(m.Tables))
+	fmt.Printf("存储文件: 本次 %d 个 (%s)，共 %d 个\n", m.Files.Included, utils.FormatBytes(m.Files.IncludedSize), m.Files.Total)
+	fmt.Printf("向量索引: %v\n", m.Vector)
+
+	if *prune {
+		cfg := config.GetConfig().Backup
+		removed, err := backup.Prune(cfg.Dir, cfg.Keep)
+		if err != nil {
+			return fmt.Errorf("清理旧备份失败: %w", err)
+		}
+		fmt.Printf("清理旧备份: %d 个文件\n", removed)
+	}
+	return nil
+}
+
+func runRestoreCommand(args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	yes := fs.Bool("yes", false, "不再确认，直接恢复")
+	withConfig := fs.Bool("with-config", false, "同时用备份中的配置文件覆盖 configs/config.yaml")
+	skipVector := fs.Bool("skip-vector", false, "不恢复向量索引")
+	skipFiles := fs.Bool("skip-files", false, "不恢复本地存储文件")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "用法: pixelpunk restore [参数] <备份文件>...")
+		fmt.Fprintln(fs.Output(), "只给出增量备份时，会从同一目录中查找它依赖的备份")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("未指定备份文件")
+	}
+
+	// 配置文件决定连接哪个数据库，需要在初始化数据库之前写入
+	if *withConfig {
+		chain, err := backup.ResolveChain(fs.Args())
+		if err != nil {
+			return err
+		}
+		if !*yes && !confirm("将用备份中的配置文件覆盖 configs/config.yaml") {
+			return fmt.Errorf("已取消")
+		}
+		ok, err := backup.ExtractConfig(chain, "configs/config.yaml")
+		if err != nil {
+			return fmt.Errorf("写入配置文件失败: %w", err)
+		}
+		if !ok {
+			return fmt.Errorf("备份中没有配置文件")
+		}
+	}
+	if err := initCommandEnv(); err != nil {
+		return err
+	}
+
+	chain, err := backup.PrepareRestore(fs.Args())
+	if err != nil {
+		return err
+	}
+	final := chain[len(chain)-1].Manifest
+	fmt.Println("将按以下备份恢复:")
+	for _, a := range chain {
+		fmt.Printf("  %s  %s  版本 %s\n", a.Manifest.CreatedAt.Format("2006-01-02 15:04:05"), a.Path, a.Manifest.AppVersion)
+	}
+	fmt.Printf("数据库: %s，数据表 %d 个；存储文件 %d 个；向量索引: %v\n",
+		config.GetConfig().Database.Type, len(final.Tables), final.Files.Total, final.Vector && !*skipVector)
+	fmt.Println("当前数据库中的全部数据将被替换，请确认服务已停止。")
+	if !*yes && !confirm("继续恢复") {
+		return fmt.Errorf("已取消")
+	}
+
+	result, err := backup.Restore(chain, backup.RestoreOptions{SkipVector: *skipVector, SkipFiles: *skipFiles})
+	if err != nil {
+		return err
+	}
+
+	// 备份来自较早的版本时，补做之后新增的数据迁移
+	cache.InitCache()
+	RunMigrations()
+	if err := cache.ClearNamespaceCache(); err != nil {
+		logger.Warn("清空缓存失败，请手动清理: %v", err)
+	}
+
+	fmt.Printf("已恢复数据表 %d 个，共 %d 行\n", result.Tables, result.Rows)
+	if !*skipFiles {
+		fmt.Printf("已恢复存储文件 %d 个", result.Files)
+		if result.MissingFiles > 0 {
+			fmt.Printf("，%d 个文件在备份中缺失", result.MissingFiles)
+		}
+		fmt.Println()
+	}
+	fmt.Printf("向量索引: %v\n", result.Vector)
+	for _, warning := range result.Warnings {
+		fmt.Println("注意:", warning)
+	}
+	return nil
+}
+
+// confirm 在终端上请求确认，输入 yes 才继续
+func confirm(action string) bool {
+	fmt.Printf("%s？输入 yes 继续: ", action)
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	return strings.TrimSpace(line) == "yes"
+}
@@ -2,6 +2,7 @@ package bootstrap
 
 import (
 	"pixelpunk/migrations"
+	"pixelpunk/pkg/config"
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/logger"
 )
@@ -21,6 +22,17 @@ func RunMigrations() {
 		return
 	}
 
+	// 关闭自动迁移时只提示待执行的迁移，由运维预览后手动执行
+	if !config.GetConfig().Database.AutoMigrate {
+		status, err := migrations.Registry().Status(db, false)
+		if err != nil {
+			logger.Warn("读取迁移状态失败: %v", err)
+		} else if status.Pending > 0 || status.Modified > 0 {
+			logger.Warn("有 %d 个待执行、%d 个已修改的数据迁移，请执行 pixelpunk migrate status 查看", status.Pending, status.Modified)
+		}
+		return
+	}
+
 	if err := migrations.RegisterAllMigrations(db); err != nil {
 		logger.Warn("部分迁移可能执行失败: %v", err)
 		return
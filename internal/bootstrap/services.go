@@ -3,11 +3,18 @@ package bootstrap
 import (
 	"time"
 
+	"pixelpunk/internal/cluster"
 	ai "pixelpunk/internal/services/ai"
+	"pixelpunk/internal/services/automation"
+	"pixelpunk/internal/services/backup"
+	"pixelpunk/internal/services/dataexport"
+	"pixelpunk/internal/services/file"
+	"pixelpunk/internal/services/hostimport"
 	"pixelpunk/internal/services/message"
 	"pixelpunk/internal/services/setting"
 	"pixelpunk/internal/services/user"
 	vectorSvc "pixelpunk/internal/services/vector"
+	"pixelpunk/pkg/health"
 	"pixelpunk/pkg/logger"
 	"pixelpunk/pkg/vector"
 )
@@ -19,9 +26,35 @@ func InitAllServices(appVersion string) {
 	initVectorEngine()
 	ai.RegisterAISettingHooks()
 	vectorSvc.RegisterVectorConfigHooks()
+	automation.RegisterAutomationHooks()
 	if err := ai.InitGlobalTaggingQueue(); err != nil {
 		logger.Warn("AI打标队列初始化警告: %v", err)
 	}
+	hostimport.RegisterImportSources()
+	// 导入和导出任务在 API 进程中执行，多个进程之间通过任务租约认领
+	if cluster.ServesAPI() {
+		file.ResumeImportJobs()
+		dataexport.ResumeExports()
+	}
+	backup.SetAppVersion(appVersion)
+	initWorkerSync()
+}
+
+// initWorkerSync 独立的 worker 进程收不到 API 进程的设置变更通知，随心跳从数据库同步队列设置并上报队列状态
+func initWorkerSync() {
+	if !cluster.RunsWorkers() {
+		return
+	}
+	if !cluster.ServesAPI() {
+		cluster.OnHeartbeat(ai.SyncRuntimeSettings)
+		cluster.OnHeartbeat(vectorSvc.SyncRuntimeSettings)
+	}
+	checker := &workerChecker{}
+	cluster.RegisterDetails(checker.Name(), func() map[string]interface{} {
+		_, details := checker.Check()
+		return details
+	})
+	health.RegisterChecker(checker)
 }
 
 func initVectorEngine() {
@@ -57,7 +90,7 @@ func initVectorEngine() {
 	}
 
 
-	if queueSvc := vectorSvc.GetGlobalVectorQueueService(); queueSvc != nil {
+	if queueSvc := vectorSvc.GetGlobalVectorQueueService(); queueSvc != nil && cluster.RunsWorkers() {
 		go func() {
 			time.Sleep(3 * time.Second)
 			if n, err := queueSvc.EnqueueAllPending(1000); err == nil && n > 0 {
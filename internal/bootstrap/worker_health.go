@@ -0,0 +1,39 @@
+package bootstrap
+
+import (
+	"pixelpunk/internal/services/ai"
+	vectorSvc "pixelpunk/internal/services/vector"
+	"pixelpunk/pkg/health"
+)
+
+// workerChecker 队列处理协程健康检查器，只在 worker 角色的进程中注册
+type workerChecker struct{}
+
+// Name 返回检查项名称
+func (c *workerChecker) Name() string {
+	return "worker"
+}
+
+// Check 执行健康检查，上报 AI 打标和向量队列的运行状态（暂停由管理员控制，不视为异常）
+func (c *workerChecker) Check() (health.Status, map[string]interface{}) {
+	details := map[string]interface{}{}
+
+	if svc := ai.GetGlobalTaggingService(); svc != nil {
+		details["ai"] = svc.GetQueueStats()
+	} else {
+		details["ai"] = map[string]interface{}{"enabled": false}
+	}
+
+	if svc := vectorSvc.GetGlobalVectorQueueService(); svc != nil {
+		details["vector"] = map[string]interface{}{"paused": svc.IsPaused()}
+	} else {
+		details["vector"] = map[string]interface{}{"enabled": false}
+	}
+
+	return health.StatusUp, details
+}
+
+// Type 返回检查类型
+func (c *workerChecker) Type() health.CheckType {
+	return health.CheckTypeBasic
+}
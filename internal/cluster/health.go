@@ -0,0 +1,77 @@
+package cluster
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"pixelpunk/pkg/health"
+)
+
+// NodesChecker 汇总各进程心跳的健康检查器
+type NodesChecker struct{}
+
+// Name 返回检查项名称
+func (c *NodesChecker) Name() string {
+	return "cluster"
+}
+
+// Check 执行健康检查：没有存活的 worker 或 cron 进程时视为性能下降
+func (c *NodesChecker) Check() (health.Status, map[string]interface{}) {
+	nodes, err := ListNodes()
+	if err != nil {
+		return health.StatusDown, map[string]interface{}{
+			"error": err.Error(),
+		}
+	}
+
+	hasWorker, hasCron := false, false
+	items := make([]map[string]interface{}, 0, len(nodes))
+	for _, node := range nodes {
+		roles := strings.Split(node.Roles, ",")
+		for _, role := range roles {
+			hasWorker = hasWorker || role == RoleWorker
+			hasCron = hasCron || role == RoleCron
+		}
+		item := map[string]interface{}{
+			"id":             node.ID,
+			"roles":          roles,
+			"hostname":       node.Hostname,
+			"version":        node.Version,
+			"started_at":     node.StartedAt,
+			"last_heartbeat": time.Since(node.HeartbeatAt).Truncate(time.Second).String(),
+		}
+		var details map[string]interface{}
+		if node.Details != "" && json.Unmarshal([]byte(node.Details), &details) == nil && len(details) > 0 {
+			item["details"] = details
+		}
+		items = append(items, item)
+	}
+
+	result := map[string]interface{}{
+		"current": instanceID,
+		"nodes":   items,
+	}
+	if !hasWorker || !hasCron {
+		missing := make([]string, 0, 2)
+		if !hasWorker {
+			missing = append(missing, RoleWorker)
+		}
+		if !hasCron {
+			missing = append(missing, RoleCron)
+		}
+		result["missing_roles"] = missing
+		return health.StatusDegraded, result
+	}
+	return health.StatusUp, result
+}
+
+// Type 返回检查类型
+func (c *NodesChecker) Type() health.CheckType {
+	return health.CheckTypeComplete
+}
+
+/* RegisterHealthChecker 注册集群健康检查项 */
+func RegisterHealthChecker() {
+	health.RegisterChecker(&NodesChecker{})
+}
@@ -0,0 +1,89 @@
+package cluster
+
+import (
+	"sync/atomic"
+	"time"
+
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/logger"
+)
+
+const (
+	// JobLeaseTTL 后台任务的租约时长，处理进程异常退出后最多经过该时间由其他进程接手
+	JobLeaseTTL = 2 * time.Minute
+	// jobLeaseRenewInterval 处理中续约的间隔
+	jobLeaseRenewInterval = 30 * time.Second
+)
+
+/* JobLease 进程对一条后台任务记录的租约，任务表需要有 status、holder、lease_expires_at 字段 */
+type JobLease struct {
+	model interface{}
+	id    string
+	lost  atomic.Bool
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+/* ClaimJob 原子地认领状态在 statuses 中、且没有被其他进程持有（或租约已过期）的任务
+ * 认领成功后在后台续约，返回 nil 表示任务已由其他进程处理或已结束 */
+func ClaimJob(model interface{}, id string, statuses []string) (*JobLease, error) {
+	now := time.Now()
+	result := database.GetDB().Model(model).
+		Where("id = ? AND status IN ?", id, statuses).
+		Where("holder = '' OR holder IS NULL OR holder = ? OR lease_expires_at < ?", instanceID, now).
+		Updates(map[string]interface{}{"holder": instanceID, "lease_expires_at": now.Add(JobLeaseTTL)})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	l := &JobLease{model: model, id: id, stop: make(chan struct{}), done: make(chan struct{})}
+	go l.loop()
+	return l, nil
+}
+
+/* LeaseHeld 任务是否正由某个存活的进程处理 */
+func LeaseHeld(holder string, expiresAt *time.Time) bool {
+	return holder != "" && expiresAt != nil && expiresAt.After(time.Now())
+}
+
+/* Lost 续约失败，任务可能已由其他进程接手，当前进程应尽快停止处理 */
+func (l *JobLease) Lost() bool {
+	return l.lost.Load()
+}
+
+/* Release 停止续约并释放租约，任务结束或放弃处理时调用 */
+func (l *JobLease) Release() {
+	close(l.stop)
+	<-l.done
+	database.GetDB().Model(l.model).
+		Where("id = ? AND holder = ?", l.id, instanceID).
+		Updates(map[string]interface{}{"holder": "", "lease_expires_at": nil})
+}
+
+func (l *JobLease) loop() {
+	defer close(l.done)
+	ticker := time.NewTicker(jobLeaseRenewInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			result := database.GetDB().Model(l.model).
+				Where("id = ? AND holder = ?", l.id, instanceID).
+				Update("lease_expires_at", time.Now().Add(JobLeaseTTL))
+			if result.Error != nil {
+				logger.Warn("任务续约失败: id=%s, error=%v", l.id, result.Error)
+				continue
+			}
+			if result.RowsAffected == 0 {
+				logger.Warn("任务租约已被其他进程接手: id=%s", l.id)
+				l.lost.Store(true)
+				return
+			}
+		}
+	}
+}
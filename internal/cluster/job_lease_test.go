@@ -0,0 +1,28 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLeaseHeld(t *testing.T) {
+	future := time.Now().Add(time.Minute)
+	past := time.Now().Add(-time.Second)
+
+	cases := []struct {
+		name      string
+		holder    string
+		expiresAt *time.Time
+		want      bool
+	}{
+		{"无持有者", "", &future, false},
+		{"没有租约时间", "node-1", nil, false},
+		{"租约已过期", "node-1", &past, false},
+		{"租约有效", "node-1", &future, true},
+	}
+	for _, tc := range cases {
+		if got := LeaseHeld(tc.holder, tc.expiresAt); got != tc.want {
+			t.Errorf("%s: 期望 %v, 实际 %v", tc.name, tc.want, got)
+		}
+	}
+}
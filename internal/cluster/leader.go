@@ -0,0 +1,156 @@
+package cluster
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/cache"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/logger"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// leaderTTL 主进程的租约时长，主进程异常退出后最多经过该时间由其他进程接替
+	leaderTTL = 30 * time.Second
+	// leaderRenewInterval 续约和竞选的间隔
+	leaderRenewInterval = 10 * time.Second
+)
+
+// 只有锁仍由自己持有时才续约或释放
+var (
+	renewScript   = redis.NewScript(`if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("PEXPIRE", KEYS[1], ARGV[2]) end return 0`)
+	releaseScript = redis.NewScript(`if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) end return 0`)
+)
+
+/* Leader 多进程选主：启用 Redis 时使用 Redis 锁，否则使用数据库锁 */
+type Leader struct {
+	name     string
+	leading  atomic.Bool
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+/* StartLeaderElection 开始竞选名为 name 的主进程，竞选和续约在后台进行 */
+func StartLeaderElection(name string) *Leader {
+	l := &Leader{name: name, stop: make(chan struct{}), done: make(chan struct{})}
+	l.tick()
+	go l.loop()
+	return l
+}
+
+/* IsLeader 当前进程是否为主进程 */
+func (l *Leader) IsLeader() bool {
+	return l != nil && l.leading.Load()
+}
+
+/* Stop 停止竞选，持有锁时立即释放以便其他进程接替 */
+func (l *Leader) Stop() {
+	if l == nil {
+		return
+	}
+	l.stopOnce.Do(func() {
+		close(l.stop)
+		<-l.done
+		if l.leading.Swap(false) {
+			l.release()
+		}
+	})
+}
+
+func (l *Leader) loop() {
+	defer close(l.done)
+	ticker := time.NewTicker(leaderRenewInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			l.tick()
+		}
+	}
+}
+
+func (l *Leader) tick() {
+	acquired, err := l.acquire()
+	if err != nil {
+		// 无法确认锁状态时放弃主进程身份，避免两个进程同时执行
+		logger.Warn("选主失败: name=%s, error=%v", l.name, err)
+		acquired = false
+	}
+	if was := l.leading.Swap(acquired); was != acquired {
+		if acquired {
+			logger.Info("成为主进程: name=%s, id=%s", l.name, instanceID)
+		} else {
+			logger.Info("不再是主进程: name=%s, id=%s", l.name, instanceID)
+		}
+	}
+}
+
+func (l *Leader) redisKey() string {
+	return fmt.Sprintf("%s:leader:%s", cache.GetNamespace(), l.name)
+}
+
+// acquire 竞选或续约，返回当前进程是否持有锁
+func (l *Leader) acquire() (bool, error) {
+	if rc := cache.GetRedisClient(); rc != nil {
+		ctx := cache.GetRedisContext()
+		if l.leading.Load() {
+			n, err := renewScript.Run(ctx, rc, []string{l.redisKey()}, instanceID, leaderTTL.Milliseconds()).Int()
+			if err != nil {
+				return false, err
+			}
+			if n == 1 {
+				return true, nil
+			}
+		}
+		return rc.SetNX(ctx, l.redisKey(), instanceID, leaderTTL).Result()
+	}
+	return l.acquireDB()
+}
+
+func (l *Leader) acquireDB() (bool, error) {
+	db := database.GetDB()
+	if db == nil {
+		return false, fmt.Errorf("数据库不可用")
+	}
+	now := time.Now()
+	result := db.Model(&models.ClusterLock{}).
+		Where("name = ? AND (holder = ? OR expires_at < ?)", l.name, instanceID, now).
+		Updates(map[string]interface{}{"holder": instanceID, "expires_at": now.Add(leaderTTL)})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return true, nil
+	}
+
+	var count int64
+	if err := db.Model(&models.ClusterLock{}).Where("name = ?", l.name).Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return false, nil
+	}
+	// 锁记录不存在时插入，主键冲突说明其他进程同时插入成功
+	err := db.Create(&models.ClusterLock{Name: l.name, Holder: instanceID, ExpiresAt: now.Add(leaderTTL)}).Error
+	return err == nil, nil
+}
+
+func (l *Leader) release() {
+	if rc := cache.GetRedisClient(); rc != nil {
+		_ = releaseScript.Run(cache.GetRedisContext(), rc, []string{l.redisKey()}, instanceID).Err()
+		return
+	}
+	if db := database.GetDB(); db != nil {
+		_ = db.Model(&models.ClusterLock{}).
+			Where("name = ? AND holder = ?", l.name, instanceID).
+			Updates(map[string]interface{}{"holder": "", "expires_at": time.Now()}).Error
+	}
+}
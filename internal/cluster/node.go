@@ -0,0 +1,163 @@
+package cluster
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/logger"
+)
+
+const (
+	// HeartbeatInterval 进程写入心跳的间隔
+	HeartbeatInterval = 15 * time.Second
+	// NodeTimeout 超过该时间没有心跳的进程视为已下线
+	NodeTimeout = 4 * HeartbeatInterval
+	// nodeRetention 下线进程的记录保留时长
+	nodeRetention = 24 * time.Hour
+)
+
+var (
+	instanceID = newInstanceID()
+
+	nodeMu       sync.Mutex
+	nodeStop     chan struct{}
+	nodeVersion  string
+	nodeStarted  time.Time
+	detailFuncs  = map[string]func() map[string]interface{}{}
+	onHeartbeats []func()
+)
+
+func newInstanceID() string {
+	host, _ := os.Hostname()
+	if len(host) > 40 {
+		host = host[:40]
+	}
+	buf := make([]byte, 4)
+	_, _ = rand.Read(buf)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(buf))
+}
+
+/* InstanceID 当前进程的唯一标识，用于心跳记录和锁持有者 */
+func InstanceID() string { return instanceID }
+
+/* RegisterDetails 注册随心跳上报的组件状态，name 为 JSON 中的键 */
+func RegisterDetails(name string, fn func() map[string]interface{}) {
+	nodeMu.Lock()
+	defer nodeMu.Unlock()
+	detailFuncs[name] = fn
+}
+
+/* OnHeartbeat 注册每次心跳时执行的回调，用于同步其他进程修改的运行时设置 */
+func OnHeartbeat(fn func()) {
+	nodeMu.Lock()
+	defer nodeMu.Unlock()
+	onHeartbeats = append(onHeartbeats, fn)
+}
+
+/* StartNode 写入当前进程的心跳记录并定时刷新 */
+func StartNode(version string) {
+	nodeMu.Lock()
+	if nodeStop != nil {
+		nodeMu.Unlock()
+		return
+	}
+	nodeStop = make(chan struct{})
+	nodeVersion = version
+	nodeStarted = time.Now()
+	stop := nodeStop
+	nodeMu.Unlock()
+
+	heartbeat()
+	logger.Info("进程已注册: id=%s, 角色=%s", instanceID, RolesString())
+
+	go func() {
+		ticker := time.NewTicker(HeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				heartbeat()
+			}
+		}
+	}()
+}
+
+/* StopNode 停止心跳并删除当前进程的记录 */
+func StopNode() {
+	nodeMu.Lock()
+	stop := nodeStop
+	nodeStop = nil
+	nodeMu.Unlock()
+	if stop == nil {
+		return
+	}
+	close(stop)
+	if db := database.GetDB(); db != nil {
+		_ = db.Where("id = ?", instanceID).Delete(&models.ClusterNode{}).Error
+	}
+}
+
+func heartbeat() {
+	nodeMu.Lock()
+	hooks := append([]func(){}, onHeartbeats...)
+	funcs := make(map[string]func() map[string]interface{}, len(detailFuncs))
+	for name, fn := range detailFuncs {
+		funcs[name] = fn
+	}
+	nodeMu.Unlock()
+
+	for _, fn := range hooks {
+		fn()
+	}
+
+	details := make(map[string]interface{}, len(funcs))
+	for name, fn := range funcs {
+		details[name] = fn()
+	}
+	data, _ := json.Marshal(details)
+
+	db := database.GetDB()
+	if db == nil {
+		return
+	}
+	host, _ := os.Hostname()
+	now := time.Now()
+	node := models.ClusterNode{
+		ID:          instanceID,
+		Roles:       RolesString(),
+		Hostname:    host,
+		Version:     nodeVersion,
+		StartedAt:   nodeStarted,
+		HeartbeatAt: now,
+		Details:     string(data),
+	}
+	if err := db.Save(&node).Error; err != nil {
+		logger.Warn("写入进程心跳失败: %v", err)
+		return
+	}
+	_ = db.Where("heartbeat_at < ?", now.Add(-nodeRetention)).Delete(&models.ClusterNode{}).Error
+}
+
+/* ListNodes 列出心跳未超时的进程，按启动时间排序 */
+func ListNodes() ([]models.ClusterNode, error) {
+	db := database.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("数据库不可用")
+	}
+	var nodes []models.ClusterNode
+	if err := db.Where("heartbeat_at >= ?", time.Now().Add(-NodeTimeout)).Find(&nodes).Error; err != nil {
+		return nil, err
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].StartedAt.Before(nodes[j].StartedAt) })
+	return nodes, nil
+}
@@ -0,0 +1,81 @@
+package cluster
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+/* 进程角色：同一数据库和 Redis 上可以运行多个不同角色的进程 */
+const (
+	RoleAPI    = "api"    // HTTP 接口、上传与文件访问
+	RoleWorker = "worker" // AI 打标与向量队列的处理协程
+	RoleCron   = "cron"   // 定时任务，多个进程之间选主，只在主进程上执行
+	RoleAll    = "all"    // 以上全部（默认，单进程部署）
+)
+
+var (
+	rolesMu sync.RWMutex
+	roles   = map[string]bool{RoleAPI: true, RoleWorker: true, RoleCron: true}
+)
+
+/* ParseRoles 解析逗号分隔的角色配置，为空时视为 all */
+func ParseRoles(value string) (map[string]bool, error) {
+	result := make(map[string]bool)
+	for _, part := range strings.Split(value, ",") {
+		switch role := strings.ToLower(strings.TrimSpace(part)); role {
+		case "":
+			continue
+		case RoleAll:
+			result[RoleAPI], result[RoleWorker], result[RoleCron] = true, true, true
+		case RoleAPI, RoleWorker, RoleCron:
+			result[role] = true
+		default:
+			return nil, fmt.Errorf("未知的进程角色: %s", role)
+		}
+	}
+	if len(result) == 0 {
+		return ParseRoles(RoleAll)
+	}
+	return result, nil
+}
+
+/* SetRoles 设置当前进程的角色，应在初始化服务之前调用 */
+func SetRoles(value string) error {
+	parsed, err := ParseRoles(value)
+	if err != nil {
+		return err
+	}
+	rolesMu.Lock()
+	roles = parsed
+	rolesMu.Unlock()
+	return nil
+}
+
+func hasRole(role string) bool {
+	rolesMu.RLock()
+	defer rolesMu.RUnlock()
+	return roles[role]
+}
+
+/* ServesAPI 当前进程是否提供 HTTP 接口 */
+func ServesAPI() bool { return hasRole(RoleAPI) }
+
+/* RunsWorkers 当前进程是否运行 AI 打标和向量队列的处理协程 */
+func RunsWorkers() bool { return hasRole(RoleWorker) }
+
+/* RunsCron 当前进程是否参与定时任务选主 */
+func RunsCron() bool { return hasRole(RoleCron) }
+
+/* RolesString 返回排序后的角色列表，如 "api,cron" */
+func RolesString() string {
+	rolesMu.RLock()
+	defer rolesMu.RUnlock()
+	names := make([]string, 0, len(roles))
+	for role := range roles {
+		names = append(names, role)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,34 @@
+package cluster
+
+import "testing"
+
+func TestParseRoles(t *testing.T) {
+	cases := map[string][]string{
+		"":             {RoleAPI, RoleWorker, RoleCron},
+		"all":          {RoleAPI, RoleWorker, RoleCron},
+		"worker":       {RoleWorker},
+		" API , cron ": {RoleAPI, RoleCron},
+		"worker,all":   {RoleAPI, RoleWorker, RoleCron},
+		"cron,,":       {RoleCron},
+	}
+	for value, want := range cases {
+		got, err := ParseRoles(value)
+		if err != nil {
+			t.Errorf("%q: 解析失败: %v", value, err)
+			continue
+		}
+		if len(got) != len(want) {
+			t.Errorf("%q: 期望 %v, 实际 %v", value, want, got)
+			continue
+		}
+		for _, role := range want {
+			if !got[role] {
+				t.Errorf("%q: 缺少角色 %s", value, role)
+			}
+		}
+	}
+
+	if _, err := ParseRoles("api,scheduler"); err == nil {
+		t.Error("未知角色应解析失败")
+	}
+}
@@ -15,6 +15,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ReviewQueueQueryDTO struct {
@@ -84,7 +85,7 @@ func GetReviewQueue(c *gin.Context) {
 	db := database.GetDB().Model(&models.File{}).Preload("AIInfo").Preload("User").Where("status = ?", "pending_review")
 
 	if keyword != "" {
-		db = db.Where("original_name LIKE ? OR display_name LIKE ?", "%"+keyword+"%", "%"+keyword+"%")
+		db = db.Where(database.ILike("original_name LIKE ? OR display_name LIKE ?"), "%"+keyword+"%", "%"+keyword+"%")
 	}
 
 	var total int64
@@ -357,9 +358,11 @@ func GetReviewLogs(c *gin.Context) {
 
 	if req.Keyword != "" {
 		keyword := "%" + req.Keyword + "%"
+		// user 在 PostgreSQL 中是保留字，表名交给 GORM 加引号
+		userTable := clause.Table{Name: models.User{}.TableName()}
 		query = query.Where(
-			"reason LIKE ? OR EXISTS (SELECT 1 FROM user WHERE user.id = review_log.auditor_id AND user.username LIKE ?) OR EXISTS (SELECT 1 FROM user WHERE user.id = review_log.uploader_id AND user.username LIKE ?) OR EXISTS (SELECT 1 FROM file WHERE file.id = review_log.file_id AND (file.original_name LIKE ? OR file.display_name LIKE ?))",
-			keyword, keyword, keyword, keyword, keyword,
+			database.ILike("reason LIKE ? OR EXISTS (SELECT 1 FROM ? u WHERE u.id = review_log.auditor_id AND u.username LIKE ?) OR EXISTS (SELECT 1 FROM ? u WHERE u.id = review_log.uploader_id AND u.username LIKE ?) OR EXISTS (SELECT 1 FROM file f WHERE f.id = review_log.file_id AND (f.original_name LIKE ? OR f.display_name LIKE ?))"),
+			keyword, userTable, keyword, userTable, keyword, keyword, keyword,
 		)
 	}
 
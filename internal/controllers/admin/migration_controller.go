@@ -0,0 +1,44 @@
+package admin
+
+import (
+	"strconv"
+
+	"pixelpunk/migrations"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/migrate"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMigrationStatus 查看数据迁移状态和表结构是否需要同步
+func GetMigrationStatus(c *gin.Context) {
+	status, err := migrations.Registry().Status(database.GetDB(), true)
+	if err != nil {
+		errors.HandleError(c, errors.Wrap(err, errors.CodeDBQueryFailed, "读取迁移状态失败: "+err.Error()))
+		return
+	}
+
+	errors.ResponseSuccess(c, status, "获取迁移状态成功")
+}
+
+// PreviewMigrations 预览升级将要执行的 SQL，不修改数据库；执行迁移请使用 pixelpunk migrate 命令
+func PreviewMigrations(c *gin.Context) {
+	var target int64
+	if to := c.Query("to"); to != "" {
+		v, err := strconv.ParseInt(to, 10, 64)
+		if err != nil || v < 0 {
+			errors.HandleError(c, errors.NewValidationError("to", "版本号无效"))
+			return
+		}
+		target = v
+	}
+
+	result, err := migrations.Registry().Up(database.GetDB(), migrate.Options{Target: target, DryRun: true, SyncSchema: true})
+	if err != nil {
+		errors.HandleError(c, errors.Wrap(err, errors.CodeDBQueryFailed, "预览迁移失败: "+err.Error()))
+		return
+	}
+
+	errors.ResponseSuccess(c, result, "预览迁移成功")
+}
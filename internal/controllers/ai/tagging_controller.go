@@ -3,6 +3,7 @@ package ai
 import (
 	"fmt"
 	"pixelpunk/internal/controllers/ai/dto"
+	setdto "pixelpunk/internal/controllers/setting/dto"
 	"pixelpunk/internal/middleware"
 	"pixelpunk/internal/models"
 	"pixelpunk/internal/services/ai"
@@ -161,22 +162,22 @@ func ToggleAutoProcessing(c *gin.Context) {
 		errors.HandleError(c, errors.New(errors.CodeInvalidParameter, "参数错误"))
 		return
 	}
-	svc := ai.GetGlobalTaggingService()
-	if svc == nil {
+	if ai.GetGlobalTaggingService() == nil {
 		if initErr := ai.InitGlobalTaggingQueue(); initErr != nil {
 			errors.HandleError(c, errors.New(errors.CodeInternal, "队列服务初始化失败: "+initErr.Error()))
 			return
 		}
-		svc = ai.GetGlobalTaggingService()
-		if svc == nil {
+		if ai.GetGlobalTaggingService() == nil {
 			errors.HandleError(c, errors.New(errors.CodeInternal, "AI功能未启用，请先在AI设置中启用AI功能"))
 			return
 		}
 	}
-	if body.Enabled {
-		svc.Resume()
-	} else {
-		svc.Pause()
+	// 保存开关后由设置变更钩子暂停或恢复本进程的队列，独立的 worker 进程在下次心跳时同步
+	if _, err := setting.BatchUpsertSettings(&setdto.BatchUpsertSettingDTO{Settings: []setdto.SettingCreateDTO{{
+		Key: "ai_auto_processing_enabled", Value: body.Enabled, Type: "boolean", Group: "ai", Description: "AI队列自动处理开关", IsSystem: true,
+	}}}); err != nil {
+		errors.HandleError(c, err)
+		return
 	}
 	errors.ResponseSuccess(c, gin.H{"enabled": body.Enabled}, "已更新自动处理状态")
 }
@@ -0,0 +1,183 @@
+package album
+
+import (
+	"pixelpunk/internal/controllers/album/dto"
+	"pixelpunk/internal/middleware"
+	albumService "pixelpunk/internal/services/album"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary 获取相册列表
+// @Tags 相册
+// @Produce json
+// @Router /albums [get]
+func ListAlbums(c *gin.Context) {
+	albums, err := albumService.ListAlbums(middleware.GetCurrentUserID(c))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{"items": albums}, "获取成功")
+}
+
+// @Summary 获取相册详情
+// @Tags 相册
+// @Produce json
+// @Param album_id path string true "相册ID"
+// @Router /albums/{album_id} [get]
+func GetAlbum(c *gin.Context) {
+	album, err := albumService.GetAlbumDetail(middleware.GetCurrentUserID(c), c.Param("album_id"))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, album, "获取成功")
+}
+
+// @Summary 创建相册
+// @Tags 相册
+// @Accept json
+// @Produce json
+// @Param body body dto.CreateAlbumDTO true "相册内容"
+// @Router /albums [post]
+func CreateAlbum(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.CreateAlbumDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	album, err := albumService.CreateAlbum(middleware.GetCurrentUserID(c), req)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, album, "创建成功")
+}
+
+// @Summary 更新相册
+// @Tags 相册
+// @Accept json
+// @Produce json
+// @Param album_id path string true "相册ID"
+// @Param body body dto.UpdateAlbumDTO true "相册内容"
+// @Router /albums/{album_id} [put]
+func UpdateAlbum(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.UpdateAlbumDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	album, err := albumService.UpdateAlbum(middleware.GetCurrentUserID(c), c.Param("album_id"), req)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, album, "更新成功")
+}
+
+// @Summary 删除相册
+// @Tags 相册
+// @Produce json
+// @Param album_id path string true "相册ID"
+// @Router /albums/{album_id} [delete]
+func DeleteAlbum(c *gin.Context) {
+	if err := albumService.DeleteAlbum(middleware.GetCurrentUserID(c), c.Param("album_id")); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, nil, "删除成功")
+}
+
+// @Summary 获取相册文件列表
+// @Tags 相册
+// @Produce json
+// @Param album_id path string true "相册ID"
+// @Param page query int false "页码"
+// @Param size query int false "每页数量"
+// @Param sort query string false "排序方式"
+// @Router /albums/{album_id}/files [get]
+func ListAlbumFiles(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.AlbumFileListQueryDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Size <= 0 {
+		req.Size = 20
+	}
+
+	files, total, err := albumService.ListAlbumFiles(middleware.GetCurrentUserID(c), c.Param("album_id"), req.Page, req.Size, req.Sort)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{
+		"items": files,
+		"pagination": gin.H{
+			"total":        total,
+			"size":         req.Size,
+			"current_page": req.Page,
+			"last_page":    (total + int64(req.Size) - 1) / int64(req.Size),
+		},
+	}, "获取成功")
+}
+
+// @Summary 向相册添加文件
+// @Tags 相册
+// @Accept json
+// @Produce json
+// @Param album_id path string true "相册ID"
+// @Param body body dto.AlbumFilesDTO true "文件ID列表"
+// @Router /albums/{album_id}/files [post]
+func AddAlbumFiles(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.AlbumFilesDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	added, err := albumService.AddFilesToAlbum(middleware.GetCurrentUserID(c), c.Param("album_id"), req.FileIDs)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{"added": added}, "添加成功")
+}
+
+// @Summary 从相册移除文件
+// @Tags 相册
+// @Accept json
+// @Produce json
+// @Param album_id path string true "相册ID"
+// @Param body body dto.AlbumFilesDTO true "文件ID列表"
+// @Router /albums/{album_id}/files/remove [post]
+func RemoveAlbumFiles(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.AlbumFilesDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	removed, err := albumService.RemoveFilesFromAlbum(middleware.GetCurrentUserID(c), c.Param("album_id"), req.FileIDs)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{"removed": removed}, "移除成功")
+}
@@ -0,0 +1,102 @@
+package dto
+
+// AlbumQueryDTO 智能相册保存的查询条件，字段含义与文件列表筛选一致
+type AlbumQueryDTO struct {
+	Keyword       string   `json:"keyword,omitempty" binding:"omitempty,max=100"`
+	Tags          []string `json:"tags,omitempty" binding:"omitempty,max=20"` // 标签ID
+	CategoryIDs   []string `json:"category_ids,omitempty" binding:"omitempty,max=20"`
+	DominantColor []string `json:"dominant_color,omitempty" binding:"omitempty,max=10"`
+	Resolution    string   `json:"resolution,omitempty" binding:"omitempty,max=20"`
+	MinWidth      int      `json:"min_width,omitempty" binding:"omitempty,min=0"`
+	MaxWidth      int      `json:"max_width,omitempty" binding:"omitempty,min=0"`
+	MinHeight     int      `json:"min_height,omitempty" binding:"omitempty,min=0"`
+	MaxHeight     int      `json:"max_height,omitempty" binding:"omitempty,min=0"`
+	DateFrom      string   `json:"date_from,omitempty" binding:"omitempty,datetime=2006-01-02"` // 上传日期起
+	DateTo        string   `json:"date_to,omitempty" binding:"omitempty,datetime=2006-01-02"`   // 上传日期止（含当天）
+	CameraMake    string   `json:"camera_make,omitempty" binding:"omitempty,max=100"`
+	CameraModel   string   `json:"camera_model,omitempty" binding:"omitempty,max=100"`
+	FolderID      string   `json:"folder_id,omitempty" binding:"omitempty,max=32"`
+	AccessLevel   string   `json:"access_level,omitempty" binding:"omitempty,oneof=public private protected"`
+	Sort          string   `json:"sort,omitempty" binding:"omitempty,oneof=newest oldest name size width height views"`
+}
+
+// CreateAlbumDTO 创建相册
+type CreateAlbumDTO struct {
+	Name        string         `json:"name" binding:"required,max=100"`
+	Description string         `json:"description" binding:"omitempty,max=500"`
+	Type        string         `json:"type" binding:"required,oneof=manual smart"`
+	Query       *AlbumQueryDTO `json:"query"`
+	FileIDs     []string       `json:"file_ids" binding:"omitempty,max=500"` // 手动相册的初始文件
+	CoverFileID string         `json:"cover_file_id" binding:"omitempty,max=32"`
+}
+
+func (d *CreateAlbumDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Name.required":     "相册名称不能为空",
+		"Name.max":          "相册名称不能超过100个字符",
+		"Description.max":   "相册描述不能超过500个字符",
+		"Type.required":     "相册类型不能为空",
+		"Type.oneof":        "相册类型必须是manual或smart",
+		"FileIDs.max":       "一次最多添加500个文件",
+		"Keyword.max":       "搜索关键字不能超过100个字符",
+		"Tags.max":          "最多选择20个标签",
+		"CategoryIDs.max":   "最多选择20个分类",
+		"DateFrom.datetime": "开始日期格式应为YYYY-MM-DD",
+		"DateTo.datetime":   "结束日期格式应为YYYY-MM-DD",
+		"AccessLevel.oneof": "访问级别必须是 public、private 或 protected",
+		"Sort.oneof":        "排序方式不正确",
+	}
+}
+
+// UpdateAlbumDTO 更新相册，未传的字段保持不变
+type UpdateAlbumDTO struct {
+	Name        *string        `json:"name" binding:"omitempty,min=1,max=100"`
+	Description *string        `json:"description" binding:"omitempty,max=500"`
+	Query       *AlbumQueryDTO `json:"query"`
+	CoverFileID *string        `json:"cover_file_id" binding:"omitempty,max=32"`
+	SortOrder   *int           `json:"sort_order"`
+}
+
+func (d *UpdateAlbumDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Name.min":          "相册名称不能为空",
+		"Name.max":          "相册名称不能超过100个字符",
+		"Description.max":   "相册描述不能超过500个字符",
+		"Keyword.max":       "搜索关键字不能超过100个字符",
+		"Tags.max":          "最多选择20个标签",
+		"CategoryIDs.max":   "最多选择20个分类",
+		"DateFrom.datetime": "开始日期格式应为YYYY-MM-DD",
+		"DateTo.datetime":   "结束日期格式应为YYYY-MM-DD",
+		"AccessLevel.oneof": "访问级别必须是 public、private 或 protected",
+		"Sort.oneof":        "排序方式不正确",
+	}
+}
+
+// AlbumFilesDTO 向手动相册添加或移除文件
+type AlbumFilesDTO struct {
+	FileIDs []string `json:"file_ids" binding:"required,min=1,max=500"`
+}
+
+func (d *AlbumFilesDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"FileIDs.required": "文件ID列表不能为空",
+		"FileIDs.min":      "文件ID列表不能为空",
+		"FileIDs.max":      "一次最多操作500个文件",
+	}
+}
+
+// AlbumFileListQueryDTO 相册文件列表查询
+type AlbumFileListQueryDTO struct {
+	Page int    `form:"page" binding:"omitempty,min=1"`
+	Size int    `form:"size" binding:"omitempty,min=1,max=100"`
+	Sort string `form:"sort" binding:"omitempty,oneof=newest oldest name size width height views"`
+}
+
+func (d *AlbumFileListQueryDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Page.min":   "页码必须大于等于1",
+		"Size.min":   "每页数量必须大于等于1",
+		"Size.max":   "每页数量必须小于等于100",
+		"Sort.oneof": "排序方式不正确",
+	}
+}
@@ -0,0 +1,125 @@
+package dto
+
+// RuleConditionDTO 规则匹配条件，所有已填写的条件都满足时才算命中
+type RuleConditionDTO struct {
+	Formats         []string `json:"formats,omitempty"`          // 文件格式，如 jpg、png
+	MinSize         int64    `json:"min_size,omitempty"`         // 最小文件大小（字节）
+	MaxSize         int64    `json:"max_size,omitempty"`         // 最大文件大小（字节）
+	MinWidth        int      `json:"min_width,omitempty"`        // 最小宽度
+	MaxWidth        int      `json:"max_width,omitempty"`        // 最大宽度
+	MinHeight       int      `json:"min_height,omitempty"`       // 最小高度
+	MaxHeight       int      `json:"max_height,omitempty"`       // 最大高度
+	CameraMake      string   `json:"camera_make,omitempty"`      // EXIF制造商，包含匹配
+	CameraModel     string   `json:"camera_model,omitempty"`     // EXIF型号，包含匹配
+	AITagsAny       []string `json:"ai_tags_any,omitempty"`      // 包含任意一个标签
+	AITagsAll       []string `json:"ai_tags_all,omitempty"`      // 包含全部标签
+	CategoryIDs     []uint   `json:"category_ids,omitempty"`     // 所属分类
+	MinNSFWScore    *float64 `json:"min_nsfw_score,omitempty"`   // NSFW评分下限
+	MaxNSFWScore    *float64 `json:"max_nsfw_score,omitempty"`   // NSFW评分上限
+	APIKeyIDs       []string `json:"api_key_ids,omitempty"`      // 通过指定API密钥上传
+	FilenamePattern string   `json:"filename_pattern,omitempty"` // 文件名通配符，以 re: 开头时按正则匹配
+}
+
+// RuleActionDTO 规则动作
+type RuleActionDTO struct {
+	Type          string   `json:"type" binding:"required,oneof=move_folder set_access_level add_tags set_category set_expiry add_to_share webhook"`
+	FolderID      string   `json:"folder_id,omitempty"`
+	AccessLevel   string   `json:"access_level,omitempty" binding:"omitempty,oneof=public private protected"`
+	Tags          []string `json:"tags,omitempty" binding:"omitempty,max=20"`
+	CategoryID    uint     `json:"category_id,omitempty"`
+	ExpireDays    int      `json:"expire_days,omitempty" binding:"omitempty,min=0,max=3650"`
+	ShareID       string   `json:"share_id,omitempty"`
+	WebhookURL    string   `json:"webhook_url,omitempty" binding:"omitempty,url,max=500"`
+	WebhookSecret string   `json:"webhook_secret,omitempty" binding:"omitempty,max=128"`
+}
+
+// SaveRuleDTO 创建/更新规则
+type SaveRuleDTO struct {
+	Name           string           `json:"name" binding:"required,max=100"`
+	Description    string           `json:"description" binding:"omitempty,max=255"`
+	Enabled        *bool            `json:"enabled"`
+	Priority       int              `json:"priority" binding:"min=0,max=9999"`
+	Triggers       []string         `json:"triggers" binding:"required,min=1,dive,oneof=upload ai_tagged"`
+	Conditions     RuleConditionDTO `json:"conditions"`
+	Actions        []RuleActionDTO  `json:"actions" binding:"required,min=1,max=10,dive"`
+	StopProcessing bool             `json:"stop_processing"`
+}
+
+func (d *SaveRuleDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Name.required":     "规则名称不能为空",
+		"Name.max":          "规则名称不能超过100个字符",
+		"Description.max":   "规则描述不能超过255个字符",
+		"Priority.min":      "优先级不能为负数",
+		"Priority.max":      "优先级不能超过9999",
+		"Triggers.required": "请选择触发时机",
+		"Triggers.min":      "请选择触发时机",
+		"Triggers.oneof":    "触发时机必须是upload或ai_tagged",
+		"Actions.required":  "至少需要一个动作",
+		"Actions.min":       "至少需要一个动作",
+		"Actions.max":       "单条规则最多10个动作",
+		"Type.required":     "动作类型不能为空",
+		"Type.oneof":        "不支持的动作类型",
+		"AccessLevel.oneof": "访问级别必须是public、private或protected",
+		"Tags.max":          "单个动作最多添加20个标签",
+		"ExpireDays.min":    "过期天数不能为负数",
+		"ExpireDays.max":    "过期天数不能超过3650",
+		"WebhookURL.url":    "Webhook地址格式不正确",
+		"WebhookURL.max":    "Webhook地址不能超过500个字符",
+		"WebhookSecret.max": "Webhook密钥不能超过128个字符",
+	}
+}
+
+// ToggleRuleDTO 启用/停用规则
+type ToggleRuleDTO struct {
+	Enabled bool `json:"enabled"`
+}
+
+// RuleLogQuery 规则执行日志查询参数
+type RuleLogQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=success partial failed"`
+	Page   int    `form:"page" binding:"omitempty,min=1"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+func (d *RuleLogQuery) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Status.oneof": "状态必须是success、partial或failed",
+		"Page.min":     "页码必须大于等于1",
+		"Limit.min":    "每页数量必须大于等于1",
+		"Limit.max":    "每页数量不能超过100",
+	}
+}
+
+// DryRunRuleDTO 试运行规则，不修改任何数据
+// 指定 RuleID 时使用已保存的规则，否则使用 Rule 中的临时规则；未指定文件时取最近上传的文件
+type DryRunRuleDTO struct {
+	RuleID  uint         `json:"rule_id"`
+	Rule    *SaveRuleDTO `json:"rule"`
+	FileIDs []string     `json:"file_ids" binding:"omitempty,max=50"`
+	Limit   int          `json:"limit" binding:"omitempty,min=1,max=50"`
+}
+
+func (d *DryRunRuleDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"FileIDs.max": "单次最多试运行50个文件",
+		"Limit.min":   "文件数量必须大于等于1",
+		"Limit.max":   "文件数量不能超过50",
+	}
+}
+
+// DryRunFileResult 单个文件的试运行结果
+type DryRunFileResult struct {
+	FileID   string   `json:"file_id"`
+	FileName string   `json:"file_name"`
+	Matched  bool     `json:"matched"`
+	Reasons  []string `json:"reasons,omitempty"` // 未命中的条件
+	Actions  []string `json:"actions,omitempty"` // 命中后将执行的动作
+}
+
+// DryRunResponse 试运行结果
+type DryRunResponse struct {
+	Total   int                `json:"total"`
+	Matched int                `json:"matched"`
+	Files   []DryRunFileResult `json:"files"`
+}
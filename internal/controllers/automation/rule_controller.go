@@ -0,0 +1,204 @@
+package automation
+
+import (
+	"strconv"
+
+	"pixelpunk/internal/controllers/automation/dto"
+	"pixelpunk/internal/middleware"
+	"pixelpunk/internal/services/automation"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+func parseRuleID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		errors.HandleError(c, errors.New(errors.CodeInvalidParameter, "无效的规则ID"))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// @Summary 获取自动化规则列表
+// @Tags 用户自动任务
+// @Produce json
+// @Router /user/automation/rules [get]
+func ListRules(c *gin.Context) {
+	rules, err := automation.ListRules(middleware.GetCurrentUserID(c))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{"items": rules}, "获取成功")
+}
+
+// @Summary 获取自动化规则详情
+// @Tags 用户自动任务
+// @Produce json
+// @Param id path int true "规则ID"
+// @Router /user/automation/rules/{id} [get]
+func GetRule(c *gin.Context) {
+	ruleID, ok := parseRuleID(c)
+	if !ok {
+		return
+	}
+
+	rule, err := automation.GetRule(middleware.GetCurrentUserID(c), ruleID)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, rule, "获取成功")
+}
+
+// @Summary 创建自动化规则
+// @Tags 用户自动任务
+// @Accept json
+// @Produce json
+// @Param body body dto.SaveRuleDTO true "规则内容"
+// @Router /user/automation/rules [post]
+func CreateRule(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.SaveRuleDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	rule, err := automation.CreateRule(middleware.GetCurrentUserID(c), req)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, rule, "创建成功")
+}
+
+// @Summary 更新自动化规则
+// @Tags 用户自动任务
+// @Accept json
+// @Produce json
+// @Param id path int true "规则ID"
+// @Param body body dto.SaveRuleDTO true "规则内容"
+// @Router /user/automation/rules/{id} [put]
+func UpdateRule(c *gin.Context) {
+	ruleID, ok := parseRuleID(c)
+	if !ok {
+		return
+	}
+
+	req, err := common.ValidateRequest[dto.SaveRuleDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	rule, err := automation.UpdateRule(middleware.GetCurrentUserID(c), ruleID, req)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, rule, "更新成功")
+}
+
+// @Summary 启用或停用自动化规则
+// @Tags 用户自动任务
+// @Accept json
+// @Produce json
+// @Param id path int true "规则ID"
+// @Param body body dto.ToggleRuleDTO true "启用状态"
+// @Router /user/automation/rules/{id}/enabled [put]
+func ToggleRule(c *gin.Context) {
+	ruleID, ok := parseRuleID(c)
+	if !ok {
+		return
+	}
+
+	req, err := common.ValidateRequest[dto.ToggleRuleDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	if err := automation.SetRuleEnabled(middleware.GetCurrentUserID(c), ruleID, req.Enabled); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{"enabled": req.Enabled}, "更新成功")
+}
+
+// @Summary 删除自动化规则
+// @Tags 用户自动任务
+// @Produce json
+// @Param id path int true "规则ID"
+// @Router /user/automation/rules/{id} [delete]
+func DeleteRule(c *gin.Context) {
+	ruleID, ok := parseRuleID(c)
+	if !ok {
+		return
+	}
+
+	if err := automation.DeleteRule(middleware.GetCurrentUserID(c), ruleID); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, nil, "删除成功")
+}
+
+// @Summary 获取自动化规则执行日志
+// @Tags 用户自动任务
+// @Produce json
+// @Param id path int true "规则ID"
+// @Param status query string false "状态过滤"
+// @Param page query int false "页码" default(1)
+// @Param limit query int false "每页数量" default(20)
+// @Router /user/automation/rules/{id}/logs [get]
+func GetRuleLogs(c *gin.Context) {
+	ruleID, ok := parseRuleID(c)
+	if !ok {
+		return
+	}
+
+	query, err := common.ValidateRequest[dto.RuleLogQuery](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	result, err := automation.ListRuleLogs(middleware.GetCurrentUserID(c), ruleID, query)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, result, "获取成功")
+}
+
+// @Summary 试运行自动化规则
+// @Tags 用户自动任务
+// @Accept json
+// @Produce json
+// @Param body body dto.DryRunRuleDTO true "试运行参数"
+// @Success 200 {object} dto.DryRunResponse
+// @Router /user/automation/rules/dry-run [post]
+func DryRunRule(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.DryRunRuleDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	result, err := automation.DryRunRule(middleware.GetCurrentUserID(c), req)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, result, "试运行完成")
+}
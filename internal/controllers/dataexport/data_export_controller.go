@@ -0,0 +1,148 @@
+package dataexport
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+
+	"pixelpunk/internal/controllers/dataexport/dto"
+	"pixelpunk/internal/middleware"
+	"pixelpunk/internal/services/dataexport"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/logger"
+	"pixelpunk/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// forwardedHeader 标记由其他 api 进程转发的下载请求
+const forwardedHeader = "X-PixelPunk-Forwarded"
+
+// CreateDataExport 导出当前用户的全部数据，完成后通过消息通知
+func CreateDataExport(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+
+	export, err := dataexport.CreateExport(userID, userID)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, export, "数据导出已开始，完成后将通过消息通知您")
+}
+
+// ListDataExports 查询当前用户的数据导出记录
+func ListDataExports(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.DataExportQueryDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	exports, total, err := dataexport.ListExports(middleware.GetCurrentUserID(c), req.Page, req.Size)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{"items": exports, "total": total}, "获取成功")
+}
+
+// GetDataExport 查询当前用户的单个数据导出
+func GetDataExport(c *gin.Context) {
+	export, err := dataexport.GetExport(middleware.GetCurrentUserID(c), c.Param("export_id"))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, export, "获取成功")
+}
+
+// DownloadDataExport 下载导出的压缩包，只有数据所属的用户可以下载
+func DownloadDataExport(c *gin.Context) {
+	export, err := dataexport.GetExport(middleware.GetCurrentUserID(c), c.Param("export_id"))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+	archivePath, nodeURL, err := dataexport.ArchivePath(export)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+	if nodeURL != "" {
+		forwardArchiveDownload(c, nodeURL)
+		return
+	}
+
+	c.Header("Content-Disposition", utils.SetContentDispositionFilename(dataexport.ArchiveName(export)))
+	c.Header("Content-Type", "application/zip")
+	c.File(archivePath)
+}
+
+// forwardArchiveDownload 压缩包保存在生成它的 api 进程上，带着原请求的登录凭证转发给该进程
+// 已转发过的请求不再转发，避免进程之间的地址配置错误时循环转发
+func forwardArchiveDownload(c *gin.Context, nodeURL string) {
+	target, err := url.Parse(nodeURL)
+	if err != nil || c.GetHeader(forwardedHeader) != "" {
+		errors.HandleError(c, errors.New(errors.CodeFileNotFound, "导出文件不存在，请重新导出"))
+		return
+	}
+
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	director := proxy.Director
+	proxy.Director = func(req *http.Request) {
+		director(req)
+		req.Host = target.Host
+		req.Header.Set(forwardedHeader, "1")
+	}
+	proxy.ModifyResponse = func(resp *http.Response) error {
+		// CORS 等中间件已在本进程设置的响应头不再重复添加
+		for key := range c.Writer.Header() {
+			resp.Header.Del(key)
+		}
+		return nil
+	}
+	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		logger.Warn("转发导出文件下载失败: node=%s, %v", nodeURL, err)
+		errors.HandleError(c, errors.New(errors.CodeServiceUnavailable, "导出文件所在的节点暂时不可用，请稍后再试"))
+	}
+	proxy.ServeHTTP(c.Writer, c.Request)
+}
+
+// AdminCreateDataExport 管理员为指定用户导出数据，压缩包仍只能由该用户下载
+func AdminCreateDataExport(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		errors.HandleError(c, errors.New(errors.CodeInvalidParameter, "用户ID格式不正确"))
+		return
+	}
+
+	export, err := dataexport.CreateExport(uint(id), middleware.GetCurrentUserID(c))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, export, "数据导出已开始，完成后将通知该用户")
+}
+
+// AdminListDataExports 管理员查询数据导出记录
+func AdminListDataExports(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.AdminDataExportQueryDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	exports, total, err := dataexport.ListExports(req.UserID, req.Page, req.Size)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{"items": exports, "total": total}, "获取成功")
+}
@@ -0,0 +1,30 @@
+package dto
+
+// DataExportQueryDTO 数据导出列表查询DTO
+type DataExportQueryDTO struct {
+	Page int `form:"page" binding:"omitempty,min=1"`
+	Size int `form:"size" binding:"omitempty,min=1,max=100"`
+}
+
+func (d *DataExportQueryDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Page.min": "页码必须大于等于1",
+		"Size.min": "每页数量必须大于等于1",
+		"Size.max": "每页数量必须小于等于100",
+	}
+}
+
+// AdminDataExportQueryDTO 管理员数据导出列表查询DTO，user_id 为空时查询全部用户
+type AdminDataExportQueryDTO struct {
+	Page   int  `form:"page" binding:"omitempty,min=1"`
+	Size   int  `form:"size" binding:"omitempty,min=1,max=100"`
+	UserID uint `form:"user_id"`
+}
+
+func (d *AdminDataExportQueryDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Page.min": "页码必须大于等于1",
+		"Size.min": "每页数量必须大于等于1",
+		"Size.max": "每页数量必须小于等于100",
+	}
+}
@@ -39,6 +39,7 @@ type AdminGetFileListParams struct {
 	MaxHeight     int     `form:"max_height" json:"max_height"`
 	UserID        uint    `form:"user_id" json:"user_id"`
 	IsRecommended *bool   `form:"is_recommended" json:"is_recommended"`
+	dto.EXIFFilterQueryDTO
 }
 
 // AdminGetFileList 管理员获取文件列表
@@ -97,6 +98,7 @@ func AdminGetFileList(c *gin.Context) {
 		UserID:        params.UserID,
 		IsRecommended: params.IsRecommended,
 	}
+	applyEXIFFilters(&searchParams, params.EXIFFilterQueryDTO)
 
 	files, total, err := filesvc.AdminGetFileList(searchParams)
 	if err != nil {
@@ -10,6 +10,7 @@ type InitChunkedUploadDTO struct {
 	AccessLevel     string      `json:"access_level" binding:"omitempty,oneof=public private protected"`
 	Optimize        bool        `json:"optimize"`
 	WatermarkConfig interface{} `json:"watermark_config"`
+	ExifPolicy      string      `json:"exif_policy" binding:"omitempty,oneof=keep strip_gps strip_serial strip_all"`
 }
 
 func (d *InitChunkedUploadDTO) GetValidationMessages() map[string]string {
@@ -25,6 +26,7 @@ func (d *InitChunkedUploadDTO) GetValidationMessages() map[string]string {
 		"ChunkSize.min":      "分片大小不能小于1MB",
 		"ChunkSize.max":      "分片大小不能大于10MB",
 		"AccessLevel.oneof":  "访问级别必须是 public、private 或 protected",
+		"ExifPolicy.oneof":   "元数据策略必须是 keep、strip_gps、strip_serial 或 strip_all",
 	}
 }
 
@@ -0,0 +1,32 @@
+package dto
+
+// EXIFFilterQueryDTO 文件列表的 EXIF 筛选条件
+type EXIFFilterQueryDTO struct {
+	CameraMake     string  `form:"camera_make" json:"camera_make" binding:"omitempty,max=100"`
+	CameraModel    string  `form:"camera_model" json:"camera_model" binding:"omitempty,max=100"`
+	Lens           string  `form:"lens" json:"lens" binding:"omitempty,max=100"`
+	MinISO         int     `form:"min_iso" json:"min_iso" binding:"omitempty,min=0"`
+	MaxISO         int     `form:"max_iso" json:"max_iso" binding:"omitempty,min=0"`
+	MinFocalLength float64 `form:"min_focal_length" json:"min_focal_length" binding:"omitempty,min=0"`
+	MaxFocalLength float64 `form:"max_focal_length" json:"max_focal_length" binding:"omitempty,min=0"`
+	TakenFrom      string  `form:"taken_from" json:"taken_from" binding:"omitempty,datetime=2006-01-02"` // 拍摄日期起
+	TakenTo        string  `form:"taken_to" json:"taken_to" binding:"omitempty,datetime=2006-01-02"`     // 拍摄日期止（含当天）
+	Country        string  `form:"country" json:"country" binding:"omitempty,max=100"`                   // 拍摄地国家，代码或名称
+	City           string  `form:"city" json:"city" binding:"omitempty,max=100"`                         // 拍摄地城市
+}
+
+func (d *EXIFFilterQueryDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"CameraMake.max":     "相机制造商不能超过100个字符",
+		"CameraModel.max":    "相机型号不能超过100个字符",
+		"Lens.max":           "镜头型号不能超过100个字符",
+		"MinISO.min":         "ISO不能为负数",
+		"MaxISO.min":         "ISO不能为负数",
+		"MinFocalLength.min": "焦距不能为负数",
+		"MaxFocalLength.min": "焦距不能为负数",
+		"TakenFrom.datetime": "拍摄开始日期格式应为YYYY-MM-DD",
+		"TakenTo.datetime":   "拍摄结束日期格式应为YYYY-MM-DD",
+		"Country.max":        "国家不能超过100个字符",
+		"City.max":           "城市不能超过100个字符",
+	}
+}
@@ -0,0 +1,41 @@
+package dto
+
+// GeoQueryDTO 地图查询参数，矩形范围和圆形范围可以单独或同时使用
+type GeoQueryDTO struct {
+	EXIFFilterQueryDTO
+
+	MinLat   *float64 `form:"min_lat" json:"min_lat" binding:"omitempty,min=-90,max=90"`     // 矩形范围南边界
+	MinLon   *float64 `form:"min_lon" json:"min_lon" binding:"omitempty,min=-180,max=180"`   // 矩形范围西边界
+	MaxLat   *float64 `form:"max_lat" json:"max_lat" binding:"omitempty,min=-90,max=90"`     // 矩形范围北边界
+	MaxLon   *float64 `form:"max_lon" json:"max_lon" binding:"omitempty,min=-180,max=180"`   // 矩形范围东边界，小于西边界表示跨越经度180°
+	Lat      *float64 `form:"lat" json:"lat" binding:"omitempty,min=-90,max=90"`             // 圆心纬度
+	Lon      *float64 `form:"lon" json:"lon" binding:"omitempty,min=-180,max=180"`           // 圆心经度
+	RadiusKm float64  `form:"radius_km" json:"radius_km" binding:"omitempty,gt=0,max=20000"` // 半径（km）
+	Zoom     int      `form:"zoom" json:"zoom" binding:"omitempty,min=0,max=20"`             // 地图缩放级别，20 为不聚合
+	FolderID string   `form:"folder_id" json:"folder_id" binding:"omitempty"`                // 文件夹ID，仅限自己的文件
+}
+
+func (d *GeoQueryDTO) GetValidationMessages() map[string]string {
+	messages := d.EXIFFilterQueryDTO.GetValidationMessages()
+	for field, msg := range map[string]string{
+		"MinLat.min":   "纬度范围为-90到90",
+		"MinLat.max":   "纬度范围为-90到90",
+		"MaxLat.min":   "纬度范围为-90到90",
+		"MaxLat.max":   "纬度范围为-90到90",
+		"Lat.min":      "纬度范围为-90到90",
+		"Lat.max":      "纬度范围为-90到90",
+		"MinLon.min":   "经度范围为-180到180",
+		"MinLon.max":   "经度范围为-180到180",
+		"MaxLon.min":   "经度范围为-180到180",
+		"MaxLon.max":   "经度范围为-180到180",
+		"Lon.min":      "经度范围为-180到180",
+		"Lon.max":      "经度范围为-180到180",
+		"RadiusKm.gt":  "半径必须大于0",
+		"RadiusKm.max": "半径不能超过20000km",
+		"Zoom.min":     "缩放级别范围为0到20",
+		"Zoom.max":     "缩放级别范围为0到20",
+	} {
+		messages[field] = msg
+	}
+	return messages
+}
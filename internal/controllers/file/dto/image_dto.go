@@ -10,16 +10,18 @@ type UploadFileDTO struct {
 	Watermark          string `form:"watermark" json:"watermark"`
 	WebPEnabled        *bool  `form:"webp_enabled" json:"webp_enabled"`       // WebP转换开关（nil表示使用全局配置）
 	WebPQuality        *int   `form:"webp_quality" json:"webp_quality"`       // WebP转换质量（nil表示使用全局配置）
+	ExifPolicy         string `form:"exif_policy" json:"exif_policy" binding:"omitempty,oneof=keep strip_gps strip_serial strip_all"` // 元数据策略（为空时使用用户设置）
 	// 兼容旧参数（将逐步淘汰）
 	WatermarkEnabled bool   `form:"watermark_enabled" json:"watermark_enabled"`
-	WatermarkType    string `form:"watermark_type" json:"watermark_type" binding:"omitempty,oneof=file"`
+	WatermarkType    string `form:"watermark_type" json:"watermark_type" binding:"omitempty,oneof=file text"`
 	WatermarkConfig  string `form:"watermark_config" json:"watermark_config"`
 }
 
 func (d *UploadFileDTO) GetValidationMessages() map[string]string {
 	return map[string]string{
 		"AccessLevel.oneof":   "访问级别必须是 public、private 或 protected",
-		"WatermarkType.oneof": "水印类型必须是 file",
+		"WatermarkType.oneof": "水印类型必须是 file 或 text",
+		"ExifPolicy.oneof":    "元数据策略必须是 keep、strip_gps、strip_serial 或 strip_all",
 	}
 }
 
@@ -41,7 +43,7 @@ type FileListQueryDTO struct {
 	Page          int    `form:"page" binding:"omitempty,min=1"`
 	Size          int    `form:"size" binding:"omitempty,min=1,max=100"`
 	FolderID      string `form:"folder_id"`
-	Sort          string `form:"sort" binding:"omitempty,oneof=newest oldest name size width height quality nsfw_score"`
+	Sort          string `form:"sort" binding:"omitempty,oneof=newest oldest name size width height quality nsfw_score taken taken_oldest"`
 	AccessLevel   string `form:"access_level" binding:"omitempty,oneof=public private protected"`
 	Keyword       string `form:"keyword" binding:"omitempty,max=100"`
 	Tags          string `form:"tags"`           // 逗号分隔的标签字符串
@@ -52,17 +54,22 @@ type FileListQueryDTO struct {
 	MaxWidth      int    `form:"max_width"`
 	MinHeight     int    `form:"min_height"`
 	MaxHeight     int    `form:"max_height"`
+	EXIFFilterQueryDTO
 }
 
 func (d *FileListQueryDTO) GetValidationMessages() map[string]string {
-	return map[string]string{
+	messages := d.EXIFFilterQueryDTO.GetValidationMessages()
+	for field, msg := range map[string]string{
 		"Page.min":          "页码必须大于等于1",
 		"Size.min":          "每页数量必须大于等于1",
 		"Size.max":          "每页数量必须小于等于100",
-		"Sort.oneof":        "排序方式必须是 newest、oldest、name、size、width、height、quality 或 nsfw_score",
+		"Sort.oneof":        "排序方式必须是 newest、oldest、name、size、width、height、quality、nsfw_score、taken 或 taken_oldest",
 		"AccessLevel.oneof": "访问级别必须是 public、private 或 protected",
 		"Keyword.max":       "搜索关键字不能超过100个字符",
+	} {
+		messages[field] = msg
 	}
+	return messages
 }
 
 // FileStatsQueryDTO 文件统计数据查询DTO
@@ -171,7 +178,7 @@ type InstantUploadDTO struct {
 	StorageDuration  string `json:"storage_duration"`
 	Watermark        string `json:"watermark"`
 	WatermarkEnabled bool   `json:"watermark_enabled"`
-	WatermarkType    string `json:"watermark_type" binding:"omitempty,oneof=file"`
+	WatermarkType    string `json:"watermark_type" binding:"omitempty,oneof=file text"`
 	WatermarkConfig  string `json:"watermark_config"`
 }
 
@@ -184,7 +191,7 @@ func (d *InstantUploadDTO) GetValidationMessages() map[string]string {
 		"FileSize.required":   "文件大小不能为空",
 		"FileSize.min":        "文件大小必须大于0",
 		"AccessLevel.oneof":   "访问级别必须是 public、private 或 protected",
-		"WatermarkType.oneof": "水印类型必须是 file",
+		"WatermarkType.oneof": "水印类型必须是 file 或 text",
 	}
 }
 
@@ -196,17 +203,19 @@ type GuestUploadDTO struct {
 	Fingerprint      string `form:"fingerprint" json:"fingerprint"`
 	Watermark        string `form:"watermark" json:"watermark"`
 	WatermarkEnabled bool   `form:"watermark_enabled" json:"watermark_enabled"`
-	WatermarkType    string `form:"watermark_type" json:"watermark_type" binding:"omitempty,oneof=file"`
+	WatermarkType    string `form:"watermark_type" json:"watermark_type" binding:"omitempty,oneof=file text"`
 	WatermarkConfig  string `form:"watermark_config" json:"watermark_config"`
 	WebPEnabled      *bool  `form:"webp_enabled" json:"webp_enabled"`   // WebP转换开关（nil表示使用全局配置）
 	WebPQuality      *int   `form:"webp_quality" json:"webp_quality"`   // WebP转换质量（nil表示使用全局配置）
+	ExifPolicy       string `form:"exif_policy" json:"exif_policy" binding:"omitempty,oneof=keep strip_gps strip_serial strip_all"`
 }
 
 func (d *GuestUploadDTO) GetValidationMessages() map[string]string {
 	return map[string]string{
 		"AccessLevel.oneof":        "访问级别必须是 public、private 或 protected",
 		"StorageDuration.required": "游客上传必须指定存储时长",
-		"WatermarkType.oneof":      "水印类型必须是 file",
+		"WatermarkType.oneof":      "水印类型必须是 file 或 text",
+		"ExifPolicy.oneof":         "元数据策略必须是 keep、strip_gps、strip_serial 或 strip_all",
 	}
 }
 
@@ -0,0 +1,72 @@
+package dto
+
+// CreateDirectoryImportDTO 管理员导入服务器目录或压缩包DTO
+type CreateDirectoryImportDTO struct {
+	Path          string `json:"path" binding:"required,max=1024"`
+	UserID        uint   `json:"user_id"`                                 // 文件归属的用户，为空时为当前管理员
+	FolderPath    string `json:"folder_path" binding:"omitempty,max=255"` // 目标根文件夹路径
+	AccessLevel   string `json:"access_level" binding:"omitempty,oneof=public private protected"`
+	Optimize      bool   `json:"optimize"`
+	ExifPolicy    string `json:"exif_policy" binding:"omitempty,oneof=keep strip_gps strip_serial strip_all"`
+	PreserveMtime bool   `json:"preserve_mtime"` // 使用源文件修改时间作为创建时间
+}
+
+func (d *CreateDirectoryImportDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Path.required":     "导入路径不能为空",
+		"Path.max":          "导入路径不能超过1024个字符",
+		"FolderPath.max":    "文件夹路径不能超过255个字符",
+		"AccessLevel.oneof": "访问级别必须是 public、private 或 protected",
+		"ExifPolicy.oneof":  "元数据策略必须是 keep、strip_gps、strip_serial 或 strip_all",
+	}
+}
+
+// CreateArchiveImportDTO 管理员上传压缩包导入DTO（multipart表单，压缩包字段为 file）
+type CreateArchiveImportDTO struct {
+	UserID        uint   `form:"user_id"`
+	FolderPath    string `form:"folder_path" binding:"omitempty,max=255"`
+	AccessLevel   string `form:"access_level" binding:"omitempty,oneof=public private protected"`
+	Optimize      bool   `form:"optimize"`
+	ExifPolicy    string `form:"exif_policy" binding:"omitempty,oneof=keep strip_gps strip_serial strip_all"`
+	PreserveMtime bool   `form:"preserve_mtime"`
+}
+
+func (d *CreateArchiveImportDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"FolderPath.max":    "文件夹路径不能超过255个字符",
+		"AccessLevel.oneof": "访问级别必须是 public、private 或 protected",
+		"ExifPolicy.oneof":  "元数据策略必须是 keep、strip_gps、strip_serial 或 strip_all",
+	}
+}
+
+// CreateHostImportDTO 管理员导入其他图床导出数据DTO
+type CreateHostImportDTO struct {
+	Format          string `json:"format" binding:"required,oneof=lsky chevereto manifest"`
+	Path            string `json:"path" binding:"required,max=1024"`          // SQL 导出文件或清单文件
+	FilesDir        string `json:"files_dir" binding:"omitempty,max=1024"`    // 原图床的文件目录
+	BaseURL         string `json:"base_url" binding:"omitempty,max=1024,url"` // 原图床的访问地址，没有文件目录时从这里下载
+	TablePrefix     string `json:"table_prefix" binding:"omitempty,max=64"`   // 数据表前缀
+	UserID          uint   `json:"user_id"`                                   // 不创建用户时的文件归属，为空时为当前管理员
+	CreateUsers     bool   `json:"create_users"`                              // 按原系统的用户创建账号
+	FolderPath      string `json:"folder_path" binding:"omitempty,max=255"`   // 目标根文件夹路径
+	AlbumsAsFolders bool   `json:"albums_as_folders"`                         // 原相册映射为文件夹
+	AccessLevel     string `json:"access_level" binding:"omitempty,oneof=public private protected"`
+	Optimize        bool   `json:"optimize"`
+	ExifPolicy      string `json:"exif_policy" binding:"omitempty,oneof=keep strip_gps strip_serial strip_all"`
+}
+
+func (d *CreateHostImportDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Format.required":   "导入格式不能为空",
+		"Format.oneof":      "导入格式必须是 lsky、chevereto 或 manifest",
+		"Path.required":     "导出文件路径不能为空",
+		"Path.max":          "导出文件路径不能超过1024个字符",
+		"FilesDir.max":      "文件目录不能超过1024个字符",
+		"BaseURL.max":       "访问地址不能超过1024个字符",
+		"BaseURL.url":       "访问地址格式无效",
+		"TablePrefix.max":   "数据表前缀不能超过64个字符",
+		"FolderPath.max":    "文件夹路径不能超过255个字符",
+		"AccessLevel.oneof": "访问级别必须是 public、private 或 protected",
+		"ExifPolicy.oneof":  "元数据策略必须是 keep、strip_gps、strip_serial 或 strip_all",
+	}
+}
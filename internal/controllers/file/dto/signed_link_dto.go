@@ -0,0 +1,53 @@
+package dto
+
+// MintSignedLinksDTO 批量签发签名链接DTO
+type MintSignedLinksDTO struct {
+	FileIDs      []string `json:"file_ids" binding:"required,min=1,max=100"`
+	Kind         string   `json:"kind" binding:"omitempty,oneof=f t s"`                // f:原图 t:缩略图 s:短链
+	ExpiresIn    int      `json:"expires_in" binding:"omitempty,min=1"`                // 有效期(秒)
+	BindIP       string   `json:"bind_ip" binding:"omitempty,max=64"`                  // 绑定IP或CIDR
+	MaxDownloads int      `json:"max_downloads" binding:"omitempty,min=0,max=1000000"` // 最大访问次数
+	Width        int      `json:"width" binding:"omitempty,min=1,max=4096"`            // 变换宽度
+	Height       int      `json:"height" binding:"omitempty,min=1,max=4096"`           // 变换高度
+	Quality      int      `json:"quality" binding:"omitempty,min=1,max=100"`           // 变换质量
+	Format       string   `json:"format" binding:"omitempty,oneof=jpeg jpg png"`       // 变换输出格式
+	Note         string   `json:"note" binding:"omitempty,max=255"`                    // 备注
+}
+
+func (d *MintSignedLinksDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"FileIDs.required": "文件ID列表不能为空",
+		"FileIDs.min":      "文件ID列表不能为空",
+		"FileIDs.max":      "单次最多签发100个链接",
+		"Kind.oneof":       "链接类型必须是f、t或s",
+		"ExpiresIn.min":    "有效期必须大于0秒",
+		"BindIP.max":       "绑定IP不能超过64个字符",
+		"MaxDownloads.min": "最大访问次数不能为负数",
+		"MaxDownloads.max": "最大访问次数过大",
+		"Width.min":        "宽度必须大于0",
+		"Width.max":        "宽度不能超过4096",
+		"Height.min":       "高度必须大于0",
+		"Height.max":       "高度不能超过4096",
+		"Quality.min":      "质量必须在1-100之间",
+		"Quality.max":      "质量必须在1-100之间",
+		"Format.oneof":     "输出格式仅支持jpeg或png",
+		"Note.max":         "备注不能超过255个字符",
+	}
+}
+
+// SignedLinkQueryDTO 签名链接列表查询DTO
+type SignedLinkQueryDTO struct {
+	Page   int    `form:"page" binding:"omitempty,min=1"`
+	Size   int    `form:"size" binding:"omitempty,min=1,max=100"`
+	FileID string `form:"file_id" binding:"omitempty,max=32"`
+	Active bool   `form:"active"` // 仅返回未过期且未吊销的链接
+}
+
+func (d *SignedLinkQueryDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Page.min":   "页码必须大于等于1",
+		"Size.min":   "每页数量必须大于等于1",
+		"Size.max":   "每页数量必须小于等于100",
+		"FileID.max": "文件ID格式无效",
+	}
+}
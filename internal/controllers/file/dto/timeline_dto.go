@@ -0,0 +1,56 @@
+package dto
+
+// TimelineQueryDTO 时间线统计参数，可限定在某一年或某一月内
+type TimelineQueryDTO struct {
+	Granularity string `form:"granularity" json:"granularity" binding:"omitempty,oneof=year month day"` // 聚合粒度，默认 month
+	Year        int    `form:"year" json:"year" binding:"omitempty,min=1,max=9999"`
+	Month       int    `form:"month" json:"month" binding:"omitempty,min=1,max=12"`
+	FolderID    string `form:"folder_id" json:"folder_id" binding:"omitempty"` // 文件夹ID，仅限自己的文件
+}
+
+func (d *TimelineQueryDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Granularity.oneof": "聚合粒度必须是 year、month 或 day",
+		"Year.min":          "年份范围为1到9999",
+		"Year.max":          "年份范围为1到9999",
+		"Month.min":         "月份范围为1到12",
+		"Month.max":         "月份范围为1到12",
+	}
+}
+
+// TimelineFilesQueryDTO 按拍摄日期分页浏览文件，年月日都为空时浏览全部
+type TimelineFilesQueryDTO struct {
+	Year     int    `form:"year" json:"year" binding:"omitempty,min=1,max=9999"`
+	Month    int    `form:"month" json:"month" binding:"omitempty,min=1,max=12"`
+	Day      int    `form:"day" json:"day" binding:"omitempty,min=1,max=31"`
+	Page     int    `form:"page" json:"page" binding:"omitempty,min=1"`
+	Size     int    `form:"size" json:"size" binding:"omitempty,min=1,max=100"`
+	Order    string `form:"order" json:"order" binding:"omitempty,oneof=desc asc"` // 拍摄时间排序，默认 desc
+	FolderID string `form:"folder_id" json:"folder_id" binding:"omitempty"`        // 文件夹ID，仅限自己的文件
+}
+
+func (d *TimelineFilesQueryDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Year.min":    "年份范围为1到9999",
+		"Year.max":    "年份范围为1到9999",
+		"Month.min":   "月份范围为1到12",
+		"Month.max":   "月份范围为1到12",
+		"Day.min":     "日期范围为1到31",
+		"Day.max":     "日期范围为1到31",
+		"Page.min":    "页码必须大于等于1",
+		"Size.min":    "每页数量必须大于等于1",
+		"Size.max":    "每页数量必须小于等于100",
+		"Order.oneof": "排序方式必须是 desc 或 asc",
+	}
+}
+
+// OnThisDayQueryDTO 那年今日查询参数
+type OnThisDayQueryDTO struct {
+	Date string `form:"date" json:"date" binding:"omitempty,datetime=2006-01-02"` // 参照日期，默认今天
+}
+
+func (d *OnThisDayQueryDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Date.datetime": "日期格式应为YYYY-MM-DD",
+	}
+}
@@ -0,0 +1,74 @@
+package dto
+
+// ImportURLDTO 通过远程地址导入单个文件DTO
+type ImportURLDTO struct {
+	URL         string `json:"url" binding:"required,max=2048"`
+	FolderID    string `json:"folder_id" binding:"omitempty,max=32"`
+	FilePath    string `json:"file_path" binding:"omitempty,max=255"` // 按路径创建并使用文件夹，优先于folder_id
+	AccessLevel string `json:"access_level" binding:"omitempty,oneof=public private protected"`
+	Optimize    bool   `json:"optimize"`
+	ExifPolicy  string `json:"exif_policy" binding:"omitempty,oneof=keep strip_gps strip_serial strip_all"` // 元数据策略（为空时使用用户设置）
+}
+
+func (d *ImportURLDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"URL.required":      "远程地址不能为空",
+		"URL.max":           "远程地址不能超过2048个字符",
+		"FolderID.max":      "文件夹ID格式无效",
+		"FilePath.max":      "文件路径不能超过255个字符",
+		"AccessLevel.oneof": "访问级别必须是 public、private 或 protected",
+		"ExifPolicy.oneof":  "元数据策略必须是 keep、strip_gps、strip_serial 或 strip_all",
+	}
+}
+
+// CreateURLImportJobDTO 批量导入远程地址DTO
+type CreateURLImportJobDTO struct {
+	URLs        []string `json:"urls" binding:"required,min=1,max=200"`
+	FolderID    string   `json:"folder_id" binding:"omitempty,max=32"`
+	FilePath    string   `json:"file_path" binding:"omitempty,max=255"`
+	AccessLevel string   `json:"access_level" binding:"omitempty,oneof=public private protected"`
+	Optimize    bool     `json:"optimize"`
+	ExifPolicy  string   `json:"exif_policy" binding:"omitempty,oneof=keep strip_gps strip_serial strip_all"`
+}
+
+func (d *CreateURLImportJobDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"URLs.required":     "远程地址列表不能为空",
+		"URLs.min":          "远程地址列表不能为空",
+		"URLs.max":          "单个任务最多导入200个地址",
+		"FolderID.max":      "文件夹ID格式无效",
+		"FilePath.max":      "文件路径不能超过255个字符",
+		"AccessLevel.oneof": "访问级别必须是 public、private 或 protected",
+		"ExifPolicy.oneof":  "元数据策略必须是 keep、strip_gps、strip_serial 或 strip_all",
+	}
+}
+
+// ImportJobQueryDTO 导入任务列表查询DTO
+type ImportJobQueryDTO struct {
+	Page int `form:"page" binding:"omitempty,min=1"`
+	Size int `form:"size" binding:"omitempty,min=1,max=100"`
+}
+
+func (d *ImportJobQueryDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Page.min": "页码必须大于等于1",
+		"Size.min": "每页数量必须大于等于1",
+		"Size.max": "每页数量必须小于等于100",
+	}
+}
+
+// ImportJobItemQueryDTO 导入条目查询DTO
+type ImportJobItemQueryDTO struct {
+	Page   int    `form:"page" binding:"omitempty,min=1"`
+	Size   int    `form:"size" binding:"omitempty,min=1,max=100"`
+	Status string `form:"status" binding:"omitempty,oneof=pending success failed skipped"`
+}
+
+func (d *ImportJobItemQueryDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Page.min":     "页码必须大于等于1",
+		"Size.min":     "每页数量必须大于等于1",
+		"Size.max":     "每页数量必须小于等于100",
+		"Status.oneof": "状态必须是 pending、success、failed 或 skipped",
+	}
+}
@@ -0,0 +1,150 @@
+package file
+
+import (
+	"strconv"
+
+	"pixelpunk/internal/controllers/file/dto"
+	"pixelpunk/internal/middleware"
+	filesvc "pixelpunk/internal/services/file"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetGeoFiles 获取自己带拍摄位置的文件，按缩放级别聚合
+func GetGeoFiles(c *gin.Context) {
+	params, ok := bindGeoQuery(c, filesvc.AdminFileSearchParams{UserID: middleware.GetCurrentUserID(c)})
+	if !ok {
+		return
+	}
+	respondGeoFiles(c, params)
+}
+
+// GetGeoPlaces 获取自己的文件拍摄地点统计
+func GetGeoPlaces(c *gin.Context) {
+	params, ok := bindGeoQuery(c, filesvc.AdminFileSearchParams{UserID: middleware.GetCurrentUserID(c)})
+	if !ok {
+		return
+	}
+	respondGeoPlaces(c, params)
+}
+
+// GetPublicGeoFiles 获取推荐文件的地图聚合，只包含允许他人查看位置的文件
+func GetPublicGeoFiles(c *gin.Context) {
+	params, ok := bindGeoQuery(c, publicGeoScope())
+	if !ok {
+		return
+	}
+	respondGeoFiles(c, params)
+}
+
+// GetPublicGeoPlaces 获取推荐文件的拍摄地点统计
+func GetPublicGeoPlaces(c *gin.Context) {
+	params, ok := bindGeoQuery(c, publicGeoScope())
+	if !ok {
+		return
+	}
+	respondGeoPlaces(c, params)
+}
+
+// GetAuthorGeoFiles 获取作者公开文件的地图聚合
+func GetAuthorGeoFiles(c *gin.Context) {
+	scope, ok := authorScope(c)
+	if !ok {
+		return
+	}
+	params, ok := bindGeoQuery(c, scope)
+	if !ok {
+		return
+	}
+	respondGeoFiles(c, params)
+}
+
+// GetAuthorGeoPlaces 获取作者公开文件的拍摄地点统计
+func GetAuthorGeoPlaces(c *gin.Context) {
+	scope, ok := authorScope(c)
+	if !ok {
+		return
+	}
+	params, ok := bindGeoQuery(c, scope)
+	if !ok {
+		return
+	}
+	respondGeoPlaces(c, params)
+}
+
+func publicGeoScope() filesvc.AdminFileSearchParams {
+	isRecommended := true
+	return filesvc.AdminFileSearchParams{AccessLevel: "public", IsRecommended: &isRecommended, HideStrippedEXIF: true}
+}
+
+func authorScope(c *gin.Context) (filesvc.AdminFileSearchParams, bool) {
+	authorID, err := strconv.ParseUint(c.Param("author_id"), 10, 32)
+	if err != nil {
+		errors.HandleError(c, errors.New(errors.CodeInvalidParameter, "无效的作者ID"))
+		return filesvc.AdminFileSearchParams{}, false
+	}
+	scope, err := filesvc.AuthorPublicScope(uint(authorID))
+	if err != nil {
+		errors.HandleError(c, err)
+		return filesvc.AdminFileSearchParams{}, false
+	}
+	return scope, true
+}
+
+// bindGeoQuery 解析地图查询参数，scope 决定文件范围，只有自己的文件可以按文件夹筛选
+func bindGeoQuery(c *gin.Context, scope filesvc.AdminFileSearchParams) (filesvc.GeoSearchParams, bool) {
+	req, err := common.ValidateRequest[dto.GeoQueryDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return filesvc.GeoSearchParams{}, false
+	}
+
+	params := filesvc.GeoSearchParams{AdminFileSearchParams: scope, Zoom: req.Zoom}
+	if !scope.HideStrippedEXIF {
+		params.FolderID = req.FolderID
+	}
+	applyEXIFFilters(&params.AdminFileSearchParams, req.EXIFFilterQueryDTO)
+
+	if req.MinLat != nil || req.MinLon != nil || req.MaxLat != nil || req.MaxLon != nil {
+		if req.MinLat == nil || req.MinLon == nil || req.MaxLat == nil || req.MaxLon == nil {
+			errors.HandleError(c, errors.New(errors.CodeInvalidParameter, "矩形范围需要同时提供 min_lat、min_lon、max_lat、max_lon"))
+			return params, false
+		}
+		if *req.MinLat > *req.MaxLat {
+			errors.HandleError(c, errors.New(errors.CodeInvalidParameter, "min_lat 不能大于 max_lat"))
+			return params, false
+		}
+		params.HasBounds = true
+		params.MinLat, params.MinLon, params.MaxLat, params.MaxLon = *req.MinLat, *req.MinLon, *req.MaxLat, *req.MaxLon
+	}
+
+	if req.Lat != nil || req.Lon != nil || req.RadiusKm > 0 {
+		if req.Lat == nil || req.Lon == nil || req.RadiusKm <= 0 {
+			errors.HandleError(c, errors.New(errors.CodeInvalidParameter, "圆形范围需要同时提供 lat、lon、radius_km"))
+			return params, false
+		}
+		params.HasRadius = true
+		params.Latitude, params.Longitude, params.RadiusKm = *req.Lat, *req.Lon, req.RadiusKm
+	}
+	return params, true
+}
+
+func respondGeoFiles(c *gin.Context, params filesvc.GeoSearchParams) {
+	result, err := filesvc.SearchGeoFiles(params)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+	errors.ResponseSuccess(c, result, "获取地图数据成功")
+}
+
+func respondGeoPlaces(c *gin.Context, params filesvc.GeoSearchParams) {
+	places, err := filesvc.ListGeoPlaces(params)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+	errors.ResponseSuccess(c, places, "获取拍摄地点成功")
+}
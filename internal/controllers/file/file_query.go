@@ -6,10 +6,12 @@ import (
 
 	"pixelpunk/internal/controllers/file/dto"
 	"pixelpunk/internal/middleware"
+	"pixelpunk/internal/models"
 	filesvc "pixelpunk/internal/services/file"
 	setting "pixelpunk/internal/services/setting"
 	"pixelpunk/pkg/common"
 	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/geocode"
 
 	"github.com/gin-gonic/gin"
 )
@@ -66,6 +68,7 @@ func GetFileList(c *gin.Context) {
 		MaxHeight:     req.MaxHeight,
 		UserID:        userID, // 设置为当前用户ID，限制只查询该用户的文件
 	}
+	applyEXIFFilters(&searchParams, req.EXIFFilterQueryDTO)
 
 	if req.FolderID != "" {
 		searchParams.FolderID = req.FolderID
@@ -94,6 +97,31 @@ func GetFileList(c *gin.Context) {
 	errors.ResponseSuccess(c, data, "获取成功")
 }
 
+// applyEXIFFilters 将 EXIF 筛选条件写入查询参数，拍摄日期按本地时区解析
+func applyEXIFFilters(params *filesvc.AdminFileSearchParams, filter dto.EXIFFilterQueryDTO) {
+	params.CameraMake = strings.TrimSpace(filter.CameraMake)
+	params.CameraModel = strings.TrimSpace(filter.CameraModel)
+	params.LensModel = strings.TrimSpace(filter.Lens)
+	params.MinISO = filter.MinISO
+	params.MaxISO = filter.MaxISO
+	params.MinFocalLength = filter.MinFocalLength
+	params.MaxFocalLength = filter.MaxFocalLength
+	if t, err := time.ParseInLocation("2006-01-02", filter.TakenFrom, time.Local); err == nil {
+		params.TakenFrom = &t
+	}
+	if t, err := time.ParseInLocation("2006-01-02", filter.TakenTo, time.Local); err == nil {
+		end := t.AddDate(0, 0, 1)
+		params.TakenTo = &end
+	}
+	if country := strings.TrimSpace(filter.Country); country != "" {
+		if c, ok := geocode.LookupCountry(country); ok {
+			country = c.Code
+		}
+		params.GeoCountry = strings.ToUpper(country)
+	}
+	params.GeoCity = geocode.NormalizeCity(filter.City)
+}
+
 func GetFileDetail(c *gin.Context) {
 	userID := middleware.GetCurrentUserID(c)
 
@@ -111,6 +139,28 @@ func GetFileDetail(c *gin.Context) {
 
 	errors.ResponseSuccess(c, imgInfo, "获取成功")
 }
+
+// GetFileEXIF 获取文件的 EXIF 元数据，非所有者按访问级别和元数据策略隐藏敏感信息
+func GetFileEXIF(c *gin.Context) {
+	fileObj, exists := c.Get("file_info")
+	if !exists {
+		errors.HandleError(c, errors.New(errors.CodeFileNotFound, "文件信息获取失败"))
+		return
+	}
+	file, ok := fileObj.(models.File)
+	if !ok {
+		errors.HandleError(c, errors.New(errors.CodeFileNotFound, "文件信息无效"))
+		return
+	}
+
+	exifInfo, err := filesvc.GetVisibleFileEXIF(file, middleware.GetCurrentUserID(c), middleware.IsCurrentUserAdmin(c))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+	errors.ResponseSuccess(c, gin.H{"exif_info": exifInfo}, "获取成功")
+}
+
 func GetFileStats(c *gin.Context) {
 	userID := middleware.GetCurrentUserID(c)
 
@@ -199,6 +249,8 @@ func GetRecommendedFileList(c *gin.Context) {
 		AccessLevel:   "public",
 		IsRecommended: &isRecommended,
 	}
+	applyEXIFFilters(&searchParams, params.EXIFFilterQueryDTO)
+	searchParams.HideStrippedEXIF = true
 
 	files, total, err := filesvc.AdminGetFileList(searchParams)
 	if err != nil {
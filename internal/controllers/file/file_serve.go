@@ -71,6 +71,11 @@ func ServeFile(c *gin.Context) {
 		proxyResp := result.(*filesvc.ProxyResponse)
 		defer proxyResp.Content.Close()
 
+		if !forceThumbnail && fileInfo.IsVideo() {
+			serveVideoProxy(c, fileInfo, proxyResp)
+			return
+		}
+
 		// 设置Content-Type
 		c.Header("Content-Type", proxyResp.ContentType)
 		// 设置Content-Length以支持真实下载进度
@@ -235,8 +240,23 @@ func DownloadFile(c *gin.Context) {
 		}
 	}
 
+	// 非所有者下载时同样叠加访问时水印，所有者和管理员下载原图
+	watermarkConfig := ""
+	if currentUserID != file.UserID && !middleware.IsCurrentUserAdmin(c) {
+		watermarkConfig = middleware.ResolveServeWatermark(file, "", isThumb, false)
+	}
+
 	// 根据quality参数获取相应的文件文件
-	result, isLocal, isProxy, err := filesvc.ServeFile(file, isThumb)
+	var (
+		result           interface{}
+		isLocal, isProxy bool
+		err              error
+	)
+	if watermarkConfig != "" {
+		result, isLocal, isProxy, err = filesvc.ServeWatermarkedFile(file, isThumb, watermarkConfig)
+	} else {
+		result, isLocal, isProxy, err = filesvc.ServeFile(file, isThumb)
+	}
 	if err != nil {
 		errors.HandleError(c, err)
 		return
@@ -271,6 +291,14 @@ func DownloadFile(c *gin.Context) {
 	case isLocal:
 		filePath := result.(string)
 
+		// 带水印的缓存文件格式可能与原图不同，按内容设置Content-Type
+		if watermarkConfig != "" {
+			if fileData, err := os.ReadFile(filePath); err == nil {
+				c.Data(http.StatusOK, http.DetectContentType(fileData), fileData)
+				return
+			}
+		}
+
 		// 检查文件是否为ASCII格式，如果是则转换后返回
 		if fileData, err := os.ReadFile(filePath); err == nil {
 			// 检查是否为真正的ASCII数组格式 (如 "[60, 63, 120, ...]")
@@ -303,6 +331,10 @@ func DownloadFile(c *gin.Context) {
 	case isProxy:
 		proxyResp := result.(*filesvc.ProxyResponse)
 		defer proxyResp.Content.Close()
+		if file.IsVideo() {
+			serveVideoProxy(c, file, proxyResp)
+			return
+		}
 		// 设置Content-Length以支持真实下载进度
 		c.Header("Content-Length", strconv.FormatInt(file.Size, 10))
 		c.Status(http.StatusOK)
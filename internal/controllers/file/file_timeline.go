@@ -0,0 +1,172 @@
+package file
+
+import (
+	"time"
+
+	"pixelpunk/internal/controllers/file/dto"
+	"pixelpunk/internal/middleware"
+	"pixelpunk/internal/services/author"
+	filesvc "pixelpunk/internal/services/file"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetTimeline 按拍摄日期统计自己的文件数量
+func GetTimeline(c *gin.Context) {
+	respondTimeline(c, filesvc.AdminFileSearchParams{UserID: middleware.GetCurrentUserID(c)})
+}
+
+// GetTimelineFiles 按拍摄日期分页浏览自己的文件
+func GetTimelineFiles(c *gin.Context) {
+	respondTimelineFiles(c, filesvc.AdminFileSearchParams{UserID: middleware.GetCurrentUserID(c)})
+}
+
+// GetAuthorTimeline 按拍摄日期统计作者的公开文件数量
+func GetAuthorTimeline(c *gin.Context) {
+	scope, ok := authorScope(c)
+	if !ok {
+		return
+	}
+	respondTimeline(c, scope)
+}
+
+// GetAuthorTimelineFiles 按拍摄日期分页浏览作者的公开文件，返回与作者主页相同的公开文件信息
+func GetAuthorTimelineFiles(c *gin.Context) {
+	scope, ok := authorScope(c)
+	if !ok {
+		return
+	}
+	params, ok := bindTimelineFiles(c, scope)
+	if !ok {
+		return
+	}
+
+	files, total, err := filesvc.SearchFiles(params)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+	errors.ResponseSuccess(c, gin.H{
+		"items":      author.BuildFileInfos(files),
+		"pagination": timelinePagination(total, params.Page, params.Size),
+	}, "获取成功")
+}
+
+// GetOnThisDay 那年今日：往年同一天拍摄的自己的文件
+func GetOnThisDay(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.OnThisDayQueryDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	date := time.Now()
+	if req.Date != "" {
+		date, _ = time.ParseInLocation("2006-01-02", req.Date, time.Local)
+	}
+
+	groups, err := filesvc.GetOnThisDay(filesvc.AdminFileSearchParams{UserID: middleware.GetCurrentUserID(c)}, date)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+	errors.ResponseSuccess(c, gin.H{
+		"date":   date.Format("2006-01-02"),
+		"groups": groups,
+	}, "获取那年今日成功")
+}
+
+// respondTimeline 按 scope 统计时间线，只有自己的文件可以按文件夹筛选
+func respondTimeline(c *gin.Context, scope filesvc.AdminFileSearchParams) {
+	req, err := common.ValidateRequest[dto.TimelineQueryDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	params := scope
+	if scope.AccessLevel == "" {
+		params.FolderID = req.FolderID
+	}
+	if err := filesvc.ApplyTakenDate(&params, req.Year, req.Month, 0); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	granularity := req.Granularity
+	if granularity == "" {
+		granularity = filesvc.TimelineMonth
+	}
+	buckets, err := filesvc.GetTimeline(params, granularity)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+	errors.ResponseSuccess(c, gin.H{
+		"granularity": granularity,
+		"buckets":     buckets,
+	}, "获取时间线成功")
+}
+
+// bindTimelineFiles 解析时间线文件查询参数，只有自己的文件可以按文件夹筛选
+func bindTimelineFiles(c *gin.Context, scope filesvc.AdminFileSearchParams) (filesvc.AdminFileSearchParams, bool) {
+	req, err := common.ValidateRequest[dto.TimelineFilesQueryDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return scope, false
+	}
+
+	params := scope
+	if scope.AccessLevel == "" {
+		params.FolderID = req.FolderID
+	}
+	if err := filesvc.ApplyTakenDate(&params, req.Year, req.Month, req.Day); err != nil {
+		errors.HandleError(c, err)
+		return scope, false
+	}
+	params.Sort = filesvc.TimelineSort(req.Order)
+	params.Page, params.Size = timelinePage(req.Page, req.Size)
+	return params, true
+}
+
+func respondTimelineFiles(c *gin.Context, scope filesvc.AdminFileSearchParams) {
+	params, ok := bindTimelineFiles(c, scope)
+	if !ok {
+		return
+	}
+
+	files, total, err := filesvc.AdminGetFileList(params)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+	if files == nil {
+		files = []filesvc.AdminFileDetailResponse{}
+	}
+	errors.ResponseSuccess(c, gin.H{
+		"items":      files,
+		"pagination": timelinePagination(total, params.Page, params.Size),
+	}, "获取成功")
+}
+
+// timelinePage 时间线分页参数默认第1页、每页20条
+func timelinePage(page, size int) (int, int) {
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 {
+		size = 20
+	}
+	return page, size
+}
+
+func timelinePagination(total int64, page, size int) gin.H {
+	return gin.H{
+		"total":        total,
+		"size":         size,
+		"current_page": page,
+		"last_page":    (total + int64(size) - 1) / int64(size),
+	}
+}
@@ -2,9 +2,12 @@ package file
 
 import (
 	"io"
+	"pixelpunk/internal/middleware"
 	"pixelpunk/internal/models"
 	filesvc "pixelpunk/internal/services/file"
 	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/utils"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -58,17 +61,50 @@ func ServeFileByShortURL(c *gin.Context) {
 }
 
 func serveFileByInfo(c *gin.Context, fileInfo models.File, isThumb bool) {
-	result, isLocalPath, isProxy, err := filesvc.ServeFile(fileInfo, isThumb)
+	var (
+		result               interface{}
+		isLocalPath, isProxy bool
+		err                  error
+	)
+	cacheControl := "public, max-age=2592000, immutable"
+	watermarkConfig := c.GetString(middleware.ServeWatermarkKey)
+
+	// 签名链接有过期、吊销与次数限制，响应不允许被缓存
+	if link, ok := c.Get("signed_link"); ok {
+		result, isLocalPath, isProxy, err = filesvc.ServeSignedFile(fileInfo, isThumb, link.(*models.SignedLink), watermarkConfig)
+		cacheControl = "private, no-store"
+	} else if watermarkConfig != "" {
+		// 同一地址对所有者返回原图，带水印的响应不允许共享缓存
+		result, isLocalPath, isProxy, err = filesvc.ServeWatermarkedFile(fileInfo, isThumb, watermarkConfig)
+		cacheControl = "private, max-age=3600"
+	} else {
+		result, isLocalPath, isProxy, err = filesvc.ServeFile(fileInfo, isThumb)
+		if c.GetBool(middleware.ServeWatermarkBypassedKey) {
+			// 所有者或管理员跳过水印拿到的原图，任何缓存都不能保存
+			cacheControl = "private, no-store"
+		} else if middleware.SupportsServeWatermark(fileInfo) {
+			// 所有者随时可能新增水印策略，可叠加水印的文件不能长期缓存原图
+			cacheControl = "public, max-age=3600"
+		}
+	}
 	if err != nil {
 		errors.HandleError(c, err)
 		return
 	}
 
-	c.Header("Cache-Control", "public, max-age=2592000, immutable")
+	c.Header("Cache-Control", cacheControl)
+	if middleware.SupportsServeWatermark(fileInfo) {
+		// 同一地址按访问者身份返回原图或水印图
+		c.Header("Vary", "Authorization")
+	}
 	c.Header("Access-Control-Allow-Origin", "*")
 
 	if isLocalPath {
 		if filePath, ok := result.(string); ok {
+			// c.File 本身支持 Range，视频只需明确 Content-Type（系统 MIME 表可能缺少 webm/mov）
+			if !isThumb && fileInfo.IsVideo() {
+				c.Header("Content-Type", filesvc.GetContentTypeByFormat(fileInfo.Format))
+			}
 			c.File(filePath)
 		}
 		return
@@ -78,11 +114,17 @@ func serveFileByInfo(c *gin.Context, fileInfo models.File, isThumb bool) {
 		if proxyResp, ok := result.(*filesvc.ProxyResponse); ok {
 			defer proxyResp.Content.Close()
 
-			c.Header("Content-Type", proxyResp.ContentType)
+			if !isThumb && fileInfo.IsVideo() {
+				serveVideoProxy(c, fileInfo, proxyResp)
+				return
+			}
 
-			// 注意：不设置 Content-Length，因为实际文件大小可能与数据库记录不同
-			// （例如 WebP 转换后的文件大小与原始文件不同）
-			// 让 HTTP 自动处理传输编码
+			// ContentLength 只在服务端生成内容（如签名链接的变换结果）时设置，是实际输出的字节数
+			// 远程代理不使用数据库记录的文件大小，它可能与存储中的内容不同（例如 WebP 转换后），交给分块传输
+			c.Header("Content-Type", proxyResp.ContentType)
+			if proxyResp.ContentLength > 0 {
+				c.Header("Content-Length", strconv.FormatInt(proxyResp.ContentLength, 10))
+			}
 
 			c.Status(200)
 			io.Copy(c.Writer, proxyResp.Content)
@@ -94,3 +136,12 @@ func serveFileByInfo(c *gin.Context, fileInfo models.File, isThumb bool) {
 		c.Redirect(302, url)
 	}
 }
+
+// serveVideoProxy 远程存储的视频通过代理返回时支持 Range，播放器才能拖动进度
+func serveVideoProxy(c *gin.Context, fileInfo models.File, proxyResp *filesvc.ProxyResponse) {
+	size := proxyResp.ContentLength
+	if size <= 0 {
+		size = fileInfo.Size
+	}
+	utils.ServeReaderRange(c, proxyResp.Content, size, filesvc.GetContentTypeByFormat(fileInfo.Format), proxyResp.OpenRange)
+}
@@ -0,0 +1,22 @@
+package file
+
+import (
+	"net/http"
+
+	filesvc "pixelpunk/internal/services/file"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RedirectLegacyURL 从其他图床迁移来的文件的旧地址永久重定向到新地址，返回是否已处理
+func RedirectLegacyURL(c *gin.Context) bool {
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		return false
+	}
+	target := filesvc.LegacyRedirectTarget(c.Request.URL.Path)
+	if target == "" {
+		return false
+	}
+	c.Redirect(http.StatusMovedPermanently, target)
+	return true
+}
@@ -0,0 +1,173 @@
+package file
+
+import (
+	"pixelpunk/internal/controllers/file/dto"
+	"pixelpunk/internal/middleware"
+	filesvc "pixelpunk/internal/services/file"
+	"pixelpunk/internal/services/hostimport"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminCreateDirectoryImport 导入服务器上允许目录中的目录或压缩包
+func AdminCreateDirectoryImport(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.CreateDirectoryImportDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	job, err := filesvc.CreateDirectoryImportJob(importTargetUser(c, req.UserID), req.Path, filesvc.LocalImportOptions{
+		FolderPath:    req.FolderPath,
+		AccessLevel:   req.AccessLevel,
+		Optimize:      req.Optimize,
+		ExifPolicy:    req.ExifPolicy,
+		PreserveMtime: req.PreserveMtime,
+	})
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, importJobResponse(job), "导入任务已创建")
+}
+
+// AdminCreateArchiveImport 上传压缩包并在后台解压导入
+func AdminCreateArchiveImport(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.CreateArchiveImportDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+	file, err := c.FormFile("file")
+	if err != nil {
+		errors.HandleError(c, errors.New(errors.CodeInvalidParameter, "请选择要导入的压缩包"))
+		return
+	}
+
+	job, err := filesvc.CreateArchiveImportJob(importTargetUser(c, req.UserID), file, filesvc.LocalImportOptions{
+		FolderPath:    req.FolderPath,
+		AccessLevel:   req.AccessLevel,
+		Optimize:      req.Optimize,
+		ExifPolicy:    req.ExifPolicy,
+		PreserveMtime: req.PreserveMtime,
+	})
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, importJobResponse(job), "导入任务已创建")
+}
+
+// AdminCreateHostImport 导入 Lsky Pro、Chevereto 的数据库导出或通用清单
+func AdminCreateHostImport(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.CreateHostImportDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	job, err := hostimport.CreateHostImportJob(hostimport.Options{
+		Format:          req.Format,
+		ExportPath:      req.Path,
+		FilesDir:        req.FilesDir,
+		BaseURL:         req.BaseURL,
+		TablePrefix:     req.TablePrefix,
+		UserID:          importTargetUser(c, req.UserID),
+		CreateUsers:     req.CreateUsers,
+		FolderPath:      req.FolderPath,
+		AlbumsAsFolders: req.AlbumsAsFolders,
+		AccessLevel:     req.AccessLevel,
+		Optimize:        req.Optimize,
+		ExifPolicy:      req.ExifPolicy,
+	})
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, importJobResponse(job), "导入任务已创建")
+}
+
+// AdminListLocalImports 查询服务器导入任务列表
+func AdminListLocalImports(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.ImportJobQueryDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	jobs, total, err := filesvc.ListLocalImportJobs(req.Page, req.Size)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	items := make([]gin.H, 0, len(jobs))
+	for i := range jobs {
+		items = append(items, importJobResponse(&jobs[i]))
+	}
+	errors.ResponseSuccess(c, gin.H{
+		"items": items,
+		"total": total,
+	}, "获取导入任务成功")
+}
+
+// AdminGetLocalImport 查询服务器导入任务进度
+func AdminGetLocalImport(c *gin.Context) {
+	job, err := filesvc.GetLocalImportJob(c.Param("job_id"))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, importJobResponse(job), "获取导入任务成功")
+}
+
+// AdminListLocalImportItems 查询服务器导入任务中每个文件的处理结果
+func AdminListLocalImportItems(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.ImportJobItemQueryDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	job, err := filesvc.GetLocalImportJob(c.Param("job_id"))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	items, total, err := filesvc.ListImportJobItems(job.ID, req.Status, req.Page, req.Size)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{
+		"items": items,
+		"total": total,
+	}, "获取导入结果成功")
+}
+
+// AdminCancelLocalImport 取消服务器导入任务，已导入的文件保留
+func AdminCancelLocalImport(c *gin.Context) {
+	job, err := filesvc.CancelLocalImportJob(c.Param("job_id"))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, importJobResponse(job), "导入任务已取消")
+}
+
+// importTargetUser 未指定用户时导入到当前管理员名下
+func importTargetUser(c *gin.Context, userID uint) uint {
+	if userID == 0 {
+		return middleware.GetCurrentUserID(c)
+	}
+	return userID
+}
@@ -0,0 +1,138 @@
+package file
+
+import (
+	"pixelpunk/internal/controllers/file/dto"
+	"pixelpunk/internal/middleware"
+	"pixelpunk/internal/models"
+	filesvc "pixelpunk/internal/services/file"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// signedLinkOwner 获取签名链接的归属：API密钥请求取密钥所属用户，否则取当前登录用户
+func signedLinkOwner(c *gin.Context) (uint, string) {
+	if keyObj, exists := c.Get("api_key"); exists {
+		if key, ok := keyObj.(*models.APIKey); ok {
+			return key.UserID, key.ID
+		}
+	}
+	return middleware.GetCurrentUserID(c), ""
+}
+
+// MintSignedLinks 批量签发签名链接（登录用户与API密钥共用）
+func MintSignedLinks(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.MintSignedLinksDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	userID, apiKeyID := signedLinkOwner(c)
+	results, err := filesvc.MintSignedLinks(userID, apiKeyID, filesvc.SignedLinkOptions{
+		FileIDs:      req.FileIDs,
+		Kind:         req.Kind,
+		ExpiresIn:    req.ExpiresIn,
+		BindIP:       req.BindIP,
+		MaxDownloads: req.MaxDownloads,
+		Width:        req.Width,
+		Height:       req.Height,
+		Quality:      req.Quality,
+		Format:       req.Format,
+		Note:         req.Note,
+	})
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	success := 0
+	for _, r := range results {
+		if r.Error == "" {
+			success++
+		}
+	}
+
+	errors.ResponseSuccess(c, gin.H{
+		"items":   results,
+		"success": success,
+		"failed":  len(results) - success,
+	}, "签发签名链接成功")
+}
+
+// ListSignedLinks 查询已签发的签名链接
+func ListSignedLinks(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.SignedLinkQueryDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	size := req.Size
+	if size <= 0 {
+		size = common.DefaultPageSize
+	}
+
+	userID, _ := signedLinkOwner(c)
+	links, total, err := filesvc.ListSignedLinks(userID, req.FileID, req.Active, page, size)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	items := make([]gin.H, 0, len(links))
+	for _, link := range links {
+		items = append(items, gin.H{
+			"id":             link.ID,
+			"file_id":        link.FileID,
+			"kind":           link.Kind,
+			"api_key_id":     link.APIKeyID,
+			"expires_at":     link.ExpiresAt,
+			"bind_ip":        link.BindIP,
+			"max_downloads":  link.MaxDownloads,
+			"download_count": link.DownloadCount,
+			"width":          link.Width,
+			"height":         link.Height,
+			"quality":        link.Quality,
+			"format":         link.Format,
+			"note":           link.Note,
+			"is_expired":     link.IsExpired(),
+			"is_revoked":     link.IsRevoked(),
+			"revoked_at":     link.RevokedAt,
+			"last_access_at": link.LastAccessAt,
+			"created_at":     link.CreatedAt,
+		})
+	}
+
+	errors.ResponseSuccess(c, gin.H{
+		"items": items,
+		"pagination": gin.H{
+			"total":       total,
+			"page":        page,
+			"size":        size,
+			"total_pages": (total + int64(size) - 1) / int64(size),
+		},
+	}, "获取签名链接列表成功")
+}
+
+// RevokeSignedLink 吊销单个签名链接
+func RevokeSignedLink(c *gin.Context) {
+	linkID := c.Param("link_id")
+	if linkID == "" {
+		errors.HandleError(c, errors.New(errors.CodeInvalidParameter, "链接ID不能为空"))
+		return
+	}
+
+	userID, _ := signedLinkOwner(c)
+	if err := filesvc.RevokeSignedLink(userID, linkID); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{"id": linkID}, "签名链接已吊销")
+}
@@ -0,0 +1,149 @@
+package file
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"pixelpunk/internal/middleware"
+	"pixelpunk/internal/models"
+	filesvc "pixelpunk/internal/services/file"
+	"pixelpunk/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// tusChecksumMismatch tus 校验和扩展规定的状态码
+const tusChecksumMismatch = 460
+
+// TusOptions 返回 tus 服务端支持的版本、扩展和大小限制
+func TusOptions(c *gin.Context) {
+	c.Header("Tus-Version", filesvc.TusVersion)
+	c.Header("Tus-Extension", filesvc.TusExtensions)
+	c.Header("Tus-Checksum-Algorithm", filesvc.TusChecksumAlgorithms)
+	c.Header("Tus-Max-Size", strconv.FormatInt(filesvc.TusMaxSize(), 10))
+	c.Status(http.StatusNoContent)
+}
+
+// CreateTusUpload 创建上传，Location 为后续 HEAD/PATCH/DELETE 的地址
+func CreateTusUpload(c *gin.Context) {
+	if c.GetHeader("Upload-Defer-Length") != "" {
+		c.String(http.StatusBadRequest, "不支持 Upload-Defer-Length")
+		return
+	}
+	length, err := strconv.ParseInt(c.GetHeader("Upload-Length"), 10, 64)
+	if err != nil || length < 0 {
+		c.String(http.StatusBadRequest, "Upload-Length 无效")
+		return
+	}
+
+	userID, key := uploaderIdentity(c)
+	session, err := filesvc.CreateTusUpload(userID, key, length, c.GetHeader("Upload-Metadata"))
+	if err != nil {
+		respondTusError(c, err)
+		return
+	}
+
+	c.Header("Location", strings.TrimSuffix(c.Request.URL.Path, "/")+"/"+session.SessionID)
+	c.Header("Upload-Expires", session.ExpiresAt.UTC().Format(http.TimeFormat))
+	c.Status(http.StatusCreated)
+}
+
+// GetTusUploadOffset HEAD 查询已接收的字节数，用于断点续传
+func GetTusUploadOffset(c *gin.Context) {
+	userID, _ := uploaderIdentity(c)
+	session, err := filesvc.GetTusUpload(userID, c.Param("id"))
+	if err != nil {
+		respondTusError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Upload-Offset", strconv.FormatInt(session.UploadOffset, 10))
+	c.Header("Upload-Length", strconv.FormatInt(session.FileSize, 10))
+	if session.UploadMetadata != "" {
+		c.Header("Upload-Metadata", session.UploadMetadata)
+	}
+	setTusSessionHeaders(c, session)
+	c.Status(http.StatusOK)
+}
+
+// PatchTusUpload 从 Upload-Offset 处追加数据，最后一段到达后生成文件
+func PatchTusUpload(c *gin.Context) {
+	if c.ContentType() != "application/offset+octet-stream" {
+		c.String(http.StatusUnsupportedMediaType, "Content-Type 必须为 application/offset+octet-stream")
+		return
+	}
+	offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		c.String(http.StatusBadRequest, "Upload-Offset 无效")
+		return
+	}
+
+	userID, _ := uploaderIdentity(c)
+	session, file, err := filesvc.WriteTusChunk(userID, c.Param("id"), offset, c.Request.Body, c.GetHeader("Upload-Checksum"))
+	if session != nil {
+		c.Header("Upload-Offset", strconv.FormatInt(session.UploadOffset, 10))
+	}
+	if err != nil {
+		respondTusError(c, err)
+		return
+	}
+
+	if file != nil {
+		c.Header("X-File-Id", file.ID)
+	}
+	setTusSessionHeaders(c, session)
+	c.Status(http.StatusNoContent)
+}
+
+// TerminateTusUpload 终止上传并删除已接收的数据
+func TerminateTusUpload(c *gin.Context) {
+	userID, _ := uploaderIdentity(c)
+	if err := filesvc.TerminateTusUpload(userID, c.Param("id")); err != nil {
+		respondTusError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// uploaderIdentity 上传者：API密钥路由使用密钥所属用户，其他路由使用登录用户
+func uploaderIdentity(c *gin.Context) (uint, *models.APIKey) {
+	if v, ok := c.Get("api_key"); ok {
+		if key, ok := v.(*models.APIKey); ok {
+			return key.UserID, key
+		}
+	}
+	return middleware.GetCurrentUserID(c), nil
+}
+
+// setTusSessionHeaders 未完成的上传返回过期时间，已完成的上传返回生成的文件ID
+func setTusSessionHeaders(c *gin.Context, session *models.UploadSession) {
+	if session.IsCompleted() {
+		if session.FileID != "" {
+			c.Header("X-File-Id", session.FileID)
+		}
+		return
+	}
+	c.Header("Upload-Expires", session.ExpiresAt.UTC().Format(http.TimeFormat))
+}
+
+// respondTusError tus 客户端按状态码处理错误，响应体为纯文本说明
+func respondTusError(c *gin.Context, err error) {
+	status := errors.HTTPStatus(err)
+	switch {
+	case errors.Is(err, errors.CodeUploadSessionExpired):
+		status = http.StatusGone
+	case errors.Is(err, errors.CodeFileTooLarge):
+		status = http.StatusRequestEntityTooLarge
+	case errors.Is(err, errors.CodeValidationFailed):
+		status = tusChecksumMismatch
+	case errors.Is(err, errors.CodeFileTypeNotSupported):
+		status = http.StatusUnsupportedMediaType
+	}
+	message := err.Error()
+	if e, ok := err.(*errors.Error); ok {
+		message = e.Message
+	}
+	c.String(status, message)
+}
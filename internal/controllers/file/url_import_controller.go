@@ -0,0 +1,163 @@
+package file
+
+import (
+	"pixelpunk/internal/controllers/file/dto"
+	"pixelpunk/internal/models"
+	filesvc "pixelpunk/internal/services/file"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImportFromURL 下载远程地址的文件并上传（登录用户与API密钥共用）
+func ImportFromURL(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.ImportURLDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	userID, key := uploaderIdentity(c)
+	file, err := filesvc.ImportFromURL(userID, key, req.URL, filesvc.URLImportOptions{
+		FolderID:    req.FolderID,
+		FilePath:    req.FilePath,
+		AccessLevel: req.AccessLevel,
+		Optimize:    req.Optimize,
+		ExifPolicy:  req.ExifPolicy,
+	})
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, file, "导入成功")
+}
+
+// CreateURLImportJob 创建批量导入任务，地址在后台逐个下载
+func CreateURLImportJob(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.CreateURLImportJobDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	userID, key := uploaderIdentity(c)
+	job, err := filesvc.CreateURLImportJob(userID, key, req.URLs, filesvc.URLImportOptions{
+		FolderID:    req.FolderID,
+		FilePath:    req.FilePath,
+		AccessLevel: req.AccessLevel,
+		Optimize:    req.Optimize,
+		ExifPolicy:  req.ExifPolicy,
+	})
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, importJobResponse(job), "导入任务已创建")
+}
+
+// ListImportJobs 查询导入任务列表
+func ListImportJobs(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.ImportJobQueryDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	userID, apiKeyID := importJobOwner(c)
+	jobs, total, err := filesvc.ListImportJobs(userID, apiKeyID, req.Page, req.Size)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	items := make([]gin.H, 0, len(jobs))
+	for i := range jobs {
+		items = append(items, importJobResponse(&jobs[i]))
+	}
+	errors.ResponseSuccess(c, gin.H{
+		"items": items,
+		"total": total,
+	}, "获取导入任务成功")
+}
+
+// GetImportJob 查询导入任务进度
+func GetImportJob(c *gin.Context) {
+	userID, apiKeyID := importJobOwner(c)
+	job, err := filesvc.GetImportJob(userID, apiKeyID, c.Param("job_id"))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, importJobResponse(job), "获取导入任务成功")
+}
+
+// ListImportJobItems 查询任务中每个地址的处理结果
+func ListImportJobItems(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.ImportJobItemQueryDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	userID, apiKeyID := importJobOwner(c)
+	job, err := filesvc.GetImportJob(userID, apiKeyID, c.Param("job_id"))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	items, total, err := filesvc.ListImportJobItems(job.ID, req.Status, req.Page, req.Size)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{
+		"items": items,
+		"total": total,
+	}, "获取导入结果成功")
+}
+
+// CancelImportJob 取消未完成的导入任务，已导入的文件保留
+func CancelImportJob(c *gin.Context) {
+	userID, apiKeyID := importJobOwner(c)
+	job, err := filesvc.CancelImportJob(userID, apiKeyID, c.Param("job_id"))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, importJobResponse(job), "导入任务已取消")
+}
+
+// importJobOwner API密钥只能查看自己创建的任务，登录用户可以查看全部任务
+func importJobOwner(c *gin.Context) (uint, string) {
+	userID, key := uploaderIdentity(c)
+	if key != nil {
+		return userID, key.ID
+	}
+	return userID, ""
+}
+
+func importJobResponse(job *models.ImportJob) gin.H {
+	return gin.H{
+		"id":            job.ID,
+		"user_id":       job.UserID,
+		"source":        job.Source,
+		"status":        job.Status,
+		"folder_id":     job.FolderID,
+		"total_count":   job.TotalCount,
+		"success_count": job.SuccessCount,
+		"failed_count":  job.FailedCount,
+		"skipped_count": job.SkippedCount,
+		"pending_count": job.TotalCount - job.ProcessedCount(),
+		"error_msg":     job.ErrorMsg,
+		"created_at":    job.CreatedAt,
+		"started_at":    job.StartedAt,
+		"completed_at":  job.CompletedAt,
+	}
+}
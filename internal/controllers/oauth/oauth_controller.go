@@ -3,141 +3,225 @@ package oauth
 import (
 	"fmt"
 	"pixelpunk/internal/controllers/setting/dto"
+	"pixelpunk/internal/middleware"
 	"pixelpunk/internal/models"
-	"pixelpunk/internal/services/auth"
 	oauthService "pixelpunk/internal/services/oauth"
 	"pixelpunk/internal/services/setting"
+	userService "pixelpunk/internal/services/user"
 	"pixelpunk/pkg/common"
 	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/utils"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
-// OAuthHandler 通用 OAuth 处理函数
-type OAuthHandler func(code string, proxyConfig *oauthService.ProxyConfig) (*models.User, error)
+// identityResolver 使用授权码（OIDC 还需 state）换取统一的第三方身份
+type identityResolver func(code, state string) (*oauthService.ExternalIdentity, error)
 
 // handleOAuthLogin 通用 OAuth 登录处理逻辑
-func handleOAuthLogin(c *gin.Context, code string, provider string, handler OAuthHandler, proxyConfig *oauthService.ProxyConfig) {
-	user, err := handler(code, proxyConfig)
+func handleOAuthLogin(c *gin.Context, code, state, provider string) {
+	resolver, name, err := resolverFor(provider)
 	if err != nil {
-		errors.HandleError(c, errors.New(errors.CodeInternal, fmt.Sprintf("%s 登录失败: %v", provider, err)))
+		errors.HandleError(c, err)
 		return
 	}
 
-	securitySettings, err := setting.GetSettingsByGroupAsMap("security")
+	ident, err := resolver(code, state)
 	if err != nil {
-		errors.HandleError(c, errors.New(errors.CodeInternal, "安全配置读取失败"))
+		errors.HandleError(c, wrapOAuthError(err, name))
 		return
 	}
 
-	jwtSecret := ""
-	if val, ok := securitySettings.Settings["jwt_secret"]; ok {
-		if secretStr, ok := val.(string); ok {
-			jwtSecret = secretStr
-		}
-	}
-	if strings.TrimSpace(jwtSecret) == "" {
-		errors.HandleError(c, errors.New(errors.CodeInternal, "JWT 密钥未配置"))
+	user, err := oauthService.FindOrCreateUser(ident)
+	if err != nil {
+		errors.HandleError(c, wrapOAuthError(err, name))
 		return
 	}
-
-	expiresHours := 0
-	if val, ok := securitySettings.Settings["login_expire_hours"]; ok {
-		if hours, ok := val.(float64); ok && hours > 0 {
-			expiresHours = int(hours)
-		}
-	}
-	if expiresHours <= 0 {
-		errors.HandleError(c, errors.New(errors.CodeInternal, "登录过期时间配置错误"))
+	if !user.IsNormal() {
+		errors.HandleError(c, errors.New(errors.CodeUserDisabled, "账号已被禁用"))
 		return
 	}
 
-	token, err := auth.GenerateToken(user.ID, user.Username, int(user.Role), jwtSecret, expiresHours)
+	result, err := userService.CompleteLogin(user, userService.SessionClient{
+		IP:          utils.GetClientIP(c),
+		UserAgent:   c.Request.UserAgent(),
+		LoginMethod: "oauth_" + provider,
+	})
 	if err != nil {
-		errors.HandleError(c, errors.New(errors.CodeInternal, "生成登录凭证失败"))
+		errors.HandleError(c, err)
 		return
 	}
 
-	userInfo := map[string]interface{}{
-		"id":       user.ID,
-		"username": user.Username,
-		"email":    user.Email,
-		"avatar":   user.Avatar,
-		"bio":      user.Bio,
-		"website":  user.Website,
-		"role":     user.Role,
-		"status":   user.Status,
+	if result.TwoFactorRequired {
+		errors.ResponseSuccess(c, gin.H{
+			"two_factor_required": true,
+			"challenge":           result.Challenge,
+		}, "请输入两步验证码")
+		return
 	}
 
 	data := gin.H{
-		"token":    token,
-		"userInfo": userInfo,
-		"email":    user.Email,
+		"token":              result.Token,
+		"userInfo":           result.UserInfo,
+		"email":              user.Email,
+		"refresh_token":      result.RefreshToken,
+		"session_id":         result.SessionID,
+		"expires_in":         result.ExpiresIn,
+		"refresh_expires_at": result.RefreshExpiresAt,
+	}
+	if result.TwoFactorSetupRequired {
+		data["two_factor_setup_required"] = true
 	}
 
 	errors.ResponseSuccess(c, data, "登录成功")
 }
 
-func GithubLogin(c *gin.Context) {
-	req, err := common.ValidateRequest[dto.GithubOAuthLoginDTO](c)
-	if err != nil {
-		errors.HandleError(c, err)
-		return
+// wrapOAuthError 业务错误原样返回，其余错误统一包装为登录失败
+func wrapOAuthError(err error, name string) error {
+	if appErr, ok := err.(*errors.Error); ok {
+		return appErr
+	}
+	return errors.New(errors.CodeInternal, fmt.Sprintf("%s 登录失败: %v", name, err))
+}
+
+// resolverFor 按提供方读取配置并返回身份解析函数与显示名称
+func resolverFor(provider string) (identityResolver, string, error) {
+	if provider == models.IdentityProviderOIDC {
+		return oidcResolver()
 	}
 
 	oauthConfig, err := setting.GetOAuthConfig()
 	if err != nil {
-		errors.HandleError(c, errors.New(errors.CodeInternal, "获取 OAuth 配置失败"))
-		return
+		return nil, "", errors.New(errors.CodeInternal, "获取 OAuth 配置失败")
 	}
 
-	if !oauthConfig.Github.Enabled {
-		errors.HandleError(c, errors.New(errors.CodeForbidden, "GitHub 登录功能未启用"))
-		return
+	switch provider {
+	case models.IdentityProviderGithub:
+		cfg := oauthConfig.Github
+		if err := checkProviderConfig("GitHub", cfg.Enabled, cfg.ClientID, cfg.ClientSecret); err != nil {
+			return nil, "", err
+		}
+		proxyConfig := buildProxyConfig(cfg.ProxyEnabled, cfg.ProxyDynamic, cfg.ProxyAPIURL, cfg.ProxyType, cfg.ProxyHost, cfg.ProxyPort, cfg.ProxyUsername, cfg.ProxyPassword)
+		return func(code, _ string) (*oauthService.ExternalIdentity, error) {
+			githubService := oauthService.NewGithubOAuthService(cfg.ClientID, cfg.ClientSecret, cfg.RedirectURI, proxyConfig)
+			tokenResp, err := githubService.ExchangeCode(code)
+			if err != nil {
+				return nil, fmt.Errorf("授权失败: %w", err)
+			}
+			githubUser, err := githubService.GetUserInfo(tokenResp.AccessToken)
+			if err != nil {
+				return nil, fmt.Errorf("获取用户信息失败: %w", err)
+			}
+			return githubUser.ToIdentity(), nil
+		}, "GitHub", nil
+
+	case models.IdentityProviderGoogle:
+		cfg := oauthConfig.Google
+		if err := checkProviderConfig("Google", cfg.Enabled, cfg.ClientID, cfg.ClientSecret); err != nil {
+			return nil, "", err
+		}
+		proxyConfig := buildProxyConfig(cfg.ProxyEnabled, cfg.ProxyDynamic, cfg.ProxyAPIURL, cfg.ProxyType, cfg.ProxyHost, cfg.ProxyPort, cfg.ProxyUsername, cfg.ProxyPassword)
+		return func(code, _ string) (*oauthService.ExternalIdentity, error) {
+			googleService := oauthService.NewGoogleOAuthService(cfg.ClientID, cfg.ClientSecret, cfg.RedirectURI, proxyConfig)
+			tokenResp, err := googleService.ExchangeCode(code)
+			if err != nil {
+				return nil, fmt.Errorf("授权失败: %w", err)
+			}
+			googleUser, err := googleService.GetUserInfo(tokenResp.AccessToken)
+			if err != nil {
+				return nil, fmt.Errorf("获取用户信息失败: %w", err)
+			}
+			return googleUser.ToIdentity(), nil
+		}, "Google", nil
+
+	case models.IdentityProviderLinuxdo:
+		cfg := oauthConfig.Linuxdo
+		if err := checkProviderConfig("Linux DO", cfg.Enabled, cfg.ClientID, cfg.ClientSecret); err != nil {
+			return nil, "", err
+		}
+		proxyConfig := buildProxyConfig(cfg.ProxyEnabled, cfg.ProxyDynamic, cfg.ProxyAPIURL, cfg.ProxyType, cfg.ProxyHost, cfg.ProxyPort, cfg.ProxyUsername, cfg.ProxyPassword)
+		return func(code, _ string) (*oauthService.ExternalIdentity, error) {
+			linuxdoService := oauthService.NewLinuxdoOAuthService(cfg.ClientID, cfg.ClientSecret, cfg.RedirectURI, proxyConfig)
+			tokenResp, err := linuxdoService.ExchangeCode(code)
+			if err != nil {
+				return nil, fmt.Errorf("授权失败: %w", err)
+			}
+			linuxdoUser, err := linuxdoService.GetUserInfo(tokenResp.AccessToken)
+			if err != nil {
+				return nil, fmt.Errorf("获取用户信息失败: %w", err)
+			}
+			return linuxdoUser.ToIdentity(), nil
+		}, "Linux DO", nil
 	}
 
-	if oauthConfig.Github.ClientID == "" || oauthConfig.Github.ClientSecret == "" {
-		errors.HandleError(c, errors.New(errors.CodeInternal, "GitHub OAuth 配置不完整"))
-		return
-	}
+	return nil, "", errors.New(errors.CodeInvalidParameter, "不支持的登录方式")
+}
 
-	var proxyConfig *oauthService.ProxyConfig
-	if oauthConfig.Github.ProxyEnabled {
-		proxyConfig = &oauthService.ProxyConfig{
-			Enabled:  oauthConfig.Github.ProxyEnabled,
-			Dynamic:  oauthConfig.Github.ProxyDynamic,
-			APIURL:   oauthConfig.Github.ProxyAPIURL,
-			Type:     oauthConfig.Github.ProxyType,
-			Host:     oauthConfig.Github.ProxyHost,
-			Port:     oauthConfig.Github.ProxyPort,
-			Username: oauthConfig.Github.ProxyUsername,
-			Password: oauthConfig.Github.ProxyPassword,
+func oidcResolver() (identityResolver, string, error) {
+	cfg, err := loadOIDCConfig()
+	if err != nil {
+		return nil, "", err
+	}
+	oidcService := oauthService.NewOIDCService(cfg, oidcProxyConfig(cfg))
+	return func(code, state string) (*oauthService.ExternalIdentity, error) {
+		if state == "" {
+			return nil, errors.New(errors.CodeInvalidParameter, "授权状态无效，请重新登录")
 		}
+		return oidcService.ResolveIdentity(code, state)
+	}, cfg.DisplayName, nil
+}
+
+func loadOIDCConfig() (*dto.OIDCConfig, error) {
+	cfg := setting.GetOIDCConfig()
+	if !cfg.Enabled {
+		return nil, errors.New(errors.CodeForbidden, fmt.Sprintf("%s 登录功能未启用", cfg.DisplayName))
+	}
+	if cfg.Issuer == "" || cfg.ClientID == "" || cfg.RedirectURI == "" {
+		return nil, errors.New(errors.CodeInternal, "OIDC 配置不完整")
 	}
+	return cfg, nil
+}
 
-	handler := func(code string, proxyConfig *oauthService.ProxyConfig) (*models.User, error) {
-		githubService := oauthService.NewGithubOAuthService(
-			oauthConfig.Github.ClientID,
-			oauthConfig.Github.ClientSecret,
-			oauthConfig.Github.RedirectURI,
-			proxyConfig,
-		)
+func oidcProxyConfig(cfg *dto.OIDCConfig) *oauthService.ProxyConfig {
+	return buildProxyConfig(cfg.ProxyEnabled, cfg.ProxyDynamic, cfg.ProxyAPIURL, cfg.ProxyType, cfg.ProxyHost, cfg.ProxyPort, cfg.ProxyUsername, cfg.ProxyPassword)
+}
 
-		tokenResp, err := githubService.ExchangeCode(code)
-		if err != nil {
-			return nil, fmt.Errorf("授权失败: %w", err)
-		}
+func checkProviderConfig(name string, enabled bool, clientID, clientSecret string) error {
+	if !enabled {
+		return errors.New(errors.CodeForbidden, fmt.Sprintf("%s 登录功能未启用", name))
+	}
+	if clientID == "" || clientSecret == "" {
+		return errors.New(errors.CodeInternal, fmt.Sprintf("%s OAuth 配置不完整", name))
+	}
+	return nil
+}
 
-		githubUser, err := githubService.GetUserInfo(tokenResp.AccessToken)
-		if err != nil {
-			return nil, fmt.Errorf("获取用户信息失败: %w", err)
-		}
+func buildProxyConfig(enabled, dynamic bool, apiURL, proxyType, host, port, username, password string) *oauthService.ProxyConfig {
+	if !enabled {
+		return nil
+	}
+	return &oauthService.ProxyConfig{
+		Enabled:  enabled,
+		Dynamic:  dynamic,
+		APIURL:   apiURL,
+		Type:     proxyType,
+		Host:     host,
+		Port:     port,
+		Username: username,
+		Password: password,
+	}
+}
 
-		return githubService.FindOrCreateUser(githubUser)
+func GithubLogin(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.GithubOAuthLoginDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
 	}
 
-	handleOAuthLogin(c, req.Code, "GitHub", handler, proxyConfig)
+	handleOAuthLogin(c, req.Code, "", models.IdentityProviderGithub)
 }
 
 func GoogleLogin(c *gin.Context) {
@@ -147,117 +231,96 @@ func GoogleLogin(c *gin.Context) {
 		return
 	}
 
-	oauthConfig, err := setting.GetOAuthConfig()
+	handleOAuthLogin(c, req.Code, "", models.IdentityProviderGoogle)
+}
+
+func LinuxdoLogin(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.LinuxdoOAuthLoginDTO](c)
 	if err != nil {
-		errors.HandleError(c, errors.New(errors.CodeInternal, "获取 OAuth 配置失败"))
+		errors.HandleError(c, err)
 		return
 	}
 
-	if !oauthConfig.Google.Enabled {
-		errors.HandleError(c, errors.New(errors.CodeForbidden, "Google 登录功能未启用"))
-		return
-	}
+	handleOAuthLogin(c, req.Code, "", models.IdentityProviderLinuxdo)
+}
 
-	if oauthConfig.Google.ClientID == "" || oauthConfig.Google.ClientSecret == "" {
-		errors.HandleError(c, errors.New(errors.CodeInternal, "Google OAuth 配置不完整"))
+// OIDCAuthorize 生成 OIDC 授权地址（含 PKCE），前端跳转后回调携带 code 与 state
+func OIDCAuthorize(c *gin.Context) {
+	cfg, err := loadOIDCConfig()
+	if err != nil {
+		errors.HandleError(c, err)
 		return
 	}
 
-	var proxyConfig *oauthService.ProxyConfig
-	if oauthConfig.Google.ProxyEnabled {
-		proxyConfig = &oauthService.ProxyConfig{
-			Enabled:  oauthConfig.Google.ProxyEnabled,
-			Dynamic:  oauthConfig.Google.ProxyDynamic,
-			APIURL:   oauthConfig.Google.ProxyAPIURL,
-			Type:     oauthConfig.Google.ProxyType,
-			Host:     oauthConfig.Google.ProxyHost,
-			Port:     oauthConfig.Google.ProxyPort,
-			Username: oauthConfig.Google.ProxyUsername,
-			Password: oauthConfig.Google.ProxyPassword,
-		}
+	authURL, state, err := oauthService.NewOIDCService(cfg, oidcProxyConfig(cfg)).AuthorizationURL()
+	if err != nil {
+		errors.HandleError(c, errors.New(errors.CodeInternal, fmt.Sprintf("%s 授权地址生成失败: %v", cfg.DisplayName, err)))
+		return
 	}
 
-	handler := func(code string, proxyConfig *oauthService.ProxyConfig) (*models.User, error) {
-		googleService := oauthService.NewGoogleOAuthService(
-			oauthConfig.Google.ClientID,
-			oauthConfig.Google.ClientSecret,
-			oauthConfig.Google.RedirectURI,
-			proxyConfig,
-		)
-
-		tokenResp, err := googleService.ExchangeCode(code)
-		if err != nil {
-			return nil, fmt.Errorf("授权失败: %w", err)
-		}
-
-		googleUser, err := googleService.GetUserInfo(tokenResp.AccessToken)
-		if err != nil {
-			return nil, fmt.Errorf("获取用户信息失败: %w", err)
-		}
+	errors.ResponseSuccess(c, gin.H{"url": authURL, "state": state}, "获取成功")
+}
 
-		return googleService.FindOrCreateUser(googleUser)
+func OIDCLogin(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.OIDCLoginDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
 	}
 
-	handleOAuthLogin(c, req.Code, "Google", handler, proxyConfig)
+	handleOAuthLogin(c, req.Code, req.State, models.IdentityProviderOIDC)
 }
 
-func LinuxdoLogin(c *gin.Context) {
-	req, err := common.ValidateRequest[dto.LinuxdoOAuthLoginDTO](c)
+func ListIdentities(c *gin.Context) {
+	identities, err := oauthService.ListUserIdentities(middleware.GetCurrentUserID(c))
 	if err != nil {
 		errors.HandleError(c, err)
 		return
 	}
 
-	oauthConfig, err := setting.GetOAuthConfig()
+	errors.ResponseSuccess(c, gin.H{"items": identities}, "获取成功")
+}
+
+// LinkIdentity 已登录用户完成第三方授权后绑定该身份
+func LinkIdentity(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.LinkIdentityDTO](c)
 	if err != nil {
-		errors.HandleError(c, errors.New(errors.CodeInternal, "获取 OAuth 配置失败"))
+		errors.HandleError(c, err)
 		return
 	}
 
-	if !oauthConfig.Linuxdo.Enabled {
-		errors.HandleError(c, errors.New(errors.CodeForbidden, "Linux DO 登录功能未启用"))
+	resolver, name, err := resolverFor(strings.ToLower(req.Provider))
+	if err != nil {
+		errors.HandleError(c, err)
 		return
 	}
 
-	if oauthConfig.Linuxdo.ClientID == "" || oauthConfig.Linuxdo.ClientSecret == "" {
-		errors.HandleError(c, errors.New(errors.CodeInternal, "Linux DO OAuth 配置不完整"))
+	ident, err := resolver(req.Code, req.State)
+	if err != nil {
+		errors.HandleError(c, wrapOAuthError(err, name))
 		return
 	}
 
-	var proxyConfig *oauthService.ProxyConfig
-	if oauthConfig.Linuxdo.ProxyEnabled {
-		proxyConfig = &oauthService.ProxyConfig{
-			Enabled:  oauthConfig.Linuxdo.ProxyEnabled,
-			Dynamic:  oauthConfig.Linuxdo.ProxyDynamic,
-			APIURL:   oauthConfig.Linuxdo.ProxyAPIURL,
-			Type:     oauthConfig.Linuxdo.ProxyType,
-			Host:     oauthConfig.Linuxdo.ProxyHost,
-			Port:     oauthConfig.Linuxdo.ProxyPort,
-			Username: oauthConfig.Linuxdo.ProxyUsername,
-			Password: oauthConfig.Linuxdo.ProxyPassword,
-		}
+	identity, err := oauthService.LinkIdentity(middleware.GetCurrentUserID(c), ident)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
 	}
 
-	handler := func(code string, proxyConfig *oauthService.ProxyConfig) (*models.User, error) {
-		linuxdoService := oauthService.NewLinuxdoOAuthService(
-			oauthConfig.Linuxdo.ClientID,
-			oauthConfig.Linuxdo.ClientSecret,
-			oauthConfig.Linuxdo.RedirectURI,
-			proxyConfig,
-		)
-
-		tokenResp, err := linuxdoService.ExchangeCode(code)
-		if err != nil {
-			return nil, fmt.Errorf("授权失败: %w", err)
-		}
+	errors.ResponseSuccess(c, identity, "绑定成功")
+}
 
-		linuxdoUser, err := linuxdoService.GetUserInfo(tokenResp.AccessToken)
-		if err != nil {
-			return nil, fmt.Errorf("获取用户信息失败: %w", err)
-		}
+func UnlinkIdentity(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		errors.HandleError(c, errors.New(errors.CodeInvalidParameter, "绑定记录ID格式不正确"))
+		return
+	}
 
-		return linuxdoService.FindOrCreateUser(linuxdoUser)
+	if err := oauthService.UnlinkIdentity(middleware.GetCurrentUserID(c), uint(id)); err != nil {
+		errors.HandleError(c, err)
+		return
 	}
 
-	handleOAuthLogin(c, req.Code, "Linux DO", handler, proxyConfig)
+	errors.ResponseSuccess(c, nil, "解绑成功")
 }
@@ -77,9 +77,11 @@ type GlobalSettingsGroupDTO struct {
 }
 
 type OAuthProvidersDTO struct {
-	GithubEnabled  bool `json:"github_enabled"`
-	GoogleEnabled  bool `json:"google_enabled"`
-	LinuxdoEnabled bool `json:"linuxdo_enabled"`
+	GithubEnabled  bool   `json:"github_enabled"`
+	GoogleEnabled  bool   `json:"google_enabled"`
+	LinuxdoEnabled bool   `json:"linuxdo_enabled"`
+	OIDCEnabled    bool   `json:"oidc_enabled"`
+	OIDCName       string `json:"oidc_name"` // OIDC 登录按钮显示名称
 }
 
 type GlobalSettingsResponseDTO struct {
@@ -276,6 +278,34 @@ type LinuxdoOAuthConfig struct {
 	ProxyPassword string `json:"proxy_password"`
 }
 
+/* OIDCConfig 通用 OpenID Connect 提供方配置 */
+type OIDCConfig struct {
+	Enabled              bool     `json:"enabled"`
+	DisplayName          string   `json:"display_name"`           // 登录按钮显示名称
+	Issuer               string   `json:"issuer"`                 // 签发方地址，用于服务发现
+	ClientID             string   `json:"client_id"`              // Client ID
+	ClientSecret         string   `json:"client_secret"`          // Client Secret，公共客户端可为空（仅PKCE）
+	RedirectURI          string   `json:"redirect_uri"`           // 回调地址
+	Scope                string   `json:"scope"`                  // 授权范围
+	ClaimUsername        string   `json:"claim_username"`         // 用户名声明，支持 a.b 形式的嵌套路径
+	ClaimEmail           string   `json:"claim_email"`            // 邮箱声明
+	ClaimName            string   `json:"claim_name"`             // 显示名声明
+	ClaimAvatar          string   `json:"claim_avatar"`           // 头像声明
+	ClaimGroups          string   `json:"claim_groups"`           // 用户组声明
+	GroupRoleMapping     string   `json:"group_role_mapping"`     // 用户组到角色的映射，如 "ops=admin,staff=user"
+	AllowedDomains       []string `json:"allowed_domains"`        // 允许登录的邮箱域名，空表示不限制
+	AllowedGroups        []string `json:"allowed_groups"`         // 允许登录的用户组，空表示不限制
+	RequireVerifiedEmail bool     `json:"require_verified_email"` // 要求 email_verified 为 true
+	ProxyEnabled         bool     `json:"proxy_enabled"`
+	ProxyDynamic         bool     `json:"proxy_dynamic"`
+	ProxyAPIURL          string   `json:"proxy_api_url"`
+	ProxyType            string   `json:"proxy_type"`
+	ProxyHost            string   `json:"proxy_host"`
+	ProxyPort            string   `json:"proxy_port"`
+	ProxyUsername        string   `json:"proxy_username"`
+	ProxyPassword        string   `json:"proxy_password"`
+}
+
 type OAuthConfigResponseDTO struct {
 	Github  GithubOAuthConfig  `json:"github"`
 	Google  GoogleOAuthConfig  `json:"google"`
@@ -312,6 +342,34 @@ func (d *LinuxdoOAuthLoginDTO) GetValidationMessages() map[string]string {
 	}
 }
 
+type OIDCLoginDTO struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required,max=64"`
+}
+
+func (d *OIDCLoginDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Code.required":  "授权码不能为空",
+		"State.required": "授权状态无效，请重新登录",
+		"State.max":      "授权状态无效，请重新登录",
+	}
+}
+
+type LinkIdentityDTO struct {
+	Provider string `json:"provider" binding:"required,oneof=github google linuxdo oidc"`
+	Code     string `json:"code" binding:"required"`
+	State    string `json:"state" binding:"max=64"`
+}
+
+func (d *LinkIdentityDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Provider.required": "登录方式不能为空",
+		"Provider.oneof":    "不支持的登录方式",
+		"Code.required":     "授权码不能为空",
+		"State.max":         "授权状态无效",
+	}
+}
+
 type TestProxyDTO struct {
 	ProxyDynamic  bool   `json:"proxy_dynamic"`  // 是否使用动态代理
 	ProxyAPIURL   string `json:"proxy_api_url"`  // 动态代理API地址
@@ -335,3 +393,10 @@ type TestProxyResponseDTO struct {
 	Message string `json:"message"` // 消息
 	Latency int64  `json:"latency"` // 延迟(毫秒)
 }
+
+// SettingExportDTO 系统设置导出文件
+type SettingExportDTO struct {
+	Version    int                `json:"version"`     // 导出格式版本
+	ExportedAt string             `json:"exported_at"` // 导出时间
+	Settings   []SettingCreateDTO `json:"settings"`    // 设置项
+}
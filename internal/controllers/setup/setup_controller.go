@@ -12,6 +12,7 @@ import (
 	"pixelpunk/internal/models"
 	ai "pixelpunk/internal/services/ai"
 	"pixelpunk/internal/services/auth"
+	"pixelpunk/internal/services/automation"
 	"pixelpunk/internal/services/message"
 	"pixelpunk/internal/services/setting"
 	"pixelpunk/internal/services/storage"
@@ -64,6 +65,11 @@ func (s *SetupController) TestConnection(c *gin.Context) {
 			errors.HandleError(c, errors.New(errors.CodeValidationFailed, "MySQL数据库连接信息不完整"))
 			return
 		}
+	case "postgres":
+		if req.Host == "" || req.Username == "" || req.Name == "" {
+			errors.HandleError(c, errors.New(errors.CodeValidationFailed, "PostgreSQL数据库连接信息不完整"))
+			return
+		}
 	case "sqlite":
 		if req.Path == "" {
 			errors.HandleError(c, errors.New(errors.CodeValidationFailed, "SQLite数据库文件路径不能为空"))
@@ -301,7 +307,7 @@ func updateExistingConfigFile(configPath string, req struct {
 	dbConfig := existingConfig["database"].(map[string]interface{})
 
 	dbConfig["type"] = req.Database.Type
-	if req.Database.Type == "mysql" {
+	if req.Database.Type == "mysql" || req.Database.Type == "postgres" {
 		dbConfig["host"] = req.Database.Host
 		dbConfig["port"] = req.Database.Port
 		dbConfig["username"] = req.Database.Username
@@ -363,7 +369,7 @@ func createNewConfigFile(configPath string, req struct {
 	var configContent string
 
 	switch req.Database.Type {
-	case "mysql":
+	case "mysql", "postgres":
 		configContent = fmt.Sprintf(`# 应用基本配置
 app:
   port: 9520
@@ -371,7 +377,7 @@ app:
 
 # 数据库配置
 database:
-  type: "mysql"
+  type: "%s"
   host: "%s"
   port: %d
   username: "%s"
@@ -391,6 +397,7 @@ cors:
   allowed_origins:
     - "*"
 `,
+			req.Database.Type,
 			req.Database.Host,
 			req.Database.Port,
 			req.Database.Username,
@@ -547,6 +554,7 @@ func initializeSystemServices(vectorConfig struct {
 	initAllServices()
 	websocket.InitWebSocketManager()
 	cron.InitCronManager()
+	cron.InitNodeTasks()
 
 	return nil
 }
@@ -569,6 +577,7 @@ func initAllServices() {
 
 	ai.RegisterAISettingHooks()
 	vectorSvc.RegisterVectorConfigHooks()
+	automation.RegisterAutomationHooks()
 }
 
 func writeVectorConfigToDatabase(qdrantURL string, qdrantTimeout int) error {
@@ -0,0 +1,63 @@
+package dto
+
+type ShareAnalyticsQueryDTO struct {
+	StartDate   string `form:"start_date" binding:"omitempty,datetime=2006-01-02"`
+	EndDate     string `form:"end_date" binding:"omitempty,datetime=2006-01-02"`
+	Granularity string `form:"granularity" binding:"omitempty,oneof=day week month"`
+	TopN        int    `form:"top_n" binding:"omitempty,min=1,max=100"`
+}
+
+func (d *ShareAnalyticsQueryDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"StartDate.datetime": "开始日期格式必须为YYYY-MM-DD",
+		"EndDate.datetime":   "结束日期格式必须为YYYY-MM-DD",
+		"Granularity.oneof":  "统计粒度必须是day、week或month",
+		"TopN.min":           "排行数量必须大于等于1",
+		"TopN.max":           "排行数量必须小于等于100",
+	}
+}
+
+type ShareAnalyticsSummary struct {
+	Views          int64 `json:"views"`           // 分享页浏览次数
+	FileViews      int64 `json:"file_views"`      // 文件查看次数
+	Downloads      int64 `json:"downloads"`       // 下载次数
+	UniqueVisitors int64 `json:"unique_visitors"` // 独立访客数(IP+UA)
+}
+
+type ShareAnalyticsPoint struct {
+	Date           string `json:"date"`
+	Views          int64  `json:"views"`
+	FileViews      int64  `json:"file_views"`
+	Downloads      int64  `json:"downloads"`
+	UniqueVisitors int64  `json:"unique_visitors"`
+}
+
+type ShareAnalyticsBucket struct {
+	Key   string `json:"key"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+type ShareFileAnalytics struct {
+	FileID         string `json:"file_id"`
+	DisplayName    string `json:"display_name"`
+	Views          int64  `json:"views"`
+	Downloads      int64  `json:"downloads"`
+	UniqueVisitors int64  `json:"unique_visitors"`
+}
+
+type ShareAnalyticsResponseDTO struct {
+	ShareID     string                 `json:"share_id,omitempty"`
+	StartDate   string                 `json:"start_date"`
+	EndDate     string                 `json:"end_date"`
+	Granularity string                 `json:"granularity"`
+	GeoEnabled  bool                   `json:"geo_enabled"`
+	Summary     ShareAnalyticsSummary  `json:"summary"`
+	TimeSeries  []ShareAnalyticsPoint  `json:"time_series"`
+	Referrers   []ShareAnalyticsBucket `json:"referrers"`
+	Browsers    []ShareAnalyticsBucket `json:"browsers"`
+	OS          []ShareAnalyticsBucket `json:"os"`
+	Devices     []ShareAnalyticsBucket `json:"devices"`
+	Countries   []ShareAnalyticsBucket `json:"countries"`
+	Files       []ShareFileAnalytics   `json:"files"`
+}
@@ -1,7 +1,7 @@
 package dto
 
 type ShareItemDTO struct {
-	ItemType string `json:"item_type" binding:"required,oneof=folder file"`
+	ItemType string `json:"item_type" binding:"required,oneof=folder file album"`
 	ItemID   string `json:"item_id" binding:"required"`
 }
 
@@ -27,7 +27,7 @@ func (d *CreateShareDTO) GetValidationMessages() map[string]string {
 		"Items.required":            "分享项目不能为空",
 		"Items.min":                 "至少需要分享一个项目",
 		"ItemType.required":         "项目类型不能为空",
-		"ItemType.oneof":            "项目类型必须是folder或file",
+		"ItemType.oneof":            "项目类型必须是folder、file或album",
 		"ItemID.required":           "项目ID不能为空",
 		"NotificationThreshold.min": "通知阈值必须大于0",
 	}
@@ -0,0 +1,97 @@
+package share
+
+import (
+	"fmt"
+	"pixelpunk/internal/controllers/share/dto"
+	"pixelpunk/internal/middleware"
+	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/share"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/logger"
+	"pixelpunk/pkg/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetShareAnalytics(c *gin.Context) {
+	shareID := c.Param("id")
+	userID := middleware.GetCurrentUserID(c)
+
+	var shareObj models.Share
+	if err := database.DB.Where("id = ? AND user_id = ?", shareID, userID).First(&shareObj).Error; err != nil {
+		errors.HandleError(c, errors.New(errors.CodeNotFound, "分享不存在或您无权访问"))
+		return
+	}
+
+	respondShareAnalytics(c, shareID)
+}
+
+func ExportShareAnalytics(c *gin.Context) {
+	shareID := c.Param("id")
+	userID := middleware.GetCurrentUserID(c)
+
+	var shareObj models.Share
+	if err := database.DB.Where("id = ? AND user_id = ?", shareID, userID).First(&shareObj).Error; err != nil {
+		errors.HandleError(c, errors.New(errors.CodeNotFound, "分享不存在或您无权访问"))
+		return
+	}
+
+	exportShareAnalyticsCSV(c, shareID)
+}
+
+func AdminGetShareAnalytics(c *gin.Context) {
+	respondShareAnalytics(c, c.Param("id"))
+}
+
+func AdminExportShareAnalytics(c *gin.Context) {
+	exportShareAnalyticsCSV(c, c.Param("id"))
+}
+
+func respondShareAnalytics(c *gin.Context, shareID string) {
+	var query dto.ShareAnalyticsQueryDTO
+	if err := c.ShouldBindQuery(&query); err != nil {
+		errors.HandleError(c, errors.New(errors.CodeInvalidParameter, "请求参数错误: "+err.Error()))
+		return
+	}
+
+	result, err := share.GetShareAnalytics(shareID, &query)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, result, "获取分享访问分析成功")
+}
+
+func exportShareAnalyticsCSV(c *gin.Context, shareID string) {
+	var query dto.ShareAnalyticsQueryDTO
+	if err := c.ShouldBindQuery(&query); err != nil {
+		errors.HandleError(c, errors.New(errors.CodeInvalidParameter, "请求参数错误: "+err.Error()))
+		return
+	}
+
+	exportType := c.DefaultQuery("type", "events")
+	if exportType != "events" && exportType != "series" && exportType != "files" {
+		errors.HandleError(c, errors.New(errors.CodeInvalidParameter, "导出类型必须是events、series或files"))
+		return
+	}
+
+	scope := shareID
+	if scope == "" {
+		scope = "all"
+	}
+	fileName := fmt.Sprintf("share_analytics_%s_%s_%s.csv", scope, exportType, time.Now().Format("20060102150405"))
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", utils.SetContentDispositionFilename(fileName))
+
+	if err := share.ExportShareAnalyticsCSV(c.Writer, shareID, exportType, &query); err != nil {
+		if !c.Writer.Written() {
+			errors.HandleError(c, err)
+			return
+		}
+		logger.Error("导出分享访问分析失败: %v", err)
+	}
+}
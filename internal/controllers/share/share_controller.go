@@ -164,8 +164,9 @@ func ViewShare(c *gin.Context) {
 	}
 
 	folderID := c.Query("folder_id")
+	albumID := c.Query("album_id")
 
-	data, err := share.GetShareForView(shareKey, folderID)
+	data, err := share.GetShareForView(shareKey, folderID, albumID)
 	if err != nil {
 		errors.HandleError(c, err)
 		return
@@ -375,7 +376,18 @@ func DownloadSharedFile(c *gin.Context) {
 		return
 	}
 
-	result, isLocal, isProxy, err := filesvc.ServeFile(file, false)
+	// 分享下载同样遵循访问时水印策略
+	watermarkConfig := middleware.ResolveServeWatermark(file, shareKey, false, false)
+
+	var (
+		result           interface{}
+		isLocal, isProxy bool
+	)
+	if watermarkConfig != "" {
+		result, isLocal, isProxy, err = filesvc.ServeWatermarkedFile(file, false, watermarkConfig)
+	} else {
+		result, isLocal, isProxy, err = filesvc.ServeFile(file, false)
+	}
 	if err != nil {
 		errors.HandleError(c, err)
 		return
@@ -395,6 +407,12 @@ func DownloadSharedFile(c *gin.Context) {
 		}
 	}()
 
+	go func(ip, userAgent, referer string) {
+		if err := share.LogShareFileEvent(shareKey, fileID, common.ShareEventDownload, ip, userAgent, referer); err != nil {
+			logger.Error("记录分享下载事件失败: %v", err)
+		}
+	}(c.ClientIP(), c.Request.UserAgent(), c.Request.Referer())
+
 	fileName := file.DisplayName
 	if fileName == "" {
 		fileName = file.OriginalName
@@ -404,7 +422,9 @@ func DownloadSharedFile(c *gin.Context) {
 
 	c.Header("Content-Type", "application/octet-stream")
 	c.Header("Content-Disposition", utils.SetContentDispositionFilename(fileName))
-	c.Header("Content-Length", fmt.Sprintf("%d", file.Size))
+	if watermarkConfig == "" {
+		c.Header("Content-Length", fmt.Sprintf("%d", file.Size))
+	}
 	c.Header("Accept-Ranges", "bytes")
 
 	switch {
@@ -413,6 +433,10 @@ func DownloadSharedFile(c *gin.Context) {
 	case isProxy:
 		proxyResp := result.(*filesvc.ProxyResponse)
 		defer proxyResp.Content.Close()
+		if file.IsVideo() {
+			utils.ServeReaderRange(c, proxyResp.Content, file.Size, "application/octet-stream", proxyResp.OpenRange)
+			return
+		}
 		c.Status(http.StatusOK)
 		io.Copy(c.Writer, proxyResp.Content)
 	default:
@@ -423,6 +447,10 @@ func DownloadSharedFile(c *gin.Context) {
 			return
 		}
 		defer fileReader.Close()
+		if file.IsVideo() {
+			utils.ServeReaderRange(c, fileReader, file.Size, "application/octet-stream", filesvc.RemoteRangeOpener(file, false))
+			return
+		}
 		c.Status(http.StatusOK)
 		io.Copy(c.Writer, fileReader)
 	}
@@ -0,0 +1,115 @@
+package share
+
+import (
+	filedto "pixelpunk/internal/controllers/file/dto"
+	"pixelpunk/internal/models"
+	filesvc "pixelpunk/internal/services/file"
+	"pixelpunk/internal/services/share"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetShareTimeline 按拍摄日期统计分享内的文件数量
+func GetShareTimeline(c *gin.Context) {
+	req, err := common.ValidateRequest[filedto.TimelineQueryDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	_, params, ok := sharedTimelineScope(c)
+	if !ok {
+		return
+	}
+	if err := filesvc.ApplyTakenDate(&params, req.Year, req.Month, 0); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	granularity := req.Granularity
+	if granularity == "" {
+		granularity = filesvc.TimelineMonth
+	}
+	buckets, err := filesvc.GetTimeline(params, granularity)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+	errors.ResponseSuccess(c, gin.H{
+		"granularity": granularity,
+		"buckets":     buckets,
+	}, "获取时间线成功")
+}
+
+// GetShareTimelineFiles 按拍摄日期分页浏览分享内的文件
+func GetShareTimelineFiles(c *gin.Context) {
+	req, err := common.ValidateRequest[filedto.TimelineFilesQueryDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	shareInfo, params, ok := sharedTimelineScope(c)
+	if !ok {
+		return
+	}
+	if err := filesvc.ApplyTakenDate(&params, req.Year, req.Month, req.Day); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+	params.Sort = filesvc.TimelineSort(req.Order)
+	params.Page, params.Size = req.Page, req.Size
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Size <= 0 {
+		params.Size = 20
+	}
+
+	files, total, err := share.GetSharedTimelineFiles(shareInfo, params)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+	errors.ResponseSuccess(c, gin.H{
+		"items": files,
+		"pagination": gin.H{
+			"total":        total,
+			"size":         params.Size,
+			"current_page": params.Page,
+			"last_page":    (total + int64(params.Size) - 1) / int64(params.Size),
+		},
+	}, "获取成功")
+}
+
+// sharedTimelineScope 校验分享及访问令牌，返回分享内文件的查询范围
+func sharedTimelineScope(c *gin.Context) (models.Share, filesvc.AdminFileSearchParams, bool) {
+	shareKey := c.Param("key")
+	shareInfo, err := share.GetShareByKey(shareKey)
+	if err != nil {
+		errors.HandleError(c, errors.New(errors.CodeNotFound, "分享不存在或已失效"))
+		return models.Share{}, filesvc.AdminFileSearchParams{}, false
+	}
+
+	if shareInfo.Password != "" {
+		accessToken := c.Query("access_token")
+		if accessToken == "" {
+			errors.HandleError(c, errors.New(errors.CodeUnauthorized, "需要提供访问令牌"))
+			return models.Share{}, filesvc.AdminFileSearchParams{}, false
+		}
+		valid, err := share.ValidateAccessToken(shareKey, accessToken)
+		if err != nil || !valid {
+			errors.HandleError(c, errors.New(errors.CodeUnauthorized, "访问令牌无效或已过期"))
+			return models.Share{}, filesvc.AdminFileSearchParams{}, false
+		}
+	}
+
+	params, err := share.SharedTimelineScope(shareInfo)
+	if err != nil {
+		errors.HandleError(c, err)
+		return models.Share{}, filesvc.AdminFileSearchParams{}, false
+	}
+	return shareInfo, params, true
+}
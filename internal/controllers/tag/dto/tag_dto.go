@@ -215,3 +215,32 @@ type TagListResponseDTO struct {
 	Total      int64                     `json:"total"`
 	Pagination map[string]interface{}    `json:"pagination,omitempty"`
 }
+
+// SetTagParentDTO 设置父标签，parent_id 为空或0时移到顶层
+type SetTagParentDTO struct {
+	ParentID *uint `json:"parent_id"`
+}
+
+// TagAliasesDTO 添加标签同义词
+type TagAliasesDTO struct {
+	Aliases []string `json:"aliases" binding:"required,min=1,max=50,dive,required,max=50"`
+}
+
+func (d *TagAliasesDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Aliases.required": "同义词不能为空",
+		"Aliases.min":      "至少需要一个同义词",
+		"Aliases.max":      "一次最多添加50个同义词",
+	}
+}
+
+// ExportTaxonomyQueryDTO 导出标签体系
+type ExportTaxonomyQueryDTO struct {
+	Format string `form:"format" binding:"omitempty,oneof=json csv"`
+}
+
+func (d *ExportTaxonomyQueryDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Format.oneof": "导出格式必须是json或csv",
+	}
+}
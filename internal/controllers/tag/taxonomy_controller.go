@@ -0,0 +1,203 @@
+package tag
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"pixelpunk/internal/controllers/tag/dto"
+	"pixelpunk/internal/middleware"
+	tagService "pixelpunk/internal/services/tag"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/logger"
+	"pixelpunk/pkg/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxTaxonomyFileSize 标签体系导入文件的大小上限
+const maxTaxonomyFileSize = 10 << 20
+
+func parseTagIDParam(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 32)
+	if err != nil || id == 0 {
+		errors.HandleError(c, errors.New(errors.CodeInvalidParameter, "无效的标签ID"))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// GetTagTree 获取标签树
+func GetTagTree(c *gin.Context) {
+	tree, err := tagService.NewGlobalTagService().GetTagTree()
+	if err != nil {
+		errors.HandleError(c, errors.New(errors.CodeInternal, fmt.Sprintf("获取标签树失败: %v", err)))
+		return
+	}
+
+	errors.ResponseSuccess(c, tree, "获取标签树成功")
+}
+
+// SetTagParent 设置父标签
+func SetTagParent(c *gin.Context) {
+	tagID, ok := parseTagIDParam(c, "tag_id")
+	if !ok {
+		return
+	}
+
+	req, err := common.ValidateRequest[dto.SetTagParentDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	tag, err := tagService.NewGlobalTagService().SetTagParent(tagID, req.ParentID)
+	if err != nil {
+		errors.HandleError(c, errors.New(errors.CodeInvalidParameter, fmt.Sprintf("设置父标签失败: %v", err)))
+		return
+	}
+
+	errors.ResponseSuccess(c, tag, "设置父标签成功")
+}
+
+// ListTagAliases 获取标签同义词
+func ListTagAliases(c *gin.Context) {
+	tagID, ok := parseTagIDParam(c, "tag_id")
+	if !ok {
+		return
+	}
+
+	aliases, err := tagService.NewGlobalTagService().ListTagAliases(tagID)
+	if err != nil {
+		errors.HandleError(c, errors.New(errors.CodeInternal, fmt.Sprintf("获取同义词失败: %v", err)))
+		return
+	}
+
+	errors.ResponseSuccess(c, aliases, "获取同义词成功")
+}
+
+// AddTagAliases 添加标签同义词
+func AddTagAliases(c *gin.Context) {
+	tagID, ok := parseTagIDParam(c, "tag_id")
+	if !ok {
+		return
+	}
+
+	req, err := common.ValidateRequest[dto.TagAliasesDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	aliases, err := tagService.NewGlobalTagService().AddTagAliases(tagID, req.Aliases)
+	if err != nil {
+		errors.HandleError(c, errors.New(errors.CodeInvalidParameter, fmt.Sprintf("添加同义词失败: %v", err)))
+		return
+	}
+
+	errors.ResponseSuccess(c, aliases, "添加同义词成功")
+}
+
+// RemoveTagAlias 删除标签同义词
+func RemoveTagAlias(c *gin.Context) {
+	tagID, ok := parseTagIDParam(c, "tag_id")
+	if !ok {
+		return
+	}
+	aliasID, err := strconv.ParseUint(c.Param("alias_id"), 10, 32)
+	if err != nil || aliasID == 0 {
+		errors.HandleError(c, errors.New(errors.CodeInvalidParameter, "无效的同义词ID"))
+		return
+	}
+
+	if err := tagService.NewGlobalTagService().RemoveTagAlias(tagID, uint(aliasID)); err != nil {
+		errors.HandleError(c, errors.New(errors.CodeInvalidParameter, fmt.Sprintf("删除同义词失败: %v", err)))
+		return
+	}
+
+	errors.ResponseSuccess(c, nil, "删除同义词成功")
+}
+
+// ExportTaxonomy 导出标签体系（标签层级、同义词与分类模板）
+func ExportTaxonomy(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.ExportTaxonomyQueryDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	taxonomy, err := tagService.NewGlobalTagService().ExportTaxonomy()
+	if err != nil {
+		errors.HandleError(c, errors.New(errors.CodeInternal, fmt.Sprintf("导出标签体系失败: %v", err)))
+		return
+	}
+
+	format := req.Format
+	if format == "" {
+		format = "json"
+	}
+	fileName := fmt.Sprintf("taxonomy_%s.%s", time.Now().Format("20060102150405"), format)
+	c.Header("Content-Disposition", utils.SetContentDispositionFilename(fileName))
+
+	if format == "csv" {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		if err := tagService.WriteTaxonomyCSV(c.Writer, taxonomy); err != nil {
+			logger.Error("导出标签体系失败: %v", err)
+		}
+		return
+	}
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	encoder := json.NewEncoder(c.Writer)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(taxonomy); err != nil {
+		logger.Error("导出标签体系失败: %v", err)
+	}
+}
+
+// ImportTaxonomy 导入标签体系，上传字段为 file，格式按扩展名判断
+func ImportTaxonomy(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		errors.HandleError(c, errors.New(errors.CodeInvalidParameter, "请上传标签体系文件"))
+		return
+	}
+	if fileHeader.Size > maxTaxonomyFileSize {
+		errors.HandleError(c, errors.New(errors.CodeInvalidParameter, "导入文件不能超过10MB"))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		errors.HandleError(c, errors.New(errors.CodeInvalidParameter, "读取导入文件失败"))
+		return
+	}
+	defer file.Close()
+
+	var taxonomy *tagService.Taxonomy
+	switch strings.ToLower(filepath.Ext(fileHeader.Filename)) {
+	case ".csv":
+		taxonomy, err = tagService.ParseTaxonomyCSV(file)
+	case ".json":
+		taxonomy, err = tagService.ParseTaxonomyJSON(file)
+	default:
+		errors.HandleError(c, errors.New(errors.CodeInvalidParameter, "仅支持json或csv文件"))
+		return
+	}
+	if err != nil {
+		errors.HandleError(c, errors.New(errors.CodeInvalidParameter, err.Error()))
+		return
+	}
+
+	overwrite := c.PostForm("overwrite") == "true"
+	result, err := tagService.NewGlobalTagService().ImportTaxonomy(middleware.GetCurrentUserID(c), taxonomy, overwrite)
+	if err != nil {
+		errors.HandleError(c, errors.New(errors.CodeInternal, fmt.Sprintf("导入标签体系失败: %v", err)))
+		return
+	}
+
+	errors.ResponseSuccess(c, result, "导入标签体系完成")
+}
@@ -0,0 +1,12 @@
+package dto
+
+// ExifPolicyDTO 设置上传元数据策略，为空表示使用系统设置
+type ExifPolicyDTO struct {
+	Policy string `json:"policy" binding:"omitempty,oneof=keep strip_gps strip_serial strip_all"`
+}
+
+func (d *ExifPolicyDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Policy.oneof": "元数据策略必须是 keep、strip_gps、strip_serial 或 strip_all",
+	}
+}
@@ -0,0 +1,12 @@
+package dto
+
+type RefreshTokenDTO struct {
+	RefreshToken string `json:"refresh_token" binding:"required,max=128"`
+}
+
+func (r *RefreshTokenDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"RefreshToken.required": "刷新令牌不能为空",
+		"RefreshToken.max":      "刷新令牌格式不正确",
+	}
+}
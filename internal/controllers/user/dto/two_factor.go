@@ -0,0 +1,26 @@
+package dto
+
+type TwoFactorLoginDTO struct {
+	Challenge string `json:"challenge" binding:"required,max=64"`
+	Code      string `json:"code" binding:"required,max=32"`
+}
+
+func (r *TwoFactorLoginDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Challenge.required": "验证请求无效，请重新登录",
+		"Challenge.max":      "验证请求无效，请重新登录",
+		"Code.required":      "请输入验证码或恢复码",
+		"Code.max":           "验证码格式不正确",
+	}
+}
+
+type TwoFactorCodeDTO struct {
+	Code string `json:"code" binding:"required,max=32"`
+}
+
+func (r *TwoFactorCodeDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Code.required": "请输入验证码",
+		"Code.max":      "验证码格式不正确",
+	}
+}
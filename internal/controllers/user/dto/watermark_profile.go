@@ -0,0 +1,46 @@
+package dto
+
+import "encoding/json"
+
+// WatermarkProfileDTO 创建或更新水印方案
+type WatermarkProfileDTO struct {
+	Name      string          `json:"name" binding:"required,max=100"`
+	Config    json.RawMessage `json:"config" binding:"required"`
+	IsDefault bool            `json:"is_default"`
+}
+
+func (d *WatermarkProfileDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Name.required":   "方案名称不能为空",
+		"Name.max":        "方案名称不能超过100个字符",
+		"Config.required": "水印配置不能为空",
+	}
+}
+
+// SetDefaultWatermarkProfileDTO 设置默认水印方案，profile_id 为0时取消默认
+type SetDefaultWatermarkProfileDTO struct {
+	ProfileID uint `json:"profile_id"`
+}
+
+func (d *SetDefaultWatermarkProfileDTO) GetValidationMessages() map[string]string {
+	return map[string]string{}
+}
+
+// WatermarkPolicyDTO 创建或更新访问时水印策略
+type WatermarkPolicyDTO struct {
+	Scope             string `json:"scope" binding:"required,oneof=folder share access_level"`
+	TargetID          string `json:"target_id" binding:"required,max=32"`
+	ProfileID         uint   `json:"profile_id" binding:"required"`
+	ApplyToThumbnails bool   `json:"apply_to_thumbnails"`
+	Enabled           *bool  `json:"enabled"` // 为空时默认启用
+}
+
+func (d *WatermarkPolicyDTO) GetValidationMessages() map[string]string {
+	return map[string]string{
+		"Scope.required":     "策略范围不能为空",
+		"Scope.oneof":        "策略范围必须是 folder、share 或 access_level",
+		"TargetID.required":  "策略目标不能为空",
+		"TargetID.max":       "策略目标格式不正确",
+		"ProfileID.required": "请选择水印方案",
+	}
+}
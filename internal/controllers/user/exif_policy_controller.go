@@ -0,0 +1,36 @@
+package user
+
+import (
+	"pixelpunk/internal/controllers/user/dto"
+	"pixelpunk/internal/middleware"
+	"pixelpunk/internal/services/user"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetExifPolicy 获取上传元数据策略
+func GetExifPolicy(c *gin.Context) {
+	settings, err := user.GetUserSettings(middleware.GetCurrentUserID(c))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+	errors.ResponseSuccess(c, gin.H{"policy": settings.ExifPolicy}, "获取成功")
+}
+
+// UpdateExifPolicy 设置上传元数据策略
+func UpdateExifPolicy(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.ExifPolicyDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	if err := user.UpdateUserExifPolicy(middleware.GetCurrentUserID(c), req.Policy); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+	errors.ResponseSuccess(c, gin.H{"policy": req.Policy}, "设置成功")
+}
@@ -0,0 +1,126 @@
+package user
+
+import (
+	"strconv"
+
+	"pixelpunk/internal/controllers/user/dto"
+	"pixelpunk/internal/middleware"
+	"pixelpunk/internal/services/activity"
+	"pixelpunk/internal/services/user"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sessionClient 从请求中提取会话的客户端信息
+func sessionClient(c *gin.Context, method string) user.SessionClient {
+	return user.SessionClient{
+		IP:          utils.GetClientIP(c),
+		UserAgent:   c.Request.UserAgent(),
+		LoginMethod: method,
+	}
+}
+
+// currentSessionID 当前访问令牌绑定的会话ID，旧版令牌为空
+func currentSessionID(c *gin.Context) string {
+	if claims := middleware.GetCurrentUser(c); claims != nil {
+		return claims.SessionID
+	}
+	return ""
+}
+
+// RefreshToken 使用刷新令牌换取新的访问令牌（刷新令牌同时轮换）
+func RefreshToken(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.RefreshTokenDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	result, err := user.RefreshSession(req.RefreshToken, sessionClient(c, ""))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{
+		"token":              result.Token,
+		"refresh_token":      result.RefreshToken,
+		"session_id":         result.SessionID,
+		"expires_in":         result.ExpiresIn,
+		"refresh_expires_at": result.RefreshExpiresAt,
+		"userInfo":           result.UserInfo,
+	}, "刷新成功")
+}
+
+// Logout 退出登录，吊销当前会话
+func Logout(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	if sessionID := currentSessionID(c); sessionID != "" {
+		if err := user.RevokeSession(userID, sessionID, user.SessionRevokeLogout); err != nil {
+			errors.HandleError(c, err)
+			return
+		}
+	}
+
+	errors.ResponseSuccess(c, nil, "已退出登录")
+}
+
+func ListSessions(c *gin.Context) {
+	items, err := user.ListUserSessions(middleware.GetCurrentUserID(c), currentSessionID(c))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{"items": items}, "获取成功")
+}
+
+func RevokeSession(c *gin.Context) {
+	sessionID := c.Param("session_id")
+	if sessionID == "" {
+		errors.HandleError(c, errors.New(errors.CodeInvalidParameter, "会话ID不能为空"))
+		return
+	}
+
+	userID := middleware.GetCurrentUserID(c)
+	if err := user.RevokeSession(userID, sessionID, user.SessionRevokeUser); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	activity.LogSessionRevoke(userID, "single", 1, userID)
+	errors.ResponseSuccess(c, nil, "已退出该设备")
+}
+
+func RevokeOtherSessions(c *gin.Context) {
+	userID := middleware.GetCurrentUserID(c)
+	count, err := user.RevokeOtherSessions(userID, currentSessionID(c), user.SessionRevokeOthers)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	activity.LogSessionRevoke(userID, "others", count, userID)
+	errors.ResponseSuccess(c, gin.H{"count": count}, "已退出其它设备")
+}
+
+// AdminLogoutUserEverywhere 管理员强制用户在所有设备下线
+func AdminLogoutUserEverywhere(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		errors.HandleError(c, errors.New(errors.CodeInvalidParameter, "用户ID格式不正确"))
+		return
+	}
+
+	count, err := user.RevokeAllUserSessions(uint(id), user.SessionRevokeAdmin)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	activity.LogSessionRevoke(uint(id), "admin_all", count, middleware.GetCurrentUserID(c))
+	errors.ResponseSuccess(c, gin.H{"count": count}, "已强制该用户在所有设备下线")
+}
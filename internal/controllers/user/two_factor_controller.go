@@ -0,0 +1,125 @@
+package user
+
+import (
+	"strconv"
+
+	"pixelpunk/internal/controllers/user/dto"
+	"pixelpunk/internal/middleware"
+	"pixelpunk/internal/services/activity"
+	"pixelpunk/internal/services/user"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LoginTwoFactor 提交两步验证码（或恢复码）完成登录
+func LoginTwoFactor(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.TwoFactorLoginDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	result, err := user.VerifyTwoFactorLogin(req.Challenge, req.Code, sessionClient(c, "2fa"))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	respondLoginResult(c, result)
+}
+
+func GetTwoFactorStatus(c *gin.Context) {
+	status, err := user.GetTwoFactorStatus(middleware.GetCurrentUserID(c))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, status, "获取两步验证状态成功")
+}
+
+// SetupTwoFactor 生成TOTP密钥与二维码配置URI，需调用 EnableTwoFactor 验证后才生效
+func SetupTwoFactor(c *gin.Context) {
+	setup, err := user.SetupTwoFactor(middleware.GetCurrentUserID(c))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, setup, "请使用验证器扫描二维码")
+}
+
+func EnableTwoFactor(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.TwoFactorCodeDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	userID := middleware.GetCurrentUserID(c)
+	codes, err := user.EnableTwoFactor(userID, req.Code)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	activity.LogTwoFactorChange(userID, "enable", userID)
+
+	errors.ResponseSuccess(c, gin.H{"recovery_codes": codes}, "两步验证已启用，请妥善保存恢复码")
+}
+
+func DisableTwoFactor(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.TwoFactorCodeDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	userID := middleware.GetCurrentUserID(c)
+	if err := user.DisableTwoFactor(userID, middleware.GetCurrentUserRole(c), req.Code); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	activity.LogTwoFactorChange(userID, "disable", userID)
+
+	errors.ResponseSuccess(c, nil, "两步验证已关闭")
+}
+
+func RegenerateRecoveryCodes(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.TwoFactorCodeDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	userID := middleware.GetCurrentUserID(c)
+	codes, err := user.RegenerateRecoveryCodes(userID, req.Code)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	activity.LogTwoFactorChange(userID, "regenerate_recovery_codes", userID)
+
+	errors.ResponseSuccess(c, gin.H{"recovery_codes": codes}, "恢复码已重新生成")
+}
+
+func AdminResetTwoFactor(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		errors.HandleError(c, errors.New(errors.CodeInvalidParameter, "用户ID格式不正确"))
+		return
+	}
+
+	if err := user.AdminResetTwoFactor(uint(id)); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	activity.LogTwoFactorChange(uint(id), "admin_reset", middleware.GetCurrentUserID(c))
+
+	errors.ResponseSuccess(c, nil, "已重置该用户的两步验证")
+}
@@ -37,12 +37,26 @@ func Login(c *gin.Context) {
 		return
 	}
 
-	userInfo, token, err := user.Login(req.Account, req.Password)
+	result, err := user.Login(req.Account, req.Password, sessionClient(c, "password"))
 	if err != nil {
 		errors.HandleError(c, err)
 		return
 	}
 
+	respondLoginResult(c, result)
+}
+
+/* respondLoginResult 输出登录结果：需要两步验证时仅返回挑战ID */
+func respondLoginResult(c *gin.Context, result *user.LoginResult) {
+	if result.TwoFactorRequired {
+		errors.ResponseSuccess(c, gin.H{
+			"two_factor_required": true,
+			"challenge":           result.Challenge,
+		}, "请输入两步验证码")
+		return
+	}
+
+	userInfo := result.UserInfo
 	email := ""
 	if val, ok := userInfo["email"]; ok {
 		if emailStr, isString := val.(string); isString {
@@ -51,9 +65,16 @@ func Login(c *gin.Context) {
 	}
 
 	data := gin.H{
-		"token":    token,
-		"userInfo": userInfo,
-		"email":    email,
+		"token":              result.Token,
+		"userInfo":           userInfo,
+		"email":              email,
+		"refresh_token":      result.RefreshToken,
+		"session_id":         result.SessionID,
+		"expires_in":         result.ExpiresIn,
+		"refresh_expires_at": result.RefreshExpiresAt,
+	}
+	if result.TwoFactorSetupRequired {
+		data["two_factor_setup_required"] = true
 	}
 
 	if userIDVal, ok := userInfo["id"]; ok {
@@ -133,6 +154,14 @@ func UpdatePassword(c *gin.Context) {
 		return
 	}
 
+	// 修改密码后其它设备需要重新登录
+	if claims := middleware.GetCurrentUser(c); claims != nil {
+		if _, err := user.RevokeOtherSessions(userID, claims.SessionID, user.SessionRevokePassword); err != nil {
+			errors.HandleError(c, err)
+			return
+		}
+	}
+
 	activity.LogPasswordChange(userID)
 
 	errors.ResponseSuccess(c, nil, "密码修改成功")
@@ -0,0 +1,177 @@
+package user
+
+import (
+	"strconv"
+
+	"pixelpunk/internal/controllers/user/dto"
+	"pixelpunk/internal/middleware"
+	"pixelpunk/internal/services/user"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+func parseWatermarkProfileID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("profile_id"), 10, 32)
+	if err != nil || id == 0 {
+		errors.HandleError(c, errors.New(errors.CodeInvalidParameter, "无效的水印方案ID"))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// ListWatermarkProfiles 获取水印方案列表
+func ListWatermarkProfiles(c *gin.Context) {
+	profiles, err := user.ListWatermarkProfiles(middleware.GetCurrentUserID(c))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{"items": profiles}, "获取成功")
+}
+
+// CreateWatermarkProfile 创建水印方案
+func CreateWatermarkProfile(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.WatermarkProfileDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	profile, err := user.CreateWatermarkProfile(middleware.GetCurrentUserID(c), req)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, profile, "创建成功")
+}
+
+// UpdateWatermarkProfile 更新水印方案
+func UpdateWatermarkProfile(c *gin.Context) {
+	profileID, ok := parseWatermarkProfileID(c)
+	if !ok {
+		return
+	}
+
+	req, err := common.ValidateRequest[dto.WatermarkProfileDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	profile, err := user.UpdateWatermarkProfile(middleware.GetCurrentUserID(c), profileID, req)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, profile, "更新成功")
+}
+
+// DeleteWatermarkProfile 删除水印方案
+func DeleteWatermarkProfile(c *gin.Context) {
+	profileID, ok := parseWatermarkProfileID(c)
+	if !ok {
+		return
+	}
+
+	if err := user.DeleteWatermarkProfile(middleware.GetCurrentUserID(c), profileID); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, nil, "删除成功")
+}
+
+// SetDefaultWatermarkProfile 设置或取消默认水印方案
+func SetDefaultWatermarkProfile(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.SetDefaultWatermarkProfileDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	if err := user.SetDefaultWatermarkProfile(middleware.GetCurrentUserID(c), req.ProfileID); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, nil, "设置成功")
+}
+
+func parseWatermarkPolicyID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("policy_id"), 10, 32)
+	if err != nil || id == 0 {
+		errors.HandleError(c, errors.New(errors.CodeInvalidParameter, "无效的水印策略ID"))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// ListWatermarkPolicies 获取访问时水印策略列表
+func ListWatermarkPolicies(c *gin.Context) {
+	policies, err := user.ListWatermarkPolicies(middleware.GetCurrentUserID(c))
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, gin.H{"items": policies}, "获取成功")
+}
+
+// CreateWatermarkPolicy 创建访问时水印策略
+func CreateWatermarkPolicy(c *gin.Context) {
+	req, err := common.ValidateRequest[dto.WatermarkPolicyDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	policy, err := user.CreateWatermarkPolicy(middleware.GetCurrentUserID(c), req)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, policy, "创建成功")
+}
+
+// UpdateWatermarkPolicy 更新访问时水印策略
+func UpdateWatermarkPolicy(c *gin.Context) {
+	policyID, ok := parseWatermarkPolicyID(c)
+	if !ok {
+		return
+	}
+
+	req, err := common.ValidateRequest[dto.WatermarkPolicyDTO](c)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	policy, err := user.UpdateWatermarkPolicy(middleware.GetCurrentUserID(c), policyID, req)
+	if err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, policy, "更新成功")
+}
+
+// DeleteWatermarkPolicy 删除访问时水印策略
+func DeleteWatermarkPolicy(c *gin.Context) {
+	policyID, ok := parseWatermarkPolicyID(c)
+	if !ok {
+		return
+	}
+
+	if err := user.DeleteWatermarkPolicy(middleware.GetCurrentUserID(c), policyID); err != nil {
+		errors.HandleError(c, err)
+		return
+	}
+
+	errors.ResponseSuccess(c, nil, "删除成功")
+}
@@ -0,0 +1,21 @@
+package cron
+
+import (
+	"pixelpunk/internal/services/automation"
+	"pixelpunk/pkg/logger"
+)
+
+func registerAutomationLogCleanupTask() {
+	// 清理30天前的自动化规则执行日志 - 每天凌晨4点20分执行
+	_, err := cronManager.AddFunc("0 20 4 * * *", func() {
+		count, err := automation.CleanupRuleLogs(30)
+		if err != nil {
+			logger.Error("清理自动化规则日志失败: %v", err)
+		} else if count > 0 {
+			logger.Info("清理自动化规则日志: %d", count)
+		}
+	})
+	if err != nil {
+		logger.Error("注册自动化规则日志清理任务失败: %v", err)
+	}
+}
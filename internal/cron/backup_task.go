@@ -0,0 +1,23 @@
+package cron
+
+import (
+	"pixelpunk/internal/services/backup"
+	"pixelpunk/pkg/config"
+	"pixelpunk/pkg/logger"
+)
+
+func registerBackupTask() {
+	// 定时备份 - 按配置的 backup.schedule 执行，为空时不启用
+	schedule := config.GetConfig().Backup.Schedule
+	if schedule == "" {
+		return
+	}
+	_, err := cronManager.AddFunc(schedule, func() {
+		if _, err := backup.RunScheduled(); err != nil {
+			logger.Error("定时备份失败: %v", err)
+		}
+	})
+	if err != nil {
+		logger.Error("注册定时备份任务失败: %v", err)
+	}
+}
@@ -1,6 +1,8 @@
 package cron
 
 import (
+	"os"
+	"path/filepath"
 	"pixelpunk/internal/models"
 	"pixelpunk/pkg/common"
 	"pixelpunk/pkg/database"
@@ -24,10 +26,12 @@ func NewChunkedUploadCleanupJob() *ChunkedUploadCleanupJob {
 
 /* Execute 执行清理任务 */
 func (j *ChunkedUploadCleanupJob) Execute() error {
-	expiredTime := time.Now().Add(-24 * time.Hour)
+	now := time.Now()
+	expiredTime := now.Add(-24 * time.Hour)
 
+	// 超过24小时没有进展或已过有效期（tus 上传通过 Upload-Expires 告知客户端）的会话
 	var expiredSessions []models.UploadSession
-	err := j.db.Where("updated_at < ? AND status NOT IN (?)", expiredTime, []string{"completed", "failed"}).
+	err := j.db.Where("(updated_at < ? OR expires_at < ?) AND status NOT IN (?)", expiredTime, now, []string{"completed", "failed", "cleaned"}).
 		Find(&expiredSessions).Error
 	if err != nil {
 		logger.Error("查询过期上传会话失败: %v", err)
@@ -63,6 +67,10 @@ func (j *ChunkedUploadCleanupJob) cleanupSessionBatch(sessions []models.UploadSe
 				return err
 			}
 
+			if err := os.RemoveAll(filepath.Join("temp", "chunks", session.SessionID)); err != nil {
+				logger.Warn("删除会话 %s 的临时文件失败: %v", session.SessionID, err)
+			}
+
 			session.Status = "cleaned"
 			session.UpdatedAt = common.JSONTime(time.Now())
 			if err := tx.Save(&session).Error; err != nil {
@@ -76,6 +84,47 @@ func (j *ChunkedUploadCleanupJob) cleanupSessionBatch(sessions []models.UploadSe
 	})
 }
 
+/* CleanupLocalDirs 删除本进程上已结束或过期会话的分片临时目录
+ * Execute 只在主进程上执行，会话由其他进程接收时临时目录留在那个进程本地，由各进程自行清理 */
+func (j *ChunkedUploadCleanupJob) CleanupLocalDirs() error {
+	root := filepath.Join("temp", "chunks")
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	expiredTime := time.Now().Add(-24 * time.Hour)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		var session models.UploadSession
+		err := j.db.Select("status", "updated_at", "expires_at").Where("session_id = ?", entry.Name()).First(&session).Error
+		if err == nil && !sessionFinished(session, expiredTime) {
+			continue
+		}
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return err
+		}
+		if err := os.RemoveAll(filepath.Join(root, entry.Name())); err != nil {
+			logger.Warn("删除会话 %s 的临时文件失败: %v", entry.Name(), err)
+		}
+	}
+	return nil
+}
+
+/* sessionFinished 会话已结束，或与 Execute 相同的条件判断已过期 */
+func sessionFinished(session models.UploadSession, expiredTime time.Time) bool {
+	switch session.Status {
+	case "completed", "failed", "cleaned", "expired":
+		return true
+	}
+	return time.Time(session.UpdatedAt).Before(expiredTime) || session.IsExpired()
+}
+
 /* GetName 获取任务名称 */
 func (j *ChunkedUploadCleanupJob) GetName() string {
 	return "chunked_upload_cleanup"
@@ -1,6 +1,7 @@
 package cron
 
 import (
+	"pixelpunk/internal/cluster"
 	"pixelpunk/internal/services/ai"
 	"pixelpunk/internal/services/stats"
 	"pixelpunk/internal/services/tag"
@@ -13,10 +14,12 @@ import (
 )
 
 var cronManager *cron.Cron
+var nodeCron *cron.Cron
 var db *gorm.DB
 var taggingService *ai.TaggingService
+var leader *cluster.Leader
 
-/* InitCronManager 初始化定时任务管理器 */
+/* InitCronManager 初始化定时任务管理器，多个进程同时运行时只有选出的主进程执行任务 */
 func InitCronManager() {
 	db = database.GetDB()
 	if db == nil {
@@ -24,21 +27,54 @@ func InitCronManager() {
 		return
 	}
 
-	cronManager = cron.New(cron.WithSeconds())
+	leader = cluster.StartLeaderElection("cron")
+	cronManager = cron.New(cron.WithSeconds(), cron.WithChain(leaderOnly(leader)))
 
-	taggingService = ai.NewTaggingServiceWithConfig(db) // 使用配置的并发数
-	if taggingService == nil {
-		logger.Error("AI文件标记服务创建失败")
-		return
+	// 已有全局打标服务时复用，避免重复启动处理流水线
+	if ai.GetGlobalTaggingService() == nil {
+		if cluster.RunsWorkers() {
+			taggingService = ai.NewTaggingServiceWithConfig(db) // 使用配置的并发数
+			if taggingService == nil {
+				logger.Error("AI文件标记服务创建失败")
+				return
+			}
+			ai.SetGlobalTaggingService(taggingService)
+		} else if err := ai.InitTaggingQueueClient(); err != nil {
+			logger.Error("AI打标队列连接失败: %v", err)
+			return
+		}
 	}
 
-	ai.SetGlobalTaggingService(taggingService)
-
 	registerTasks()
 
 	cronManager.Start()
 }
 
+/* InitNodeTasks 启动在每个 api 进程上执行的定时任务，不参与选主
+ * 分片临时目录、水印缓存和导出压缩包保存在各个进程本地；导入和导出任务由认领到租约的进程处理 */
+func InitNodeTasks() {
+	if nodeCron != nil {
+		return
+	}
+	nodeCron = cron.New(cron.WithSeconds())
+	registerLocalChunkCleanupTask()
+	registerWatermarkCacheCleanupTask()
+	registerDataExportCleanupTask()
+	registerBackgroundJobResumeTask()
+	nodeCron.Start()
+}
+
+/* leaderOnly 非主进程跳过本次执行 */
+func leaderOnly(l *cluster.Leader) cron.JobWrapper {
+	return func(job cron.Job) cron.Job {
+		return cron.FuncJob(func() {
+			if l.IsLeader() {
+				job.Run()
+			}
+		})
+	}
+}
+
 func registerTasks() {
 
 	registerStatsTask()
@@ -59,6 +95,12 @@ func registerTasks() {
 
 	registerTagUsageCountCalibrationTask()
 
+	registerSignedLinkCleanupTask()
+	registerSessionCleanupTask()
+	registerAutomationLogCleanupTask()
+	registerGeoPlaceBackfillTask()
+	registerBackupTask()
+
 }
 
 func registerStatsTask() {
@@ -158,6 +200,19 @@ func registerChunkedUploadCleanupTask() {
 	}
 }
 
+func registerLocalChunkCleanupTask() {
+	cleanupJob := NewChunkedUploadCleanupJob()
+
+	_, err := nodeCron.AddFunc(cleanupJob.GetSchedule(), func() {
+		if err := cleanupJob.CleanupLocalDirs(); err != nil {
+			logger.Error("清理本地分片临时目录失败: %v", err)
+		}
+	})
+	if err != nil {
+		logger.Error("注册本地分片临时目录清理任务失败: %v", err)
+	}
+}
+
 func registerVectorVerificationTask() {
 	verificationJob := NewVectorVerificationJob()
 
@@ -203,6 +258,10 @@ func Stop() {
 	if cronManager != nil {
 		cronManager.Stop()
 	}
+	if nodeCron != nil {
+		nodeCron.Stop()
+	}
+	leader.Stop()
 
 	if taggingService != nil {
 		taggingService.Stop()
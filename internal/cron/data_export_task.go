@@ -0,0 +1,33 @@
+package cron
+
+import (
+	"pixelpunk/internal/services/dataexport"
+	filesvc "pixelpunk/internal/services/file"
+	"pixelpunk/pkg/logger"
+)
+
+func registerDataExportCleanupTask() {
+	// 删除过期的用户数据导出压缩包 - 每小时执行一次，压缩包保存在生成它的进程上，由 InitNodeTasks 注册
+	_, err := nodeCron.AddFunc("0 15 * * * *", func() {
+		count, err := dataexport.CleanupExpiredExports()
+		if err != nil {
+			logger.Error("清理过期数据导出失败: %v", err)
+		} else if count > 0 {
+			logger.Info("清理过期数据导出: %d", count)
+		}
+	})
+	if err != nil {
+		logger.Error("注册数据导出清理任务失败: %v", err)
+	}
+}
+
+func registerBackgroundJobResumeTask() {
+	// 接手处理进程已退出、租约过期的导入任务和数据导出 - 每分钟执行一次，由 InitNodeTasks 注册
+	_, err := nodeCron.AddFunc("0 * * * * *", func() {
+		filesvc.ResumeImportJobs()
+		dataexport.ResumeExports()
+	})
+	if err != nil {
+		logger.Error("注册后台任务接手任务失败: %v", err)
+	}
+}
@@ -0,0 +1,34 @@
+package cron
+
+import (
+	filesvc "pixelpunk/internal/services/file"
+	"pixelpunk/pkg/logger"
+)
+
+func registerGeoPlaceBackfillTask() {
+	// 从原图为历史 EXIF 补充坐标 - 每10分钟处理一批，需要读取原图，批次较小
+	_, err := cronManager.AddFunc("0 5-59/10 * * * *", func() {
+		count, err := filesvc.BackfillEXIFGPS(100)
+		if err != nil {
+			logger.Error("补充拍摄坐标失败: %v", err)
+		} else if count > 0 {
+			logger.Info("补充拍摄坐标: %d", count)
+		}
+	})
+	if err != nil {
+		logger.Error("注册拍摄坐标补充任务失败: %v", err)
+	}
+
+	// 为历史 EXIF 补充拍摄地点 - 每10分钟处理一批
+	_, err = cronManager.AddFunc("0 */10 * * * *", func() {
+		count, err := filesvc.BackfillGeoPlaces(500)
+		if err != nil {
+			logger.Error("补充拍摄地点失败: %v", err)
+		} else if count > 0 {
+			logger.Info("补充拍摄地点: %d", count)
+		}
+	})
+	if err != nil {
+		logger.Error("注册拍摄地点补充任务失败: %v", err)
+	}
+}
@@ -0,0 +1,21 @@
+package cron
+
+import (
+	usersvc "pixelpunk/internal/services/user"
+	"pixelpunk/pkg/logger"
+)
+
+func registerSessionCleanupTask() {
+	// 清理过期或吊销超过30天的登录会话 - 每天凌晨4点10分执行
+	_, err := cronManager.AddFunc("0 10 4 * * *", func() {
+		count, err := usersvc.CleanupExpiredSessions(30)
+		if err != nil {
+			logger.Error("清理过期登录会话失败: %v", err)
+		} else if count > 0 {
+			logger.Info("清理过期登录会话: %d", count)
+		}
+	})
+	if err != nil {
+		logger.Error("注册登录会话清理任务失败: %v", err)
+	}
+}
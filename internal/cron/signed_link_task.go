@@ -0,0 +1,21 @@
+package cron
+
+import (
+	filesvc "pixelpunk/internal/services/file"
+	"pixelpunk/pkg/logger"
+)
+
+func registerSignedLinkCleanupTask() {
+	// 清理过期超过30天的签名链接记录 - 每天凌晨4点执行
+	_, err := cronManager.AddFunc("0 0 4 * * *", func() {
+		count, err := filesvc.CleanupExpiredSignedLinks(30)
+		if err != nil {
+			logger.Error("清理过期签名链接失败: %v", err)
+		} else if count > 0 {
+			logger.Info("清理过期签名链接: %d", count)
+		}
+	})
+	if err != nil {
+		logger.Error("注册签名链接清理任务失败: %v", err)
+	}
+}
@@ -0,0 +1,21 @@
+package cron
+
+import (
+	filesvc "pixelpunk/internal/services/file"
+	"pixelpunk/pkg/logger"
+)
+
+func registerWatermarkCacheCleanupTask() {
+	// 清理7天未被访问的访问时水印缓存 - 每天凌晨4点30分执行，缓存在各进程本地，由 InitNodeTasks 注册
+	_, err := nodeCron.AddFunc("0 30 4 * * *", func() {
+		count, err := filesvc.CleanupWatermarkCache(7)
+		if err != nil {
+			logger.Error("清理水印缓存失败: %v", err)
+		} else if count > 0 {
+			logger.Info("清理水印缓存: %d", count)
+		}
+	})
+	if err != nil {
+		logger.Error("注册水印缓存清理任务失败: %v", err)
+	}
+}
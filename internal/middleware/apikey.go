@@ -10,6 +10,15 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// apiKeyUploadRoutes 通过API密钥创建文件的接口，请求前检查上传次数限制
+// 签名链接、取消导入任务等不产生文件的 POST 接口不受上传次数限制
+var apiKeyUploadRoutes = map[string]bool{
+	"/api/v1/external/upload":      true,
+	"/api/v1/external/import-url":  true,
+	"/api/v1/external/import-jobs": true,
+	"/api/v1/external/tus":         true,
+}
+
 func APIKeyAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if c.Request.Method == http.MethodOptions {
@@ -56,7 +65,7 @@ func APIKeyAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		if c.Request.Method == "POST" {
+		if c.Request.Method == http.MethodPost && apiKeyUploadRoutes[c.FullPath()] {
 			if !key.CheckUploadCountLimit() {
 				errors.HandleError(c, errors.New(errors.CodeForbidden, "已达到上传次数限制"))
 				c.Abort()
@@ -4,12 +4,15 @@ import (
 	"pixelpunk/internal/models"
 	"pixelpunk/internal/services/auth"
 	"pixelpunk/internal/services/setting"
+	userService "pixelpunk/internal/services/user"
 	"pixelpunk/pkg/cache"
 	"pixelpunk/pkg/common"
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/errors"
 	"pixelpunk/pkg/logger"
+	"pixelpunk/pkg/utils"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -170,6 +173,12 @@ func JWTAuth() gin.HandlerFunc {
 			return
 		}
 
+		if !accessSessionValid(c, claims) {
+			c.Set(AuthErrorKey, "登录已失效，请重新登录")
+			c.Next()
+			return
+		}
+
 		c.Set(ContextPayloadKey, claims)
 
 		// 检查用户是否被禁用（在JWT解析后立即检查，覆盖所有需要认证的接口）
@@ -184,6 +193,15 @@ func JWTAuth() gin.HandlerFunc {
 	}
 }
 
+// accessSessionValid 检查令牌所属的登录会话，会话已退出或被管理员强制下线时返回 false
+func accessSessionValid(c *gin.Context, claims *auth.JWTClaims) bool {
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+	return userService.ValidateAccessSession(claims.UserID, claims.SessionID, issuedAt, utils.GetClientIP(c))
+}
+
 func RequireAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if authError, exists := c.Get(AuthErrorKey); exists {
@@ -215,6 +233,10 @@ func RequireAdmin() gin.HandlerFunc {
 			c.Abort()
 			return
 		}
+
+		if !checkAdminTwoFactor(c, claims) {
+			return
+		}
 		c.Next()
 	}
 }
@@ -239,10 +261,24 @@ func RequireSuperAdmin() gin.HandlerFunc {
 			c.Abort()
 			return
 		}
+
+		if !checkAdminTwoFactor(c, claims) {
+			return
+		}
 		c.Next()
 	}
 }
 
+/* checkAdminTwoFactor 开启管理员强制两步验证后，未启用两步验证的管理员无法访问管理接口 */
+func checkAdminTwoFactor(c *gin.Context, claims *auth.JWTClaims) bool {
+	if userService.IsTwoFactorSetupRequired(claims.UserID, claims.Role) {
+		errors.HandleError(c, errors.New(errors.CodeTwoFactorSetupRequired, "管理员账号必须先启用两步验证"))
+		c.Abort()
+		return false
+	}
+	return true
+}
+
 /* RequireActiveUser 用户状态校验中间件（检查用户是否被禁用）
  * 使用Redis黑名单实现即时踢出，Redis不可用时降级为数据库查询
  * 建议用于：写操作、敏感接口、管理后台
@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"strings"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -26,10 +28,14 @@ func CORSMiddleware() gin.HandlerFunc {
 			c.Writer.Header().Set("Access-Control-Allow-Headers", baseAllowedHeaders)
 		}
 
-		c.Writer.Header().Set("Access-Control-Expose-Headers", "Content-Disposition, Content-Type, X-Request-Id, X-Request-ID")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "Content-Disposition, Content-Type, X-Request-Id, X-Request-ID, "+
+			"Location, Upload-Offset, Upload-Length, Upload-Metadata, Upload-Expires, X-File-Id, "+
+			"Tus-Resumable, Tus-Version, Tus-Extension, Tus-Max-Size, Tus-Checksum-Algorithm")
 		c.Writer.Header().Set("Access-Control-Max-Age", "86400")
 
-		if c.Request.Method == "OPTIONS" {
+		// tus 客户端用不带预检头的 OPTIONS 查询服务端能力，交给 tus 路由处理
+		isTusDiscovery := c.Request.Header.Get("Access-Control-Request-Method") == "" && strings.Contains(c.Request.URL.Path, "/tus")
+		if c.Request.Method == "OPTIONS" && !isTusDiscovery {
 			c.AbortWithStatus(204)
 			return
 		}
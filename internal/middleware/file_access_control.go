@@ -42,6 +42,12 @@ func FileAccessControlMiddleware() gin.HandlerFunc {
 		isThumbObj, _ := c.Get("isThumb")
 		isThumb, _ := isThumbObj.(bool)
 
+		// v1签名链接优先处理，签名存在但校验失败时直接拒绝，不再回退到其他访问方式
+		if utils.IsSignedURLQuery(c.Request.URL.Query()) {
+			handleSignedLinkAccess(c, file, isThumb)
+			return
+		}
+
 		if isSpecialAccessScenario(c) {
 			if !isThumb {
 				go updateFileStats(file.ID, file.UserID, file.Size)
@@ -61,6 +67,7 @@ func FileAccessControlMiddleware() gin.HandlerFunc {
 				assets.ServeDefaultFile(c, assets.FileTypeReview)
 				return
 			}
+			applyServeWatermarkPolicy(c, file, "", false)
 			c.Next()
 			return
 		}
@@ -71,6 +78,8 @@ func FileAccessControlMiddleware() gin.HandlerFunc {
 			if accessToken != "" {
 				valid, _ := share.ValidateAccessToken(shareKey, accessToken)
 				if valid {
+					recordShareFileView(c, shareKey, file.ID, isThumb)
+					applyServeWatermarkPolicy(c, file, shareKey, false)
 					c.Next()
 					return
 				}
@@ -78,6 +87,8 @@ func FileAccessControlMiddleware() gin.HandlerFunc {
 				return
 			}
 			if verifyShareAccess(c, shareKey, file.ID) {
+				recordShareFileView(c, shareKey, file.ID, isThumb)
+				applyServeWatermarkPolicy(c, file, shareKey, false)
 				c.Next()
 				return
 			}
@@ -94,27 +105,27 @@ func FileAccessControlMiddleware() gin.HandlerFunc {
 }
 
 func verifyShareAccess(c *gin.Context, shareKey string, fileID string) bool {
-	var share models.Share
-	if err := database.DB.Where("share_key = ? AND status = ?", shareKey, common.ShareStatusNormal).First(&share).Error; err != nil {
+	var shareModel models.Share
+	if err := database.DB.Where("share_key = ? AND status = ?", shareKey, common.ShareStatusNormal).First(&shareModel).Error; err != nil {
 		return false
 	}
 
-	if share.ExpiredAt != nil && time.Now().After(time.Time(*share.ExpiredAt)) {
-		database.DB.Model(&share).Update("status", common.ShareStatusExpired)
+	if shareModel.ExpiredAt != nil && time.Now().After(time.Time(*shareModel.ExpiredAt)) {
+		database.DB.Model(&shareModel).Update("status", common.ShareStatusExpired)
 		return false
 	}
 
-	if share.MaxViews > 0 && share.CurrentViews >= share.MaxViews {
+	if shareModel.MaxViews > 0 && shareModel.CurrentViews >= shareModel.MaxViews {
 		return false
 	}
 
-	if share.Password != "" {
+	if shareModel.Password != "" {
 		return false
 	}
 
 	var count int64
 	database.DB.Model(&models.ShareItem{}).
-		Where("share_id = ? AND item_type = ? AND item_id = ?", share.ID, common.ShareItemTypeFile, fileID).
+		Where("share_id = ? AND item_type = ? AND item_id = ?", shareModel.ID, common.ShareItemTypeFile, fileID).
 		Count(&count)
 	if count > 0 {
 		return true
@@ -126,7 +137,7 @@ func verifyShareAccess(c *gin.Context, shareKey string, fileID string) bool {
 	}
 
 	var folderShares []models.ShareItem
-	database.DB.Where("share_id = ? AND item_type = ?", share.ID, common.ShareItemTypeFolder).Find(&folderShares)
+	database.DB.Where("share_id = ? AND item_type = ?", shareModel.ID, common.ShareItemTypeFolder).Find(&folderShares)
 
 	for _, folderShare := range folderShares {
 		if targetImage.FolderID == folderShare.ItemID {
@@ -150,7 +161,53 @@ func verifyShareAccess(c *gin.Context, shareKey string, fileID string) bool {
 		}
 	}
 
-	return false
+	return share.IsFileInSharedAlbums(shareModel.ID, shareModel.UserID, fileID)
+}
+
+/* handleSignedLinkAccess 校验v1签名链接，通过后将链接信息写入上下文供文件输出使用 */
+func handleSignedLinkAccess(c *gin.Context, file models.File, isThumb bool) {
+	kind, identifier := models.SignedLinkKindFile, c.Param("fileID")
+	path := c.Request.URL.Path
+	if strings.HasPrefix(path, "/t/") || strings.HasPrefix(path, "/thumb/") {
+		kind = models.SignedLinkKindThumb
+	} else if strings.HasPrefix(path, "/s/") {
+		kind, identifier = models.SignedLinkKindShort, c.Param("shortURL")
+	}
+
+	continuation := utils.IsContinuationRange(c.GetHeader("Range"), file.Size)
+	link, counted, err := filesvc.VerifySignedLink(kind, identifier, file.ID, c.Request.URL.Query(), c.ClientIP(), continuation)
+	if err != nil {
+		logger.Debug("[ACCESS_CONTROL] 签名链接校验失败: fileID=%s, error=%v", file.ID, err)
+		assets.ServeDefaultFile(c, assets.FileTypeUnauthorized)
+		return
+	}
+
+	if file.Status == "pending_review" {
+		assets.ServeDefaultFile(c, assets.FileTypeReview)
+		return
+	}
+
+	if !isThumb && counted {
+		go updateFileStats(file.ID, file.UserID, file.Size)
+	}
+
+	c.Set("signed_link", link)
+	applyServeWatermarkPolicy(c, file, "", false)
+	c.Next()
+}
+
+/* recordShareFileView 异步记录通过分享查看原图的事件，缩略图不计入 */
+func recordShareFileView(c *gin.Context, shareKey, fileID string, isThumb bool) {
+	if isThumb {
+		return
+	}
+
+	ip, userAgent, referer := c.ClientIP(), c.Request.UserAgent(), c.Request.Referer()
+	go func() {
+		if err := share.LogShareFileEvent(shareKey, fileID, common.ShareEventFileView, ip, userAgent, referer); err != nil {
+			logger.Error("[ACCESS_CONTROL] 记录分享文件访问失败: %v", err)
+		}
+	}()
 }
 
 type FileAccessConfig struct {
@@ -191,6 +248,7 @@ func handleFileAccessLevel(c *gin.Context, file models.File, isInternalRequest b
 			return true
 		}
 
+		applyServeWatermarkPolicy(c, file, "", false)
 		c.Next()
 		return true
 
@@ -220,6 +278,7 @@ func handleProtectedAccess(c *gin.Context, file models.File, isInternalRequest b
 			return true
 		}
 
+		applyServeWatermarkPolicy(c, file, "", false)
 		c.Next()
 		return true
 	}
@@ -236,6 +295,7 @@ func handlePrivateAccess(c *gin.Context, file models.File) bool {
 		return true
 	}
 
+	applyServeWatermarkPolicy(c, file, "", false)
 	c.Next()
 	return true
 }
@@ -257,21 +317,21 @@ func handleUserAccessControl(c *gin.Context, file models.File) bool {
 	if config.EnableIPCheck {
 		isIPAllowed := access_control.CheckUserIP(config, ip, isIPInList)
 		if !isIPAllowed {
-			handleBlockAction(c, config, ip, domain)
+			handleBlockAction(c, file, config, ip, domain)
 			return false
 		}
 	}
 
 	if config.EnableRefererCheck {
 		if referer == "" && !config.AllowEmptyReferer {
-			handleBlockAction(c, config, ip, domain)
+			handleBlockAction(c, file, config, ip, domain)
 			return false
 		}
 
 		if referer != "" {
 			isDomainAllowed := access_control.CheckUserDomain(config, domain, referer, isDomainInList)
 			if !isDomainAllowed {
-				handleBlockAction(c, config, ip, domain)
+				handleBlockAction(c, file, config, ip, domain)
 				return false
 			}
 		}
@@ -280,7 +340,7 @@ func handleUserAccessControl(c *gin.Context, file models.File) bool {
 	return true
 }
 
-func handleBlockAction(c *gin.Context, config *models.UserAccessControl, ip, domain string) {
+func handleBlockAction(c *gin.Context, file models.File, config *models.UserAccessControl, ip, domain string) {
 	switch config.BlockAction {
 	case models.BlockActionRedirect:
 		if config.RedirectURL != "" {
@@ -292,7 +352,7 @@ func handleBlockAction(c *gin.Context, config *models.UserAccessControl, ip, dom
 	case models.BlockActionBlock:
 		assets.ServeDefaultFile(c, assets.FileTypeUnauthorized)
 	case models.BlockActionWatermark:
-		c.Set("watermark", true)
+		applyServeWatermarkPolicy(c, file, "", true)
 		c.Next()
 	case models.BlockActionThumbnail:
 		c.Set("forceThumbnail", true)
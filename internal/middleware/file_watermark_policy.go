@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"strings"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/auth"
+	"pixelpunk/internal/services/user"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServeWatermarkKey 上下文中访问时水印配置（JSON）的键
+const ServeWatermarkKey = "serve_watermark"
+
+// ServeWatermarkBypassedKey 上下文中标记所有者或管理员跳过了本应叠加的水印
+const ServeWatermarkBypassedKey = "serve_watermark_bypassed"
+
+// maxWatermarkFolderDepth 查找文件夹策略时向上遍历的最大层数
+const maxWatermarkFolderDepth = 64
+
+// hotlinkWatermarkConfig 防盗链选择"添加水印"且所有者没有默认水印方案时使用的水印
+const hotlinkWatermarkConfig = `{"enabled":true,"type":"text","text":"{username}","fontSize":24,"fontColor":"#ffffff","strokeColor":"#000000","strokeWidth":1,"opacity":0.5,"rotation":-30,"mode":"tiled"}`
+
+/* applyServeWatermarkPolicy 非所有者访问时解析应叠加的水印，写入上下文供文件输出使用
+ * 所有者或管理员访问时返回原图，但仍标记跳过了水印，避免原图响应被共享缓存
+ * forced 为 true 表示防盗链要求添加水印，没有匹配策略时使用默认水印 */
+func applyServeWatermarkPolicy(c *gin.Context, file models.File, shareKey string, forced bool) {
+	if !SupportsServeWatermark(file) {
+		return
+	}
+
+	isThumbObj, _ := c.Get("isThumb")
+	isThumb, _ := isThumbObj.(bool)
+
+	config := ResolveServeWatermark(file, shareKey, isThumb, forced)
+	if config == "" {
+		return
+	}
+	if isFileOwnerRequest(c, file) {
+		c.Set(ServeWatermarkBypassedKey, true)
+		return
+	}
+	c.Set(ServeWatermarkKey, config)
+}
+
+/* ResolveServeWatermark 按 文件上传时指定的水印 > 分享 > 文件夹 > 访问级别 的顺序解析访问时水印，无需水印时返回空字符串 */
+func ResolveServeWatermark(file models.File, shareKey string, isThumb, forced bool) string {
+	if !SupportsServeWatermark(file) {
+		return ""
+	}
+
+	if !isThumb && file.WatermarkConfig != "" {
+		return file.WatermarkConfig
+	}
+
+	if policy := matchWatermarkPolicy(file, shareKey); policy != nil && (!isThumb || policy.ApplyToThumbnails) {
+		var profile models.WatermarkProfile
+		if err := database.DB.Where("id = ? AND user_id = ?", policy.ProfileID, file.UserID).First(&profile).Error; err == nil {
+			return string(profile.Config)
+		}
+		logger.Warn("[WATERMARK] 水印策略引用的方案不存在: policy=%d, profile=%d", policy.ID, policy.ProfileID)
+	}
+
+	if forced {
+		if config, err := user.GetDefaultWatermarkConfig(file.UserID); err == nil && config != "" {
+			return config
+		}
+		return hotlinkWatermarkConfig
+	}
+	return ""
+}
+
+/* matchWatermarkPolicy 查找文件所有者对本次访问生效的水印策略 */
+func matchWatermarkPolicy(file models.File, shareKey string) *models.WatermarkPolicy {
+	var policies []models.WatermarkPolicy
+	if err := database.DB.Where("user_id = ? AND enabled = ?", file.UserID, true).Find(&policies).Error; err != nil || len(policies) == 0 {
+		return nil
+	}
+
+	byTarget := make(map[string]*models.WatermarkPolicy, len(policies))
+	hasFolderPolicy := false
+	for i := range policies {
+		byTarget[policies[i].Scope+":"+policies[i].TargetID] = &policies[i]
+		if policies[i].Scope == models.WatermarkPolicyScopeFolder {
+			hasFolderPolicy = true
+		}
+	}
+
+	if shareKey != "" {
+		var shareModel models.Share
+		if err := database.DB.Select("id").Where("share_key = ?", shareKey).First(&shareModel).Error; err == nil {
+			if policy, ok := byTarget[models.WatermarkPolicyScopeShare+":"+shareModel.ID]; ok {
+				return policy
+			}
+		}
+	}
+
+	if hasFolderPolicy {
+		folderID := file.FolderID
+		for depth := 0; folderID != "" && folderID != "0" && depth < maxWatermarkFolderDepth; depth++ {
+			if policy, ok := byTarget[models.WatermarkPolicyScopeFolder+":"+folderID]; ok {
+				return policy
+			}
+			var folder models.Folder
+			if err := database.DB.Select("id", "parent_id").Where("id = ?", folderID).First(&folder).Error; err != nil {
+				break
+			}
+			folderID = folder.ParentID
+		}
+	}
+
+	return byTarget[models.WatermarkPolicyScopeAccessLevel+":"+file.AccessLevel]
+}
+
+/* SupportsServeWatermark 仅静态位图支持访问时水印，其他文件不会被任何水印策略影响 */
+func SupportsServeWatermark(file models.File) bool {
+	if !file.IsImage() {
+		return false
+	}
+	switch strings.ToLower(file.Format) {
+	case "jpg", "jpeg", "png", "webp":
+		return true
+	}
+	return false
+}
+
+/* isFileOwnerRequest 文件所有者或管理员访问时返回原图
+ * 文件路由没有经过认证中间件时自行校验令牌，与认证中间件一样检查过期、登录会话和账号状态 */
+func isFileOwnerRequest(c *gin.Context, file models.File) bool {
+	claims := GetCurrentUser(c)
+	if claims == nil {
+		authHeader := c.GetHeader("Authorization")
+		jwtSecret := getJWTSecret()
+		if !strings.HasPrefix(authHeader, "Bearer ") || strings.TrimSpace(jwtSecret) == "" {
+			return false
+		}
+		parsed, err := auth.ParseToken(strings.TrimPrefix(authHeader, "Bearer "), jwtSecret)
+		if err != nil || parsed.ExpiresAt == nil || parsed.ExpiresAt.Unix() < auth.GetCurrentTimestamp() {
+			return false
+		}
+		if !accessSessionValid(c, parsed) || !checkUserActive(parsed) {
+			return false
+		}
+		claims = parsed
+	}
+	return claims.UserID == file.UserID || claims.Role == common.UserRoleAdmin || claims.Role == common.UserRoleSuperAdmin
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"net/http"
+
+	filesvc "pixelpunk/internal/services/file"
+
+	"github.com/gin-gonic/gin"
+)
+
+/* TusProtocol tus 协议公共处理：所有响应带 Tus-Resumable，OPTIONS 之外的请求版本不匹配时返回 412
+ * 需放在认证中间件之前，使认证失败的响应同样带有协议头 */
+func TusProtocol() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Tus-Resumable", filesvc.TusVersion)
+		if c.Request.Method != http.MethodOptions && c.GetHeader("Tus-Resumable") != filesvc.TusVersion {
+			c.Header("Tus-Version", filesvc.TusVersion)
+			c.AbortWithStatus(http.StatusPreconditionFailed)
+			return
+		}
+		c.Next()
+	}
+}
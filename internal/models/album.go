@@ -0,0 +1,49 @@
+package models
+
+import (
+	"encoding/json"
+
+	"pixelpunk/pkg/common"
+)
+
+const (
+	AlbumTypeManual = "manual" // 手动相册，引用任意文件夹中的文件
+	AlbumTypeSmart  = "smart"  // 智能相册，按保存的查询条件动态生成
+)
+
+/* Album 相册，不改变文件所在的文件夹 */
+type Album struct {
+	ID        string          `gorm:"primarykey;size:32" json:"id"`
+	CreatedAt common.JSONTime `json:"created_at"`
+	UpdatedAt common.JSONTime `json:"updated_at"`
+
+	UserID      uint            `gorm:"not null;index" json:"user_id"`
+	Name        string          `gorm:"size:100;not null" json:"name"`
+	Description string          `gorm:"size:500" json:"description"`
+	Type        string          `gorm:"size:10;not null;default:manual" json:"type"` // manual/smart
+	Query       json.RawMessage `gorm:"type:json" json:"query,omitempty"`            // 智能相册的查询条件
+	CoverFileID string          `gorm:"size:32" json:"cover_file_id"`
+	SortOrder   int             `gorm:"default:0" json:"sort_order"`
+}
+
+func (Album) TableName() string {
+	return "album"
+}
+
+func (a *Album) IsSmart() bool {
+	return a.Type == AlbumTypeSmart
+}
+
+/* AlbumItem 手动相册中的文件 */
+type AlbumItem struct {
+	ID        uint            `gorm:"primarykey" json:"id"`
+	CreatedAt common.JSONTime `json:"created_at"`
+
+	AlbumID   string `gorm:"size:32;not null;uniqueIndex:idx_album_item_file" json:"album_id"`
+	FileID    string `gorm:"size:32;not null;uniqueIndex:idx_album_item_file;index" json:"file_id"`
+	SortOrder int    `gorm:"default:0" json:"sort_order"`
+}
+
+func (AlbumItem) TableName() string {
+	return "album_item"
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+
+	"pixelpunk/pkg/common"
+)
+
+const (
+	AutomationTriggerUpload   = "upload"    // 上传完成后
+	AutomationTriggerAITagged = "ai_tagged" // AI打标完成后
+)
+
+const (
+	AutomationLogStatusSuccess = "success" // 全部动作执行成功
+	AutomationLogStatusPartial = "partial" // 部分动作失败
+	AutomationLogStatusFailed  = "failed"  // 全部动作失败
+)
+
+/* AutomationRule 用户自定义自动化规则，满足条件后依次执行动作 */
+type AutomationRule struct {
+	ID        uint            `gorm:"primarykey" json:"id"`
+	CreatedAt common.JSONTime `json:"created_at"`
+	UpdatedAt common.JSONTime `json:"updated_at"`
+
+	UserID         uint            `gorm:"not null;index" json:"user_id"`
+	Name           string          `gorm:"size:100;not null" json:"name"`
+	Description    string          `gorm:"size:255" json:"description"`
+	Enabled        bool            `gorm:"default:false" json:"enabled"`
+	Priority       int             `gorm:"default:0" json:"priority"`            // 数值越小越先执行
+	Triggers       string          `gorm:"size:50;not null" json:"triggers"`     // 逗号分隔：upload,ai_tagged
+	Conditions     json.RawMessage `gorm:"type:json" json:"conditions"`          // 匹配条件
+	Actions        json.RawMessage `gorm:"type:json" json:"actions"`             // 动作列表
+	StopProcessing bool            `gorm:"default:false" json:"stop_processing"` // 命中后不再执行后续规则
+
+	MatchCount      int64            `gorm:"default:0" json:"match_count"`
+	LastTriggeredAt *common.JSONTime `json:"last_triggered_at"`
+}
+
+func (AutomationRule) TableName() string {
+	return "automation_rule"
+}
+
+func (r *AutomationRule) HasTrigger(trigger string) bool {
+	for _, t := range strings.Split(r.Triggers, ",") {
+		if strings.TrimSpace(t) == trigger {
+			return true
+		}
+	}
+	return false
+}
+
+/* AutomationRuleLog 规则执行日志，每次命中记录一条 */
+type AutomationRuleLog struct {
+	ID        uint            `gorm:"primarykey" json:"id"`
+	CreatedAt common.JSONTime `gorm:"index" json:"created_at"`
+
+	RuleID     uint            `gorm:"not null;index" json:"rule_id"`
+	UserID     uint            `gorm:"not null;index" json:"user_id"`
+	FileID     string          `gorm:"size:32;index" json:"file_id"`
+	Trigger    string          `gorm:"size:20" json:"trigger"`
+	Status     string          `gorm:"size:20" json:"status"`
+	Results    json.RawMessage `gorm:"type:json" json:"results"` // 每个动作的执行结果
+	Error      string          `gorm:"type:text" json:"error"`
+	DurationMs int64           `json:"duration_ms"`
+}
+
+func (AutomationRuleLog) TableName() string {
+	return "automation_rule_log"
+}
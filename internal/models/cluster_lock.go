@@ -0,0 +1,14 @@
+package models
+
+import (
+	"time"
+)
+
+/* ClusterLock 未启用 Redis 时用于多进程选主的数据库锁 */
+type ClusterLock struct {
+	Name      string    `gorm:"primarykey;size:64" json:"name"`
+	Holder    string    `gorm:"size:64" json:"holder"` // 持有锁的进程ID
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (ClusterLock) TableName() string { return "cluster_lock" }
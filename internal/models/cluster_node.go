@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+/* ClusterNode 运行中的服务进程，由各进程定时写入心跳 */
+type ClusterNode struct {
+	ID          string    `gorm:"primarykey;size:64" json:"id"` // 主机名-进程号-随机串
+	Roles       string    `gorm:"size:64" json:"roles"`         // 逗号分隔的角色
+	Hostname    string    `gorm:"size:255" json:"hostname"`
+	Version     string    `gorm:"size:32" json:"version"`
+	StartedAt   time.Time `json:"started_at"`
+	HeartbeatAt time.Time `gorm:"index" json:"heartbeat_at"`
+	Details     string    `gorm:"type:text" json:"details"` // 最近一次心跳时的组件状态(JSON)
+}
+
+func (ClusterNode) TableName() string { return "cluster_node" }
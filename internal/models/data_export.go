@@ -0,0 +1,63 @@
+package models
+
+import (
+	"pixelpunk/pkg/common"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+/* DataExport 用户数据导出任务，生成包含全部原始文件和数据清单的压缩包，到期后删除 */
+type DataExport struct {
+	ID        string          `gorm:"primarykey;size:32" json:"id"`
+	CreatedAt common.JSONTime `json:"created_at"`
+	UpdatedAt common.JSONTime `json:"updated_at"`
+
+	UserID      uint   `gorm:"not null;index" json:"user_id"`
+	RequestedBy uint   `gorm:"not null" json:"requested_by"`         // 发起导出的用户，管理员代为导出时为管理员ID
+	Status      string `gorm:"size:20;not null;index" json:"status"` // pending/running/completed/failed/expired
+
+	FilePath     string `gorm:"size:500" json:"-"`              // 压缩包在服务器上的路径
+	ArchiveSize  int64  `gorm:"default:0" json:"archive_size"`  // 压缩包大小（字节）
+	FileCount    int    `gorm:"default:0" json:"file_count"`    // 打包的原始文件数
+	MissingCount int    `gorm:"default:0" json:"missing_count"` // 存储中读取失败、只记录在清单中的文件数
+	ErrorMsg     string `gorm:"type:text" json:"error_msg"`
+	NodeURL      string `gorm:"size:255" json:"-"` // 生成压缩包的进程地址，压缩包不在当前进程时转发下载
+
+	Holder         string     `gorm:"size:64;index" json:"-"` // 正在生成压缩包的进程
+	LeaseExpiresAt *time.Time `json:"-"`                      // 处理进程的租约到期时间，过期后其他进程可以接手
+
+	StartedAt   *common.JSONTime `json:"started_at"`
+	CompletedAt *common.JSONTime `json:"completed_at"`
+	ExpiresAt   *common.JSONTime `gorm:"index" json:"expires_at"` // 完成后可下载的截止时间
+}
+
+/* DataExport 状态常量 */
+const (
+	DataExportStatusPending   = "pending"
+	DataExportStatusRunning   = "running"
+	DataExportStatusCompleted = "completed"
+	DataExportStatusFailed    = "failed"
+	DataExportStatusExpired   = "expired" // 压缩包已过期删除
+)
+
+func (DataExport) TableName() string {
+	return "data_export"
+}
+
+func (e *DataExport) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == "" {
+		e.ID = strings.ReplaceAll(uuid.New().String(), "-", "")
+	}
+	if e.Status == "" {
+		e.Status = DataExportStatusPending
+	}
+	return nil
+}
+
+/* IsActive 检查任务是否还在排队或运行 */
+func (e *DataExport) IsActive() bool {
+	return e.Status == DataExportStatusPending || e.Status == DataExportStatusRunning
+}
@@ -81,11 +81,29 @@ type File struct {
 
 	SortOrder int `gorm:"default:0" json:"sort_order"`
 
+	TakenAt  *time.Time `gorm:"index" json:"taken_at,omitempty"`   // 拍摄时间，没有 EXIF 拍摄时间时为上传时间
+	TakenDay int        `gorm:"not null;default:0;index" json:"-"` // 拍摄日期 YYYYMMDD，用于时间线按年/月/日聚合
+
+	WatermarkConfig string `gorm:"type:longtext" json:"-"`               // 上传时指定的水印，访问时叠加，原图不受影响
+	ExifPolicy      string `gorm:"size:20" json:"exif_policy,omitempty"` // 上传时应用的元数据策略，为空表示保留全部
+
 	User     *User         `gorm:"foreignKey:UserID;references:ID" json:"user"`
 	AIInfo   *FileAIInfo   `gorm:"foreignKey:FileID;references:ID" json:"ai_info"`
 	Category *FileCategory `gorm:"foreignKey:CategoryID;references:ID" json:"category"`
 }
 
+/* TakenDayOf 返回 YYYYMMDD 形式的日期 */
+func TakenDayOf(t time.Time) int {
+	return t.Year()*10000 + int(t.Month())*100 + t.Day()
+}
+
+/* TakenAtFromEXIF 将 EXIF 拍摄时间转换为本地时区下相同的年月日时分秒
+ * EXIF 时间没有时区，提取时按 UTC 解析 */
+func TakenAtFromEXIF(t time.Time) time.Time {
+	u := t.UTC()
+	return time.Date(u.Year(), u.Month(), u.Day(), u.Hour(), u.Minute(), u.Second(), 0, time.Local)
+}
+
 const (
 	FileTypeImage    = "image"
 	FileTypeVideo    = "video"
@@ -164,6 +182,14 @@ func (f *File) BeforeCreate(tx *gorm.DB) error {
 		f.FileType = f.DetectFileType()
 	}
 
+	if f.TakenAt == nil {
+		now := time.Now()
+		f.TakenAt = &now
+	}
+	if f.TakenDay == 0 {
+		f.TakenDay = TakenDayOf(*f.TakenAt)
+	}
+
 	if f.SortOrder == 0 {
 		var maxOrder int
 		query := tx.Model(&File{}).Where("user_id = ?", f.UserID)
@@ -217,7 +243,7 @@ func DetectFileTypeFromFilename(filename string) string {
 		return FileTypeImage
 	case "mp4", "avi", "mov", "wmv", "flv", "webm", "mkv", "m4v", "3gp", "ogv":
 		return FileTypeVideo
-	case "pdf", "doc", "docx", "xls", "xlsx", "ppt", "pptx", "txt", "rtf", "odt", "ods", "odp":
+	case "pdf", "doc", "docx", "xls", "xlsx", "ppt", "pptx", "txt", "rtf", "odt", "ods", "odp", "md", "csv":
 		return FileTypeDocument
 	case "zip", "rar", "7z", "tar", "gz", "bz2", "xz", "cab", "iso":
 		return FileTypeArchive
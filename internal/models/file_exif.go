@@ -8,6 +8,28 @@ import (
 	"gorm.io/gorm"
 )
 
+/* 元数据策略：上传时按策略清理文件中的元数据，非所有者查看 EXIF 时同样按策略隐藏 */
+const (
+	ExifPolicyKeep        = "keep"         // 保留全部
+	ExifPolicyStripGPS    = "strip_gps"    // 清除 GPS
+	ExifPolicyStripSerial = "strip_serial" // 清除序列号
+	ExifPolicyStripAll    = "strip_all"    // 清除全部
+)
+
+/* IsValidExifPolicy 是否为有效的元数据策略 */
+func IsValidExifPolicy(policy string) bool {
+	switch policy {
+	case ExifPolicyKeep, ExifPolicyStripGPS, ExifPolicyStripSerial, ExifPolicyStripAll:
+		return true
+	}
+	return false
+}
+
+/* ExifPolicyShowsGPS 该策略下其他人是否可以看到拍摄位置，未记录策略的历史文件不可见 */
+func ExifPolicyShowsGPS(policy string) bool {
+	return policy == ExifPolicyKeep || policy == ExifPolicyStripSerial
+}
+
 /* FileEXIF 文件 EXIF 元数据模型 */
 type FileEXIF struct {
 	ID        string    `gorm:"primarykey;size:32" json:"id"`
@@ -33,6 +55,11 @@ type FileEXIF struct {
 	GPSAltitude     *float64 `json:"gps_altitude,omitempty"`                    // 海拔 (米)
 	GPSLatitudeRef  string   `gorm:"size:1" json:"gps_latitude_ref,omitempty"`  // 纬度参考 (N/S)
 	GPSLongitudeRef string   `gorm:"size:1" json:"gps_longitude_ref,omitempty"` // 经度参考 (E/W)
+	GPSChecked      bool     `gorm:"not null;default:false" json:"-"`           // 已按修正后的解析逻辑提取坐标，早期版本的记录由定时任务从原图补充
+
+	GeoCountryCode string `gorm:"size:2;index" json:"geo_country_code,omitempty"` // 离线反向地理编码得到的国家代码
+	GeoCountry     string `gorm:"size:100" json:"geo_country,omitempty"`          // 国家名称
+	GeoCity        string `gorm:"size:100;index" json:"geo_city,omitempty"`       // 最近的城市
 
 	DateTime          *time.Time `json:"date_time,omitempty"`                       // 文件修改时间
 	DateTimeOriginal  *time.Time `gorm:"index" json:"date_time_original,omitempty"` // 原始拍摄时间
@@ -77,6 +104,31 @@ func (fe *FileEXIF) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+/* Redacted 返回按元数据策略隐藏敏感字段后的副本，策略为清除全部时返回 nil
+ * 未记录策略的历史文件同时隐藏 GPS 和序列号 */
+func (fe *FileEXIF) Redacted(policy string) *FileEXIF {
+	if policy == ExifPolicyStripAll {
+		return nil
+	}
+	redacted := *fe
+	redacted.File = nil
+	if !ExifPolicyShowsGPS(policy) {
+		redacted.GPSLatitude = nil
+		redacted.GPSLongitude = nil
+		redacted.GPSAltitude = nil
+		redacted.GPSLatitudeRef = ""
+		redacted.GPSLongitudeRef = ""
+		redacted.GeoCountryCode = ""
+		redacted.GeoCountry = ""
+		redacted.GeoCity = ""
+	}
+	if policy != ExifPolicyKeep && policy != ExifPolicyStripGPS {
+		redacted.SerialNumber = ""
+		redacted.LensSerialNumber = ""
+	}
+	return &redacted
+}
+
 func (fe *FileEXIF) HasGPS() bool {
 	return fe.GPSLatitude != nil && fe.GPSLongitude != nil
 }
@@ -16,6 +16,7 @@ type GlobalTag struct {
 	Name        string          `gorm:"size:50;not null;uniqueIndex:idx_global_tag_name" json:"name"`
 	Slug        string          `gorm:"size:50;not null;uniqueIndex:idx_global_tag_slug" json:"slug"`
 	Description string          `gorm:"type:text" json:"description"`
+	ParentID    *uint           `gorm:"index:idx_global_tag_parent" json:"parent_id"` // 父标签，搜索父标签时包含全部子孙标签
 	IsSystem    bool            `gorm:"default:false;index:idx_global_tag_system" json:"is_system"`
 	CreatorID   uint            `gorm:"not null;index:idx_global_tag_creator" json:"creator_id"`
 	UsageCount  int             `gorm:"default:0;index:idx_global_tag_usage" json:"usage_count"` // 全局使用次数统计
@@ -56,6 +57,18 @@ func (t *GlobalTag) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+/* GlobalTagAlias 标签同义词，AI与手动打标时同义词会被归并到规范标签 */
+type GlobalTagAlias struct {
+	ID        uint            `gorm:"primarykey" json:"id"`
+	TagID     uint            `gorm:"not null;index:idx_global_tag_alias_tag" json:"tag_id"`
+	Alias     string          `gorm:"size:50;not null;uniqueIndex:idx_global_tag_alias" json:"alias"` // 小写存储
+	CreatedAt common.JSONTime `json:"created_at"`
+}
+
+func (GlobalTagAlias) TableName() string {
+	return "global_tag_alias"
+}
+
 /* UserTagReference 用户标签引用表 - 用户与全局标签的多对多关系 */
 type UserTagReference struct {
 	ID        uint            `gorm:"primarykey" json:"id"`
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"pixelpunk/pkg/common"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+/* ImportJob 后台导入任务，每个待导入的条目对应一条 ImportJobItem */
+type ImportJob struct {
+	ID        string          `gorm:"primarykey;size:32" json:"id"`
+	CreatedAt common.JSONTime `json:"created_at"`
+	UpdatedAt common.JSONTime `json:"updated_at"`
+
+	UserID   uint   `gorm:"not null;index" json:"user_id"`
+	APIKeyID string `gorm:"size:32;index" json:"api_key_id"`      // 通过API密钥创建时记录
+	Source   string `gorm:"size:20;not null" json:"source"`       // 导入来源：url/directory/archive/lsky/chevereto/manifest
+	Status   string `gorm:"size:20;not null;index" json:"status"` // pending/running/completed/canceled/failed
+	FolderID string `gorm:"size:32" json:"folder_id"`             // 目标文件夹
+	Options  string `gorm:"type:text" json:"-"`                   // 上传选项JSON
+
+	TotalCount   int `gorm:"default:0" json:"total_count"`
+	SuccessCount int `gorm:"default:0" json:"success_count"`
+	FailedCount  int `gorm:"default:0" json:"failed_count"`
+	SkippedCount int `gorm:"default:0" json:"skipped_count"`
+
+	ErrorMsg string `gorm:"type:text" json:"error_msg"` // 任务整体失败的原因，如压缩包无法解压
+
+	Holder         string     `gorm:"size:64;index" json:"-"` // 正在处理任务的进程
+	LeaseExpiresAt *time.Time `json:"-"`                      // 处理进程的租约到期时间，过期后其他进程可以接手
+
+	StartedAt   *common.JSONTime `json:"started_at"`
+	CompletedAt *common.JSONTime `json:"completed_at"`
+}
+
+/* ImportJobItem 导入任务中的单个条目及其处理结果 */
+type ImportJobItem struct {
+	ID        uint            `gorm:"primarykey" json:"id"`
+	CreatedAt common.JSONTime `json:"created_at"`
+	UpdatedAt common.JSONTime `json:"updated_at"`
+
+	JobID    string `gorm:"size:32;not null;index:idx_import_job_item_seq,priority:1" json:"job_id"`
+	Seq      int    `gorm:"not null;index:idx_import_job_item_seq,priority:2" json:"seq"` // 条目在任务中的顺序
+	Source   string `gorm:"type:text;not null" json:"source"`                             // 远程地址，或目录、压缩包、导出数据中的文件路径
+	Status   string `gorm:"size:20;not null" json:"status"`                               // pending/success/failed/skipped
+	FileID   string `gorm:"size:32" json:"file_id"`                                       // 导入成功后的文件ID
+	FileName string `gorm:"size:255" json:"file_name"`
+	Size     int64  `gorm:"default:0" json:"size"`
+	ErrorMsg string `gorm:"type:text" json:"error_msg"`
+	Payload  string `gorm:"type:text" json:"-"` // 来源处理条目所需的附加数据
+}
+
+/* ImportJob 状态常量 */
+const (
+	ImportJobStatusPending   = "pending"
+	ImportJobStatusRunning   = "running"
+	ImportJobStatusCompleted = "completed"
+	ImportJobStatusCanceled  = "canceled"
+	ImportJobStatusFailed    = "failed"
+)
+
+/* ImportJobItem 状态常量 */
+const (
+	ImportItemStatusPending = "pending"
+	ImportItemStatusSuccess = "success"
+	ImportItemStatusFailed  = "failed"
+	ImportItemStatusSkipped = "skipped" // 格式不支持，或同一文件夹中已有相同内容的文件
+)
+
+/* ImportJob 来源常量 */
+const (
+	ImportSourceURL       = "url"
+	ImportSourceDirectory = "directory" // 服务器上的目录
+	ImportSourceArchive   = "archive"   // 上传的压缩包
+	ImportSourceLsky      = "lsky"      // Lsky Pro 数据库导出
+	ImportSourceChevereto = "chevereto" // Chevereto 数据库导出
+	ImportSourceManifest  = "manifest"  // 通用 JSON/CSV 清单
+)
+
+func (ImportJob) TableName() string {
+	return "import_job"
+}
+
+func (ImportJobItem) TableName() string {
+	return "import_job_item"
+}
+
+func (j *ImportJob) BeforeCreate(tx *gorm.DB) error {
+	if j.ID == "" {
+		j.ID = strings.ReplaceAll(uuid.New().String(), "-", "")
+	}
+	if j.Status == "" {
+		j.Status = ImportJobStatusPending
+	}
+	return nil
+}
+
+func (i *ImportJobItem) BeforeCreate(tx *gorm.DB) error {
+	if i.Status == "" {
+		i.Status = ImportItemStatusPending
+	}
+	return nil
+}
+
+/* IsFinished 检查任务是否已结束 */
+func (j *ImportJob) IsFinished() bool {
+	return j.Status == ImportJobStatusCompleted || j.Status == ImportJobStatusCanceled || j.Status == ImportJobStatusFailed
+}
+
+/* ProcessedCount 已处理的条目数 */
+func (j *ImportJob) ProcessedCount() int {
+	return j.SuccessCount + j.FailedCount + j.SkippedCount
+}
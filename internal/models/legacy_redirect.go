@@ -0,0 +1,24 @@
+package models
+
+import "pixelpunk/pkg/common"
+
+/* LegacyRedirect 从其他图床迁移来的文件的旧地址，访问旧地址时重定向到新文件 */
+type LegacyRedirect struct {
+	ID        uint            `gorm:"primarykey" json:"id"`
+	CreatedAt common.JSONTime `json:"created_at"`
+
+	Path   string `gorm:"size:500;not null;uniqueIndex:idx_legacy_redirect_path" json:"path"` // 旧地址的路径部分，不含域名和查询参数
+	FileID string `gorm:"size:32;not null;index" json:"file_id"`
+	Kind   string `gorm:"size:10;not null" json:"kind"` // f:原图 t:缩略图
+	JobID  string `gorm:"size:32;index" json:"job_id"`  // 创建该记录的导入任务
+}
+
+/* LegacyRedirect 目标类型常量 */
+const (
+	LegacyRedirectKindFile  = "f"
+	LegacyRedirectKindThumb = "t"
+)
+
+func (LegacyRedirect) TableName() string {
+	return "legacy_redirect"
+}
@@ -10,11 +10,20 @@ type ShareAccessLog struct {
 	ID      string `gorm:"primarykey;size:32" json:"id"`
 	ShareID string `gorm:"size:32;not null;index" json:"share_id"`
 
-	AccessedAt common.JSONTime `json:"accessed_at"`                 // 访问时间
+	EventType string `gorm:"size:20;default:view;index" json:"event_type"` // 事件类型：view/file_view/download
+	FileID    string `gorm:"size:32;index" json:"file_id"`                 // 关联文件ID(文件级事件)
+
+	AccessedAt common.JSONTime `gorm:"index" json:"accessed_at"`    // 访问时间
 	IPAddress  string          `gorm:"size:50" json:"ip_address"`   // 访问者IP
 	UserAgent  string          `gorm:"type:text" json:"user_agent"` // 用户代理
 	Referer    string          `gorm:"size:255" json:"referer"`     // 来源页面
 
+	CountryCode string `gorm:"size:8" json:"country_code"` // 国家代码(GeoIP)
+	Country     string `gorm:"size:64" json:"country"`     // 国家名称(GeoIP)
+	Browser     string `gorm:"size:32" json:"browser"`     // 浏览器族
+	OS          string `gorm:"size:32" json:"os"`          // 操作系统族
+	DeviceType  string `gorm:"size:16" json:"device_type"` // 设备类型
+
 	VisitorName  string `gorm:"size:100" json:"visitor_name"`  // 访客姓名(可选)
 	VisitorEmail string `gorm:"size:100" json:"visitor_email"` // 访客邮箱(可选)
 
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"pixelpunk/pkg/common"
+	"time"
+)
+
+/* SignedLink 签名访问链接（用于吊销、IP绑定与下载次数控制） */
+type SignedLink struct {
+	ID        string          `gorm:"primarykey;size:32" json:"id"`
+	CreatedAt common.JSONTime `json:"created_at"`
+
+	FileID   string `gorm:"size:32;not null;index" json:"file_id"`
+	UserID   uint   `gorm:"not null;index" json:"user_id"`
+	APIKeyID string `gorm:"size:32;index" json:"api_key_id"` // 通过API密钥签发时记录
+	Kind     string `gorm:"size:10;not null" json:"kind"`    // f:原图 t:缩略图 s:短链
+
+	ExpiresAt     common.JSONTime  `gorm:"index" json:"expires_at"`
+	BindIP        string           `gorm:"size:64" json:"bind_ip"`          // 绑定IP或CIDR
+	MaxDownloads  int              `gorm:"default:0" json:"max_downloads"`  // 0表示不限制
+	DownloadCount int              `gorm:"default:0" json:"download_count"` // 已访问次数
+	Width         int              `gorm:"default:0" json:"width"`          // 变换宽度
+	Height        int              `gorm:"default:0" json:"height"`         // 变换高度
+	Quality       int              `gorm:"default:0" json:"quality"`        // 变换质量
+	Format        string           `gorm:"size:10" json:"format"`           // 变换输出格式
+	Note          string           `gorm:"size:255" json:"note"`            // 备注
+	RevokedAt     *common.JSONTime `json:"revoked_at"`                      // 吊销时间
+	LastAccessAt  *common.JSONTime `json:"last_access_at"`                  // 最后访问时间
+}
+
+/* SignedLink 资源类型常量 */
+const (
+	SignedLinkKindFile  = "f"
+	SignedLinkKindThumb = "t"
+	SignedLinkKindShort = "s"
+)
+
+func (SignedLink) TableName() string {
+	return "signed_link"
+}
+
+func (l *SignedLink) IsRevoked() bool {
+	return l.RevokedAt != nil
+}
+
+func (l *SignedLink) IsExpired() bool {
+	return time.Now().After(time.Time(l.ExpiresAt))
+}
+
+func (l *SignedLink) IsExhausted() bool {
+	return l.MaxDownloads > 0 && l.DownloadCount >= l.MaxDownloads
+}
@@ -7,6 +7,11 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	UploadProtocolChunked = "chunked"
+	UploadProtocolTus     = "tus"
+)
+
 type UploadSession struct {
 	ID        uint            `gorm:"primarykey" json:"id"`
 	CreatedAt common.JSONTime `gorm:"index:idx_upload_session_created_at" json:"created_at"`
@@ -31,9 +36,16 @@ type UploadSession struct {
 	Optimize    bool   `gorm:"default:true" json:"optimize"`
 
 	WatermarkConfig string `gorm:"type:text" json:"watermark_config"`
+	ExifPolicy      string `gorm:"size:20" json:"exif_policy"`
 
 	FileID string `gorm:"size:32" json:"file_id"`
 
+	// tus 协议上传：每个 PATCH 请求的数据保存为一个分片，TotalChunks 随上传增长
+	Protocol       string `gorm:"size:10;default:chunked" json:"protocol"` // chunked/tus
+	UploadOffset   int64  `gorm:"default:0" json:"upload_offset"`          // tus 已接收的字节数
+	UploadMetadata string `gorm:"type:text" json:"upload_metadata"`        // tus Upload-Metadata 原文，HEAD 时原样返回
+	APIKeyID       string `gorm:"size:32" json:"api_key_id"`               // 通过API密钥创建时记录，完成后关联文件
+
 	ExpiresAt time.Time `gorm:"index:idx_upload_session_expires_at" json:"expires_at"` // 24小时后过期
 }
 
@@ -46,6 +58,10 @@ func (us *UploadSession) BeforeCreate(tx *gorm.DB) error {
 		us.Status = "pending"
 	}
 
+	if us.Protocol == "" {
+		us.Protocol = UploadProtocolChunked
+	}
+
 	if us.AccessLevel == "" {
 		us.AccessLevel = "private"
 	}
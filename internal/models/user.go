@@ -21,10 +21,7 @@ type User struct {
 	Status   int    `gorm:"default:1" json:"status"` // 1:正常 2:禁用 3:删除
 	Role     int    `gorm:"default:3" json:"role"`   // 1:超级管理员 2:管理员 3:普通用户
 
-	PathAlias string  `gorm:"size:32;uniqueIndex:idx_user_path_alias,sort:asc" json:"path_alias"`
-	GithubID  *int64  `gorm:"uniqueIndex:idx_user_github_id,sort:asc" json:"github_id"`
-	GoogleID  *string `gorm:"size:50;uniqueIndex:idx_user_google_id,sort:asc" json:"google_id"`
-	LinuxdoID *int64  `gorm:"uniqueIndex:idx_user_linuxdo_id,sort:asc" json:"linuxdo_id"`
+	PathAlias string `gorm:"size:32;uniqueIndex:idx_user_path_alias,sort:asc" json:"path_alias"` // 第三方登录身份见 UserIdentity
 
 	LastActivityAt *common.JSONTime `gorm:"column:last_activity_at" json:"last_activity_at"`
 	LastActivityIP string           `gorm:"size:45;column:last_activity_ip" json:"last_activity_ip"` // 支持IPv6
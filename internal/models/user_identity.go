@@ -0,0 +1,34 @@
+package models
+
+import (
+	"pixelpunk/pkg/common"
+)
+
+// 第三方身份提供方
+const (
+	IdentityProviderGithub  = "github"
+	IdentityProviderGoogle  = "google"
+	IdentityProviderLinuxdo = "linuxdo"
+	IdentityProviderOIDC    = "oidc"
+)
+
+/* UserIdentity 用户绑定的第三方登录身份，一个用户可绑定多个提供方 */
+type UserIdentity struct {
+	ID        uint            `gorm:"primarykey" json:"id"`
+	CreatedAt common.JSONTime `json:"created_at"`
+	UpdatedAt common.JSONTime `json:"updated_at"`
+
+	UserID   uint   `gorm:"not null;index" json:"user_id"`
+	Provider string `gorm:"size:30;not null;uniqueIndex:idx_user_identity_subject,priority:1" json:"provider"`
+	Issuer   string `gorm:"size:255;not null;default:'';uniqueIndex:idx_user_identity_subject,priority:2" json:"issuer"` // OIDC签发方，内置提供方为空
+	Subject  string `gorm:"size:255;not null;uniqueIndex:idx_user_identity_subject,priority:3" json:"subject"`           // 提供方内的用户唯一标识
+
+	Email       string           `gorm:"size:100" json:"email"`
+	DisplayName string           `gorm:"size:100" json:"display_name"`
+	AvatarURL   string           `gorm:"size:500" json:"avatar_url"`
+	LastLoginAt *common.JSONTime `json:"last_login_at"`
+}
+
+func (UserIdentity) TableName() string {
+	return "user_identity"
+}
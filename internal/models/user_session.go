@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"pixelpunk/pkg/common"
+)
+
+/* UserSession 用户登录会话，每次登录生成一条，刷新令牌轮换时复用 */
+type UserSession struct {
+	ID        string          `gorm:"primarykey;size:32" json:"id"`
+	CreatedAt common.JSONTime `json:"created_at"`
+	UpdatedAt common.JSONTime `json:"updated_at"`
+
+	UserID           uint   `gorm:"not null;index" json:"user_id"`
+	RefreshTokenHash string `gorm:"size:64;not null;uniqueIndex:idx_user_session_refresh" json:"-"` // 当前刷新令牌的SHA256
+	PrevTokenHash    string `gorm:"size:64;index" json:"-"`                                         // 上一个刷新令牌，用于识别重放
+	LoginMethod      string `gorm:"size:20" json:"login_method"`                                    // password/oauth/2fa
+
+	UserAgent  string `gorm:"size:500" json:"user_agent"`
+	Browser    string `gorm:"size:50" json:"browser"`
+	OS         string `gorm:"size:50" json:"os"`
+	DeviceType string `gorm:"size:20" json:"device_type"`
+	IP         string `gorm:"size:64" json:"ip"`
+
+	LastSeenAt    common.JSONTime  `json:"last_seen_at"`
+	ExpiresAt     common.JSONTime  `gorm:"index" json:"expires_at"` // 刷新令牌过期时间
+	RevokedAt     *common.JSONTime `gorm:"index" json:"revoked_at"`
+	RevokedReason string           `gorm:"size:50" json:"revoked_reason"`
+}
+
+func (UserSession) TableName() string {
+	return "user_session"
+}
+
+func (s *UserSession) IsRevoked() bool {
+	return s.RevokedAt != nil
+}
+
+func (s *UserSession) IsExpired() bool {
+	return time.Now().After(time.Time(s.ExpiresAt))
+}
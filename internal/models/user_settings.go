@@ -14,6 +14,7 @@ type UserSettings struct {
 	BandwidthLimit     int64           `gorm:"not null;default:107374182400" json:"bandwidth_limit"` // 默认1GB
 	DefaultAccessLevel string          `gorm:"size:20;not null;default:private" json:"default_access_level"`
 	OptimizeImages     bool            `gorm:"not null;default:false" json:"optimize_files"`
+	ExifPolicy         string          `gorm:"size:20" json:"exif_policy"` // 上传元数据策略，为空时使用系统设置
 	CreatedAt          common.JSONTime `json:"created_at"`
 	UpdatedAt          common.JSONTime `json:"updated_at"`
 }
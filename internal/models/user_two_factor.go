@@ -0,0 +1,35 @@
+package models
+
+import (
+	"pixelpunk/pkg/common"
+)
+
+/* UserTwoFactor 用户两步验证(TOTP)配置 */
+type UserTwoFactor struct {
+	ID        uint            `gorm:"primarykey" json:"id"`
+	CreatedAt common.JSONTime `json:"created_at"`
+	UpdatedAt common.JSONTime `json:"updated_at"`
+
+	UserID    uint             `gorm:"not null;uniqueIndex:idx_user_two_factor_user_id" json:"user_id"`
+	Secret    string           `gorm:"size:64;not null" json:"-"`          // Base32密钥
+	Enabled   bool             `gorm:"default:false;index" json:"enabled"` // 是否已启用（完成首次验证）
+	EnabledAt *common.JSONTime `json:"enabled_at"`
+	LastStep  int64            `gorm:"default:0" json:"-"` // 最近一次通过校验的时间步，用于防重放
+}
+
+func (UserTwoFactor) TableName() string {
+	return "user_two_factor"
+}
+
+/* UserRecoveryCode 两步验证一次性恢复码（仅保存哈希） */
+type UserRecoveryCode struct {
+	ID        uint             `gorm:"primarykey" json:"id"`
+	CreatedAt common.JSONTime  `json:"created_at"`
+	UserID    uint             `gorm:"not null;index" json:"user_id"`
+	CodeHash  string           `gorm:"size:64;not null;index" json:"-"`
+	UsedAt    *common.JSONTime `json:"used_at"`
+}
+
+func (UserRecoveryCode) TableName() string {
+	return "user_recovery_code"
+}
@@ -0,0 +1,27 @@
+package models
+
+import "pixelpunk/pkg/common"
+
+const (
+	WatermarkPolicyScopeFolder      = "folder"       // 文件夹（含子文件夹）
+	WatermarkPolicyScopeShare       = "share"        // 通过分享访问
+	WatermarkPolicyScopeAccessLevel = "access_level" // 按文件访问级别
+)
+
+/* WatermarkPolicy 访问时水印策略，非所有者访问原图时按策略叠加水印方案 */
+type WatermarkPolicy struct {
+	ID        uint            `gorm:"primarykey" json:"id"`
+	CreatedAt common.JSONTime `json:"created_at"`
+	UpdatedAt common.JSONTime `json:"updated_at"`
+
+	UserID            uint   `gorm:"not null;uniqueIndex:idx_watermark_policy_target" json:"user_id"`
+	Scope             string `gorm:"size:20;not null;uniqueIndex:idx_watermark_policy_target" json:"scope"`
+	TargetID          string `gorm:"size:32;not null;uniqueIndex:idx_watermark_policy_target" json:"target_id"` // 文件夹ID、分享ID或访问级别
+	ProfileID         uint   `gorm:"not null;index" json:"profile_id"`
+	ApplyToThumbnails bool   `gorm:"default:false" json:"apply_to_thumbnails"`
+	Enabled           bool   `gorm:"not null" json:"enabled"`
+}
+
+func (WatermarkPolicy) TableName() string {
+	return "watermark_policy"
+}
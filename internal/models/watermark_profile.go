@@ -0,0 +1,23 @@
+package models
+
+import (
+	"encoding/json"
+
+	"pixelpunk/pkg/common"
+)
+
+/* WatermarkProfile 用户保存的水印方案，默认方案在上传未指定水印时自动应用 */
+type WatermarkProfile struct {
+	ID        uint            `gorm:"primarykey" json:"id"`
+	CreatedAt common.JSONTime `json:"created_at"`
+	UpdatedAt common.JSONTime `json:"updated_at"`
+
+	UserID    uint            `gorm:"not null;index" json:"user_id"`
+	Name      string          `gorm:"size:100;not null" json:"name"`
+	Config    json.RawMessage `gorm:"type:json" json:"config"` // 水印配置，格式与上传参数 watermark 一致
+	IsDefault bool            `gorm:"default:false" json:"is_default"`
+}
+
+func (WatermarkProfile) TableName() string {
+	return "watermark_profile"
+}
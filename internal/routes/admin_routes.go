@@ -3,6 +3,7 @@ package routes
 import (
 	adminController "pixelpunk/internal/controllers/admin"
 	aiController "pixelpunk/internal/controllers/ai"
+	dataExportController "pixelpunk/internal/controllers/dataexport"
 	fileController "pixelpunk/internal/controllers/file"
 	statsController "pixelpunk/internal/controllers/stats"
 	userController "pixelpunk/internal/controllers/user"
@@ -45,10 +46,14 @@ func RegisterAdminRoutes(r *gin.RouterGroup) {
 		userRoutes.POST("/update", middleware.RequireSuperAdmin(), userController.AdminUpdateUser)
 		userRoutes.POST("/storage", middleware.RequireSuperAdmin(), userController.AdminUpdateUserStorage)
 		userRoutes.POST("/reset-password/:id", middleware.RequireSuperAdmin(), userController.AdminResetUserPassword)
+		userRoutes.POST("/reset-2fa/:id", middleware.RequireSuperAdmin(), userController.AdminResetTwoFactor)
+		userRoutes.POST("/logout-everywhere/:id", middleware.RequireSuperAdmin(), userController.AdminLogoutUserEverywhere)
 		userRoutes.POST("/send-email", middleware.RequireSuperAdmin(), userController.AdminSendUserEmail)
 		userRoutes.POST("/toggle-status", middleware.RequireSuperAdmin(), userController.AdminToggleUserStatus)
 		userRoutes.POST("/delete/:id", middleware.RequireSuperAdmin(), userController.AdminDeleteUser)
 		userRoutes.POST("/batch", middleware.RequireSuperAdmin(), userController.AdminBatchOperateUsers)
+		userRoutes.POST("/data-export/:id", dataExportController.AdminCreateDataExport)
+		userRoutes.GET("/data-exports", dataExportController.AdminListDataExports)
 	}
 
 	imageRoutes := r.Group("/files")
@@ -105,4 +110,23 @@ func RegisterAdminRoutes(r *gin.RouterGroup) {
 		fileRoutes.POST("/upload", fileController.UploadAdminFile)
 	}
 
+	importRoutes := r.Group("/import-jobs")
+	importRoutes.Use(middleware.RequireAdmin())
+	{
+		importRoutes.POST("/directory", fileController.AdminCreateDirectoryImport)
+		importRoutes.POST("/archive", fileController.AdminCreateArchiveImport)
+		importRoutes.POST("/host", fileController.AdminCreateHostImport)
+		importRoutes.GET("", fileController.AdminListLocalImports)
+		importRoutes.GET("/:job_id", fileController.AdminGetLocalImport)
+		importRoutes.GET("/:job_id/items", fileController.AdminListLocalImportItems)
+		importRoutes.POST("/:job_id/cancel", fileController.AdminCancelLocalImport)
+	}
+
+	migrationRoutes := r.Group("/migrations")
+	migrationRoutes.Use(middleware.RequireSuperAdmin())
+	{
+		migrationRoutes.GET("", adminController.GetMigrationStatus)
+		migrationRoutes.GET("/preview", adminController.PreviewMigrations)
+	}
+
 }
@@ -22,6 +22,14 @@ func RegisterAdminShareRoutes(r *gin.RouterGroup) {
 
 	r.GET("/stats", shareController.AdminGetShareStats)
 
+	r.GET("/analytics", shareController.AdminGetShareAnalytics)
+
+	r.GET("/analytics/export", shareController.AdminExportShareAnalytics)
+
+	r.GET("/:id/analytics", shareController.AdminGetShareAnalytics)
+
+	r.GET("/:id/analytics/export", shareController.AdminExportShareAnalytics)
+
 	visitorGroup := r.Group("/visitors")
 	{
 		visitorGroup.GET("", shareController.AdminGetAllVisitors)
@@ -0,0 +1,24 @@
+package routes
+
+import (
+	albumController "pixelpunk/internal/controllers/album"
+	"pixelpunk/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterAlbumRoutes(r *gin.RouterGroup) {
+	r.Use(middleware.RequireAuth())
+	{
+		r.GET("", albumController.ListAlbums)
+		r.POST("", albumController.CreateAlbum)
+
+		r.GET("/:album_id", albumController.GetAlbum)
+		r.PUT("/:album_id", albumController.UpdateAlbum)
+		r.DELETE("/:album_id", albumController.DeleteAlbum)
+
+		r.GET("/:album_id/files", albumController.ListAlbumFiles)
+		r.POST("/:album_id/files", albumController.AddAlbumFiles)
+		r.POST("/:album_id/files/remove", albumController.RemoveAlbumFiles)
+	}
+}
@@ -11,6 +11,8 @@ import (
 func RegisterAuthRoutes(r *gin.RouterGroup) {
 	r.POST("/register", userController.Register)
 	r.POST("/login", userController.Login)
+	r.POST("/login/2fa", userController.LoginTwoFactor)
+	r.POST("/refresh", userController.RefreshToken)
 
 	r.POST("/send-registration-code", userController.SendRegistrationCode)
 	r.POST("/send-reset-password-code", userController.SendResetPasswordCode)
@@ -28,5 +30,7 @@ func RegisterAuthRoutes(r *gin.RouterGroup) {
 		oauthRoutes.POST("/github/login", oauthController.GithubLogin)
 		oauthRoutes.POST("/google/login", oauthController.GoogleLogin)
 		oauthRoutes.POST("/linuxdo/login", oauthController.LinuxdoLogin)
+		oauthRoutes.GET("/oidc/authorize", oauthController.OIDCAuthorize)
+		oauthRoutes.POST("/oidc/login", oauthController.OIDCLogin)
 	}
 }
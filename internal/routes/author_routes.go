@@ -2,6 +2,7 @@ package routes
 
 import (
 	authorController "pixelpunk/internal/controllers/author"
+	fileController "pixelpunk/internal/controllers/file"
 
 	"github.com/gin-gonic/gin"
 )
@@ -11,5 +12,11 @@ func RegisterAuthorRoutes(r *gin.RouterGroup) {
 		r.GET("/:author_id", authorController.GetAuthorHomepage)
 
 		r.GET("/:author_id/folders/:folder_id", authorController.GetAuthorFolder)
+
+		r.GET("/:author_id/geo", fileController.GetAuthorGeoFiles)
+		r.GET("/:author_id/geo/places", fileController.GetAuthorGeoPlaces)
+
+		r.GET("/:author_id/timeline", fileController.GetAuthorTimeline)
+		r.GET("/:author_id/timeline/files", fileController.GetAuthorTimelineFiles)
 	}
 }
@@ -16,5 +16,14 @@ func RegisterAutomationRoutes(r *gin.RouterGroup) {
 		userAutomation.GET("/tagging/tasks", automation.GetUserTaggingTasks)
 
 		userAutomation.GET("/vector/tasks", automation.GetUserVectorTasks)
+
+		userAutomation.GET("/rules", automation.ListRules)
+		userAutomation.POST("/rules", automation.CreateRule)
+		userAutomation.POST("/rules/dry-run", automation.DryRunRule)
+		userAutomation.GET("/rules/:id", automation.GetRule)
+		userAutomation.PUT("/rules/:id", automation.UpdateRule)
+		userAutomation.PUT("/rules/:id/enabled", automation.ToggleRule)
+		userAutomation.DELETE("/rules/:id", automation.DeleteRule)
+		userAutomation.GET("/rules/:id/logs", automation.GetRuleLogs)
 	}
 }
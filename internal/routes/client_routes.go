@@ -1,6 +1,7 @@
 package routes
 
 import (
+	fileController "pixelpunk/internal/controllers/file"
 	"pixelpunk/internal/middleware"
 	"pixelpunk/internal/static"
 	"strings"
@@ -17,6 +18,9 @@ func RegisterClientRoutes(r *gin.Engine) {
 			c.Next()
 			return
 		}
+		if fileController.RedirectLegacyURL(c) {
+			return
+		}
 		middleware.StaticFileHandler(distFS)(c)
 	})
 }
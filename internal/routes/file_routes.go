@@ -11,6 +11,8 @@ func RegisterFileRoutes(r *gin.RouterGroup) {
 	guestGroup := r.Group("/guest")
 	guestGroup.GET("/list", fileController.GetRecommendedFileList)
 	guestGroup.GET("/random", fileController.GetRandomRecommendedFile)
+	guestGroup.GET("/geo", fileController.GetPublicGeoFiles)
+	guestGroup.GET("/geo/places", fileController.GetPublicGeoPlaces)
 
 	guestGroup.POST("/upload", middleware.UploadConcurrencyLimit(), fileController.GuestUpload)
 
@@ -22,6 +24,11 @@ func RegisterFileRoutes(r *gin.RouterGroup) {
 		middleware.OptionalAuthForFileDownload(),
 		fileController.DownloadFile)
 
+	r.GET("/:file_id/exif",
+		middleware.JWTAuth(),
+		middleware.OptionalAuthForFileDownload(),
+		fileController.GetFileEXIF)
+
 	authGroup := r.Group("")
 	authGroup.Use(middleware.RequireAuth())
 
@@ -33,6 +40,13 @@ func RegisterFileRoutes(r *gin.RouterGroup) {
 
 	authGroup.GET("/list", fileController.GetFileList)
 
+	authGroup.GET("/geo", fileController.GetGeoFiles)
+	authGroup.GET("/geo/places", fileController.GetGeoPlaces)
+
+	authGroup.GET("/timeline", fileController.GetTimeline)
+	authGroup.GET("/timeline/files", fileController.GetTimelineFiles)
+	authGroup.GET("/on-this-day", fileController.GetOnThisDay)
+
 	authGroup.POST("/batch-delete", fileController.BatchDeleteFiles)
 
 	authGroup.POST("/reorder", fileController.ReorderFiles)
@@ -40,6 +54,18 @@ func RegisterFileRoutes(r *gin.RouterGroup) {
 	authGroup.POST("/move", fileController.MoveFiles)
 
 	authGroup.GET("/:file_id/link", fileController.GenerateFileLink)
+
+	authGroup.POST("/import-url", middleware.UploadConcurrencyLimit(), fileController.ImportFromURL)
+	authGroup.POST("/import-jobs", fileController.CreateURLImportJob)
+	authGroup.GET("/import-jobs", fileController.ListImportJobs)
+	authGroup.GET("/import-jobs/:job_id", fileController.GetImportJob)
+	authGroup.GET("/import-jobs/:job_id/items", fileController.ListImportJobItems)
+	authGroup.POST("/import-jobs/:job_id/cancel", fileController.CancelImportJob)
+
+	authGroup.POST("/signed-links", fileController.MintSignedLinks)
+	authGroup.GET("/signed-links", fileController.ListSignedLinks)
+	authGroup.DELETE("/signed-links/:link_id", fileController.RevokeSignedLink)
+
 	authGroup.POST("/:file_id/toggle-access-level", fileController.ToggleAccessLevel)
 
 	authGroup.GET("/:file_id", fileController.GetFileDetail)
@@ -2,10 +2,16 @@ package routes
 
 import (
 	activityController "pixelpunk/internal/controllers/activity"
+	dataExportController "pixelpunk/internal/controllers/dataexport"
 
 	"github.com/gin-gonic/gin"
 )
 
 func RegisterPersonalRoutes(r *gin.RouterGroup) {
 	r.GET("/activities", activityController.GetUserActivities)
+
+	r.POST("/data-exports", dataExportController.CreateDataExport)
+	r.GET("/data-exports", dataExportController.ListDataExports)
+	r.GET("/data-exports/:export_id", dataExportController.GetDataExport)
+	r.GET("/data-exports/:export_id/download", dataExportController.DownloadDataExport)
 }
@@ -66,11 +66,16 @@ func RegisterRoutes(r *gin.Engine) {
 
 	RegisterChunkedUploadRoutes(fileRoutes)
 
+	RegisterTusRoutes(fileRoutes, middleware.RequireAuth())
+
 	RegisterConfigRoutes(version)
 
 	folderRoutes := version.Group("/folders")
 	RegisterFolderRoutes(folderRoutes)
 
+	albumRoutes := version.Group("/albums")
+	RegisterAlbumRoutes(albumRoutes)
+
 	tagRoutes := version.Group("/tags")
 	RegisterTagRoutes(tagRoutes)
 
@@ -154,9 +159,21 @@ func RegisterRoutes(r *gin.Engine) {
 
 	r.GET("/file/admin/:fileName", fileController.ServeAdminFile)
 
+	// tus 路由自行在协议中间件之后认证，需在API密钥中间件之前注册
+	RegisterTusRoutes(r.Group("/api/v1/external"), middleware.APIKeyAuthMiddleware())
+
 	apiUploadRoutes := r.Group("/api/v1/external")
 	apiUploadRoutes.Use(middleware.APIKeyAuthMiddleware())
 	apiUploadRoutes.POST("/upload", fileController.UploadForApiKey)
+	apiUploadRoutes.POST("/import-url", middleware.UploadConcurrencyLimit(), fileController.ImportFromURL)
+	apiUploadRoutes.POST("/import-jobs", fileController.CreateURLImportJob)
+	apiUploadRoutes.GET("/import-jobs", fileController.ListImportJobs)
+	apiUploadRoutes.GET("/import-jobs/:job_id", fileController.GetImportJob)
+	apiUploadRoutes.GET("/import-jobs/:job_id/items", fileController.ListImportJobItems)
+	apiUploadRoutes.POST("/import-jobs/:job_id/cancel", fileController.CancelImportJob)
+	apiUploadRoutes.POST("/signed-links", fileController.MintSignedLinks)
+	apiUploadRoutes.GET("/signed-links", fileController.ListSignedLinks)
+	apiUploadRoutes.DELETE("/signed-links/:link_id", fileController.RevokeSignedLink)
 
 	// 随机图片API公开接口（不需要认证）
 	randomImageRoutes := r.Group("/api/v1/r")
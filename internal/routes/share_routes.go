@@ -22,6 +22,10 @@ func RegisterShareRoutes(r *gin.RouterGroup) {
 
 	userShareGroup.DELETE("/:id/visitors/:visitor_id", shareController.DeleteShareVisitor)
 
+	userShareGroup.GET("/:id/analytics", shareController.GetShareAnalytics)
+
+	userShareGroup.GET("/:id/analytics/export", shareController.ExportShareAnalytics)
+
 	userShareGroup.DELETE("/:id", shareController.DeleteShare)
 
 	publicGroup := r.Group("/public")
@@ -33,4 +37,8 @@ func RegisterShareRoutes(r *gin.RouterGroup) {
 	publicGroup.POST("/:key/visitor", shareController.SubmitVisitorInfo)
 
 	publicGroup.GET("/:key/files/:file_id/download", shareController.DownloadSharedFile)
+
+	publicGroup.GET("/:key/timeline", shareController.GetShareTimeline)
+
+	publicGroup.GET("/:key/timeline/files", shareController.GetShareTimelineFiles)
 }
@@ -35,5 +35,14 @@ func RegisterTagRoutes(r *gin.RouterGroup) {
 
 		adminRoute.GET("/stats/detailed", tagController.GetDetailedTagStats)
 		adminRoute.GET("/analytics", tagController.GetTagAnalytics)
+
+		adminRoute.GET("/tree", tagController.GetTagTree)
+		adminRoute.PUT("/:tag_id/parent", tagController.SetTagParent)
+		adminRoute.GET("/:tag_id/aliases", tagController.ListTagAliases)
+		adminRoute.POST("/:tag_id/aliases", tagController.AddTagAliases)
+		adminRoute.DELETE("/:tag_id/aliases/:alias_id", tagController.RemoveTagAlias)
+
+		adminRoute.GET("/taxonomy/export", tagController.ExportTaxonomy)
+		adminRoute.POST("/taxonomy/import", tagController.ImportTaxonomy)
 	}
 }
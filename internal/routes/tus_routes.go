@@ -0,0 +1,26 @@
+package routes
+
+import (
+	fileController "pixelpunk/internal/controllers/file"
+	"pixelpunk/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterTusRoutes 注册 tus 1.0 可续传上传路由，auth 为登录或API密钥认证中间件
+func RegisterTusRoutes(r *gin.RouterGroup, auth gin.HandlerFunc) {
+	tus := r.Group("/tus")
+	tus.Use(middleware.TusProtocol(), auth)
+	{
+		tus.OPTIONS("", fileController.TusOptions)
+		tus.OPTIONS("/:id", fileController.TusOptions)
+
+		tus.POST("", fileController.CreateTusUpload)
+
+		tus.HEAD("/:id", fileController.GetTusUploadOffset)
+
+		tus.PATCH("/:id", fileController.PatchTusUpload)
+
+		tus.DELETE("/:id", fileController.TerminateTusUpload)
+	}
+}
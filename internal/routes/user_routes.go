@@ -2,6 +2,7 @@ package routes
 
 import (
 	activityController "pixelpunk/internal/controllers/activity"
+	oauthController "pixelpunk/internal/controllers/oauth"
 	userController "pixelpunk/internal/controllers/user"
 	"pixelpunk/internal/middleware"
 
@@ -12,6 +13,8 @@ import (
 func RegisterPublicUserRoutes(r *gin.RouterGroup) {
 	r.POST("/register", userController.Register)
 	r.POST("/login", userController.Login)
+	r.POST("/login/2fa", userController.LoginTwoFactor)
+	r.POST("/refresh", userController.RefreshToken)
 	r.POST("/send-registration-code", userController.SendRegistrationCode)
 	r.POST("/send-reset-password-code", userController.SendResetPasswordCode)
 	r.POST("/reset-password", userController.ResetPassword)
@@ -41,6 +44,34 @@ func RegisterUserRoutes(r *gin.RouterGroup) {
 		userGroup.GET("/workspace/stats", userController.GetWorkspaceStats)
 
 		userGroup.GET("/activities", activityController.GetUserActivities)
+
+		userGroup.GET("/2fa/status", userController.GetTwoFactorStatus)
+		userGroup.POST("/2fa/setup", userController.SetupTwoFactor)
+		userGroup.POST("/2fa/enable", userController.EnableTwoFactor)
+		userGroup.POST("/2fa/disable", userController.DisableTwoFactor)
+		userGroup.POST("/2fa/recovery-codes", userController.RegenerateRecoveryCodes)
+
+		userGroup.POST("/logout", userController.Logout)
+		userGroup.GET("/sessions", userController.ListSessions)
+		userGroup.DELETE("/sessions/:session_id", userController.RevokeSession)
+		userGroup.POST("/sessions/revoke-others", userController.RevokeOtherSessions)
+
+		userGroup.GET("/exif-policy", userController.GetExifPolicy)
+		userGroup.PUT("/exif-policy", userController.UpdateExifPolicy)
+
+		userGroup.GET("/watermark-profiles", userController.ListWatermarkProfiles)
+		userGroup.POST("/watermark-profiles", userController.CreateWatermarkProfile)
+		userGroup.PUT("/watermark-profiles/default", userController.SetDefaultWatermarkProfile)
+		userGroup.PUT("/watermark-profiles/:profile_id", userController.UpdateWatermarkProfile)
+		userGroup.DELETE("/watermark-profiles/:profile_id", userController.DeleteWatermarkProfile)
+		userGroup.GET("/watermark-policies", userController.ListWatermarkPolicies)
+		userGroup.POST("/watermark-policies", userController.CreateWatermarkPolicy)
+		userGroup.PUT("/watermark-policies/:policy_id", userController.UpdateWatermarkPolicy)
+		userGroup.DELETE("/watermark-policies/:policy_id", userController.DeleteWatermarkPolicy)
+
+		userGroup.GET("/identities", oauthController.ListIdentities)
+		userGroup.POST("/identities/link", oauthController.LinkIdentity)
+		userGroup.DELETE("/identities/:id", oauthController.UnlinkIdentity)
 	}
 
 	adminGroup := r.Group("/admin")
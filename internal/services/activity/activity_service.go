@@ -897,3 +897,67 @@ func LogRandomAPIToggleStatus(userID uint, apiName string, apiID uint, newStatus
 
 	globalService.LogActivityAsync(params)
 }
+
+/* LogTwoFactorChange 记录两步验证状态变更（enable/disable/regenerate_recovery_codes/admin_reset） */
+func LogTwoFactorChange(userID uint, action string, operatorID uint) {
+	params := LogActivityParams{
+		UserID:     &userID,
+		Type:       "two_factor_change",
+		Module:     "auth",
+		EntityType: "user",
+		EntityID:   fmt.Sprintf("%d", userID),
+		IsVisible:  true,
+		Tags:       fmt.Sprintf("security,2fa,%s", action),
+		Data: map[string]any{
+			"action":      action,
+			"operator_id": operatorID,
+		},
+	}
+
+	globalService.LogActivityAsync(params)
+}
+
+/* LogTwoFactorLogin 记录两步验证登录结果 */
+func LogTwoFactorLogin(userID uint, clientIP string, success bool, usedRecoveryCode bool) {
+	result := "failed"
+	if success {
+		result = "success"
+	}
+
+	params := LogActivityParams{
+		UserID:     &userID,
+		Type:       "two_factor_login",
+		Module:     "auth",
+		EntityType: "user",
+		EntityID:   fmt.Sprintf("%d", userID),
+		IsVisible:  true,
+		Tags:       fmt.Sprintf("security,2fa,login,%s", result),
+		Data: map[string]any{
+			"ip_address":         clientIP,
+			"success":            success,
+			"used_recovery_code": usedRecoveryCode,
+		},
+	}
+
+	globalService.LogActivityAsync(params)
+}
+
+/* LogSessionRevoke 记录登录会话吊销（用户主动退出设备或管理员强制下线） */
+func LogSessionRevoke(userID uint, action string, count int64, operatorID uint) {
+	params := LogActivityParams{
+		UserID:     &userID,
+		Type:       "session_revoke",
+		Module:     "auth",
+		EntityType: "user",
+		EntityID:   fmt.Sprintf("%d", userID),
+		IsVisible:  true,
+		Tags:       fmt.Sprintf("security,session,%s", action),
+		Data: map[string]any{
+			"action":      action,
+			"count":       count,
+			"operator_id": operatorID,
+		},
+	}
+
+	globalService.LogActivityAsync(params)
+}
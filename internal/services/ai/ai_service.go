@@ -10,6 +10,7 @@ import (
 	"pixelpunk/pkg/ai"
 	"pixelpunk/pkg/common"
 	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/hooks"
 	"pixelpunk/pkg/logger"
 	"strings"
 	"time"
@@ -106,6 +107,7 @@ func AiImageTaggingAndSaveWithBase64(file models.File, base64Data, imageFormat s
 		return err
 	}
 
+	hooks.TriggerFileEvent(hooks.FileEventAITagged, file.ID)
 	return nil
 }
 
@@ -178,6 +180,13 @@ func processAIResponse(tx *gorm.DB, file models.File, aiResp *AIFileResponse, co
 	if file.Resolution != "" {
 		result.BasicInfo.Resolution = file.Resolution
 	}
+	// 视频分析的是关键帧拼图、文档分析的是首页预览，尺寸与格式以上传时的解析结果为准
+	if file.IsVideo() || file.IsDocument() {
+		result.BasicInfo.Width = file.Width
+		result.BasicInfo.Height = file.Height
+		result.BasicInfo.AspectRatio = file.Ratio
+		result.BasicInfo.ImageType = file.Format
+	}
 
 	// 使用 UPSERT 保存AI信息，自动处理新建或更新
 	_, err = saveFileAIInfo(tx, file.ID, result, aiResp.Usage)
@@ -189,6 +198,13 @@ func processAIResponse(tx *gorm.DB, file models.File, aiResp *AIFileResponse, co
 		logger.Error("保存AI标记结果失败: %v", err)
 		return err
 	}
+	// 文档的描述即摘要
+	if file.IsDocument() && result.Description != "" {
+		if err := tx.Model(&models.FileAIInfo{}).Where("file_id = ?", file.ID).
+			Update("document_summary", result.Description).Error; err != nil {
+			logger.Warn("保存文档摘要失败: %v", err)
+		}
+	}
 
 	// 处理标签 - 根据配置决定是否为敏感内容生成标签
 	if !contentDetectionEnabled || !result.ContentSafety.IsNSFW {
@@ -2,10 +2,13 @@ package ai
 
 import (
 	"fmt"
+	"pixelpunk/internal/cluster"
 	"pixelpunk/internal/controllers/websocket"
 	"pixelpunk/internal/models"
+	qqueue "pixelpunk/internal/queue"
 	"pixelpunk/internal/services/setting"
 	ws "pixelpunk/internal/websocket"
+	"pixelpunk/pkg/cache"
 	"pixelpunk/pkg/common"
 	"pixelpunk/pkg/logger"
 	"sync"
@@ -133,6 +136,17 @@ func InitGlobalTaggingQueue() error {
 		return nil
 	}
 
+	// 不运行 worker 的进程只向队列投递任务，由 worker 进程处理
+	if !cluster.RunsWorkers() {
+		if err := InitTaggingQueueClient(); err != nil {
+			return err
+		}
+		if !setting.GetBool("ai", "ai_auto_processing_enabled", true) {
+			globalTaggingService.Pause()
+		}
+		return nil
+	}
+
 	db := GetDBFromContext()
 	if db == nil {
 		return fmt.Errorf("无法获取数据库连接")
@@ -186,6 +200,52 @@ func InitGlobalTaggingQueue() error {
 	return nil
 }
 
+// InitTaggingQueueClient 只连接AI打标队列，不启动处理流水线和后台任务，供命令行向服务进程的队列投递任务
+func InitTaggingQueueClient() error {
+	if globalTaggingService != nil {
+		return nil
+	}
+	db := GetDBFromContext()
+	if db == nil {
+		return fmt.Errorf("无法获取数据库连接")
+	}
+
+	svc := &TaggingService{db: db, reaperStop: make(chan struct{})}
+	if cache.IsRedisEnabled() {
+		if rq := qqueue.NewRedisQueue(); rq != nil {
+			svc.taskQueue = rq
+		}
+	}
+	if svc.taskQueue == nil {
+		svc.taskQueue = qqueue.NewDBQueue()
+	}
+	SetGlobalTaggingService(svc)
+	return nil
+}
+
+// SyncRuntimeSettings 按数据库中的设置启动打标服务并同步暂停状态和并发数，供收不到其他进程设置变更通知的 worker 进程定时调用
+func SyncRuntimeSettings() {
+	svc := globalTaggingService
+	if svc == nil {
+		if setting.GetBoolDirectFromDB("ai", "ai_enabled", false) {
+			if err := InitGlobalTaggingQueue(); err != nil {
+				logger.Warn("[AI服务] 初始化队列失败: %v", err)
+			}
+		}
+		return
+	}
+
+	autoProcessing := setting.GetBoolDirectFromDB("ai", "ai_auto_processing_enabled", true)
+	if autoProcessing && svc.IsPaused() {
+		svc.Resume()
+	} else if !autoProcessing && !svc.IsPaused() {
+		svc.Pause()
+	}
+	if concurrency := setting.GetIntDirectFromDB("ai", "ai_concurrency", 5); concurrency > 0 {
+		_ = svc.UpdateConcurrency(concurrency)
+	}
+}
+
 func RecoverPendingOnStartup(limit int) (int, error) {
 	db := GetDBFromContext()
 	if db == nil {
@@ -246,7 +306,7 @@ func AddFileToQueue(file models.File) error {
 
 	if err := db.Model(&models.File{}).Where("id = ?", file.ID).
 		Updates(map[string]interface{}{
-			"ai_tagging_status": common.AITaggingStatusPending,
+			"ai_tagging_status":    common.AITaggingStatusPending,
 			"ai_last_heartbeat_at": time.Now(),
 		}).Error; err != nil {
 		logger.Error("更新文件AI状态失败: %v", err)
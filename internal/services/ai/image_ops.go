@@ -56,6 +56,8 @@ func executeFileDeletion(file *models.File) error {
 
 	database.DB.Where("item_type = ? AND item_id = ?", "file", fileID).Delete(&models.ShareItem{})
 
+	database.DB.Where("file_id = ?", fileID).Delete(&models.AlbumItem{})
+
 	database.DB.Where("file_id = ?", fileID).Delete(&models.UploadSession{})
 
 	storageService := storage.NewGlobalStorage()
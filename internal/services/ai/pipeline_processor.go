@@ -9,6 +9,7 @@ import (
 	"pixelpunk/internal/models"
 	qqueue "pixelpunk/internal/queue"
 	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/hooks"
 	"pixelpunk/pkg/logger"
 	ai "pixelpunk/pkg/ai"
 
@@ -274,6 +275,13 @@ func (pp *PipelineProcessor) fileLoader(workerID int) {
 
 		base64Data, imageFormat, err := pp.service.readImageAsBase64(file)
 		if err != nil {
+			if errors.Is(err, errVideoFramesUnavailable) {
+				// 没有 ffmpeg 时视频不参与 AI 分析，安装后可重新触发
+				_ = pp.service.db.Model(&models.File{}).Where("id = ?", file.ID).
+					Update("ai_tagging_status", common.AITaggingStatusIgnored).Error
+				task.Ack()
+				continue
+			}
 			if errors.Is(err, errMissingFile) {
 				policy := getMissingFilePolicy()
 				switch policy {
@@ -498,13 +506,18 @@ func (pp *PipelineProcessor) saveResultToDB(result *ProcessResult) error {
 		return err
 	}
 
-	return db.Model(&models.File{}).
+	if err := db.Model(&models.File{}).
 		Where("id = ?", result.FileID).
 		Updates(map[string]interface{}{
 			"ai_tagging_status": common.AITaggingStatusDone,
 			"ai_tagging_tries":  0,
 			"ai_http_duration":  result.HttpDuration,
-		}).Error
+		}).Error; err != nil {
+		return err
+	}
+
+	hooks.TriggerFileEvent(hooks.FileEventAITagged, result.FileID)
+	return nil
 }
 
 func getContentDetectionEnabled() bool {
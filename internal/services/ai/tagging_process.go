@@ -31,6 +31,9 @@ func (s *TaggingService) processImageWithBase64(file models.File) error {
 
 // readImageAsBase64 使用存储适配器读取文件文件并转换为Base64
 func (s *TaggingService) readImageAsBase64(file models.File) (string, string, error) {
+	if file.IsVideo() {
+		return s.readVideoFramesAsBase64(file)
+	}
 	ctx := context.Background()
 
 	if file.StorageProviderID == "" {
@@ -95,6 +98,11 @@ func (s *TaggingService) readImageAsBase64(file models.File) (string, string, er
 		}
 	}
 
+	// 文档原文件不是图片，只能使用首页预览缩略图
+	if file.IsDocument() {
+		originalPath = ""
+	}
+
 	if thumbPath != "" {
 		readAttempts = append(readAttempts, fmt.Sprintf("缩略图路径: %s", thumbPath))
 		// 对 local 做对象键归一化，避免重复前缀
@@ -0,0 +1,95 @@
+package ai
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"pixelpunk/internal/models"
+	sadapter "pixelpunk/pkg/storage/adapter"
+	"pixelpunk/pkg/video"
+)
+
+const (
+	// videoKeyFrames 视频分析截取的关键帧数量，拼成 2×2 的网格图
+	videoKeyFrames = 4
+	// videoSheetCellWidth 网格中每帧的宽度
+	videoSheetCellWidth = 640
+)
+
+// errVideoFramesUnavailable 没有 ffmpeg 时无法截取视频帧，视频跳过 AI 分析
+var errVideoFramesUnavailable = errors.New("ai:video_frames_unavailable")
+
+// readVideoFramesAsBase64 截取视频的关键帧拼成一张网格图，作为视频的 AI 分析输入
+func (s *TaggingService) readVideoFramesAsBase64(file models.File) (string, string, error) {
+	if !video.Available() {
+		return "", "", errVideoFramesUnavailable
+	}
+
+	input, cleanup, err := s.videoInputPath(file)
+	if err != nil {
+		return "", "", err
+	}
+	defer cleanup()
+
+	var duration float64
+	s.db.Model(&models.FileAIInfo{}).Where("file_id = ?", file.ID).Select("duration").Scan(&duration)
+	if duration <= 0 {
+		data, err := os.ReadFile(input)
+		if err != nil {
+			return "", "", fmt.Errorf("读取视频失败: %w", err)
+		}
+		if info, err := video.Probe(data); err == nil {
+			duration = info.Duration
+		}
+	}
+
+	frames, err := video.ExtractFrames(context.Background(), input, video.KeyFrameTimes(duration, videoKeyFrames), videoSheetCellWidth)
+	if err != nil {
+		return "", "", fmt.Errorf("截取视频关键帧失败: %w", err)
+	}
+	sheet, err := video.ContactSheet(frames, 2, videoSheetCellWidth)
+	if err != nil {
+		return "", "", fmt.Errorf("拼接视频关键帧失败: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(sheet), "jpg", nil
+}
+
+// videoInputPath ffmpeg 需要可寻址的文件：本地存储直接使用原文件，其他存储下载到临时文件
+func (s *TaggingService) videoInputPath(file models.File) (string, func(), error) {
+	if file.StorageType == "local" && file.LocalFilePath != "" {
+		if _, err := os.Stat(file.LocalFilePath); err == nil {
+			return file.LocalFilePath, func() {}, nil
+		}
+	}
+	if file.StorageProviderID == "" || file.LocalFilePath == "" {
+		return "", nil, fmt.Errorf("文件缺少存储路径: %s", file.ID)
+	}
+
+	reader, err := s.storage.ReadFile(context.Background(), file.StorageProviderID, file.LocalFilePath)
+	if err != nil {
+		if sadapter.IsNotFoundError(err) {
+			return "", nil, fmt.Errorf("missing file: %w", errMissingFile)
+		}
+		return "", nil, fmt.Errorf("读取视频失败: %w", err)
+	}
+	defer reader.Close()
+
+	tmp, err := os.CreateTemp("", "pixelpunk-ai-video-*")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.Remove(tmp.Name()) }
+	_, err = io.Copy(tmp, reader)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("下载视频失败: %w", err)
+	}
+	return tmp.Name(), cleanup, nil
+}
@@ -12,6 +12,7 @@ import (
 	"pixelpunk/pkg/ai/prompts"
 	"pixelpunk/pkg/common"
 	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/document"
 
 	"gorm.io/gorm"
 )
@@ -69,6 +70,11 @@ func performAITagging(file models.File, base64Data, imageFormat, categoryName, c
 
 	// 使用统一的提示词管理获取增强提示词
 	enhancedPrompt := prompts.GetEnhancedImageAnalysisPrompt(categoryName, categoryDescription, promptTags)
+	if file.IsVideo() {
+		enhancedPrompt = prompts.WithVideoKeyFrames(enhancedPrompt)
+	} else if file.IsDocument() {
+		enhancedPrompt = withDocumentText(file, enhancedPrompt)
+	}
 
 	aiResp, err := ai.AnalyzeImageByBase64(base64Data, imageFormat, enhancedPrompt)
 	if err != nil {
@@ -78,6 +84,24 @@ func performAITagging(file models.File, base64Data, imageFormat, categoryName, c
 	return convertAIResponse(aiResp), nil
 }
 
+// documentPromptRunes 附在提示词中的文档正文节选长度
+const documentPromptRunes = 3000
+
+// withDocumentText 文档的首页预览信息有限，将上传时提取的正文节选附在提示词中
+func withDocumentText(file models.File, prompt string) string {
+	var row struct {
+		DocumentText string
+		PageCount    int
+	}
+	GetDBFromContext().Model(&models.FileAIInfo{}).Where("file_id = ?", file.ID).
+		Select("document_text", "page_count").Scan(&row)
+	name := file.DisplayName
+	if name == "" {
+		name = file.OriginalName
+	}
+	return prompts.WithDocumentText(prompt, name, row.PageCount, document.Excerpt(row.DocumentText, documentPromptRunes))
+}
+
 // convertAIResponse 转换AI响应格式
 func convertAIResponse(aiResp *ai.AIResponse) *AIFileResponse {
 	convertedResp := &AIFileResponse{
@@ -9,6 +9,7 @@ import (
 	vector2 "pixelpunk/internal/services/vector"
 	"pixelpunk/pkg/common"
 	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/document"
 	"pixelpunk/pkg/logger"
 	"pixelpunk/pkg/vector"
 	"strings"
@@ -16,6 +17,9 @@ import (
 	"gorm.io/gorm"
 )
 
+// documentVectorRunes 向量内容中附带的文档正文长度，受嵌入模型输入长度限制
+const documentVectorRunes = 1000
+
 // buildEnrichedVectorContent 构建专为语义搜索优化的向量内容
 func buildEnrichedVectorContent(fileID, description string) (string, error) {
 	db := GetDBFromContext()
@@ -80,6 +84,10 @@ func buildEnrichedVectorContent(fileID, description string) (string, error) {
 			contentParts = append(contentParts, "高清")
 		}
 	}
+	// 文档附上正文开头，使按正文内容的搜索也能命中
+	if aiInfo.DocumentText != "" {
+		contentParts = append(contentParts, document.Excerpt(aiInfo.DocumentText, documentVectorRunes))
+	}
 	result := strings.Join(contentParts, " ")
 	if strings.TrimSpace(result) == "" {
 		return description, nil
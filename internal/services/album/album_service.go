@@ -0,0 +1,378 @@
+package album
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"pixelpunk/internal/controllers/album/dto"
+	"pixelpunk/internal/models"
+	filesvc "pixelpunk/internal/services/file"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+const (
+	maxAlbumsPerUser   = 200
+	maxAlbumItems      = 10000
+	smartAlbumDateForm = "2006-01-02"
+)
+
+/* AlbumInfo 相册信息，附带当前文件数量 */
+type AlbumInfo struct {
+	models.Album
+	FileCount int64 `json:"file_count"`
+}
+
+/* ListAlbums 获取用户的全部相册 */
+func ListAlbums(userID uint) ([]AlbumInfo, error) {
+	var albums []models.Album
+	if err := database.DB.Where("user_id = ?", userID).
+		Order("sort_order ASC, created_at DESC").
+		Find(&albums).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询相册失败")
+	}
+
+	result := make([]AlbumInfo, 0, len(albums))
+	for i := range albums {
+		result = append(result, AlbumInfo{Album: albums[i], FileCount: countAlbumFiles(&albums[i])})
+	}
+	return result, nil
+}
+
+/* GetAlbum 获取用户自己的相册 */
+func GetAlbum(userID uint, albumID string) (*models.Album, error) {
+	var album models.Album
+	if err := database.DB.Where("id = ? AND user_id = ?", albumID, userID).First(&album).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New(errors.CodeNotFound, "相册不存在")
+		}
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询相册失败")
+	}
+	return &album, nil
+}
+
+/* GetAlbumDetail 获取相册详情 */
+func GetAlbumDetail(userID uint, albumID string) (*AlbumInfo, error) {
+	album, err := GetAlbum(userID, albumID)
+	if err != nil {
+		return nil, err
+	}
+	return &AlbumInfo{Album: *album, FileCount: countAlbumFiles(album)}, nil
+}
+
+/* CreateAlbum 创建手动或智能相册 */
+func CreateAlbum(userID uint, req *dto.CreateAlbumDTO) (*AlbumInfo, error) {
+	var count int64
+	database.DB.Model(&models.Album{}).Where("user_id = ?", userID).Count(&count)
+	if count >= maxAlbumsPerUser {
+		return nil, errors.New(errors.CodeInvalidRequest, fmt.Sprintf("每个用户最多创建%d个相册", maxAlbumsPerUser))
+	}
+
+	album := models.Album{
+		ID:          utils.GenerateFileID(),
+		UserID:      userID,
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Type:        req.Type,
+	}
+
+	if album.IsSmart() {
+		if req.Query == nil {
+			return nil, errors.New(errors.CodeInvalidParameter, "智能相册需要设置查询条件")
+		}
+		query, err := encodeQuery(req.Query)
+		if err != nil {
+			return nil, err
+		}
+		album.Query = query
+	}
+
+	if req.CoverFileID != "" {
+		if !ownsFiles(userID, []string{req.CoverFileID}) {
+			return nil, errors.New(errors.CodeFileNotFound, "封面文件不存在")
+		}
+		album.CoverFileID = req.CoverFileID
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&album).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBCreateFailed, "创建相册失败")
+		}
+		if !album.IsSmart() && len(req.FileIDs) > 0 {
+			if _, err := addAlbumItems(tx, &album, req.FileIDs); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &AlbumInfo{Album: album, FileCount: countAlbumFiles(&album)}, nil
+}
+
+/* UpdateAlbum 更新相册名称、描述、封面与查询条件 */
+func UpdateAlbum(userID uint, albumID string, req *dto.UpdateAlbumDTO) (*AlbumInfo, error) {
+	album, err := GetAlbum(userID, albumID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, errors.New(errors.CodeInvalidParameter, "相册名称不能为空")
+		}
+		album.Name = name
+	}
+	if req.Description != nil {
+		album.Description = *req.Description
+	}
+	if req.SortOrder != nil {
+		album.SortOrder = *req.SortOrder
+	}
+	if req.CoverFileID != nil {
+		if *req.CoverFileID != "" && !ownsFiles(userID, []string{*req.CoverFileID}) {
+			return nil, errors.New(errors.CodeFileNotFound, "封面文件不存在")
+		}
+		album.CoverFileID = *req.CoverFileID
+	}
+	if req.Query != nil {
+		if !album.IsSmart() {
+			return nil, errors.New(errors.CodeInvalidParameter, "手动相册不支持查询条件")
+		}
+		query, err := encodeQuery(req.Query)
+		if err != nil {
+			return nil, err
+		}
+		album.Query = query
+	}
+
+	if err := database.DB.Save(album).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBUpdateFailed, "更新相册失败")
+	}
+	return &AlbumInfo{Album: *album, FileCount: countAlbumFiles(album)}, nil
+}
+
+/* DeleteAlbum 删除相册，文件本身不受影响 */
+func DeleteAlbum(userID uint, albumID string) error {
+	album, err := GetAlbum(userID, albumID)
+	if err != nil {
+		return err
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("album_id = ?", album.ID).Delete(&models.AlbumItem{}).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBDeleteFailed, "删除相册文件失败")
+		}
+		if err := tx.Where("item_type = ? AND item_id = ?", common.ShareItemTypeAlbum, album.ID).Delete(&models.ShareItem{}).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBDeleteFailed, "删除相册分享项失败")
+		}
+		if err := tx.Delete(album).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBDeleteFailed, "删除相册失败")
+		}
+		return nil
+	})
+}
+
+/* AddFilesToAlbum 向手动相册添加文件，已存在的文件会被跳过 */
+func AddFilesToAlbum(userID uint, albumID string, fileIDs []string) (int, error) {
+	album, err := GetAlbum(userID, albumID)
+	if err != nil {
+		return 0, err
+	}
+	if album.IsSmart() {
+		return 0, errors.New(errors.CodeInvalidRequest, "智能相册的内容由查询条件决定，不能手动添加")
+	}
+	return addAlbumItems(database.DB, album, fileIDs)
+}
+
+/* RemoveFilesFromAlbum 从手动相册移除文件 */
+func RemoveFilesFromAlbum(userID uint, albumID string, fileIDs []string) (int64, error) {
+	album, err := GetAlbum(userID, albumID)
+	if err != nil {
+		return 0, err
+	}
+	if album.IsSmart() {
+		return 0, errors.New(errors.CodeInvalidRequest, "智能相册的内容由查询条件决定，不能手动移除")
+	}
+
+	result := database.DB.Where("album_id = ? AND file_id IN ?", album.ID, fileIDs).Delete(&models.AlbumItem{})
+	if result.Error != nil {
+		return 0, errors.Wrap(result.Error, errors.CodeDBDeleteFailed, "移除文件失败")
+	}
+	return result.RowsAffected, nil
+}
+
+/* ListAlbumFiles 分页获取相册中的文件，复用文件列表的筛选与排序 */
+func ListAlbumFiles(userID uint, albumID string, page, size int, sort string) ([]filesvc.FileDetailResponse, int64, error) {
+	album, err := GetAlbum(userID, albumID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	params, err := AlbumSearchParams(album)
+	if err != nil {
+		return nil, 0, err
+	}
+	params.Page = page
+	params.Size = size
+	if sort != "" {
+		params.Sort = sort
+	}
+	return filesvc.GetUserFileListByParams(params)
+}
+
+/* AlbumSearchParams 将相册转换为文件搜索参数，始终限定为相册所有者的文件 */
+func AlbumSearchParams(album *models.Album) (filesvc.AdminFileSearchParams, error) {
+	params := filesvc.AdminFileSearchParams{UserID: album.UserID, Sort: "newest"}
+	if !album.IsSmart() {
+		params.AlbumID = album.ID
+		return params, nil
+	}
+
+	var q dto.AlbumQueryDTO
+	if len(album.Query) > 0 {
+		if err := json.Unmarshal(album.Query, &q); err != nil {
+			return params, errors.New(errors.CodeInvalidParameter, "相册查询条件格式错误")
+		}
+	}
+
+	params.Keyword = q.Keyword
+	params.Tags = q.Tags
+	params.CategoryIDs = q.CategoryIDs
+	params.DominantColor = q.DominantColor
+	params.Resolution = q.Resolution
+	params.MinWidth = q.MinWidth
+	params.MaxWidth = q.MaxWidth
+	params.MinHeight = q.MinHeight
+	params.MaxHeight = q.MaxHeight
+	params.CameraMake = q.CameraMake
+	params.CameraModel = q.CameraModel
+	params.FolderID = q.FolderID
+	params.AccessLevel = q.AccessLevel
+	if q.Sort != "" {
+		params.Sort = q.Sort
+	}
+	if q.DateFrom != "" {
+		if t, err := time.ParseInLocation(smartAlbumDateForm, q.DateFrom, time.Local); err == nil {
+			params.CreatedFrom = &t
+		}
+	}
+	if q.DateTo != "" {
+		if t, err := time.ParseInLocation(smartAlbumDateForm, q.DateTo, time.Local); err == nil {
+			end := t.AddDate(0, 0, 1)
+			params.CreatedTo = &end
+		}
+	}
+	return params, nil
+}
+
+/* AlbumFileIDs 获取相册中的文件ID，最多 limit 个 */
+func AlbumFileIDs(album *models.Album, limit int) ([]string, error) {
+	params, err := AlbumSearchParams(album)
+	if err != nil {
+		return nil, err
+	}
+	return filesvc.SearchFileIDs(params, limit)
+}
+
+/* AlbumContainsFile 判断文件当前是否属于相册 */
+func AlbumContainsFile(album *models.Album, fileID string) bool {
+	params, err := AlbumSearchParams(album)
+	if err != nil {
+		return false
+	}
+	params.FileIDs = []string{fileID}
+	count, err := filesvc.CountSearchFiles(params)
+	return err == nil && count > 0
+}
+
+func countAlbumFiles(album *models.Album) int64 {
+	params, err := AlbumSearchParams(album)
+	if err != nil {
+		return 0
+	}
+	count, _ := filesvc.CountSearchFiles(params)
+	return count
+}
+
+func encodeQuery(q *dto.AlbumQueryDTO) (json.RawMessage, error) {
+	if q.DateFrom != "" && q.DateTo != "" && q.DateFrom > q.DateTo {
+		return nil, errors.New(errors.CodeInvalidParameter, "开始日期不能晚于结束日期")
+	}
+	data, err := json.Marshal(q)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeInvalidParameter, "查询条件格式错误")
+	}
+	return data, nil
+}
+
+func ownsFiles(userID uint, fileIDs []string) bool {
+	var count int64
+	database.DB.Model(&models.File{}).
+		Where("id IN ? AND user_id = ? AND status <> ?", fileIDs, userID, filesvc.StatusPendingDeletion).
+		Count(&count)
+	return count == int64(len(fileIDs))
+}
+
+func addAlbumItems(tx *gorm.DB, album *models.Album, fileIDs []string) (int, error) {
+	fileIDs = uniqueIDs(fileIDs)
+	if !ownsFiles(album.UserID, fileIDs) {
+		return 0, errors.New(errors.CodeInvalidParameter, "部分文件不存在或无权限")
+	}
+
+	var existing []string
+	tx.Model(&models.AlbumItem{}).Where("album_id = ? AND file_id IN ?", album.ID, fileIDs).Pluck("file_id", &existing)
+	existingSet := make(map[string]bool, len(existing))
+	for _, id := range existing {
+		existingSet[id] = true
+	}
+
+	var total int64
+	tx.Model(&models.AlbumItem{}).Where("album_id = ?", album.ID).Count(&total)
+
+	var maxSort struct{ Max int }
+	tx.Model(&models.AlbumItem{}).Select("COALESCE(MAX(sort_order), 0) AS max").Where("album_id = ?", album.ID).Scan(&maxSort)
+
+	items := make([]models.AlbumItem, 0, len(fileIDs))
+	for _, id := range fileIDs {
+		if existingSet[id] {
+			continue
+		}
+		maxSort.Max++
+		items = append(items, models.AlbumItem{AlbumID: album.ID, FileID: id, SortOrder: maxSort.Max})
+	}
+	if len(items) == 0 {
+		return 0, nil
+	}
+	if total+int64(len(items)) > maxAlbumItems {
+		return 0, errors.New(errors.CodeInvalidRequest, fmt.Sprintf("单个相册最多包含%d个文件", maxAlbumItems))
+	}
+
+	if err := tx.Create(&items).Error; err != nil {
+		return 0, errors.Wrap(err, errors.CodeDBCreateFailed, "添加文件到相册失败")
+	}
+	return len(items), nil
+}
+
+func uniqueIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	result := make([]string, 0, len(ids))
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+	}
+	return result
+}
@@ -167,7 +167,7 @@ func GetAnnouncementList(query *dto.AnnouncementQueryDTO) (*dto.AnnouncementList
 
 	if query.Keyword != "" {
 		keyword := "%" + query.Keyword + "%"
-		queryBuilder = queryBuilder.Where("title LIKE ? OR summary LIKE ?", keyword, keyword)
+		queryBuilder = queryBuilder.Where(database.ILike("title LIKE ? OR summary LIKE ?"), keyword, keyword)
 	}
 
 	var total int64
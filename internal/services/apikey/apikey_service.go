@@ -45,7 +45,7 @@ func generateAPIKeyValue() (string, error) {
 		}
 
 		var count int64
-		if err := database.DB.Model(&models.APIKey{}).Where("key = ?", keyValue).Count(&count).Error; err != nil {
+		if err := database.DB.Model(&models.APIKey{}).Where(map[string]interface{}{"key": keyValue}).Count(&count).Error; err != nil {
 			return keyValue, nil
 		}
 
@@ -166,7 +166,7 @@ func GetAPIKeyList(userID uint, page, size int, status int, search string) ([]mo
 	}
 
 	if search != "" {
-		query = query.Where("name LIKE ?", "%"+search+"%")
+		query = query.Where(database.ILike("name LIKE ?"), "%"+search+"%")
 	}
 
 	var total int64
@@ -12,6 +12,8 @@ type JWTClaims struct {
 	UserID   uint   `json:"user_id"`
 	Role     int    `json:"role"`
 	Username string `json:"username"`
+	// SessionID 会话ID，旧版令牌为空
+	SessionID string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -52,6 +54,29 @@ func GenerateToken(userID uint, username string, role int, jwtSecret string, exp
 	return token.SignedString([]byte(jwtSecret))
 }
 
+/* GenerateSessionToken 生成绑定会话的短期访问令牌 */
+func GenerateSessionToken(userID uint, username string, role int, jwtSecret string, sessionID string, ttl time.Duration) (string, error) {
+	if jwtSecret == "" {
+		jwtSecret = defaultJWTSecret
+	}
+
+	now := time.Now()
+	claims := JWTClaims{
+		UserID:    userID,
+		Username:  username,
+		Role:      role,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(jwtSecret))
+}
+
 /* ParseToken 解析JWT令牌 */
 func ParseToken(tokenString string, jwtSecret string) (*JWTClaims, error) {
 	if jwtSecret == "" {
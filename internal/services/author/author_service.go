@@ -111,6 +111,7 @@ type FileInfo struct {
 	IsDuplicate  bool        `json:"is_duplicate"`          // 添加is_duplicate字段
 	Description  string      `json:"description,omitempty"` // 顶层描述字段（从AI信息中提取）
 	AIInfo       *FileAIInfo `json:"ai_info,omitempty"`     // AI信息
+	TakenAt      *time.Time  `json:"taken_at,omitempty"`    // 拍摄时间，时间线按此排序
 	CreatedAt    time.Time   `json:"created_at"`            // 改为created_at
 	UpdatedAt    time.Time   `json:"updated_at"`            // 添加updated_at字段
 }
@@ -277,46 +278,7 @@ func GetAuthorHomepage(authorID uint) (*AuthorHomepage, error) {
 		rootFiles = []models.File{}
 	}
 
-	fileInfos := make([]FileInfo, 0, len(rootFiles))
-	for _, file := range rootFiles {
-		fullPath, fullThumbURL, _ := storage.GetFullURLs(file)
-
-		var stats models.FileStats
-		views := 0
-		if err := db.Where("file_id = ?", file.ID).First(&stats).Error; err == nil {
-			views = int(stats.Views)
-		}
-
-		aiInfo, _ := getFileAIInfo(file.ID)
-
-		// 从AI信息中提取描述到顶层
-		description := ""
-		if aiInfo != nil && aiInfo.Description != "" {
-			description = aiInfo.Description
-		}
-
-		fileInfos = append(fileInfos, FileInfo{
-			ID:           file.ID,
-			FileName:     file.FileName,
-			OriginalName: file.OriginalName,
-			DisplayName:  file.DisplayName,
-			URL:          file.URL,
-			FullURL:      fullPath,
-			ThumbURL:     file.ThumbURL,
-			FullThumbURL: fullThumbURL,
-			Size:         file.Size,
-			Format:       file.Format,
-			Width:        file.Width,
-			Height:       file.Height,
-			Views:        views,
-			AccessLevel:  file.AccessLevel,
-			IsDuplicate:  file.IsDuplicate,
-			Description:  description,
-			AIInfo:       aiInfo,
-			CreatedAt:    time.Time(file.CreatedAt),
-			UpdatedAt:    time.Time(file.UpdatedAt),
-		})
-	}
+	fileInfos := BuildFileInfos(rootFiles)
 
 	pagination := PaginationInfo{
 		CurrentPage: page,
@@ -475,46 +437,7 @@ func GetAuthorFolder(authorID uint, folderID string, page, size int) (*FolderCon
 		return nil, errors.New(errors.CodeInternal, "获取文件列表失败")
 	}
 
-	imageInfos := make([]FileInfo, 0, len(images))
-	for _, file := range images {
-		fullPath, fullThumbURL, _ := storage.GetFullURLs(file)
-
-		var stats models.FileStats
-		views := 0
-		if err := db.Where("file_id = ?", file.ID).First(&stats).Error; err == nil {
-			views = int(stats.Views)
-		}
-
-		aiInfo, _ := getFileAIInfo(file.ID)
-
-		// 从AI信息中提取描述到顶层
-		description := ""
-		if aiInfo != nil && aiInfo.Description != "" {
-			description = aiInfo.Description
-		}
-
-		imageInfos = append(imageInfos, FileInfo{
-			ID:           file.ID,
-			FileName:     file.FileName,
-			OriginalName: file.OriginalName,
-			DisplayName:  file.DisplayName,
-			URL:          file.URL,
-			FullURL:      fullPath,
-			ThumbURL:     file.ThumbURL,
-			FullThumbURL: fullThumbURL,
-			Size:         file.Size,
-			Format:       file.Format,
-			Width:        file.Width,
-			Height:       file.Height,
-			Views:        views,
-			AccessLevel:  file.AccessLevel,
-			IsDuplicate:  file.IsDuplicate,
-			Description:  description,
-			AIInfo:       aiInfo,
-			CreatedAt:    time.Time(file.CreatedAt),
-			UpdatedAt:    time.Time(file.UpdatedAt),
-		})
-	}
+	imageInfos := BuildFileInfos(images)
 
 	pagination := PaginationInfo{
 		CurrentPage: page,
@@ -531,6 +454,56 @@ func GetAuthorFolder(authorID uint, folderID string, page, size int) (*FolderCon
 	}, nil
 }
 
+/* BuildFileInfos 将文件转换为作者主页使用的公开文件信息 */
+func BuildFileInfos(files []models.File) []FileInfo {
+	infos := make([]FileInfo, 0, len(files))
+	for _, file := range files {
+		infos = append(infos, buildFileInfo(file))
+	}
+	return infos
+}
+
+func buildFileInfo(file models.File) FileInfo {
+	fullPath, fullThumbURL, _ := storage.GetFullURLs(file)
+
+	var stats models.FileStats
+	views := 0
+	if err := database.GetDB().Where("file_id = ?", file.ID).First(&stats).Error; err == nil {
+		views = int(stats.Views)
+	}
+
+	aiInfo, _ := getFileAIInfo(file.ID)
+
+	// 从AI信息中提取描述到顶层
+	description := ""
+	if aiInfo != nil && aiInfo.Description != "" {
+		description = aiInfo.Description
+	}
+
+	return FileInfo{
+		ID:           file.ID,
+		FileName:     file.FileName,
+		OriginalName: file.OriginalName,
+		DisplayName:  file.DisplayName,
+		URL:          file.URL,
+		FullURL:      fullPath,
+		ThumbURL:     file.ThumbURL,
+		FullThumbURL: fullThumbURL,
+		Size:         file.Size,
+		Format:       file.Format,
+		Width:        file.Width,
+		Height:       file.Height,
+		Views:        views,
+		AccessLevel:  file.AccessLevel,
+		IsDuplicate:  file.IsDuplicate,
+		Description:  description,
+		AIInfo:       aiInfo,
+		TakenAt:      file.TakenAt,
+		CreatedAt:    time.Time(file.CreatedAt),
+		UpdatedAt:    time.Time(file.UpdatedAt),
+	}
+}
+
 /* getFileAIInfo 获取文件AI信息 */
 func getFileAIInfo(fileID string) (*FileAIInfo, error) {
 	db := database.GetDB()
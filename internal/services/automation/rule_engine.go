@@ -0,0 +1,561 @@
+package automation
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"pixelpunk/internal/controllers/automation/dto"
+	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/tag"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/hooks"
+	"pixelpunk/pkg/logger"
+	"pixelpunk/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+const ruleTagSource = "rule"
+
+var registerHooksOnce sync.Once
+
+// fileFacts 规则匹配所需的文件信息
+type fileFacts struct {
+	File   models.File
+	EXIF   *models.FileEXIF
+	AIInfo *models.FileAIInfo
+	AITags []string
+}
+
+// actionResult 单个动作的执行结果，写入执行日志
+type actionResult struct {
+	Type    string `json:"type"`
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+/* RegisterAutomationHooks 订阅上传完成与AI打标完成事件，异步执行用户规则 */
+func RegisterAutomationHooks() {
+	registerHooksOnce.Do(func() {
+		hooks.RegisterFileEventHook(hooks.FileEventUploaded, func(_ string, fileID string) {
+			go runRulesSafely(models.AutomationTriggerUpload, fileID)
+		})
+		hooks.RegisterFileEventHook(hooks.FileEventAITagged, func(_ string, fileID string) {
+			go runRulesSafely(models.AutomationTriggerAITagged, fileID)
+		})
+	})
+}
+
+func runRulesSafely(trigger, fileID string) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("[自动化规则] panic: %v, 文件ID: %s", r, fileID)
+		}
+	}()
+	if err := RunRules(trigger, fileID); err != nil {
+		logger.Warn("[自动化规则] 执行失败: %v, 文件ID: %s", err, fileID)
+	}
+}
+
+/* RunRules 对文件依次执行用户在指定时机启用的规则 */
+func RunRules(trigger, fileID string) error {
+	facts, err := loadFileFacts(fileID)
+	if err != nil {
+		return err
+	}
+	if facts.File.UserID == 0 {
+		return nil
+	}
+
+	var rules []models.AutomationRule
+	if err := database.DB.Where("user_id = ? AND enabled = ?", facts.File.UserID, true).
+		Order("priority ASC, id ASC").
+		Find(&rules).Error; err != nil {
+		return fmt.Errorf("查询自动化规则失败: %v", err)
+	}
+
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.HasTrigger(trigger) {
+			continue
+		}
+
+		var cond dto.RuleConditionDTO
+		var actions []dto.RuleActionDTO
+		if err := json.Unmarshal(rule.Conditions, &cond); err != nil {
+			logger.Warn("[自动化规则] 规则 %d 条件解析失败: %v", rule.ID, err)
+			continue
+		}
+		if err := json.Unmarshal(rule.Actions, &actions); err != nil {
+			logger.Warn("[自动化规则] 规则 %d 动作解析失败: %v", rule.ID, err)
+			continue
+		}
+
+		if len(matchConditions(&cond, facts)) > 0 {
+			continue
+		}
+
+		executeRule(rule, trigger, actions, facts)
+
+		if rule.StopProcessing {
+			break
+		}
+	}
+	return nil
+}
+
+/* DryRunRule 试运行规则，只返回匹配结果与将执行的动作，不修改任何数据 */
+func DryRunRule(userID uint, req *dto.DryRunRuleDTO) (*dto.DryRunResponse, error) {
+	var cond dto.RuleConditionDTO
+	var actions []dto.RuleActionDTO
+
+	switch {
+	case req.RuleID > 0:
+		rule, err := GetRule(userID, req.RuleID)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(rule.Conditions, &cond); err != nil {
+			return nil, errors.New(errors.CodeInvalidParameter, "规则条件格式错误")
+		}
+		if err := json.Unmarshal(rule.Actions, &actions); err != nil {
+			return nil, errors.New(errors.CodeInvalidParameter, "规则动作格式错误")
+		}
+	case req.Rule != nil:
+		if err := validateRuleRequest(userID, req.Rule); err != nil {
+			return nil, err
+		}
+		cond, actions = req.Rule.Conditions, req.Rule.Actions
+	default:
+		return nil, errors.New(errors.CodeInvalidParameter, "请指定规则ID或规则内容")
+	}
+
+	query := database.DB.Model(&models.File{}).Select("id").Where("user_id = ?", userID)
+	if len(req.FileIDs) > 0 {
+		query = query.Where("id IN ?", req.FileIDs)
+	} else {
+		limit := req.Limit
+		if limit <= 0 {
+			limit = 10
+		}
+		query = query.Order("created_at DESC").Limit(limit)
+	}
+
+	var fileIDs []string
+	if err := query.Pluck("id", &fileIDs).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件失败")
+	}
+
+	resp := &dto.DryRunResponse{Files: make([]dto.DryRunFileResult, 0, len(fileIDs))}
+	for _, id := range fileIDs {
+		facts, err := loadFileFacts(id)
+		if err != nil {
+			continue
+		}
+		item := dto.DryRunFileResult{
+			FileID:   facts.File.ID,
+			FileName: facts.File.OriginalName,
+			Reasons:  matchConditions(&cond, facts),
+		}
+		item.Matched = len(item.Reasons) == 0
+		if item.Matched {
+			resp.Matched++
+			for _, action := range actions {
+				item.Actions = append(item.Actions, describeAction(action))
+			}
+		}
+		resp.Files = append(resp.Files, item)
+	}
+	resp.Total = len(resp.Files)
+	return resp, nil
+}
+
+func loadFileFacts(fileID string) (*fileFacts, error) {
+	facts := &fileFacts{}
+	if err := database.DB.Where("id = ?", fileID).First(&facts.File).Error; err != nil {
+		return nil, fmt.Errorf("获取文件信息失败: %v", err)
+	}
+
+	var exif models.FileEXIF
+	if err := database.DB.Where("file_id = ?", fileID).First(&exif).Error; err == nil {
+		facts.EXIF = &exif
+	}
+
+	var aiInfo models.FileAIInfo
+	if err := database.DB.Where("file_id = ?", fileID).First(&aiInfo).Error; err == nil {
+		facts.AIInfo = &aiInfo
+	}
+
+	database.DB.Table("global_tag").
+		Joins("JOIN file_global_tag_relation ON global_tag.id = file_global_tag_relation.tag_id").
+		Where("file_global_tag_relation.file_id = ? AND file_global_tag_relation.source = ?", fileID, "ai").
+		Pluck("global_tag.name", &facts.AITags)
+
+	return facts, nil
+}
+
+// matchConditions 返回未满足的条件说明，为空表示命中
+func matchConditions(cond *dto.RuleConditionDTO, facts *fileFacts) []string {
+	var reasons []string
+	file := &facts.File
+
+	if len(cond.Formats) > 0 {
+		format := normalizeFormat(file.Format)
+		matched := false
+		for _, f := range cond.Formats {
+			if normalizeFormat(f) == format {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			reasons = append(reasons, fmt.Sprintf("格式 %s 不在范围内", file.Format))
+		}
+	}
+
+	if cond.MinSize > 0 && file.Size < cond.MinSize {
+		reasons = append(reasons, "文件小于最小大小")
+	}
+	if cond.MaxSize > 0 && file.Size > cond.MaxSize {
+		reasons = append(reasons, "文件超过最大大小")
+	}
+	if cond.MinWidth > 0 && file.Width < cond.MinWidth {
+		reasons = append(reasons, "宽度小于下限")
+	}
+	if cond.MaxWidth > 0 && file.Width > cond.MaxWidth {
+		reasons = append(reasons, "宽度超过上限")
+	}
+	if cond.MinHeight > 0 && file.Height < cond.MinHeight {
+		reasons = append(reasons, "高度小于下限")
+	}
+	if cond.MaxHeight > 0 && file.Height > cond.MaxHeight {
+		reasons = append(reasons, "高度超过上限")
+	}
+
+	if cond.CameraMake != "" || cond.CameraModel != "" {
+		if facts.EXIF == nil {
+			reasons = append(reasons, "没有EXIF相机信息")
+		} else {
+			if cond.CameraMake != "" && !containsFold(facts.EXIF.Make, cond.CameraMake) {
+				reasons = append(reasons, "相机制造商不匹配")
+			}
+			if cond.CameraModel != "" && !containsFold(facts.EXIF.Model, cond.CameraModel) {
+				reasons = append(reasons, "相机型号不匹配")
+			}
+		}
+	}
+
+	if len(cond.AITagsAny) > 0 || len(cond.AITagsAll) > 0 {
+		tagSet := make(map[string]bool, len(facts.AITags))
+		for _, t := range facts.AITags {
+			tagSet[strings.ToLower(strings.TrimSpace(t))] = true
+		}
+		if len(cond.AITagsAny) > 0 {
+			matched := false
+			for _, t := range cond.AITagsAny {
+				if tagSet[strings.ToLower(strings.TrimSpace(t))] {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				reasons = append(reasons, "不包含任一指定AI标签")
+			}
+		}
+		for _, t := range cond.AITagsAll {
+			if !tagSet[strings.ToLower(strings.TrimSpace(t))] {
+				reasons = append(reasons, fmt.Sprintf("缺少AI标签 %s", t))
+			}
+		}
+	}
+
+	if len(cond.CategoryIDs) > 0 {
+		matched := false
+		if file.CategoryID != nil {
+			for _, id := range cond.CategoryIDs {
+				if id == *file.CategoryID {
+					matched = true
+					break
+				}
+			}
+		}
+		if !matched {
+			reasons = append(reasons, "分类不匹配")
+		}
+	}
+
+	if cond.MinNSFWScore != nil || cond.MaxNSFWScore != nil {
+		if facts.AIInfo == nil {
+			reasons = append(reasons, "尚无NSFW评分")
+		} else {
+			score := facts.AIInfo.NSFWScore
+			if cond.MinNSFWScore != nil && score < *cond.MinNSFWScore {
+				reasons = append(reasons, "NSFW评分低于下限")
+			}
+			if cond.MaxNSFWScore != nil && score > *cond.MaxNSFWScore {
+				reasons = append(reasons, "NSFW评分高于上限")
+			}
+		}
+	}
+
+	if len(cond.APIKeyIDs) > 0 {
+		matched := false
+		for _, id := range cond.APIKeyIDs {
+			if file.APIKeyID != "" && id == file.APIKeyID {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			reasons = append(reasons, "不是通过指定API密钥上传")
+		}
+	}
+
+	if cond.FilenamePattern != "" && !matchFilename(cond.FilenamePattern, file.OriginalName) {
+		reasons = append(reasons, "文件名不匹配")
+	}
+
+	return reasons
+}
+
+// matchFilename 默认按通配符匹配（忽略大小写），以 re: 开头时按正则匹配
+func matchFilename(pattern, name string) bool {
+	if strings.HasPrefix(pattern, regexPatternFlag) {
+		re, err := regexp.Compile(strings.TrimPrefix(pattern, regexPatternFlag))
+		if err != nil {
+			return false
+		}
+		return re.MatchString(name)
+	}
+	matched, err := path.Match(strings.ToLower(pattern), strings.ToLower(name))
+	return err == nil && matched
+}
+
+func normalizeFormat(format string) string {
+	format = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(format), "."))
+	if format == "jpeg" {
+		return "jpg"
+	}
+	return format
+}
+
+func containsFold(value, substr string) bool {
+	return strings.Contains(strings.ToLower(value), strings.ToLower(strings.TrimSpace(substr)))
+}
+
+func executeRule(rule *models.AutomationRule, trigger string, actions []dto.RuleActionDTO, facts *fileFacts) {
+	start := time.Now()
+	results := make([]actionResult, 0, len(actions))
+	var failures []string
+
+	for _, action := range actions {
+		msg, err := executeAction(rule, trigger, action, facts)
+		result := actionResult{Type: action.Type, Success: err == nil, Message: msg}
+		if err != nil {
+			result.Message = err.Error()
+			failures = append(failures, fmt.Sprintf("%s: %v", action.Type, err))
+		}
+		results = append(results, result)
+	}
+
+	status := models.AutomationLogStatusSuccess
+	if len(failures) == len(actions) {
+		status = models.AutomationLogStatusFailed
+	} else if len(failures) > 0 {
+		status = models.AutomationLogStatusPartial
+	}
+
+	resultsJSON, _ := json.Marshal(results)
+	entry := models.AutomationRuleLog{
+		RuleID:     rule.ID,
+		UserID:     rule.UserID,
+		FileID:     facts.File.ID,
+		Trigger:    trigger,
+		Status:     status,
+		Results:    resultsJSON,
+		Error:      strings.Join(failures, "; "),
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if err := database.DB.Create(&entry).Error; err != nil {
+		logger.Warn("[自动化规则] 保存执行日志失败: %v", err)
+	}
+
+	now := common.JSONTime(time.Now())
+	database.DB.Model(&models.AutomationRule{}).Where("id = ?", rule.ID).Updates(map[string]interface{}{
+		"match_count":       gorm.Expr("match_count + 1"),
+		"last_triggered_at": now,
+	})
+}
+
+// executeAction 执行单个动作，成功后同步更新内存中的文件信息供后续规则使用
+func executeAction(rule *models.AutomationRule, trigger string, action dto.RuleActionDTO, facts *fileFacts) (string, error) {
+	file := &facts.File
+
+	switch action.Type {
+	case ActionMoveFolder:
+		if action.FolderID != "" && !userOwns(&models.Folder{}, file.UserID, action.FolderID) {
+			return "", fmt.Errorf("目标文件夹不存在")
+		}
+		if err := updateFile(file.ID, map[string]interface{}{"folder_id": action.FolderID}); err != nil {
+			return "", err
+		}
+		file.FolderID = action.FolderID
+		return "已移动到目标文件夹", nil
+
+	case ActionSetAccessLevel:
+		updates := map[string]interface{}{"access_level": action.AccessLevel}
+		if action.AccessLevel == "protected" && file.AccessKey == "" {
+			updates["access_key"] = utils.GenerateRandomString(16)
+		}
+		if err := updateFile(file.ID, updates); err != nil {
+			return "", err
+		}
+		file.AccessLevel = action.AccessLevel
+		return "访问级别已设为 " + action.AccessLevel, nil
+
+	case ActionAddTags:
+		return addTags(file, normalizeTags(action.Tags))
+
+	case ActionSetCategory:
+		if !userOwns(&models.FileCategory{}, file.UserID, action.CategoryID) {
+			return "", fmt.Errorf("目标分类不存在")
+		}
+		if err := updateFile(file.ID, map[string]interface{}{
+			"category_id":     action.CategoryID,
+			"category_source": ruleTagSource,
+		}); err != nil {
+			return "", err
+		}
+		categoryID := action.CategoryID
+		file.CategoryID = &categoryID
+		return "已设置分类", nil
+
+	case ActionSetExpiry:
+		updates := map[string]interface{}{"expiry_notification_sent": false}
+		if action.ExpireDays > 0 {
+			expiresAt := time.Now().AddDate(0, 0, action.ExpireDays)
+			updates["expires_at"] = expiresAt
+			updates["storage_duration"] = fmt.Sprintf("%dd", action.ExpireDays)
+		} else {
+			updates["expires_at"] = nil
+			updates["storage_duration"] = "permanent"
+		}
+		if err := updateFile(file.ID, updates); err != nil {
+			return "", err
+		}
+		if action.ExpireDays > 0 {
+			return fmt.Sprintf("%d天后过期", action.ExpireDays), nil
+		}
+		return "已设为永久保存", nil
+
+	case ActionAddToShare:
+		return addToShare(file, action.ShareID)
+
+	case ActionWebhook:
+		if err := sendWebhook(rule, trigger, file, action.WebhookURL, action.WebhookSecret); err != nil {
+			return "", err
+		}
+		return "Webhook已送达", nil
+	}
+
+	return "", fmt.Errorf("不支持的动作类型: %s", action.Type)
+}
+
+func updateFile(fileID string, updates map[string]interface{}) error {
+	if err := database.DB.Model(&models.File{}).Where("id = ?", fileID).Updates(updates).Error; err != nil {
+		return fmt.Errorf("更新文件失败: %v", err)
+	}
+	return nil
+}
+
+func addTags(file *models.File, names []string) (string, error) {
+	if len(names) == 0 {
+		return "没有需要添加的标签", nil
+	}
+
+	globalTagService := tag.NewGlobalTagService()
+	var tagIDs []uint
+	for _, name := range names {
+		t, err := globalTagService.CreateOrGetGlobalTag(name, "", file.UserID, false)
+		if err != nil {
+			logger.Warn("[自动化规则] 创建标签失败 [%s]: %v", name, err)
+			continue
+		}
+		if err := globalTagService.AddUserTagReference(file.UserID, t.ID, ruleTagSource); err != nil {
+			logger.Warn("[自动化规则] 添加用户标签引用失败 [%s]: %v", name, err)
+		}
+		tagIDs = append(tagIDs, t.ID)
+	}
+	if len(tagIDs) == 0 {
+		return "", fmt.Errorf("标签创建失败")
+	}
+
+	if err := tag.NewFileGlobalTagService().AddTagsToFile(file.ID, tagIDs, ruleTagSource, 1.0); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("已添加%d个标签", len(tagIDs)), nil
+}
+
+func addToShare(file *models.File, shareID string) (string, error) {
+	var share models.Share
+	if err := database.DB.Where("id = ? AND user_id = ?", shareID, file.UserID).First(&share).Error; err != nil {
+		return "", fmt.Errorf("目标分享不存在")
+	}
+
+	var count int64
+	database.DB.Model(&models.ShareItem{}).
+		Where("share_id = ? AND item_type = ? AND item_id = ?", share.ID, common.ShareItemTypeFile, file.ID).
+		Count(&count)
+	if count > 0 {
+		return "文件已在分享中", nil
+	}
+
+	var maxSort struct{ Max int }
+	database.DB.Model(&models.ShareItem{}).Select("COALESCE(MAX(sort_order), -1) AS max").
+		Where("share_id = ?", share.ID).Scan(&maxSort)
+
+	item := models.ShareItem{
+		ID:        utils.GenerateFileID(),
+		ShareID:   share.ID,
+		ItemType:  common.ShareItemTypeFile,
+		ItemID:    file.ID,
+		SortOrder: maxSort.Max + 1,
+	}
+	if err := database.DB.Create(&item).Error; err != nil {
+		return "", fmt.Errorf("添加到分享失败: %v", err)
+	}
+	return "已添加到分享 " + share.Name, nil
+}
+
+func describeAction(action dto.RuleActionDTO) string {
+	switch action.Type {
+	case ActionMoveFolder:
+		if action.FolderID == "" {
+			return "移动到根目录"
+		}
+		return "移动到文件夹 " + action.FolderID
+	case ActionSetAccessLevel:
+		return "设置访问级别为 " + action.AccessLevel
+	case ActionAddTags:
+		return "添加标签 " + strings.Join(normalizeTags(action.Tags), "、")
+	case ActionSetCategory:
+		return fmt.Sprintf("设置分类 %d", action.CategoryID)
+	case ActionSetExpiry:
+		if action.ExpireDays > 0 {
+			return fmt.Sprintf("设置%d天后过期", action.ExpireDays)
+		}
+		return "设为永久保存"
+	case ActionAddToShare:
+		return "添加到分享 " + action.ShareID
+	case ActionWebhook:
+		return "推送Webhook " + action.WebhookURL
+	}
+	return action.Type
+}
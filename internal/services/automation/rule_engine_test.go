@@ -0,0 +1,77 @@
+package automation
+
+import (
+	"net"
+	"testing"
+
+	"pixelpunk/internal/controllers/automation/dto"
+	"pixelpunk/internal/models"
+)
+
+func TestMatchConditions(t *testing.T) {
+	categoryID := uint(3)
+	minScore := 0.6
+	facts := &fileFacts{
+		File: models.File{
+			OriginalName: "IMG_2024.JPEG",
+			Format:       "jpeg",
+			Size:         2 << 20,
+			Width:        4000,
+			Height:       3000,
+			CategoryID:   &categoryID,
+			APIKeyID:     "key1",
+		},
+		EXIF:   &models.FileEXIF{Make: "Canon", Model: "EOS R5"},
+		AIInfo: &models.FileAIInfo{NSFWScore: 0.2},
+		AITags: []string{"Cat", "outdoor"},
+	}
+
+	matched := &dto.RuleConditionDTO{
+		Formats:         []string{"jpg", "png"},
+		MinSize:         1 << 20,
+		MinWidth:        1920,
+		CameraMake:      "canon",
+		CameraModel:     "r5",
+		AITagsAny:       []string{"dog", "cat"},
+		AITagsAll:       []string{"outdoor"},
+		CategoryIDs:     []uint{1, 3},
+		APIKeyIDs:       []string{"key1"},
+		FilenamePattern: "img_*",
+	}
+	if reasons := matchConditions(matched, facts); len(reasons) != 0 {
+		t.Fatalf("expected match, got %v", reasons)
+	}
+
+	unmatched := &dto.RuleConditionDTO{
+		MaxHeight:       1080,
+		AITagsAll:       []string{"cat", "night"},
+		MinNSFWScore:    &minScore,
+		FilenamePattern: `re:^\d+\.png$`,
+	}
+	if reasons := matchConditions(unmatched, facts); len(reasons) != 4 {
+		t.Fatalf("expected 4 unmet conditions, got %v", reasons)
+	}
+
+	if reasons := matchConditions(&dto.RuleConditionDTO{CameraMake: "canon"}, &fileFacts{}); len(reasons) != 1 {
+		t.Fatalf("missing EXIF should not match, got %v", reasons)
+	}
+}
+
+func TestIsInternalIP(t *testing.T) {
+	cases := map[string]bool{
+		"127.0.0.1":       true,
+		"10.1.2.3":        true,
+		"169.254.169.254": true,
+		"100.64.0.1":      true,
+		"0.0.0.0":         true,
+		"::1":             true,
+		"fd00::1":         true,
+		"8.8.8.8":         false,
+		"2606:4700::1111": false,
+	}
+	for addr, want := range cases {
+		if got := isInternalIP(net.ParseIP(addr)); got != want {
+			t.Errorf("isInternalIP(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
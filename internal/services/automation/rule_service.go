@@ -0,0 +1,279 @@
+package automation
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"pixelpunk/internal/controllers/automation/dto"
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+)
+
+const (
+	maxRulesPerUser  = 50
+	maxRuleLogDays   = 30
+	regexPatternFlag = "re:"
+)
+
+const (
+	ActionMoveFolder     = "move_folder"
+	ActionSetAccessLevel = "set_access_level"
+	ActionAddTags        = "add_tags"
+	ActionSetCategory    = "set_category"
+	ActionSetExpiry      = "set_expiry"
+	ActionAddToShare     = "add_to_share"
+	ActionWebhook        = "webhook"
+)
+
+/* RuleLogList 规则执行日志分页结果 */
+type RuleLogList struct {
+	Items []models.AutomationRuleLog `json:"items"`
+	Total int64                      `json:"total"`
+	Page  int                        `json:"page"`
+	Limit int                        `json:"limit"`
+}
+
+/* ListRules 获取用户的全部自动化规则，按执行顺序排列 */
+func ListRules(userID uint) ([]models.AutomationRule, error) {
+	var rules []models.AutomationRule
+	if err := database.DB.Where("user_id = ?", userID).
+		Order("priority ASC, id ASC").
+		Find(&rules).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询自动化规则失败")
+	}
+	return rules, nil
+}
+
+/* GetRule 获取单条规则 */
+func GetRule(userID, ruleID uint) (*models.AutomationRule, error) {
+	var rule models.AutomationRule
+	if err := database.DB.Where("id = ? AND user_id = ?", ruleID, userID).First(&rule).Error; err != nil {
+		return nil, errors.New(errors.CodeNotFound, "规则不存在")
+	}
+	return &rule, nil
+}
+
+/* CreateRule 创建自动化规则 */
+func CreateRule(userID uint, req *dto.SaveRuleDTO) (*models.AutomationRule, error) {
+	var count int64
+	database.DB.Model(&models.AutomationRule{}).Where("user_id = ?", userID).Count(&count)
+	if count >= maxRulesPerUser {
+		return nil, errors.New(errors.CodeInvalidRequest, fmt.Sprintf("每个用户最多创建%d条规则", maxRulesPerUser))
+	}
+
+	rule := &models.AutomationRule{UserID: userID, Enabled: true}
+	if err := applyRuleRequest(userID, rule, req); err != nil {
+		return nil, err
+	}
+	if err := database.DB.Create(rule).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBCreateFailed, "创建规则失败")
+	}
+	return rule, nil
+}
+
+/* UpdateRule 更新自动化规则 */
+func UpdateRule(userID, ruleID uint, req *dto.SaveRuleDTO) (*models.AutomationRule, error) {
+	rule, err := GetRule(userID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyRuleRequest(userID, rule, req); err != nil {
+		return nil, err
+	}
+	if err := database.DB.Save(rule).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBUpdateFailed, "更新规则失败")
+	}
+	return rule, nil
+}
+
+/* SetRuleEnabled 启用或停用规则 */
+func SetRuleEnabled(userID, ruleID uint, enabled bool) error {
+	result := database.DB.Model(&models.AutomationRule{}).
+		Where("id = ? AND user_id = ?", ruleID, userID).
+		Update("enabled", enabled)
+	if result.Error != nil {
+		return errors.Wrap(result.Error, errors.CodeDBUpdateFailed, "更新规则状态失败")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(errors.CodeNotFound, "规则不存在")
+	}
+	return nil
+}
+
+/* DeleteRule 删除规则及其执行日志 */
+func DeleteRule(userID, ruleID uint) error {
+	rule, err := GetRule(userID, ruleID)
+	if err != nil {
+		return err
+	}
+	if err := database.DB.Where("rule_id = ?", rule.ID).Delete(&models.AutomationRuleLog{}).Error; err != nil {
+		return errors.Wrap(err, errors.CodeDBDeleteFailed, "删除规则日志失败")
+	}
+	if err := database.DB.Delete(rule).Error; err != nil {
+		return errors.Wrap(err, errors.CodeDBDeleteFailed, "删除规则失败")
+	}
+	return nil
+}
+
+/* ListRuleLogs 分页查询规则执行日志 */
+func ListRuleLogs(userID, ruleID uint, query *dto.RuleLogQuery) (*RuleLogList, error) {
+	if _, err := GetRule(userID, ruleID); err != nil {
+		return nil, err
+	}
+
+	page, limit := query.Page, query.Limit
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+
+	db := database.DB.Model(&models.AutomationRuleLog{}).Where("rule_id = ? AND user_id = ?", ruleID, userID)
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询规则日志失败")
+	}
+
+	var logs []models.AutomationRuleLog
+	if err := db.Order("id DESC").Offset((page - 1) * limit).Limit(limit).Find(&logs).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询规则日志失败")
+	}
+
+	return &RuleLogList{Items: logs, Total: total, Page: page, Limit: limit}, nil
+}
+
+/* CleanupRuleLogs 清理超过保留天数的规则执行日志 */
+func CleanupRuleLogs(days int) (int64, error) {
+	if days <= 0 {
+		days = maxRuleLogDays
+	}
+	cutoff := time.Now().AddDate(0, 0, -days)
+	result := database.DB.Where("created_at < ?", cutoff).Delete(&models.AutomationRuleLog{})
+	return result.RowsAffected, result.Error
+}
+
+func applyRuleRequest(userID uint, rule *models.AutomationRule, req *dto.SaveRuleDTO) error {
+	if err := validateRuleRequest(userID, req); err != nil {
+		return err
+	}
+
+	conditions, err := json.Marshal(req.Conditions)
+	if err != nil {
+		return errors.Wrap(err, errors.CodeInvalidParameter, "规则条件格式错误")
+	}
+	actions, err := json.Marshal(req.Actions)
+	if err != nil {
+		return errors.Wrap(err, errors.CodeInvalidParameter, "规则动作格式错误")
+	}
+
+	rule.Name = strings.TrimSpace(req.Name)
+	rule.Description = req.Description
+	rule.Priority = req.Priority
+	rule.Triggers = strings.Join(uniqueStrings(req.Triggers), ",")
+	rule.Conditions = conditions
+	rule.Actions = actions
+	rule.StopProcessing = req.StopProcessing
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	return nil
+}
+
+// validateRuleRequest 校验条件取值与动作引用的资源是否属于当前用户
+func validateRuleRequest(userID uint, req *dto.SaveRuleDTO) error {
+	cond := req.Conditions
+	if cond.MinSize > 0 && cond.MaxSize > 0 && cond.MinSize > cond.MaxSize {
+		return errors.New(errors.CodeInvalidParameter, "最小文件大小不能大于最大文件大小")
+	}
+	if cond.MinWidth > 0 && cond.MaxWidth > 0 && cond.MinWidth > cond.MaxWidth {
+		return errors.New(errors.CodeInvalidParameter, "最小宽度不能大于最大宽度")
+	}
+	if cond.MinHeight > 0 && cond.MaxHeight > 0 && cond.MinHeight > cond.MaxHeight {
+		return errors.New(errors.CodeInvalidParameter, "最小高度不能大于最大高度")
+	}
+	if cond.MinNSFWScore != nil && cond.MaxNSFWScore != nil && *cond.MinNSFWScore > *cond.MaxNSFWScore {
+		return errors.New(errors.CodeInvalidParameter, "NSFW评分下限不能大于上限")
+	}
+	if strings.HasPrefix(cond.FilenamePattern, regexPatternFlag) {
+		if _, err := regexp.Compile(strings.TrimPrefix(cond.FilenamePattern, regexPatternFlag)); err != nil {
+			return errors.New(errors.CodeInvalidParameter, "文件名正则表达式无效")
+		}
+	}
+	for _, id := range cond.CategoryIDs {
+		if !userOwns(&models.FileCategory{}, userID, id) {
+			return errors.New(errors.CodeInvalidParameter, "条件中的分类不存在")
+		}
+	}
+
+	for _, action := range req.Actions {
+		switch action.Type {
+		case ActionMoveFolder:
+			if action.FolderID != "" && !userOwns(&models.Folder{}, userID, action.FolderID) {
+				return errors.New(errors.CodeFolderNotFound, "目标文件夹不存在")
+			}
+		case ActionSetAccessLevel:
+			if action.AccessLevel == "" {
+				return errors.New(errors.CodeInvalidParameter, "请选择访问级别")
+			}
+		case ActionAddTags:
+			if len(normalizeTags(action.Tags)) == 0 {
+				return errors.New(errors.CodeInvalidParameter, "请填写要添加的标签")
+			}
+		case ActionSetCategory:
+			if action.CategoryID == 0 || !userOwns(&models.FileCategory{}, userID, action.CategoryID) {
+				return errors.New(errors.CodeInvalidParameter, "目标分类不存在")
+			}
+		case ActionAddToShare:
+			if action.ShareID == "" || !userOwns(&models.Share{}, userID, action.ShareID) {
+				return errors.New(errors.CodeInvalidParameter, "目标分享不存在")
+			}
+		case ActionWebhook:
+			if action.WebhookURL == "" {
+				return errors.New(errors.CodeInvalidParameter, "请填写Webhook地址")
+			}
+			if err := validateWebhookURL(action.WebhookURL); err != nil {
+				return errors.New(errors.CodeInvalidParameter, err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+func userOwns(model interface{}, userID uint, id interface{}) bool {
+	var count int64
+	database.DB.Model(model).Where("id = ? AND user_id = ?", id, userID).Count(&count)
+	return count > 0
+}
+
+func normalizeTags(tags []string) []string {
+	result := make([]string, 0, len(tags))
+	for _, t := range uniqueStrings(tags) {
+		if len([]rune(t)) <= 50 {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		result = append(result, v)
+	}
+	return result
+}
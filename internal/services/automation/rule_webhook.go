@@ -0,0 +1,150 @@
+package automation
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/urlfetch"
+)
+
+const webhookTimeout = 10 * time.Second
+
+// webhookClient 在建立连接时校验实际解析到的地址，防止通过DNS重绑定访问内网；不跟随重定向
+var webhookClient = &http.Client{
+	Timeout: webhookTimeout,
+	Transport: &http.Transport{
+		Proxy: nil,
+		DialContext: (&net.Dialer{
+			Timeout: 5 * time.Second,
+			Control: urlfetch.DenyInternalAddress,
+		}).DialContext,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ResponseHeaderTimeout: webhookTimeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+func isInternalIP(ip net.IP) bool {
+	return urlfetch.IsInternalIP(ip)
+}
+
+// validateWebhookURL 保存规则时的静态校验，连接时还会再次校验解析结果
+func validateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("Webhook地址格式不正确")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("Webhook地址仅支持http或https")
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		return fmt.Errorf("Webhook地址缺少主机名")
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") || strings.HasSuffix(host, ".local") || strings.HasSuffix(host, ".internal") {
+		return fmt.Errorf("Webhook地址不能指向内网")
+	}
+	if ip := net.ParseIP(host); ip != nil && isInternalIP(ip) {
+		return fmt.Errorf("Webhook地址不能指向内网")
+	}
+	return nil
+}
+
+type webhookPayload struct {
+	Event     string      `json:"event"`
+	Trigger   string      `json:"trigger"`
+	Rule      webhookRule `json:"rule"`
+	File      webhookFile `json:"file"`
+	Timestamp int64       `json:"timestamp"`
+}
+
+type webhookRule struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+type webhookFile struct {
+	ID           string `json:"id"`
+	OriginalName string `json:"original_name"`
+	DisplayName  string `json:"display_name"`
+	Format       string `json:"format"`
+	Size         int64  `json:"size"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	FolderID     string `json:"folder_id"`
+	AccessLevel  string `json:"access_level"`
+	URL          string `json:"url"`
+}
+
+// sendWebhook 推送规则命中事件，配置密钥时附带 HMAC-SHA256 签名
+func sendWebhook(rule *models.AutomationRule, trigger string, file *models.File, targetURL, secret string) error {
+	if err := validateWebhookURL(targetURL); err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(webhookPayload{
+		Event:   "automation.rule_matched",
+		Trigger: trigger,
+		Rule:    webhookRule{ID: rule.ID, Name: rule.Name},
+		File: webhookFile{
+			ID:           file.ID,
+			OriginalName: file.OriginalName,
+			DisplayName:  file.DisplayName,
+			Format:       file.Format,
+			Size:         file.Size,
+			Width:        file.Width,
+			Height:       file.Height,
+			FolderID:     file.FolderID,
+			AccessLevel:  file.AccessLevel,
+			URL:          file.URL,
+		},
+		Timestamp: time.Now().Unix(),
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, targetURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("创建Webhook请求失败: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "PixelPunk-Webhook/1.0")
+	req.Header.Set("X-PixelPunk-Event", "automation.rule_matched")
+	if secret != "" {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		req.Header.Set("X-PixelPunk-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Webhook请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("Webhook返回状态码 %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,466 @@
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/setting"
+	"pixelpunk/pkg/config"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/logger"
+	"pixelpunk/pkg/vector"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const archiveExt = ".tar.gz"
+
+// appVersion 写入备份描述信息的程序版本，启动时设置
+var appVersion = "unknown"
+
+// configPaths 与 config.loadConfigFromFile 的查找顺序一致
+var configPaths = []string{"configs/config.yaml", "config.yaml"}
+
+// Options 备份选项
+type Options struct {
+	Output          string // 备份文件路径，不以 .tar.gz 结尾时视为目录并自动命名，为空时使用配置的备份目录
+	IncrementalFrom string // 增量备份基于的上一个备份文件，为空时做全量备份
+	SkipVector      bool   // 不备份向量索引
+	SkipFiles       bool   // 不备份本地存储文件，只备份数据库和配置
+}
+
+// Result 备份结果
+type Result struct {
+	Path     string
+	Size     int64
+	Manifest *Manifest
+}
+
+/* SetAppVersion 设置写入备份的程序版本 */
+func SetAppVersion(version string) {
+	appVersion = version
+}
+
+/* Create 生成一个备份：数据库在同一个读事务中导出，保证各表数据一致；增量备份只包含相对上一个备份有变化的存储文件 */
+func Create(opts Options) (*Result, error) {
+	if database.DB == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	now := time.Now()
+	m := &Manifest{
+		FormatVersion: FormatVersion,
+		ID:            strings.ReplaceAll(uuid.New().String(), "-", ""),
+		AppVersion:    appVersion,
+		CreatedAt:     now,
+		DBType:        config.GetConfig().Database.Type,
+	}
+	m.ChainID = m.ID
+
+	var baseIndex map[string]indexRecord
+	if opts.IncrementalFrom != "" {
+		if opts.SkipFiles {
+			return nil, fmt.Errorf("增量备份针对存储文件，不能同时跳过存储文件")
+		}
+		base, index, err := readManifestAndIndex(opts.IncrementalFrom)
+		if err != nil {
+			return nil, fmt.Errorf("读取上一个备份失败: %w", err)
+		}
+		if base.FormatVersion != FormatVersion {
+			return nil, fmt.Errorf("上一个备份的格式版本为 %d，请重新做全量备份", base.FormatVersion)
+		}
+		m.BaseID, m.ChainID, m.Seq = base.ID, base.ChainID, base.Seq+1
+		baseIndex = index
+	}
+
+	output, err := outputPath(opts.Output, m)
+	if err != nil {
+		return nil, err
+	}
+	staging, err := os.MkdirTemp(filepath.Dir(output), ".backup-")
+	if err != nil {
+		return nil, fmt.Errorf("创建临时目录失败: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	if err := dumpDatabase(m, staging); err != nil {
+		return nil, err
+	}
+
+	if !opts.SkipVector {
+		included, err := snapshotVector(filepath.Join(staging, "vector.snapshot"))
+		if err != nil {
+			return nil, fmt.Errorf("备份向量索引失败（可跳过向量索引，恢复后重新生成向量）: %w", err)
+		}
+		m.Vector = included
+	}
+
+	m.Channels, err = localChannels()
+	if err != nil {
+		return nil, err
+	}
+	var files []pendingFile
+	if !opts.SkipFiles {
+		files, err = buildIndex(m, baseIndex, filepath.Join(staging, "index.jsonl"))
+		if err != nil {
+			return nil, err
+		}
+	} else if err := os.WriteFile(filepath.Join(staging, "index.jsonl"), nil, 0600); err != nil {
+		return nil, err
+	}
+
+	configPath := findConfigFile()
+	m.Config = configPath != ""
+
+	partial := output + ".partial"
+	if err := writeArchive(partial, m, staging, configPath, files); err != nil {
+		os.Remove(partial)
+		return nil, err
+	}
+	if err := os.Rename(partial, output); err != nil {
+		os.Remove(partial)
+		return nil, fmt.Errorf("保存备份文件失败: %w", err)
+	}
+
+	info, err := os.Stat(output)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("备份完成: %s, 数据表 %d 个, 存储文件 %d/%d 个, 耗时 %s",
+		output, len(m.Tables), m.Files.Included, m.Files.Total, time.Since(now).Round(time.Second))
+	return &Result{Path: output, Size: info.Size(), Manifest: m}, nil
+}
+
+// outputPath 确定备份文件路径，不以 .tar.gz 结尾时视为目录，文件名包含时间和备份类型
+func outputPath(output string, m *Manifest) (string, error) {
+	if output == "" {
+		output = config.GetConfig().Backup.Dir
+	}
+	if !strings.HasSuffix(output, archiveExt) {
+		kind := "full"
+		if m.IsIncremental() {
+			kind = "incr"
+		}
+		output = filepath.Join(output, fmt.Sprintf("pixelpunk-backup-%s-%s%s", m.CreatedAt.Format("20060102-150405"), kind, archiveExt))
+	}
+	if err := os.MkdirAll(filepath.Dir(output), 0700); err != nil {
+		return "", fmt.Errorf("创建备份目录失败: %w", err)
+	}
+	if _, err := os.Stat(output); err == nil {
+		return "", fmt.Errorf("备份文件已存在: %s", output)
+	}
+	return output, nil
+}
+
+func findConfigFile() string {
+	for _, path := range configPaths {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// dumpDatabase 在一个只读事务中导出全部数据表，每个表一个 JSONL 文件
+// 使用可重复读隔离级别，各表读到同一时刻的快照，备份期间的写入不会造成表之间数据不一致
+func dumpDatabase(m *Manifest, dir string) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		tables, err := tx.Migrator().GetTables()
+		if err != nil {
+			return fmt.Errorf("读取数据表失败: %w", err)
+		}
+		sort.Strings(tables)
+		for _, table := range tables {
+			if strings.HasPrefix(table, "sqlite_") {
+				continue
+			}
+			info, err := dumpTable(tx, table, filepath.Join(dir, table+".jsonl"))
+			if err != nil {
+				return fmt.Errorf("导出数据表 %s 失败: %w", table, err)
+			}
+			m.Tables = append(m.Tables, *info)
+			if table == "migration_versions" {
+				if err := tx.Table(table).Order("id").Pluck("name", &m.Migrations).Error; err != nil {
+					return fmt.Errorf("读取数据迁移记录失败: %w", err)
+				}
+			}
+		}
+		return nil
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+}
+
+func dumpTable(tx *gorm.DB, table, path string) (*TableInfo, error) {
+	rows, err := tx.Table(table).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+
+	info := &TableInfo{Name: table, Columns: columns}
+	values := make([]interface{}, len(columns))
+	ptrs := make([]interface{}, len(columns))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	row := make([]interface{}, len(columns))
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		for i, v := range values {
+			row[i] = encodeValue(v)
+		}
+		if err := enc.Encode(row); err != nil {
+			return nil, err
+		}
+		info.Rows++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+	return info, f.Close()
+}
+
+// snapshotVector 为向量集合创建快照并下载，向量功能未启用时返回 false
+func snapshotVector(path string) (bool, error) {
+	client := qdrantClient()
+	if client == nil {
+		return false, nil
+	}
+	name, err := client.CreateSnapshot()
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err := client.DeleteSnapshot(name); err != nil {
+			logger.Warn("删除 Qdrant 上的临时快照失败: %v", err)
+		}
+	}()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	if err := client.DownloadSnapshot(name, f); err != nil {
+		return false, err
+	}
+	return true, f.Close()
+}
+
+// qdrantClient 按系统设置创建 Qdrant 客户端，向量功能未启用时返回 nil
+func qdrantClient() *vector.QdrantClient {
+	if !setting.GetBoolDirectFromDB("vector", "vector_enabled", false) {
+		return nil
+	}
+	qdrantURL := setting.GetStringDirectFromDB("vector", "qdrant_url", "")
+	if qdrantURL == "" {
+		return nil
+	}
+	return vector.NewQdrantClient(strings.TrimSuffix(qdrantURL, "/"), setting.GetIntDirectFromDB("vector", "qdrant_timeout", 30))
+}
+
+// localChannels 读取本地存储渠道的文件目录，未配置时使用本地存储的默认目录
+func localChannels() ([]ChannelDirs, error) {
+	var channels []models.StorageChannel
+	if err := database.DB.Where("type = ?", "local").Order("id").Find(&channels).Error; err != nil {
+		return nil, fmt.Errorf("读取存储渠道失败: %w", err)
+	}
+	result := make([]ChannelDirs, 0, len(channels))
+	for _, channel := range channels {
+		dirs := ChannelDirs{ID: channel.ID, FilesDir: "uploads/files", ThumbnailsDir: "uploads/thumbnails"}
+		var items []models.StorageConfigItem
+		if err := database.DB.Where("channel_id = ? AND key_name IN ?", channel.ID, []string{"base_path", "thumbnail_path"}).
+			Find(&items).Error; err != nil {
+			return nil, fmt.Errorf("读取存储渠道配置失败: %w", err)
+		}
+		for _, item := range items {
+			if item.Value == "" {
+				continue
+			}
+			if item.KeyName == "base_path" {
+				dirs.FilesDir = item.Value
+			} else {
+				dirs.ThumbnailsDir = item.Value
+			}
+		}
+		result = append(result, dirs)
+	}
+	return result, nil
+}
+
+// pendingFile 需要写入归档的存储文件
+type pendingFile struct {
+	name   string // 归档中的路径
+	source string // 磁盘上的路径
+}
+
+// buildIndex 遍历本地存储目录，写出全部文件的索引，返回需要写入归档的文件（全量备份为全部文件）
+func buildIndex(m *Manifest, baseIndex map[string]indexRecord, indexPath string) ([]pendingFile, error) {
+	f, err := os.Create(indexPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+
+	var files []pendingFile
+	for _, channel := range m.Channels {
+		for kind, dir := range map[string]string{"files": channel.FilesDir, "thumbnails": channel.ThumbnailsDir} {
+			err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+				if err != nil {
+					if os.IsNotExist(err) && path == dir {
+						return filepath.SkipDir
+					}
+					return err
+				}
+				if !d.Type().IsRegular() {
+					return nil
+				}
+				info, err := d.Info()
+				if err != nil {
+					return err
+				}
+				rel, err := filepath.Rel(dir, path)
+				if err != nil {
+					return err
+				}
+				rec := indexRecord{Path: storagePath(channel.ID, kind, rel), Size: info.Size(), MTime: info.ModTime().UnixNano()}
+				if err := enc.Encode(rec); err != nil {
+					return err
+				}
+				m.Files.Total++
+				m.Files.TotalSize += rec.Size
+				if baseIndex == nil || rec.changed(baseIndex) {
+					files = append(files, pendingFile{name: rec.Path, source: path})
+					m.Files.Included++
+					m.Files.IncludedSize += rec.Size
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, fmt.Errorf("读取存储目录 %s 失败: %w", dir, err)
+			}
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].name < files[j].name })
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+	return files, f.Close()
+}
+
+// writeArchive 按固定顺序写出归档：描述信息、文件索引、配置、数据表、向量快照、存储文件
+func writeArchive(path string, m *Manifest, staging, configPath string, files []pendingFile) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("创建备份文件失败: %w", err)
+	}
+	defer f.Close()
+	bw := bufio.NewWriterSize(f, 1<<20)
+	gz := gzip.NewWriter(bw)
+	tw := tar.NewWriter(gz)
+
+	manifest, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := addBytes(tw, manifestEntry, manifest, m.CreatedAt); err != nil {
+		return err
+	}
+	if err := addFile(tw, indexEntry, filepath.Join(staging, "index.jsonl")); err != nil {
+		return err
+	}
+	if configPath != "" {
+		if err := addFile(tw, configEntry, configPath); err != nil {
+			return err
+		}
+	}
+	for _, table := range m.Tables {
+		if err := addFile(tw, dbPrefix+table.Name+".jsonl", filepath.Join(staging, table.Name+".jsonl")); err != nil {
+			return err
+		}
+	}
+	if m.Vector {
+		if err := addFile(tw, vectorEntry, filepath.Join(staging, "vector.snapshot")); err != nil {
+			return err
+		}
+	}
+	for _, file := range files {
+		if err := addFile(tw, file.name, file.source); err != nil {
+			if os.IsNotExist(err) {
+				// 文件在建立索引后被删除，恢复时会记为缺失
+				logger.Warn("备份时文件已不存在，跳过: %s", file.source)
+				continue
+			}
+			return fmt.Errorf("写入文件 %s 失败: %w", file.source, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func addBytes(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	hdr := &tar.Header{Name: name, Mode: 0600, Size: int64(len(data)), ModTime: modTime, Format: tar.FormatPAX}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// addFile 写入一个文件，修改时间保留到纳秒，恢复后的文件与索引一致，之后的增量备份不会重复包含
+func addFile(tw *tar.Writer, name, source string) error {
+	f, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	hdr := &tar.Header{Name: name, Mode: 0600, Size: info.Size(), ModTime: info.ModTime(), Format: tar.FormatPAX}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err = io.CopyN(tw, f, info.Size())
+	return err
+}
@@ -0,0 +1,329 @@
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FormatVersion 备份归档格式版本，格式不兼容地变化时递增
+const FormatVersion = 1
+
+// 归档内的固定路径，backup.json 和 storage.index.jsonl 始终是前两个条目，恢复前只需读取开头即可校验
+const (
+	manifestEntry = "backup.json"
+	indexEntry    = "storage.index.jsonl"
+	configEntry   = "config/config.yaml"
+	dbPrefix      = "db/"
+	vectorEntry   = "vector/file_vectors.snapshot"
+	storagePrefix = "storage/"
+)
+
+// Manifest 备份描述信息，写在归档的第一个条目 backup.json 中
+type Manifest struct {
+	FormatVersion int       `json:"format_version"`
+	ID            string    `json:"id"`
+	ChainID       string    `json:"chain_id"`          // 所属备份链，即链上全量备份的 ID
+	BaseID        string    `json:"base_id,omitempty"` // 增量备份的上一个备份，全量备份为空
+	Seq           int       `json:"seq"`               // 在备份链中的序号，全量备份为 0
+	AppVersion    string    `json:"app_version"`
+	CreatedAt     time.Time `json:"created_at"`
+
+	DBType     string      `json:"db_type"`
+	Migrations []string    `json:"migrations"` // 备份时已执行的数据迁移
+	Tables     []TableInfo `json:"tables"`
+
+	Config   bool          `json:"config"` // 是否包含配置文件
+	Vector   bool          `json:"vector"` // 是否包含向量索引快照
+	Channels []ChannelDirs `json:"channels"`
+	Files    FileStats     `json:"files"`
+}
+
+// TableInfo 数据表的列和行数，恢复时据此校验当前数据库结构
+type TableInfo struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+	Rows    int64    `json:"rows"`
+}
+
+// ChannelDirs 本地存储渠道的文件目录
+type ChannelDirs struct {
+	ID            string `json:"id"`
+	FilesDir      string `json:"files_dir"`
+	ThumbnailsDir string `json:"thumbnails_dir"`
+}
+
+// FileStats 存储文件统计，Total 为当前全部文件，Included 为本次归档实际包含的文件
+type FileStats struct {
+	Total        int   `json:"total"`
+	TotalSize    int64 `json:"total_size"`
+	Included     int   `json:"included"`
+	IncludedSize int64 `json:"included_size"`
+}
+
+// IsIncremental 是否为增量备份
+func (m *Manifest) IsIncremental() bool {
+	return m.BaseID != ""
+}
+
+// indexRecord 存储文件索引中的一行，记录备份时的全部文件，增量备份据此判断文件是否变化
+type indexRecord struct {
+	Path  string `json:"path"`
+	Size  int64  `json:"size"`
+	MTime int64  `json:"mtime"` // UnixNano
+}
+
+// changed 判断文件相对上一次备份是否有变化
+func (r indexRecord) changed(prev map[string]indexRecord) bool {
+	old, ok := prev[r.Path]
+	return !ok || old.Size != r.Size || old.MTime != r.MTime
+}
+
+// archiveReader 顺序读取 tar.gz 归档
+type archiveReader struct {
+	file *os.File
+	gz   *gzip.Reader
+	*tar.Reader
+}
+
+func openArchive(path string) (*archiveReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	gz, err := gzip.NewReader(bufio.NewReader(f))
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s 不是有效的备份文件: %w", path, err)
+	}
+	return &archiveReader{file: f, gz: gz, Reader: tar.NewReader(gz)}, nil
+}
+
+func (a *archiveReader) Close() error {
+	a.gz.Close()
+	return a.file.Close()
+}
+
+// readManifest 读取归档开头的 backup.json
+func (a *archiveReader) readManifest() (*Manifest, error) {
+	hdr, err := a.Next()
+	if err != nil || hdr.Name != manifestEntry {
+		return nil, fmt.Errorf("备份文件缺少 %s", manifestEntry)
+	}
+	var m Manifest
+	if err := json.NewDecoder(a).Decode(&m); err != nil {
+		return nil, fmt.Errorf("解析 %s 失败: %w", manifestEntry, err)
+	}
+	return &m, nil
+}
+
+// readIndex 读取紧跟在 backup.json 之后的存储文件索引
+func (a *archiveReader) readIndex() (map[string]indexRecord, error) {
+	hdr, err := a.Next()
+	if err != nil || hdr.Name != indexEntry {
+		return nil, fmt.Errorf("备份文件缺少 %s", indexEntry)
+	}
+	index := make(map[string]indexRecord)
+	dec := json.NewDecoder(a)
+	for dec.More() {
+		var rec indexRecord
+		if err := dec.Decode(&rec); err != nil {
+			return nil, fmt.Errorf("解析 %s 失败: %w", indexEntry, err)
+		}
+		index[rec.Path] = rec
+	}
+	return index, nil
+}
+
+// ReadManifest 读取备份文件的描述信息
+func ReadManifest(path string) (*Manifest, error) {
+	a, err := openArchive(path)
+	if err != nil {
+		return nil, err
+	}
+	defer a.Close()
+	return a.readManifest()
+}
+
+// readManifestAndIndex 读取备份文件的描述信息和存储文件索引
+func readManifestAndIndex(path string) (*Manifest, map[string]indexRecord, error) {
+	a, err := openArchive(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer a.Close()
+	m, err := a.readManifest()
+	if err != nil {
+		return nil, nil, err
+	}
+	index, err := a.readIndex()
+	if err != nil {
+		return nil, nil, err
+	}
+	return m, index, nil
+}
+
+// Archive 一个备份文件及其描述信息
+type Archive struct {
+	Path     string
+	Manifest *Manifest
+}
+
+// scanArchives 读取目录中全部备份文件的描述信息，按创建时间升序，无法识别的文件被忽略
+func scanArchives(dir string) ([]Archive, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var archives []Archive
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), archiveExt) {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		m, err := ReadManifest(path)
+		if err != nil {
+			continue
+		}
+		archives = append(archives, Archive{Path: path, Manifest: m})
+	}
+	sort.Slice(archives, func(i, j int) bool {
+		return archives[i].Manifest.CreatedAt.Before(archives[j].Manifest.CreatedAt)
+	})
+	return archives, nil
+}
+
+// ResolveChain 把待恢复的备份文件补全为完整的备份链：只给出增量备份时，从同一目录中查找它依赖的备份
+func ResolveChain(paths []string) ([]Archive, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("未指定备份文件")
+	}
+	chain := make([]Archive, 0, len(paths))
+	for _, path := range paths {
+		m, err := ReadManifest(path)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, Archive{Path: path, Manifest: m})
+	}
+
+	if chain[0].Manifest.IsIncremental() {
+		candidates, err := scanArchives(filepath.Dir(chain[0].Path))
+		if err != nil {
+			return nil, err
+		}
+		byID := make(map[string]Archive, len(candidates))
+		for _, c := range candidates {
+			byID[c.Manifest.ID] = c
+		}
+		for chain[0].Manifest.IsIncremental() {
+			base, ok := byID[chain[0].Manifest.BaseID]
+			if !ok {
+				return nil, fmt.Errorf("找不到 %s 依赖的备份 %s，请一并指定", filepath.Base(chain[0].Path), chain[0].Manifest.BaseID)
+			}
+			chain = append([]Archive{base}, chain...)
+		}
+	}
+
+	manifests := make([]*Manifest, len(chain))
+	for i, a := range chain {
+		manifests[i] = a.Manifest
+	}
+	if err := validateChain(manifests); err != nil {
+		return nil, err
+	}
+	return chain, nil
+}
+
+// validateChain 校验备份链：第一个为全量备份，之后每个增量备份都基于前一个
+func validateChain(chain []*Manifest) error {
+	if len(chain) == 0 {
+		return fmt.Errorf("未指定备份文件")
+	}
+	for i, m := range chain {
+		if m.FormatVersion < 1 || m.FormatVersion > FormatVersion {
+			return fmt.Errorf("备份 %s 的格式版本为 %d，当前程序支持的版本为 %d", m.ID, m.FormatVersion, FormatVersion)
+		}
+		if i == 0 {
+			if m.IsIncremental() {
+				return fmt.Errorf("备份链的第一个备份 %s 是增量备份，缺少它依赖的备份 %s", m.ID, m.BaseID)
+			}
+			continue
+		}
+		if m.BaseID != chain[i-1].ID {
+			return fmt.Errorf("备份 %s 不是基于 %s 的增量备份", m.ID, chain[i-1].ID)
+		}
+	}
+	return nil
+}
+
+// checkMigrations 校验备份的数据迁移版本：备份中存在当前程序不认识的迁移，说明备份来自更新的版本
+func checkMigrations(applied, known []string) error {
+	knownSet := make(map[string]bool, len(known))
+	for _, name := range known {
+		knownSet[name] = true
+	}
+	var unknown []string
+	for _, name := range applied {
+		if !knownSet[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("备份来自更新版本的程序，包含当前版本不支持的数据迁移: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// checkColumns 校验备份的数据表结构：备份中的每一列都必须存在于当前数据库
+func checkColumns(tables []TableInfo, current map[string][]string) error {
+	var problems []string
+	for _, table := range tables {
+		columns, ok := current[table.Name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("数据表 %s 不存在", table.Name))
+			continue
+		}
+		columnSet := make(map[string]bool, len(columns))
+		for _, c := range columns {
+			columnSet[strings.ToLower(c)] = true
+		}
+		for _, c := range table.Columns {
+			if !columnSet[strings.ToLower(c)] {
+				problems = append(problems, fmt.Sprintf("数据表 %s 缺少列 %s", table.Name, c))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("备份与当前数据库结构不一致: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// storagePath 归档中存储文件的路径 storage/<渠道ID>/<files|thumbnails>/<相对路径>
+func storagePath(channelID, kind, rel string) string {
+	return storagePrefix + channelID + "/" + kind + "/" + filepath.ToSlash(rel)
+}
+
+// splitStoragePath 拆分归档中的存储文件路径，相对路径不能跳出渠道目录
+func splitStoragePath(name string) (channelID, kind, rel string, ok bool) {
+	parts := strings.SplitN(strings.TrimPrefix(name, storagePrefix), "/", 3)
+	if len(parts) != 3 || parts[0] == "" || (parts[1] != "files" && parts[1] != "thumbnails") {
+		return "", "", "", false
+	}
+	rel = filepath.Clean(filepath.FromSlash(parts[2]))
+	if rel == "." || filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", "", "", false
+	}
+	return parts[0], parts[1], rel, true
+}
@@ -0,0 +1,119 @@
+package backup
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestValidateChain(t *testing.T) {
+	full := &Manifest{FormatVersion: 1, ID: "a", ChainID: "a"}
+	incr1 := &Manifest{FormatVersion: 1, ID: "b", ChainID: "a", BaseID: "a", Seq: 1}
+	incr2 := &Manifest{FormatVersion: 1, ID: "c", ChainID: "a", BaseID: "b", Seq: 2}
+
+	if err := validateChain([]*Manifest{full, incr1, incr2}); err != nil {
+		t.Errorf("完整的备份链校验失败: %v", err)
+	}
+	cases := map[string][]*Manifest{
+		"缺少全量备份": {incr1, incr2},
+		"跳过中间备份": {full, incr2},
+		"顺序错误":   {full, incr2, incr1},
+		"格式版本过新": {{FormatVersion: FormatVersion + 1, ID: "x"}},
+		"空链":     {},
+	}
+	for name, chain := range cases {
+		if err := validateChain(chain); err == nil {
+			t.Errorf("%s: 应校验失败", name)
+		}
+	}
+}
+
+func TestCheckMigrationsAndColumns(t *testing.T) {
+	known := []string{"m1", "m2", "m3"}
+	if err := checkMigrations([]string{"m1", "m2"}, known); err != nil {
+		t.Errorf("较早版本的备份应允许恢复: %v", err)
+	}
+	if err := checkMigrations([]string{"m1", "m4"}, known); err == nil {
+		t.Error("包含未知迁移的备份应拒绝恢复")
+	}
+
+	current := map[string][]string{"user": {"id", "username", "bio"}}
+	if err := checkColumns([]TableInfo{{Name: "user", Columns: []string{"id", "USERNAME"}}}, current); err != nil {
+		t.Errorf("备份的列是当前结构的子集时应允许恢复: %v", err)
+	}
+	if err := checkColumns([]TableInfo{{Name: "user", Columns: []string{"id", "nickname"}}}, current); err == nil {
+		t.Error("当前结构缺少备份中的列时应拒绝恢复")
+	}
+	if err := checkColumns([]TableInfo{{Name: "album", Columns: []string{"id"}}}, current); err == nil {
+		t.Error("当前结构缺少备份中的表时应拒绝恢复")
+	}
+}
+
+func TestSplitStoragePath(t *testing.T) {
+	name := storagePath("ch1", "files", filepath.Join("2026", "a.jpg"))
+	channelID, kind, rel, ok := splitStoragePath(name)
+	if !ok || channelID != "ch1" || kind != "files" || rel != filepath.Join("2026", "a.jpg") {
+		t.Errorf("splitStoragePath(%q) = %q %q %q %v", name, channelID, kind, rel, ok)
+	}
+	for _, bad := range []string{
+		"storage/ch1/files/../../etc/passwd",
+		"storage/ch1/other/a.jpg",
+		"storage/ch1/files",
+		"storage//files/a.jpg",
+	} {
+		if _, _, _, ok := splitStoragePath(bad); ok {
+			t.Errorf("splitStoragePath(%q) 应判定为无效路径", bad)
+		}
+	}
+}
+
+func TestPruneArchives(t *testing.T) {
+	var archives []Archive
+	for i, chain := range []string{"a", "a", "b", "b", "b", "c"} {
+		archives = append(archives, Archive{
+			Path:     string(rune('0' + i)),
+			Manifest: &Manifest{ChainID: chain},
+		})
+	}
+	var removed []string
+	n, err := pruneArchives(archives, 2, func(path string) error {
+		removed = append(removed, path)
+		return nil
+	})
+	if err != nil || n != 2 || len(removed) != 2 || removed[0] != "0" || removed[1] != "1" {
+		t.Errorf("只应删除最早一条链的备份，实际删除 %v", removed)
+	}
+}
+
+func TestRowRoundTrip(t *testing.T) {
+	created := time.Date(2026, 10, 1, 10, 0, 0, 123456000, time.FixedZone("CST", 8*3600))
+	row := []interface{}{int64(9007199254740993), 1.5, "中文 <tag>", []byte("text"), []byte{0xff, 0x00}, created, nil, true}
+
+	encoded := make([]interface{}, len(row))
+	for i, v := range row {
+		encoded[i] = encodeValue(v)
+	}
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(encoded); err != nil {
+		t.Fatal(err)
+	}
+	got, err := decodeRow(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got[0] != int64(9007199254740993) || got[1] != 1.5 || got[2] != "中文 <tag>" || got[3] != "text" {
+		t.Errorf("数字或字符串未正确还原: %v", got[:4])
+	}
+	if b, ok := got[4].([]byte); !ok || !bytes.Equal(b, []byte{0xff, 0x00}) {
+		t.Errorf("二进制数据未正确还原: %v", got[4])
+	}
+	if ts, ok := got[5].(time.Time); !ok || !ts.Equal(created) {
+		t.Errorf("时间未正确还原: %v", got[5])
+	}
+	if got[6] != nil || got[7] != true {
+		t.Errorf("空值或布尔值未正确还原: %v", got[6:])
+	}
+}
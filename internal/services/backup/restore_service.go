@@ -0,0 +1,410 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"pixelpunk/migrations"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// restoreBatchSize 每次插入的行数，SQLite 单条语句的参数个数有上限
+const restoreBatchSize = 100
+
+// RestoreOptions 恢复选项
+type RestoreOptions struct {
+	SkipVector bool // 不恢复向量索引
+	SkipFiles  bool // 不恢复本地存储文件
+}
+
+// RestoreResult 恢复结果
+type RestoreResult struct {
+	Tables       int
+	Rows         int64
+	Files        int
+	MissingFiles int      // 索引中有、但备份链中找不到的文件
+	Vector       bool     // 是否恢复了向量索引
+	Warnings     []string // 不影响数据库恢复的问题，如向量索引恢复失败
+}
+
+/* PrepareRestore 解析备份链并校验：格式版本、链的完整性、备份的数据迁移版本和数据表结构都必须与当前程序兼容 */
+func PrepareRestore(paths []string) ([]Archive, error) {
+	chain, err := ResolveChain(paths)
+	if err != nil {
+		return nil, err
+	}
+	final := chain[len(chain)-1].Manifest
+	if err := checkMigrations(final.Migrations, migrations.GetAllMigrationNames()); err != nil {
+		return nil, err
+	}
+	current, err := currentColumns()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkColumns(final.Tables, current); err != nil {
+		return nil, err
+	}
+	return chain, nil
+}
+
+/* ExtractConfig 把备份链最后一个备份中的配置文件写到 dest，备份不含配置文件时返回 false */
+func ExtractConfig(chain []Archive, dest string) (bool, error) {
+	final := chain[len(chain)-1]
+	if !final.Manifest.Config {
+		return false, nil
+	}
+	a, err := openArchive(final.Path)
+	if err != nil {
+		return false, err
+	}
+	defer a.Close()
+	for {
+		hdr, err := a.Next()
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if hdr.Name != configEntry {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return false, err
+		}
+		data, err := io.ReadAll(a)
+		if err != nil {
+			return false, err
+		}
+		return true, os.WriteFile(dest, data, 0600)
+	}
+}
+
+/* Restore 按备份链恢复：数据库取自最后一个备份，在一个事务中清空并重新写入全部数据表；存储文件按最后一个备份的索引，从后往前取每个文件的最新版本 */
+func Restore(chain []Archive, opts RestoreOptions) (*RestoreResult, error) {
+	final := chain[len(chain)-1]
+	a, err := openArchive(final.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer a.Close()
+	m, err := a.readManifest()
+	if err != nil {
+		return nil, err
+	}
+	index, err := a.readIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	tables := make(map[string]TableInfo, len(m.Tables))
+	for _, t := range m.Tables {
+		tables[t.Name] = t
+	}
+	result := &RestoreResult{}
+	restorer := &fileRestorer{index: index, done: make(map[string]bool), fallback: m.Channels}
+
+	var tx *gorm.DB
+	defer func() {
+		if tx != nil {
+			tx.Rollback()
+		}
+	}()
+	commit := func() error {
+		if err := database.ResetSequences(tx); err != nil {
+			return err
+		}
+		if err := tx.Commit().Error; err != nil {
+			return fmt.Errorf("提交数据库恢复失败: %w", err)
+		}
+		tx = nil
+		logger.Info("数据库已恢复: 数据表 %d 个, %d 行", result.Tables, result.Rows)
+		return nil
+	}
+
+	for {
+		hdr, err := a.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("读取备份文件失败: %w", err)
+		}
+
+		if strings.HasPrefix(hdr.Name, dbPrefix) {
+			if tx == nil {
+				if tx, err = beginRestore(); err != nil {
+					return nil, err
+				}
+			}
+			table := strings.TrimSuffix(strings.TrimPrefix(hdr.Name, dbPrefix), ".jsonl")
+			info, ok := tables[table]
+			if !ok {
+				return nil, fmt.Errorf("备份描述信息中没有数据表 %s", table)
+			}
+			if err := restoreTable(tx, info, a); err != nil {
+				return nil, fmt.Errorf("恢复数据表 %s 失败: %w", table, err)
+			}
+			result.Tables++
+			result.Rows += info.Rows
+			continue
+		}
+		if tx != nil {
+			if err := commit(); err != nil {
+				return nil, err
+			}
+		}
+
+		switch {
+		case hdr.Name == vectorEntry && !opts.SkipVector:
+			if err := restoreVector(a); err != nil {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("恢复向量索引失败，请在后台重新生成向量: %v", err))
+			} else {
+				result.Vector = true
+			}
+		case strings.HasPrefix(hdr.Name, storagePrefix) && !opts.SkipFiles:
+			if err := restorer.extract(hdr.Name, hdr.ModTime, a); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if tx != nil {
+		if err := commit(); err != nil {
+			return nil, err
+		}
+	}
+	if result.Tables != len(m.Tables) {
+		return nil, fmt.Errorf("备份文件不完整：应有 %d 个数据表，实际 %d 个", len(m.Tables), result.Tables)
+	}
+	if m.Vector && !opts.SkipVector && !result.Vector && len(result.Warnings) == 0 {
+		result.Warnings = append(result.Warnings, "向量功能未启用，未恢复向量索引")
+	}
+
+	if !opts.SkipFiles {
+		// 增量备份只包含变化的文件，其余文件从之前的备份中找
+		for i := len(chain) - 2; i >= 0 && len(restorer.done) < len(index); i-- {
+			if err := restorer.extractArchive(chain[i].Path); err != nil {
+				return nil, err
+			}
+		}
+		result.Files = len(restorer.done)
+		result.MissingFiles = len(index) - len(restorer.done)
+	}
+	return result, nil
+}
+
+// currentColumns 读取当前数据库的全部数据表和列
+func currentColumns() (map[string][]string, error) {
+	if database.DB == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+	// 迁移记录表在启动时才创建，全新安装的数据库上可能还没有
+	if err := migrations.EnsureMigrationTable(database.DB); err != nil {
+		return nil, fmt.Errorf("创建数据迁移记录表失败: %w", err)
+	}
+	tables, err := database.DB.Migrator().GetTables()
+	if err != nil {
+		return nil, fmt.Errorf("读取数据表失败: %w", err)
+	}
+	result := make(map[string][]string, len(tables))
+	for _, table := range tables {
+		columnTypes, err := database.DB.Migrator().ColumnTypes(table)
+		if err != nil {
+			return nil, fmt.Errorf("读取数据表 %s 的结构失败: %w", table, err)
+		}
+		columns := make([]string, len(columnTypes))
+		for i, c := range columnTypes {
+			columns[i] = c.Name()
+		}
+		result[table] = columns
+	}
+	return result, nil
+}
+
+// beginRestore 开启恢复事务并清空当前全部数据表，包括备份中没有的表，避免残留与恢复的数据不一致的记录
+func beginRestore() (*gorm.DB, error) {
+	tables, err := database.DB.Migrator().GetTables()
+	if err != nil {
+		return nil, fmt.Errorf("读取数据表失败: %w", err)
+	}
+	tx := database.DB.Begin()
+	if tx.Error != nil {
+		return nil, fmt.Errorf("开启事务失败: %w", tx.Error)
+	}
+	for _, table := range tables {
+		if strings.HasPrefix(table, "sqlite_") {
+			continue
+		}
+		if err := tx.Exec("DELETE FROM ?", clause.Table{Name: table}).Error; err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("清空数据表 %s 失败: %w", table, err)
+		}
+	}
+	return tx, nil
+}
+
+// restoreTable 分批写入一个数据表，写入的行数必须与备份描述信息一致
+func restoreTable(tx *gorm.DB, info TableInfo, r io.Reader) error {
+	reader := bufio.NewReaderSize(r, 1<<20)
+	batch := make([]map[string]interface{}, 0, restoreBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := tx.Table(info.Name).Create(&batch).Error; err != nil {
+			return err
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	var count int64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			values, decodeErr := decodeRow(line)
+			if decodeErr != nil {
+				return fmt.Errorf("第 %d 行解析失败: %w", count+1, decodeErr)
+			}
+			if len(values) != len(info.Columns) {
+				return fmt.Errorf("第 %d 行的列数与备份描述信息不一致", count+1)
+			}
+			row := make(map[string]interface{}, len(values))
+			for i, column := range info.Columns {
+				row[column] = values[i]
+			}
+			batch = append(batch, row)
+			count++
+			if len(batch) == restoreBatchSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	if count != info.Rows {
+		return fmt.Errorf("行数不一致：备份描述信息为 %d，实际为 %d", info.Rows, count)
+	}
+	return nil
+}
+
+// restoreVector 用快照替换向量集合，Qdrant 地址取自刚恢复的系统设置
+func restoreVector(r io.Reader) error {
+	client := qdrantClient()
+	if client == nil {
+		return nil
+	}
+	return client.RestoreSnapshot(r)
+}
+
+// fileRestorer 把归档中的存储文件写回本地存储渠道的目录
+type fileRestorer struct {
+	index    map[string]indexRecord // 最后一个备份的索引，即要恢复的全部文件
+	done     map[string]bool
+	dirs     map[string]ChannelDirs
+	fallback []ChannelDirs // 备份时记录的渠道目录，恢复的数据库中找不到渠道时使用
+}
+
+func (r *fileRestorer) extractArchive(path string) error {
+	a, err := openArchive(path)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+	for {
+		hdr, err := a.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("读取备份文件 %s 失败: %w", filepath.Base(path), err)
+		}
+		if strings.HasPrefix(hdr.Name, storagePrefix) {
+			if err := r.extract(hdr.Name, hdr.ModTime, a); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// extract 写出一个存储文件；已被之后的备份覆盖、或最终已删除的文件跳过
+func (r *fileRestorer) extract(name string, modTime time.Time, src io.Reader) error {
+	if r.done[name] {
+		return nil
+	}
+	if _, ok := r.index[name]; !ok {
+		return nil
+	}
+	channelID, kind, rel, ok := splitStoragePath(name)
+	if !ok {
+		return fmt.Errorf("备份中的文件路径无效: %s", name)
+	}
+	dirs, err := r.channelDirs(channelID)
+	if err != nil {
+		return err
+	}
+	base := dirs.FilesDir
+	if kind == "thumbnails" {
+		base = dirs.ThumbnailsDir
+	}
+
+	dest := filepath.Join(base, rel)
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+	f, err := os.Create(dest)
+	if err != nil {
+		return fmt.Errorf("写入文件 %s 失败: %w", dest, err)
+	}
+	if _, err := io.Copy(f, src); err != nil {
+		f.Close()
+		return fmt.Errorf("写入文件 %s 失败: %w", dest, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("写入文件 %s 失败: %w", dest, err)
+	}
+	os.Chtimes(dest, modTime, modTime)
+	r.done[name] = true
+	return nil
+}
+
+// channelDirs 渠道目录取自恢复后的数据库，第一次使用时读取
+func (r *fileRestorer) channelDirs(channelID string) (ChannelDirs, error) {
+	if r.dirs == nil {
+		channels, err := localChannels()
+		if err != nil {
+			return ChannelDirs{}, err
+		}
+		r.dirs = make(map[string]ChannelDirs, len(channels))
+		for _, c := range r.fallback {
+			r.dirs[c.ID] = c
+		}
+		for _, c := range channels {
+			r.dirs[c.ID] = c
+		}
+	}
+	dirs, ok := r.dirs[channelID]
+	if !ok {
+		return ChannelDirs{}, fmt.Errorf("找不到存储渠道 %s 的目录", channelID)
+	}
+	return dirs, nil
+}
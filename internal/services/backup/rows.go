@@ -0,0 +1,75 @@
+package backup
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+	"unicode/utf8"
+)
+
+// 数据表每行保存为与 TableInfo.Columns 对齐的 JSON 数组。JSON 无法区分的类型用单键对象包装：
+// 时间为 {"$t": RFC3339Nano}，不是合法 UTF-8 的二进制数据为 {"$b": base64}
+const (
+	timeKey  = "$t"
+	bytesKey = "$b"
+)
+
+// encodeValue 把数据库驱动返回的值转换为可写入 JSON 的值
+func encodeValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case nil:
+		return nil
+	case time.Time:
+		return map[string]string{timeKey: val.Format(time.RFC3339Nano)}
+	case []byte:
+		if utf8.Valid(val) {
+			return string(val)
+		}
+		return map[string]string{bytesKey: base64.StdEncoding.EncodeToString(val)}
+	default:
+		return val
+	}
+}
+
+// decodeRow 解析一行数据，整数保持精度，包装过的时间和二进制数据还原为原类型
+func decodeRow(line []byte) ([]interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	var raw []interface{}
+	if err := dec.Decode(&raw); err != nil {
+		return nil, err
+	}
+	for i, v := range raw {
+		val, err := decodeValue(v)
+		if err != nil {
+			return nil, err
+		}
+		raw[i] = val
+	}
+	return raw, nil
+}
+
+func decodeValue(v interface{}) (interface{}, error) {
+	switch val := v.(type) {
+	case json.Number:
+		if n, err := val.Int64(); err == nil {
+			return n, nil
+		}
+		return val.Float64()
+	case map[string]interface{}:
+		if len(val) != 1 {
+			return nil, fmt.Errorf("无法识别的值: %v", val)
+		}
+		if s, ok := val[timeKey].(string); ok {
+			return time.Parse(time.RFC3339Nano, s)
+		}
+		if s, ok := val[bytesKey].(string); ok {
+			return base64.StdEncoding.DecodeString(s)
+		}
+		return nil, fmt.Errorf("无法识别的值: %v", val)
+	default:
+		return val, nil
+	}
+}
@@ -0,0 +1,89 @@
+package backup
+
+import (
+	"fmt"
+	"os"
+
+	"pixelpunk/pkg/config"
+	"pixelpunk/pkg/logger"
+)
+
+// scheduleRunning 定时备份耗时可能超过调度间隔，同一时间只运行一个
+var scheduleRunning = make(chan struct{}, 1)
+
+/* RunScheduled 按配置执行一次定时备份：最近的备份链未满时做增量备份，否则做全量备份，完成后清理旧的备份链 */
+func RunScheduled() (*Result, error) {
+	select {
+	case scheduleRunning <- struct{}{}:
+		defer func() { <-scheduleRunning }()
+	default:
+		return nil, fmt.Errorf("上一次定时备份仍在进行")
+	}
+
+	cfg := config.GetConfig().Backup
+	opts := Options{Output: cfg.Dir, SkipVector: cfg.SkipVector}
+	if cfg.Incremental {
+		archives, err := scanArchives(cfg.Dir)
+		if err != nil {
+			return nil, fmt.Errorf("读取备份目录失败: %w", err)
+		}
+		if n := len(archives); n > 0 && archives[n-1].Manifest.Seq < cfg.FullEvery &&
+			archives[n-1].Manifest.FormatVersion == FormatVersion {
+			opts.IncrementalFrom = archives[n-1].Path
+		}
+	}
+
+	result, err := Create(opts)
+	if err != nil {
+		return nil, err
+	}
+	if removed, err := Prune(cfg.Dir, cfg.Keep); err != nil {
+		logger.Warn("清理旧备份失败: %v", err)
+	} else if removed > 0 {
+		logger.Info("清理旧备份: %d 个文件", removed)
+	}
+	return result, nil
+}
+
+/* Prune 只保留目录中最近的 keep 条备份链，删除更早的链上的全部备份，返回删除的文件数；keep 不大于 0 时不清理 */
+func Prune(dir string, keep int) (int, error) {
+	if keep <= 0 {
+		return 0, nil
+	}
+	archives, err := scanArchives(dir)
+	if err != nil {
+		return 0, err
+	}
+	return pruneArchives(archives, keep, os.Remove)
+}
+
+// pruneArchives 按链的创建顺序保留最近的 keep 条链，archives 按创建时间升序
+func pruneArchives(archives []Archive, keep int, remove func(string) error) (int, error) {
+	var chains []string
+	seen := make(map[string]bool)
+	for _, a := range archives {
+		if !seen[a.Manifest.ChainID] {
+			seen[a.Manifest.ChainID] = true
+			chains = append(chains, a.Manifest.ChainID)
+		}
+	}
+	if len(chains) <= keep {
+		return 0, nil
+	}
+	expired := make(map[string]bool)
+	for _, chainID := range chains[:len(chains)-keep] {
+		expired[chainID] = true
+	}
+
+	removed := 0
+	for _, a := range archives {
+		if !expired[a.Manifest.ChainID] {
+			continue
+		}
+		if err := remove(a.Path); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
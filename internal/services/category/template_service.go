@@ -265,7 +265,7 @@ func (s *TemplateService) ListTemplates(query TemplateListQuery) (*TemplateListR
 
 	if query.Keyword != "" {
 		keyword := "%" + query.Keyword + "%"
-		db = db.Where("name LIKE ? OR description LIKE ?", keyword, keyword)
+		db = db.Where(database.ILike("name LIKE ? OR description LIKE ?"), keyword, keyword)
 	}
 
 	if query.IsPopular != nil {
@@ -0,0 +1,403 @@
+package dataexport
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"pixelpunk/internal/models"
+	filesvc "pixelpunk/internal/services/file"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+const (
+	manifestVersion = 1
+	exportBatchSize = 200
+	filesRoot       = "files"
+)
+
+// archiveResult 打包结果
+type archiveResult struct {
+	FileCount    int
+	MissingCount int
+}
+
+// exportFile 清单中的文件条目，不包含存储路径等内部字段
+type exportFile struct {
+	ID           string     `json:"id"`
+	ArchivePath  string     `json:"archive_path,omitempty"` // 压缩包中的路径，读取失败时为空
+	Missing      bool       `json:"missing,omitempty"`
+	OriginalName string     `json:"original_name"`
+	DisplayName  string     `json:"display_name,omitempty"`
+	FolderID     string     `json:"folder_id,omitempty"`
+	FolderPath   string     `json:"folder_path"`
+	Size         int64      `json:"size"`
+	MD5Hash      string     `json:"md5_hash"`
+	Format       string     `json:"format"`
+	MimeType     string     `json:"mime_type"`
+	FileType     string     `json:"file_type"`
+	Width        int        `json:"width"`
+	Height       int        `json:"height"`
+	Description  string     `json:"description"`
+	AccessLevel  string     `json:"access_level"`
+	ShortURL     string     `json:"short_url"`
+	NSFW         bool       `json:"nsfw"`
+	CreatedAt    time.Time  `json:"created_at"`
+	TakenAt      *time.Time `json:"taken_at,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+
+	AIInfo     *models.FileAIInfo `json:"ai_info,omitempty"`
+	Tags       []exportTag        `json:"tags"`
+	Categories []exportCategory   `json:"categories"`
+	EXIF       *models.FileEXIF   `json:"exif,omitempty"`
+}
+
+type exportTag struct {
+	Name       string  `json:"name"`
+	Source     string  `json:"source"`
+	Confidence float64 `json:"confidence"`
+}
+
+type exportCategory struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+}
+
+type exportShare struct {
+	models.Share
+	Items []models.ShareItem `json:"items"`
+}
+
+type exportAlbum struct {
+	models.Album
+	FileIDs []string `json:"file_ids"`
+}
+
+// writeArchive 将用户的原始文件按文件夹结构写入 files/ 目录，数据清单写入 manifest.json
+// 存储中读取失败的文件只在清单中标记为 missing，不会导致整个导出失败
+func writeArchive(userID uint, archivePath string) (*archiveResult, error) {
+	var user models.User
+	if err := database.DB.Where("id = ?", userID).First(&user).Error; err != nil {
+		return nil, errors.New(errors.CodeUserNotFound, "用户不存在")
+	}
+
+	out, err := os.Create(archivePath)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeInternal, "创建导出文件失败")
+	}
+	defer out.Close()
+	zw := zip.NewWriter(out)
+
+	folders, err := loadFolderPaths(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 先写原始文件并收集文件条目，清单最后写入
+	result := &archiveResult{}
+	var files []exportFile
+	names := newNameSet()
+	var batch []models.File
+	err = database.DB.Where("user_id = ? AND status <> ?", userID, filesvc.StatusPendingDeletion).
+		Order("id ASC").FindInBatches(&batch, exportBatchSize, func(tx *gorm.DB, _ int) error {
+		entries, err := buildFileEntries(batch, folders)
+		if err != nil {
+			return err
+		}
+		for i := range entries {
+			name := names.unique(path.Join(filesRoot, entries[i].FolderPath, safeName(entries[i].OriginalName, entries[i].ID)))
+			if err := copyOriginal(zw, &batch[i], name); err != nil {
+				logger.Warn("导出时读取文件失败: file_id=%s, %v", batch[i].ID, err)
+				entries[i].Missing = true
+				result.MissingCount++
+			} else {
+				entries[i].ArchivePath = name
+				result.FileCount++
+			}
+		}
+		files = append(files, entries...)
+		return nil
+	}).Error
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "读取文件数据失败")
+	}
+
+	manifest, err := buildManifest(&user, folders, files)
+	if err != nil {
+		return nil, err
+	}
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: "manifest.json", Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeInternal, "写入数据清单失败")
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(manifest); err != nil {
+		return nil, errors.Wrap(err, errors.CodeInternal, "写入数据清单失败")
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, errors.Wrap(err, errors.CodeInternal, "写入导出文件失败")
+	}
+	return result, nil
+}
+
+// buildManifest 汇总文件以外的账号数据，密码、密钥值、分享密码等字段在模型中已不参与序列化
+func buildManifest(user *models.User, folders map[string]string, files []exportFile) (map[string]interface{}, error) {
+	userID := user.ID
+	db := database.DB
+
+	var folderList []models.Folder
+	var albums []models.Album
+	var shares []models.Share
+	var apiKeys []models.APIKey
+	var activities []models.ActivityLog
+	var messages []models.Message
+	queries := []struct {
+		dest  interface{}
+		query *gorm.DB
+	}{
+		{&folderList, db.Where("user_id = ?", userID).Order("created_at ASC")},
+		{&albums, db.Where("user_id = ?", userID).Order("created_at ASC")},
+		{&shares, db.Where("user_id = ?", userID).Order("created_at ASC")},
+		{&apiKeys, db.Where("user_id = ?", userID).Order("created_at ASC")},
+		{&activities, db.Where("user_id = ?", userID).Order("id ASC")},
+		{&messages, db.Where("user_id = ?", userID).Order("created_at ASC")},
+	}
+	for _, q := range queries {
+		if err := q.query.Find(q.dest).Error; err != nil {
+			return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "读取账号数据失败")
+		}
+	}
+
+	exportAlbums := make([]exportAlbum, 0, len(albums))
+	for _, a := range albums {
+		var fileIDs []string
+		if err := db.Model(&models.AlbumItem{}).Where("album_id = ?", a.ID).Order("sort_order ASC, id ASC").Pluck("file_id", &fileIDs).Error; err != nil {
+			return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "读取相册数据失败")
+		}
+		exportAlbums = append(exportAlbums, exportAlbum{Album: a, FileIDs: fileIDs})
+	}
+
+	exportShares := make([]exportShare, 0, len(shares))
+	for _, s := range shares {
+		var items []models.ShareItem
+		if err := db.Where("share_id = ?", s.ID).Order("sort_order ASC").Find(&items).Error; err != nil {
+			return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "读取分享数据失败")
+		}
+		exportShares = append(exportShares, exportShare{Share: s, Items: items})
+	}
+
+	type exportFolder struct {
+		models.Folder
+		Path string `json:"path"`
+	}
+	exportFolders := make([]exportFolder, 0, len(folderList))
+	for _, f := range folderList {
+		exportFolders = append(exportFolders, exportFolder{Folder: f, Path: folders[f.ID]})
+	}
+
+	if files == nil {
+		files = []exportFile{}
+	}
+	return map[string]interface{}{
+		"version":     manifestVersion,
+		"exported_at": time.Now().Format(time.RFC3339),
+		"user":        user,
+		"folders":     exportFolders,
+		"albums":      exportAlbums,
+		"files":       files,
+		"shares":      exportShares,
+		"api_keys":    apiKeys,
+		"activities":  activities,
+		"messages":    messages,
+	}, nil
+}
+
+// buildFileEntries 批量查询文件的 AI 信息、标签、分类和 EXIF
+func buildFileEntries(files []models.File, folders map[string]string) ([]exportFile, error) {
+	ids := make([]string, len(files))
+	for i, f := range files {
+		ids[i] = f.ID
+	}
+
+	var aiInfos []models.FileAIInfo
+	var exifs []models.FileEXIF
+	var tagRelations []models.FileGlobalTagRelation
+	var categoryRelations []models.FileCategoryRelation
+	if err := database.DB.Where("file_id IN ?", ids).Find(&aiInfos).Error; err != nil {
+		return nil, err
+	}
+	if err := database.DB.Where("file_id IN ?", ids).Find(&exifs).Error; err != nil {
+		return nil, err
+	}
+	if err := database.DB.Preload("Tag").Where("file_id IN ?", ids).Find(&tagRelations).Error; err != nil {
+		return nil, err
+	}
+	if err := database.DB.Preload("Category").Where("file_id IN ?", ids).Find(&categoryRelations).Error; err != nil {
+		return nil, err
+	}
+
+	aiByFile := map[string]*models.FileAIInfo{}
+	for i := range aiInfos {
+		aiByFile[aiInfos[i].FileID] = &aiInfos[i]
+	}
+	exifByFile := map[string]*models.FileEXIF{}
+	for i := range exifs {
+		exifByFile[exifs[i].FileID] = &exifs[i]
+	}
+	tagsByFile := map[string][]exportTag{}
+	for _, r := range tagRelations {
+		tagsByFile[r.FileID] = append(tagsByFile[r.FileID], exportTag{Name: r.Tag.Name, Source: r.Source, Confidence: r.Confidence})
+	}
+	categoriesByFile := map[string][]exportCategory{}
+	for _, r := range categoryRelations {
+		categoriesByFile[r.FileID] = append(categoriesByFile[r.FileID], exportCategory{Name: r.Category.Name, Source: r.Source})
+	}
+
+	entries := make([]exportFile, len(files))
+	for i, f := range files {
+		entries[i] = exportFile{
+			ID:           f.ID,
+			OriginalName: f.OriginalName,
+			DisplayName:  f.DisplayName,
+			FolderID:     f.FolderID,
+			FolderPath:   folders[f.FolderID],
+			Size:         f.Size,
+			MD5Hash:      f.MD5Hash,
+			Format:       f.Format,
+			MimeType:     f.MimeType,
+			FileType:     f.FileType,
+			Width:        f.Width,
+			Height:       f.Height,
+			Description:  f.Description,
+			AccessLevel:  f.AccessLevel,
+			ShortURL:     f.ShortURL,
+			NSFW:         f.NSFW,
+			CreatedAt:    time.Time(f.CreatedAt),
+			TakenAt:      f.TakenAt,
+			ExpiresAt:    f.ExpiresAt,
+			AIInfo:       aiByFile[f.ID],
+			Tags:         tagsByFile[f.ID],
+			Categories:   categoriesByFile[f.ID],
+			EXIF:         exifByFile[f.ID],
+		}
+		if entries[i].Tags == nil {
+			entries[i].Tags = []exportTag{}
+		}
+		if entries[i].Categories == nil {
+			entries[i].Categories = []exportCategory{}
+		}
+	}
+	return entries, nil
+}
+
+// copyOriginal 将原始文件写入压缩包：本地存储直接读取，其他存储通过存储服务读取
+// 图片和视频本身已压缩，按存储方式写入以节省 CPU
+func copyOriginal(zw *zip.Writer, file *models.File, name string) error {
+	reader, err := openOriginal(file)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store, Modified: time.Time(file.CreatedAt)})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, reader)
+	return err
+}
+
+func openOriginal(file *models.File) (io.ReadCloser, error) {
+	if file.StorageType == "local" && file.LocalFilePath != "" {
+		if f, err := os.Open(file.LocalFilePath); err == nil {
+			return f, nil
+		}
+	}
+	if file.StorageProviderID == "" || file.LocalFilePath == "" {
+		return nil, fmt.Errorf("文件缺少存储路径")
+	}
+	svc, err := filesvc.GetStorageServiceInstance()
+	if err != nil {
+		return nil, err
+	}
+	return svc.ReadFile(context.Background(), file.StorageProviderID, file.LocalFilePath)
+}
+
+// loadFolderPaths 返回用户全部文件夹的完整路径，用于还原压缩包中的目录结构
+func loadFolderPaths(userID uint) (map[string]string, error) {
+	var folders []models.Folder
+	if err := database.DB.Select("id", "parent_id", "name").Where("user_id = ?", userID).Find(&folders).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "读取文件夹失败")
+	}
+	byID := make(map[string]models.Folder, len(folders))
+	for _, f := range folders {
+		byID[f.ID] = f
+	}
+	return folderPaths(byID), nil
+}
+
+// folderPaths 按父级关系拼接文件夹路径，父级缺失或存在循环时从该处截断
+func folderPaths(byID map[string]models.Folder) map[string]string {
+	paths := make(map[string]string, len(byID))
+	var resolve func(id string, depth int) string
+	resolve = func(id string, depth int) string {
+		if p, ok := paths[id]; ok {
+			return p
+		}
+		f, ok := byID[id]
+		if !ok || depth > 64 {
+			return ""
+		}
+		p := safeName(f.Name, f.ID)
+		if f.ParentID != "" {
+			if parent := resolve(f.ParentID, depth+1); parent != "" {
+				p = parent + "/" + p
+			}
+		}
+		paths[id] = p
+		return p
+	}
+	for id := range byID {
+		resolve(id, 0)
+	}
+	return paths
+}
+
+// safeName 将文件名或文件夹名转换为压缩包中安全的路径段
+func safeName(name, fallback string) string {
+	name = strings.TrimSpace(strings.NewReplacer("/", "_", "\\", "_", "\x00", "").Replace(name))
+	if name == "" || name == "." || name == ".." {
+		return fallback
+	}
+	return name
+}
+
+// nameSet 同一目录下的重名文件依次加上 (2)、(3) 后缀
+type nameSet map[string]bool
+
+func newNameSet() nameSet {
+	return nameSet{}
+}
+
+func (s nameSet) unique(name string) string {
+	candidate := name
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 2; s[strings.ToLower(candidate)]; i++ {
+		candidate = fmt.Sprintf("%s (%d)%s", base, i, ext)
+	}
+	s[strings.ToLower(candidate)] = true
+	return candidate
+}
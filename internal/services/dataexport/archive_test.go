@@ -0,0 +1,49 @@
+package dataexport
+
+import (
+	"testing"
+
+	"pixelpunk/internal/models"
+)
+
+func TestFolderPaths(t *testing.T) {
+	paths := folderPaths(map[string]models.Folder{
+		"a": {ID: "a", Name: "旅行"},
+		"b": {ID: "b", Name: "2019", ParentID: "a"},
+		"c": {ID: "c", Name: "../etc", ParentID: "b"},
+		"d": {ID: "d", Name: "orphan", ParentID: "missing"},
+		"x": {ID: "x", Name: "loop", ParentID: "y"},
+		"y": {ID: "y", Name: "..", ParentID: "x"},
+	})
+
+	want := map[string]string{
+		"a": "旅行",
+		"b": "旅行/2019",
+		"c": "旅行/2019/.._etc",
+		"d": "orphan",
+	}
+	for id, p := range want {
+		if paths[id] != p {
+			t.Errorf("paths[%s] = %q, want %q", id, paths[id], p)
+		}
+	}
+	if paths["x"] == "" || paths["y"] == "" {
+		t.Errorf("循环引用的文件夹也应有路径: %v", paths)
+	}
+}
+
+func TestNameSetUnique(t *testing.T) {
+	names := newNameSet()
+	got := []string{
+		names.unique("files/a.jpg"),
+		names.unique("files/A.jpg"),
+		names.unique("files/a.jpg"),
+		names.unique("files/b/a.jpg"),
+	}
+	want := []string{"files/a.jpg", "files/A (2).jpg", "files/a (3).jpg", "files/b/a.jpg"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("unique[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package dataexport
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"pixelpunk/internal/cluster"
+	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/message"
+	"pixelpunk/internal/services/setting"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/config"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/logger"
+	"pixelpunk/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+// exportSlots 同时只生成一个导出，打包会读取用户的全部原始文件
+var exportSlots = make(chan struct{}, 1)
+
+// runningExports 当前进程中已在运行或排队的导出，防止重复启动；进程之间通过任务租约互斥
+var runningExports sync.Map
+
+/* CreateExport 创建用户数据导出任务并在后台打包，同一用户同时只能有一个进行中的导出 */
+func CreateExport(userID, requestedBy uint) (*models.DataExport, error) {
+	var user models.User
+	if err := database.DB.Select("id").Where("id = ?", userID).First(&user).Error; err != nil {
+		return nil, errors.New(errors.CodeUserNotFound, "用户不存在")
+	}
+
+	var active int64
+	if err := database.DB.Model(&models.DataExport{}).
+		Where("user_id = ? AND status IN ?", userID, []string{models.DataExportStatusPending, models.DataExportStatusRunning}).
+		Count(&active).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询导出任务失败")
+	}
+	if active > 0 {
+		return nil, errors.New(errors.CodeConflict, "已有正在进行的数据导出，请等待完成后再试")
+	}
+
+	export := &models.DataExport{UserID: userID, RequestedBy: requestedBy}
+	if err := database.DB.Create(export).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBCreateFailed, "创建导出任务失败")
+	}
+	startExport(export.ID)
+	return export, nil
+}
+
+/* GetExport 获取用户的导出任务，userID 为 0 时不限制用户（管理员） */
+func GetExport(userID uint, exportID string) (*models.DataExport, error) {
+	query := database.DB.Where("id = ?", exportID)
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	var export models.DataExport
+	if err := query.First(&export).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New(errors.CodeNotFound, "导出任务不存在")
+		}
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询导出任务失败")
+	}
+	return &export, nil
+}
+
+/* ListExports 分页查询导出任务，userID 为 0 时查询全部用户（管理员） */
+func ListExports(userID uint, page, size int) ([]models.DataExport, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 || size > 100 {
+		size = 20
+	}
+
+	query := database.DB.Model(&models.DataExport{})
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询导出任务总数失败")
+	}
+	var exports []models.DataExport
+	if err := query.Order("created_at DESC").Offset((page - 1) * size).Limit(size).Find(&exports).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询导出任务失败")
+	}
+	return exports, total, nil
+}
+
+/* ArchivePath 返回可下载的压缩包路径，任务未完成或已过期时返回错误
+ * 压缩包由其他进程生成、不在本进程的导出目录中时返回生成进程的地址，由调用方转发下载 */
+func ArchivePath(export *models.DataExport) (path string, nodeURL string, err error) {
+	if export.Status == models.DataExportStatusExpired ||
+		(export.ExpiresAt != nil && time.Time(*export.ExpiresAt).Before(time.Now())) {
+		return "", "", errors.New(errors.CodeNotFound, "导出文件已过期，请重新导出")
+	}
+	if export.Status != models.DataExportStatusCompleted {
+		return "", "", errors.New(errors.CodeInvalidParameter, "导出尚未完成")
+	}
+	if _, err := os.Stat(export.FilePath); err != nil {
+		if export.NodeURL != "" && export.NodeURL != advertiseURL() {
+			return "", export.NodeURL, nil
+		}
+		return "", "", errors.New(errors.CodeFileNotFound, "导出文件不存在，请重新导出")
+	}
+	return export.FilePath, "", nil
+}
+
+/* ArchiveName 下载时使用的文件名 */
+func ArchiveName(export *models.DataExport) string {
+	return fmt.Sprintf("pixelpunk-export-%d-%s.zip", export.UserID, time.Time(export.CreatedAt).Format("20060102"))
+}
+
+/* ResumeExports 重新生成没有进程持有的未完成导出，中断时写了一半的压缩包会被覆盖
+ * 启动时和定时执行，处理进程异常退出、租约过期的导出由其他 api 进程接手 */
+func ResumeExports() {
+	var ids []string
+	if err := database.DB.Model(&models.DataExport{}).
+		Where("status IN ?", []string{models.DataExportStatusPending, models.DataExportStatusRunning}).
+		Where("holder = '' OR holder IS NULL OR lease_expires_at < ?", time.Now()).
+		Order("created_at ASC").Pluck("id", &ids).Error; err != nil {
+		logger.Error("查询未完成的数据导出失败: %v", err)
+		return
+	}
+	started := 0
+	for _, id := range ids {
+		if startExport(id) {
+			started++
+		}
+	}
+	if started > 0 {
+		logger.Info("继续处理 %d 个未完成的数据导出", started)
+	}
+}
+
+/* CleanupExpiredExports 删除本进程生成的已过期导出压缩包，返回清理的数量
+ * 压缩包保存在生成进程的导出目录中，每个 api 进程各自清理；导出目录共享时任一进程都可以删除 */
+func CleanupExpiredExports() (int, error) {
+	var exports []models.DataExport
+	if err := database.DB.Where("status = ? AND expires_at < ?", models.DataExportStatusCompleted, time.Now()).
+		Find(&exports).Error; err != nil {
+		return 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询过期的数据导出失败")
+	}
+
+	cleaned := 0
+	for _, export := range exports {
+		err := os.Remove(export.FilePath)
+		if err != nil && !os.IsNotExist(err) {
+			logger.Warn("删除过期的导出文件失败: export_id=%s, %v", export.ID, err)
+			continue
+		}
+		if err != nil && export.NodeURL != "" && export.NodeURL != advertiseURL() {
+			// 压缩包在其他进程上，由生成它的进程删除
+			continue
+		}
+		database.DB.Model(&models.DataExport{}).Where("id = ?", export.ID).
+			Updates(map[string]interface{}{"status": models.DataExportStatusExpired, "file_path": ""})
+		cleaned++
+	}
+	return cleaned, nil
+}
+
+// advertiseURL 本进程的内部地址，未配置时压缩包只能从导出目录读取
+func advertiseURL() string {
+	return strings.TrimSuffix(config.GetConfig().App.AdvertiseURL, "/")
+}
+
+func exportDir() string {
+	return config.GetConfig().Export.Dir
+}
+
+func retention() time.Duration {
+	hours := config.GetConfig().Export.RetentionHours
+	if hours <= 0 {
+		hours = 72
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// startExport 认领导出后在后台打包，多个 api 进程同时恢复导出时只有认领成功的进程执行
+func startExport(exportID string) bool {
+	if _, loaded := runningExports.LoadOrStore(exportID, true); loaded {
+		return false
+	}
+	lease, err := cluster.ClaimJob(&models.DataExport{}, exportID, []string{models.DataExportStatusPending, models.DataExportStatusRunning})
+	if err != nil || lease == nil {
+		runningExports.Delete(exportID)
+		if err != nil {
+			logger.Error("认领数据导出失败: export_id=%s, %v", exportID, err)
+		}
+		return false
+	}
+	go func() {
+		defer runningExports.Delete(exportID)
+		defer lease.Release()
+		exportSlots <- struct{}{}
+		defer func() { <-exportSlots }()
+
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Error("数据导出异常退出: export_id=%s, %v", exportID, r)
+			}
+		}()
+		runExport(exportID)
+	}()
+	return true
+}
+
+func runExport(exportID string) {
+	var export models.DataExport
+	if err := database.DB.Where("id = ?", exportID).First(&export).Error; err != nil {
+		logger.Error("加载导出任务失败: export_id=%s, %v", exportID, err)
+		return
+	}
+	if !export.IsActive() {
+		return
+	}
+
+	now := common.JSONTime(time.Now())
+	database.DB.Model(&models.DataExport{}).Where("id = ?", exportID).
+		Updates(map[string]interface{}{"status": models.DataExportStatusRunning, "started_at": now})
+
+	if err := os.MkdirAll(exportDir(), 0755); err != nil {
+		failExport(&export, errors.Wrap(err, errors.CodeInternal, "创建导出目录失败"))
+		return
+	}
+	archivePath := filepath.Join(exportDir(), export.ID+".zip")
+	// 先写入本进程的临时文件，导出目录在进程之间共享时不会与接手的进程写同一个文件
+	partPath := archivePath + "." + cluster.InstanceID() + ".part"
+	result, err := writeArchive(export.UserID, partPath)
+	if err != nil {
+		os.Remove(partPath)
+		failExport(&export, err)
+		return
+	}
+
+	info, err := os.Stat(partPath)
+	if err == nil {
+		err = os.Rename(partPath, archivePath)
+	}
+	if err != nil {
+		os.Remove(partPath)
+		failExport(&export, errors.Wrap(err, errors.CodeInternal, "保存导出文件失败"))
+		return
+	}
+	done := time.Now()
+	expiresAt := common.JSONTime(done.Add(retention()))
+	// 只在仍持有租约时完成，租约过期后由接手的进程完成
+	updated := database.DB.Model(&models.DataExport{}).Where("id = ? AND holder = ?", exportID, cluster.InstanceID()).Updates(map[string]interface{}{
+		"status":        models.DataExportStatusCompleted,
+		"file_path":     archivePath,
+		"node_url":      advertiseURL(),
+		"archive_size":  info.Size(),
+		"file_count":    result.FileCount,
+		"missing_count": result.MissingCount,
+		"completed_at":  common.JSONTime(done),
+		"expires_at":    expiresAt,
+	})
+	if updated.Error != nil || updated.RowsAffected == 0 {
+		logger.Warn("数据导出已由其他进程接手: export_id=%s", exportID)
+		return
+	}
+	logger.Info("数据导出完成: export_id=%s, user_id=%d, 文件 %d 个, 缺失 %d 个", exportID, export.UserID, result.FileCount, result.MissingCount)
+
+	notifyExport(&export, common.MessageTypeAccountDataExportReady, map[string]interface{}{
+		"file_count":   result.FileCount,
+		"archive_size": utils.FormatBytes(info.Size()),
+		"expires_at":   time.Time(expiresAt).Format("2006-01-02 15:04"),
+		"download_url": downloadURL(export.ID),
+	})
+}
+
+func failExport(export *models.DataExport, err error) {
+	logger.Error("数据导出失败: export_id=%s, %v", export.ID, err)
+	msg := err.Error()
+	if e, ok := err.(*errors.Error); ok {
+		msg = e.Message
+	}
+	database.DB.Model(&models.DataExport{}).Where("id = ?", export.ID).Updates(map[string]interface{}{
+		"status":       models.DataExportStatusFailed,
+		"error_msg":    msg,
+		"completed_at": common.JSONTime(time.Now()),
+	})
+	notifyExport(export, common.MessageTypeAccountDataExportFailed, map[string]interface{}{"reason": msg})
+}
+
+func notifyExport(export *models.DataExport, msgType string, variables map[string]interface{}) {
+	variables["export_id"] = export.ID
+	variables["related_type"] = common.RelatedTypeUser
+	variables["related_id"] = export.UserID
+	if err := message.GetMessageService().SendTemplateMessage(export.UserID, msgType, variables); err != nil {
+		logger.Warn("发送数据导出通知失败: export_id=%s, %v", export.ID, err)
+	}
+}
+
+// downloadURL 消息中的下载地址：配置了网站地址时为完整地址，浏览器通过登录 Cookie 鉴权；否则指向设置页面
+func downloadURL(exportID string) string {
+	websiteSettings, err := setting.GetSettingsByGroupAsMap("website")
+	if err == nil {
+		if baseURL, ok := websiteSettings.Settings["site_base_url"].(string); ok && baseURL != "" {
+			return strings.TrimSuffix(baseURL, "/") + "/api/v1/personal/data-exports/" + exportID + "/download"
+		}
+	}
+	return "/settings"
+}
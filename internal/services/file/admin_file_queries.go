@@ -6,10 +6,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"pixelpunk/internal/models"
+	tagService "pixelpunk/internal/services/tag"
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/errors"
 	"pixelpunk/pkg/logger"
+	"strconv"
 	"strings"
+
+	"gorm.io/gorm"
 )
 
 /* AdminGetFileList 管理员获取文件列表（语义化命名） */
@@ -18,17 +22,100 @@ func AdminGetFileList(params AdminFileSearchParams) ([]AdminFileDetailResponse,
 	var images []models.File
 	var responses []AdminFileDetailResponse
 
-	query := database.DB.Model(&models.File{}).Where("status <> ?", StatusPendingDeletion)
+	query, empty, err := buildFileSearchQuery(params)
+	if err != nil {
+		return nil, 0, err
+	}
+	if empty {
+		return []AdminFileDetailResponse{}, 0, nil
+	}
+
+	var countQuery = query
+	if err := countQuery.Count(&total).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "获取文件总数失败")
+	}
+
+	query = applyFileSort(query, params.Sort)
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Size <= 0 {
+		params.Size = 20
+	}
+	offset := (params.Page - 1) * params.Size
+
+	selectFields := []string{"id", "user_id", "folder_id", "original_name", "display_name",
+		"url", "thumb_url", "size", "width", "height", "format", "access_level",
+		"is_recommended", "storage_provider_id", "is_duplicate", "md5_hash",
+		"created_at", "updated_at", "remote_url", "remote_thumb_url",
+		"storage_duration", "expires_at", "taken_at"}
+	if err := query.Select(selectFields).Offset(offset).Limit(params.Size).Find(&images).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件列表失败")
+	}
+
+	imageIDs := make([]string, 0, len(images))
+	for _, file := range images {
+		imageIDs = append(imageIDs, file.ID)
+	}
+	var aiInfoList []models.FileAIInfo
+	if len(imageIDs) > 0 {
+		database.DB.Where("file_id IN ?", imageIDs).Find(&aiInfoList)
+	}
+	aiInfoMap := make(map[string]models.FileAIInfo)
+	for _, ai := range aiInfoList {
+		aiInfoMap[ai.FileID] = ai
+	}
+	var statsList []models.FileStats
+	if len(imageIDs) > 0 {
+		database.DB.Where("file_id IN ?", imageIDs).Find(&statsList)
+	}
+	statsMap := make(map[string]int64)
+	for _, s := range statsList {
+		statsMap[s.FileID] = s.Views
+	}
+
+	for _, file := range images {
+		var userName string
+		if file.UserID > 0 {
+			var user models.User
+			if err := database.DB.Select("username").Where("id = ?", file.UserID).First(&user).Error; err == nil {
+				userName = user.Username
+			}
+		}
+		var aiInfo *AIInfoResponse
+		if ai, ok := aiInfoMap[file.ID]; ok {
+			aiInfo = convertToAIResponse(ai)
+		}
+		views := statsMap[file.ID]
+		resp := BuildAdminFileDetailResponse(file, views, userName, aiInfo)
+		responses = append(responses, resp)
+	}
+	return responses, total, nil
+}
+
+// buildFileSearchQuery 按搜索参数构建文件查询，empty 为 true 表示已确定没有匹配结果
+func buildFileSearchQuery(params AdminFileSearchParams) (query *gorm.DB, empty bool, err error) {
+	query = database.DB.Model(&models.File{}).Where("status <> ?", StatusPendingDeletion)
 
 	if len(params.Tags) > 0 {
+		// 搜索父标签时包含全部子孙标签
+		tagIDs := make([]uint, 0, len(params.Tags))
+		for _, idStr := range params.Tags {
+			if id, err := strconv.ParseUint(idStr, 10, 64); err == nil {
+				tagIDs = append(tagIDs, uint(id))
+			}
+		}
+		if len(tagIDs) == 0 {
+			return nil, true, nil
+		}
 		var imageIDs []string
-		if err := database.DB.Model(&models.FileGlobalTagRelation{}).Where("tag_id IN ?", params.Tags).Distinct("file_id").Pluck("file_id", &imageIDs).Error; err != nil {
-			return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询标签关系失败")
+		if err := database.DB.Model(&models.FileGlobalTagRelation{}).Where("tag_id IN ?", tagService.ExpandTagDescendants(tagIDs)).Distinct("file_id").Pluck("file_id", &imageIDs).Error; err != nil {
+			return nil, false, errors.Wrap(err, errors.CodeDBQueryFailed, "查询标签关系失败")
 		}
 		if len(imageIDs) > 0 {
 			query = query.Where("id IN ?", imageIDs)
 		} else {
-			return []AdminFileDetailResponse{}, 0, nil
+			return nil, true, nil
 		}
 	}
 
@@ -44,16 +131,16 @@ func AdminGetFileList(params AdminFileSearchParams) ([]AdminFileDetailResponse,
 		if len(categoryIDs) > 0 {
 			query = query.Where("category_id IN ?", categoryIDs)
 		} else {
-			return []AdminFileDetailResponse{}, 0, nil
+			return nil, true, nil
 		}
 	}
 
 	if params.Keyword != "" {
-		nameQuery := database.DB.Where("original_name LIKE ? OR display_name LIKE ?", "%"+params.Keyword+"%", "%"+params.Keyword+"%")
+		nameQuery := database.DB.Where(database.ILike("original_name LIKE ? OR display_name LIKE ?"), "%"+params.Keyword+"%", "%"+params.Keyword+"%")
 		var aiMatchingIDs []string
-		database.DB.Model(&models.FileAIInfo{}).Where("description LIKE ?", "%"+params.Keyword+"%").Pluck("file_id", &aiMatchingIDs)
+		database.DB.Model(&models.FileAIInfo{}).Where(database.ILike("description LIKE ?"), "%"+params.Keyword+"%").Pluck("file_id", &aiMatchingIDs)
 		var tagIDs []uint
-		database.DB.Model(&models.GlobalTag{}).Where("name LIKE ?", "%"+params.Keyword+"%").Pluck("id", &tagIDs)
+		database.DB.Model(&models.GlobalTag{}).Where(database.ILike("name LIKE ?"), "%"+params.Keyword+"%").Pluck("id", &tagIDs)
 		var tagMatchingIDs []string
 		if len(tagIDs) > 0 {
 			database.DB.Model(&models.FileGlobalTagRelation{}).Where("tag_id IN ?", tagIDs).Pluck("file_id", &tagMatchingIDs)
@@ -85,6 +172,10 @@ func AdminGetFileList(params AdminFileSearchParams) ([]AdminFileDetailResponse,
 	if params.AccessLevel != "" {
 		query = query.Where("access_level = ?", params.AccessLevel)
 	}
+	if params.PublicFolderOnly {
+		query = query.Where("folder_id = '' OR folder_id IS NULL OR folder_id IN (?)",
+			database.DB.Model(&models.Folder{}).Select("id").Where("permission = ?", "public"))
+	}
 	if params.IsRecommended != nil {
 		query = query.Where("is_recommended = ?", *params.IsRecommended)
 	}
@@ -118,7 +209,40 @@ func AdminGetFileList(params AdminFileSearchParams) ([]AdminFileDetailResponse,
 		if len(colorMatchFileIDs) > 0 {
 			query = query.Where("id IN ?", colorMatchFileIDs)
 		} else {
-			return []AdminFileDetailResponse{}, 0, nil
+			return nil, true, nil
+		}
+	}
+
+	if params.AlbumID != "" {
+		query = query.Where("id IN (?)", database.DB.Model(&models.AlbumItem{}).Select("file_id").Where("album_id = ?", params.AlbumID))
+	}
+	if params.FileIDs != nil {
+		if len(params.FileIDs) == 0 {
+			return nil, true, nil
+		}
+		query = query.Where("id IN ?", params.FileIDs)
+	}
+	if params.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *params.CreatedFrom)
+	}
+	if params.CreatedTo != nil {
+		query = query.Where("created_at < ?", *params.CreatedTo)
+	}
+	if params.TakenDayFrom > 0 {
+		query = query.Where("taken_day >= ?", params.TakenDayFrom)
+	}
+	if params.TakenDayTo > 0 {
+		query = query.Where("taken_day < ?", params.TakenDayTo)
+	}
+	if params.TakenMonthDay > 0 {
+		query = query.Where("taken_day % 10000 = ?", params.TakenMonthDay)
+	}
+	if exifQuery := buildEXIFFilterQuery(params); exifQuery != nil {
+		query = query.Where("id IN (?)", exifQuery)
+		if params.HideStrippedEXIF && (params.GeoCountry != "" || params.GeoCity != "") {
+			query = query.Where("exif_policy IN ?", []string{models.ExifPolicyKeep, models.ExifPolicyStripSerial})
+		} else if params.HideStrippedEXIF {
+			query = query.Where("exif_policy IS NULL OR exif_policy <> ?", models.ExifPolicyStripAll)
 		}
 	}
 
@@ -145,20 +269,76 @@ func AdminGetFileList(params AdminFileSearchParams) ([]AdminFileDetailResponse,
 		if len(aiFilterFileIDs) > 0 {
 			query = query.Where("id IN ?", aiFilterFileIDs)
 		} else {
-			return []AdminFileDetailResponse{}, 0, nil
+			return nil, true, nil
 		}
 	}
 
-	var countQuery = query
-	if err := countQuery.Count(&total).Error; err != nil {
+	return query, false, nil
+}
+
+/* SearchFiles 按搜索参数分页获取文件，由调用方转换为对应的响应结构 */
+func SearchFiles(params AdminFileSearchParams) ([]models.File, int64, error) {
+	query, empty, err := buildFileSearchQuery(params)
+	if err != nil || empty {
+		return []models.File{}, 0, err
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "获取文件总数失败")
 	}
 
-	switch strings.ToLower(params.Sort) {
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Size <= 0 {
+		params.Size = 20
+	}
+	var files []models.File
+	if err := applyFileSort(query, params.Sort).Offset((params.Page - 1) * params.Size).Limit(params.Size).Find(&files).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件列表失败")
+	}
+	return files, total, nil
+}
+
+/* SearchFileIDs 按搜索参数获取匹配的文件ID，按排序方式返回前 limit 个 */
+func SearchFileIDs(params AdminFileSearchParams, limit int) ([]string, error) {
+	query, empty, err := buildFileSearchQuery(params)
+	if err != nil || empty {
+		return []string{}, err
+	}
+
+	var ids []string
+	if err := applyFileSort(query, params.Sort).Limit(limit).Pluck("file.id", &ids).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件失败")
+	}
+	return ids, nil
+}
+
+/* CountSearchFiles 统计符合搜索参数的文件数量 */
+func CountSearchFiles(params AdminFileSearchParams) (int64, error) {
+	query, empty, err := buildFileSearchQuery(params)
+	if err != nil || empty {
+		return 0, err
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return 0, errors.Wrap(err, errors.CodeDBQueryFailed, "获取文件总数失败")
+	}
+	return total, nil
+}
+
+func applyFileSort(query *gorm.DB, sort string) *gorm.DB {
+	switch strings.ToLower(sort) {
 	case "newest":
 		query = query.Order("created_at DESC")
 	case "oldest":
 		query = query.Order("created_at ASC")
+	case "taken":
+		query = query.Order("taken_at DESC").Order("id DESC")
+	case "taken_oldest":
+		query = query.Order("taken_at ASC").Order("id ASC")
 	case "name":
 		query = query.Order("display_name ASC")
 	case "size":
@@ -172,61 +352,7 @@ func AdminGetFileList(params AdminFileSearchParams) ([]AdminFileDetailResponse,
 	default:
 		query = query.Order("created_at DESC")
 	}
-	if params.Page <= 0 {
-		params.Page = 1
-	}
-	if params.Size <= 0 {
-		params.Size = 20
-	}
-	offset := (params.Page - 1) * params.Size
-
-	selectFields := []string{"id", "user_id", "folder_id", "original_name", "display_name",
-		"url", "thumb_url", "size", "width", "height", "format", "access_level",
-		"is_recommended", "storage_provider_id", "is_duplicate", "md5_hash",
-		"created_at", "updated_at", "remote_url", "remote_thumb_url",
-		"storage_duration", "expires_at"}
-	if err := query.Select(selectFields).Offset(offset).Limit(params.Size).Find(&images).Error; err != nil {
-		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件列表失败")
-	}
-
-	imageIDs := make([]string, 0, len(images))
-	for _, file := range images {
-		imageIDs = append(imageIDs, file.ID)
-	}
-	var aiInfoList []models.FileAIInfo
-	if len(imageIDs) > 0 {
-		database.DB.Where("file_id IN ?", imageIDs).Find(&aiInfoList)
-	}
-	aiInfoMap := make(map[string]models.FileAIInfo)
-	for _, ai := range aiInfoList {
-		aiInfoMap[ai.FileID] = ai
-	}
-	var statsList []models.FileStats
-	if len(imageIDs) > 0 {
-		database.DB.Where("file_id IN ?", imageIDs).Find(&statsList)
-	}
-	statsMap := make(map[string]int64)
-	for _, s := range statsList {
-		statsMap[s.FileID] = s.Views
-	}
-
-	for _, file := range images {
-		var userName string
-		if file.UserID > 0 {
-			var user models.User
-			if err := database.DB.Select("username").Where("id = ?", file.UserID).First(&user).Error; err == nil {
-				userName = user.Username
-			}
-		}
-		var aiInfo *AIInfoResponse
-		if ai, ok := aiInfoMap[file.ID]; ok {
-			aiInfo = convertToAIResponse(ai)
-		}
-		views := statsMap[file.ID]
-		resp := BuildAdminFileDetailResponse(file, views, userName, aiInfo)
-		responses = append(responses, resp)
-	}
-	return responses, total, nil
+	return query
 }
 
 /* AdminGetImageList -> AdminGetFileList */
@@ -264,3 +390,52 @@ func convertToAIResponse(ai models.FileAIInfo) *AIInfoResponse {
 		NSFWCategories: nsfwCategories,
 	}
 }
+
+/* buildEXIFFilterQuery 根据 EXIF 筛选条件构建文件ID子查询，没有 EXIF 条件时返回 nil */
+func buildEXIFFilterQuery(params AdminFileSearchParams) *gorm.DB {
+	exifQuery := database.DB.Model(&models.FileEXIF{}).Select("file_id")
+	filtered := false
+	where := func(cond string, args ...interface{}) {
+		exifQuery = exifQuery.Where(cond, args...)
+		filtered = true
+	}
+
+	if params.CameraMake != "" {
+		where(database.ILike("make LIKE ?"), "%"+params.CameraMake+"%")
+	}
+	if params.CameraModel != "" {
+		where(database.ILike("model LIKE ?"), "%"+params.CameraModel+"%")
+	}
+	if params.LensModel != "" {
+		where(database.ILike("lens_model LIKE ?"), "%"+params.LensModel+"%")
+	}
+	if params.MinISO > 0 {
+		where("iso >= ?", params.MinISO)
+	}
+	if params.MaxISO > 0 {
+		where("iso <= ?", params.MaxISO)
+	}
+	if params.MinFocalLength > 0 {
+		where("focal_length >= ?", params.MinFocalLength)
+	}
+	if params.MaxFocalLength > 0 {
+		where("focal_length <= ?", params.MaxFocalLength)
+	}
+	if params.TakenFrom != nil {
+		where("date_time_original >= ?", *params.TakenFrom)
+	}
+	if params.TakenTo != nil {
+		where("date_time_original < ?", *params.TakenTo)
+	}
+	if params.GeoCountry != "" {
+		where("geo_country_code = ?", params.GeoCountry)
+	}
+	if params.GeoCity != "" {
+		where("geo_city = ?", params.GeoCity)
+	}
+
+	if !filtered {
+		return nil
+	}
+	return exifQuery
+}
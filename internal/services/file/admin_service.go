@@ -12,6 +12,7 @@ import (
 	"pixelpunk/pkg/storage"
 
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -38,6 +39,28 @@ type AdminFileSearchParams struct {
 	IsRecommended *bool    // 是否推荐内容(可选)
 	FolderID      string   // 文件夹ID
 	AccessLevel   string   // 访问级别
+
+	AlbumID     string     // 手动相册ID，限定为相册内的文件
+	FileIDs     []string   // 限定文件ID范围，非nil的空切片表示无结果
+	CreatedFrom *time.Time // 上传时间起（含）
+	CreatedTo   *time.Time // 上传时间止（不含）
+	CameraMake  string     // EXIF相机制造商，模糊匹配
+	CameraModel string     // EXIF相机型号，模糊匹配
+
+	LensModel        string     // EXIF镜头型号，模糊匹配
+	MinISO           int        // 最小ISO
+	MaxISO           int        // 最大ISO
+	MinFocalLength   float64    // 最小焦距（mm）
+	MaxFocalLength   float64    // 最大焦距（mm）
+	TakenFrom        *time.Time // 拍摄时间起（含）
+	TakenTo          *time.Time // 拍摄时间止（不含）
+	GeoCountry       string     // 拍摄地国家代码
+	GeoCity          string     // 拍摄地城市
+	TakenDayFrom     int        // 拍摄日期起 YYYYMMDD（含）
+	TakenDayTo       int        // 拍摄日期止 YYYYMMDD（不含）
+	TakenMonthDay    int        // 拍摄的月日 MMDD，用于那年今日
+	HideStrippedEXIF bool       // 排除元数据策略为清除全部的文件，用于非所有者的列表；按地点筛选时同时排除隐藏位置的文件
+	PublicFolderOnly bool       // 只包含根目录或公开文件夹中的文件，与作者主页一致
 }
 
 type AdminImageSearchParams = AdminFileSearchParams
@@ -98,10 +98,11 @@ func GetPublicTagList(page, size int, keyword string) ([]TagWithCount, int64, er
 		Where("file.access_level = ?", "public")
 
 	if keyword != "" {
-		query = query.Where("global_tag.name LIKE ?", "%"+keyword+"%")
+		query = query.Where(database.ILike("global_tag.name LIKE ?"), "%"+keyword+"%")
 	}
 
-	query = query.Group("global_tag.id").Having("count > 0")
+	// PostgreSQL 的 HAVING 不能引用 SELECT 中的别名
+	query = query.Group("global_tag.id").Having("COUNT(DISTINCT file_global_tag_relation.file_id) > 0")
 
 	countQuery := db.Model(&models.GlobalTag{}).
 		Select("COUNT(DISTINCT global_tag.id)").
@@ -110,7 +111,7 @@ func GetPublicTagList(page, size int, keyword string) ([]TagWithCount, int64, er
 		Where("file.access_level = ?", "public")
 
 	if keyword != "" {
-		countQuery = countQuery.Where("global_tag.name LIKE ?", "%"+keyword+"%")
+		countQuery = countQuery.Where(database.ILike("global_tag.name LIKE ?"), "%"+keyword+"%")
 	}
 
 	if err := countQuery.Count(&total).Error; err != nil {
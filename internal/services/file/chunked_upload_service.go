@@ -110,6 +110,7 @@ func InitChunkedUpload(userID uint, req *dto.InitChunkedUploadDTO) (*models.Uplo
 		AccessLevel:     req.AccessLevel,
 		Optimize:        req.Optimize,
 		WatermarkConfig: watermarkConfigJSON,
+		ExifPolicy:      req.ExifPolicy,
 		ExpiresAt:       time.Now().Add(time.Duration(sessionTimeoutHours) * time.Hour),
 	}
 
@@ -260,8 +261,11 @@ func CompleteChunkedUpload(sessionID string) (*FileDetailResponse, error) {
 		return nil, err
 	}
 
-	if err := validateMergedFile(mergedFilePath, session.FileMD5); err != nil {
-		return nil, err
+	// tus 上传没有整体MD5，各段数据由 Upload-Checksum 校验
+	if session.FileMD5 != "" {
+		if err := validateMergedFile(mergedFilePath, session.FileMD5); err != nil {
+			return nil, err
+		}
 	}
 
 	imageResponse, err := processUploadedFile(session.UserID, mergedFilePath, &session)
@@ -470,6 +474,8 @@ func processUploadedFile(userID uint, filePath string, session *models.UploadSes
 		ctx.WatermarkEnabled = true
 		ctx.WatermarkConfig = session.WatermarkConfig
 	}
+	applyDefaultWatermarkProfile(ctx)
+	ctx.ExifPolicy = session.ExifPolicy
 
 	ctx.FileExt = filepath.Ext(session.FileName)
 	ctx.FileHash = session.FileMD5
@@ -539,6 +545,11 @@ func uploadMergedFileDirectly(ctx *UploadContext, mergedFilePath string) error {
 		originalFile := ctx.File
 		ctx.File = files[0]
 
+		if err := prepareMergedFileMetadata(ctx); err != nil {
+			ctx.File = originalFile
+			return err
+		}
+
 		// 如果启用了水印，调用带水印的上传流程
 		if ctx.WatermarkEnabled && ctx.WatermarkConfig != "" {
 			err = processFileAndUploadWithWatermark(ctx)
@@ -19,6 +19,7 @@ func cleanupFileResources(fileID string, file models.File, totalReferences int64
 	cleanupFileShares(fileID)
 	cleanupFileUploadSessions(fileID)
 	cleanupFileVectors(fileID)
+	removeWatermarkCache(fileID)
 	if totalReferences == 0 {
 		cleanupPhysicalFiles(file)
 	}
@@ -42,6 +43,9 @@ func cleanupFileShares(fileID string) {
 	if err := db.Unscoped().Where("item_type = ? AND item_id = ?", "file", fileID).Delete(&models.ShareItem{}).Error; err != nil {
 		logger.Error("删除分享项目失败 [%s]: %v", fileID, err)
 	}
+	if err := db.Where("file_id = ?", fileID).Delete(&models.AlbumItem{}).Error; err != nil {
+		logger.Error("删除相册文件失败 [%s]: %v", fileID, err)
+	}
 }
 
 func cleanupFileUploadSessions(fileID string) {
@@ -94,6 +94,29 @@ func ServeFile(file models.File, isThumb bool) (interface{}, bool, bool, error)
 		useProxy = globalHideRemoteURL
 	}
 
+	remoteUrl := remoteObjectPath(file, isThumb)
+
+	if useProxy {
+		content, contentType, err := provider.GetRemoteContent(remoteUrl, isThumb, file.UserID)
+		if err != nil {
+			logger.Error("代理模式获取内容失败: %v, remoteUrl=%s", err, remoteUrl)
+			return nil, false, false, err
+		}
+		return &ProxyResponse{
+			Content:     content,
+			ContentType: contentType,
+			OpenRange:   remoteRangeOpener(provider, file, isThumb),
+		}, false, true, nil
+	}
+	fileURL, err := provider.GetFileURL(remoteUrl, isThumb)
+	if err != nil {
+		return nil, false, false, err
+	}
+	return fileURL, false, false, nil
+}
+
+/* remoteObjectPath 获取远程存储中原图或缩略图的对象路径 */
+func remoteObjectPath(file models.File, isThumb bool) string {
 	var candidate string
 	if isThumb {
 		if file.RemoteThumbURL != "" && !pathutil.IsHTTPURL(file.RemoteThumbURL) {
@@ -108,22 +131,7 @@ func ServeFile(file models.File, isThumb bool) (interface{}, bool, bool, error)
 			candidate = file.URL
 		}
 	}
-	candidate = strings.TrimPrefix(candidate, "/")
-	remoteUrl := candidate
-
-	if useProxy {
-		content, contentType, err := provider.GetRemoteContent(remoteUrl, isThumb, file.UserID)
-		if err != nil {
-			logger.Error("代理模式获取内容失败: %v, remoteUrl=%s", err, remoteUrl)
-			return nil, false, false, err
-		}
-		return &ProxyResponse{Content: content, ContentType: contentType, ContentLength: 0}, false, true, nil
-	}
-	fileURL, err := provider.GetFileURL(remoteUrl, isThumb)
-	if err != nil {
-		return nil, false, false, err
-	}
-	return fileURL, false, false, nil
+	return strings.TrimPrefix(candidate, "/")
 }
 
 /* ProxyResponse 代理响应 */
@@ -131,4 +139,23 @@ type ProxyResponse struct {
 	Content       io.ReadCloser
 	ContentType   string
 	ContentLength int64
+	// OpenRange 内容为存储中的原始文件时非空，用于把 Range 请求转发给存储，不必从头读取
+	OpenRange func(start, end int64) (io.ReadCloser, error)
+}
+
+/* remoteRangeOpener 按范围读取远程存储中的原图或缩略图 */
+func remoteRangeOpener(provider storage.RemoteReadProvider, file models.File, isThumb bool) func(start, end int64) (io.ReadCloser, error) {
+	remotePath := remoteObjectPath(file, isThumb)
+	return func(start, end int64) (io.ReadCloser, error) {
+		return provider.GetRemoteRange(remotePath, isThumb, file.UserID, start, end)
+	}
+}
+
+/* RemoteRangeOpener 按范围读取文件在远程存储中的内容，本地存储或获取存储失败时返回 nil */
+func RemoteRangeOpener(file models.File, isThumb bool) func(start, end int64) (io.ReadCloser, error) {
+	provider, err := storage.GetStorageProviderByChannelID(file.StorageProviderID)
+	if err != nil || provider.IsDirectAccess() {
+		return nil
+	}
+	return remoteRangeOpener(provider, file, isThumb)
 }
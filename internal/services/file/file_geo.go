@@ -0,0 +1,314 @@
+package file
+
+import (
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/exif"
+	"pixelpunk/pkg/geocode"
+	"pixelpunk/pkg/logger"
+	"pixelpunk/pkg/storage"
+
+	"gorm.io/gorm"
+)
+
+// maxGeoPoints 单次地图查询最多参与聚合的坐标数量
+const maxGeoPoints = 20000
+
+// GeoSearchParams 地图查询参数，范围和筛选条件复用文件搜索参数
+type GeoSearchParams struct {
+	AdminFileSearchParams
+
+	HasBounds bool    // 按矩形范围查询，MinLon 大于 MaxLon 时表示跨越经度180°
+	MinLat    float64 // 南
+	MinLon    float64 // 西
+	MaxLat    float64 // 北
+	MaxLon    float64 // 东
+
+	HasRadius bool    // 按圆形范围查询
+	Latitude  float64 // 圆心纬度
+	Longitude float64 // 圆心经度
+	RadiusKm  float64 // 半径（km）
+
+	Zoom int // 地图缩放级别，达到 geocode.MaxZoom 时不聚合
+}
+
+// GeoFilePreview 地图上展示的文件信息
+type GeoFilePreview struct {
+	ID           string `json:"id"`
+	DisplayName  string `json:"display_name"`
+	FullURL      string `json:"full_url"`
+	FullThumbURL string `json:"full_thumb_url"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+}
+
+// GeoCluster 地图聚合点，File 为聚合内最新上传的文件
+type GeoCluster struct {
+	geocode.Cluster
+	File *GeoFilePreview `json:"file,omitempty"`
+}
+
+// GeoSearchResult 地图查询结果
+type GeoSearchResult struct {
+	Clusters  []GeoCluster `json:"clusters"`
+	Total     int64        `json:"total"`     // 范围内带坐标的文件数量
+	Truncated bool         `json:"truncated"` // 文件过多时只聚合最新的部分
+	Zoom      int          `json:"zoom"`
+}
+
+// GeoPlaceStat 地点统计
+type GeoPlaceStat struct {
+	CountryCode string `json:"country_code"`
+	Country     string `json:"country"`
+	City        string `json:"city"`
+	Count       int64  `json:"count"`
+}
+
+type geoPointRow struct {
+	FileID       string
+	GPSLatitude  float64
+	GPSLongitude float64
+}
+
+/* fillGeoPlace 根据 GPS 坐标填充离线反向地理编码的国家和城市 */
+func fillGeoPlace(fe *models.FileEXIF) {
+	if fe == nil || !fe.HasGPS() {
+		return
+	}
+	if place := geocode.Reverse(*fe.GPSLatitude, *fe.GPSLongitude); place != nil {
+		fe.GeoCountryCode = place.CountryCode
+		fe.GeoCountry = place.Country
+		fe.GeoCity = place.City
+	}
+}
+
+/* buildGeoQuery 构建带坐标的 EXIF 查询，文件范围和筛选条件由搜索参数决定
+ * HideStrippedEXIF 时只包含允许他人查看位置的文件 */
+func buildGeoQuery(params GeoSearchParams) (*gorm.DB, bool, error) {
+	fileQuery, empty, err := buildFileSearchQuery(params.AdminFileSearchParams)
+	if err != nil || empty {
+		return nil, empty, err
+	}
+	if params.HideStrippedEXIF {
+		fileQuery = fileQuery.Where("exif_policy IN ?", []string{models.ExifPolicyKeep, models.ExifPolicyStripSerial})
+	}
+
+	query := database.DB.Model(&models.FileEXIF{}).
+		Where("file_id IN (?)", fileQuery.Select("id")).
+		Where("gps_latitude IS NOT NULL AND gps_longitude IS NOT NULL")
+
+	if params.HasRadius {
+		minLat, minLon, maxLat, maxLon := geocode.BoundsAround(params.Latitude, params.Longitude, params.RadiusKm)
+		query = query.Where("gps_latitude BETWEEN ? AND ? AND gps_longitude BETWEEN ? AND ?", minLat, maxLat, minLon, maxLon)
+	}
+	if params.HasBounds {
+		query = query.Where("gps_latitude BETWEEN ? AND ?", params.MinLat, params.MaxLat)
+		if params.MinLon <= params.MaxLon {
+			query = query.Where("gps_longitude BETWEEN ? AND ?", params.MinLon, params.MaxLon)
+		} else {
+			query = query.Where("gps_longitude >= ? OR gps_longitude <= ?", params.MinLon, params.MaxLon)
+		}
+	}
+	return query, false, nil
+}
+
+/* SearchGeoFiles 查询范围内带坐标的文件，并按缩放级别聚合 */
+func SearchGeoFiles(params GeoSearchParams) (*GeoSearchResult, error) {
+	result := &GeoSearchResult{Clusters: []GeoCluster{}, Zoom: params.Zoom}
+	query, empty, err := buildGeoQuery(params)
+	if err != nil {
+		return nil, err
+	}
+	if empty {
+		return result, nil
+	}
+
+	var rows []geoPointRow
+	if err := query.Select("file_id, gps_latitude, gps_longitude").
+		Order("created_at DESC").
+		Limit(maxGeoPoints + 1).
+		Find(&rows).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询地理位置失败")
+	}
+	if len(rows) > maxGeoPoints {
+		rows = rows[:maxGeoPoints]
+		result.Truncated = true
+	}
+
+	points := make([]geocode.Point, 0, len(rows))
+	for _, row := range rows {
+		if params.HasRadius && geocode.Distance(params.Latitude, params.Longitude, row.GPSLatitude, row.GPSLongitude) > params.RadiusKm {
+			continue
+		}
+		points = append(points, geocode.Point{ID: row.FileID, Latitude: row.GPSLatitude, Longitude: row.GPSLongitude})
+	}
+
+	result.Total = int64(len(points))
+	if result.Truncated && !params.HasRadius {
+		if err := query.Count(&result.Total).Error; err != nil {
+			return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "统计地理位置失败")
+		}
+	}
+
+	clusters := geocode.ClusterPoints(points, params.Zoom)
+	previews, err := loadGeoFilePreviews(clusters)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range clusters {
+		result.Clusters = append(result.Clusters, GeoCluster{Cluster: c, File: previews[c.ID]})
+	}
+	return result, nil
+}
+
+func loadGeoFilePreviews(clusters []geocode.Cluster) (map[string]*GeoFilePreview, error) {
+	previews := make(map[string]*GeoFilePreview, len(clusters))
+	if len(clusters) == 0 {
+		return previews, nil
+	}
+	ids := make([]string, 0, len(clusters))
+	for _, c := range clusters {
+		ids = append(ids, c.ID)
+	}
+
+	var files []models.File
+	if err := database.DB.Select("id", "display_name", "original_name", "url", "thumb_url", "width", "height",
+		"storage_provider_id", "remote_url", "remote_thumb_url", "access_level").
+		Where("id IN ?", ids).Find(&files).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件失败")
+	}
+	for _, file := range files {
+		fullURL, fullThumbURL, _ := storage.GetFullURLs(file)
+		name := file.DisplayName
+		if name == "" {
+			name = file.OriginalName
+		}
+		previews[file.ID] = &GeoFilePreview{
+			ID:           file.ID,
+			DisplayName:  name,
+			FullURL:      fullURL,
+			FullThumbURL: fullThumbURL,
+			Width:        file.Width,
+			Height:       file.Height,
+		}
+	}
+	return previews, nil
+}
+
+/* ListGeoPlaces 按国家和城市统计范围内带坐标的文件数量，用于地点筛选 */
+func ListGeoPlaces(params GeoSearchParams) ([]GeoPlaceStat, error) {
+	stats := []GeoPlaceStat{}
+	query, empty, err := buildGeoQuery(params)
+	if err != nil || empty {
+		return stats, err
+	}
+
+	if err := query.Select("geo_country_code AS country_code, geo_country AS country, geo_city AS city, COUNT(*) AS count").
+		Where("geo_country_code <> ''").
+		Group("geo_country_code, geo_country, geo_city").
+		Order("count DESC").
+		Scan(&stats).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "统计地点失败")
+	}
+	return stats, nil
+}
+
+// geoBackfillCursor 补充地点的进度，无法识别地点的坐标不会重复处理，重启后从头开始
+var geoBackfillCursor string
+
+/* BackfillGeoPlaces 为已有坐标但尚未反向地理编码的 EXIF 补充地点，返回处理数量 */
+func BackfillGeoPlaces(batchSize int) (int, error) {
+	var rows []models.FileEXIF
+	if err := database.DB.Select("id", "gps_latitude", "gps_longitude").
+		Where("id > ?", geoBackfillCursor).
+		Where("gps_latitude IS NOT NULL AND gps_longitude IS NOT NULL").
+		Where("geo_country_code = '' OR geo_country_code IS NULL").
+		Order("id ASC").
+		Limit(batchSize).
+		Find(&rows).Error; err != nil {
+		return 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询待补充地点的EXIF失败")
+	}
+
+	for i := range rows {
+		geoBackfillCursor = rows[i].ID
+		fillGeoPlace(&rows[i])
+		if rows[i].GeoCountryCode == "" {
+			continue
+		}
+		if err := database.DB.Model(&models.FileEXIF{}).Where("id = ?", rows[i].ID).Updates(map[string]interface{}{
+			"geo_country_code": rows[i].GeoCountryCode,
+			"geo_country":      rows[i].GeoCountry,
+			"geo_city":         rows[i].GeoCity,
+		}).Error; err != nil {
+			return i, errors.Wrap(err, errors.CodeDBUpdateFailed, "更新EXIF地点失败")
+		}
+	}
+	return len(rows), nil
+}
+
+// gpsBackfillCursor 补充坐标的进度，读取原图失败的记录在本次运行中不再重试
+var gpsBackfillCursor string
+
+/* BackfillEXIFGPS 早期版本解析 GPS 有误，历史 EXIF 记录的坐标都为空
+ * 从存储中的原图重新提取坐标，只处理元数据策略允许保留位置的文件（含未记录策略的历史文件），返回处理数量
+ * 补上坐标后由 BackfillGeoPlaces 补充地点 */
+func BackfillEXIFGPS(batchSize int) (int, error) {
+	var rows []models.FileEXIF
+	if err := database.DB.Model(&models.FileEXIF{}).
+		Select("file_exif.id", "file_exif.file_id").
+		Joins("JOIN file ON file.id = file_exif.file_id").
+		Where("file_exif.id > ?", gpsBackfillCursor).
+		Where("file_exif.gps_checked = ? AND file_exif.gps_latitude IS NULL", false).
+		Where("file.exif_policy IS NULL OR file.exif_policy IN ?", []string{"", models.ExifPolicyKeep, models.ExifPolicyStripSerial}).
+		Order("file_exif.id ASC").
+		Limit(batchSize).
+		Find(&rows).Error; err != nil {
+		return 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询待补充坐标的EXIF失败")
+	}
+
+	for i, row := range rows {
+		gpsBackfillCursor = row.ID
+		var file models.File
+		if err := database.DB.Where("id = ?", row.FileID).First(&file).Error; err != nil {
+			continue
+		}
+
+		updates := map[string]interface{}{"gps_checked": true}
+		if file.Size <= signedLinkTransformMaxSize {
+			data, err := readStoredContent(file, false)
+			if err != nil {
+				logger.Warn("补充坐标时读取原图失败: fileID=%s, error=%v", file.ID, err)
+				continue
+			}
+			if parsed, err := exif.ExtractEXIFFromBytes(data); err == nil && parsed != nil && parsed.GPSLatitude != nil && parsed.GPSLongitude != nil {
+				fe := &models.FileEXIF{GPSLatitude: parsed.GPSLatitude, GPSLongitude: parsed.GPSLongitude}
+				fillGeoPlace(fe)
+				updates["gps_latitude"] = parsed.GPSLatitude
+				updates["gps_longitude"] = parsed.GPSLongitude
+				updates["gps_altitude"] = parsed.GPSAltitude
+				updates["gps_latitude_ref"] = parsed.GPSLatitudeRef
+				updates["gps_longitude_ref"] = parsed.GPSLongitudeRef
+				updates["geo_country_code"] = fe.GeoCountryCode
+				updates["geo_country"] = fe.GeoCountry
+				updates["geo_city"] = fe.GeoCity
+			}
+		}
+		if err := database.DB.Model(&models.FileEXIF{}).Where("id = ?", row.ID).Updates(updates).Error; err != nil {
+			return i, errors.Wrap(err, errors.CodeDBUpdateFailed, "更新EXIF坐标失败")
+		}
+	}
+	return len(rows), nil
+}
+
+/* AuthorPublicScope 作者主页的文件范围：作者根目录或公开文件夹中的公开文件，元数据按策略隐藏 */
+func AuthorPublicScope(authorID uint) (AdminFileSearchParams, error) {
+	var count int64
+	if err := database.DB.Model(&models.User{}).Where("id = ?", authorID).Count(&count).Error; err != nil {
+		return AdminFileSearchParams{}, errors.Wrap(err, errors.CodeDBQueryFailed, "查询作者失败")
+	}
+	if count == 0 {
+		return AdminFileSearchParams{}, errors.New(errors.CodeUserNotFound, "作者不存在")
+	}
+	return AdminFileSearchParams{UserID: authorID, AccessLevel: "public", PublicFolderOnly: true, HideStrippedEXIF: true}, nil
+}
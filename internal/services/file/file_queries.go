@@ -4,6 +4,7 @@ package file
 
 import (
 	"pixelpunk/internal/models"
+	tagService "pixelpunk/internal/services/tag"
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/errors"
 
@@ -26,22 +27,23 @@ func GetFileList(userID uint, folderID string, page, size int, sort string, acce
 		query = query.Where("access_level = ?", accessLevel)
 	}
 	if keyword != "" {
-		query = query.Where("original_name LIKE ? OR file_path LIKE ? OR display_name LIKE ?", "%"+keyword+"%", "%"+keyword+"%", "%"+keyword+"%")
+		query = query.Where(database.ILike("original_name LIKE ? OR file_path LIKE ? OR display_name LIKE ?"), "%"+keyword+"%", "%"+keyword+"%", "%"+keyword+"%")
 	}
 
 	if len(tags) > 0 {
-		var tagIDs []uint
-		if err := database.DB.Model(&models.GlobalTag{}).Where("name IN ?", tags).Pluck("id", &tagIDs).Error; err != nil {
-			return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询标签失败")
-		}
-		if len(tagIDs) == 0 {
-			return []FileDetailResponse{}, 0, nil
-		}
-		sub := database.DB.Model(&models.FileGlobalTagRelation{}).Select("DISTINCT file_id").Where("tag_id IN ?", tagIDs)
-		if len(tags) > 1 {
-			sub = sub.Group("file_id").Having("COUNT(DISTINCT tag_id) = ?", len(tagIDs))
+		// 每个标签（含同义词与子孙标签）都需要命中
+		globalTagService := tagService.NewGlobalTagService()
+		for _, name := range tags {
+			tag, err := globalTagService.ResolveTagByName(name)
+			if err != nil {
+				return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询标签失败")
+			}
+			if tag == nil {
+				return []FileDetailResponse{}, 0, nil
+			}
+			sub := database.DB.Model(&models.FileGlobalTagRelation{}).Select("file_id").Where("tag_id IN ?", tagService.ExpandTagDescendants([]uint{tag.ID}))
+			query = query.Where("id IN (?)", sub)
 		}
-		query = query.Where("id IN (?)", sub)
 	}
 
 	if len(dominantColor) > 0 {
@@ -105,6 +107,24 @@ func GetFileList(userID uint, folderID string, page, size int, sort string, acce
 	return responses, total, nil
 }
 
+/* GetUserFileListByParams 按搜索参数分页获取用户自己的文件，返回与文件列表相同的用户响应结构 */
+func GetUserFileListByParams(params AdminFileSearchParams) ([]FileDetailResponse, int64, error) {
+	if params.UserID == 0 {
+		return nil, 0, errors.New(errors.CodeInvalidParameter, "缺少用户ID")
+	}
+
+	files, total, err := SearchFiles(params)
+	if err != nil {
+		return nil, 0, err
+	}
+	responses := make([]FileDetailResponse, 0, len(files))
+	for _, file := range files {
+		aiInfo, _ := GetFileAIInfo(file.ID)
+		responses = append(responses, BuildFileDetailResponse(file, 0, aiInfo))
+	}
+	return responses, total, nil
+}
+
 /* GetFileDetail 获取单个文件详情 */
 func GetFileDetail(userID uint, fileID string) (*FileDetailResponse, error) {
 	var file models.File
@@ -130,3 +150,24 @@ func GetFileDetail(userID uint, fileID string) (*FileDetailResponse, error) {
 
 	return &resp, nil
 }
+
+/* GetVisibleFileEXIF 按访问级别和元数据策略返回查看者可见的 EXIF
+ * 所有者和管理员可见全部；私有文件对其他人不可见；公开和受保护文件按上传时的元数据策略隐藏 */
+func GetVisibleFileEXIF(file models.File, viewerID uint, isAdmin bool) (*models.FileEXIF, error) {
+	isOwner := isAdmin || (viewerID != 0 && viewerID == file.UserID)
+	if !isOwner && file.IsPrivate() {
+		return nil, errors.New(errors.CodeFileAccessDenied, "无权查看该文件的元数据")
+	}
+
+	var exifInfo models.FileEXIF
+	if err := database.DB.Where("file_id = ?", file.ID).First(&exifInfo).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件元数据失败")
+	}
+	if isOwner {
+		return &exifInfo, nil
+	}
+	return exifInfo.Redacted(file.ExifPolicy), nil
+}
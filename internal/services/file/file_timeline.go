@@ -0,0 +1,165 @@
+package file
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/errors"
+)
+
+/* 时间线聚合粒度 */
+const (
+	TimelineYear  = "year"
+	TimelineMonth = "month"
+	TimelineDay   = "day"
+)
+
+// onThisDayMaxFiles 那年今日最多返回的文件数
+const onThisDayMaxFiles = 200
+
+// TimelineBucket 时间线上一个年/月/日的文件数量
+type TimelineBucket struct {
+	Period string `json:"period"` // 2024、2024-05 或 2024-05-20
+	Year   int    `json:"year"`
+	Month  int    `json:"month,omitempty"`
+	Day    int    `json:"day,omitempty"`
+	Count  int64  `json:"count"`
+}
+
+// OnThisDayGroup 那年今日中某一年的文件
+type OnThisDayGroup struct {
+	Year     int                       `json:"year"`
+	YearsAgo int                       `json:"years_ago"`
+	Count    int                       `json:"count"`
+	Files    []AdminFileDetailResponse `json:"files"`
+}
+
+/* TakenDayRange 将年、月、日转换为 taken_day 的查询范围 [from, to)，year 为0表示不限 */
+func TakenDayRange(year, month, day int) (from, to int) {
+	switch {
+	case year == 0:
+		return 0, 0
+	case month == 0:
+		return year * 10000, (year + 1) * 10000
+	case day == 0:
+		start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.Local)
+		return models.TakenDayOf(start), models.TakenDayOf(start.AddDate(0, 1, 0))
+	default:
+		start := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.Local)
+		return models.TakenDayOf(start), models.TakenDayOf(start.AddDate(0, 0, 1))
+	}
+}
+
+/* ApplyTakenDate 校验年月日组合并限定查询的拍摄日期范围，月份需要年份，日期需要月份 */
+func ApplyTakenDate(params *AdminFileSearchParams, year, month, day int) error {
+	if month > 0 && year == 0 {
+		return errors.New(errors.CodeInvalidParameter, "指定月份时需要同时指定年份")
+	}
+	if day > 0 && month == 0 {
+		return errors.New(errors.CodeInvalidParameter, "指定日期时需要同时指定月份")
+	}
+	if day > 0 && time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.Local).Day() != day {
+		return errors.New(errors.CodeInvalidParameter, "日期不存在")
+	}
+	params.TakenDayFrom, params.TakenDayTo = TakenDayRange(year, month, day)
+	return nil
+}
+
+/* TimelineSort 将时间线的排序方向转换为文件列表排序方式 */
+func TimelineSort(order string) string {
+	if order == "asc" {
+		return "taken_oldest"
+	}
+	return "taken"
+}
+
+/* GetTimeline 按拍摄日期统计文件数量，granularity 为 year/month/day，结果按时间倒序 */
+func GetTimeline(params AdminFileSearchParams, granularity string) ([]TimelineBucket, error) {
+	buckets := []TimelineBucket{}
+	query, empty, err := buildFileSearchQuery(params)
+	if err != nil || empty {
+		return buckets, err
+	}
+
+	var rows []struct {
+		TakenDay int
+		Count    int64
+	}
+	if err := query.Select("taken_day, COUNT(*) AS count").
+		Where("taken_day > 0").
+		Group("taken_day").
+		Scan(&rows).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "统计时间线失败")
+	}
+
+	counts := make(map[int]int64)
+	for _, row := range rows {
+		counts[rollupTakenDay(row.TakenDay, granularity)] += row.Count
+	}
+	for key, count := range counts {
+		buckets = append(buckets, newTimelineBucket(key, granularity, count))
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].Period > buckets[j].Period
+	})
+	return buckets, nil
+}
+
+// rollupTakenDay 将 YYYYMMDD 按粒度截断为 YYYY、YYYYMM 或 YYYYMMDD
+func rollupTakenDay(day int, granularity string) int {
+	switch granularity {
+	case TimelineYear:
+		return day / 10000
+	case TimelineDay:
+		return day
+	default:
+		return day / 100
+	}
+}
+
+func newTimelineBucket(key int, granularity string, count int64) TimelineBucket {
+	switch granularity {
+	case TimelineYear:
+		return TimelineBucket{Period: fmt.Sprintf("%04d", key), Year: key, Count: count}
+	case TimelineDay:
+		year, month, day := key/10000, key/100%100, key%100
+		return TimelineBucket{Period: fmt.Sprintf("%04d-%02d-%02d", year, month, day), Year: year, Month: month, Day: day, Count: count}
+	default:
+		year, month := key/100, key%100
+		return TimelineBucket{Period: fmt.Sprintf("%04d-%02d", year, month), Year: year, Month: month, Count: count}
+	}
+}
+
+/* GetOnThisDay 获取往年同一天拍摄的文件，按年份分组，最近的年份在前 */
+func GetOnThisDay(params AdminFileSearchParams, date time.Time) ([]OnThisDayGroup, error) {
+	groups := []OnThisDayGroup{}
+	params.TakenMonthDay = int(date.Month())*100 + date.Day()
+	params.TakenDayTo = date.Year() * 10000
+	params.Sort = "taken"
+	params.Page = 1
+	params.Size = onThisDayMaxFiles
+
+	files, _, err := AdminGetFileList(params)
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[int]int)
+	for _, file := range files {
+		if file.TakenAt == nil {
+			continue
+		}
+		year := time.Time(*file.TakenAt).Year()
+		i, ok := index[year]
+		if !ok {
+			i = len(groups)
+			index[year] = i
+			groups = append(groups, OnThisDayGroup{Year: year, YearsAgo: date.Year() - year, Files: []AdminFileDetailResponse{}})
+		}
+		groups[i].Files = append(groups[i].Files, file)
+		groups[i].Count++
+	}
+	return groups, nil
+}
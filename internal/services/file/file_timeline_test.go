@@ -0,0 +1,52 @@
+package file
+
+import "testing"
+
+func TestTakenDayRange(t *testing.T) {
+	cases := []struct {
+		year, month, day int
+		from, to         int
+	}{
+		{0, 0, 0, 0, 0},
+		{2024, 0, 0, 20240000, 20250000},
+		{2024, 2, 0, 20240201, 20240301},
+		{2024, 12, 0, 20241201, 20250101},
+		{2024, 2, 29, 20240229, 20240301},
+		{2023, 12, 31, 20231231, 20240101},
+	}
+	for _, tc := range cases {
+		from, to := TakenDayRange(tc.year, tc.month, tc.day)
+		if from != tc.from || to != tc.to {
+			t.Errorf("TakenDayRange(%d, %d, %d) = [%d, %d), want [%d, %d)", tc.year, tc.month, tc.day, from, to, tc.from, tc.to)
+		}
+	}
+}
+
+func TestApplyTakenDateRejectsInvalidCombination(t *testing.T) {
+	var params AdminFileSearchParams
+	if err := ApplyTakenDate(&params, 0, 5, 0); err == nil {
+		t.Error("month without year should be rejected")
+	}
+	if err := ApplyTakenDate(&params, 2024, 0, 3); err == nil {
+		t.Error("day without month should be rejected")
+	}
+	if err := ApplyTakenDate(&params, 2023, 2, 29); err == nil {
+		t.Error("2023-02-29 should be rejected")
+	}
+	if err := ApplyTakenDate(&params, 2024, 5, 20); err != nil || params.TakenDayFrom != 20240520 || params.TakenDayTo != 20240521 {
+		t.Errorf("unexpected range [%d, %d), err %v", params.TakenDayFrom, params.TakenDayTo, err)
+	}
+}
+
+func TestTimelineBucketRollup(t *testing.T) {
+	day := 20240520
+	if b := newTimelineBucket(rollupTakenDay(day, TimelineYear), TimelineYear, 3); b.Period != "2024" || b.Year != 2024 || b.Month != 0 {
+		t.Errorf("year bucket = %+v", b)
+	}
+	if b := newTimelineBucket(rollupTakenDay(day, TimelineMonth), TimelineMonth, 3); b.Period != "2024-05" || b.Month != 5 || b.Day != 0 {
+		t.Errorf("month bucket = %+v", b)
+	}
+	if b := newTimelineBucket(rollupTakenDay(day, TimelineDay), TimelineDay, 3); b.Period != "2024-05-20" || b.Day != 20 || b.Count != 3 {
+		t.Errorf("day bucket = %+v", b)
+	}
+}
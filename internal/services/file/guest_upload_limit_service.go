@@ -92,9 +92,12 @@ func (s *GuestUploadLimitService) CheckIPUploadLimit(ip string) (bool, error) {
 	var count int64
 	cfg := config.GetConfig()
 	var dateCondition string
-	if cfg.Database.Type == "sqlite" {
+	switch cfg.Database.Type {
+	case "sqlite":
 		dateCondition = "DATE(created_at) = DATE('now')"
-	} else {
+	case "postgres":
+		dateCondition = "DATE(created_at) = CURRENT_DATE"
+	default:
 		dateCondition = "DATE(created_at) = CURDATE()"
 	}
 	err = database.DB.Model(&models.GuestUploadLog{}).
@@ -0,0 +1,429 @@
+package file
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"mime"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"pixelpunk/internal/cluster"
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/logger"
+	"pixelpunk/pkg/urlfetch"
+
+	"gorm.io/gorm"
+)
+
+const importJobConcurrency = 2 // 同时运行的导入任务数，任务内的条目顺序处理
+
+// importJobSlots 限制同时运行的导入任务数，避免批量任务占满带宽和存储
+var importJobSlots = make(chan struct{}, importJobConcurrency)
+
+// runningImportJobs 当前进程中已在运行或排队的任务，防止重复启动；进程之间通过任务租约互斥
+var runningImportJobs sync.Map
+
+// importSourceFactories 其他模块注册的导入来源，如其他图床的导出数据
+var importSourceFactories = map[string]func(job *models.ImportJob) (ImportSource, error){}
+
+// ImportSource 不同来源的导入任务在通用执行流程中的差异部分
+type ImportSource interface {
+	// Prepare 开始处理条目前调用，可以在这里生成条目；返回错误时整个任务记为失败
+	Prepare(job *models.ImportJob) error
+	// Process 导入单个条目
+	Process(job *models.ImportJob, item *models.ImportJobItem) (*ImportItemResult, error)
+	// Cleanup 任务结束（完成、取消或失败）后清理临时文件
+	Cleanup(job *models.ImportJob)
+}
+
+// ImportItemResult 单个条目的导入结果，Skipped 为 true 时记为跳过而不是成功
+type ImportItemResult struct {
+	FileID   string
+	FileName string
+	Size     int64
+	Skipped  bool
+	Reason   string
+}
+
+/* RegisterImportSource 注册导入来源，需在 ResumeImportJobs 之前调用 */
+func RegisterImportSource(source string, factory func(job *models.ImportJob) (ImportSource, error)) {
+	importSourceFactories[source] = factory
+}
+
+/* CreateImportJob 创建任务并在后台处理，条目由来源的 Prepare 生成 */
+func CreateImportJob(job *models.ImportJob) error {
+	if err := database.DB.Create(job).Error; err != nil {
+		return errors.Wrap(err, errors.CodeDBCreateFailed, "创建导入任务失败")
+	}
+	startImportJob(job.ID)
+	return nil
+}
+
+func newImportSource(job *models.ImportJob) (ImportSource, error) {
+	switch job.Source {
+	case models.ImportSourceURL:
+		return newURLImportSource(job)
+	case models.ImportSourceDirectory, models.ImportSourceArchive:
+		return newLocalImportSource(job)
+	}
+	if factory, ok := importSourceFactories[job.Source]; ok {
+		return factory(job)
+	}
+	return nil, errors.New(errors.CodeInvalidParameter, "未知的导入来源: "+job.Source)
+}
+
+/* ImportFileOptions 服务端导入文件时的上传选项 */
+type ImportFileOptions struct {
+	AccessLevel string
+	Optimize    bool
+	ExifPolicy  string
+	ModTime     *time.Time // 不为nil时用作创建时间
+}
+
+/* ImportFileData 将服务端读取或下载的文件内容按普通上传流程保存到用户的文件夹
+ * 同一文件夹中已有相同内容的文件时不重复导入，返回已有文件的ID；不计入每日上传次数 */
+func ImportFileData(userID uint, folderID, fileName string, data []byte, opts ImportFileOptions) (*FileDetailResponse, string, error) {
+	sum := md5.Sum(data)
+	hash := hex.EncodeToString(sum[:])
+
+	var existing []models.File
+	if err := database.DB.Select("id").
+		Where("user_id = ? AND folder_id = ? AND md5_hash = ?", userID, folderID, hash).
+		Where("status <> ?", StatusPendingDeletion).
+		Limit(1).Find(&existing).Error; err != nil {
+		return nil, "", errors.Wrap(err, errors.CodeDBQueryFailed, "查询重复文件失败")
+	}
+	if len(existing) > 0 {
+		return nil, existing[0].ID, nil
+	}
+
+	if err := checkImportStorage(userID, int64(len(data))); err != nil {
+		return nil, "", err
+	}
+
+	contentType := urlfetch.SniffContentType(data, mime.TypeByExtension(filepath.Ext(fileName)))
+	fileHeader, err := newMemoryFileHeader(fileName, contentType, data)
+	if err != nil {
+		return nil, "", err
+	}
+
+	ctx := CreateUploadContext(nil, userID, fileHeader, folderID, opts.AccessLevel, opts.Optimize)
+	ctx.ExifPolicy = opts.ExifPolicy
+	ctx.ModTime = opts.ModTime
+
+	resp, err := uploadImportedFile(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	return resp, "", nil
+}
+
+/* ImportMaxFileSize 服务端导入单个文件的大小上限，与系统设置的最大上传大小一致 */
+func ImportMaxFileSize() int64 {
+	return urlImportMaxSize()
+}
+
+/* FetchRemoteFile 按远程导入的限制下载文件，下载错误转换为业务错误码 */
+func FetchRemoteFile(rawURL string) (*urlfetch.Result, error) {
+	return fetchRemoteFile(rawURL, urlImportMaxSize())
+}
+
+/* GetImportJob 获取用户的远程导入任务，apiKeyID 不为空时只能查看该密钥创建的任务 */
+func GetImportJob(userID uint, apiKeyID, jobID string) (*models.ImportJob, error) {
+	query := database.DB.Where("id = ? AND user_id = ? AND source = ?", jobID, userID, models.ImportSourceURL)
+	if apiKeyID != "" {
+		query = query.Where("api_key_id = ?", apiKeyID)
+	}
+	return findImportJob(query)
+}
+
+/* ListImportJobs 分页查询用户的远程导入任务 */
+func ListImportJobs(userID uint, apiKeyID string, page, size int) ([]models.ImportJob, int64, error) {
+	query := database.DB.Model(&models.ImportJob{}).Where("user_id = ? AND source = ?", userID, models.ImportSourceURL)
+	if apiKeyID != "" {
+		query = query.Where("api_key_id = ?", apiKeyID)
+	}
+	return listImportJobs(query, page, size)
+}
+
+/* ListImportJobItems 分页查询任务中每个条目的处理状态，status 为空时返回全部 */
+func ListImportJobItems(jobID, status string, page, size int) ([]models.ImportJobItem, int64, error) {
+	page, size = normalizeImportPage(page, size)
+
+	query := database.DB.Model(&models.ImportJobItem{}).Where("job_id = ?", jobID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询导入条目总数失败")
+	}
+	var items []models.ImportJobItem
+	if err := query.Order("seq ASC").Offset((page - 1) * size).Limit(size).Find(&items).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询导入条目失败")
+	}
+	return items, total, nil
+}
+
+/* CancelImportJob 取消未完成的远程导入任务，正在下载的条目处理完后停止，已导入的文件保留 */
+func CancelImportJob(userID uint, apiKeyID, jobID string) (*models.ImportJob, error) {
+	job, err := GetImportJob(userID, apiKeyID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if err := cancelImportJob(job); err != nil {
+		return nil, err
+	}
+	return GetImportJob(userID, apiKeyID, jobID)
+}
+
+/* ResumeImportJobs 继续处理没有进程持有的未完成任务，已处理的条目不会重复导入
+ * 启动时和定时执行，处理进程异常退出、租约过期的任务由其他 api 进程接手 */
+func ResumeImportJobs() {
+	var ids []string
+	if err := database.DB.Model(&models.ImportJob{}).
+		Where("status IN ?", []string{models.ImportJobStatusPending, models.ImportJobStatusRunning}).
+		Where("holder = '' OR holder IS NULL OR lease_expires_at < ?", time.Now()).
+		Order("created_at ASC").Pluck("id", &ids).Error; err != nil {
+		logger.Error("查询未完成的导入任务失败: %v", err)
+		return
+	}
+	started := 0
+	for _, id := range ids {
+		if startImportJob(id) {
+			started++
+		}
+	}
+	if started > 0 {
+		logger.Info("继续处理 %d 个未完成的导入任务", started)
+	}
+}
+
+func findImportJob(query *gorm.DB) (*models.ImportJob, error) {
+	var job models.ImportJob
+	if err := query.First(&job).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New(errors.CodeNotFound, "导入任务不存在")
+		}
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询导入任务失败")
+	}
+	return &job, nil
+}
+
+func listImportJobs(query *gorm.DB, page, size int) ([]models.ImportJob, int64, error) {
+	page, size = normalizeImportPage(page, size)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询导入任务总数失败")
+	}
+	var jobs []models.ImportJob
+	if err := query.Order("created_at DESC").Offset((page - 1) * size).Limit(size).Find(&jobs).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询导入任务失败")
+	}
+	return jobs, total, nil
+}
+
+// cancelImportJob 将未结束的任务标记为取消，运行中的任务在处理下一个条目前停止并清理
+// 排队中的任务不会再被调度，需要在这里直接清理
+func cancelImportJob(job *models.ImportJob) error {
+	if job.IsFinished() {
+		return nil
+	}
+
+	now := common.JSONTime(time.Now())
+	if err := database.DB.Model(&models.ImportJob{}).
+		Where("id = ? AND status IN ?", job.ID, []string{models.ImportJobStatusPending, models.ImportJobStatusRunning}).
+		Updates(map[string]interface{}{"status": models.ImportJobStatusCanceled, "completed_at": now}).Error; err != nil {
+		return errors.Wrap(err, errors.CodeDBUpdateFailed, "取消导入任务失败")
+	}
+	// 由其他进程持有的任务在该进程停止处理时清理
+	if !cluster.LeaseHeld(job.Holder, job.LeaseExpiresAt) {
+		if source, err := newImportSource(job); err == nil {
+			source.Cleanup(job)
+		}
+	}
+	return nil
+}
+
+// startImportJob 认领任务后在后台处理，多个 api 进程同时恢复任务时只有认领成功的进程执行
+func startImportJob(jobID string) bool {
+	if _, loaded := runningImportJobs.LoadOrStore(jobID, true); loaded {
+		return false
+	}
+	lease, err := cluster.ClaimJob(&models.ImportJob{}, jobID, []string{models.ImportJobStatusPending, models.ImportJobStatusRunning})
+	if err != nil || lease == nil {
+		runningImportJobs.Delete(jobID)
+		if err != nil {
+			logger.Error("认领导入任务失败: job_id=%s, %v", jobID, err)
+		}
+		return false
+	}
+	go func() {
+		defer runningImportJobs.Delete(jobID)
+		defer lease.Release()
+		importJobSlots <- struct{}{}
+		defer func() { <-importJobSlots }()
+
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Error("导入任务异常退出: job_id=%s, %v", jobID, r)
+			}
+		}()
+		runImportJob(jobID, lease)
+	}()
+	return true
+}
+
+func runImportJob(jobID string, lease *cluster.JobLease) {
+	var job models.ImportJob
+	if err := database.DB.Where("id = ?", jobID).First(&job).Error; err != nil {
+		logger.Error("加载导入任务失败: job_id=%s, %v", jobID, err)
+		return
+	}
+	if job.IsFinished() {
+		// 排队期间被取消的任务，取消时由本进程持有，在这里清理
+		if job.Status == models.ImportJobStatusCanceled {
+			if source, err := newImportSource(&job); err == nil {
+				source.Cleanup(&job)
+			}
+		}
+		return
+	}
+
+	source, err := newImportSource(&job)
+	if err != nil {
+		failImportJob(&job, err)
+		return
+	}
+	defer func() {
+		if importJobFinished(jobID) {
+			source.Cleanup(&job)
+		}
+	}()
+
+	now := common.JSONTime(time.Now())
+	updates := map[string]interface{}{"status": models.ImportJobStatusRunning}
+	if job.StartedAt == nil {
+		updates["started_at"] = now
+	}
+	database.DB.Model(&models.ImportJob{}).Where("id = ? AND status = ?", jobID, job.Status).Updates(updates)
+
+	if err := source.Prepare(&job); err != nil {
+		if !importJobCanceled(jobID) {
+			failImportJob(&job, err)
+		}
+		return
+	}
+
+	for {
+		if importJobCanceled(jobID) || lease.Lost() {
+			return
+		}
+		var items []models.ImportJobItem
+		if err := database.DB.Where("job_id = ? AND status = ?", jobID, models.ImportItemStatusPending).
+			Order("seq ASC").Limit(1).Find(&items).Error; err != nil {
+			logger.Error("查询导入条目失败: job_id=%s, %v", jobID, err)
+			return
+		}
+		if len(items) == 0 {
+			break
+		}
+		processImportItem(source, &job, &items[0])
+	}
+
+	done := common.JSONTime(time.Now())
+	database.DB.Model(&models.ImportJob{}).
+		Where("id = ? AND status = ?", jobID, models.ImportJobStatusRunning).
+		Updates(map[string]interface{}{"status": models.ImportJobStatusCompleted, "completed_at": done})
+}
+
+// processImportItem 处理单个条目并记录结果，条目状态与任务计数在同一事务中更新
+func processImportItem(source ImportSource, job *models.ImportJob, item *models.ImportJobItem) {
+	result, err := source.Process(job, item)
+
+	itemUpdates := map[string]interface{}{}
+	counter := "success_count"
+	switch {
+	case err != nil:
+		itemUpdates["status"] = models.ImportItemStatusFailed
+		itemUpdates["error_msg"] = importErrorMessage(err)
+		counter = "failed_count"
+	case result.Skipped:
+		itemUpdates["status"] = models.ImportItemStatusSkipped
+		itemUpdates["error_msg"] = result.Reason
+		itemUpdates["file_id"] = result.FileID
+		counter = "skipped_count"
+	default:
+		itemUpdates["status"] = models.ImportItemStatusSuccess
+		itemUpdates["file_id"] = result.FileID
+		itemUpdates["file_name"] = result.FileName
+		itemUpdates["size"] = result.Size
+	}
+
+	txErr := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.ImportJobItem{}).Where("id = ?", item.ID).Updates(itemUpdates).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.ImportJob{}).Where("id = ?", job.ID).
+			UpdateColumn(counter, gorm.Expr(counter+" + 1")).Error
+	})
+	if txErr != nil {
+		logger.Error("更新导入条目失败: job_id=%s, item=%d, %v", job.ID, item.ID, txErr)
+	}
+}
+
+// failImportJob 任务无法继续时记录原因，已导入的文件保留
+func failImportJob(job *models.ImportJob, err error) {
+	logger.Error("导入任务失败: job_id=%s, %v", job.ID, err)
+	now := common.JSONTime(time.Now())
+	database.DB.Model(&models.ImportJob{}).
+		Where("id = ? AND status IN ?", job.ID, []string{models.ImportJobStatusPending, models.ImportJobStatusRunning}).
+		Updates(map[string]interface{}{
+			"status":       models.ImportJobStatusFailed,
+			"error_msg":    importErrorMessage(err),
+			"completed_at": now,
+		})
+}
+
+func importJobCanceled(jobID string) bool {
+	var status string
+	if err := database.DB.Model(&models.ImportJob{}).Where("id = ?", jobID).Pluck("status", &status).Error; err != nil {
+		return false
+	}
+	return status == models.ImportJobStatusCanceled
+}
+
+func importJobFinished(jobID string) bool {
+	var job models.ImportJob
+	if err := database.DB.Select("status").Where("id = ?", jobID).First(&job).Error; err != nil {
+		return false
+	}
+	return job.IsFinished()
+}
+
+// importErrorMessage 条目失败原因只记录可读的提示，不包含内部错误细节
+func importErrorMessage(err error) string {
+	if e, ok := err.(*errors.Error); ok {
+		return e.Message
+	}
+	return err.Error()
+}
+
+func normalizeImportPage(page, size int) (int, int) {
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 {
+		size = common.DefaultPageSize
+	}
+	if size > common.MaxPageSize {
+		size = common.MaxPageSize
+	}
+	return page, size
+}
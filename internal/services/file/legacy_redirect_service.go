@@ -0,0 +1,155 @@
+package file
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/logger"
+
+	"gorm.io/gorm/clause"
+)
+
+const (
+	legacyRedirectMaxPath     = 500
+	legacyRedirectMaxPrefixes = 100 // 启动时最多加载的第一级路径数
+)
+
+// legacyRedirectPrefixes 已登记的旧地址的第一级路径，前端页面等其他请求不需要查询数据库
+var legacyRedirectPrefixes = struct {
+	sync.RWMutex
+	loaded bool
+	set    map[string]bool
+}{set: map[string]bool{}}
+
+// reservedLegacyPrefixes 系统自身使用的路径，旧地址不能覆盖
+var reservedLegacyPrefixes = []string{"api", "f", "t", "s", "thumb", "file", "debug"}
+
+/* SaveLegacyRedirects 登记文件的旧地址，同一路径已存在时改为指向新文件
+ * 返回实际登记的数量，无法识别的地址和系统保留路径被忽略 */
+func SaveLegacyRedirects(fileID, jobID, kind string, rawURLs []string) (int, error) {
+	var records []models.LegacyRedirect
+	seen := map[string]bool{}
+	for _, raw := range rawURLs {
+		p := NormalizeLegacyPath(raw)
+		if p == "" || seen[p] {
+			continue
+		}
+		seen[p] = true
+		records = append(records, models.LegacyRedirect{Path: p, FileID: fileID, Kind: kind, JobID: jobID})
+	}
+	if len(records) == 0 {
+		return 0, nil
+	}
+
+	if err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "path"}},
+		DoUpdates: clause.AssignmentColumns([]string{"file_id", "kind", "job_id"}),
+	}).Create(&records).Error; err != nil {
+		return 0, errors.Wrap(err, errors.CodeDBCreateFailed, "保存旧地址失败")
+	}
+
+	legacyRedirectPrefixes.Lock()
+	for _, r := range records {
+		legacyRedirectPrefixes.set[legacyPrefix(r.Path)] = true
+	}
+	legacyRedirectPrefixes.Unlock()
+	return len(records), nil
+}
+
+/* LegacyRedirectTarget 返回旧地址对应的新地址，没有登记时返回空字符串 */
+func LegacyRedirectTarget(reqPath string) string {
+	if database.DB == nil || !hasLegacyPrefix(legacyPrefix(reqPath)) {
+		return ""
+	}
+
+	var redirects []models.LegacyRedirect
+	if err := database.DB.Where("path = ?", strings.TrimSuffix(reqPath, "/")).Limit(1).Find(&redirects).Error; err != nil || len(redirects) == 0 {
+		return ""
+	}
+	r := redirects[0]
+	if r.Kind == models.LegacyRedirectKindThumb {
+		return "/t/" + r.FileID
+	}
+	return "/f/" + r.FileID
+}
+
+/* NormalizeLegacyPath 取旧地址的路径部分，不合法或属于系统保留路径时返回空字符串 */
+func NormalizeLegacyPath(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	p := strings.TrimSuffix(u.Path, "/")
+	if !strings.HasPrefix(p, "/") || len(p) > legacyRedirectMaxPath {
+		return ""
+	}
+	prefix := legacyPrefix(p)
+	for _, reserved := range reservedLegacyPrefixes {
+		if prefix == reserved {
+			return ""
+		}
+	}
+	return p
+}
+
+func legacyPrefix(p string) string {
+	p = strings.TrimPrefix(p, "/")
+	if i := strings.Index(p, "/"); i >= 0 {
+		return p[:i]
+	}
+	return p
+}
+
+func hasLegacyPrefix(prefix string) bool {
+	legacyRedirectPrefixes.RLock()
+	loaded := legacyRedirectPrefixes.loaded
+	found := legacyRedirectPrefixes.set[prefix]
+	legacyRedirectPrefixes.RUnlock()
+	if loaded {
+		return found
+	}
+
+	loadLegacyRedirectPrefixes()
+	legacyRedirectPrefixes.RLock()
+	defer legacyRedirectPrefixes.RUnlock()
+	return legacyRedirectPrefixes.set[prefix]
+}
+
+// loadLegacyRedirectPrefixes 每次查询一条不属于已知前缀的记录，查询次数等于前缀数量
+func loadLegacyRedirectPrefixes() {
+	found := map[string]bool{}
+	for i := 0; i < legacyRedirectMaxPrefixes; i++ {
+		query := database.DB.Model(&models.LegacyRedirect{})
+		for prefix := range found {
+			query = query.Where("path <> ? AND path NOT LIKE ? ESCAPE '!'", "/"+prefix, "/"+escapeLike(prefix)+"/%")
+		}
+		var paths []string
+		if err := query.Limit(1).Pluck("path", &paths).Error; err != nil {
+			logger.Warn("加载旧地址前缀失败: %v", err)
+			return
+		}
+		if len(paths) == 0 {
+			break
+		}
+		found[legacyPrefix(paths[0])] = true
+	}
+
+	legacyRedirectPrefixes.Lock()
+	defer legacyRedirectPrefixes.Unlock()
+	for prefix := range found {
+		legacyRedirectPrefixes.set[prefix] = true
+	}
+	legacyRedirectPrefixes.loaded = true
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
@@ -0,0 +1,416 @@
+package file
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/folder"
+	"pixelpunk/pkg/archive"
+	"pixelpunk/pkg/config"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/logger"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	localImportMaxEntries = 100000 // 单个任务最多包含的文件数
+	localImportBatchSize  = 500
+)
+
+/* LocalImportOptions 服务器目录和压缩包导入的选项 */
+type LocalImportOptions struct {
+	FolderPath    string `json:"folder_path"` // 目标根文件夹路径，为空时导入到根目录，子目录按相同结构创建
+	AccessLevel   string `json:"access_level"`
+	Optimize      bool   `json:"optimize"`
+	ExifPolicy    string `json:"exif_policy"`
+	PreserveMtime bool   `json:"preserve_mtime"` // 使用源文件修改时间作为创建时间
+}
+
+// localImportState 任务记录中保存的选项和扫描进度，重启后据此继续
+type localImportState struct {
+	LocalImportOptions
+	Root    string `json:"root"`    // 待导入的目录，压缩包任务为解压目录
+	Archive string `json:"archive"` // 待解压的压缩包
+	Scanned bool   `json:"scanned"` // 已生成全部条目
+}
+
+/* CreateDirectoryImportJob 导入服务器上的目录或压缩包，路径必须位于配置允许的目录中
+ * 文件归属 userID 对应的用户，子目录映射为同名文件夹 */
+func CreateDirectoryImportJob(userID uint, rawPath string, opts LocalImportOptions) (*models.ImportJob, error) {
+	realPath, err := ResolveImportPath(rawPath)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(realPath)
+	if err != nil {
+		return nil, errors.New(errors.CodeNotFound, "路径不存在")
+	}
+
+	state := localImportState{LocalImportOptions: opts}
+	source := models.ImportSourceDirectory
+	switch {
+	case info.IsDir():
+		state.Root = realPath
+	case archive.IsArchive(realPath):
+		state.Archive = realPath
+		source = models.ImportSourceArchive
+	default:
+		return nil, errors.New(errors.CodeInvalidParameter, "路径必须是目录或 zip、tar、tar.gz 压缩包")
+	}
+
+	job := &models.ImportJob{ID: newImportJobID(), UserID: userID, Source: source}
+	if err := createLocalImportJob(job, state); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+/* CreateArchiveImportJob 保存上传的压缩包到暂存目录并在后台解压导入，任务结束后删除暂存文件 */
+func CreateArchiveImportJob(userID uint, fileHeader *multipart.FileHeader, opts LocalImportOptions) (*models.ImportJob, error) {
+	name := filepath.Base(strings.ReplaceAll(fileHeader.Filename, "\\", "/"))
+	if !archive.IsArchive(name) {
+		return nil, errors.New(errors.CodeFileTypeNotSupported, archive.ErrUnsupported.Error())
+	}
+	cfg := config.GetConfig().Import
+	if cfg.MaxArchiveSize > 0 && fileHeader.Size > cfg.MaxArchiveSize*1024*1024 {
+		return nil, errors.New(errors.CodeFileTooLarge, fmt.Sprintf("压缩包不能超过%dMB", cfg.MaxArchiveSize))
+	}
+
+	job := &models.ImportJob{ID: newImportJobID(), UserID: userID, Source: models.ImportSourceArchive}
+	workDir := importWorkDir(job.ID)
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		return nil, errors.Wrap(err, errors.CodeFileUploadFailed, "创建暂存目录失败")
+	}
+	state := localImportState{LocalImportOptions: opts, Archive: filepath.Join(workDir, name)}
+	if err := saveUploadedArchive(fileHeader, state.Archive); err != nil {
+		os.RemoveAll(workDir)
+		return nil, err
+	}
+
+	if err := createLocalImportJob(job, state); err != nil {
+		os.RemoveAll(workDir)
+		return nil, err
+	}
+	return job, nil
+}
+
+/* GetLocalImportJob 管理员查看服务器导入任务，包括目录、压缩包和其他图床数据的导入 */
+func GetLocalImportJob(jobID string) (*models.ImportJob, error) {
+	return findImportJob(database.DB.Where("id = ? AND source <> ?", jobID, models.ImportSourceURL))
+}
+
+/* ListLocalImportJobs 分页查询全部用户的服务器导入任务 */
+func ListLocalImportJobs(page, size int) ([]models.ImportJob, int64, error) {
+	return listImportJobs(database.DB.Model(&models.ImportJob{}).Where("source <> ?", models.ImportSourceURL), page, size)
+}
+
+/* CancelLocalImportJob 取消服务器导入任务，已导入的文件保留，暂存文件随后删除 */
+func CancelLocalImportJob(jobID string) (*models.ImportJob, error) {
+	job, err := GetLocalImportJob(jobID)
+	if err != nil {
+		return nil, err
+	}
+	if err := cancelImportJob(job); err != nil {
+		return nil, err
+	}
+	return GetLocalImportJob(jobID)
+}
+
+func createLocalImportJob(job *models.ImportJob, state localImportState) error {
+	var user models.User
+	if err := database.DB.Select("id").Where("id = ?", job.UserID).First(&user).Error; err != nil {
+		return errors.New(errors.CodeUserNotFound, "目标用户不存在")
+	}
+
+	folderID, err := folder.CreateFolderByPath(job.UserID, state.FolderPath)
+	if err != nil {
+		return err
+	}
+	options, _ := json.Marshal(state)
+	job.FolderID = folderID
+	job.Options = string(options)
+
+	return CreateImportJob(job)
+}
+
+func saveUploadedArchive(fileHeader *multipart.FileHeader, dst string) error {
+	src, err := fileHeader.Open()
+	if err != nil {
+		return errors.Wrap(err, errors.CodeFileUploadFailed, "读取压缩包失败")
+	}
+	defer src.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return errors.Wrap(err, errors.CodeFileUploadFailed, "保存压缩包失败")
+	}
+	if _, err := io.Copy(out, src); err != nil {
+		out.Close()
+		return errors.Wrap(err, errors.CodeFileUploadFailed, "保存压缩包失败")
+	}
+	if err := out.Close(); err != nil {
+		return errors.Wrap(err, errors.CodeFileUploadFailed, "保存压缩包失败")
+	}
+	return nil
+}
+
+/* ResolveImportPath 解析符号链接后的真实路径必须位于某个允许导入的目录中 */
+func ResolveImportPath(rawPath string) (string, error) {
+	var roots []string
+	for _, dir := range strings.Split(config.GetConfig().Import.AllowedDirs, ",") {
+		if dir = strings.TrimSpace(dir); dir != "" {
+			roots = append(roots, dir)
+		}
+	}
+	if len(roots) == 0 {
+		return "", errors.New(errors.CodeForbidden, "未配置允许导入的服务器目录")
+	}
+	if strings.TrimSpace(rawPath) == "" {
+		return "", errors.New(errors.CodeInvalidParameter, "导入路径不能为空")
+	}
+
+	abs, err := filepath.Abs(rawPath)
+	if err != nil {
+		return "", errors.New(errors.CodeInvalidParameter, "导入路径无效")
+	}
+	realPath, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return "", errors.New(errors.CodeNotFound, "路径不存在")
+	}
+	for _, root := range roots {
+		realRoot, err := filepath.Abs(root)
+		if err != nil {
+			continue
+		}
+		if resolved, err := filepath.EvalSymlinks(realRoot); err == nil {
+			realRoot = resolved
+		}
+		if PathWithin(realRoot, realPath) {
+			return realPath, nil
+		}
+	}
+	return "", errors.New(errors.CodeForbidden, "路径不在允许导入的目录中")
+}
+
+/* PathWithin 判断 p 是否为 root 本身或其子路径 */
+func PathWithin(root, p string) bool {
+	rel, err := filepath.Rel(root, p)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
+
+func importWorkDir(jobID string) string {
+	return filepath.Join(config.GetConfig().Import.StagingDir, jobID)
+}
+
+func newImportJobID() string {
+	return strings.ReplaceAll(uuid.New().String(), "-", "")
+}
+
+// localImportSource 服务器目录或压缩包导入任务，首次运行时扫描目录生成条目
+type localImportSource struct {
+	state   localImportState
+	folders map[string]string // 相对目录 => 文件夹ID
+	maxSize int64
+}
+
+func newLocalImportSource(job *models.ImportJob) (ImportSource, error) {
+	s := &localImportSource{folders: map[string]string{".": job.FolderID}, maxSize: urlImportMaxSize()}
+	if err := json.Unmarshal([]byte(job.Options), &s.state); err != nil {
+		return nil, errors.Wrap(err, errors.CodeInvalidParameter, "解析导入任务选项失败")
+	}
+	return s, nil
+}
+
+// Prepare 压缩包先解压到暂存目录，再扫描目录生成条目；中途重启时重新扫描
+func (s *localImportSource) Prepare(job *models.ImportJob) error {
+	if s.state.Scanned {
+		return nil
+	}
+
+	if s.state.Archive != "" {
+		dst := filepath.Join(importWorkDir(job.ID), "files")
+		os.RemoveAll(dst)
+		cfg := config.GetConfig().Import
+		_, err := archive.Extract(s.state.Archive, dst, archive.Limits{
+			MaxTotalSize: cfg.MaxExtractSize * 1024 * 1024,
+			MaxEntries:   localImportMaxEntries,
+		})
+		if err != nil {
+			if stderrors.Is(err, archive.ErrTooLarge) || stderrors.Is(err, archive.ErrTooManyEntries) || stderrors.Is(err, archive.ErrUnsupported) {
+				return errors.New(errors.CodeInvalidParameter, err.Error())
+			}
+			return errors.Wrap(err, errors.CodeInvalidParameter, "解压失败: "+err.Error())
+		}
+		s.state.Root = dst
+	}
+
+	items, skipped, err := scanImportDir(s.state.Root)
+	if err != nil {
+		return err
+	}
+	s.state.Scanned = true
+	options, _ := json.Marshal(s.state)
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("job_id = ?", job.ID).Delete(&models.ImportJobItem{}).Error; err != nil {
+			return err
+		}
+		for i := range items {
+			items[i].JobID = job.ID
+		}
+		if len(items) > 0 {
+			if err := tx.CreateInBatches(items, localImportBatchSize).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&models.ImportJob{}).Where("id = ?", job.ID).Updates(map[string]interface{}{
+			"options":       string(options),
+			"total_count":   len(items),
+			"success_count": 0,
+			"failed_count":  0,
+			"skipped_count": skipped,
+		}).Error
+	})
+	if err != nil {
+		return errors.Wrap(err, errors.CodeDBCreateFailed, "生成导入条目失败")
+	}
+	return nil
+}
+
+// scanImportDir 按路径顺序列出目录中的文件，忽略符号链接、隐藏文件和 macOS 资源目录
+// 不支持的格式直接记为跳过
+func scanImportDir(root string) ([]models.ImportJobItem, int, error) {
+	var items []models.ImportJobItem
+	skipped := 0
+	allowed := map[string]bool{}
+
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == root {
+			return nil
+		}
+		name := d.Name()
+		if strings.HasPrefix(name, ".") || name == "__MACOSX" {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+		if len(items) >= localImportMaxEntries {
+			return archive.ErrTooManyEntries
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		item := models.ImportJobItem{Seq: len(items), Source: filepath.ToSlash(rel), FileName: name}
+		ext := strings.ToLower(filepath.Ext(name))
+		ok, cached := allowed[ext]
+		if !cached {
+			ok = ext != "" && isValidFileType(ext)
+			allowed[ext] = ok
+		}
+		if !ok {
+			item.Status = models.ImportItemStatusSkipped
+			item.ErrorMsg = "不支持的文件格式"
+			skipped++
+		}
+		items = append(items, item)
+		return nil
+	})
+	if err != nil {
+		if stderrors.Is(err, archive.ErrTooManyEntries) {
+			return nil, 0, errors.New(errors.CodeInvalidParameter, fmt.Sprintf("单个任务最多导入%d个文件", localImportMaxEntries))
+		}
+		return nil, 0, errors.Wrap(err, errors.CodeFileNotFound, "读取导入目录失败")
+	}
+	return items, skipped, nil
+}
+
+// Process 同一文件夹中已有相同内容的文件时跳过，其他文件按普通上传流程处理
+func (s *localImportSource) Process(job *models.ImportJob, item *models.ImportJobItem) (*ImportItemResult, error) {
+	p := filepath.Join(s.state.Root, filepath.FromSlash(item.Source))
+	if !PathWithin(s.state.Root, p) {
+		return nil, errors.New(errors.CodeForbidden, "文件路径无效")
+	}
+	info, err := os.Lstat(p)
+	if err != nil || !info.Mode().IsRegular() {
+		return nil, errors.New(errors.CodeFileNotFound, "文件不存在")
+	}
+	if info.Size() > s.maxSize {
+		return nil, errors.New(errors.CodeFileTooLarge, "文件超过大小限制")
+	}
+
+	folderID, err := s.folderFor(job, path.Dir(item.Source))
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeFileNotFound, "读取文件失败")
+	}
+	opts := ImportFileOptions{
+		AccessLevel: s.state.AccessLevel,
+		Optimize:    s.state.Optimize,
+		ExifPolicy:  s.state.ExifPolicy,
+	}
+	if s.state.PreserveMtime {
+		modTime := info.ModTime()
+		opts.ModTime = &modTime
+	}
+
+	resp, existingID, err := ImportFileData(job.UserID, folderID, info.Name(), data, opts)
+	if err != nil {
+		return nil, err
+	}
+	if existingID != "" {
+		return &ImportItemResult{FileID: existingID, Skipped: true, Reason: "文件已存在"}, nil
+	}
+	return &ImportItemResult{FileID: resp.ID, FileName: resp.OriginalName, Size: resp.Size}, nil
+}
+
+// folderFor 将相对目录映射为目标文件夹下的同名文件夹，不存在时创建
+func (s *localImportSource) folderFor(job *models.ImportJob, dir string) (string, error) {
+	if id, ok := s.folders[dir]; ok {
+		return id, nil
+	}
+	id, err := folder.CreateFolderByPath(job.UserID, path.Join(s.state.FolderPath, dir))
+	if err != nil {
+		return "", err
+	}
+	s.folders[dir] = id
+	return id, nil
+}
+
+// Cleanup 删除暂存目录中的压缩包和解压文件，服务器上的原始目录和压缩包不受影响
+func (s *localImportSource) Cleanup(job *models.ImportJob) {
+	dir := importWorkDir(job.ID)
+	if _, err := os.Stat(dir); err != nil {
+		return
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		logger.Warn("删除导入暂存目录失败: job_id=%s, %v", job.ID, err)
+	}
+}
@@ -86,9 +86,10 @@ type AdminFileDetailResponse struct {
 	UserName        string           `json:"user_name,omitempty"`
 	UserInfo        interface{}      `json:"user_info,omitempty"` // 用户详细信息
 	AIInfo          *AIInfoResponse  `json:"ai_info,omitempty"`
-	StorageDuration string           `json:"storage_duration"` // 存储时长：3d/7d/30d/permanent
-	ExpiresAt       *common.JSONTime `json:"expires_at"`       // 过期时间
-	IsTimeLimited   bool             `json:"is_time_limited"`  // 是否为限时存储
+	StorageDuration string           `json:"storage_duration"`   // 存储时长：3d/7d/30d/permanent
+	ExpiresAt       *common.JSONTime `json:"expires_at"`         // 过期时间
+	IsTimeLimited   bool             `json:"is_time_limited"`    // 是否为限时存储
+	TakenAt         *common.JSONTime `json:"taken_at,omitempty"` // 拍摄时间
 }
 
 /* TagWithCount 带有计数的标签结构 */
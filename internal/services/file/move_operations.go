@@ -5,6 +5,7 @@ import (
 	"pixelpunk/internal/models"
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/errors"
+	"strconv"
 	"strings"
 
 	"gorm.io/gorm"
@@ -93,10 +94,11 @@ func ReorderFiles(userID uint, folderID string, fileIDs []string) error {
 	sqlBuilder := strings.Builder{}
 	sqlBuilder.WriteString("UPDATE file SET sort_order = CASE id ")
 
-	args := make([]interface{}, 0, len(fileIDs)*2+1)
+	// 排序值直接写入 SQL：PostgreSQL 无法推断 THEN 后参数的类型，会按文本处理
+	args := make([]interface{}, 0, len(fileIDs)+1)
 	for i, fileID := range fileIDs {
-		sqlBuilder.WriteString("WHEN ? THEN ? ")
-		args = append(args, fileID, i+1)
+		sqlBuilder.WriteString("WHEN ? THEN " + strconv.Itoa(i+1) + " ")
+		args = append(args, fileID)
 	}
 	sqlBuilder.WriteString("END WHERE id IN (?)")
 	args = append(args, fileIDs)
@@ -65,5 +65,6 @@ func BuildAdminFileDetailResponse(file models.File, views int64, userName string
 		StorageDuration:   file.StorageDuration,
 		ExpiresAt:         (*common.JSONTime)(file.ExpiresAt),
 		IsTimeLimited:     file.IsTimeLimitedStorage(),
+		TakenAt:           (*common.JSONTime)(file.TakenAt),
 	}
 }
@@ -0,0 +1,321 @@
+package file
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/setting"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	signedLinkMaxBatch         = 100       // 单次最多签发的链接数
+	signedLinkDefaultExpire    = 3600      // 默认有效期(秒)
+	signedLinkDefaultMaxExpire = 7 * 86400 // 默认最长有效期(秒)，可通过 security.signed_url_max_expire 调整
+	signedLinkMaxDimension     = 4096      // 变换最大边长
+
+	// 续传窗口：上次计数访问后该时间内的续传 Range 请求不再计数，超出窗口按新的下载计数
+	signedLinkResumeWindow = 10 * time.Minute
+)
+
+/* SignedLinkOptions 签发签名链接的参数 */
+type SignedLinkOptions struct {
+	FileIDs      []string
+	Kind         string // f/t/s，默认f
+	ExpiresIn    int    // 有效期(秒)
+	BindIP       string // 绑定IP或CIDR
+	MaxDownloads int
+	Width        int
+	Height       int
+	Quality      int
+	Format       string
+	Note         string
+}
+
+/* SignedLinkResult 单个签名链接的签发结果 */
+type SignedLinkResult struct {
+	FileID    string    `json:"file_id"`
+	LinkID    string    `json:"link_id,omitempty"`
+	URL       string    `json:"url,omitempty"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+/* MintSignedLinks 批量签发签名链接，单个文件失败不影响其余文件 */
+func MintSignedLinks(userID uint, apiKeyID string, opts SignedLinkOptions) ([]SignedLinkResult, error) {
+	if err := normalizeSignedLinkOptions(&opts); err != nil {
+		return nil, err
+	}
+
+	var files []models.File
+	if err := database.DB.Where("id IN ? AND user_id = ?", opts.FileIDs, userID).
+		Where("status <> ?", "pending_deletion").
+		Find(&files).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询文件失败")
+	}
+	fileMap := make(map[string]models.File, len(files))
+	for _, f := range files {
+		fileMap[f.ID] = f
+	}
+
+	expiresAt := time.Now().Add(time.Duration(opts.ExpiresIn) * time.Second)
+	signer := utils.GetURLSigner()
+	results := make([]SignedLinkResult, 0, len(opts.FileIDs))
+
+	for _, fileID := range opts.FileIDs {
+		file, ok := fileMap[fileID]
+		if !ok {
+			results = append(results, SignedLinkResult{FileID: fileID, Error: "文件不存在或无权访问"})
+			continue
+		}
+
+		kind := opts.Kind
+		identifier := file.ID
+		if kind == models.SignedLinkKindShort {
+			if file.ShortURL == "" {
+				kind = models.SignedLinkKindFile
+			} else {
+				identifier = file.ShortURL
+			}
+		}
+
+		link := models.SignedLink{
+			ID:           strings.ReplaceAll(uuid.New().String(), "-", ""),
+			FileID:       file.ID,
+			UserID:       userID,
+			APIKeyID:     apiKeyID,
+			Kind:         kind,
+			ExpiresAt:    common.JSONTime(expiresAt),
+			BindIP:       opts.BindIP,
+			MaxDownloads: opts.MaxDownloads,
+			Width:        opts.Width,
+			Height:       opts.Height,
+			Quality:      opts.Quality,
+			Format:       opts.Format,
+			Note:         opts.Note,
+		}
+		if err := database.DB.Create(&link).Error; err != nil {
+			results = append(results, SignedLinkResult{FileID: fileID, Error: "保存签名链接失败"})
+			continue
+		}
+
+		params := signedParamsFromLink(&link, identifier)
+		query := params.Query(signer.SignParams(params))
+		results = append(results, SignedLinkResult{
+			FileID:    fileID,
+			LinkID:    link.ID,
+			URL:       utils.GetSystemFileURL(fmt.Sprintf("/%s/%s?%s", kind, identifier, query.Encode())),
+			ExpiresAt: expiresAt,
+		})
+	}
+
+	return results, nil
+}
+
+func normalizeSignedLinkOptions(opts *SignedLinkOptions) error {
+	seen := make(map[string]bool, len(opts.FileIDs))
+	ids := make([]string, 0, len(opts.FileIDs))
+	for _, id := range opts.FileIDs {
+		id = strings.TrimSpace(id)
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return errors.New(errors.CodeInvalidParameter, "文件ID不能为空")
+	}
+	if len(ids) > signedLinkMaxBatch {
+		return errors.New(errors.CodeInvalidParameter, fmt.Sprintf("单次最多签发%d个链接", signedLinkMaxBatch))
+	}
+	opts.FileIDs = ids
+
+	switch opts.Kind {
+	case "":
+		opts.Kind = models.SignedLinkKindFile
+	case models.SignedLinkKindFile, models.SignedLinkKindThumb, models.SignedLinkKindShort:
+	default:
+		return errors.New(errors.CodeInvalidParameter, "链接类型必须是f、t或s")
+	}
+
+	maxExpire := setting.GetInt("security", "signed_url_max_expire", signedLinkDefaultMaxExpire)
+	if opts.ExpiresIn <= 0 {
+		opts.ExpiresIn = signedLinkDefaultExpire
+	}
+	if opts.ExpiresIn > maxExpire {
+		return errors.New(errors.CodeInvalidParameter, fmt.Sprintf("有效期不能超过%d秒", maxExpire))
+	}
+
+	if opts.BindIP != "" {
+		if _, _, err := net.ParseCIDR(opts.BindIP); err != nil && net.ParseIP(opts.BindIP) == nil {
+			return errors.New(errors.CodeInvalidParameter, "绑定IP格式无效")
+		}
+	}
+
+	if opts.Width > signedLinkMaxDimension || opts.Height > signedLinkMaxDimension {
+		return errors.New(errors.CodeInvalidParameter, fmt.Sprintf("变换尺寸不能超过%d", signedLinkMaxDimension))
+	}
+	opts.Format = strings.ToLower(opts.Format)
+	if opts.Format == "jpg" {
+		opts.Format = "jpeg"
+	}
+	if opts.Format != "" && opts.Format != "jpeg" && opts.Format != "png" {
+		return errors.New(errors.CodeInvalidParameter, "变换格式仅支持jpeg或png")
+	}
+	return nil
+}
+
+func signedParamsFromLink(link *models.SignedLink, identifier string) *utils.SignedURLParams {
+	return &utils.SignedURLParams{
+		Resource:     utils.SignedResource(link.Kind, identifier),
+		Expires:      time.Time(link.ExpiresAt).Unix(),
+		LinkID:       link.ID,
+		IP:           link.BindIP,
+		MaxDownloads: link.MaxDownloads,
+		Width:        link.Width,
+		Height:       link.Height,
+		Quality:      link.Quality,
+		Format:       link.Format,
+	}
+}
+
+/* VerifySignedLink 校验签名链接：签名、过期、吊销、IP绑定与下载次数，返回本次是否计为一次下载
+ * continuation 表示请求为续传 Range（见 utils.IsContinuationRange），仅在上次计数访问后的续传窗口内不计数；
+ * last_access_at 只在计数时更新，续传无法无限延长窗口 */
+func VerifySignedLink(kind, identifier, fileID string, query url.Values, clientIP string, continuation bool) (*models.SignedLink, bool, error) {
+	params, signature, err := utils.ParseSignedURLParams(utils.SignedResource(kind, identifier), query)
+	if err != nil {
+		return nil, false, errors.New(errors.CodeInvalidParameter, err.Error())
+	}
+	if !utils.GetURLSigner().VerifyParams(params, signature) {
+		return nil, false, errors.New(errors.CodeForbidden, "签名无效")
+	}
+	if time.Now().Unix() > params.Expires {
+		return nil, false, errors.New(errors.CodeForbidden, "链接已过期")
+	}
+	if params.IP != "" && !matchBindIP(params.IP, clientIP) {
+		return nil, false, errors.New(errors.CodeForbidden, "当前IP无权访问该链接")
+	}
+
+	var link models.SignedLink
+	if err := database.DB.Where("id = ?", params.LinkID).First(&link).Error; err != nil {
+		return nil, false, errors.New(errors.CodeForbidden, "链接不存在")
+	}
+	if link.FileID != fileID || link.Kind != kind {
+		return nil, false, errors.New(errors.CodeForbidden, "链接与文件不匹配")
+	}
+	if link.IsRevoked() {
+		return nil, false, errors.New(errors.CodeForbidden, "链接已被吊销")
+	}
+
+	if !signedLinkCountsAccess(&link, continuation, time.Now()) {
+		return &link, false, nil
+	}
+
+	now := common.JSONTime(time.Now())
+	result := database.DB.Model(&models.SignedLink{}).
+		Where("id = ? AND (max_downloads = 0 OR download_count < max_downloads)", link.ID).
+		Updates(map[string]interface{}{
+			"download_count": gorm.Expr("download_count + 1"),
+			"last_access_at": now,
+		})
+	if result.Error != nil {
+		return nil, false, errors.Wrap(result.Error, errors.CodeDBUpdateFailed, "更新链接访问次数失败")
+	}
+	if result.RowsAffected == 0 {
+		return nil, false, errors.New(errors.CodeForbidden, "链接访问次数已用尽")
+	}
+	link.DownloadCount++
+	link.LastAccessAt = &now
+
+	return &link, true, nil
+}
+
+/* signedLinkCountsAccess 本次访问是否需要计数：只有已计数过、且仍在上次计数访问后续传窗口内的续传请求免计数 */
+func signedLinkCountsAccess(link *models.SignedLink, continuation bool, now time.Time) bool {
+	if !continuation || link.DownloadCount == 0 || link.LastAccessAt == nil {
+		return true
+	}
+	return now.Sub(time.Time(*link.LastAccessAt)) > signedLinkResumeWindow
+}
+
+func matchBindIP(bind, clientIP string) bool {
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return false
+	}
+	if _, network, err := net.ParseCIDR(bind); err == nil {
+		return network.Contains(ip)
+	}
+	bound := net.ParseIP(bind)
+	return bound != nil && bound.Equal(ip)
+}
+
+/* ListSignedLinks 分页查询用户签发的签名链接 */
+func ListSignedLinks(userID uint, fileID string, activeOnly bool, page, size int) ([]models.SignedLink, int64, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 {
+		size = common.DefaultPageSize
+	}
+	if size > common.MaxPageSize {
+		size = common.MaxPageSize
+	}
+
+	query := database.DB.Model(&models.SignedLink{}).Where("user_id = ?", userID)
+	if fileID != "" {
+		query = query.Where("file_id = ?", fileID)
+	}
+	if activeOnly {
+		query = query.Where("revoked_at IS NULL AND expires_at > ?", time.Now())
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询签名链接总数失败")
+	}
+
+	var links []models.SignedLink
+	if err := query.Order("created_at DESC").Offset((page - 1) * size).Limit(size).Find(&links).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询签名链接失败")
+	}
+	return links, total, nil
+}
+
+/* RevokeSignedLink 吊销单个签名链接 */
+func RevokeSignedLink(userID uint, linkID string) error {
+	var link models.SignedLink
+	if err := database.DB.Where("id = ? AND user_id = ?", linkID, userID).First(&link).Error; err != nil {
+		return errors.New(errors.CodeNotFound, "签名链接不存在")
+	}
+	if link.IsRevoked() {
+		return nil
+	}
+
+	now := common.JSONTime(time.Now())
+	if err := database.DB.Model(&link).Update("revoked_at", now).Error; err != nil {
+		return errors.Wrap(err, errors.CodeDBUpdateFailed, "吊销签名链接失败")
+	}
+	return nil
+}
+
+/* CleanupExpiredSignedLinks 删除过期超过指定天数的签名链接记录 */
+func CleanupExpiredSignedLinks(retainDays int) (int64, error) {
+	if retainDays <= 0 {
+		retainDays = 30
+	}
+	cutoff := time.Now().AddDate(0, 0, -retainDays)
+	result := database.DB.Where("expires_at < ?", cutoff).Delete(&models.SignedLink{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,56 @@
+package file
+
+import (
+	"testing"
+	"time"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/utils"
+)
+
+func TestSignedLinkCountsAccess(t *testing.T) {
+	now := time.Now()
+	recent := common.JSONTime(now.Add(-time.Minute))
+	stale := common.JSONTime(now.Add(-signedLinkResumeWindow - time.Minute))
+
+	cases := []struct {
+		name         string
+		link         models.SignedLink
+		continuation bool
+		want         bool
+	}{
+		{"full request", models.SignedLink{DownloadCount: 1, LastAccessAt: &recent}, false, true},
+		{"continuation before first download", models.SignedLink{}, true, true},
+		{"continuation inside window", models.SignedLink{DownloadCount: 1, LastAccessAt: &recent}, true, false},
+		{"continuation after window", models.SignedLink{DownloadCount: 1, LastAccessAt: &stale}, true, true},
+	}
+	for _, tc := range cases {
+		if got := signedLinkCountsAccess(&tc.link, tc.continuation, now); got != tc.want {
+			t.Errorf("%s: signedLinkCountsAccess = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+// 下载次数用尽后，后缀范围和窗口外的续传都必须计数，从而被次数限制拦下
+func TestSignedLinkLimitHoldsForRangeRequests(t *testing.T) {
+	const size = 4096
+	now := time.Now()
+	stale := common.JSONTime(now.Add(-signedLinkResumeWindow - time.Second))
+	recent := common.JSONTime(now.Add(-time.Second))
+
+	for _, header := range []string{"bytes=-4096", "bytes=-1", "bytes=0-", "bytes=5-9,0-"} {
+		link := models.SignedLink{MaxDownloads: 1, DownloadCount: 1, LastAccessAt: &recent}
+		if !signedLinkCountsAccess(&link, utils.IsContinuationRange(header, size), now) {
+			t.Errorf("%q should be counted even right after a download", header)
+		}
+		if !link.IsExhausted() {
+			t.Errorf("%q: link should be exhausted", header)
+		}
+	}
+
+	link := models.SignedLink{MaxDownloads: 1, DownloadCount: 1, LastAccessAt: &stale}
+	if !signedLinkCountsAccess(&link, utils.IsContinuationRange("bytes=1-", size), now) {
+		t.Error(`"bytes=1-" outside the resume window should be counted`)
+	}
+}
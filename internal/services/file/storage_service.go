@@ -108,6 +108,11 @@ func convertToNewStorageRequest(ctx *UploadContext) *newstorage.UploadRequest {
 
 	logger.Info("[WebP调试] 转换前状态: webpEnabled=%v, processedData大小=%d", webpEnabled, len(processedData))
 
+	// 视频和文档不做图片转换
+	if ctx.VideoInfo != nil || ctx.DocumentInfo != nil {
+		webpEnabled = false
+	}
+
 	// 在调用存储之前进行 WebP 转换
 	if webpEnabled && len(processedData) > 0 {
 		logger.Info("[WebP调试] 开始转换(有预处理数据)...")
@@ -192,6 +197,11 @@ func convertToNewStorageRequest(ctx *UploadContext) *newstorage.UploadRequest {
 		}
 	}
 
+	if ctx.VideoInfo != nil || ctx.DocumentInfo != nil {
+		req.Compress = false
+		req.ThumbnailData, req.ThumbnailFormat = posterThumbnail(ctx, req.ThumbWidth, req.ThumbHeight, req.ThumbQuality)
+	}
+
 	return req
 }
 
@@ -0,0 +1,369 @@
+package file
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/folder"
+	"pixelpunk/internal/services/setting"
+	"pixelpunk/internal/services/stats"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/imagex/formats"
+	"pixelpunk/pkg/logger"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	TusVersion            = "1.0.0"
+	TusExtensions         = "creation,termination,checksum,expiration"
+	TusChecksumAlgorithms = "md5,sha1,sha256"
+)
+
+// tusLocks 同一上传的 PATCH 请求串行处理，避免并发写入同一偏移
+var tusLocks sync.Map
+
+func lockTusUpload(sessionID string) func() {
+	v, _ := tusLocks.LoadOrStore(sessionID, &sync.Mutex{})
+	mu := v.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
+/* TusMaxSize tus 上传允许的最大文件大小，取系统设置中的最大文件大小 */
+func TusMaxSize() int64 {
+	maxFileSizeMB, err := setting.GetNumberValue("max_file_size", 100.0)
+	if err != nil {
+		maxFileSizeMB = 100.0
+	}
+	return int64(maxFileSizeMB * 1024 * 1024)
+}
+
+/* CreateTusUpload 创建 tus 上传，文件名、目录等参数来自 Upload-Metadata
+ * 通过API密钥创建时 key 不为nil，按密钥的限制和默认目录处理 */
+func CreateTusUpload(userID uint, key *models.APIKey, length int64, rawMetadata string) (*models.UploadSession, error) {
+	chunkedUploadEnabled, err := setting.GetBoolValue("chunked_upload_enabled", false)
+	if err != nil {
+		logger.Error("获取分片上传开关配置失败: %v", err)
+		return nil, errors.New(errors.CodeInternal, "获取分片上传配置失败")
+	}
+	if !chunkedUploadEnabled {
+		return nil, errors.New(errors.CodeForbidden, "分片上传功能已禁用")
+	}
+
+	meta, err := ParseTusMetadata(rawMetadata)
+	if err != nil {
+		return nil, err
+	}
+	fileName := meta["filename"]
+	if fileName == "" {
+		fileName = meta["name"]
+	}
+	fileName = filepath.Base(strings.ReplaceAll(fileName, "\\", "/"))
+	if fileName == "" || fileName == "." || fileName == "/" || len(fileName) > 255 {
+		return nil, errors.New(errors.CodeInvalidParameter, "Upload-Metadata 中缺少有效的 filename")
+	}
+	ext := strings.ToLower(filepath.Ext(fileName))
+	if !isValidFileType(ext) {
+		return nil, errors.New(errors.CodeFileTypeNotSupported, "不支持的文件格式")
+	}
+
+	if length <= 0 {
+		return nil, errors.New(errors.CodeInvalidParameter, "Upload-Length 必须大于0")
+	}
+	if maxFileSize := TusMaxSize(); length > maxFileSize {
+		return nil, errors.New(errors.CodeFileTooLarge, fmt.Sprintf("文件大小不能超过 %d 字节", maxFileSize))
+	}
+
+	accessLevel := meta["access_level"]
+	if accessLevel != "" && accessLevel != "public" && accessLevel != "private" && accessLevel != "protected" {
+		return nil, errors.New(errors.CodeInvalidParameter, "访问级别必须是 public、private 或 protected")
+	}
+	exifPolicy := meta["exif_policy"]
+	if exifPolicy != "" && !models.IsValidExifPolicy(exifPolicy) {
+		return nil, errors.New(errors.CodeInvalidParameter, "元数据策略必须是 keep、strip_gps、strip_serial 或 strip_all")
+	}
+
+	var folderID, apiKeyID string
+	if key != nil {
+		if err := validateSingleFileLimits(key, length); err != nil {
+			return nil, err
+		}
+		if folderID, err = determineTargetFolder(key, meta["folder_id"], meta["file_path"]); err != nil {
+			return nil, err
+		}
+		apiKeyID = key.ID
+	} else if meta["file_path"] != "" {
+		if folderID, err = folder.CreateFolderByPath(userID, meta["file_path"]); err != nil {
+			return nil, err
+		}
+	} else {
+		folderID = meta["folder_id"]
+	}
+
+	available, err := stats.CheckUserStorageAvailable(userID, length)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeInternal, "检查用户存储空间失败")
+	}
+	if !available {
+		return nil, errors.New(errors.CodeStorageLimitExceeded, "存储空间不足，无法上传文件")
+	}
+
+	mimeType := meta["filetype"]
+	if mimeType == "" {
+		mimeType = meta["type"]
+	}
+	if mimeType == "" || len(mimeType) > 50 {
+		mimeType = formats.GetContentType(ext)
+	}
+
+	sessionTimeoutHours, err := setting.GetNumberValue("session_timeout", 24.0)
+	if err != nil {
+		logger.Error("获取会话超时时间配置失败: %v", err)
+		sessionTimeoutHours = 24.0
+	}
+
+	session := &models.UploadSession{
+		SessionID:      strings.ReplaceAll(uuid.New().String(), "-", ""),
+		UserID:         userID,
+		FileName:       fileName,
+		FileSize:       length,
+		MimeType:       mimeType,
+		Status:         "pending",
+		FolderID:       folderID,
+		AccessLevel:    accessLevel,
+		Optimize:       meta["optimize"] == "true" || meta["optimize"] == "1",
+		ExifPolicy:     exifPolicy,
+		Protocol:       models.UploadProtocolTus,
+		UploadMetadata: rawMetadata,
+		APIKeyID:       apiKeyID,
+		ExpiresAt:      time.Now().Add(time.Duration(sessionTimeoutHours) * time.Hour),
+	}
+	if err := database.DB.Create(session).Error; err != nil {
+		logger.Error("创建tus上传会话失败: %v", err)
+		return nil, errors.Wrap(err, errors.CodeInternal, "创建上传会话失败")
+	}
+
+	if err := os.MkdirAll(filepath.Join("temp", "chunks", session.SessionID), 0755); err != nil {
+		logger.Error("创建临时存储目录失败: %v", err)
+		return nil, errors.Wrap(err, errors.CodeInternal, "创建临时目录失败")
+	}
+	return session, nil
+}
+
+/* GetTusUpload 查询当前用户的 tus 上传，已终止的上传视为不存在 */
+func GetTusUpload(userID uint, sessionID string) (*models.UploadSession, error) {
+	var session models.UploadSession
+	err := database.DB.Where("session_id = ? AND protocol = ?", sessionID, models.UploadProtocolTus).First(&session).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New(errors.CodeNotFound, "上传不存在")
+		}
+		return nil, errors.Wrap(err, errors.CodeInternal, "查询上传会话失败")
+	}
+	if session.UserID != userID || (!session.IsActive() && !session.IsCompleted()) {
+		return nil, errors.New(errors.CodeNotFound, "上传不存在")
+	}
+	if session.IsActive() && session.IsExpired() {
+		return nil, errors.New(errors.CodeUploadSessionExpired, "上传已过期")
+	}
+	return &session, nil
+}
+
+/* WriteTusChunk 在指定偏移追加一段数据，保存为新的分片
+ * 没有校验和时连接中断前收到的数据也会保留，客户端可从新的偏移续传；
+ * 数据全部到达后立即走分片上传的合并和处理流程，返回生成的文件 */
+func WriteTusChunk(userID uint, sessionID string, offset int64, body io.Reader, checksum string) (*models.UploadSession, *FileDetailResponse, error) {
+	unlock := lockTusUpload(sessionID)
+	defer unlock()
+
+	session, err := GetTusUpload(userID, sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if offset != session.UploadOffset {
+		return session, nil, errors.New(errors.CodeConflict, fmt.Sprintf("Upload-Offset 不匹配，当前偏移为 %d", session.UploadOffset))
+	}
+	if session.IsCompleted() {
+		return session, nil, nil
+	}
+
+	var hasher hash.Hash
+	var expected []byte
+	if checksum != "" {
+		if hasher, expected, err = parseTusChecksum(checksum); err != nil {
+			return session, nil, err
+		}
+	}
+
+	chunkPath := filepath.Join("temp", "chunks", sessionID, fmt.Sprintf("chunk_%d", session.TotalChunks))
+	n, readErr := writeTusChunkFile(chunkPath+".part", body, session.FileSize-offset, hasher)
+	if n > session.FileSize-offset {
+		os.Remove(chunkPath + ".part")
+		return session, nil, errors.New(errors.CodeInvalidParameter, "数据超出 Upload-Length")
+	}
+	if hasher != nil && (readErr != nil || !bytes.Equal(hasher.Sum(nil), expected)) {
+		os.Remove(chunkPath + ".part")
+		if readErr != nil {
+			return session, nil, errors.Wrap(readErr, errors.CodeChunkUploadFailed, "接收数据中断")
+		}
+		return session, nil, errors.New(errors.CodeValidationFailed, "Upload-Checksum 校验失败")
+	}
+	if n == 0 {
+		os.Remove(chunkPath + ".part")
+		if readErr != nil {
+			return session, nil, errors.Wrap(readErr, errors.CodeChunkUploadFailed, "接收数据中断")
+		}
+		return session, nil, nil
+	}
+	if err := os.Rename(chunkPath+".part", chunkPath); err != nil {
+		return session, nil, errors.Wrap(err, errors.CodeInternal, "保存分片文件失败")
+	}
+
+	if err := commitTusChunk(session, chunkPath, n); err != nil {
+		os.Remove(chunkPath)
+		return session, nil, err
+	}
+	if readErr != nil {
+		return session, nil, errors.Wrap(readErr, errors.CodeChunkUploadFailed, "接收数据中断")
+	}
+	if session.UploadOffset < session.FileSize {
+		return session, nil, nil
+	}
+
+	resp, err := completeTusUpload(session)
+	return session, resp, err
+}
+
+// writeTusChunkFile 最多多读一个字节，用于判断请求体是否超出剩余长度
+func writeTusChunkFile(path string, body io.Reader, remaining int64, hasher hash.Hash) (int64, error) {
+	dst, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	defer dst.Close()
+	var w io.Writer = dst
+	if hasher != nil {
+		w = io.MultiWriter(dst, hasher)
+	}
+	return io.Copy(w, io.LimitReader(body, remaining+1))
+}
+
+func commitTusChunk(session *models.UploadSession, chunkPath string, size int64) error {
+	chunk := &models.UploadChunk{
+		SessionID:   session.SessionID,
+		ChunkNumber: session.TotalChunks,
+		ChunkSize:   size,
+		Status:      "uploaded",
+		StoragePath: chunkPath,
+	}
+	session.TotalChunks++
+	session.UploadOffset += size
+	session.Progress = int(session.UploadOffset * 100 / session.FileSize)
+	session.Status = "uploading"
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(chunk).Error; err != nil {
+			return errors.Wrap(err, errors.CodeInternal, "创建分片记录失败")
+		}
+		if err := tx.Model(&models.UploadSession{}).Where("id = ?", session.ID).Updates(map[string]interface{}{
+			"total_chunks":  session.TotalChunks,
+			"upload_offset": session.UploadOffset,
+			"progress":      session.Progress,
+			"status":        session.Status,
+		}).Error; err != nil {
+			return errors.Wrap(err, errors.CodeInternal, "更新上传会话失败")
+		}
+		return nil
+	})
+}
+
+/* completeTusUpload 合并分片并处理文件，处理失败的上传直接终止，客户端需要重新上传 */
+func completeTusUpload(session *models.UploadSession) (*FileDetailResponse, error) {
+	resp, err := CompleteChunkedUpload(session.SessionID)
+	tusLocks.Delete(session.SessionID)
+	if err != nil {
+		logger.Warn("tus上传处理失败: session_id=%s, %v", session.SessionID, err)
+		if cancelErr := CancelChunkedUpload(session.SessionID); cancelErr != nil {
+			logger.Error("终止tus上传失败: %v", cancelErr)
+		}
+		return nil, err
+	}
+
+	session.Status = "completed"
+	session.Progress = 100
+	session.FileID = resp.ID
+	if session.APIKeyID != "" {
+		if err := associateFileWithAPIKey(resp.ID, session.APIKeyID); err != nil {
+			logger.Error("更新文件API密钥关联失败", "fileID", resp.ID, "error", err)
+		}
+		go updateAPIKeyUsageAsync(session.APIKeyID, session.FileSize)
+	}
+	return resp, nil
+}
+
+/* TerminateTusUpload 终止上传并删除已接收的数据 */
+func TerminateTusUpload(userID uint, sessionID string) error {
+	unlock := lockTusUpload(sessionID)
+	defer unlock()
+
+	session, err := GetTusUpload(userID, sessionID)
+	if err != nil && !errors.Is(err, errors.CodeUploadSessionExpired) {
+		return err
+	}
+	if session != nil && session.IsCompleted() {
+		return errors.New(errors.CodeConflict, "上传已完成，无法终止")
+	}
+	tusLocks.Delete(sessionID)
+	return CancelChunkedUpload(sessionID)
+}
+
+/* ParseTusMetadata 解析 Upload-Metadata：逗号分隔的键值对，值为 Base64 编码，可以省略 */
+func ParseTusMetadata(raw string) (map[string]string, error) {
+	meta := make(map[string]string)
+	for _, pair := range strings.Split(raw, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, encoded, _ := strings.Cut(pair, " ")
+		value, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil || key == "" {
+			return nil, errors.New(errors.CodeInvalidParameter, "Upload-Metadata 格式错误")
+		}
+		meta[key] = string(value)
+	}
+	return meta, nil
+}
+
+// parseTusChecksum 解析 Upload-Checksum：算法名和 Base64 编码的摘要
+func parseTusChecksum(header string) (hash.Hash, []byte, error) {
+	algorithm, encoded, _ := strings.Cut(strings.TrimSpace(header), " ")
+	sum, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(sum) == 0 {
+		return nil, nil, errors.New(errors.CodeInvalidParameter, "Upload-Checksum 格式错误")
+	}
+	switch strings.ToLower(algorithm) {
+	case "md5":
+		return md5.New(), sum, nil
+	case "sha1":
+		return sha1.New(), sum, nil
+	case "sha256":
+		return sha256.New(), sum, nil
+	}
+	return nil, nil, errors.New(errors.CodeInvalidParameter, "不支持的校验算法: "+algorithm)
+}
@@ -0,0 +1,38 @@
+package file
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+)
+
+func TestParseTusMetadata(t *testing.T) {
+	meta, err := ParseTusMetadata("filename 5rWL6K+VLnBuZw==, filetype aW1hZ2UvcG5n,optimize")
+	if err != nil {
+		t.Fatalf("ParseTusMetadata: %v", err)
+	}
+	if meta["filename"] != "测试.png" || meta["filetype"] != "image/png" {
+		t.Errorf("unexpected metadata %v", meta)
+	}
+	if v, ok := meta["optimize"]; !ok || v != "" {
+		t.Errorf("key without value should map to empty string, got %q, %v", v, ok)
+	}
+	if _, err := ParseTusMetadata("filename not-base64!"); err == nil {
+		t.Error("invalid base64 value should be rejected")
+	}
+}
+
+func TestParseTusChecksum(t *testing.T) {
+	sum := sha256.Sum256([]byte("data"))
+	h, expected, err := parseTusChecksum("sha256 " + base64.StdEncoding.EncodeToString(sum[:]))
+	if err != nil {
+		t.Fatalf("parseTusChecksum: %v", err)
+	}
+	h.Write([]byte("data"))
+	if string(h.Sum(nil)) != string(expected) {
+		t.Error("digest mismatch")
+	}
+	if _, _, err := parseTusChecksum("crc32 AAAAAA=="); err == nil {
+		t.Error("unsupported algorithm should be rejected")
+	}
+}
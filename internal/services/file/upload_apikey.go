@@ -14,6 +14,7 @@ import (
 	"pixelpunk/internal/services/stats"
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/hooks"
 	"pixelpunk/pkg/logger"
 	"pixelpunk/pkg/storage/middleware"
 	"pixelpunk/pkg/utils"
@@ -184,6 +185,7 @@ func uploadValidFiles(c *gin.Context, key *models.APIKey, folderID, accessLevel
 		if err := associateFileWithAPIKey(imgInfo.ID, key.ID); err != nil {
 			logger.Error("更新文件API密钥关联失败", "fileID", imgInfo.ID, "error", err)
 		}
+		hooks.TriggerFileEvent(hooks.FileEventUploaded, imgInfo.ID)
 
 		responses = append(responses, imgInfo)
 		go updateAPIKeyUsageAsync(key.ID, file.Size)
@@ -216,7 +218,7 @@ func processSingleFileUpload(c *gin.Context, key *models.APIKey, folderID, acces
 		IsSingleUpload: true,
 	}
 
-	if err := validateSingleFileLimits(key, file); err != nil {
+	if err := validateSingleFileLimits(key, file.Size); err != nil {
 		return result, err
 	}
 
@@ -230,6 +232,7 @@ func processSingleFileUpload(c *gin.Context, key *models.APIKey, folderID, acces
 	if err := associateFileWithAPIKey(imgInfo.ID, key.ID); err != nil {
 		logger.Error("更新文件API密钥关联失败", "fileID", imgInfo.ID, "error", err)
 	}
+	hooks.TriggerFileEvent(hooks.FileEventUploaded, imgInfo.ID)
 
 	go updateAPIKeyUsageAsync(key.ID, file.Size)
 
@@ -238,12 +241,12 @@ func processSingleFileUpload(c *gin.Context, key *models.APIKey, folderID, acces
 	return result, nil
 }
 
-func validateSingleFileLimits(key *models.APIKey, file *multipart.FileHeader) error {
-	if key.SingleFileLimit > 0 && file.Size > key.SingleFileLimit {
+func validateSingleFileLimits(key *models.APIKey, size int64) error {
+	if key.SingleFileLimit > 0 && size > key.SingleFileLimit {
 		return errors.New(errors.CodeFileTooLarge, fmt.Sprintf("文件大小超过API密钥限制(%.1fMB)", float64(key.SingleFileLimit)/1024/1024))
 	}
 
-	if key.StorageLimit > 0 && key.StorageUsed+file.Size > key.StorageLimit {
+	if key.StorageLimit > 0 && key.StorageUsed+size > key.StorageLimit {
 		return errors.New(errors.CodeStorageLimitExceeded, "API密钥存储容量已用尽")
 	}
 
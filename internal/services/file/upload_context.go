@@ -8,7 +8,9 @@ import (
 	"pixelpunk/internal/services/setting"
 	"pixelpunk/internal/services/stats"
 	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/document"
 	pkgStorage "pixelpunk/pkg/storage"
+	"pixelpunk/pkg/video"
 	"strings"
 	"time"
 
@@ -132,13 +134,24 @@ type UploadContext struct {
 	WatermarkWrapper       interface{} // 水印处理后的文件包装器（内部使用）
 	WatermarkApplied       bool        // 水印是否成功应用
 	WatermarkFailureReason string      // 水印失败原因
+	WatermarkDeferred      bool        // 水印配置保存到文件记录，访问时叠加
 	OriginalFileData       []byte      // 原始文件数据（一次性读取，供多次使用）
 
 	WebPEnabled *bool // WebP转换开关（nil表示使用全局配置）
 	WebPQuality *int  // WebP转换质量（nil表示使用全局配置）
 
-	EXIFData  *models.FileEXIF // 提取的 EXIF 元数据
-	FileModel *models.File     // 文件模型（用于后续操作）
+	EXIFData          *models.FileEXIF // 提取的 EXIF 元数据
+	ExifPolicy        string           // 元数据策略，上传参数为空时按用户设置和系统设置确定
+	ExifPolicyApplied bool             // 元数据策略已应用到 OriginalFileData
+	FileModel         *models.File     // 文件模型（用于后续操作）
+
+	VideoInfo   *video.Info // 视频容器解析结果，非视频文件为nil
+	VideoPoster []byte      // 视频封面（JPEG），用于生成缩略图
+
+	DocumentInfo    *document.Info // 文档解析结果，非文档文件为nil
+	DocumentPreview []byte         // 文档首页预览（JPEG），用于生成缩略图
+
+	ModTime *time.Time // 服务器端导入时保留的源文件修改时间，用作创建时间
 }
 
 /* CreateUploadContext 创建一个新的上传上下文 */
@@ -160,6 +173,10 @@ func CreateUploadContextWithDuration(c *gin.Context, userID uint, file *multipar
 		IsGuestUpload:   userID == 0, // 用户ID为0表示游客
 	}
 
+	if c != nil {
+		ctx.ExifPolicy = c.PostForm("exif_policy")
+	}
+
 	if ctx.IsGuestUpload {
 		ctx.GuestIP = getClientIP(c)
 		ctx.GuestUserAgent = c.GetHeader("User-Agent")
@@ -0,0 +1,71 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	_ "image/jpeg"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/document"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/logger"
+)
+
+// documentPageWidth 渲染文档首页的最大宽度，之后再按缩略图设置缩放
+const documentPageWidth = 1240
+
+/* prepareDocumentUpload 解析文档的文本、页数和标题，解析失败的文件拒绝上传
+ * 配置了 pdftoppm（Office 文档另需 LibreOffice）时渲染首页作为预览，否则使用占位图 */
+func prepareDocumentUpload(ctx *UploadContext) error {
+	info, err := document.Extract(ctx.OriginalFileData, ctx.FileExt)
+	if err != nil {
+		logger.Warn("解析文档失败: %s, %v", ctx.File.Filename, err)
+		return errors.New(errors.CodeFileTypeNotSupported, "无法解析文档，仅支持 PDF、DOCX、XLSX、PPTX、ODT、ODS、ODP 和纯文本")
+	}
+	ctx.DocumentInfo = info
+
+	if document.CanRender(info.Format) {
+		page, err := document.RenderFirstPage(context.Background(), ctx.OriginalFileData, info.Format, documentPageWidth)
+		if err == nil {
+			ctx.DocumentPreview = page
+			return nil
+		}
+		logger.Warn("渲染文档首页失败，使用占位图: %s, %v", ctx.File.Filename, err)
+	}
+	ctx.DocumentPreview = document.PlaceholderPreview(info)
+	return nil
+}
+
+// applyDocumentResult 存储层无法识别文档尺寸，使用首页预览的尺寸，便于列表按比例排版
+func applyDocumentResult(ctx *UploadContext) {
+	if ctx.DocumentInfo == nil || ctx.Result == nil || len(ctx.DocumentPreview) == 0 {
+		return
+	}
+	if cfg, _, err := image.DecodeConfig(bytes.NewReader(ctx.DocumentPreview)); err == nil {
+		ctx.Result.Width = cfg.Width
+		ctx.Result.Height = cfg.Height
+	}
+}
+
+// documentAIInfo 上传时即保存提取的文本和页数，AI 分析完成后再写入摘要
+func documentAIInfo(ctx *UploadContext, file *models.File) *models.FileAIInfo {
+	info := ctx.DocumentInfo
+	return &models.FileAIInfo{
+		FileID:           file.ID,
+		SemanticKeywords: json.RawMessage("[]"),
+		Tags:             json.RawMessage("[]"),
+		ColorPalette:     json.RawMessage("[]"),
+		NSFWCategories:   json.RawMessage("{}"),
+		Width:            file.Width,
+		Height:           file.Height,
+		AspectRatio:      file.Ratio,
+		FileType:         file.Format,
+		EstimatedSize:    file.SizeFormatted,
+		DocumentText:     info.Text,
+		PageCount:        info.PageCount,
+		Language:         info.Language,
+		DocumentType:     info.Type,
+	}
+}
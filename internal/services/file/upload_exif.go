@@ -0,0 +1,106 @@
+package file
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/setting"
+	"pixelpunk/internal/services/user"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/exif"
+	"pixelpunk/pkg/imagex/formats"
+	"pixelpunk/pkg/logger"
+)
+
+/* resolveExifPolicy 元数据策略优先级：上传参数 > 用户设置 > 系统设置 preserve_exif */
+func resolveExifPolicy(ctx *UploadContext) string {
+	if models.IsValidExifPolicy(ctx.ExifPolicy) {
+		return ctx.ExifPolicy
+	}
+	if !ctx.IsGuestUpload {
+		if settings, err := user.GetUserSettings(ctx.UserID); err == nil && models.IsValidExifPolicy(settings.ExifPolicy) {
+			return settings.ExifPolicy
+		}
+	}
+	if setting.GetBool("upload", "preserve_exif", true) {
+		return models.ExifPolicyKeep
+	}
+	return models.ExifPolicyStripAll
+}
+
+func exifScrubOptions(policy string) exif.ScrubOptions {
+	return exif.ScrubOptions{
+		StripAll:    policy == models.ExifPolicyStripAll,
+		StripGPS:    policy == models.ExifPolicyStripGPS,
+		StripSerial: policy == models.ExifPolicyStripSerial,
+	}
+}
+
+/* applyExifPolicy 提取 EXIF 后按策略清理待上传的文件数据，只执行一次
+ * 提取的 EXIF 完整保存，仅所有者可见；其他人查看时按策略隐藏 */
+func applyExifPolicy(ctx *UploadContext) error {
+	if ctx.ExifPolicyApplied {
+		return nil
+	}
+	ctx.ExifPolicyApplied = true
+	if !models.IsValidExifPolicy(ctx.ExifPolicy) {
+		ctx.ExifPolicy = resolveExifPolicy(ctx)
+	}
+
+	if ctx.EXIFData == nil {
+		if exifData, err := exif.ExtractEXIFFromBytes(ctx.OriginalFileData); err == nil && exifData != nil {
+			ctx.EXIFData = convertToFileEXIF(exifData)
+		}
+	}
+
+	opts := exifScrubOptions(ctx.ExifPolicy)
+	if opts.IsZero() || len(ctx.OriginalFileData) == 0 {
+		return nil
+	}
+	scrubbed, err := exif.Scrub(ctx.OriginalFileData, opts)
+	if err != nil {
+		logger.Warn("清理文件元数据失败: %v", err)
+		return errors.Wrap(err, errors.CodeFileUploadFailed, "清理文件元数据失败")
+	}
+	ctx.OriginalFileData = scrubbed
+	return nil
+}
+
+/* prepareMergedFileMetadata 分片上传合并后读取文件内容：图片提取 EXIF 并应用元数据策略，
+ * 视频和文档与普通上传一样解析并生成封面/首页预览 */
+func prepareMergedFileMetadata(ctx *UploadContext) error {
+	if !models.IsValidExifPolicy(ctx.ExifPolicy) {
+		ctx.ExifPolicy = resolveExifPolicy(ctx)
+	}
+	ext := strings.ToLower(filepath.Ext(ctx.File.Filename))
+	isVideo, isDocument := formats.IsVideo(ext), formats.IsDocument(ext)
+	switch ext {
+	case ".jpg", ".jpeg", ".png", ".webp":
+	default:
+		if !isVideo && !isDocument {
+			return nil
+		}
+	}
+
+	if ctx.OriginalFileData == nil {
+		src, err := ctx.File.Open()
+		if err != nil {
+			return errors.Wrap(err, errors.CodeFileUploadFailed, "打开上传文件失败")
+		}
+		data, err := io.ReadAll(src)
+		src.Close()
+		if err != nil {
+			return errors.Wrap(err, errors.CodeFileUploadFailed, "读取文件数据失败")
+		}
+		ctx.OriginalFileData = data
+	}
+	switch {
+	case isVideo:
+		return prepareVideoUpload(ctx)
+	case isDocument:
+		return prepareDocumentUpload(ctx)
+	}
+	return applyExifPolicy(ctx)
+}
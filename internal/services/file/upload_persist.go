@@ -7,8 +7,10 @@ import (
 	"path/filepath"
 	"pixelpunk/internal/models"
 	"pixelpunk/internal/services/user"
+	"pixelpunk/pkg/common"
 	"pixelpunk/pkg/errors"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -55,6 +57,7 @@ func createFileModel(ctx *UploadContext) *models.File {
 	}
 	return &models.File{
 		ID:                        ctx.FileID,
+		CreatedAt:                 uploadCreatedAt(ctx),
 		UserID:                    ctx.UserID,
 		FolderID:                  ctx.FolderID,
 		OriginalName:              ctx.File.Filename,
@@ -75,7 +78,7 @@ func createFileModel(ctx *UploadContext) *models.File {
 		Height:                    ctx.Result.Height,
 		Ratio:                     ratio,
 		Format:                    getFileFormat(ctx),
-		Mime:                      ctx.File.Header.Get("Content-Type"),
+		Mime:                      uploadMime(ctx),
 		Resolution:                resolutionType,
 		Description:               getDescriptionFromContext(ctx),
 		NSFW:                      false,
@@ -95,9 +98,44 @@ func createFileModel(ctx *UploadContext) *models.File {
 		GuestIP:                   ctx.GuestIP,
 		ThumbnailGenerationFailed: ctx.Result.ThumbnailGenerationFailed,
 		ThumbnailFailureReason:    ctx.Result.ThumbnailFailureReason,
+		WatermarkConfig:           deferredWatermarkConfig(ctx),
+		ExifPolicy:                ctx.ExifPolicy,
+		TakenAt:                   uploadTakenAt(ctx),
 	}
 }
 
+/* uploadTakenAt 拍摄时间取 EXIF 原始拍摄时间，导入的文件没有时取源文件修改时间，都没有时为nil，创建时回退为上传时间
+ * 清除全部元数据的文件不使用这些时间，避免通过公开的时间线暴露拍摄日期 */
+func uploadTakenAt(ctx *UploadContext) *time.Time {
+	if ctx.ExifPolicy == models.ExifPolicyStripAll {
+		return nil
+	}
+	if ctx.VideoInfo != nil {
+		if takenAt := videoTakenAt(ctx); takenAt != nil {
+			return takenAt
+		}
+	} else if ctx.EXIFData != nil && ctx.EXIFData.DateTimeOriginal != nil {
+		takenAt := models.TakenAtFromEXIF(*ctx.EXIFData.DateTimeOriginal)
+		return &takenAt
+	}
+	return ctx.ModTime
+}
+
+// uploadCreatedAt 导入时保留源文件的修改时间作为创建时间，零值由 GORM 填充为当前时间
+func uploadCreatedAt(ctx *UploadContext) common.JSONTime {
+	if ctx.ModTime == nil {
+		return common.JSONTime{}
+	}
+	return common.JSONTime(*ctx.ModTime)
+}
+
+func deferredWatermarkConfig(ctx *UploadContext) string {
+	if !ctx.WatermarkDeferred {
+		return ""
+	}
+	return ctx.WatermarkConfig
+}
+
 func formatFileSize(size int64) string {
 	const (
 		B  = 1
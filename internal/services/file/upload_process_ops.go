@@ -9,6 +9,7 @@ import (
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/errors"
 	"pixelpunk/pkg/exif"
+	"pixelpunk/pkg/imagex/formats"
 	pkgStorage "pixelpunk/pkg/storage"
 	storageutils "pixelpunk/pkg/storage/utils"
 	"strings"
@@ -30,12 +31,27 @@ func processFile(ctx *UploadContext) error {
 	}
 	fileHashStr := storageutils.CalculateDataMD5(ctx.OriginalFileData)
 	ctx.FileHash = fileHashStr
+	if !models.IsValidExifPolicy(ctx.ExifPolicy) {
+		ctx.ExifPolicy = resolveExifPolicy(ctx)
+	}
 	if err := checkDuplicateFile(ctx, fileHashStr); err != nil {
 		return err
 	}
 
-	if exifData, err := exif.ExtractEXIFFromBytes(ctx.OriginalFileData); err == nil && exifData != nil {
-		ctx.EXIFData = convertToFileEXIF(exifData)
+	if formats.IsVideo(ctx.FileExt) {
+		if !ctx.ReuseExistingFile {
+			if err := prepareVideoUpload(ctx); err != nil {
+				return err
+			}
+		}
+	} else if formats.IsDocument(ctx.FileExt) {
+		if !ctx.ReuseExistingFile {
+			if err := prepareDocumentUpload(ctx); err != nil {
+				return err
+			}
+		}
+	} else if err := applyExifPolicy(ctx); err != nil {
+		return err
 	}
 
 	src.Seek(0, 0)
@@ -44,7 +60,7 @@ func processFile(ctx *UploadContext) error {
 
 // convertToFileEXIF 将 EXIF 数据转换为数据库模型
 func convertToFileEXIF(data *exif.FileEXIFData) *models.FileEXIF {
-	return &models.FileEXIF{
+	fe := &models.FileEXIF{
 		Make:              data.Make,
 		Model:             data.Model,
 		LensModel:         data.LensModel,
@@ -61,6 +77,7 @@ func convertToFileEXIF(data *exif.FileEXIFData) *models.FileEXIF {
 		GPSAltitude:       data.GPSAltitude,
 		GPSLatitudeRef:    data.GPSLatitudeRef,
 		GPSLongitudeRef:   data.GPSLongitudeRef,
+		GPSChecked:        true,
 		DateTime:          data.DateTime,
 		DateTimeOriginal:  data.DateTimeOriginal,
 		DateTimeDigitized: data.DateTimeDigitized,
@@ -86,6 +103,8 @@ func convertToFileEXIF(data *exif.FileEXIFData) *models.FileEXIF {
 		Copyright:         data.Copyright,
 		ImageDescription:  data.ImageDescription,
 	}
+	fillGeoPlace(fe)
+	return fe
 }
 
 func checkDuplicateFile(ctx *UploadContext, fileHash string) error {
@@ -93,6 +112,14 @@ func checkDuplicateFile(ctx *UploadContext, fileHash string) error {
 	if err := database.DB.Where("user_id = ? AND md5_hash = ?", ctx.UserID, fileHash).
 		Where("status <> ?", "pending_deletion").
 		First(&existingImage).Error; err == nil {
+		// 已有文件按其他元数据策略存储时不复用，避免保留了应清理的元数据
+		existingPolicy := existingImage.ExifPolicy
+		if existingPolicy == "" {
+			existingPolicy = models.ExifPolicyKeep
+		}
+		if existingPolicy != ctx.ExifPolicy {
+			return nil
+		}
 		ctx.IsDuplicate = true
 		ctx.OriginalFileID = existingImage.ID
 		ctx.ReuseExistingFile = true
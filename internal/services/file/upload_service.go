@@ -12,6 +12,7 @@ import (
 	"pixelpunk/pkg/common"
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/hooks"
 	"pixelpunk/pkg/logger"
 	pathutil "pixelpunk/pkg/storage/path"
 	"pixelpunk/pkg/utils"
@@ -66,6 +67,7 @@ func completeFileUpload(ctx *UploadContext) (*FileDetailResponse, error) {
 
 	if response != nil {
 		activity.LogImageUploadByID(ctx.FileID, ctx.FolderID)
+		hooks.TriggerFileEvent(hooks.FileEventUploaded, ctx.FileID)
 	}
 
 	return response, nil
@@ -116,6 +118,8 @@ func uploadNewFile(ctx *UploadContext) error {
 	}
 
 	ctx.Result = convertFromNewStorageResult(uploadResult)
+	applyVideoResult(ctx)
+	applyDocumentResult(ctx)
 
 	prevHash := ctx.FileHash
 	if uploadResult.Hash != "" && len(uploadResult.Hash) == 32 {
@@ -168,6 +172,18 @@ func saveFileData(ctx *UploadContext) error {
 			}
 		}
 
+		if ctx.VideoInfo != nil {
+			if err := tx.Create(videoAIInfo(ctx, file)).Error; err != nil {
+				logger.Warn("保存视频元数据失败: %v", err)
+			}
+		}
+
+		if ctx.DocumentInfo != nil {
+			if err := tx.Create(documentAIInfo(ctx, file)).Error; err != nil {
+				logger.Warn("保存文档文本失败: %v", err)
+			}
+		}
+
 		return nil
 	})
 
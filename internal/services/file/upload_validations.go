@@ -82,6 +82,9 @@ func isValidFileType(ext string) bool {
 			".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".webp": true,
 			".bmp": true, ".apng": true, ".svg": true, ".ico": true, ".jp2": true,
 			".tiff": true, ".tif": true, ".tga": true, ".heic": true, ".heif": true,
+			".mp4": true, ".m4v": true, ".mov": true, ".webm": true,
+			".pdf": true, ".docx": true, ".xlsx": true, ".pptx": true, ".odt": true,
+			".ods": true, ".odp": true, ".txt": true, ".md": true, ".csv": true,
 		}
 		return validTypes[ext]
 	}
@@ -100,6 +103,9 @@ func isValidFileType(ext string) bool {
 		".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".webp": true,
 		".bmp": true, ".apng": true, ".svg": true, ".ico": true, ".jp2": true,
 		".tiff": true, ".tif": true, ".tga": true, ".heic": true, ".heif": true,
+		".mp4": true, ".m4v": true, ".mov": true, ".webm": true,
+		".pdf": true, ".docx": true, ".xlsx": true, ".pptx": true, ".odt": true,
+		".ods": true, ".odp": true, ".txt": true, ".md": true, ".csv": true,
 	}
 	return validTypes[ext]
 }
@@ -0,0 +1,126 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"time"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/imagex/formats"
+	"pixelpunk/pkg/logger"
+	"pixelpunk/pkg/storage/pipeline"
+	"pixelpunk/pkg/video"
+)
+
+// posterFrameWidth 截取封面帧的最大宽度，之后再按缩略图设置缩放
+const posterFrameWidth = 1280
+
+/* prepareVideoUpload 解析视频容器并生成封面，解析失败的文件拒绝上传
+ * 有 ffmpeg 时截取片头之后的一帧作为封面，否则使用保持宽高比的占位图 */
+func prepareVideoUpload(ctx *UploadContext) error {
+	info, err := video.Probe(ctx.OriginalFileData)
+	if err != nil {
+		logger.Warn("解析视频失败: %s, %v", ctx.File.Filename, err)
+		return errors.New(errors.CodeFileTypeNotSupported, "无法解析视频文件，仅支持 MP4、MOV 和 WebM")
+	}
+	ctx.VideoInfo = info
+
+	if video.Available() {
+		poster, err := extractVideoPoster(ctx.OriginalFileData, info)
+		if err == nil {
+			ctx.VideoPoster = poster
+			return nil
+		}
+		logger.Warn("截取视频封面失败，使用占位图: %s, %v", ctx.File.Filename, err)
+	}
+	ctx.VideoPoster = video.PlaceholderPoster(info.Width, info.Height)
+	return nil
+}
+
+// extractVideoPoster ffmpeg 需要可寻址的输入，先写入临时文件再截帧
+func extractVideoPoster(data []byte, info *video.Info) ([]byte, error) {
+	tmp, err := os.CreateTemp("", "pixelpunk-video-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+	return video.ExtractFrame(context.Background(), tmp.Name(), video.PosterTime(info.Duration), posterFrameWidth)
+}
+
+// posterThumbnail 将视频封面或文档首页预览按缩略图设置缩放，作为预生成的缩略图交给存储适配器
+func posterThumbnail(ctx *UploadContext, width, height, quality int) ([]byte, string) {
+	poster := ctx.VideoPoster
+	if ctx.DocumentInfo != nil {
+		poster = ctx.DocumentPreview
+	}
+	if len(poster) == 0 {
+		return nil, ""
+	}
+	thumb, format, _ := pipeline.GenerateOrFallback(poster, pipeline.Options{
+		Width: width, Height: height, Quality: quality, EnableWebP: true, FallbackOnError: true,
+	})
+	return thumb, format
+}
+
+// applyVideoResult 存储层无法识别视频尺寸，使用容器中解析出的显示尺寸
+func applyVideoResult(ctx *UploadContext) {
+	if ctx.VideoInfo == nil || ctx.Result == nil {
+		return
+	}
+	ctx.Result.Width = ctx.VideoInfo.Width
+	ctx.Result.Height = ctx.VideoInfo.Height
+}
+
+// uploadMime 视频和文档使用按扩展名确定的标准 MIME，浏览器上传时常给出 application/octet-stream
+func uploadMime(ctx *UploadContext) string {
+	if ctx.VideoInfo != nil || ctx.DocumentInfo != nil || formats.IsVideo(ctx.FileExt) || formats.IsDocument(ctx.FileExt) {
+		return formats.GetContentType(ctx.FileExt)
+	}
+	return ctx.File.Header.Get("Content-Type")
+}
+
+// videoTakenAt 视频的拍摄时间取容器记录的创建时间，清除全部元数据时不使用
+func videoTakenAt(ctx *UploadContext) *time.Time {
+	if ctx.ExifPolicy == models.ExifPolicyStripAll || ctx.VideoInfo == nil || ctx.VideoInfo.CreatedAt == nil {
+		return nil
+	}
+	// 部分设备不写入创建时间，容器中记录为起点时间
+	if ctx.VideoInfo.CreatedAt.Year() < 1971 {
+		return nil
+	}
+	takenAt := ctx.VideoInfo.CreatedAt.Local()
+	return &takenAt
+}
+
+// videoAIInfo 上传时即保存视频的时长、编码等技术信息，AI 分析完成后只更新内容相关字段
+func videoAIInfo(ctx *UploadContext, file *models.File) *models.FileAIInfo {
+	info := ctx.VideoInfo
+	return &models.FileAIInfo{
+		FileID:           file.ID,
+		SemanticKeywords: json.RawMessage("[]"),
+		Tags:             json.RawMessage("[]"),
+		ColorPalette:     json.RawMessage("[]"),
+		NSFWCategories:   json.RawMessage("{}"),
+		Width:            file.Width,
+		Height:           file.Height,
+		AspectRatio:      file.Ratio,
+		Resolution:       file.Resolution,
+		FileType:         file.Format,
+		EstimatedSize:    file.SizeFormatted,
+		Duration:         info.Duration,
+		Bitrate:          info.Bitrate,
+		SampleRate:       info.SampleRate,
+		Channels:         info.Channels,
+		VideoCodec:       info.VideoCodec,
+		AudioCodec:       info.AudioCodec,
+	}
+}
@@ -4,10 +4,16 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"mime/multipart"
+	"path/filepath"
+	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/setting"
 	"pixelpunk/internal/services/stats"
+	"pixelpunk/internal/services/user"
+	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/errors"
 	"pixelpunk/pkg/logger"
 	"pixelpunk/pkg/watermark"
+	"strings"
 )
 
 func processFileAndUploadWithWatermark(ctx *UploadContext) error {
@@ -16,7 +22,11 @@ func processFileAndUploadWithWatermark(ctx *UploadContext) error {
 		return err
 	}
 
-	if ctx.WatermarkEnabled && ctx.WatermarkConfig != "" {
+	// 视频不添加水印
+	watermarkRequested := ctx.WatermarkEnabled && ctx.WatermarkConfig != "" && ctx.VideoInfo == nil && ctx.DocumentInfo == nil
+	if watermarkRequested && !setting.GetBool("upload", "watermark_burn_on_upload", false) {
+		deferWatermarkToServe(ctx)
+	} else if watermarkRequested {
 		if err := applyWatermarkToFile(ctx); err != nil {
 			logger.Warn("水印处理失败，使用原图上传: %v", err)
 			// 记录失败原因，不中断上传流程
@@ -34,12 +44,37 @@ func processFileAndUploadWithWatermark(ctx *UploadContext) error {
 	return nil
 }
 
+/* deferWatermarkToServe 校验水印配置并随文件记录保存，访问时再叠加，原图保持不变 */
+func deferWatermarkToServe(ctx *UploadContext) {
+	config, err := watermark.ParseConfigFromJSON(ctx.WatermarkConfig)
+	if err == nil && config.Enabled {
+		err = watermark.ValidateConfig(config)
+	}
+	if err != nil {
+		logger.Warn("水印配置无效，不添加水印: %v", err)
+		ctx.WatermarkApplied = false
+		ctx.WatermarkFailureReason = err.Error()
+		return
+	}
+	if !config.Enabled {
+		return
+	}
+	ctx.WatermarkDeferred = true
+	ctx.WatermarkApplied = true
+}
+
 func applyWatermarkToFile(ctx *UploadContext) error {
 	if ctx.OriginalFileData == nil {
 		return errors.New(errors.CodeFileUploadFailed, "原始文件数据不可用")
 	}
 
-	result, err := watermark.ProcessBytesWithConfigJSON(ctx.OriginalFileData, ctx.WatermarkConfig)
+	config, err := watermark.ParseConfigFromJSON(ctx.WatermarkConfig)
+	if err != nil {
+		return errors.Wrap(err, errors.CodeInvalidParameter, "水印配置无效")
+	}
+	config.Variables = watermarkVariables(ctx)
+
+	result, err := watermark.ProcessImageBytes(ctx.OriginalFileData, config)
 	if err != nil {
 		logger.Error("水印合成失败: %v", err)
 		return errors.Wrap(err, errors.CodeFileUploadFailed, "水印合成失败")
@@ -58,6 +93,52 @@ func applyWatermarkToFile(ctx *UploadContext) error {
 	return nil
 }
 
+/* watermarkVariables 文字水印的模板变量取值 */
+func watermarkVariables(ctx *UploadContext) map[string]string {
+	vars := map[string]string{
+		watermark.VarFileID: ctx.FileID,
+	}
+	if ctx.File != nil {
+		vars[watermark.VarFilename] = ctx.File.Filename
+	}
+	if ctx.UserID != 0 {
+		var u models.User
+		if err := database.DB.Select("username").Where("id = ?", ctx.UserID).First(&u).Error; err == nil {
+			vars[watermark.VarUsername] = u.Username
+		}
+	}
+	return vars
+}
+
+/* applyDefaultWatermarkProfile 上传未指定水印时，使用用户的默认水印方案（仅对支持水印的图片格式生效） */
+func applyDefaultWatermarkProfile(ctx *UploadContext) {
+	if ctx.WatermarkEnabled || ctx.IsGuestUpload || ctx.File == nil {
+		return
+	}
+
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(ctx.File.Filename)), ".")
+	supported := false
+	for _, format := range watermark.GetSupportedFormats() {
+		if ext == format {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		return
+	}
+
+	config, err := user.GetDefaultWatermarkConfig(ctx.UserID)
+	if err != nil {
+		logger.Warn("获取默认水印方案失败: %v", err)
+		return
+	}
+	if config != "" {
+		ctx.WatermarkEnabled = true
+		ctx.WatermarkConfig = config
+	}
+}
+
 /* UploadFileWithWatermark 上传单张文件（支持水印） */
 func UploadFileWithWatermark(c *gin.Context, userID uint, file *multipart.FileHeader, folderID, accessLevel string, optimize bool, storageDuration string, watermarkEnabled bool, watermarkConfig string) (*FileUploadResponse, error) {
 	return UploadFileWithOptions(c, userID, file, folderID, accessLevel, optimize, storageDuration, watermarkEnabled, watermarkConfig, nil, nil)
@@ -86,6 +167,7 @@ func UploadFileWithOptions(c *gin.Context, userID uint, file *multipart.FileHead
 		ctx.WatermarkEnabled = watermarkEnabled
 		ctx.WatermarkConfig = watermarkConfig
 	}
+	applyDefaultWatermarkProfile(ctx)
 
 	// 设置WebP转换选项
 	ctx.WebPEnabled = webpEnabled
@@ -0,0 +1,328 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"mime/multipart"
+	"net"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/folder"
+	"pixelpunk/internal/services/setting"
+	"pixelpunk/internal/services/stats"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/imagex/formats"
+	"pixelpunk/pkg/logger"
+	"pixelpunk/pkg/urlfetch"
+
+	"gorm.io/gorm"
+)
+
+const (
+	urlImportTimeout      = 60 * time.Second // 单个地址的下载超时
+	urlImportMaxRedirects = 3
+	urlImportMaxBatch     = 200 // 单个批量任务最多包含的地址数
+	urlImportMaxURLLength = 2048
+)
+
+/* URLImportOptions 远程导入的上传选项，批量任务以JSON保存在任务记录中 */
+type URLImportOptions struct {
+	FolderID    string `json:"folder_id"`
+	FilePath    string `json:"file_path"`
+	AccessLevel string `json:"access_level"`
+	Optimize    bool   `json:"optimize"`
+	ExifPolicy  string `json:"exif_policy"`
+}
+
+/* ImportFromURL 下载远程文件并按普通上传流程保存
+ * 通过API密钥调用时 key 不为nil，按密钥的限制和默认目录处理 */
+func ImportFromURL(userID uint, key *models.APIKey, rawURL string, opts URLImportOptions) (*FileDetailResponse, error) {
+	if _, err := urlfetch.ValidateURL(rawURL); err != nil {
+		return nil, urlImportError(err)
+	}
+	folderID, err := resolveImportFolder(userID, key, opts)
+	if err != nil {
+		return nil, err
+	}
+	return importRemoteFile(userID, key, folderID, rawURL, opts)
+}
+
+/* CreateURLImportJob 创建批量导入任务并在后台处理，重复的地址只导入一次
+ * 格式不正确的地址直接记为失败，不影响其他地址 */
+func CreateURLImportJob(userID uint, key *models.APIKey, urls []string, opts URLImportOptions) (*models.ImportJob, error) {
+	seen := make(map[string]bool, len(urls))
+	items := make([]models.ImportJobItem, 0, len(urls))
+	for _, raw := range urls {
+		raw = strings.TrimSpace(raw)
+		if raw == "" || seen[raw] {
+			continue
+		}
+		seen[raw] = true
+		item := models.ImportJobItem{Seq: len(items), Source: raw}
+		if len(raw) > urlImportMaxURLLength {
+			item.Source = raw[:urlImportMaxURLLength]
+			item.Status = models.ImportItemStatusFailed
+			item.ErrorMsg = "远程地址过长"
+		} else if _, err := urlfetch.ValidateURL(raw); err != nil {
+			item.Status = models.ImportItemStatusFailed
+			item.ErrorMsg = importErrorMessage(urlImportError(err))
+		}
+		items = append(items, item)
+	}
+	if len(items) == 0 {
+		return nil, errors.New(errors.CodeInvalidParameter, "请至少提供一个远程地址")
+	}
+	if len(items) > urlImportMaxBatch {
+		return nil, errors.New(errors.CodeInvalidParameter, fmt.Sprintf("单个任务最多导入%d个地址", urlImportMaxBatch))
+	}
+
+	folderID, err := resolveImportFolder(userID, key, opts)
+	if err != nil {
+		return nil, err
+	}
+	options, _ := json.Marshal(opts)
+
+	job := &models.ImportJob{
+		UserID:     userID,
+		Source:     models.ImportSourceURL,
+		FolderID:   folderID,
+		Options:    string(options),
+		TotalCount: len(items),
+	}
+	if key != nil {
+		job.APIKeyID = key.ID
+	}
+	for _, item := range items {
+		if item.Status == models.ImportItemStatusFailed {
+			job.FailedCount++
+		}
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(job).Error; err != nil {
+			return err
+		}
+		for i := range items {
+			items[i].JobID = job.ID
+		}
+		return tx.CreateInBatches(items, 100).Error
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBCreateFailed, "创建导入任务失败")
+	}
+
+	startImportJob(job.ID)
+	return job, nil
+}
+
+// urlImportSource 远程地址导入任务，条目在创建任务时生成
+type urlImportSource struct {
+	opts URLImportOptions
+}
+
+func newURLImportSource(job *models.ImportJob) (ImportSource, error) {
+	s := &urlImportSource{}
+	if job.Options != "" {
+		if err := json.Unmarshal([]byte(job.Options), &s.opts); err != nil {
+			logger.Warn("解析导入任务选项失败: job_id=%s, %v", job.ID, err)
+		}
+	}
+	return s, nil
+}
+
+func (s *urlImportSource) Prepare(job *models.ImportJob) error { return nil }
+
+func (s *urlImportSource) Cleanup(job *models.ImportJob) {}
+
+// Process API密钥任务每次重新加载密钥以检查状态和限额
+func (s *urlImportSource) Process(job *models.ImportJob, item *models.ImportJobItem) (*ImportItemResult, error) {
+	var key *models.APIKey
+	if job.APIKeyID != "" {
+		var err error
+		if key, err = loadImportAPIKey(job.APIKeyID); err != nil {
+			return nil, err
+		}
+	}
+	resp, err := importRemoteFile(job.UserID, key, job.FolderID, item.Source, s.opts)
+	if err != nil {
+		return nil, err
+	}
+	return &ImportItemResult{FileID: resp.ID, FileName: resp.OriginalName, Size: resp.Size}, nil
+}
+
+func loadImportAPIKey(keyID string) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := database.DB.Where("id = ?", keyID).First(&key).Error; err != nil {
+		return nil, errors.New(errors.CodeForbidden, "API密钥不存在")
+	}
+	if !key.IsActive() {
+		return nil, errors.New(errors.CodeForbidden, "API密钥已禁用")
+	}
+	if key.IsExpired() {
+		return nil, errors.New(errors.CodeForbidden, "API密钥已过期")
+	}
+	return &key, nil
+}
+
+// resolveImportFolder 目标文件夹：file_path 优先，API密钥未指定时使用密钥的默认目录
+func resolveImportFolder(userID uint, key *models.APIKey, opts URLImportOptions) (string, error) {
+	if key != nil {
+		return determineTargetFolder(key, opts.FolderID, opts.FilePath)
+	}
+	if opts.FilePath != "" {
+		return folder.CreateFolderByPath(userID, opts.FilePath)
+	}
+	return opts.FolderID, nil
+}
+
+// importRemoteFile 下载并上传单个远程文件，大小限制取系统设置与API密钥单文件限制中较小的一个
+func importRemoteFile(userID uint, key *models.APIKey, folderID, rawURL string, opts URLImportOptions) (*FileDetailResponse, error) {
+	maxSize := urlImportMaxSize()
+	if key != nil && key.SingleFileLimit > 0 && key.SingleFileLimit < maxSize {
+		maxSize = key.SingleFileLimit
+	}
+
+	res, err := fetchRemoteFile(rawURL, maxSize)
+	if err != nil {
+		return nil, err
+	}
+	if formats.ExtensionFromContentType(res.ContentType) == "" && res.ContentType != "application/octet-stream" {
+		return nil, errors.New(errors.CodeFileTypeNotSupported, fmt.Sprintf("远程内容不是支持的文件格式: %s", res.ContentType))
+	}
+	size := int64(len(res.Data))
+
+	if key != nil {
+		if err := validateSingleFileLimits(key, size); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkImportStorage(userID, size); err != nil {
+		return nil, err
+	}
+	if exceeded, err := checkDailyUploadLimit(userID, 1); err != nil {
+		logger.Warn("检查每日上传限制失败: %v", err)
+	} else if exceeded {
+		return nil, errors.New(errors.CodeUploadLimitExceeded, "已达到每日上传限制")
+	}
+
+	fileHeader, err := newMemoryFileHeader(res.FileName, res.ContentType, res.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := CreateUploadContext(nil, userID, fileHeader, folderID, opts.AccessLevel, opts.Optimize)
+	ctx.ExifPolicy = opts.ExifPolicy
+
+	resp, err := uploadImportedFile(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if key != nil {
+		if err := associateFileWithAPIKey(resp.ID, key.ID); err != nil {
+			logger.Error("更新文件API密钥关联失败", "fileID", resp.ID, "error", err)
+		}
+		go updateAPIKeyUsageAsync(key.ID, size)
+	}
+	return resp, nil
+}
+
+func fetchRemoteFile(rawURL string, maxSize int64) (*urlfetch.Result, error) {
+	res, err := urlfetch.Fetch(context.Background(), rawURL, urlfetch.Options{
+		MaxSize:      maxSize,
+		Timeout:      urlImportTimeout,
+		MaxRedirects: urlImportMaxRedirects,
+	})
+	if err != nil {
+		logger.Warn("下载远程文件失败: %s, %v", rawURL, err)
+		return nil, urlImportError(err)
+	}
+	return res, nil
+}
+
+// checkImportStorage 导入前检查用户剩余存储空间
+func checkImportStorage(userID uint, size int64) error {
+	available, err := stats.CheckUserStorageAvailable(userID, size)
+	if err != nil {
+		return errors.Wrap(err, errors.CodeInternal, "检查用户存储空间失败")
+	}
+	if !available {
+		return errors.New(errors.CodeStorageLimitExceeded, "存储空间不足，无法上传文件")
+	}
+	return nil
+}
+
+// uploadImportedFile 导入的文件按普通上传流程校验、处理和保存
+func uploadImportedFile(ctx *UploadContext) (*FileDetailResponse, error) {
+	if err := validateUploadRequest(ctx); err != nil {
+		return nil, err
+	}
+	if err := processFileAndUpload(ctx); err != nil {
+		return nil, err
+	}
+	return completeFileUpload(ctx)
+}
+
+// newMemoryFileHeader 将下载的内容包装为内存中的上传文件，复用 multipart 上传流程
+func newMemoryFileHeader(fileName, contentType string, data []byte) (*multipart.FileHeader, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(fileName)))
+	header.Set("Content-Type", contentType)
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeInternal, "创建表单文件失败")
+	}
+	if _, err := part.Write(data); err != nil {
+		return nil, errors.Wrap(err, errors.CodeInternal, "写入文件内容失败")
+	}
+	if err := writer.Close(); err != nil {
+		return nil, errors.Wrap(err, errors.CodeInternal, "关闭multipart writer失败")
+	}
+
+	form, err := multipart.NewReader(&body, writer.Boundary()).ReadForm(int64(len(data)) + 1024)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeInternal, "解析multipart form失败")
+	}
+	files := form.File["file"]
+	if len(files) == 0 {
+		return nil, errors.New(errors.CodeInternal, "multipart form中没有找到文件")
+	}
+	return files[0], nil
+}
+
+func urlImportMaxSize() int64 {
+	maxFileSizeMB, err := setting.GetNumberValue("max_file_size", 100.0)
+	if err != nil {
+		maxFileSizeMB = 100.0
+	}
+	return int64(maxFileSizeMB * 1024 * 1024)
+}
+
+// urlImportError 将下载错误转换为对应的业务错误码
+func urlImportError(err error) error {
+	switch {
+	case stderrors.Is(err, urlfetch.ErrBlockedAddress):
+		return errors.New(errors.CodeForbidden, "不允许导入内网地址")
+	case stderrors.Is(err, urlfetch.ErrInvalidURL):
+		return errors.New(errors.CodeInvalidParameter, err.Error())
+	case stderrors.Is(err, urlfetch.ErrTooLarge):
+		return errors.New(errors.CodeFileTooLarge, "远程文件超过大小限制")
+	case stderrors.Is(err, urlfetch.ErrBadStatus):
+		return errors.New(errors.CodeFileDownloadFailed, err.Error())
+	}
+	var netErr net.Error
+	if stderrors.As(err, &netErr) && netErr.Timeout() {
+		return errors.New(errors.CodeTimeout, "下载远程文件超时")
+	}
+	return errors.New(errors.CodeFileDownloadFailed, "下载远程文件失败")
+}
@@ -0,0 +1,184 @@
+package file
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/logger"
+	"pixelpunk/pkg/watermark"
+)
+
+const (
+	watermarkCacheDir       = "uploads/cache/watermark" // 访问时水印渲染结果缓存目录，按文件ID分子目录
+	watermarkCacheTouchSpan = 24 * time.Hour            // 命中缓存时刷新修改时间的最小间隔
+)
+
+var watermarkRenderLocks sync.Map // 缓存文件名 -> *sync.Mutex，避免并发重复渲染
+
+/* ServeWatermarkedFile 返回叠加访问时水印后的本地缓存文件路径，原图不受影响 */
+func ServeWatermarkedFile(file models.File, isThumb bool, configJSON string) (interface{}, bool, bool, error) {
+	if file.Size > signedLinkTransformMaxSize {
+		return nil, false, false, errors.New(errors.CodeInvalidParameter, "文件过大，不支持访问时水印")
+	}
+
+	vars := serveWatermarkVariables(file)
+	key := serveWatermarkCacheKey(file, isThumb, configJSON, vars)
+	dir := filepath.Join(watermarkCacheDir, file.ID)
+
+	if path, ok := findWatermarkCache(dir, key); ok {
+		return path, true, false, nil
+	}
+
+	lockValue, _ := watermarkRenderLocks.LoadOrStore(key, &sync.Mutex{})
+	lock := lockValue.(*sync.Mutex)
+	lock.Lock()
+	defer func() {
+		lock.Unlock()
+		watermarkRenderLocks.Delete(key)
+	}()
+
+	if path, ok := findWatermarkCache(dir, key); ok {
+		return path, true, false, nil
+	}
+
+	data, err := readStoredContent(file, isThumb)
+	if err != nil {
+		return nil, false, false, err
+	}
+	output, err := applyServeWatermark(data, configJSON, vars)
+	if err != nil {
+		return nil, false, false, err
+	}
+
+	ext := ".png"
+	if http.DetectContentType(output) == "image/jpeg" {
+		ext = ".jpg"
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, false, false, errors.Wrap(err, errors.CodeInternal, "创建水印缓存目录失败")
+	}
+	path := filepath.Join(dir, key+ext)
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, output, 0644); err != nil {
+		return nil, false, false, errors.Wrap(err, errors.CodeInternal, "写入水印缓存失败")
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return nil, false, false, errors.Wrap(err, errors.CodeInternal, "写入水印缓存失败")
+	}
+	return path, true, false, nil
+}
+
+/* renderServeWatermark 为已读取的文件内容叠加访问时水印（不缓存） */
+func renderServeWatermark(file models.File, data []byte, configJSON string) ([]byte, error) {
+	return applyServeWatermark(data, configJSON, serveWatermarkVariables(file))
+}
+
+func applyServeWatermark(data []byte, configJSON string, vars map[string]string) ([]byte, error) {
+	config, err := watermark.ParseConfigFromJSON(configJSON)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeInternal, "水印配置无效")
+	}
+	config.Enabled = true
+	config.Variables = vars
+
+	result, err := watermark.ProcessImageBytes(data, config)
+	if err != nil {
+		logger.Error("访问时水印合成失败: %v", err)
+		return nil, errors.Wrap(err, errors.CodeInternal, "水印合成失败")
+	}
+	if len(result.ProcessedData) == 0 {
+		return nil, errors.New(errors.CodeInternal, "水印合成未返回数据")
+	}
+	return result.ProcessedData, nil
+}
+
+/* serveWatermarkVariables 访问时水印的模板变量，日期取文件上传时间 */
+func serveWatermarkVariables(file models.File) map[string]string {
+	createdAt := time.Time(file.CreatedAt)
+	vars := map[string]string{
+		watermark.VarFileID:   file.ID,
+		watermark.VarFilename: file.OriginalName,
+		watermark.VarDate:     createdAt.Format("2006-01-02"),
+		watermark.VarDatetime: createdAt.Format("2006-01-02 15:04"),
+	}
+	var owner models.User
+	if err := database.DB.Select("username").Where("id = ?", file.UserID).First(&owner).Error; err == nil {
+		vars[watermark.VarUsername] = owner.Username
+	}
+	return vars
+}
+
+/* serveWatermarkCacheKey 文件内容、变体、水印配置与变量任一变化都会生成新的缓存 */
+func serveWatermarkCacheKey(file models.File, isThumb bool, configJSON string, vars map[string]string) string {
+	h := sha256.New()
+	h.Write([]byte(file.MD5Hash))
+	if isThumb {
+		h.Write([]byte{0, 't'})
+	} else {
+		h.Write([]byte{0, 'o'})
+	}
+	h.Write([]byte{0})
+	h.Write([]byte(configJSON))
+	for _, name := range []string{watermark.VarUsername, watermark.VarFileID, watermark.VarFilename, watermark.VarDate} {
+		h.Write([]byte{0})
+		h.Write([]byte(vars[name]))
+	}
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+func findWatermarkCache(dir, key string) (string, bool) {
+	for _, ext := range []string{".jpg", ".png"} {
+		path := filepath.Join(dir, key+ext)
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if time.Since(info.ModTime()) > watermarkCacheTouchSpan {
+			now := time.Now()
+			_ = os.Chtimes(path, now, now)
+		}
+		return path, true
+	}
+	return "", false
+}
+
+/* removeWatermarkCache 删除文件的全部访问时水印缓存 */
+func removeWatermarkCache(fileID string) {
+	if fileID == "" {
+		return
+	}
+	if err := os.RemoveAll(filepath.Join(watermarkCacheDir, fileID)); err != nil {
+		logger.Error("删除水印缓存失败 [%s]: %v", fileID, err)
+	}
+}
+
+/* CleanupWatermarkCache 删除超过指定天数未被访问的水印缓存，返回删除数量 */
+func CleanupWatermarkCache(retainDays int) (int64, error) {
+	cutoff := time.Now().AddDate(0, 0, -retainDays)
+	var count int64
+	err := filepath.Walk(watermarkCacheDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() || info.ModTime().After(cutoff) {
+			return nil
+		}
+		if err := os.Remove(path); err == nil {
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
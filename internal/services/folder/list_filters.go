@@ -24,7 +24,7 @@ func ListFolderContentsWithFilters(userID uint, folderID, keyword string, page,
 		folderQuery = folderQuery.Where("parent_id = '' OR parent_id IS NULL")
 	}
 	if keyword != "" {
-		folderQuery = folderQuery.Where("name LIKE ?", "%"+keyword+"%")
+		folderQuery = folderQuery.Where(database.ILike("name LIKE ?"), "%"+keyword+"%")
 	}
 
 	var folderOrder string
@@ -57,7 +57,7 @@ func ListFolderContentsWithFilters(userID uint, folderID, keyword string, page,
 		imageQuery = imageQuery.Where("access_level = ?", accessLevel)
 	}
 	if keyword != "" {
-		imageQuery = imageQuery.Where("original_name LIKE ? OR display_name LIKE ?", "%"+keyword+"%", "%"+keyword+"%")
+		imageQuery = imageQuery.Where(database.ILike("original_name LIKE ? OR display_name LIKE ?"), "%"+keyword+"%", "%"+keyword+"%")
 	}
 	var imageOrder string
 	switch sortBy {
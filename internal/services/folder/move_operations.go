@@ -4,6 +4,7 @@ import (
 	"pixelpunk/internal/models"
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/errors"
+	"strconv"
 	"strings"
 
 	"gorm.io/gorm"
@@ -131,10 +132,11 @@ func ReorderFolders(userID uint, parentID string, folderIDs []string) error {
 	sqlBuilder := strings.Builder{}
 	sqlBuilder.WriteString("UPDATE folder SET sort_order = CASE id ")
 
-	args := make([]interface{}, 0, len(folderIDs)*2+1)
+	// 排序值直接写入 SQL：PostgreSQL 无法推断 THEN 后参数的类型，会按文本处理
+	args := make([]interface{}, 0, len(folderIDs)+1)
 	for i, folderID := range folderIDs {
-		sqlBuilder.WriteString("WHEN ? THEN ? ")
-		args = append(args, folderID, i+1)
+		sqlBuilder.WriteString("WHEN ? THEN " + strconv.Itoa(i+1) + " ")
+		args = append(args, folderID)
 	}
 	sqlBuilder.WriteString("END WHERE id IN (?)")
 	args = append(args, folderIDs)
@@ -0,0 +1,124 @@
+package hostimport
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"pixelpunk/pkg/sqldump"
+)
+
+// cheveretoManifest 将 Chevereto 3.x/4.x 的 users、logins、albums、images、tags 表转换为清单
+// 文件路径按图片的存储模式拼接，旧地址包括原图以及 .th、.md 缩略图
+func cheveretoManifest(tables map[string]*sqldump.Table, prefix string) (*Manifest, error) {
+	images := tables[prefix+"images"]
+	if images == nil {
+		return nil, fmt.Errorf("导出数据中没有 %simages 表", prefix)
+	}
+
+	m := &Manifest{}
+	passwords := map[string]string{}
+	if logins := tables[prefix+"logins"]; logins != nil {
+		for _, r := range logins.Records {
+			if r["login_type"] == "password" {
+				passwords[r["login_user_id"]] = r["login_secret"]
+			}
+		}
+	}
+	if users := tables[prefix+"users"]; users != nil {
+		for _, r := range users.Records {
+			m.Users = append(m.Users, ManifestUser{
+				Key:          r["user_id"],
+				Username:     r["user_username"],
+				Email:        r["user_email"],
+				PasswordHash: passwords[r["user_id"]],
+			})
+		}
+	}
+
+	albumAccess := map[string]string{}
+	if albums := tables[prefix+"albums"]; albums != nil {
+		for _, r := range albums.Records {
+			m.Albums = append(m.Albums, ManifestAlbum{
+				Key:         r["album_id"],
+				User:        r["album_user_id"],
+				Name:        r["album_name"],
+				Description: r["album_description"],
+			})
+			albumAccess[r["album_id"]] = cheveretoAccessLevel(r["album_privacy"])
+		}
+	}
+
+	tags := map[string][]string{}
+	if tagTable, links := tables[prefix+"tags"], tables[prefix+"tags_files"]; tagTable != nil && links != nil {
+		names := map[string]string{}
+		for _, r := range tagTable.Records {
+			names[r["tag_id"]] = r["tag_name"]
+		}
+		for _, r := range links.Records {
+			if name := names[r["tag_file_tag_id"]]; name != "" {
+				tags[r["tag_file_file_id"]] = append(tags[r["tag_file_file_id"]], name)
+			}
+		}
+	}
+
+	for _, r := range images.Records {
+		dir := cheveretoImageDir(r)
+		name := r["image_name"]
+		ext := r["image_extension"]
+		relPath := dir + name + "." + ext
+
+		f := ManifestFile{
+			Path:        relPath,
+			User:        r["image_user_id"],
+			Name:        r["image_original_filename"],
+			Tags:        tags[r["image_id"]],
+			Description: r["image_description"],
+			LegacyURLs:  []string{"/" + relPath},
+			LegacyThumbURLs: []string{
+				"/" + dir + name + ".th." + ext,
+				"/" + dir + name + ".md." + ext,
+			},
+			CreatedAt:   r["image_date"],
+			AccessLevel: "public",
+		}
+		if f.Description == "" {
+			f.Description = r["image_title"]
+		}
+		if album := r["image_album_id"]; album != "" {
+			f.Albums = []string{album}
+			if level, ok := albumAccess[album]; ok {
+				f.AccessLevel = level
+			}
+		}
+		m.Files = append(m.Files, f)
+	}
+	m.normalize()
+	return m, nil
+}
+
+// cheveretoImageDir 按存储模式返回图片所在目录：datefolder 为 images/年/月/日/，path 为记录的路径
+func cheveretoImageDir(r sqldump.Record) string {
+	switch r["image_storage_mode"] {
+	case "datefolder":
+		if t, err := time.Parse("2006-01-02 15:04:05", r["image_date"]); err == nil {
+			return "images/" + t.Format("2006/01/02") + "/"
+		}
+	case "path":
+		if p := strings.Trim(r["image_path"], "/"); p != "" {
+			return p + "/"
+		}
+	}
+	return "images/"
+}
+
+// cheveretoAccessLevel 相册隐私设置对应的访问级别：公开、仅链接可见为私有，其他为受保护
+func cheveretoAccessLevel(privacy string) string {
+	switch privacy {
+	case "public", "":
+		return "public"
+	case "private_but_link":
+		return "private"
+	}
+	return "protected"
+}
@@ -0,0 +1,498 @@
+package hostimport
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	albumdto "pixelpunk/internal/controllers/album/dto"
+	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/album"
+	filesvc "pixelpunk/internal/services/file"
+	"pixelpunk/internal/services/folder"
+	"pixelpunk/internal/services/tag"
+	"pixelpunk/internal/services/user"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/logger"
+	"pixelpunk/pkg/sqldump"
+	"pixelpunk/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+const importBatchSize = 500
+
+// shortURLPattern 保留原短链时允许的字符，与系统生成的短链兼容
+var shortURLPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+/* Options 其他图床数据导入的选项，保存在任务记录中 */
+type Options struct {
+	Format          string `json:"format"`            // lsky/chevereto/manifest
+	ExportPath      string `json:"export_path"`       // SQL 导出文件或清单文件，位于允许导入的目录中
+	FilesDir        string `json:"files_dir"`         // 原图床的文件目录，为空时从 base_url 或清单中的地址下载
+	BaseURL         string `json:"base_url"`          // 原图床的访问地址，与文件路径拼接后下载
+	TablePrefix     string `json:"table_prefix"`      // 数据表前缀，Chevereto 默认为 chv_
+	UserID          uint   `json:"user_id"`           // 不创建用户时的文件归属，也用于没有归属的文件（如游客上传）
+	CreateUsers     bool   `json:"create_users"`      // 按原系统的用户创建账号，邮箱相同的用户直接使用
+	FolderPath      string `json:"folder_path"`       // 目标根文件夹路径
+	AlbumsAsFolders bool   `json:"albums_as_folders"` // 原相册映射为文件夹而不是相册
+	AccessLevel     string `json:"access_level"`      // 原数据没有访问级别时使用
+	Optimize        bool   `json:"optimize"`
+	ExifPolicy      string `json:"exif_policy"`
+	Scanned         bool   `json:"scanned"` // 已生成全部条目
+}
+
+// itemPayload 条目处理所需的数据，用户和相册在生成条目时已经解析为本系统的ID
+type itemPayload struct {
+	ManifestFile
+	UserID     uint     `json:"user_id"`
+	FolderPath string   `json:"folder_path"`
+	AlbumIDs   []string `json:"album_ids"`
+}
+
+/* RegisterImportSources 将其他图床的导出格式注册到导入任务 */
+func RegisterImportSources() {
+	for _, source := range []string{models.ImportSourceLsky, models.ImportSourceChevereto, models.ImportSourceManifest} {
+		filesvc.RegisterImportSource(source, newHostImportSource)
+	}
+}
+
+/* CreateHostImportJob 创建其他图床数据的导入任务，导出文件在后台解析 */
+func CreateHostImportJob(opts Options) (*models.ImportJob, error) {
+	switch opts.Format {
+	case models.ImportSourceLsky, models.ImportSourceChevereto, models.ImportSourceManifest:
+	default:
+		return nil, errors.New(errors.CodeInvalidParameter, "不支持的导入格式")
+	}
+
+	exportPath, err := filesvc.ResolveImportPath(opts.ExportPath)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(exportPath); err != nil || info.IsDir() {
+		return nil, errors.New(errors.CodeInvalidParameter, "导出文件不存在")
+	}
+	opts.ExportPath = exportPath
+
+	if opts.FilesDir != "" {
+		filesDir, err := filesvc.ResolveImportPath(opts.FilesDir)
+		if err != nil {
+			return nil, err
+		}
+		opts.FilesDir = filesDir
+	}
+	opts.BaseURL = strings.TrimSuffix(strings.TrimSpace(opts.BaseURL), "/")
+	if opts.Format == models.ImportSourceChevereto && opts.TablePrefix == "" {
+		opts.TablePrefix = "chv_"
+	}
+
+	var owner models.User
+	if err := database.DB.Select("id").Where("id = ?", opts.UserID).First(&owner).Error; err != nil {
+		return nil, errors.New(errors.CodeUserNotFound, "目标用户不存在")
+	}
+
+	options, _ := json.Marshal(opts)
+	job := &models.ImportJob{UserID: opts.UserID, Source: opts.Format, Options: string(options)}
+	if err := filesvc.CreateImportJob(job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// loadManifest 按格式读取导出文件
+func loadManifest(opts *Options) (*Manifest, error) {
+	f, err := os.Open(opts.ExportPath)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeFileNotFound, "读取导出文件失败")
+	}
+	defer f.Close()
+
+	var m *Manifest
+	switch opts.Format {
+	case models.ImportSourceManifest:
+		if strings.EqualFold(filepath.Ext(opts.ExportPath), ".csv") {
+			m, err = ParseManifestCSV(f)
+		} else {
+			m, err = ParseManifestJSON(f)
+		}
+	case models.ImportSourceLsky, models.ImportSourceChevereto:
+		var tables map[string]*sqldump.Table
+		tables, err = sqldump.Parse(f, func(name string) bool { return strings.HasPrefix(name, opts.TablePrefix) })
+		if err != nil {
+			break
+		}
+		if opts.Format == models.ImportSourceLsky {
+			m, err = lskyManifest(tables, opts.TablePrefix)
+		} else {
+			m, err = cheveretoManifest(tables, opts.TablePrefix)
+		}
+	}
+	if err != nil {
+		return nil, errors.New(errors.CodeInvalidParameter, err.Error())
+	}
+	return m, nil
+}
+
+// hostImportSource 其他图床数据的导入任务，首次运行时解析导出文件，创建用户和相册并生成条目
+type hostImportSource struct {
+	opts    Options
+	folders map[string]string // 用户ID/文件夹路径 => 文件夹ID
+}
+
+func newHostImportSource(job *models.ImportJob) (filesvc.ImportSource, error) {
+	s := &hostImportSource{folders: map[string]string{}}
+	if err := json.Unmarshal([]byte(job.Options), &s.opts); err != nil {
+		return nil, errors.Wrap(err, errors.CodeInvalidParameter, "解析导入任务选项失败")
+	}
+	return s, nil
+}
+
+func (s *hostImportSource) Prepare(job *models.ImportJob) error {
+	if s.opts.Scanned {
+		return nil
+	}
+
+	m, err := loadManifest(&s.opts)
+	if err != nil {
+		return err
+	}
+	users, err := s.resolveUsers(m.Users)
+	if err != nil {
+		return err
+	}
+	albums, albumFolders, err := s.resolveAlbums(m.Albums, users)
+	if err != nil {
+		return err
+	}
+
+	items := make([]models.ImportJobItem, 0, len(m.Files))
+	for _, f := range m.Files {
+		p := itemPayload{ManifestFile: f, UserID: s.opts.UserID, FolderPath: path.Join(s.opts.FolderPath, f.Folder)}
+		if id, ok := users[f.User]; ok {
+			p.UserID = id
+		}
+		for _, a := range f.Albums {
+			key := albumKey(f.User, a)
+			if id, ok := albums[key]; ok {
+				p.AlbumIDs = append(p.AlbumIDs, id)
+			}
+			if dir, ok := albumFolders[key]; ok && f.Folder == "" {
+				p.FolderPath = dir
+			}
+		}
+		payload, _ := json.Marshal(p)
+
+		source := f.Path
+		if source == "" {
+			source = f.URL
+		}
+		items = append(items, models.ImportJobItem{
+			JobID:    job.ID,
+			Seq:      len(items),
+			Source:   source,
+			FileName: truncate(f.Name, 255),
+			Payload:  string(payload),
+		})
+	}
+
+	s.opts.Scanned = true
+	options, _ := json.Marshal(s.opts)
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("job_id = ?", job.ID).Delete(&models.ImportJobItem{}).Error; err != nil {
+			return err
+		}
+		if len(items) > 0 {
+			if err := tx.CreateInBatches(items, importBatchSize).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&models.ImportJob{}).Where("id = ?", job.ID).Updates(map[string]interface{}{
+			"options":       string(options),
+			"total_count":   len(items),
+			"success_count": 0,
+			"failed_count":  0,
+			"skipped_count": 0,
+		}).Error
+	})
+	if err != nil {
+		return errors.Wrap(err, errors.CodeDBCreateFailed, "生成导入条目失败")
+	}
+	logger.Info("导入任务解析完成: job_id=%s, 用户 %d 个, 相册 %d 个, 文件 %d 个", job.ID, len(users), len(albums)+len(albumFolders), len(items))
+	return nil
+}
+
+// resolveUsers 原用户映射为本系统用户：邮箱相同的直接使用，否则创建，用户名冲突时加后缀
+func (s *hostImportSource) resolveUsers(users []ManifestUser) (map[string]uint, error) {
+	result := map[string]uint{}
+	if !s.opts.CreateUsers {
+		return result, nil
+	}
+
+	for _, u := range users {
+		if u.Key == "" {
+			continue
+		}
+		if u.Email != "" {
+			var existing models.User
+			if err := database.DB.Select("id").Where("email = ?", u.Email).Limit(1).Find(&existing).Error; err == nil && existing.ID != 0 {
+				result[u.Key] = existing.ID
+				continue
+			}
+		}
+
+		username, err := availableUsername(u.Username, u.Key)
+		if err != nil {
+			return nil, err
+		}
+		email := u.Email
+		if email == "" {
+			email = "imported-" + strings.ToLower(utils.GenerateRandomString(12)) + "@import.invalid"
+		}
+		created, err := user.CreateImportedUser(username, email, u.PasswordHash)
+		if err != nil {
+			return nil, err
+		}
+		result[u.Key] = created.ID
+	}
+	return result, nil
+}
+
+// resolveAlbums 创建相册（或文件夹），同一用户已有同名相册时直接使用
+func (s *hostImportSource) resolveAlbums(albums []ManifestAlbum, users map[string]uint) (map[string]string, map[string]string, error) {
+	albumIDs := map[string]string{}
+	folderPaths := map[string]string{}
+
+	for _, a := range albums {
+		name := truncate(strings.TrimSpace(a.Name), 100)
+		if a.Key == "" || name == "" {
+			continue
+		}
+		key := albumKey(a.User, a.Key)
+		if s.opts.AlbumsAsFolders {
+			folderPaths[key] = path.Join(s.opts.FolderPath, strings.ReplaceAll(name, "/", "_"))
+			continue
+		}
+
+		userID := s.opts.UserID
+		if id, ok := users[a.User]; ok {
+			userID = id
+		}
+		var existing models.Album
+		if err := database.DB.Select("id").
+			Where("user_id = ? AND name = ? AND type = ?", userID, name, models.AlbumTypeManual).
+			Limit(1).Find(&existing).Error; err == nil && existing.ID != "" {
+			albumIDs[key] = existing.ID
+			continue
+		}
+		created, err := album.CreateAlbum(userID, &albumdto.CreateAlbumDTO{
+			Name:        name,
+			Description: truncate(a.Description, 500),
+			Type:        models.AlbumTypeManual,
+		})
+		if err != nil {
+			logger.Warn("创建相册失败 [%s]: %v", name, err)
+			continue
+		}
+		albumIDs[key] = created.ID
+	}
+	return albumIDs, folderPaths, nil
+}
+
+// Process 导入单个文件，并恢复标签、相册、短链和旧地址；文件已存在时只补充这些信息
+func (s *hostImportSource) Process(job *models.ImportJob, item *models.ImportJobItem) (*filesvc.ImportItemResult, error) {
+	var p itemPayload
+	if err := json.Unmarshal([]byte(item.Payload), &p); err != nil {
+		return nil, errors.New(errors.CodeInvalidParameter, "条目数据无效")
+	}
+
+	folderID, err := s.folderFor(p.UserID, p.FolderPath)
+	if err != nil {
+		return nil, err
+	}
+	name, data, err := s.readFile(&p)
+	if err != nil {
+		return nil, err
+	}
+
+	accessLevel := p.AccessLevel
+	if accessLevel != "public" && accessLevel != "private" && accessLevel != "protected" {
+		accessLevel = s.opts.AccessLevel
+	}
+	resp, existingID, err := filesvc.ImportFileData(p.UserID, folderID, name, data, filesvc.ImportFileOptions{
+		AccessLevel: accessLevel,
+		Optimize:    s.opts.Optimize,
+		ExifPolicy:  s.opts.ExifPolicy,
+		ModTime:     parseCreatedAt(p.CreatedAt),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fileID := existingID
+	if resp != nil {
+		fileID = resp.ID
+		s.restoreFileInfo(fileID, &p)
+	}
+	s.restoreRelations(job, fileID, &p)
+
+	if resp == nil {
+		return &filesvc.ImportItemResult{FileID: existingID, Skipped: true, Reason: "文件已存在"}, nil
+	}
+	return &filesvc.ImportItemResult{FileID: resp.ID, FileName: resp.OriginalName, Size: resp.Size}, nil
+}
+
+func (s *hostImportSource) Cleanup(job *models.ImportJob) {}
+
+// readFile 优先从原图床的文件目录读取，其次从 base_url 或清单中的地址下载
+func (s *hostImportSource) readFile(p *itemPayload) (string, []byte, error) {
+	name := p.Name
+	if p.Path != "" && s.opts.FilesDir != "" {
+		full := filepath.Join(s.opts.FilesDir, filepath.FromSlash(p.Path))
+		if !filesvc.PathWithin(s.opts.FilesDir, full) {
+			return "", nil, errors.New(errors.CodeForbidden, "文件路径无效")
+		}
+		// 文件目录已解析为真实路径，路径中的符号链接解析后仍需位于文件目录内
+		resolved, err := filepath.EvalSymlinks(full)
+		if err != nil {
+			return "", nil, errors.New(errors.CodeFileNotFound, "文件不存在")
+		}
+		if !filesvc.PathWithin(s.opts.FilesDir, resolved) {
+			return "", nil, errors.New(errors.CodeForbidden, "文件路径无效")
+		}
+		full = resolved
+		info, err := os.Lstat(full)
+		if err != nil || !info.Mode().IsRegular() {
+			return "", nil, errors.New(errors.CodeFileNotFound, "文件不存在")
+		}
+		if info.Size() > filesvc.ImportMaxFileSize() {
+			return "", nil, errors.New(errors.CodeFileTooLarge, "文件超过大小限制")
+		}
+		data, err := os.ReadFile(full)
+		if err != nil {
+			return "", nil, errors.Wrap(err, errors.CodeFileNotFound, "读取文件失败")
+		}
+		return withExtension(name, p.Path), data, nil
+	}
+
+	rawURL := p.URL
+	if p.Path != "" && s.opts.BaseURL != "" {
+		rawURL = s.opts.BaseURL + "/" + strings.TrimPrefix(p.Path, "/")
+	}
+	if rawURL == "" {
+		return "", nil, errors.New(errors.CodeInvalidParameter, "没有配置文件目录或下载地址")
+	}
+	res, err := filesvc.FetchRemoteFile(rawURL)
+	if err != nil {
+		return "", nil, err
+	}
+	return withExtension(name, res.FileName), res.Data, nil
+}
+
+// restoreFileInfo 新导入的文件恢复原描述和短链，短链已被其他文件使用时保留系统生成的短链
+func (s *hostImportSource) restoreFileInfo(fileID string, p *itemPayload) {
+	updates := map[string]interface{}{}
+	if p.Description != "" {
+		updates["description"] = p.Description
+	}
+	if shortURL := strings.TrimSpace(p.ShortURL); shortURLPattern.MatchString(shortURL) {
+		var count int64
+		database.DB.Model(&models.File{}).Where("short_url = ? AND id <> ?", shortURL, fileID).Count(&count)
+		if count == 0 {
+			updates["short_url"] = shortURL
+		} else {
+			logger.Warn("短链已被占用，使用新短链: %s", shortURL)
+		}
+	}
+	if len(updates) == 0 {
+		return
+	}
+	if err := database.DB.Model(&models.File{}).Where("id = ?", fileID).Updates(updates).Error; err != nil {
+		logger.Warn("恢复文件信息失败: file_id=%s, %v", fileID, err)
+	}
+}
+
+// restoreRelations 添加标签、相册和旧地址，重复执行不会产生重复数据
+func (s *hostImportSource) restoreRelations(job *models.ImportJob, fileID string, p *itemPayload) {
+	if len(p.Tags) > 0 {
+		tags, err := tag.NewGlobalTagService().CreateTagsFromNames(p.Tags, p.UserID, "import")
+		if err == nil && len(tags) > 0 {
+			ids := make([]uint, 0, len(tags))
+			for _, t := range tags {
+				ids = append(ids, t.ID)
+			}
+			if err := tag.NewFileGlobalTagService().AddTagsToFile(fileID, ids, "import", 1.0); err != nil {
+				logger.Warn("添加导入标签失败: file_id=%s, %v", fileID, err)
+			}
+		}
+	}
+	for _, albumID := range p.AlbumIDs {
+		if _, err := album.AddFilesToAlbum(p.UserID, albumID, []string{fileID}); err != nil {
+			logger.Warn("添加文件到相册失败: file_id=%s, album_id=%s, %v", fileID, albumID, err)
+		}
+	}
+	if _, err := filesvc.SaveLegacyRedirects(fileID, job.ID, models.LegacyRedirectKindFile, p.LegacyURLs); err != nil {
+		logger.Warn("保存旧地址失败: file_id=%s, %v", fileID, err)
+	}
+	if _, err := filesvc.SaveLegacyRedirects(fileID, job.ID, models.LegacyRedirectKindThumb, p.LegacyThumbURLs); err != nil {
+		logger.Warn("保存旧缩略图地址失败: file_id=%s, %v", fileID, err)
+	}
+}
+
+func (s *hostImportSource) folderFor(userID uint, folderPath string) (string, error) {
+	key := fmt.Sprintf("%d/%s", userID, folderPath)
+	if id, ok := s.folders[key]; ok {
+		return id, nil
+	}
+	id, err := folder.CreateFolderByPath(userID, folderPath)
+	if err != nil {
+		return "", err
+	}
+	s.folders[key] = id
+	return id, nil
+}
+
+// availableUsername 用户名已被占用时依次尝试 name_2、name_3 …
+func availableUsername(name, key string) (string, error) {
+	name = truncate(strings.TrimSpace(name), 40)
+	if name == "" {
+		name = "user_" + truncate(key, 30)
+	}
+	for i := 1; i <= 100; i++ {
+		candidate := name
+		if i > 1 {
+			candidate = fmt.Sprintf("%s_%d", name, i)
+		}
+		var count int64
+		if err := database.DB.Model(&models.User{}).Where("username = ?", candidate).Count(&count).Error; err != nil {
+			return "", errors.Wrap(err, errors.CodeDBQueryFailed, "查询用户失败")
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+	}
+	return "", errors.New(errors.CodeUserExists, "无法为导入用户生成可用的用户名: "+name)
+}
+
+// withExtension 原文件名缺少扩展名时使用存储路径或下载文件名的扩展名
+func withExtension(name, fallback string) string {
+	if name == "" {
+		return path.Base(fallback)
+	}
+	if filepath.Ext(name) == "" {
+		name += filepath.Ext(fallback)
+	}
+	return name
+}
+
+func truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max])
+}
@@ -0,0 +1,41 @@
+package hostimport
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"pixelpunk/pkg/errors"
+)
+
+// 文件目录中指向目录外的符号链接（文件或中间目录）不能被读取
+func TestReadFileRejectsSymlinkEscape(t *testing.T) {
+	outside := t.TempDir()
+	secret := filepath.Join(outside, "secret.jpg")
+	if err := os.WriteFile(secret, []byte("secret"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(secret, filepath.Join(root, "link.jpg")); err != nil {
+		t.Skipf("symlink not supported: %v", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "uploads")); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &hostImportSource{opts: Options{FilesDir: root}}
+	for _, path := range []string{"link.jpg", "uploads/secret.jpg", "../" + filepath.Base(outside) + "/secret.jpg"} {
+		_, _, err := s.readFile(&itemPayload{ManifestFile: ManifestFile{Name: "x.jpg", Path: path}})
+		if err == nil {
+			t.Errorf("%s: expected an error", path)
+			continue
+		}
+		if e, ok := err.(*errors.Error); !ok || e.Code != errors.CodeForbidden {
+			t.Errorf("%s: error = %v, want CodeForbidden", path, err)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package hostimport
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"pixelpunk/pkg/sqldump"
+)
+
+// lskyDefaultURL 本地储存策略默认的访问前缀（public/i 链接到上传目录）
+const lskyDefaultURL = "/i"
+
+// lskyManifest 将 Lsky Pro 2.x 的 users、albums、strategies、images 表转换为清单
+// 文件路径为储存策略根目录下的 path/name，旧地址为储存策略的访问地址加上同一路径
+func lskyManifest(tables map[string]*sqldump.Table, prefix string) (*Manifest, error) {
+	images := tables[prefix+"images"]
+	if images == nil {
+		return nil, fmt.Errorf("导出数据中没有 %simages 表", prefix)
+	}
+
+	m := &Manifest{}
+	if users := tables[prefix+"users"]; users != nil {
+		for _, r := range users.Records {
+			m.Users = append(m.Users, ManifestUser{
+				Key:          r["id"],
+				Username:     r["name"],
+				Email:        r["email"],
+				PasswordHash: r["password"],
+			})
+		}
+	}
+	if albums := tables[prefix+"albums"]; albums != nil {
+		for _, r := range albums.Records {
+			m.Albums = append(m.Albums, ManifestAlbum{
+				Key:         r["id"],
+				User:        r["user_id"],
+				Name:        r["name"],
+				Description: r["intro"],
+			})
+		}
+	}
+
+	strategyURLs := map[string]string{}
+	if strategies := tables[prefix+"strategies"]; strategies != nil {
+		for _, r := range strategies.Records {
+			var configs struct {
+				URL string `json:"url"`
+			}
+			if json.Unmarshal([]byte(r["configs"]), &configs) == nil && configs.URL != "" {
+				strategyURLs[r["id"]] = strings.TrimSuffix(configs.URL, "/")
+			}
+		}
+	}
+
+	for _, r := range images.Records {
+		pathname := strings.Trim(r["path"]+"/"+r["name"], "/")
+		baseURL := strategyURLs[r["strategy_id"]]
+		if baseURL == "" {
+			baseURL = lskyDefaultURL
+		}
+		fileURL := baseURL + "/" + pathname
+
+		f := ManifestFile{
+			Path:        pathname,
+			User:        r["user_id"],
+			Name:        r["origin_name"],
+			ShortURL:    r["key"],
+			LegacyURLs:  []string{fileURL},
+			CreatedAt:   r["created_at"],
+			AccessLevel: "private",
+		}
+		if strings.HasPrefix(fileURL, "http://") || strings.HasPrefix(fileURL, "https://") {
+			f.URL = fileURL
+		}
+		if r["permission"] == "1" {
+			f.AccessLevel = "public"
+		}
+		if r["album_id"] != "" {
+			f.Albums = []string{r["album_id"]}
+		}
+		m.Files = append(m.Files, f)
+	}
+	m.normalize()
+	return m, nil
+}
@@ -0,0 +1,202 @@
+package hostimport
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// Manifest 各种导出格式转换后的统一结构，也是通用 JSON 清单的格式
+type Manifest struct {
+	Users  []ManifestUser  `json:"users"`
+	Albums []ManifestAlbum `json:"albums"`
+	Files  []ManifestFile  `json:"files"`
+}
+
+// ManifestUser 原系统的用户，Key 为清单中引用该用户的标识，默认为用户名
+type ManifestUser struct {
+	Key          string `json:"key"`
+	Username     string `json:"username"`
+	Email        string `json:"email"`
+	PasswordHash string `json:"password_hash"` // bcrypt 哈希，可以保留原密码
+}
+
+// ManifestAlbum 原系统的相册，Key 默认为相册名
+type ManifestAlbum struct {
+	Key         string `json:"key"`
+	User        string `json:"user"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// ManifestFile 原系统的文件，Path 与 URL 至少填写一个，Path 优先
+type ManifestFile struct {
+	Path            string   `json:"path"` // 相对文件目录的路径
+	URL             string   `json:"url"`  // 下载地址
+	User            string   `json:"user"`
+	Name            string   `json:"name"`
+	Folder          string   `json:"folder"`
+	Albums          []string `json:"albums"`
+	Tags            []string `json:"tags"`
+	Description     string   `json:"description"`
+	AccessLevel     string   `json:"access_level"`
+	ShortURL        string   `json:"short_url"`
+	LegacyURLs      []string `json:"legacy_urls"`       // 原图的旧地址
+	LegacyThumbURLs []string `json:"legacy_thumb_urls"` // 缩略图的旧地址
+	CreatedAt       string   `json:"created_at"`
+}
+
+// ParseManifestJSON 读取 JSON 清单
+func ParseManifestJSON(r io.Reader) (*Manifest, error) {
+	var m Manifest
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, fmt.Errorf("解析 JSON 清单失败: %w", err)
+	}
+	m.normalize()
+	return &m, nil
+}
+
+// ParseManifestCSV 读取 CSV 清单，第一行为列名，列名与 ManifestFile 的 JSON 字段相同
+// albums、tags、legacy_urls、legacy_thumb_urls 的多个值用 | 分隔；用户和相册按名称自动生成
+func ParseManifestCSV(r io.Reader) (*Manifest, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("读取 CSV 列名失败: %w", err)
+	}
+	index := map[string]int{}
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	if _, ok := index["path"]; !ok {
+		if _, ok := index["url"]; !ok {
+			return nil, fmt.Errorf("CSV 清单至少需要 path 或 url 列")
+		}
+	}
+
+	m := &Manifest{}
+	users := map[string]bool{}
+	albums := map[string]bool{}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("读取 CSV 第 %d 行失败: %w", line, err)
+		}
+		get := func(col string) string {
+			if i, ok := index[col]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		f := ManifestFile{
+			Path:            get("path"),
+			URL:             get("url"),
+			User:            get("user"),
+			Name:            get("name"),
+			Folder:          get("folder"),
+			Albums:          splitList(get("albums")),
+			Tags:            splitList(get("tags")),
+			Description:     get("description"),
+			AccessLevel:     get("access_level"),
+			ShortURL:        get("short_url"),
+			LegacyURLs:      splitList(get("legacy_urls")),
+			LegacyThumbURLs: splitList(get("legacy_thumb_urls")),
+			CreatedAt:       get("created_at"),
+		}
+		if f.User != "" && !users[f.User] {
+			users[f.User] = true
+			m.Users = append(m.Users, ManifestUser{Key: f.User, Username: f.User})
+		}
+		for _, a := range f.Albums {
+			if key := albumKey(f.User, a); !albums[key] {
+				albums[key] = true
+				m.Albums = append(m.Albums, ManifestAlbum{Key: a, User: f.User, Name: a})
+			}
+		}
+		m.Files = append(m.Files, f)
+	}
+	m.normalize()
+	return m, nil
+}
+
+// normalize 补全默认的引用标识，去掉没有来源的文件
+func (m *Manifest) normalize() {
+	for i := range m.Users {
+		if m.Users[i].Key == "" {
+			m.Users[i].Key = m.Users[i].Username
+		}
+	}
+	for i := range m.Albums {
+		if m.Albums[i].Key == "" {
+			m.Albums[i].Key = m.Albums[i].Name
+		}
+	}
+	files := m.Files[:0]
+	for _, f := range m.Files {
+		if f.Path == "" && f.URL == "" {
+			continue
+		}
+		if f.Name == "" {
+			f.Name = f.fallbackName()
+		}
+		files = append(files, f)
+	}
+	m.Files = files
+}
+
+func (f *ManifestFile) fallbackName() string {
+	src := f.Path
+	if src == "" {
+		src = f.URL
+		if i := strings.IndexAny(src, "?#"); i >= 0 {
+			src = src[:i]
+		}
+	}
+	return path.Base(strings.ReplaceAll(src, "\\", "/"))
+}
+
+// albumKey 相册按所属用户区分，不同用户可以有同名相册
+func albumKey(user, key string) string {
+	return user + "\x00" + key
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	var out []string
+	for _, v := range strings.Split(s, "|") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// parseCreatedAt 支持 RFC3339、"2006-01-02 15:04:05" 和 "2006-01-02"，没有时区的按服务器时区解析
+func parseCreatedAt(s string) *time.Time {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return &t
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
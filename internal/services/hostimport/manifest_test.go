@@ -0,0 +1,86 @@
+package hostimport
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"pixelpunk/pkg/sqldump"
+)
+
+func TestParseManifestCSV(t *testing.T) {
+	csv := "\ufeffpath,user,albums,tags,legacy_urls\n" +
+		"2024/a.jpg,alice,旅行|猫,cat|cute,/i/a.jpg\n" +
+		",alice,,,\n" +
+		"2024/b.png,bob,旅行,,\n"
+
+	m, err := ParseManifestCSV(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("ParseManifestCSV: %v", err)
+	}
+	if len(m.Files) != 2 {
+		t.Fatalf("files = %+v", m.Files)
+	}
+	if f := m.Files[0]; f.Name != "a.jpg" || !reflect.DeepEqual(f.Tags, []string{"cat", "cute"}) || !reflect.DeepEqual(f.LegacyURLs, []string{"/i/a.jpg"}) {
+		t.Errorf("file = %+v", f)
+	}
+	if len(m.Users) != 2 || m.Users[0].Key != "alice" {
+		t.Errorf("users = %+v", m.Users)
+	}
+	// 不同用户的同名相册分别创建
+	if len(m.Albums) != 3 {
+		t.Errorf("albums = %+v", m.Albums)
+	}
+}
+
+func TestLskyManifest(t *testing.T) {
+	tables := map[string]*sqldump.Table{
+		"strategies": {Records: []sqldump.Record{{"id": "1", "configs": `{"root":"uploads","url":"https://img.example.com/i/"}`}}},
+		"images": {Records: []sqldump.Record{
+			{"id": "1", "user_id": "2", "album_id": "", "strategy_id": "1", "key": "AbCd", "path": "2024/05/01", "name": "x.jpg", "origin_name": "cat.jpg", "permission": "1"},
+			{"id": "2", "user_id": "", "album_id": "5", "strategy_id": "9", "key": "EfGh", "path": "2024/05/02", "name": "y.png", "origin_name": "", "permission": "0"},
+		}},
+	}
+
+	m, err := lskyManifest(tables, "")
+	if err != nil {
+		t.Fatalf("lskyManifest: %v", err)
+	}
+	first, second := m.Files[0], m.Files[1]
+	if first.URL != "https://img.example.com/i/2024/05/01/x.jpg" || first.AccessLevel != "public" || first.Name != "cat.jpg" || first.ShortURL != "AbCd" {
+		t.Errorf("first = %+v", first)
+	}
+	if second.URL != "" || second.LegacyURLs[0] != "/i/2024/05/02/y.png" || second.AccessLevel != "private" || second.Name != "y.png" {
+		t.Errorf("second = %+v", second)
+	}
+	if !reflect.DeepEqual(second.Albums, []string{"5"}) {
+		t.Errorf("albums = %v", second.Albums)
+	}
+}
+
+func TestCheveretoManifest(t *testing.T) {
+	tables := map[string]*sqldump.Table{
+		"chv_albums":     {Records: []sqldump.Record{{"album_id": "3", "album_user_id": "1", "album_name": "私密", "album_privacy": "private_but_link"}}},
+		"chv_tags":       {Records: []sqldump.Record{{"tag_id": "1", "tag_name": "sky"}}},
+		"chv_tags_files": {Records: []sqldump.Record{{"tag_file_tag_id": "1", "tag_file_file_id": "10"}}},
+		"chv_images": {Records: []sqldump.Record{{
+			"image_id": "10", "image_user_id": "1", "image_album_id": "3", "image_name": "abc", "image_extension": "jpg",
+			"image_storage_mode": "datefolder", "image_date": "2023-07-08 09:10:11", "image_title": "标题",
+		}}},
+	}
+
+	m, err := cheveretoManifest(tables, "chv_")
+	if err != nil {
+		t.Fatalf("cheveretoManifest: %v", err)
+	}
+	f := m.Files[0]
+	if f.Path != "images/2023/07/08/abc.jpg" || f.AccessLevel != "private" || f.Description != "标题" {
+		t.Errorf("file = %+v", f)
+	}
+	if !reflect.DeepEqual(f.LegacyThumbURLs, []string{"/images/2023/07/08/abc.th.jpg", "/images/2023/07/08/abc.md.jpg"}) {
+		t.Errorf("thumbs = %v", f.LegacyThumbURLs)
+	}
+	if !reflect.DeepEqual(f.Tags, []string{"sky"}) {
+		t.Errorf("tags = %v", f.Tags)
+	}
+}
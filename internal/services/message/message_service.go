@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var messageService *MessageService
@@ -413,7 +414,7 @@ func (s *MessageService) SendExpiryNotifications() (int, int, error) {
 	// 使用子查询的方式避免直接join可能的表名问题
 	err := db.Table("file i").
 		Select("i.id as file_id, i.original_name, i.expires_at, i.user_id, u.username, u.email").
-		Joins("LEFT JOIN user u ON i.user_id = u.id").
+		Joins("LEFT JOIN ? u ON i.user_id = u.id", clause.Table{Name: models.User{}.TableName()}).
 		Where("i.expires_at IS NOT NULL AND i.expires_at < ? AND i.expiry_notification_sent = ? AND i.user_id != 0",
 			threeDaysLater, false).
 		Find(&results).Error
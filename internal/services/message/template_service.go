@@ -209,6 +209,34 @@ func (s *TemplateService) InitDefaultTemplates() error {
 			DefaultActionStyle: "primary",
 			ActionURLTemplate:  "/stats/bandwidth",
 		},
+		{
+			Type:               common.MessageTypeAccountDataExportReady,
+			Title:              "数据导出已完成",
+			Content:            "您的账号数据已打包完成，共 {{.file_count}} 个文件，压缩包大小 {{.archive_size}}。下载链接有效期至 {{.expires_at}}，过期后需要重新导出。",
+			Description:        "用户数据导出完成通知",
+			IsEnabled:          true,
+			SendEmail:          true,
+			ShowToast:          true,
+			ToastType:          "success",
+			DefaultActionType:  common.ActionTypeDownload,
+			DefaultActionText:  "下载数据",
+			DefaultActionStyle: "primary",
+			ActionURLTemplate:  "{{.download_url}}",
+		},
+		{
+			Type:               common.MessageTypeAccountDataExportFailed,
+			Title:              "数据导出失败",
+			Content:            "您的账号数据导出失败，原因：{{.reason}}。请稍后重新导出，或联系管理员。",
+			Description:        "用户数据导出失败通知",
+			IsEnabled:          true,
+			SendEmail:          false,
+			ShowToast:          true,
+			ToastType:          "error",
+			DefaultActionType:  common.ActionTypeView,
+			DefaultActionText:  "查看设置",
+			DefaultActionStyle: "secondary",
+			ActionURLTemplate:  "/settings",
+		},
 		{
 			Type:               common.MessageTypeSystemMaintenance,
 			Title:              "系统维护通知",
@@ -7,8 +7,6 @@ import (
 	"net/http"
 	"net/url"
 	"pixelpunk/internal/models"
-	"pixelpunk/pkg/database"
-	"pixelpunk/pkg/utils"
 	"strings"
 )
 
@@ -132,48 +130,15 @@ func (s *GoogleOAuthService) GetUserInfo(accessToken string) (*GoogleUserInfo, e
 	return &userInfo, nil
 }
 
-func (s *GoogleOAuthService) FindOrCreateUser(googleUser *GoogleUserInfo) (*models.User, error) {
-	db := database.GetDB()
-	if db == nil {
-		return nil, fmt.Errorf("数据库连接失败")
+/* ToIdentity 转换为统一的第三方身份 */
+func (u *GoogleUserInfo) ToIdentity() *ExternalIdentity {
+	return &ExternalIdentity{
+		Provider:      models.IdentityProviderGoogle,
+		Subject:       u.ID,
+		Email:         u.Email,
+		EmailVerified: u.VerifiedEmail,
+		Username:      firstNonEmpty(u.Name, u.Email),
+		DisplayName:   u.Name,
+		AvatarURL:     u.Picture,
 	}
-
-	var user models.User
-	err := db.Where("google_id = ?", googleUser.ID).First(&user).Error
-	if err == nil {
-		return &user, nil
-	}
-
-	username := googleUser.Email
-	if googleUser.Name != "" {
-		username = googleUser.Name
-	}
-
-	existingUser := models.User{}
-	if db.Where("username = ?", username).First(&existingUser).Error == nil {
-		for i := 1; i < 100; i++ {
-			newUsername := fmt.Sprintf("%s%d", username, i)
-			if db.Where("username = ?", newUsername).First(&existingUser).Error != nil {
-				username = newUsername
-				break
-			}
-		}
-	}
-
-	googleID := googleUser.ID
-	newUser := models.User{
-		Username:  username,
-		Email:     googleUser.Email,
-		Avatar:    googleUser.Picture,
-		GoogleID:  &googleID,
-		PathAlias: utils.GenerateRandomString(16),
-		Status:    1,
-		Role:      3,
-	}
-
-	if err := db.Create(&newUser).Error; err != nil {
-		return nil, fmt.Errorf("创建用户失败: %w", err)
-	}
-
-	return &newUser, nil
 }
@@ -0,0 +1,251 @@
+package oauth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/utils"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+/* ExternalIdentity 第三方提供方返回并统一整理后的用户身份 */
+type ExternalIdentity struct {
+	Provider      string
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Username      string
+	DisplayName   string
+	AvatarURL     string
+	Bio           string
+	Website       string
+	Role          int // 由提供方决定的角色（如OIDC组映射），0表示不同步
+}
+
+/* FindOrCreateUser 根据第三方身份查找已绑定用户，不存在时创建新用户并绑定 */
+func FindOrCreateUser(ident *ExternalIdentity) (*models.User, error) {
+	db := database.GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("数据库连接失败")
+	}
+	if ident.Subject == "" {
+		return nil, fmt.Errorf("未获取到第三方用户标识")
+	}
+
+	var identity models.UserIdentity
+	err := db.Where("provider = ? AND issuer = ? AND subject = ?", ident.Provider, ident.Issuer, ident.Subject).First(&identity).Error
+	if err == nil {
+		var user models.User
+		if err := db.First(&user, identity.UserID).Error; err != nil {
+			return nil, fmt.Errorf("绑定的用户不存在")
+		}
+		touchIdentity(db, &identity, ident)
+		syncIdentityRole(db, &user, ident.Role)
+		return &user, nil
+	}
+	if !stderrors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("查询第三方身份失败: %w", err)
+	}
+
+	email := strings.TrimSpace(ident.Email)
+	if email != "" {
+		var count int64
+		db.Model(&models.User{}).Where("email = ?", email).Count(&count)
+		if count > 0 {
+			// 不自动合并同邮箱账号，避免通过第三方账号接管已有用户
+			return nil, errors.New(errors.CodeEmailExists, "该邮箱已注册，请使用原账号登录后在个人设置中绑定")
+		}
+	} else {
+		email = placeholderEmail(ident)
+	}
+
+	role := common.UserRoleUser
+	if ident.Role == common.UserRoleAdmin {
+		role = common.UserRoleAdmin
+	}
+
+	newUser := models.User{
+		Username:  uniqueUsername(db, identityUsername(ident)),
+		Email:     email,
+		Avatar:    ident.AvatarURL,
+		Bio:       ident.Bio,
+		Website:   ident.Website,
+		PathAlias: utils.GenerateRandomString(16),
+		Status:    common.UserStatusNormal,
+		Role:      role,
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&newUser).Error; err != nil {
+			return fmt.Errorf("创建用户失败: %w", err)
+		}
+		now := common.JSONTime(time.Now())
+		identity := newIdentity(newUser.ID, ident)
+		identity.LastLoginAt = &now
+		if err := tx.Create(identity).Error; err != nil {
+			return fmt.Errorf("绑定第三方身份失败: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &newUser, nil
+}
+
+/* LinkIdentity 为已登录用户绑定新的第三方身份 */
+func LinkIdentity(userID uint, ident *ExternalIdentity) (*models.UserIdentity, error) {
+	if ident.Subject == "" {
+		return nil, errors.New(errors.CodeInvalidParameter, "未获取到第三方用户标识")
+	}
+
+	var existing models.UserIdentity
+	err := database.DB.Where("provider = ? AND issuer = ? AND subject = ?", ident.Provider, ident.Issuer, ident.Subject).First(&existing).Error
+	if err == nil {
+		if existing.UserID != userID {
+			return nil, errors.New(errors.CodeForbidden, "该第三方账号已绑定其他用户")
+		}
+		touchIdentity(database.DB, &existing, ident)
+		return &existing, nil
+	}
+
+	var count int64
+	database.DB.Model(&models.UserIdentity{}).
+		Where("user_id = ? AND provider = ? AND issuer = ?", userID, ident.Provider, ident.Issuer).
+		Count(&count)
+	if count > 0 {
+		return nil, errors.New(errors.CodeInvalidRequest, "已绑定该登录方式，请先解绑")
+	}
+
+	identity := newIdentity(userID, ident)
+	if err := database.DB.Create(identity).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBCreateFailed, "绑定第三方账号失败")
+	}
+	return identity, nil
+}
+
+/* ListUserIdentities 获取用户已绑定的第三方身份 */
+func ListUserIdentities(userID uint) ([]models.UserIdentity, error) {
+	var identities []models.UserIdentity
+	if err := database.DB.Where("user_id = ?", userID).Order("id ASC").Find(&identities).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询绑定账号失败")
+	}
+	return identities, nil
+}
+
+/* UnlinkIdentity 解绑第三方身份，未设置密码时至少保留一种登录方式 */
+func UnlinkIdentity(userID, identityID uint) error {
+	var identity models.UserIdentity
+	if err := database.DB.Where("id = ? AND user_id = ?", identityID, userID).First(&identity).Error; err != nil {
+		return errors.New(errors.CodeNotFound, "绑定记录不存在")
+	}
+
+	var user models.User
+	if err := database.DB.Select("id", "password").First(&user, userID).Error; err != nil {
+		return errors.New(errors.CodeUserNotFound, "用户不存在")
+	}
+	if user.Password == "" {
+		var count int64
+		database.DB.Model(&models.UserIdentity{}).Where("user_id = ?", userID).Count(&count)
+		if count <= 1 {
+			return errors.New(errors.CodeInvalidRequest, "这是当前唯一的登录方式，请先设置密码")
+		}
+	}
+
+	if err := database.DB.Delete(&identity).Error; err != nil {
+		return errors.Wrap(err, errors.CodeDBDeleteFailed, "解绑失败")
+	}
+	return nil
+}
+
+func newIdentity(userID uint, ident *ExternalIdentity) *models.UserIdentity {
+	return &models.UserIdentity{
+		UserID:      userID,
+		Provider:    ident.Provider,
+		Issuer:      ident.Issuer,
+		Subject:     ident.Subject,
+		Email:       ident.Email,
+		DisplayName: firstNonEmpty(ident.DisplayName, ident.Username),
+		AvatarURL:   ident.AvatarURL,
+	}
+}
+
+func touchIdentity(db *gorm.DB, identity *models.UserIdentity, ident *ExternalIdentity) {
+	now := common.JSONTime(time.Now())
+	db.Model(identity).Updates(map[string]interface{}{
+		"email":         ident.Email,
+		"display_name":  firstNonEmpty(ident.DisplayName, ident.Username),
+		"avatar_url":    ident.AvatarURL,
+		"last_login_at": now,
+	})
+}
+
+/* syncIdentityRole 按提供方映射的角色同步普通用户与管理员，超级管理员不受影响 */
+func syncIdentityRole(db *gorm.DB, user *models.User, role int) {
+	if role != common.UserRoleAdmin && role != common.UserRoleUser {
+		return
+	}
+	if user.IsSuperAdmin() || user.Role == role {
+		return
+	}
+	if err := db.Model(user).Update("role", role).Error; err == nil {
+		user.Role = role
+	}
+}
+
+func identityUsername(ident *ExternalIdentity) string {
+	name := firstNonEmpty(ident.Username, ident.DisplayName)
+	if name == "" && ident.Email != "" {
+		name = strings.SplitN(ident.Email, "@", 2)[0]
+	}
+	if name == "" {
+		name = ident.Provider + "_user"
+	}
+	if len([]rune(name)) > 40 {
+		name = string([]rune(name)[:40])
+	}
+	return name
+}
+
+func uniqueUsername(db *gorm.DB, username string) string {
+	existingUser := models.User{}
+	if db.Where("username = ?", username).First(&existingUser).Error != nil {
+		return username
+	}
+	for i := 1; i < 100; i++ {
+		newUsername := fmt.Sprintf("%s%d", username, i)
+		if db.Where("username = ?", newUsername).First(&existingUser).Error != nil {
+			return newUsername
+		}
+	}
+	return fmt.Sprintf("%s_%s", username, utils.GenerateRandomString(6))
+}
+
+// placeholderEmail 提供方未返回邮箱时生成占位邮箱，保持与历史 linuxdo_{id}@placeholder.local 格式一致
+func placeholderEmail(ident *ExternalIdentity) string {
+	subject := ident.Subject
+	if ident.Issuer != "" || len(subject) > 32 || strings.ContainsAny(subject, "@|/ ") {
+		sum := sha256.Sum256([]byte(ident.Issuer + "|" + ident.Subject))
+		subject = hex.EncodeToString(sum[:])[:16]
+	}
+	return fmt.Sprintf("%s_%s@placeholder.local", ident.Provider, subject)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
@@ -7,8 +7,7 @@ import (
 	"net/http"
 	"net/url"
 	"pixelpunk/internal/models"
-	"pixelpunk/pkg/database"
-	"pixelpunk/pkg/utils"
+	"strconv"
 	"strings"
 )
 
@@ -131,57 +130,21 @@ func (s *LinuxdoOAuthService) GetUserInfo(accessToken string) (*LinuxdoUserInfo,
 	return &userInfo, nil
 }
 
-func (s *LinuxdoOAuthService) FindOrCreateUser(linuxdoUser *LinuxdoUserInfo) (*models.User, error) {
-	db := database.GetDB()
-	if db == nil {
-		return nil, fmt.Errorf("数据库连接失败")
-	}
-
-	var user models.User
-	err := db.Where("linuxdo_id = ?", linuxdoUser.ID).First(&user).Error
-	if err == nil {
-		return &user, nil
-	}
-
-	username := linuxdoUser.Username
-	if username == "" {
-		username = linuxdoUser.Name
-	}
-
-	existingUser := models.User{}
-	if db.Where("username = ?", username).First(&existingUser).Error == nil {
-		for i := 1; i < 100; i++ {
-			newUsername := fmt.Sprintf("%s%d", username, i)
-			if db.Where("username = ?", newUsername).First(&existingUser).Error != nil {
-				username = newUsername
-				break
-			}
-		}
-	}
-
+/* ToIdentity 转换为统一的第三方身份 */
+func (u *LinuxdoUserInfo) ToIdentity() *ExternalIdentity {
 	avatar := ""
-	if linuxdoUser.AvatarTemplate != "" {
-		avatar = strings.ReplaceAll(linuxdoUser.AvatarTemplate, "{size}", "120")
+	if u.AvatarTemplate != "" {
+		avatar = strings.ReplaceAll(u.AvatarTemplate, "{size}", "120")
 		if strings.HasPrefix(avatar, "/") {
 			avatar = "https://linux.do" + avatar
 		}
 	}
 
-	linuxdoID := linuxdoUser.ID
-	placeholderEmail := fmt.Sprintf("linuxdo_%d@placeholder.local", linuxdoUser.ID)
-	newUser := models.User{
-		Username:  username,
-		Email:     placeholderEmail,
-		Avatar:    avatar,
-		LinuxdoID: &linuxdoID,
-		PathAlias: utils.GenerateRandomString(16),
-		Status:    1,
-		Role:      3,
+	return &ExternalIdentity{
+		Provider:    models.IdentityProviderLinuxdo,
+		Subject:     strconv.FormatInt(u.ID, 10),
+		Username:    firstNonEmpty(u.Username, u.Name),
+		DisplayName: u.Name,
+		AvatarURL:   avatar,
 	}
-
-	if err := db.Create(&newUser).Error; err != nil {
-		return nil, fmt.Errorf("创建用户失败: %w", err)
-	}
-
-	return &newUser, nil
 }
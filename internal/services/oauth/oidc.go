@@ -0,0 +1,570 @@
+package oauth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"pixelpunk/internal/controllers/setting/dto"
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/cache"
+	"pixelpunk/pkg/common"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	oidcStateCache     = "oauth:oidc:state:%s"
+	oidcStateTTL       = 10 * time.Minute
+	oidcMetadataTTL    = time.Hour
+	oidcMaxBodySize    = 1 << 20
+	oidcClockSkew      = time.Minute
+	pkceVerifierLength = 32
+)
+
+// 支持的ID Token签名算法（仅非对称算法）
+var oidcSigningMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+/* OIDCDiscovery OpenID Provider 元数据（/.well-known/openid-configuration） */
+type OIDCDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+type OIDCTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+}
+
+type oidcAuthState struct {
+	Verifier string `json:"verifier"`
+	Nonce    string `json:"nonce"`
+}
+
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type cachedMetadata struct {
+	discovery *OIDCDiscovery
+	keys      map[string]interface{}
+	fetchedAt time.Time
+}
+
+var (
+	oidcMetadataMu    sync.Mutex
+	oidcMetadataCache = map[string]*cachedMetadata{}
+)
+
+type OIDCService struct {
+	Config      *dto.OIDCConfig
+	ProxyConfig *ProxyConfig
+}
+
+func NewOIDCService(cfg *dto.OIDCConfig, proxyConfig *ProxyConfig) *OIDCService {
+	return &OIDCService{Config: cfg, ProxyConfig: proxyConfig}
+}
+
+/* AuthorizationURL 生成带 PKCE 与 nonce 的授权地址，state 一次性有效 */
+func (s *OIDCService) AuthorizationURL() (string, string, error) {
+	discovery, err := s.discover(false)
+	if err != nil {
+		return "", "", err
+	}
+
+	state := randomURLToken(16)
+	authState := oidcAuthState{Verifier: randomURLToken(pkceVerifierLength), Nonce: randomURLToken(16)}
+	raw, _ := json.Marshal(authState)
+	if err := cache.GetCache().Set(fmt.Sprintf(oidcStateCache, state), string(raw), oidcStateTTL); err != nil {
+		return "", "", fmt.Errorf("保存授权状态失败: %w", err)
+	}
+
+	q := url.Values{}
+	q.Set("response_type", "code")
+	q.Set("client_id", s.Config.ClientID)
+	q.Set("redirect_uri", s.Config.RedirectURI)
+	q.Set("scope", s.Config.Scope)
+	q.Set("state", state)
+	q.Set("nonce", authState.Nonce)
+	q.Set("code_challenge", PKCEChallenge(authState.Verifier))
+	q.Set("code_challenge_method", "S256")
+
+	sep := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return discovery.AuthorizationEndpoint + sep + q.Encode(), state, nil
+}
+
+/* ResolveIdentity 用授权码换取令牌，校验 ID Token 并按配置映射为统一身份 */
+func (s *OIDCService) ResolveIdentity(code, state string) (*ExternalIdentity, error) {
+	stateKey := fmt.Sprintf(oidcStateCache, state)
+	raw, err := cache.GetCache().Get(stateKey)
+	if err != nil || raw == "" {
+		return nil, fmt.Errorf("授权状态无效或已过期，请重新登录")
+	}
+	_ = cache.GetCache().Del(stateKey)
+
+	var authState oidcAuthState
+	if err := json.Unmarshal([]byte(raw), &authState); err != nil {
+		return nil, fmt.Errorf("授权状态无效")
+	}
+
+	discovery, err := s.discover(false)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenResp, err := s.exchangeCode(discovery, code, authState.Verifier)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := s.verifyIDToken(tokenResp.IDToken, authState.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	// 用户信息端点的声明补充ID Token中缺失的字段（sub必须一致）
+	if discovery.UserinfoEndpoint != "" && tokenResp.AccessToken != "" {
+		if info, err := s.fetchUserinfo(discovery.UserinfoEndpoint, tokenResp.AccessToken); err == nil {
+			if sub, _ := info["sub"].(string); sub == claims["sub"] {
+				for k, v := range info {
+					if _, exists := claims[k]; !exists {
+						claims[k] = v
+					}
+				}
+			}
+		}
+	}
+
+	return MapOIDCClaims(s.Config, strings.TrimRight(discovery.Issuer, "/"), claims)
+}
+
+/* MapOIDCClaims 按声明映射、域名/用户组白名单与角色映射生成统一身份 */
+func MapOIDCClaims(cfg *dto.OIDCConfig, issuer string, claims map[string]interface{}) (*ExternalIdentity, error) {
+	subject := ClaimString(claims, "sub")
+	if subject == "" {
+		return nil, fmt.Errorf("ID Token 缺少 sub 声明")
+	}
+
+	email := strings.ToLower(ClaimString(claims, cfg.ClaimEmail))
+	emailVerified := true
+	if v, ok := LookupClaim(claims, "email_verified"); ok {
+		switch t := v.(type) {
+		case bool:
+			emailVerified = t
+		case string:
+			emailVerified = strings.EqualFold(t, "true")
+		}
+	}
+
+	if len(cfg.AllowedDomains) > 0 {
+		if email == "" || !EmailDomainAllowed(email, cfg.AllowedDomains) {
+			return nil, fmt.Errorf("该账号的邮箱域名不允许登录")
+		}
+	}
+	if email != "" && !emailVerified && (cfg.RequireVerifiedEmail || len(cfg.AllowedDomains) > 0) {
+		return nil, fmt.Errorf("该账号的邮箱尚未验证")
+	}
+
+	groups := ClaimStrings(claims, cfg.ClaimGroups)
+	if len(cfg.AllowedGroups) > 0 && !anyGroupMatch(groups, cfg.AllowedGroups) {
+		return nil, fmt.Errorf("该账号所在用户组不允许登录")
+	}
+
+	ident := &ExternalIdentity{
+		Provider:      models.IdentityProviderOIDC,
+		Issuer:        issuer,
+		Subject:       subject,
+		Email:         email,
+		EmailVerified: emailVerified,
+		Username:      ClaimString(claims, cfg.ClaimUsername),
+		DisplayName:   ClaimString(claims, cfg.ClaimName),
+		AvatarURL:     ClaimString(claims, cfg.ClaimAvatar),
+	}
+	if !emailVerified {
+		// 未验证的邮箱不用于创建账号，避免占用他人邮箱
+		ident.Email = ""
+	}
+	if mapping := ParseGroupRoleMapping(cfg.GroupRoleMapping); len(mapping) > 0 {
+		ident.Role = RoleForGroups(groups, mapping)
+	}
+	return ident, nil
+}
+
+/* ParseGroupRoleMapping 解析 "group=admin,group2=user" 形式的用户组角色映射 */
+func ParseGroupRoleMapping(value string) map[string]int {
+	mapping := map[string]int{}
+	for _, pair := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == '\n' || r == ';' }) {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		group := strings.TrimSpace(parts[0])
+		if group == "" {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(parts[1])) {
+		case "admin":
+			mapping[group] = common.UserRoleAdmin
+		case "user":
+			mapping[group] = common.UserRoleUser
+		}
+	}
+	return mapping
+}
+
+/* RoleForGroups 返回匹配到的最高角色，未匹配时为普通用户 */
+func RoleForGroups(groups []string, mapping map[string]int) int {
+	role := common.UserRoleUser
+	for _, g := range groups {
+		if r, ok := mapping[g]; ok && r < role {
+			role = r
+		}
+	}
+	return role
+}
+
+/* EmailDomainAllowed 判断邮箱域名是否在白名单内（支持子域名） */
+func EmailDomainAllowed(email string, domains []string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := strings.ToLower(email[at+1:])
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(d), "@"))
+		if d != "" && (domain == d || strings.HasSuffix(domain, "."+d)) {
+			return true
+		}
+	}
+	return false
+}
+
+/* LookupClaim 按 a.b.c 路径读取嵌套声明 */
+func LookupClaim(claims map[string]interface{}, path string) (interface{}, bool) {
+	if path == "" {
+		return nil, false
+	}
+	if v, ok := claims[path]; ok {
+		return v, true
+	}
+	var current interface{} = claims
+	for _, part := range strings.Split(path, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func ClaimString(claims map[string]interface{}, path string) string {
+	v, ok := LookupClaim(claims, path)
+	if !ok {
+		return ""
+	}
+	switch t := v.(type) {
+	case string:
+		return strings.TrimSpace(t)
+	case float64:
+		return fmt.Sprintf("%.0f", t)
+	}
+	return ""
+}
+
+/* ClaimStrings 读取字符串数组声明，兼容以逗号或空格分隔的字符串 */
+func ClaimStrings(claims map[string]interface{}, path string) []string {
+	v, ok := LookupClaim(claims, path)
+	if !ok {
+		return nil
+	}
+	var result []string
+	switch t := v.(type) {
+	case []interface{}:
+		for _, item := range t {
+			if s, ok := item.(string); ok && s != "" {
+				result = append(result, s)
+			}
+		}
+	case []string:
+		result = append(result, t...)
+	case string:
+		result = strings.FieldsFunc(t, func(r rune) bool { return r == ',' || r == ' ' })
+	}
+	return result
+}
+
+/* PKCEChallenge 计算 S256 code_challenge */
+func PKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func anyGroupMatch(groups, allowed []string) bool {
+	for _, g := range groups {
+		for _, a := range allowed {
+			if g == a {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func randomURLToken(n int) string {
+	buf := make([]byte, n)
+	_, _ = rand.Read(buf)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+/* discover 读取并缓存服务发现文档与签名公钥 */
+func (s *OIDCService) discover(refreshKeys bool) (*OIDCDiscovery, error) {
+	issuer := strings.TrimRight(s.Config.Issuer, "/")
+	if issuer == "" {
+		return nil, fmt.Errorf("OIDC 签发方地址未配置")
+	}
+
+	oidcMetadataMu.Lock()
+	cached := oidcMetadataCache[issuer]
+	oidcMetadataMu.Unlock()
+	if cached != nil && !refreshKeys && time.Since(cached.fetchedAt) < oidcMetadataTTL {
+		return cached.discovery, nil
+	}
+
+	var discovery OIDCDiscovery
+	if err := s.getJSON(issuer+"/.well-known/openid-configuration", "", &discovery); err != nil {
+		return nil, fmt.Errorf("获取 OIDC 服务发现文档失败: %w", err)
+	}
+	if strings.TrimRight(discovery.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("OIDC 服务发现文档的 issuer 与配置不一致: %s", discovery.Issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JwksURI == "" {
+		return nil, fmt.Errorf("OIDC 服务发现文档缺少必要的端点")
+	}
+
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := s.getJSON(discovery.JwksURI, "", &jwks); err != nil {
+		return nil, fmt.Errorf("获取 OIDC 签名公钥失败: %w", err)
+	}
+	keys := map[string]interface{}{}
+	for _, k := range jwks.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		if pub, err := parseJWK(k); err == nil {
+			keys[k.Kid] = pub
+		}
+	}
+
+	oidcMetadataMu.Lock()
+	oidcMetadataCache[issuer] = &cachedMetadata{discovery: &discovery, keys: keys, fetchedAt: time.Now()}
+	oidcMetadataMu.Unlock()
+	return &discovery, nil
+}
+
+func (s *OIDCService) signingKey(kid string) (interface{}, error) {
+	issuer := strings.TrimRight(s.Config.Issuer, "/")
+	lookup := func() interface{} {
+		oidcMetadataMu.Lock()
+		defer oidcMetadataMu.Unlock()
+		cached := oidcMetadataCache[issuer]
+		if cached == nil {
+			return nil
+		}
+		if kid == "" && len(cached.keys) == 1 {
+			for _, k := range cached.keys {
+				return k
+			}
+		}
+		return cached.keys[kid]
+	}
+
+	if key := lookup(); key != nil {
+		return key, nil
+	}
+	// 密钥轮换后本地缓存可能过期，强制刷新一次
+	if _, err := s.discover(true); err != nil {
+		return nil, err
+	}
+	if key := lookup(); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("未找到 ID Token 签名公钥: kid=%s", kid)
+}
+
+func (s *OIDCService) verifyIDToken(idToken, nonce string) (map[string]interface{}, error) {
+	if idToken == "" {
+		return nil, fmt.Errorf("令牌响应缺少 id_token")
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(idToken, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return s.signingKey(kid)
+	},
+		jwt.WithValidMethods(oidcSigningMethods),
+		jwt.WithAudience(s.Config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(oidcClockSkew),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ID Token 校验失败: %w", err)
+	}
+
+	// 部分提供方的 iss 带结尾斜杠，比较时统一去掉
+	if iss, _ := claims["iss"].(string); strings.TrimRight(iss, "/") != strings.TrimRight(s.Config.Issuer, "/") {
+		return nil, fmt.Errorf("ID Token issuer 不匹配")
+	}
+	if got, _ := claims["nonce"].(string); got != nonce {
+		return nil, fmt.Errorf("ID Token nonce 不匹配")
+	}
+	if azp, ok := claims["azp"].(string); ok && azp != "" && azp != s.Config.ClientID {
+		return nil, fmt.Errorf("ID Token azp 不匹配")
+	}
+	return claims, nil
+}
+
+func (s *OIDCService) exchangeCode(discovery *OIDCDiscovery, code, verifier string) (*OIDCTokenResponse, error) {
+	data := url.Values{}
+	data.Set("grant_type", "authorization_code")
+	data.Set("code", code)
+	data.Set("redirect_uri", s.Config.RedirectURI)
+	data.Set("client_id", s.Config.ClientID)
+	data.Set("code_verifier", verifier)
+	if s.Config.ClientSecret != "" {
+		data.Set("client_secret", s.Config.ClientSecret)
+	}
+
+	req, err := http.NewRequest("POST", discovery.TokenEndpoint, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	body, status, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("OIDC 令牌端点返回错误: %s, 状态码: %d", string(body), status)
+	}
+
+	var tokenResp OIDCTokenResponse
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	return &tokenResp, nil
+}
+
+func (s *OIDCService) fetchUserinfo(endpoint, accessToken string) (map[string]interface{}, error) {
+	info := map[string]interface{}{}
+	if err := s.getJSON(endpoint, accessToken, &info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func (s *OIDCService) getJSON(endpoint, bearer string, out interface{}) error {
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+
+	body, status, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("状态码: %d", status)
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("解析响应失败: %w", err)
+	}
+	return nil
+}
+
+func (s *OIDCService) do(req *http.Request) ([]byte, int, error) {
+	client := getHTTPClient(s.ProxyConfig)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, oidcMaxBodySize))
+	if err != nil {
+		return nil, 0, fmt.Errorf("读取响应失败: %w", err)
+	}
+	return body, resp.StatusCode, nil
+}
+
+func parseJWK(k jsonWebKey) (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("不支持的曲线: %s", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	}
+	return nil, fmt.Errorf("不支持的密钥类型: %s", k.Kty)
+}
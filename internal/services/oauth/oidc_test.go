@@ -0,0 +1,57 @@
+package oauth
+
+import (
+	"testing"
+
+	"pixelpunk/internal/controllers/setting/dto"
+	"pixelpunk/pkg/common"
+)
+
+func TestMapOIDCClaims(t *testing.T) {
+	cfg := &dto.OIDCConfig{
+		ClaimUsername:        "preferred_username",
+		ClaimEmail:           "email",
+		ClaimName:            "name",
+		ClaimGroups:          "realm_access.roles",
+		GroupRoleMapping:     "ops=admin, staff=user",
+		AllowedDomains:       []string{"example.com"},
+		RequireVerifiedEmail: true,
+	}
+	claims := map[string]interface{}{
+		"sub":                "abc-123",
+		"email":              "Alice@Dev.Example.com",
+		"email_verified":     true,
+		"preferred_username": "alice",
+		"realm_access":       map[string]interface{}{"roles": []interface{}{"staff", "ops"}},
+	}
+
+	ident, err := MapOIDCClaims(cfg, "https://id.example.com", claims)
+	if err != nil {
+		t.Fatalf("MapOIDCClaims error: %v", err)
+	}
+	if ident.Subject != "abc-123" || ident.Username != "alice" || ident.Email != "alice@dev.example.com" {
+		t.Fatalf("声明映射不正确: %+v", ident)
+	}
+	if ident.Role != common.UserRoleAdmin {
+		t.Fatalf("用户组映射角色应为管理员, got %d", ident.Role)
+	}
+
+	claims["email"] = "bob@other.org"
+	if _, err := MapOIDCClaims(cfg, "https://id.example.com", claims); err == nil {
+		t.Fatalf("不在白名单内的邮箱域名应被拒绝")
+	}
+
+	claims["email"] = "bob@example.com"
+	claims["email_verified"] = false
+	if _, err := MapOIDCClaims(cfg, "https://id.example.com", claims); err == nil {
+		t.Fatalf("未验证的邮箱应被拒绝")
+	}
+}
+
+// RFC 7636 附录B示例
+func TestPKCEChallenge(t *testing.T) {
+	got := PKCEChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk")
+	if got != "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM" {
+		t.Fatalf("PKCEChallenge = %s", got)
+	}
+}
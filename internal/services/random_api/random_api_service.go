@@ -91,7 +91,7 @@ func GetRandomAPIList(userID uint, page, size int, status int, search string) ([
 	}
 
 	if search != "" {
-		query = query.Where("name LIKE ?", "%"+search+"%")
+		query = query.Where(database.ILike("name LIKE ?"), "%"+search+"%")
 	}
 
 	if err := query.Count(&total).Error; err != nil {
@@ -307,6 +307,8 @@ func executeFileHardDeletion(file *models.File) error {
 
 	db.Where("item_type = ? AND item_id = ?", "file", fileID).Delete(&models.ShareItem{})
 
+	db.Where("file_id = ?", fileID).Delete(&models.AlbumItem{})
+
 	db.Where("file_id = ?", fileID).Delete(&models.UploadSession{})
 
 	if err := deletePhysicalFiles(file); err != nil {
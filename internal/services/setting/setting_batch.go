@@ -42,7 +42,7 @@ func BatchCreateSettings(createDTOs *dto.BatchSettingCreateDTO) (*dto.BatchSetti
 	err := db.Transaction(func(tx *gorm.DB) error {
 		for _, createDTO := range createDTOs.Settings {
 			var count int64
-			if err := tx.Model(&models.Setting{}).Where("`key` = ?", createDTO.Key).Count(&count).Error; err != nil {
+			if err := tx.Model(&models.Setting{}).Where(map[string]interface{}{"key": createDTO.Key}).Count(&count).Error; err != nil {
 				result.Failed = append(result.Failed, dto.BatchFailedItem{Key: createDTO.Key, Message: "检查设置键名失败: " + err.Error()})
 				continue
 			}
@@ -132,7 +132,7 @@ func BatchUpsertSettings(upsertDTOs *dto.BatchUpsertSettingDTO) (*dto.BatchSetti
 	err := db.Transaction(func(tx *gorm.DB) error {
 		for _, settingDTO := range upsertDTOs.Settings {
 			var setting models.Setting
-			err := tx.Where("`key` = ?", settingDTO.Key).First(&setting).Error
+			err := tx.Where(map[string]interface{}{"key": settingDTO.Key}).First(&setting).Error
 
 			normalizedValue := normalizeSettingValue(settingDTO.Key, settingDTO.Value)
 
@@ -239,7 +239,7 @@ func BatchUpdateSettings(updateDTOs *dto.BatchSettingUpdateDTO) (*dto.BatchSetti
 	err := db.Transaction(func(tx *gorm.DB) error {
 		for _, updateDTO := range updateDTOs.Settings {
 			var setting models.Setting
-			err := tx.Where("`key` = ?", updateDTO.Key).First(&setting).Error
+			err := tx.Where(map[string]interface{}{"key": updateDTO.Key}).First(&setting).Error
 			if err != nil {
 				result.Failed = append(result.Failed, dto.BatchFailedItem{Key: updateDTO.Key, Message: "设置不存在"})
 				continue
@@ -20,14 +20,14 @@ func GetSettings(query *dto.SettingQueryDTO) (*dto.SettingListResponseDTO, error
 	dbQuery := db.Model(&models.Setting{})
 
 	if query != nil && query.Group != "" {
-		dbQuery = dbQuery.Where("`group` = ?", query.Group)
+		dbQuery = dbQuery.Where(map[string]interface{}{"group": query.Group})
 	}
 
 	if query != nil && query.Key != "" {
-		dbQuery = dbQuery.Where("`key` = ?", query.Key)
+		dbQuery = dbQuery.Where(map[string]interface{}{"key": query.Key})
 	}
 
-	if err := dbQuery.Order("`group` ASC, `key` ASC").Find(&settings).Error; err != nil {
+	if err := dbQuery.Order(orderByGroupAndKey).Find(&settings).Error; err != nil {
 		return &dto.SettingListResponseDTO{Settings: []dto.SettingResponseDTO{}}, nil
 	}
 
@@ -60,7 +60,7 @@ func GetSetting(key string) (*dto.SettingResponseDTO, error) {
 	db := database.GetDB()
 	var setting models.Setting
 
-	if err := db.Where("`key` = ?", key).First(&setting).Error; err != nil {
+	if err := db.Where(map[string]interface{}{"key": key}).First(&setting).Error; err != nil {
 		return nil, errors.Wrap(err, errors.CodeDBNoRecord, fmt.Sprintf("设置 %s 不存在", key))
 	}
 
@@ -88,7 +88,7 @@ func CreateSetting(createDTO *dto.SettingCreateDTO) (*dto.SettingResponseDTO, er
 	db := database.GetDB()
 
 	var count int64
-	if err := db.Model(&models.Setting{}).Where("`key` = ?", createDTO.Key).Count(&count).Error; err != nil {
+	if err := db.Model(&models.Setting{}).Where(map[string]interface{}{"key": createDTO.Key}).Count(&count).Error; err != nil {
 		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "检查设置键名是否存在失败")
 	}
 	if count > 0 {
@@ -147,7 +147,7 @@ func UpdateSetting(updateDTO *dto.SettingUpdateDTO) (*dto.SettingResponseDTO, er
 	db := database.GetDB()
 
 	var setting models.Setting
-	if err := db.Where("`key` = ?", updateDTO.Key).First(&setting).Error; err != nil {
+	if err := db.Where(map[string]interface{}{"key": updateDTO.Key}).First(&setting).Error; err != nil {
 		return nil, errors.Wrap(err, errors.CodeDBNoRecord, fmt.Sprintf("设置 %s 不存在", updateDTO.Key))
 	}
 
@@ -215,7 +215,7 @@ func UpdateSetting(updateDTO *dto.SettingUpdateDTO) (*dto.SettingResponseDTO, er
 func DeleteSetting(key string) error {
 	db := database.GetDB()
 	var setting models.Setting
-	if err := db.Where("`key` = ?", key).First(&setting).Error; err != nil {
+	if err := db.Where(map[string]interface{}{"key": key}).First(&setting).Error; err != nil {
 		return errors.Wrap(err, errors.CodeDBNoRecord, fmt.Sprintf("设置 %s 不存在", key))
 	}
 	if setting.IsSystem {
@@ -232,7 +232,7 @@ func DeleteSetting(key string) error {
 func GetSettingValue(key string, defaultValue interface{}) (interface{}, error) {
 	db := database.GetDB()
 	var setting models.Setting
-	if err := db.Where("`key` = ?", key).First(&setting).Error; err != nil {
+	if err := db.Where(map[string]interface{}{"key": key}).First(&setting).Error; err != nil {
 		return defaultValue, nil
 	}
 	return parseSettingValue(setting), nil
@@ -23,7 +23,7 @@ func GetSettingDirectFromDB(group, key string, defaultValue interface{}) interfa
 
 	var row SettingRow
 	err := db.Table("setting").
-		Where("`group` = ? AND `key` = ?", group, key).
+		Where(map[string]interface{}{"group": group, "key": key}).
 		Select("value, type").
 		First(&row).Error
 
@@ -107,8 +107,8 @@ func GetMultipleSettingsDirectFromDB(group string, keys []string) (map[string]in
 
 	var rows []SettingRow
 	err := db.Table("setting").
-		Where("`group` = ? AND `key` IN (?)", group, keys).
-		Select("key, value, type").
+		Where(map[string]interface{}{"group": group, "key": keys}).
+		Select("key", "value", "type").
 		Find(&rows).Error
 
 	if err != nil {
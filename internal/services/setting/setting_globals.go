@@ -1,5 +1,7 @@
 package setting
 
+import "gorm.io/gorm/clause"
+
 var settingService *SettingService
 
 var settingChangeHandlers map[string]func(value string)
@@ -12,3 +14,12 @@ const (
 )
 
 type SettingService struct{}
+
+// key 和 group 是 SQL 保留字，排序时交给 GORM 按数据库类型加引号
+var (
+	orderByKey         = clause.OrderByColumn{Column: clause.Column{Name: "key"}}
+	orderByGroupAndKey = clause.OrderBy{Columns: []clause.OrderByColumn{
+		{Column: clause.Column{Name: "group"}},
+		{Column: clause.Column{Name: "key"}},
+	}}
+)
@@ -27,9 +27,9 @@ func GetSettingsByGroupAsMap(group string) (*dto.SettingMapResponseDTO, error) {
 	}
 
 	var settings []models.Setting
-	query := db.Model(&models.Setting{}).Where("`group` = ?", group)
+	query := db.Model(&models.Setting{}).Where(map[string]interface{}{"group": group})
 
-	if err := query.Order("`key` ASC").Find(&settings).Error; err != nil {
+	if err := query.Order(orderByKey).Find(&settings).Error; err != nil {
 		return &dto.SettingMapResponseDTO{Group: group, Settings: make(map[string]interface{})}, nil
 	}
 
@@ -162,6 +162,10 @@ func GetGlobalSettingsGroups() (*dto.GlobalSettingsResponseDTO, error) {
 				result.OAuthProviders.LinuxdoEnabled = enabledBool
 			}
 		}
+		if oidc := GetOIDCConfig(); oidc.Enabled {
+			result.OAuthProviders.OIDCEnabled = true
+			result.OAuthProviders.OIDCName = oidc.DisplayName
+		}
 	}
 
 	result.DeployMode = common.GetDeployMode()
@@ -443,6 +447,55 @@ func GetOAuthConfig() (*dto.OAuthConfigResponseDTO, error) {
 	return result, nil
 }
 
+/* GetOIDCConfig 获取通用 OIDC 配置（oauth 分组，oidc_ 前缀） */
+func GetOIDCConfig() *dto.OIDCConfig {
+	cfg := &dto.OIDCConfig{
+		Enabled:              GetBool("oauth", "oidc_enabled", false),
+		DisplayName:          GetString("oauth", "oidc_display_name", "OIDC"),
+		Issuer:               strings.TrimRight(GetString("oauth", "oidc_issuer", ""), "/"),
+		ClientID:             GetString("oauth", "oidc_client_id", ""),
+		ClientSecret:         GetString("oauth", "oidc_client_secret", ""),
+		RedirectURI:          GetString("oauth", "oidc_redirect_uri", ""),
+		Scope:                GetString("oauth", "oidc_scope", "openid email profile"),
+		ClaimUsername:        GetString("oauth", "oidc_claim_username", "preferred_username"),
+		ClaimEmail:           GetString("oauth", "oidc_claim_email", "email"),
+		ClaimName:            GetString("oauth", "oidc_claim_name", "name"),
+		ClaimAvatar:          GetString("oauth", "oidc_claim_avatar", "picture"),
+		ClaimGroups:          GetString("oauth", "oidc_claim_groups", "groups"),
+		GroupRoleMapping:     GetString("oauth", "oidc_group_role_mapping", ""),
+		AllowedDomains:       splitSettingList(GetString("oauth", "oidc_allowed_domains", "")),
+		AllowedGroups:        splitSettingList(GetString("oauth", "oidc_allowed_groups", "")),
+		RequireVerifiedEmail: GetBool("oauth", "oidc_require_verified_email", true),
+		ProxyEnabled:         GetBool("oauth", "oidc_proxy_enabled", false),
+	}
+
+	// 代理沿用 OAuth 统一代理配置
+	if cfg.ProxyEnabled {
+		cfg.ProxyDynamic = GetBool("oauth", "oauth_proxy_dynamic", false)
+		cfg.ProxyAPIURL = GetString("oauth", "oauth_proxy_api_url", "")
+		cfg.ProxyType = GetString("oauth", "oauth_proxy_type", "")
+		cfg.ProxyHost = GetString("oauth", "oauth_proxy_host", "")
+		cfg.ProxyPort = GetString("oauth", "oauth_proxy_port", "")
+		cfg.ProxyUsername = GetString("oauth", "oauth_proxy_username", "")
+		cfg.ProxyPassword = GetString("oauth", "oauth_proxy_password", "")
+	}
+	return cfg
+}
+
+// splitSettingList 拆分逗号或换行分隔的配置项
+func splitSettingList(value string) []string {
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == '\n' || r == ';'
+	})
+	result := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if f = strings.TrimSpace(f); f != "" {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
 /* DynamicProxyInfo 动态代理信息 */
 type DynamicProxyInfo struct {
 	Type     string // 代理类型: socks5
@@ -99,7 +99,7 @@ func syncGlobalSettings() {
 	var baseUrlValue string
 	result := db.Table("setting").
 		Select("value").
-		Where("`key` = ? AND `group` = ?", "site_base_url", "website").
+		Where(map[string]interface{}{"key": "site_base_url", "group": "website"}).
 		Limit(1).
 		Scan(&baseUrlValue)
 
@@ -115,7 +115,7 @@ func syncGlobalSettings() {
 	var hideRemoteUrlValue string
 	result = db.Table("setting").
 		Select("value").
-		Where("`key` = ? AND `group` = ?", "hide_remote_url", "security").
+		Where(map[string]interface{}{"key": "hide_remote_url", "group": "security"}).
 		Limit(1).
 		Scan(&hideRemoteUrlValue)
 
@@ -133,7 +133,7 @@ func syncGlobalSettings() {
 	var aiAnalysisEnabledValue string
 	result = db.Table("setting").
 		Select("value").
-		Where("`key` = ? AND `group` = ?", "ai_analysis_enabled", "upload").
+		Where(map[string]interface{}{"key": "ai_analysis_enabled", "group": "upload"}).
 		Limit(1).
 		Scan(&aiAnalysisEnabledValue)
 
@@ -151,7 +151,7 @@ func syncGlobalSettings() {
 	var adminEmailValue string
 	result = db.Table("setting").
 		Select("value").
-		Where("`key` = ? AND `group` = ?", "admin_email", "website").
+		Where(map[string]interface{}{"key": "admin_email", "group": "website"}).
 		Limit(1).
 		Scan(&adminEmailValue)
 
@@ -169,7 +169,7 @@ func syncGlobalSettings() {
 	var strictFileValidationValue string
 	result = db.Table("setting").
 		Select("value").
-		Where("`key` = ? AND `group` = ?", "strict_file_validation", "upload").
+		Where(map[string]interface{}{"key": "strict_file_validation", "group": "upload"}).
 		Limit(1).
 		Scan(&strictFileValidationValue)
 
@@ -188,7 +188,7 @@ func syncGlobalSettings() {
 	var webpConvertEnabledValue string
 	result = db.Table("setting").
 		Select("value").
-		Where("`key` = ? AND `group` = ?", "webp_convert_enabled", "upload").
+		Where(map[string]interface{}{"key": "webp_convert_enabled", "group": "upload"}).
 		Limit(1).
 		Scan(&webpConvertEnabledValue)
 
@@ -207,7 +207,7 @@ func syncGlobalSettings() {
 	var webpConvertQualityValue string
 	result = db.Table("setting").
 		Select("value").
-		Where("`key` = ? AND `group` = ?", "webp_convert_quality", "upload").
+		Where(map[string]interface{}{"key": "webp_convert_quality", "group": "upload"}).
 		Limit(1).
 		Scan(&webpConvertQualityValue)
 
@@ -0,0 +1,64 @@
+package setting
+
+import (
+	"encoding/json"
+	"pixelpunk/internal/controllers/setting/dto"
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"time"
+)
+
+// settingExportVersion 设置导出文件的格式版本
+const settingExportVersion = 1
+
+/* ExportSettings 导出系统设置，groups 为空时导出全部分组 */
+func ExportSettings(groups []string) (*dto.SettingExportDTO, error) {
+	db := database.GetDB()
+
+	query := db.Model(&models.Setting{}).Order(orderByGroupAndKey)
+	if len(groups) > 0 {
+		query = query.Where(map[string]interface{}{"group": groups})
+	}
+	var settings []models.Setting
+	if err := query.Find(&settings).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询设置失败")
+	}
+
+	export := &dto.SettingExportDTO{
+		Version:    settingExportVersion,
+		ExportedAt: time.Now().Format(time.RFC3339),
+		Settings:   make([]dto.SettingCreateDTO, 0, len(settings)),
+	}
+	for _, s := range settings {
+		export.Settings = append(export.Settings, dto.SettingCreateDTO{
+			Key:         s.Key,
+			Value:       parseSettingValue(s),
+			Type:        s.Type,
+			Group:       s.Group,
+			Description: s.Description,
+			IsSystem:    s.IsSystem,
+		})
+	}
+	return export, nil
+}
+
+/* ImportSettings 导入 ExportSettings 导出的设置，已存在的设置被覆盖，不在文件中的设置保持不变 */
+func ImportSettings(data []byte) (*dto.BatchSettingResponseDTO, error) {
+	var export dto.SettingExportDTO
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, errors.Wrap(err, errors.CodeInvalidParameter, "设置文件格式错误")
+	}
+	if export.Version <= 0 || export.Version > settingExportVersion {
+		return nil, errors.New(errors.CodeInvalidParameter, "不支持的设置文件版本")
+	}
+	if len(export.Settings) == 0 {
+		return nil, errors.New(errors.CodeInvalidParameter, "设置文件中没有设置项")
+	}
+	for _, s := range export.Settings {
+		if s.Key == "" || s.Group == "" || s.Type == "" {
+			return nil, errors.New(errors.CodeInvalidParameter, "设置项缺少键名、分组或类型")
+		}
+	}
+	return BatchUpsertSettings(&dto.BatchUpsertSettingDTO{Settings: export.Settings})
+}
@@ -23,7 +23,7 @@ func GetAllShares(query *dto.AdminShareListQueryDTO) ([]models.Share, int64, err
 
 	if query.Keyword != "" {
 		keyword := "%" + query.Keyword + "%"
-		db = db.Where("(name LIKE ? OR description LIKE ? OR share_key LIKE ?)", keyword, keyword, keyword)
+		db = db.Where(database.ILike("(name LIKE ? OR description LIKE ? OR share_key LIKE ?)"), keyword, keyword, keyword)
 	}
 
 	if query.UserID > 0 {
@@ -246,7 +246,7 @@ func AdminGetAllVisitors(query *dto.VisitorQueryDTO) ([]models.ShareVisitorInfo,
 
 	if query != nil && query.Keyword != "" {
 		keyword := "%" + query.Keyword + "%"
-		db = db.Where("(visitor_name LIKE ? OR visitor_email LIKE ? OR ip_address LIKE ?)",
+		db = db.Where(database.ILike("(visitor_name LIKE ? OR visitor_email LIKE ? OR ip_address LIKE ?)"),
 			keyword, keyword, keyword)
 	}
 
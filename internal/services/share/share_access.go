@@ -4,6 +4,7 @@ import (
 	stderrors "errors"
 	"fmt"
 	"pixelpunk/internal/models"
+	albumsvc "pixelpunk/internal/services/album"
 	"pixelpunk/pkg/common"
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/errors"
@@ -15,7 +16,10 @@ import (
 	"gorm.io/gorm"
 )
 
-func GetShareForView(shareKey string, folderID string) (map[string]interface{}, error) {
+// maxSharedAlbumFiles 分享页面单个相册最多展示的文件数
+const maxSharedAlbumFiles = 500
+
+func GetShareForView(shareKey string, folderID string, albumID string) (map[string]interface{}, error) {
 	share, err := GetShareByKey(shareKey)
 	if err != nil {
 		return nil, err
@@ -32,9 +36,27 @@ func GetShareForView(shareKey string, folderID string) (map[string]interface{},
 	}
 
 	var currentFolder *models.Folder
+	var currentAlbum *models.Album
 	var parentFolderID string
 
-	if folderID != "" && folderID != "0" {
+	if albumID != "" {
+		isAlbumInShare := false
+		for _, item := range shareItems {
+			if item.ItemType == common.ShareItemTypeAlbum && item.ItemID == albumID {
+				isAlbumInShare = true
+				break
+			}
+		}
+		if !isAlbumInShare {
+			return nil, errors.New(errors.CodeValidationFailed, "该相册不包含在分享内容中")
+		}
+
+		var album models.Album
+		if err := database.DB.Where("id = ? AND user_id = ?", albumID, share.UserID).First(&album).Error; err != nil {
+			return nil, errors.New(errors.CodeNotFound, "指定的相册不存在或无权访问")
+		}
+		currentAlbum = &album
+	} else if folderID != "" && folderID != "0" {
 		var folder models.Folder
 		if err := database.DB.Where("id = ? AND user_id = ?", folderID, share.UserID).First(&folder).Error; err != nil {
 			return nil, errors.New(errors.CodeFolderNotFound, "指定的文件夹不存在或无权访问")
@@ -93,9 +115,35 @@ func GetShareForView(shareKey string, folderID string) (map[string]interface{},
 	}
 
 	folders := []models.Folder{}
+	albums := []models.Album{}
 	files := []map[string]interface{}{}
 
-	if currentFolder != nil {
+	if currentAlbum != nil {
+		fileIDs, err := albumsvc.AlbumFileIDs(currentAlbum, maxSharedAlbumFiles)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(fileIDs) > 0 {
+			var albumFiles []models.File
+			if err := database.DB.Preload("AIInfo").Where("id IN ? AND user_id = ?", fileIDs, share.UserID).
+				Where("status <> ?", "pending_deletion").
+				Find(&albumFiles).Error; err != nil {
+				return nil, err
+			}
+
+			// 保持相册的排序
+			fileByID := make(map[string]models.File, len(albumFiles))
+			for _, file := range albumFiles {
+				fileByID[file.ID] = file
+			}
+			for _, id := range fileIDs {
+				if file, ok := fileByID[id]; ok {
+					files = append(files, buildSharedFileMap(file, shareKey))
+				}
+			}
+		}
+	} else if currentFolder != nil {
 		if err := database.DB.Where("parent_id = ? AND user_id = ?", folderID, share.UserID).Find(&folders).Error; err != nil {
 			return nil, err
 		}
@@ -108,62 +156,7 @@ func GetShareForView(shareKey string, folderID string) (map[string]interface{},
 		}
 
 		for _, file := range folderImages {
-			fullURL, fullThumbURL, _ := storage.GetFullURLs(file)
-
-			if fullURL != "" {
-				if strings.Contains(fullURL, "?") {
-					fullURL = fullURL + "&share=" + shareKey
-				} else {
-					fullURL = fullURL + "?share=" + shareKey
-				}
-			}
-
-			if fullThumbURL != "" {
-				if strings.Contains(fullThumbURL, "?") {
-					fullThumbURL = fullThumbURL + "&share=" + shareKey
-				} else {
-					fullThumbURL = fullThumbURL + "?share=" + shareKey
-				}
-			}
-
-			fileMap := map[string]interface{}{
-				"id":             file.ID,
-				"display_name":   file.DisplayName,
-				"description":    file.Description,
-				"url":            file.URL,
-				"thumb_url":      file.ThumbURL,
-				"size":           file.Size,
-				"size_formatted": file.SizeFormatted,
-				"width":          file.Width,
-				"height":         file.Height,
-				"format":         file.Format,
-				"mime":           file.Mime,
-				"created_at":     file.CreatedAt,
-				"updated_at":     file.UpdatedAt,
-				"full_url":       fullURL,            // 添加完整URL
-				"full_thumb_url": fullThumbURL,       // 添加完整缩略图URL
-				"resolution":     file.Resolution,    // 添加分辨率信息
-				"is_recommended": file.IsRecommended, // 添加推荐标记
-				"ai_info":        file.AIInfo,        // 添加AI信息
-			}
-
-			var tags []map[string]interface{}
-			var globalTags []models.GlobalTag
-			if err := database.DB.Model(&models.GlobalTag{}).
-				Joins("JOIN file_global_tag_relation ON file_global_tag_relation.tag_id = global_tag.id").
-				Where("file_global_tag_relation.file_id = ?", file.ID).
-				Find(&globalTags).Error; err == nil {
-				for _, globalTag := range globalTags {
-					tags = append(tags, map[string]interface{}{
-						"id":         globalTag.ID,
-						"name":       globalTag.Name,
-						"created_at": globalTag.CreatedAt,
-					})
-				}
-			}
-			fileMap["tags"] = tags
-
-			files = append(files, fileMap)
+			files = append(files, buildSharedFileMap(file, shareKey))
 		}
 	} else {
 		for _, item := range shareItems {
@@ -172,67 +165,17 @@ func GetShareForView(shareKey string, folderID string) (map[string]interface{},
 				if err := database.DB.Where("id = ? AND user_id = ?", item.ItemID, share.UserID).First(&folder).Error; err == nil {
 					folders = append(folders, folder)
 				}
+			} else if item.ItemType == common.ShareItemTypeAlbum {
+				var album models.Album
+				if err := database.DB.Where("id = ? AND user_id = ?", item.ItemID, share.UserID).First(&album).Error; err == nil {
+					albums = append(albums, album)
+				}
 			} else if item.ItemType == common.ShareItemTypeFile {
 				var file models.File
 				if err := database.DB.Preload("AIInfo").Where("id = ? AND user_id = ?", item.ItemID, share.UserID).
 					Where("status <> ?", "pending_deletion").
 					First(&file).Error; err == nil {
-					fullURL, fullThumbURL, _ := storage.GetFullURLs(file)
-
-					if fullURL != "" {
-						if strings.Contains(fullURL, "?") {
-							fullURL = fullURL + "&share=" + shareKey
-						} else {
-							fullURL = fullURL + "?share=" + shareKey
-						}
-					}
-
-					if fullThumbURL != "" {
-						if strings.Contains(fullThumbURL, "?") {
-							fullThumbURL = fullThumbURL + "&share=" + shareKey
-						} else {
-							fullThumbURL = fullThumbURL + "?share=" + shareKey
-						}
-					}
-
-					fileMap := map[string]interface{}{
-						"id":             file.ID,
-						"display_name":   file.DisplayName,
-						"description":    file.Description,
-						"url":            file.URL,
-						"thumb_url":      file.ThumbURL,
-						"size":           file.Size,
-						"size_formatted": file.SizeFormatted,
-						"width":          file.Width,
-						"height":         file.Height,
-						"format":         file.Format,
-						"mime":           file.Mime,
-						"created_at":     file.CreatedAt,
-						"updated_at":     file.UpdatedAt,
-						"full_url":       fullURL,            // 添加完整URL
-						"full_thumb_url": fullThumbURL,       // 添加完整缩略图URL
-						"resolution":     file.Resolution,    // 添加分辨率信息
-						"is_recommended": file.IsRecommended, // 添加推荐标记
-						"ai_info":        file.AIInfo,        // 添加AI信息
-					}
-
-					var tags []map[string]interface{}
-					var globalTags []models.GlobalTag
-					if err := database.DB.Model(&models.GlobalTag{}).
-						Joins("JOIN file_global_tag_relation ON file_global_tag_relation.tag_id = global_tag.id").
-						Where("file_global_tag_relation.file_id = ?", file.ID).
-						Find(&globalTags).Error; err == nil {
-						for _, globalTag := range globalTags {
-							tags = append(tags, map[string]interface{}{
-								"id":         globalTag.ID,
-								"name":       globalTag.Name,
-								"created_at": globalTag.CreatedAt,
-							})
-						}
-					}
-					fileMap["tags"] = tags
-
-					files = append(files, fileMap)
+					files = append(files, buildSharedFileMap(file, shareKey))
 				}
 			}
 		}
@@ -257,14 +200,76 @@ func GetShareForView(shareKey string, folderID string) (map[string]interface{},
 			"avatar":   user.Avatar,
 		},
 		"folders":        folders,
+		"albums":         albums,
 		"files":          files,
 		"current_folder": currentFolder,
+		"current_album":  currentAlbum,
 		"parent_id":      parentFolderID,
 	}
 
 	return result, nil
 }
 
+// buildSharedFileMap 构建分享页面的文件信息，访问地址附带分享key
+func buildSharedFileMap(file models.File, shareKey string) map[string]interface{} {
+	fullURL, fullThumbURL, _ := storage.GetFullURLs(file)
+
+	if fullURL != "" {
+		if strings.Contains(fullURL, "?") {
+			fullURL = fullURL + "&share=" + shareKey
+		} else {
+			fullURL = fullURL + "?share=" + shareKey
+		}
+	}
+
+	if fullThumbURL != "" {
+		if strings.Contains(fullThumbURL, "?") {
+			fullThumbURL = fullThumbURL + "&share=" + shareKey
+		} else {
+			fullThumbURL = fullThumbURL + "?share=" + shareKey
+		}
+	}
+
+	fileMap := map[string]interface{}{
+		"id":             file.ID,
+		"display_name":   file.DisplayName,
+		"description":    file.Description,
+		"url":            file.URL,
+		"thumb_url":      file.ThumbURL,
+		"size":           file.Size,
+		"size_formatted": file.SizeFormatted,
+		"width":          file.Width,
+		"height":         file.Height,
+		"format":         file.Format,
+		"mime":           file.Mime,
+		"created_at":     file.CreatedAt,
+		"updated_at":     file.UpdatedAt,
+		"full_url":       fullURL,            // 添加完整URL
+		"full_thumb_url": fullThumbURL,       // 添加完整缩略图URL
+		"resolution":     file.Resolution,    // 添加分辨率信息
+		"is_recommended": file.IsRecommended, // 添加推荐标记
+		"ai_info":        file.AIInfo,        // 添加AI信息
+	}
+
+	var tags []map[string]interface{}
+	var globalTags []models.GlobalTag
+	if err := database.DB.Model(&models.GlobalTag{}).
+		Joins("JOIN file_global_tag_relation ON file_global_tag_relation.tag_id = global_tag.id").
+		Where("file_global_tag_relation.file_id = ?", file.ID).
+		Find(&globalTags).Error; err == nil {
+		for _, globalTag := range globalTags {
+			tags = append(tags, map[string]interface{}{
+				"id":         globalTag.ID,
+				"name":       globalTag.Name,
+				"created_at": globalTag.CreatedAt,
+			})
+		}
+	}
+	fileMap["tags"] = tags
+
+	return fileMap
+}
+
 /* GenerateAccessToken 生成临时访问令牌 */
 func GenerateAccessToken(shareKey string, password string, clientIP, userAgent string) (string, error) {
 	share, err := GetShareByKey(shareKey)
@@ -391,7 +396,11 @@ func ValidateSharedFileAccess(shareID, fileID string) (bool, error) {
 
 	if err != nil {
 		if stderrors.Is(err, gorm.ErrRecordNotFound) {
-			return validateFileInSharedFolder(shareID, fileID)
+			inFolder, err := validateFileInSharedFolder(shareID, fileID)
+			if err != nil || inFolder {
+				return inFolder, err
+			}
+			return IsFileInSharedAlbums(shareID, share.UserID, fileID), nil
 		}
 		return false, err
 	}
@@ -399,6 +408,28 @@ func ValidateSharedFileAccess(shareID, fileID string) (bool, error) {
 	return true, nil
 }
 
+/* IsFileInSharedAlbums 判断文件是否属于分享中的某个相册，相册必须属于分享者 */
+func IsFileInSharedAlbums(shareID string, ownerID uint, fileID string) bool {
+	var albumIDs []string
+	database.DB.Model(&models.ShareItem{}).
+		Where("share_id = ? AND item_type = ?", shareID, common.ShareItemTypeAlbum).
+		Pluck("item_id", &albumIDs)
+	if len(albumIDs) == 0 {
+		return false
+	}
+
+	var albums []models.Album
+	if err := database.DB.Where("id IN ? AND user_id = ?", albumIDs, ownerID).Find(&albums).Error; err != nil {
+		return false
+	}
+	for i := range albums {
+		if albumsvc.AlbumContainsFile(&albums[i], fileID) {
+			return true
+		}
+	}
+	return false
+}
+
 func validateFileInSharedFolder(shareID, fileID string) (bool, error) {
 	var file models.File
 	if err := database.DB.Where("id = ?", fileID).First(&file).Error; err != nil {
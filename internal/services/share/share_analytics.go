@@ -0,0 +1,493 @@
+package share
+
+import (
+	"crypto/md5"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"pixelpunk/internal/controllers/share/dto"
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/geoip"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	analyticsDefaultDays = 30
+	analyticsMaxDays     = 366
+	analyticsDefaultTopN = 10
+	analyticsDateLayout  = "2006-01-02"
+	analyticsUnknownKey  = "unknown"
+	analyticsDirectKey   = "direct"
+)
+
+/* newShareAccessLog 构造访问日志，并解析UA族与IP地理位置 */
+func newShareAccessLog(shareID, eventType, fileID, ip, userAgent, referer string) models.ShareAccessLog {
+	ua := common.ParseUserAgent(userAgent)
+	if len(referer) > 255 {
+		referer = referer[:255]
+	}
+
+	log := models.ShareAccessLog{
+		ID:         generateID(),
+		ShareID:    shareID,
+		EventType:  eventType,
+		FileID:     fileID,
+		AccessedAt: common.JSONTime(time.Now()),
+		IPAddress:  ip,
+		UserAgent:  userAgent,
+		Referer:    referer,
+		Browser:    ua.Browser,
+		OS:         ua.OS,
+		DeviceType: ua.DeviceType,
+	}
+
+	if loc := geoip.Lookup(ip); loc != nil {
+		log.CountryCode = loc.CountryCode
+		log.Country = loc.Country
+	}
+
+	return log
+}
+
+/* LogShareFileEvent 记录分享内的文件级事件（查看原图、下载） */
+func LogShareFileEvent(shareKey, fileID, eventType, ip, userAgent, referer string) error {
+	var share models.Share
+	if err := database.DB.Select("id").Where("share_key = ?", shareKey).First(&share).Error; err != nil {
+		return err
+	}
+
+	log := newShareAccessLog(share.ID, eventType, fileID, ip, userAgent, referer)
+	return database.DB.Create(&log).Error
+}
+
+type analyticsRange struct {
+	start       time.Time
+	end         time.Time // 不包含
+	granularity string
+	topN        int
+}
+
+func resolveAnalyticsRange(query *dto.ShareAnalyticsQueryDTO) (*analyticsRange, error) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+
+	r := &analyticsRange{
+		start:       today.AddDate(0, 0, -(analyticsDefaultDays - 1)),
+		end:         today.AddDate(0, 0, 1),
+		granularity: "day",
+		topN:        analyticsDefaultTopN,
+	}
+
+	if query == nil {
+		return r, nil
+	}
+
+	if query.EndDate != "" {
+		end, err := time.ParseInLocation(analyticsDateLayout, query.EndDate, time.Local)
+		if err != nil {
+			return nil, errors.New(errors.CodeInvalidParameter, "结束日期格式错误")
+		}
+		r.end = end.AddDate(0, 0, 1)
+		r.start = end.AddDate(0, 0, -(analyticsDefaultDays - 1))
+	}
+
+	if query.StartDate != "" {
+		start, err := time.ParseInLocation(analyticsDateLayout, query.StartDate, time.Local)
+		if err != nil {
+			return nil, errors.New(errors.CodeInvalidParameter, "开始日期格式错误")
+		}
+		r.start = start
+	}
+
+	if !r.start.Before(r.end) {
+		return nil, errors.New(errors.CodeInvalidParameter, "开始日期不能晚于结束日期")
+	}
+
+	if r.end.Sub(r.start) > analyticsMaxDays*24*time.Hour {
+		return nil, errors.New(errors.CodeInvalidParameter, fmt.Sprintf("统计区间不能超过%d天", analyticsMaxDays))
+	}
+
+	if query.Granularity != "" {
+		r.granularity = query.Granularity
+	}
+	if query.TopN > 0 {
+		r.topN = query.TopN
+	}
+
+	return r, nil
+}
+
+/* bucketKey 按统计粒度计算时间桶，周以周一为起点 */
+func (r *analyticsRange) bucketKey(t time.Time) string {
+	switch r.granularity {
+	case "month":
+		return t.Format("2006-01")
+	case "week":
+		offset := (int(t.Weekday()) + 6) % 7
+		return t.AddDate(0, 0, -offset).Format(analyticsDateLayout)
+	default:
+		return t.Format(analyticsDateLayout)
+	}
+}
+
+func (r *analyticsRange) bucketKeys() []string {
+	keys := []string{}
+	seen := map[string]bool{}
+	for d := r.start; d.Before(r.end); d = d.AddDate(0, 0, 1) {
+		key := r.bucketKey(d)
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+type accessLogRow struct {
+	ShareID     string
+	EventType   string
+	FileID      string
+	AccessedAt  common.JSONTime
+	IPAddress   string
+	UserAgent   string
+	Referer     string
+	CountryCode string
+	Country     string
+	Browser     string
+	OS          string
+	DeviceType  string
+}
+
+/* normalize 为旧数据补全UA族与地理位置信息 */
+func (row *accessLogRow) normalize() {
+	if row.EventType == "" {
+		row.EventType = common.ShareEventView
+	}
+
+	if row.Browser == "" && row.UserAgent != "" {
+		ua := common.ParseUserAgent(row.UserAgent)
+		row.Browser, row.OS, row.DeviceType = ua.Browser, ua.OS, ua.DeviceType
+	}
+
+	if row.CountryCode == "" {
+		if loc := geoip.Lookup(row.IPAddress); loc != nil {
+			row.CountryCode, row.Country = loc.CountryCode, loc.Country
+		}
+	}
+}
+
+func (row *accessLogRow) visitorKey() string {
+	sum := md5.Sum([]byte(row.IPAddress + "|" + row.UserAgent))
+	return hex.EncodeToString(sum[:8])
+}
+
+func refererHost(referer string) string {
+	if referer == "" {
+		return analyticsDirectKey
+	}
+	u, err := url.Parse(referer)
+	if err != nil || u.Hostname() == "" {
+		return analyticsUnknownKey
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+/* iterateAccessLogs 逐行遍历区间内的访问日志，shareID为空表示全部分享 */
+func iterateAccessLogs(shareID string, r *analyticsRange, fn func(row *accessLogRow)) error {
+	db := database.DB.Model(&models.ShareAccessLog{}).
+		Select("share_id, event_type, file_id, accessed_at, ip_address, user_agent, referer, country_code, country, browser, os, device_type").
+		Where("accessed_at >= ? AND accessed_at < ?", r.start, r.end)
+	if shareID != "" {
+		db = db.Where("share_id = ?", shareID)
+	}
+
+	rows, err := db.Order("accessed_at ASC").Rows()
+	if err != nil {
+		return errors.Wrap(err, errors.CodeDBQueryFailed, "查询分享访问日志失败")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row accessLogRow
+		if err := database.DB.ScanRows(rows, &row); err != nil {
+			return errors.Wrap(err, errors.CodeDBQueryFailed, "读取分享访问日志失败")
+		}
+		row.normalize()
+		fn(&row)
+	}
+
+	return nil
+}
+
+type shareAnalyticsAggregator struct {
+	r             *analyticsRange
+	summary       dto.ShareAnalyticsSummary
+	visitors      map[string]struct{}
+	points        map[string]*dto.ShareAnalyticsPoint
+	pointVisitors map[string]map[string]struct{}
+	referrers     map[string]int64
+	browsers      map[string]int64
+	os            map[string]int64
+	devices       map[string]int64
+	countries     map[string]int64
+	countryNames  map[string]string
+	files         map[string]*dto.ShareFileAnalytics
+	fileVisitors  map[string]map[string]struct{}
+}
+
+func newShareAnalyticsAggregator(r *analyticsRange) *shareAnalyticsAggregator {
+	agg := &shareAnalyticsAggregator{
+		r:             r,
+		visitors:      map[string]struct{}{},
+		points:        map[string]*dto.ShareAnalyticsPoint{},
+		pointVisitors: map[string]map[string]struct{}{},
+		referrers:     map[string]int64{},
+		browsers:      map[string]int64{},
+		os:            map[string]int64{},
+		devices:       map[string]int64{},
+		countries:     map[string]int64{},
+		countryNames:  map[string]string{},
+		files:         map[string]*dto.ShareFileAnalytics{},
+		fileVisitors:  map[string]map[string]struct{}{},
+	}
+	for _, key := range r.bucketKeys() {
+		agg.points[key] = &dto.ShareAnalyticsPoint{Date: key}
+		agg.pointVisitors[key] = map[string]struct{}{}
+	}
+	return agg
+}
+
+func (agg *shareAnalyticsAggregator) add(row *accessLogRow) {
+	visitor := row.visitorKey()
+	agg.visitors[visitor] = struct{}{}
+
+	key := agg.r.bucketKey(time.Time(row.AccessedAt))
+	point, ok := agg.points[key]
+	if !ok {
+		point = &dto.ShareAnalyticsPoint{Date: key}
+		agg.points[key] = point
+		agg.pointVisitors[key] = map[string]struct{}{}
+	}
+	agg.pointVisitors[key][visitor] = struct{}{}
+
+	switch row.EventType {
+	case common.ShareEventDownload:
+		agg.summary.Downloads++
+		point.Downloads++
+	case common.ShareEventFileView:
+		agg.summary.FileViews++
+		point.FileViews++
+	default:
+		agg.summary.Views++
+		point.Views++
+	}
+
+	if row.EventType == common.ShareEventView {
+		agg.referrers[refererHost(row.Referer)]++
+		agg.browsers[row.Browser]++
+		agg.os[row.OS]++
+		agg.devices[row.DeviceType]++
+
+		country := row.CountryCode
+		if country == "" {
+			country = analyticsUnknownKey
+		} else {
+			agg.countryNames[country] = row.Country
+		}
+		agg.countries[country]++
+	}
+
+	if row.FileID != "" {
+		file, ok := agg.files[row.FileID]
+		if !ok {
+			file = &dto.ShareFileAnalytics{FileID: row.FileID}
+			agg.files[row.FileID] = file
+			agg.fileVisitors[row.FileID] = map[string]struct{}{}
+		}
+		if row.EventType == common.ShareEventDownload {
+			file.Downloads++
+		} else {
+			file.Views++
+		}
+		agg.fileVisitors[row.FileID][visitor] = struct{}{}
+	}
+}
+
+func topBuckets(counter map[string]int64, names map[string]string, limit int) []dto.ShareAnalyticsBucket {
+	buckets := make([]dto.ShareAnalyticsBucket, 0, len(counter))
+	for key, count := range counter {
+		name := key
+		if names != nil && names[key] != "" {
+			name = names[key]
+		}
+		if key == "" {
+			key, name = analyticsUnknownKey, analyticsUnknownKey
+		}
+		buckets = append(buckets, dto.ShareAnalyticsBucket{Key: key, Name: name, Count: count})
+	}
+
+	sort.Slice(buckets, func(i, j int) bool {
+		if buckets[i].Count != buckets[j].Count {
+			return buckets[i].Count > buckets[j].Count
+		}
+		return buckets[i].Key < buckets[j].Key
+	})
+
+	if limit > 0 && len(buckets) > limit {
+		buckets = buckets[:limit]
+	}
+	return buckets
+}
+
+func (agg *shareAnalyticsAggregator) result() *dto.ShareAnalyticsResponseDTO {
+	agg.summary.UniqueVisitors = int64(len(agg.visitors))
+
+	resp := &dto.ShareAnalyticsResponseDTO{
+		StartDate:   agg.r.start.Format(analyticsDateLayout),
+		EndDate:     agg.r.end.AddDate(0, 0, -1).Format(analyticsDateLayout),
+		Granularity: agg.r.granularity,
+		GeoEnabled:  geoip.Enabled(),
+		Summary:     agg.summary,
+		TimeSeries:  make([]dto.ShareAnalyticsPoint, 0, len(agg.points)),
+		Referrers:   topBuckets(agg.referrers, nil, agg.r.topN),
+		Browsers:    topBuckets(agg.browsers, nil, agg.r.topN),
+		OS:          topBuckets(agg.os, nil, agg.r.topN),
+		Devices:     topBuckets(agg.devices, nil, 0),
+		Countries:   topBuckets(agg.countries, agg.countryNames, agg.r.topN),
+		Files:       make([]dto.ShareFileAnalytics, 0, len(agg.files)),
+	}
+
+	for key, point := range agg.points {
+		point.UniqueVisitors = int64(len(agg.pointVisitors[key]))
+		resp.TimeSeries = append(resp.TimeSeries, *point)
+	}
+	sort.Slice(resp.TimeSeries, func(i, j int) bool {
+		return resp.TimeSeries[i].Date < resp.TimeSeries[j].Date
+	})
+
+	fileIDs := make([]string, 0, len(agg.files))
+	for fileID, file := range agg.files {
+		file.UniqueVisitors = int64(len(agg.fileVisitors[fileID]))
+		fileIDs = append(fileIDs, fileID)
+	}
+	fillFileNames(agg.files, fileIDs)
+	for _, file := range agg.files {
+		resp.Files = append(resp.Files, *file)
+	}
+	sort.Slice(resp.Files, func(i, j int) bool {
+		if resp.Files[i].Views+resp.Files[i].Downloads != resp.Files[j].Views+resp.Files[j].Downloads {
+			return resp.Files[i].Views+resp.Files[i].Downloads > resp.Files[j].Views+resp.Files[j].Downloads
+		}
+		return resp.Files[i].FileID < resp.Files[j].FileID
+	})
+
+	return resp
+}
+
+func fillFileNames(files map[string]*dto.ShareFileAnalytics, fileIDs []string) {
+	if len(fileIDs) == 0 {
+		return
+	}
+
+	var rows []models.File
+	if err := database.DB.Select("id, display_name, original_name").Where("id IN ?", fileIDs).Find(&rows).Error; err != nil {
+		return
+	}
+
+	for _, row := range rows {
+		if file, ok := files[row.ID]; ok {
+			file.DisplayName = row.DisplayName
+			if file.DisplayName == "" {
+				file.DisplayName = row.OriginalName
+			}
+		}
+	}
+}
+
+/* GetShareAnalytics 获取分享访问分析数据，shareID为空时统计全部分享（管理员） */
+func GetShareAnalytics(shareID string, query *dto.ShareAnalyticsQueryDTO) (*dto.ShareAnalyticsResponseDTO, error) {
+	r, err := resolveAnalyticsRange(query)
+	if err != nil {
+		return nil, err
+	}
+
+	agg := newShareAnalyticsAggregator(r)
+	if err := iterateAccessLogs(shareID, r, agg.add); err != nil {
+		return nil, err
+	}
+
+	resp := agg.result()
+	resp.ShareID = shareID
+	return resp, nil
+}
+
+/* ExportShareAnalyticsCSV 导出分享访问分析为CSV，exportType: events/series/files */
+func ExportShareAnalyticsCSV(w io.Writer, shareID string, exportType string, query *dto.ShareAnalyticsQueryDTO) error {
+	r, err := resolveAnalyticsRange(query)
+	if err != nil {
+		return err
+	}
+
+	// 写入UTF-8 BOM，便于Excel正确识别中文
+	if _, err := w.Write([]byte{0xEF, 0xBB, 0xBF}); err != nil {
+		return err
+	}
+	writer := csv.NewWriter(w)
+
+	switch exportType {
+	case "series", "files":
+		agg := newShareAnalyticsAggregator(r)
+		if err := iterateAccessLogs(shareID, r, agg.add); err != nil {
+			return err
+		}
+		resp := agg.result()
+
+		if exportType == "series" {
+			writer.Write([]string{"date", "views", "file_views", "downloads", "unique_visitors"})
+			for _, p := range resp.TimeSeries {
+				writer.Write([]string{p.Date, itoa(p.Views), itoa(p.FileViews), itoa(p.Downloads), itoa(p.UniqueVisitors)})
+			}
+		} else {
+			writer.Write([]string{"file_id", "display_name", "views", "downloads", "unique_visitors"})
+			for _, f := range resp.Files {
+				writer.Write([]string{f.FileID, f.DisplayName, itoa(f.Views), itoa(f.Downloads), itoa(f.UniqueVisitors)})
+			}
+		}
+	default:
+		writer.Write([]string{"accessed_at", "share_id", "event_type", "file_id", "ip_address", "country_code", "country", "browser", "os", "device_type", "referer", "user_agent"})
+		err := iterateAccessLogs(shareID, r, func(row *accessLogRow) {
+			writer.Write([]string{
+				time.Time(row.AccessedAt).Format("2006-01-02 15:04:05"),
+				row.ShareID,
+				row.EventType,
+				row.FileID,
+				row.IPAddress,
+				row.CountryCode,
+				row.Country,
+				row.Browser,
+				row.OS,
+				row.DeviceType,
+				row.Referer,
+				row.UserAgent,
+			})
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func itoa(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
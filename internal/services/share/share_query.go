@@ -139,15 +139,8 @@ func LogShareAccess(shareID string, viewedItems []map[string]string, c *dto.Visi
 		return err
 	}
 
-	log := models.ShareAccessLog{
-		ID:          generateID(),
-		ShareID:     shareID,
-		AccessedAt:  common.JSONTime(time.Now()),
-		IPAddress:   ip,
-		UserAgent:   userAgent,
-		Referer:     referer,
-		ViewedItems: viewedItemsJSON,
-	}
+	log := newShareAccessLog(shareID, common.ShareEventView, "", ip, userAgent, referer)
+	log.ViewedItems = viewedItemsJSON
 
 	if c != nil {
 		log.VisitorName = c.Name
@@ -167,7 +160,7 @@ func GetUserShares(userID uint, query *dto.ShareListQueryDTO) ([]map[string]inte
 
 	if query.Keyword != "" {
 		keyword := "%" + query.Keyword + "%"
-		db = db.Where("(name LIKE ? OR description LIKE ?)", keyword, keyword)
+		db = db.Where(database.ILike("(name LIKE ? OR description LIKE ?)"), keyword, keyword)
 	}
 
 	var total int64
@@ -230,10 +223,12 @@ func GetUserShares(userID uint, query *dto.ShareListQueryDTO) ([]map[string]inte
 	for i, share := range shares {
 		folderCount := int64(0)
 		fileCount := int64(0)
+		albumCount := int64(0)
 
 		if countMap[share.ID] != nil {
 			folderCount = countMap[share.ID]["folder"]
 			fileCount = countMap[share.ID]["file"]
+			albumCount = countMap[share.ID]["album"]
 		}
 
 		shareMap := map[string]interface{}{
@@ -250,6 +245,7 @@ func GetUserShares(userID uint, query *dto.ShareListQueryDTO) ([]map[string]inte
 			"updated_at":             share.UpdatedAt,
 			"folder_count":           folderCount,
 			"file_count":             fileCount,
+			"album_count":            albumCount,
 			"collect_visitor_info":   share.CollectVisitorInfo,
 			"notification_on_access": share.NotificationOnAccess,
 		}
@@ -0,0 +1,123 @@
+package share
+
+import (
+	"pixelpunk/internal/models"
+	albumsvc "pixelpunk/internal/services/album"
+	filesvc "pixelpunk/internal/services/file"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+)
+
+/* SharedFileIDs 收集分享内的全部文件ID：直接分享的文件、分享文件夹及其子文件夹中的文件、分享相册中的文件 */
+func SharedFileIDs(share models.Share) ([]string, error) {
+	shareItems, err := GetShareItems(share.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	fileIDs := []string{}
+	folderIDs := []string{}
+	for _, item := range shareItems {
+		switch item.ItemType {
+		case common.ShareItemTypeFile:
+			fileIDs = append(fileIDs, item.ItemID)
+		case common.ShareItemTypeFolder:
+			folderIDs = append(folderIDs, item.ItemID)
+		case common.ShareItemTypeAlbum:
+			var album models.Album
+			if err := database.DB.Where("id = ? AND user_id = ?", item.ItemID, share.UserID).First(&album).Error; err != nil {
+				continue
+			}
+			ids, err := albumsvc.AlbumFileIDs(&album, maxSharedAlbumFiles)
+			if err != nil {
+				return nil, err
+			}
+			fileIDs = append(fileIDs, ids...)
+		}
+	}
+
+	if len(folderIDs) > 0 {
+		allFolderIDs, err := collectSharedFolderIDs(share.UserID, folderIDs)
+		if err != nil {
+			return nil, err
+		}
+		var folderFileIDs []string
+		if err := database.DB.Model(&models.File{}).
+			Where("folder_id IN ? AND user_id = ?", allFolderIDs, share.UserID).
+			Pluck("id", &folderFileIDs).Error; err != nil {
+			return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询分享文件夹中的文件失败")
+		}
+		fileIDs = append(fileIDs, folderFileIDs...)
+	}
+	return fileIDs, nil
+}
+
+// collectSharedFolderIDs 逐层展开分享文件夹的全部子文件夹
+func collectSharedFolderIDs(ownerID uint, rootIDs []string) ([]string, error) {
+	seen := make(map[string]bool, len(rootIDs))
+	all := []string{}
+	current := rootIDs
+	for len(current) > 0 {
+		next := []string{}
+		for _, id := range current {
+			if !seen[id] {
+				seen[id] = true
+				all = append(all, id)
+				next = append(next, id)
+			}
+		}
+		if len(next) == 0 {
+			break
+		}
+		var children []string
+		if err := database.DB.Model(&models.Folder{}).
+			Where("parent_id IN ? AND user_id = ?", next, ownerID).
+			Pluck("id", &children).Error; err != nil {
+			return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询分享子文件夹失败")
+		}
+		current = children
+	}
+	return all, nil
+}
+
+/* SharedTimelineScope 分享内文件的时间线查询范围 */
+func SharedTimelineScope(share models.Share) (filesvc.AdminFileSearchParams, error) {
+	fileIDs, err := SharedFileIDs(share)
+	if err != nil {
+		return filesvc.AdminFileSearchParams{}, err
+	}
+	return filesvc.AdminFileSearchParams{UserID: share.UserID, FileIDs: fileIDs}, nil
+}
+
+/* GetSharedTimelineFiles 按拍摄时间分页获取分享内的文件，访问地址附带分享key */
+func GetSharedTimelineFiles(share models.Share, params filesvc.AdminFileSearchParams) ([]map[string]interface{}, int64, error) {
+	page, total, err := filesvc.AdminGetFileList(params)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	files := []map[string]interface{}{}
+	if len(page) == 0 {
+		return files, total, nil
+	}
+
+	ids := make([]string, 0, len(page))
+	for _, f := range page {
+		ids = append(ids, f.ID)
+	}
+	var records []models.File
+	if err := database.DB.Preload("AIInfo").Where("id IN ?", ids).Find(&records).Error; err != nil {
+		return nil, 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询分享文件失败")
+	}
+	fileByID := make(map[string]models.File, len(records))
+	for _, file := range records {
+		fileByID[file.ID] = file
+	}
+	for _, id := range ids {
+		if file, ok := fileByID[id]; ok {
+			files = append(files, buildSharedFileMap(file, share.ShareKey))
+		}
+	}
+	return files, total, nil
+}
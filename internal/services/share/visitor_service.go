@@ -63,7 +63,7 @@ func GetShareVisitors(shareID string, query *dto.VisitorQueryDTO) ([]models.Shar
 
 	if query != nil && query.Keyword != "" {
 		keyword := "%" + query.Keyword + "%"
-		db = db.Where("(visitor_name LIKE ? OR visitor_email LIKE ? OR ip_address LIKE ?)",
+		db = db.Where(database.ILike("(visitor_name LIKE ? OR visitor_email LIKE ? OR ip_address LIKE ?)"),
 			keyword, keyword, keyword)
 	}
 
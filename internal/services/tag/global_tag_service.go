@@ -213,6 +213,10 @@ func (s *GlobalTagService) MergeGlobalTags(operatorID uint, sourceTagIDs []uint,
 			}
 		}
 
+		if err := mergeTagHierarchyTx(tx, sources, &target); err != nil {
+			return err
+		}
+
 		if err := tx.Where("id IN ?", sourceTagIDs).Delete(&models.GlobalTag{}).Error; err != nil {
 			return fmt.Errorf("删除源标签失败: %v", err)
 		}
@@ -274,6 +278,10 @@ func (s *GlobalTagService) UpdateGlobalTag(tagID uint, name *string, description
 			if cnt > 0 {
 				return nil, fmt.Errorf("同名标签已存在")
 			}
+			var alias models.GlobalTagAlias
+			if err := s.db.Where("alias = ?", NormalizeTagAlias(newName)).First(&alias).Error; err == nil && alias.TagID != tag.ID {
+				return nil, fmt.Errorf("该名称已是其他标签的同义词")
+			}
 			updates["name"] = newName
 		}
 	}
@@ -305,10 +313,13 @@ func (s *GlobalTagService) CreateOrGetGlobalTag(name, description string, creato
 		return nil, fmt.Errorf("数据库连接失败")
 	}
 
-	var existingTag models.GlobalTag
-	err := s.db.Where("name = ?", name).First(&existingTag).Error
-	if err == nil {
-		return &existingTag, nil
+	// 同名标签或同义词命中时直接返回规范标签
+	existingTag, err := s.ResolveTagByName(name)
+	if err != nil {
+		return nil, err
+	}
+	if existingTag != nil {
+		return existingTag, nil
 	}
 
 	newTag := &models.GlobalTag{
@@ -450,7 +461,7 @@ func (s *GlobalTagService) SearchTags(keyword string, limit int) ([]models.Globa
 	}
 
 	var tags []models.GlobalTag
-	query := s.db.Where("name LIKE ? OR description LIKE ?", "%"+keyword+"%", "%"+keyword+"%")
+	query := s.db.Where(database.ILike("name LIKE ? OR description LIKE ?"), "%"+keyword+"%", "%"+keyword+"%")
 
 	if limit > 0 {
 		query = query.Limit(limit)
@@ -513,6 +524,18 @@ func (s *GlobalTagService) DeleteGlobalTag(tagID uint) error {
 		return fmt.Errorf("删除分类关联失败: %v", err)
 	}
 
+	if err := s.db.Where("tag_id = ?", tagID).Delete(&models.GlobalTagAlias{}).Error; err != nil {
+		return fmt.Errorf("删除标签同义词失败: %v", err)
+	}
+
+	// 子标签上移到被删除标签的父标签下
+	var tag models.GlobalTag
+	if err := s.db.Select("id, parent_id").First(&tag, tagID).Error; err == nil {
+		if err := s.db.Model(&models.GlobalTag{}).Where("parent_id = ?", tagID).Update("parent_id", tag.ParentID).Error; err != nil {
+			return fmt.Errorf("调整子标签失败: %v", err)
+		}
+	}
+
 	err = s.db.Delete(&models.GlobalTag{}, tagID).Error
 	if err != nil {
 		return fmt.Errorf("删除标签失败: %v", err)
@@ -660,7 +683,7 @@ func (s *GlobalTagService) GetTagsWithPagination(userID uint, keyword, sortBy, s
 		Joins(`LEFT JOIN user_tag_reference user_tags ON global_tag.id = user_tags.tag_id AND user_tags.user_id = ?`, userID)
 
 	if keyword != "" {
-		query = query.Where("global_tag.name LIKE ? OR global_tag.description LIKE ?",
+		query = query.Where(database.ILike("global_tag.name LIKE ? OR global_tag.description LIKE ?"),
 			"%"+keyword+"%", "%"+keyword+"%")
 	}
 
@@ -688,7 +711,7 @@ func (s *GlobalTagService) GetTagsWithPagination(userID uint, keyword, sortBy, s
 	countQuery := s.db.Table("global_tag")
 
 	if keyword != "" {
-		countQuery = countQuery.Where("global_tag.name LIKE ? OR global_tag.description LIKE ?",
+		countQuery = countQuery.Where(database.ILike("global_tag.name LIKE ? OR global_tag.description LIKE ?"),
 			"%"+keyword+"%", "%"+keyword+"%")
 	}
 
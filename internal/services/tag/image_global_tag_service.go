@@ -6,7 +6,9 @@ import (
 	"pixelpunk/pkg/ai"
 	"pixelpunk/pkg/common"
 	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/hooks"
 	"pixelpunk/pkg/logger"
+	"strings"
 
 	"gorm.io/gorm"
 )
@@ -306,8 +308,11 @@ func (s *FileGlobalTagService) ProcessAITaggingResult(fileID string, aiResult *a
 
 	globalTagService := NewGlobalTagService()
 
+	// 同义词会解析为同一个规范标签，需要去重
 	var tagIDs []uint
+	seen := make(map[uint]bool, len(aiResult.Tags))
 	for _, tagName := range aiResult.Tags {
+		tagName = strings.TrimSpace(tagName)
 		if tagName == "" {
 			continue
 		}
@@ -317,6 +322,10 @@ func (s *FileGlobalTagService) ProcessAITaggingResult(fileID string, aiResult *a
 			logger.Warn("处理AI标签失败 [%s]: %v", tagName, err)
 			continue
 		}
+		if seen[tag.ID] {
+			continue
+		}
+		seen[tag.ID] = true
 
 		err = globalTagService.AddUserTagReference(file.UserID, tag.ID, "ai")
 		if err != nil {
@@ -335,6 +344,7 @@ func (s *FileGlobalTagService) ProcessAITaggingResult(fileID string, aiResult *a
 		return fmt.Errorf("添加AI标签到文件失败: %v", err)
 	}
 
+	hooks.TriggerFileEvent(hooks.FileEventAITagged, fileID)
 	return nil
 }
 
@@ -348,9 +358,9 @@ func (s *FileGlobalTagService) GetTaggedFiles(tagIDs []uint, userID uint, access
 		return []models.File{}, 0, nil
 	}
 
-	query := s.db.Table("file").
-		Joins("JOIN file_global_tag_relation ON file.id = file_global_tag_relation.file_id").
-		Where("file_global_tag_relation.tag_id IN ?", tagIDs)
+	// 父标签包含全部子孙标签，使用子查询避免文件同时命中多个标签时重复计数
+	tagged := s.db.Model(&models.FileGlobalTagRelation{}).Select("file_id").Where("tag_id IN ?", ExpandTagDescendants(tagIDs))
+	query := s.db.Table("file").Where("file.id IN (?)", tagged)
 
 	if userID > 0 {
 		query = query.Where("file.user_id = ?", userID)
@@ -366,7 +376,8 @@ func (s *FileGlobalTagService) GetTaggedFiles(tagIDs []uint, userID uint, access
 	}
 
 	var images []models.File
-	err = query.Distinct("id").
+	// 子查询不会产生重复行；PostgreSQL 的 SELECT DISTINCT 不允许按未选择的列排序
+	err = query.Select("file.id").
 		Order("created_at DESC").
 		Limit(limit).
 		Offset(offset).
@@ -0,0 +1,378 @@
+package tag
+
+import (
+	"fmt"
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/database"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// maxTagDepth 标签层级的最大深度，防止异常数据导致无限遍历
+const maxTagDepth = 10
+
+// maxAliasesPerTag 单个标签最多的同义词数量
+const maxAliasesPerTag = 50
+
+/* TagTreeNode 标签树节点 */
+type TagTreeNode struct {
+	ID         uint           `json:"id"`
+	Name       string         `json:"name"`
+	Slug       string         `json:"slug"`
+	IsSystem   bool           `json:"is_system"`
+	UsageCount int            `json:"usage_count"`
+	SortOrder  int            `json:"sort_order"`
+	Aliases    []string       `json:"aliases"`
+	Children   []*TagTreeNode `json:"children"`
+}
+
+/* NormalizeTagAlias 同义词统一去空格并转小写 */
+func NormalizeTagAlias(alias string) string {
+	return strings.ToLower(strings.TrimSpace(alias))
+}
+
+/* ExpandTagDescendants 返回标签及其全部子孙标签ID，用于按父标签搜索 */
+func ExpandTagDescendants(tagIDs []uint) []uint {
+	if len(tagIDs) == 0 || database.DB == nil {
+		return tagIDs
+	}
+
+	seen := make(map[uint]bool, len(tagIDs))
+	result := make([]uint, 0, len(tagIDs))
+	for _, id := range tagIDs {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+
+	frontier := result
+	for depth := 0; depth < maxTagDepth && len(frontier) > 0; depth++ {
+		var children []uint
+		if err := database.DB.Model(&models.GlobalTag{}).Where("parent_id IN ?", frontier).Pluck("id", &children).Error; err != nil {
+			break
+		}
+		next := make([]uint, 0, len(children))
+		for _, id := range children {
+			if !seen[id] {
+				seen[id] = true
+				next = append(next, id)
+			}
+		}
+		result = append(result, next...)
+		frontier = next
+	}
+	return result
+}
+
+/* ResolveTagByName 按名称或同义词查找规范标签，未找到时返回 nil */
+func (s *GlobalTagService) ResolveTagByName(name string) (*models.GlobalTag, error) {
+	if s.db == nil {
+		return nil, fmt.Errorf("数据库连接失败")
+	}
+
+	var tag models.GlobalTag
+	err := s.db.Where("name = ?", name).First(&tag).Error
+	if err == nil {
+		return &tag, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("查询标签失败: %v", err)
+	}
+
+	alias := NormalizeTagAlias(name)
+	if alias == "" {
+		return nil, nil
+	}
+
+	var aliasRow models.GlobalTagAlias
+	err = s.db.Where("alias = ?", alias).First(&aliasRow).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询标签同义词失败: %v", err)
+	}
+
+	if err := s.db.First(&tag, aliasRow.TagID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("查询标签失败: %v", err)
+	}
+	return &tag, nil
+}
+
+/* SetTagParent 设置标签的父标签，parentID 为 nil 时移到顶层 */
+func (s *GlobalTagService) SetTagParent(tagID uint, parentID *uint) (*models.GlobalTag, error) {
+	if s.db == nil {
+		return nil, fmt.Errorf("数据库连接失败")
+	}
+
+	var tag models.GlobalTag
+	if err := s.db.First(&tag, tagID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("标签不存在")
+		}
+		return nil, fmt.Errorf("查询标签失败: %v", err)
+	}
+
+	if parentID != nil && *parentID == 0 {
+		parentID = nil
+	}
+
+	if parentID != nil {
+		if *parentID == tagID {
+			return nil, fmt.Errorf("不能将标签设置为自己的父标签")
+		}
+
+		// 沿父标签向上查找，父链中出现当前标签说明会形成环
+		depth := 1
+		current := *parentID
+		for {
+			var parent models.GlobalTag
+			if err := s.db.Select("id, parent_id").First(&parent, current).Error; err != nil {
+				if err == gorm.ErrRecordNotFound {
+					return nil, fmt.Errorf("父标签不存在")
+				}
+				return nil, fmt.Errorf("查询父标签失败: %v", err)
+			}
+			if parent.ParentID == nil {
+				break
+			}
+			if *parent.ParentID == tagID {
+				return nil, fmt.Errorf("不能将标签移动到自己的子标签下")
+			}
+			depth++
+			if depth >= maxTagDepth {
+				return nil, fmt.Errorf("标签层级不能超过%d层", maxTagDepth)
+			}
+			current = *parent.ParentID
+		}
+	}
+
+	if err := s.db.Model(&tag).Update("parent_id", parentID).Error; err != nil {
+		return nil, fmt.Errorf("更新父标签失败: %v", err)
+	}
+	tag.ParentID = parentID
+	return &tag, nil
+}
+
+/* GetTagTree 获取完整的标签树，同级按排序值和名称排列 */
+func (s *GlobalTagService) GetTagTree() ([]*TagTreeNode, error) {
+	if s.db == nil {
+		return nil, fmt.Errorf("数据库连接失败")
+	}
+
+	var tags []models.GlobalTag
+	if err := s.db.Order("sort_order ASC, name ASC").Find(&tags).Error; err != nil {
+		return nil, fmt.Errorf("查询标签失败: %v", err)
+	}
+
+	var aliases []models.GlobalTagAlias
+	if err := s.db.Order("alias ASC").Find(&aliases).Error; err != nil {
+		return nil, fmt.Errorf("查询标签同义词失败: %v", err)
+	}
+	aliasMap := make(map[uint][]string)
+	for _, a := range aliases {
+		aliasMap[a.TagID] = append(aliasMap[a.TagID], a.Alias)
+	}
+
+	nodes := make(map[uint]*TagTreeNode, len(tags))
+	for _, t := range tags {
+		nodes[t.ID] = &TagTreeNode{
+			ID:         t.ID,
+			Name:       t.Name,
+			Slug:       t.Slug,
+			IsSystem:   t.IsSystem,
+			UsageCount: t.UsageCount,
+			SortOrder:  t.SortOrder,
+			Aliases:    aliasMap[t.ID],
+			Children:   []*TagTreeNode{},
+		}
+	}
+
+	roots := make([]*TagTreeNode, 0)
+	for _, t := range tags {
+		node := nodes[t.ID]
+		if t.ParentID != nil {
+			if parent, ok := nodes[*t.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+	return roots, nil
+}
+
+/* ListTagAliases 获取标签的同义词 */
+func (s *GlobalTagService) ListTagAliases(tagID uint) ([]models.GlobalTagAlias, error) {
+	if s.db == nil {
+		return nil, fmt.Errorf("数据库连接失败")
+	}
+
+	var aliases []models.GlobalTagAlias
+	if err := s.db.Where("tag_id = ?", tagID).Order("alias ASC").Find(&aliases).Error; err != nil {
+		return nil, fmt.Errorf("查询标签同义词失败: %v", err)
+	}
+	return aliases, nil
+}
+
+/* AddTagAliases 为标签添加同义词，已属于其他标签或与其他标签同名的同义词会被拒绝 */
+func (s *GlobalTagService) AddTagAliases(tagID uint, aliases []string) ([]models.GlobalTagAlias, error) {
+	if s.db == nil {
+		return nil, fmt.Errorf("数据库连接失败")
+	}
+
+	var tag models.GlobalTag
+	if err := s.db.First(&tag, tagID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("标签不存在")
+		}
+		return nil, fmt.Errorf("查询标签失败: %v", err)
+	}
+
+	normalized := make([]string, 0, len(aliases))
+	seen := make(map[string]bool, len(aliases))
+	for _, a := range aliases {
+		alias := NormalizeTagAlias(a)
+		if alias == "" || seen[alias] || alias == strings.ToLower(tag.Name) {
+			continue
+		}
+		if len([]rune(alias)) > 50 {
+			return nil, fmt.Errorf("同义词不能超过50个字符: %s", alias)
+		}
+		seen[alias] = true
+		normalized = append(normalized, alias)
+	}
+	if len(normalized) == 0 {
+		return s.ListTagAliases(tagID)
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var existing []models.GlobalTagAlias
+		if err := tx.Where("alias IN ?", normalized).Find(&existing).Error; err != nil {
+			return fmt.Errorf("查询标签同义词失败: %v", err)
+		}
+		owned := make(map[string]bool, len(existing))
+		for _, e := range existing {
+			if e.TagID != tagID {
+				return fmt.Errorf("同义词「%s」已属于其他标签", e.Alias)
+			}
+			owned[e.Alias] = true
+		}
+
+		var conflictNames []string
+		if err := tx.Model(&models.GlobalTag{}).Where("LOWER(name) IN ? AND id <> ?", normalized, tagID).Pluck("name", &conflictNames).Error; err != nil {
+			return fmt.Errorf("检查标签名称失败: %v", err)
+		}
+		if len(conflictNames) > 0 {
+			return fmt.Errorf("「%s」已是独立标签，请使用标签合并", conflictNames[0])
+		}
+
+		var count int64
+		tx.Model(&models.GlobalTagAlias{}).Where("tag_id = ?", tagID).Count(&count)
+
+		rows := make([]models.GlobalTagAlias, 0, len(normalized))
+		for _, alias := range normalized {
+			if !owned[alias] {
+				rows = append(rows, models.GlobalTagAlias{TagID: tagID, Alias: alias})
+			}
+		}
+		if int(count)+len(rows) > maxAliasesPerTag {
+			return fmt.Errorf("每个标签最多%d个同义词", maxAliasesPerTag)
+		}
+		if len(rows) > 0 {
+			if err := tx.Create(&rows).Error; err != nil {
+				return fmt.Errorf("添加标签同义词失败: %v", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.ListTagAliases(tagID)
+}
+
+/* RemoveTagAlias 删除标签的同义词 */
+func (s *GlobalTagService) RemoveTagAlias(tagID, aliasID uint) error {
+	if s.db == nil {
+		return fmt.Errorf("数据库连接失败")
+	}
+
+	result := s.db.Where("id = ? AND tag_id = ?", aliasID, tagID).Delete(&models.GlobalTagAlias{})
+	if result.Error != nil {
+		return fmt.Errorf("删除标签同义词失败: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("同义词不存在")
+	}
+	return nil
+}
+
+// mergeTagHierarchyTx 合并标签时转移同义词与子标签，源标签名称作为目标标签的同义词保留
+func mergeTagHierarchyTx(tx *gorm.DB, sources []models.GlobalTag, target *models.GlobalTag) error {
+	sourceIDs := make([]uint, 0, len(sources))
+	sourceSet := make(map[uint]*models.GlobalTag, len(sources))
+	for i := range sources {
+		sourceIDs = append(sourceIDs, sources[i].ID)
+		sourceSet[sources[i].ID] = &sources[i]
+	}
+
+	var aliases []string
+	if err := tx.Model(&models.GlobalTagAlias{}).Where("tag_id IN ?", sourceIDs).Pluck("alias", &aliases).Error; err != nil {
+		return fmt.Errorf("查询源标签同义词失败: %v", err)
+	}
+	for _, t := range sources {
+		aliases = append(aliases, NormalizeTagAlias(t.Name))
+	}
+	if err := tx.Where("tag_id IN ?", sourceIDs).Delete(&models.GlobalTagAlias{}).Error; err != nil {
+		return fmt.Errorf("删除源标签同义词失败: %v", err)
+	}
+
+	var existing []string
+	tx.Model(&models.GlobalTagAlias{}).Where("tag_id = ?", target.ID).Pluck("alias", &existing)
+	skip := map[string]bool{NormalizeTagAlias(target.Name): true}
+	for _, a := range existing {
+		skip[a] = true
+	}
+	sort.Strings(aliases)
+	rows := make([]models.GlobalTagAlias, 0, len(aliases))
+	for _, a := range aliases {
+		if a == "" || skip[a] {
+			continue
+		}
+		skip[a] = true
+		rows = append(rows, models.GlobalTagAlias{TagID: target.ID, Alias: a})
+	}
+	if len(rows) > 0 {
+		if err := tx.Create(&rows).Error; err != nil {
+			return fmt.Errorf("转移标签同义词失败: %v", err)
+		}
+	}
+
+	if err := tx.Model(&models.GlobalTag{}).
+		Where("parent_id IN ? AND id <> ?", sourceIDs, target.ID).
+		Update("parent_id", target.ID).Error; err != nil {
+		return fmt.Errorf("转移子标签失败: %v", err)
+	}
+
+	// 目标标签原本挂在某个源标签下时，改挂到该源标签的父标签
+	if target.ParentID != nil {
+		if src, ok := sourceSet[*target.ParentID]; ok {
+			newParent := src.ParentID
+			if newParent != nil && (*newParent == target.ID || sourceSet[*newParent] != nil) {
+				newParent = nil
+			}
+			if err := tx.Model(&models.GlobalTag{}).Where("id = ?", target.ID).Update("parent_id", newParent).Error; err != nil {
+				return fmt.Errorf("更新目标标签层级失败: %v", err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,386 @@
+package tag
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/category"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	taxonomyVersion = 1
+
+	taxonomyTypeTag      = "tag"
+	taxonomyTypeTemplate = "category_template"
+
+	// taxonomyAliasSep CSV中多个同义词之间的分隔符
+	taxonomyAliasSep = "|"
+
+	maxTaxonomyErrors = 100
+)
+
+var taxonomyCSVHeader = []string{"type", "name", "parent", "aliases", "description", "sort_order", "is_system", "icon", "is_popular"}
+
+/* TaxonomyTag 标签体系中的标签 */
+type TaxonomyTag struct {
+	Name        string   `json:"name"`
+	Parent      string   `json:"parent,omitempty"`
+	Aliases     []string `json:"aliases,omitempty"`
+	Description string   `json:"description,omitempty"`
+	SortOrder   int      `json:"sort_order"`
+	IsSystem    bool     `json:"is_system"`
+}
+
+/* TaxonomyTemplate 标签体系中的分类模板 */
+type TaxonomyTemplate struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Icon        string `json:"icon,omitempty"`
+	SortOrder   int    `json:"sort_order"`
+	IsPopular   bool   `json:"is_popular"`
+}
+
+/* Taxonomy 标签体系，包含标签层级、同义词与分类模板 */
+type Taxonomy struct {
+	Version           int                `json:"version"`
+	ExportedAt        string             `json:"exported_at,omitempty"`
+	Tags              []TaxonomyTag      `json:"tags"`
+	CategoryTemplates []TaxonomyTemplate `json:"category_templates"`
+}
+
+/* TaxonomyImportResult 导入结果 */
+type TaxonomyImportResult struct {
+	TagsCreated      int      `json:"tags_created"`
+	TagsUpdated      int      `json:"tags_updated"`
+	ParentsSet       int      `json:"parents_set"`
+	AliasesAdded     int      `json:"aliases_added"`
+	TemplatesCreated int      `json:"templates_created"`
+	TemplatesUpdated int      `json:"templates_updated"`
+	Errors           []string `json:"errors"`
+}
+
+func (r *TaxonomyImportResult) addError(format string, args ...interface{}) {
+	if len(r.Errors) < maxTaxonomyErrors {
+		r.Errors = append(r.Errors, fmt.Sprintf(format, args...))
+	}
+}
+
+/* ExportTaxonomy 导出完整标签体系 */
+func (s *GlobalTagService) ExportTaxonomy() (*Taxonomy, error) {
+	if s.db == nil {
+		return nil, fmt.Errorf("数据库连接失败")
+	}
+
+	var tags []models.GlobalTag
+	if err := s.db.Order("sort_order ASC, id ASC").Find(&tags).Error; err != nil {
+		return nil, fmt.Errorf("查询标签失败: %v", err)
+	}
+	names := make(map[uint]string, len(tags))
+	for _, t := range tags {
+		names[t.ID] = t.Name
+	}
+
+	var aliases []models.GlobalTagAlias
+	if err := s.db.Order("alias ASC").Find(&aliases).Error; err != nil {
+		return nil, fmt.Errorf("查询标签同义词失败: %v", err)
+	}
+	aliasMap := make(map[uint][]string)
+	for _, a := range aliases {
+		aliasMap[a.TagID] = append(aliasMap[a.TagID], a.Alias)
+	}
+
+	taxonomy := &Taxonomy{
+		Version:           taxonomyVersion,
+		ExportedAt:        time.Now().Format(time.RFC3339),
+		Tags:              make([]TaxonomyTag, 0, len(tags)),
+		CategoryTemplates: []TaxonomyTemplate{},
+	}
+	for _, t := range tags {
+		item := TaxonomyTag{
+			Name:        t.Name,
+			Aliases:     aliasMap[t.ID],
+			Description: t.Description,
+			SortOrder:   t.SortOrder,
+			IsSystem:    t.IsSystem,
+		}
+		if t.ParentID != nil {
+			item.Parent = names[*t.ParentID]
+		}
+		taxonomy.Tags = append(taxonomy.Tags, item)
+	}
+
+	templates, err := category.NewTemplateService().GetAllTemplatesForAI()
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range templates {
+		taxonomy.CategoryTemplates = append(taxonomy.CategoryTemplates, TaxonomyTemplate{
+			Name:        t.Name,
+			Description: t.Description,
+			Icon:        t.Icon,
+			SortOrder:   t.SortOrder,
+			IsPopular:   t.IsPopular,
+		})
+	}
+	return taxonomy, nil
+}
+
+/* ImportTaxonomy 导入标签体系，按名称匹配已有数据；overwrite 为 true 时覆盖描述与排序 */
+func (s *GlobalTagService) ImportTaxonomy(operatorID uint, taxonomy *Taxonomy, overwrite bool) (*TaxonomyImportResult, error) {
+	if s.db == nil {
+		return nil, fmt.Errorf("数据库连接失败")
+	}
+	if taxonomy.Version > taxonomyVersion {
+		return nil, fmt.Errorf("不支持的标签体系版本: %d", taxonomy.Version)
+	}
+
+	result := &TaxonomyImportResult{Errors: []string{}}
+	tagIDs := make(map[string]uint, len(taxonomy.Tags))
+
+	// 第一轮：创建或更新标签，父子关系与同义词依赖全部标签已存在
+	for _, item := range taxonomy.Tags {
+		name := strings.TrimSpace(item.Name)
+		if name == "" {
+			continue
+		}
+		if len([]rune(name)) > 50 {
+			result.addError("标签「%s」名称超过50个字符", name)
+			continue
+		}
+
+		var tag models.GlobalTag
+		err := s.db.Where("name = ?", name).First(&tag).Error
+		if err == nil {
+			if overwrite {
+				updates := map[string]interface{}{"sort_order": item.SortOrder}
+				if item.Description != "" {
+					updates["description"] = item.Description
+				}
+				if err := s.db.Model(&tag).Updates(updates).Error; err != nil {
+					result.addError("更新标签「%s」失败: %v", name, err)
+				} else {
+					result.TagsUpdated++
+				}
+			}
+			tagIDs[name] = tag.ID
+			continue
+		}
+
+		var alias models.GlobalTagAlias
+		if err := s.db.Where("alias = ?", NormalizeTagAlias(name)).First(&alias).Error; err == nil {
+			result.addError("标签「%s」已是其他标签的同义词，已跳过", name)
+			continue
+		}
+
+		tag = models.GlobalTag{
+			Name:        name,
+			Description: item.Description,
+			CreatorID:   operatorID,
+			IsSystem:    item.IsSystem,
+			SortOrder:   item.SortOrder,
+		}
+		if err := s.db.Create(&tag).Error; err != nil {
+			result.addError("创建标签「%s」失败: %v", name, err)
+			continue
+		}
+		tagIDs[name] = tag.ID
+		result.TagsCreated++
+	}
+
+	for _, item := range taxonomy.Tags {
+		name := strings.TrimSpace(item.Name)
+		tagID, ok := tagIDs[name]
+		if !ok {
+			continue
+		}
+
+		if parentName := strings.TrimSpace(item.Parent); parentName != "" {
+			parentID, ok := tagIDs[parentName]
+			if !ok {
+				var parent models.GlobalTag
+				if err := s.db.Select("id").Where("name = ?", parentName).First(&parent).Error; err != nil {
+					result.addError("标签「%s」的父标签「%s」不存在", name, parentName)
+				} else {
+					parentID, ok = parent.ID, true
+				}
+			}
+			if ok {
+				if _, err := s.SetTagParent(tagID, &parentID); err != nil {
+					result.addError("设置标签「%s」的父标签失败: %v", name, err)
+				} else {
+					result.ParentsSet++
+				}
+			}
+		}
+
+		if len(item.Aliases) > 0 {
+			before, _ := s.ListTagAliases(tagID)
+			after, err := s.AddTagAliases(tagID, item.Aliases)
+			if err != nil {
+				result.addError("添加标签「%s」的同义词失败: %v", name, err)
+			} else {
+				result.AliasesAdded += len(after) - len(before)
+			}
+		}
+	}
+
+	templateService := category.NewTemplateService()
+	for _, item := range taxonomy.CategoryTemplates {
+		name := strings.TrimSpace(item.Name)
+		if name == "" {
+			continue
+		}
+
+		var existing models.CategoryTemplate
+		if err := s.db.Where("name = ?", name).First(&existing).Error; err == nil {
+			if !overwrite {
+				continue
+			}
+			sortOrder, isPopular := item.SortOrder, item.IsPopular
+			if _, err := templateService.UpdateTemplate(existing.ID, category.UpdateTemplateRequest{
+				Description: item.Description,
+				Icon:        item.Icon,
+				IsPopular:   &isPopular,
+				SortOrder:   &sortOrder,
+			}); err != nil {
+				result.addError("更新分类模板「%s」失败: %v", name, err)
+			} else {
+				result.TemplatesUpdated++
+			}
+			continue
+		}
+
+		if _, err := templateService.CreateTemplate(category.CreateTemplateRequest{
+			Name:        name,
+			Description: item.Description,
+			Icon:        item.Icon,
+			IsPopular:   item.IsPopular,
+			SortOrder:   item.SortOrder,
+		}); err != nil {
+			result.addError("创建分类模板「%s」失败: %v", name, err)
+		} else {
+			result.TemplatesCreated++
+		}
+	}
+
+	return result, nil
+}
+
+/* ParseTaxonomyJSON 解析JSON格式的标签体系 */
+func ParseTaxonomyJSON(r io.Reader) (*Taxonomy, error) {
+	var taxonomy Taxonomy
+	if err := json.NewDecoder(r).Decode(&taxonomy); err != nil {
+		return nil, fmt.Errorf("JSON格式错误: %v", err)
+	}
+	return &taxonomy, nil
+}
+
+/* ParseTaxonomyCSV 解析CSV格式的标签体系，首行为表头 */
+func ParseTaxonomyCSV(r io.Reader) (*Taxonomy, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("CSV格式错误: %v", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, h := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))] = i
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, fmt.Errorf("CSV缺少name列")
+	}
+
+	taxonomy := &Taxonomy{Version: taxonomyVersion, Tags: []TaxonomyTag{}, CategoryTemplates: []TaxonomyTemplate{}}
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("CSV第%d行格式错误: %v", line, err)
+		}
+
+		get := func(col string) string {
+			if i, ok := columns[col]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		sortOrder, _ := strconv.Atoi(get("sort_order"))
+
+		switch get("type") {
+		case taxonomyTypeTemplate:
+			taxonomy.CategoryTemplates = append(taxonomy.CategoryTemplates, TaxonomyTemplate{
+				Name:        get("name"),
+				Description: get("description"),
+				Icon:        get("icon"),
+				SortOrder:   sortOrder,
+				IsPopular:   parseCSVBool(get("is_popular")),
+			})
+		case taxonomyTypeTag, "":
+			var aliases []string
+			for _, a := range strings.Split(get("aliases"), taxonomyAliasSep) {
+				if a = strings.TrimSpace(a); a != "" {
+					aliases = append(aliases, a)
+				}
+			}
+			taxonomy.Tags = append(taxonomy.Tags, TaxonomyTag{
+				Name:        get("name"),
+				Parent:      get("parent"),
+				Aliases:     aliases,
+				Description: get("description"),
+				SortOrder:   sortOrder,
+				IsSystem:    parseCSVBool(get("is_system")),
+			})
+		default:
+			return nil, fmt.Errorf("CSV第%d行类型错误，应为tag或category_template", line)
+		}
+	}
+	return taxonomy, nil
+}
+
+/* WriteTaxonomyCSV 以CSV格式写出标签体系 */
+func WriteTaxonomyCSV(w io.Writer, taxonomy *Taxonomy) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(taxonomyCSVHeader); err != nil {
+		return err
+	}
+
+	for _, t := range taxonomy.Tags {
+		if err := writer.Write([]string{
+			taxonomyTypeTag, t.Name, t.Parent, strings.Join(t.Aliases, taxonomyAliasSep), t.Description,
+			strconv.Itoa(t.SortOrder), strconv.FormatBool(t.IsSystem), "", "",
+		}); err != nil {
+			return err
+		}
+	}
+	for _, t := range taxonomy.CategoryTemplates {
+		if err := writer.Write([]string{
+			taxonomyTypeTemplate, t.Name, "", "", t.Description,
+			strconv.Itoa(t.SortOrder), "", t.Icon, strconv.FormatBool(t.IsPopular),
+		}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func parseCSVBool(v string) bool {
+	switch strings.ToLower(v) {
+	case "1", "true", "yes", "y", "是":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package tag
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTaxonomyCSVRoundTrip(t *testing.T) {
+	in := &Taxonomy{
+		Version: taxonomyVersion,
+		Tags: []TaxonomyTag{
+			{Name: "animal", SortOrder: 1, IsSystem: true},
+			{Name: "cat", Parent: "animal", Aliases: []string{"cats", "kitten"}, Description: "猫, 包括幼猫"},
+		},
+		CategoryTemplates: []TaxonomyTemplate{
+			{Name: "宠物", Icon: "pet", SortOrder: 3, IsPopular: true},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTaxonomyCSV(&buf, in); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+
+	out, err := ParseTaxonomyCSV(&buf)
+	if err != nil {
+		t.Fatalf("parse csv: %v", err)
+	}
+	if !reflect.DeepEqual(in.Tags, out.Tags) {
+		t.Errorf("tags mismatch:\n got %+v\nwant %+v", out.Tags, in.Tags)
+	}
+	if !reflect.DeepEqual(in.CategoryTemplates, out.CategoryTemplates) {
+		t.Errorf("templates mismatch:\n got %+v\nwant %+v", out.CategoryTemplates, in.CategoryTemplates)
+	}
+}
+
+func TestParseTaxonomyCSVErrors(t *testing.T) {
+	if _, err := ParseTaxonomyCSV(strings.NewReader("type,parent\ntag,x\n")); err == nil {
+		t.Error("expected error for missing name column")
+	}
+	if _, err := ParseTaxonomyCSV(strings.NewReader("type,name\nfolder,x\n")); err == nil {
+		t.Error("expected error for unknown row type")
+	}
+
+	out, err := ParseTaxonomyCSV(strings.NewReader("\ufeffName,Aliases\ndog, puppy | doggo |\n"))
+	if err != nil {
+		t.Fatalf("parse csv: %v", err)
+	}
+	if len(out.Tags) != 1 || !reflect.DeepEqual(out.Tags[0].Aliases, []string{"puppy", "doggo"}) {
+		t.Errorf("unexpected tags: %+v", out.Tags)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/email"
 	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/logger"
 	"pixelpunk/pkg/utils"
 	"time"
 
@@ -139,7 +140,7 @@ func ResetPasswordWithToken(token, newPassword string) error {
 	}
 
 	// 使用 GORM Transaction 方法替代手动事务管理，确保 SQLite 兼容性
-	return db.Transaction(func(tx *gorm.DB) error {
+	err = db.Transaction(func(tx *gorm.DB) error {
 		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).Update("password", hashedPassword).Error; err != nil {
 			return errors.New(errors.CodeDBUpdateFailed, "更新密码失败")
 		}
@@ -157,6 +158,14 @@ func ResetPasswordWithToken(token, newPassword string) error {
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := RevokeAllUserSessions(user.ID, SessionRevokePassword); err != nil {
+		logger.Warn("重置密码后吊销会话失败: userID=%d, error=%v", user.ID, err)
+	}
+	return nil
 }
 
 // CleanupExpiredTokens 清理过期的重置token
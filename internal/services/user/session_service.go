@@ -0,0 +1,349 @@
+package user
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/setting"
+	"pixelpunk/pkg/cache"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/logger"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	sessionRevokedCache      = "user:session:revoked:%s"       // 已吊销会话，TTL与访问令牌有效期一致
+	sessionSeenCache         = "user:session:seen:%s"          // 最近活跃标记，用于节流数据库写入
+	userTokensRevokedCache   = "user:tokens:revoked_before:%d" // 早于该时间签发的令牌全部失效
+	sessionSeenInterval      = 5 * time.Minute                 // 会话活跃时间的最小更新间隔
+	refreshTokenBytes        = 32                              // 刷新令牌随机字节数
+	defaultAccessTokenMinute = 30                              // 访问令牌默认有效期(分钟)
+	maxSessionListSize       = 100                             // 会话列表最多返回条数
+	sessionRevokeReasonReuse = "refresh_reuse"                 // 检测到刷新令牌重放
+)
+
+// 会话吊销原因
+const (
+	SessionRevokeLogout   = "logout"
+	SessionRevokeUser     = "user_revoke"
+	SessionRevokeOthers   = "revoke_others"
+	SessionRevokeAdmin    = "admin_logout"
+	SessionRevokePassword = "password_change"
+	SessionRevokeDisabled = "user_disabled"
+)
+
+/* SessionClient 发起登录/刷新的客户端信息 */
+type SessionClient struct {
+	IP          string
+	UserAgent   string
+	LoginMethod string
+}
+
+/* SessionInfo 会话列表项 */
+type SessionInfo struct {
+	models.UserSession
+	Current bool `json:"current"`
+}
+
+/* accessTokenTTL 访问令牌有效期，不超过登录有效期 */
+func (cfg *loginSecurityConfig) accessTokenTTL() time.Duration {
+	ttl := time.Duration(cfg.accessTokenMinutes) * time.Minute
+	if max := time.Duration(cfg.expiresHours) * time.Hour; ttl <= 0 || ttl > max {
+		ttl = max
+	}
+	return ttl
+}
+
+func currentAccessTokenTTL() time.Duration {
+	minutes := setting.GetInt("security", "access_token_minutes", defaultAccessTokenMinute)
+	if minutes <= 0 {
+		minutes = defaultAccessTokenMinute
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func generateRefreshToken() (string, error) {
+	buf := make([]byte, refreshTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+/* createSession 创建登录会话并返回刷新令牌明文 */
+func createSession(userID uint, cfg *loginSecurityConfig, client SessionClient) (*models.UserSession, string, error) {
+	refreshToken, err := generateRefreshToken()
+	if err != nil {
+		return nil, "", errors.Wrap(err, errors.CodeInternal, "生成刷新令牌失败")
+	}
+
+	ua := client.UserAgent
+	if len(ua) > 500 {
+		ua = ua[:500]
+	}
+	uaInfo := common.ParseUserAgent(ua)
+	now := time.Now()
+
+	session := &models.UserSession{
+		ID:               strings.ReplaceAll(uuid.New().String(), "-", ""),
+		UserID:           userID,
+		RefreshTokenHash: hashRefreshToken(refreshToken),
+		LoginMethod:      client.LoginMethod,
+		UserAgent:        ua,
+		Browser:          uaInfo.Browser,
+		OS:               uaInfo.OS,
+		DeviceType:       uaInfo.DeviceType,
+		IP:               client.IP,
+		LastSeenAt:       common.JSONTime(now),
+		ExpiresAt:        common.JSONTime(now.Add(time.Duration(cfg.expiresHours) * time.Hour)),
+	}
+	if err := database.DB.Create(session).Error; err != nil {
+		return nil, "", errors.Wrap(err, errors.CodeDBCreateFailed, "创建登录会话失败")
+	}
+	return session, refreshToken, nil
+}
+
+/* RefreshSession 使用刷新令牌换取新的访问令牌，刷新令牌同时轮换；旧令牌被重复使用时吊销整个会话 */
+func RefreshSession(refreshToken string, client SessionClient) (*LoginResult, error) {
+	refreshToken = strings.TrimSpace(refreshToken)
+	if refreshToken == "" {
+		return nil, errors.New(errors.CodeInvalidToken, "刷新令牌无效")
+	}
+	tokenHash := hashRefreshToken(refreshToken)
+
+	var session models.UserSession
+	if err := database.DB.Where("refresh_token_hash = ?", tokenHash).First(&session).Error; err != nil {
+		// 已轮换掉的令牌再次出现，说明令牌可能泄露，吊销对应会话
+		var reused models.UserSession
+		if database.DB.Where("prev_token_hash = ? AND revoked_at IS NULL", tokenHash).First(&reused).Error == nil {
+			logger.Warn("检测到刷新令牌重放，吊销会话: userID=%d, sessionID=%s, ip=%s", reused.UserID, reused.ID, client.IP)
+			revokeSessions([]models.UserSession{reused}, sessionRevokeReasonReuse)
+		}
+		return nil, errors.New(errors.CodeInvalidToken, "刷新令牌无效或已失效，请重新登录")
+	}
+
+	if session.IsRevoked() || session.IsExpired() {
+		return nil, errors.New(errors.CodeTokenExpired, "登录已失效，请重新登录")
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, session.UserID).Error; err != nil {
+		return nil, errors.New(errors.CodeUserNotFound, "用户不存在")
+	}
+	if !user.IsNormal() {
+		revokeSessions([]models.UserSession{session}, SessionRevokeDisabled)
+		return nil, errors.New(errors.CodeUserDisabled, "账号已被禁用")
+	}
+
+	cfg, err := loadLoginSecurityConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	newToken, err := generateRefreshToken()
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeInternal, "生成刷新令牌失败")
+	}
+
+	updates := map[string]interface{}{
+		"refresh_token_hash": hashRefreshToken(newToken),
+		"prev_token_hash":    tokenHash,
+		"last_seen_at":       common.JSONTime(time.Now()),
+	}
+	if client.IP != "" {
+		updates["ip"] = client.IP
+	}
+	// 以旧哈希为条件更新，并发刷新时只有一个请求能成功
+	result := database.DB.Model(&models.UserSession{}).
+		Where("id = ? AND refresh_token_hash = ? AND revoked_at IS NULL", session.ID, tokenHash).
+		Updates(updates)
+	if result.Error != nil {
+		return nil, errors.Wrap(result.Error, errors.CodeDBUpdateFailed, "刷新会话失败")
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New(errors.CodeInvalidToken, "刷新令牌已被使用，请重新登录")
+	}
+
+	accessToken, err := signAccessToken(&user, session.ID, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LoginResult{
+		UserInfo:         buildLoginUserInfo(&user),
+		Token:            accessToken,
+		RefreshToken:     newToken,
+		SessionID:        session.ID,
+		ExpiresIn:        int64(cfg.accessTokenTTL().Seconds()),
+		RefreshExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+/* ValidateAccessSession 校验访问令牌对应的会话是否仍然有效
+ * 先查缓存中的吊销标记；每隔一段时间回源数据库一次并刷新会话活跃时间
+ */
+func ValidateAccessSession(userID uint, sessionID string, issuedAt time.Time, clientIP string) bool {
+	// 旧版令牌不绑定会话，仅受用户级吊销标记约束
+	if sessionID == "" {
+		if val, err := cache.GetCache().Get(fmt.Sprintf(userTokensRevokedCache, userID)); err == nil && val != "" {
+			if before, err := strconv.ParseInt(val, 10, 64); err == nil && issuedAt.Unix() <= before {
+				return false
+			}
+		}
+		return true
+	}
+
+	if cache.GetCache().Exists(fmt.Sprintf(sessionRevokedCache, sessionID)) {
+		return false
+	}
+
+	seenKey := fmt.Sprintf(sessionSeenCache, sessionID)
+	if cache.GetCache().Exists(seenKey) {
+		return true
+	}
+
+	var session models.UserSession
+	if err := database.DB.Select("id", "user_id", "expires_at", "revoked_at").
+		Where("id = ? AND user_id = ?", sessionID, userID).First(&session).Error; err != nil {
+		if !stderrors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Warn("会话状态查询失败: sessionID=%s, error=%v", sessionID, err)
+			return true // 查询失败默认放行，与用户状态检查保持一致
+		}
+		_ = cache.GetCache().Set(fmt.Sprintf(sessionRevokedCache, sessionID), "1", currentAccessTokenTTL())
+		return false
+	}
+	if session.IsRevoked() || session.IsExpired() {
+		_ = cache.GetCache().Set(fmt.Sprintf(sessionRevokedCache, sessionID), "1", currentAccessTokenTTL())
+		return false
+	}
+
+	updates := map[string]interface{}{"last_seen_at": common.JSONTime(time.Now())}
+	if clientIP != "" {
+		updates["ip"] = clientIP
+	}
+	if err := database.DB.Model(&models.UserSession{}).Where("id = ?", sessionID).Updates(updates).Error; err != nil {
+		logger.Warn("更新会话活跃时间失败: sessionID=%s, error=%v", sessionID, err)
+	}
+	_ = cache.GetCache().Set(seenKey, "1", sessionSeenInterval)
+	return true
+}
+
+/* ListUserSessions 列出用户当前有效的登录会话 */
+func ListUserSessions(userID uint, currentSessionID string) ([]SessionInfo, error) {
+	var sessions []models.UserSession
+	if err := database.DB.
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").
+		Limit(maxSessionListSize).
+		Find(&sessions).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询登录会话失败")
+	}
+
+	items := make([]SessionInfo, 0, len(sessions))
+	for _, s := range sessions {
+		items = append(items, SessionInfo{UserSession: s, Current: s.ID == currentSessionID})
+	}
+	return items, nil
+}
+
+/* RevokeSession 吊销用户自己的某个会话 */
+func RevokeSession(userID uint, sessionID string, reason string) error {
+	var session models.UserSession
+	if err := database.DB.Where("id = ? AND user_id = ?", sessionID, userID).First(&session).Error; err != nil {
+		return errors.New(errors.CodeNotFound, "会话不存在")
+	}
+	if session.IsRevoked() {
+		return nil
+	}
+	revokeSessions([]models.UserSession{session}, reason)
+	return nil
+}
+
+/* RevokeOtherSessions 吊销除当前会话外的所有会话，返回吊销数量 */
+func RevokeOtherSessions(userID uint, keepSessionID string, reason string) (int64, error) {
+	var sessions []models.UserSession
+	query := database.DB.Where("user_id = ? AND revoked_at IS NULL", userID)
+	if keepSessionID != "" {
+		query = query.Where("id <> ?", keepSessionID)
+	}
+	if err := query.Find(&sessions).Error; err != nil {
+		return 0, errors.Wrap(err, errors.CodeDBQueryFailed, "查询登录会话失败")
+	}
+	return revokeSessions(sessions, reason), nil
+}
+
+/* RevokeAllUserSessions 强制用户在所有设备下线，包括未绑定会话的旧版令牌 */
+func RevokeAllUserSessions(userID uint, reason string) (int64, error) {
+	var user models.User
+	if err := database.DB.Select("id").First(&user, userID).Error; err != nil {
+		return 0, errors.New(errors.CodeUserNotFound, "用户不存在")
+	}
+
+	count, err := RevokeOtherSessions(userID, "", reason)
+	if err != nil {
+		return 0, err
+	}
+
+	// 会话令牌已随会话吊销；旧版令牌有效期为登录有效期，标记保留同样时长即可
+	expiresHours := setting.GetInt("security", "login_expire_hours", 168)
+	if expiresHours <= 0 {
+		expiresHours = 168
+	}
+	_ = cache.GetCache().Set(fmt.Sprintf(userTokensRevokedCache, userID),
+		strconv.FormatInt(time.Now().Unix(), 10), time.Duration(expiresHours)*time.Hour)
+
+	return count, nil
+}
+
+/* revokeSessions 标记会话为已吊销并写入缓存吊销标记，返回实际吊销数量 */
+func revokeSessions(sessions []models.UserSession, reason string) int64 {
+	if len(sessions) == 0 {
+		return 0
+	}
+
+	ids := make([]string, 0, len(sessions))
+	for _, s := range sessions {
+		ids = append(ids, s.ID)
+	}
+
+	now := common.JSONTime(time.Now())
+	result := database.DB.Model(&models.UserSession{}).
+		Where("id IN ? AND revoked_at IS NULL", ids).
+		Updates(map[string]interface{}{"revoked_at": now, "revoked_reason": reason})
+	if result.Error != nil {
+		logger.Error("吊销登录会话失败: %v", result.Error)
+	}
+
+	ttl := currentAccessTokenTTL()
+	for _, id := range ids {
+		_ = cache.GetCache().Set(fmt.Sprintf(sessionRevokedCache, id), "1", ttl)
+		_ = cache.GetCache().Del(fmt.Sprintf(sessionSeenCache, id))
+	}
+	return result.RowsAffected
+}
+
+/* CleanupExpiredSessions 删除过期或吊销超过指定天数的会话记录 */
+func CleanupExpiredSessions(retainDays int) (int64, error) {
+	if retainDays <= 0 {
+		retainDays = 30
+	}
+	cutoff := time.Now().AddDate(0, 0, -retainDays)
+	result := database.DB.Where("expires_at < ? OR revoked_at < ?", cutoff, cutoff).Delete(&models.UserSession{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,377 @@
+package user
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/internal/services/activity"
+	"pixelpunk/internal/services/setting"
+	"pixelpunk/pkg/cache"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	recoveryCodeCount       = 10
+	recoveryCodeLength      = 10
+	recoveryCodeAlphabet    = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789" // 去除易混淆字符
+	twoFactorChallengeTTL   = 5 * time.Minute
+	twoFactorDefaultIssuer  = "PixelPunk"
+	twoFactorChallengeCache = "user:login:2fa:%s"
+)
+
+/* TwoFactorStatus 两步验证状态 */
+type TwoFactorStatus struct {
+	Enabled                bool             `json:"enabled"`
+	EnabledAt              *common.JSONTime `json:"enabled_at"`
+	RecoveryCodesRemaining int64            `json:"recovery_codes_remaining"`
+	Enforced               bool             `json:"enforced"` // 当前账号是否被强制要求启用
+}
+
+/* TwoFactorSetup 两步验证绑定信息 */
+type TwoFactorSetup struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+/* IsTwoFactorEnabled 用户是否已启用两步验证 */
+func IsTwoFactorEnabled(userID uint) bool {
+	var count int64
+	database.DB.Model(&models.UserTwoFactor{}).
+		Where("user_id = ? AND enabled = ?", userID, true).
+		Count(&count)
+	return count > 0
+}
+
+/* IsTwoFactorEnforced 该角色是否被强制要求启用两步验证 */
+func IsTwoFactorEnforced(role int) bool {
+	if role != common.UserRoleAdmin && role != common.UserRoleSuperAdmin {
+		return false
+	}
+	return setting.GetBool("security", "enforce_admin_2fa", false)
+}
+
+/* IsTwoFactorSetupRequired 是否被强制要求启用两步验证但尚未启用 */
+func IsTwoFactorSetupRequired(userID uint, role int) bool {
+	return IsTwoFactorEnforced(role) && !IsTwoFactorEnabled(userID)
+}
+
+/* GetTwoFactorStatus 获取用户两步验证状态 */
+func GetTwoFactorStatus(userID uint) (*TwoFactorStatus, error) {
+	var user models.User
+	if err := database.DB.Select("id", "role").First(&user, userID).Error; err != nil {
+		return nil, errors.New(errors.CodeUserNotFound, "用户不存在")
+	}
+
+	status := &TwoFactorStatus{Enforced: IsTwoFactorEnforced(user.Role)}
+
+	var tf models.UserTwoFactor
+	if err := database.DB.Where("user_id = ? AND enabled = ?", userID, true).First(&tf).Error; err == nil {
+		status.Enabled = true
+		status.EnabledAt = tf.EnabledAt
+		database.DB.Model(&models.UserRecoveryCode{}).
+			Where("user_id = ? AND used_at IS NULL", userID).
+			Count(&status.RecoveryCodesRemaining)
+	}
+
+	return status, nil
+}
+
+/* SetupTwoFactor 生成新的TOTP密钥（待验证），重复调用会覆盖未启用的密钥 */
+func SetupTwoFactor(userID uint) (*TwoFactorSetup, error) {
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return nil, errors.New(errors.CodeUserNotFound, "用户不存在")
+	}
+	if IsTwoFactorEnabled(userID) {
+		return nil, errors.New(errors.CodeConflict, "已启用两步验证，如需重新绑定请先关闭")
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeInternal, "生成两步验证密钥失败")
+	}
+
+	var tf models.UserTwoFactor
+	err = database.DB.Where("user_id = ?", userID).First(&tf).Error
+	if err == gorm.ErrRecordNotFound {
+		tf = models.UserTwoFactor{UserID: userID, Secret: secret}
+		err = database.DB.Create(&tf).Error
+	} else if err == nil {
+		err = database.DB.Model(&tf).Updates(map[string]interface{}{"secret": secret, "last_step": 0}).Error
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBUpdateFailed, "保存两步验证密钥失败")
+	}
+
+	account := user.Email
+	if account == "" {
+		account = user.Username
+	}
+	issuer := setting.GetString("website_info", "site_name", twoFactorDefaultIssuer)
+	if strings.TrimSpace(issuer) == "" {
+		issuer = twoFactorDefaultIssuer
+	}
+
+	return &TwoFactorSetup{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(secret, issuer, account),
+	}, nil
+}
+
+/* EnableTwoFactor 校验首个验证码后启用两步验证，返回一次性恢复码 */
+func EnableTwoFactor(userID uint, code string) ([]string, error) {
+	var tf models.UserTwoFactor
+	if err := database.DB.Where("user_id = ?", userID).First(&tf).Error; err != nil {
+		return nil, errors.New(errors.CodeInvalidRequest, "请先获取两步验证密钥")
+	}
+	if tf.Enabled {
+		return nil, errors.New(errors.CodeConflict, "两步验证已启用")
+	}
+
+	step, ok := utils.ValidateTOTP(tf.Secret, code, time.Now(), tf.LastStep)
+	if !ok {
+		return nil, errors.New(errors.CodeTwoFactorInvalid, "验证码错误，请检查验证器时间是否准确")
+	}
+
+	var codes []string
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		now := common.JSONTime(time.Now())
+		if err := tx.Model(&tf).Updates(map[string]interface{}{
+			"enabled":    true,
+			"enabled_at": now,
+			"last_step":  step,
+		}).Error; err != nil {
+			return err
+		}
+		var err error
+		codes, err = replaceRecoveryCodes(tx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBUpdateFailed, "启用两步验证失败")
+	}
+
+	return codes, nil
+}
+
+/* DisableTwoFactor 校验验证码或恢复码后关闭两步验证 */
+func DisableTwoFactor(userID uint, role int, code string) error {
+	if IsTwoFactorEnforced(role) {
+		return errors.New(errors.CodeForbidden, "管理员账号必须启用两步验证，无法关闭")
+	}
+	if !IsTwoFactorEnabled(userID) {
+		return errors.New(errors.CodeInvalidRequest, "未启用两步验证")
+	}
+	if _, err := verifyTwoFactorCode(userID, code); err != nil {
+		return err
+	}
+	return removeTwoFactor(userID)
+}
+
+/* RegenerateRecoveryCodes 校验验证码后重新生成恢复码，旧恢复码全部失效 */
+func RegenerateRecoveryCodes(userID uint, code string) ([]string, error) {
+	var tf models.UserTwoFactor
+	if err := database.DB.Where("user_id = ? AND enabled = ?", userID, true).First(&tf).Error; err != nil {
+		return nil, errors.New(errors.CodeInvalidRequest, "未启用两步验证")
+	}
+	if err := verifyTOTP(&tf, code); err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = replaceRecoveryCodes(tx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBUpdateFailed, "生成恢复码失败")
+	}
+	return codes, nil
+}
+
+/* AdminResetTwoFactor 管理员重置用户两步验证（用于设备丢失等场景） */
+func AdminResetTwoFactor(userID uint) error {
+	var user models.User
+	if err := database.DB.Select("id").First(&user, userID).Error; err != nil {
+		return errors.New(errors.CodeUserNotFound, "用户不存在")
+	}
+	return removeTwoFactor(userID)
+}
+
+/* VerifyTwoFactorLogin 提交两步验证码完成登录，失败次数计入登录锁定 */
+func VerifyTwoFactorLogin(challenge, code string, client SessionClient) (*LoginResult, error) {
+	clientIP := client.IP
+
+	challengeKey := fmt.Sprintf(twoFactorChallengeCache, challenge)
+	val, err := cache.GetCache().Get(challengeKey)
+	if err != nil || val == "" {
+		return nil, errors.New(errors.CodeTokenExpired, "验证已过期，请重新登录")
+	}
+	id, err := strconv.ParseUint(val, 10, 32)
+	if err != nil {
+		return nil, errors.New(errors.CodeInvalidToken, "无效的验证请求")
+	}
+	userID := uint(id)
+
+	cfg, err := loadLoginSecurityConfig()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkLoginLock(userID); err != nil {
+		_ = cache.GetCache().Del(challengeKey)
+		return nil, err
+	}
+
+	usedRecovery, err := verifyTwoFactorCode(userID, code)
+	if err != nil {
+		activity.LogTwoFactorLogin(userID, clientIP, false, false)
+		failErr := recordLoginFailure(userID, cfg, errors.CodeTwoFactorInvalid, "验证码错误")
+		if checkLoginLock(userID) != nil {
+			_ = cache.GetCache().Del(challengeKey)
+		}
+		return nil, failErr
+	}
+
+	clearLoginFailures(userID)
+	_ = cache.GetCache().Del(challengeKey)
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return nil, errors.New(errors.CodeUserNotFound, "用户不存在")
+	}
+	if !user.IsNormal() {
+		return nil, errors.New(errors.CodeUserDisabled, "账号已被禁用")
+	}
+
+	res, err := issueLoginToken(&user, cfg, client)
+	if err != nil {
+		return nil, err
+	}
+
+	activity.LogTwoFactorLogin(userID, clientIP, true, usedRecovery)
+	return res, nil
+}
+
+func createTwoFactorChallenge(userID uint) (string, error) {
+	challenge := strings.ReplaceAll(uuid.New().String(), "-", "")
+	key := fmt.Sprintf(twoFactorChallengeCache, challenge)
+	if err := cache.GetCache().Set(key, strconv.FormatUint(uint64(userID), 10), twoFactorChallengeTTL); err != nil {
+		return "", errors.Wrap(err, errors.CodeInternal, "创建两步验证请求失败")
+	}
+	return challenge, nil
+}
+
+/* verifyTwoFactorCode 校验TOTP验证码或一次性恢复码，返回是否使用了恢复码 */
+func verifyTwoFactorCode(userID uint, code string) (bool, error) {
+	var tf models.UserTwoFactor
+	if err := database.DB.Where("user_id = ? AND enabled = ?", userID, true).First(&tf).Error; err != nil {
+		return false, errors.New(errors.CodeInvalidRequest, "未启用两步验证")
+	}
+
+	normalized := normalizeRecoveryCode(code)
+	if len(normalized) != recoveryCodeLength {
+		return false, verifyTOTP(&tf, code)
+	}
+
+	now := common.JSONTime(time.Now())
+	result := database.DB.Model(&models.UserRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hashRecoveryCode(normalized)).
+		Update("used_at", now)
+	if result.Error != nil {
+		return false, errors.Wrap(result.Error, errors.CodeDBUpdateFailed, "校验恢复码失败")
+	}
+	if result.RowsAffected == 0 {
+		return false, errors.New(errors.CodeTwoFactorInvalid, "恢复码无效或已使用")
+	}
+	return true, nil
+}
+
+/* verifyTOTP 校验TOTP并推进时间步，同一验证码只能使用一次 */
+func verifyTOTP(tf *models.UserTwoFactor, code string) error {
+	step, ok := utils.ValidateTOTP(tf.Secret, code, time.Now(), tf.LastStep)
+	if !ok {
+		return errors.New(errors.CodeTwoFactorInvalid, "验证码错误")
+	}
+
+	result := database.DB.Model(&models.UserTwoFactor{}).
+		Where("id = ? AND last_step < ?", tf.ID, step).
+		Update("last_step", step)
+	if result.Error != nil {
+		return errors.Wrap(result.Error, errors.CodeDBUpdateFailed, "更新两步验证状态失败")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(errors.CodeTwoFactorInvalid, "验证码已使用，请等待下一个验证码")
+	}
+	tf.LastStep = step
+	return nil
+}
+
+func removeTwoFactor(userID uint) error {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserTwoFactor{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.UserRecoveryCode{}).Error
+	})
+	if err != nil {
+		return errors.Wrap(err, errors.CodeDBDeleteFailed, "关闭两步验证失败")
+	}
+	return nil
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.UserRecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]models.UserRecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw, err := randomRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, raw[:recoveryCodeLength/2]+"-"+raw[recoveryCodeLength/2:])
+		records = append(records, models.UserRecoveryCode{UserID: userID, CodeHash: hashRecoveryCode(raw)})
+	}
+
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func randomRecoveryCode() (string, error) {
+	buf := make([]byte, recoveryCodeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	for i := range buf {
+		buf[i] = recoveryCodeAlphabet[int(buf[i])%len(recoveryCodeAlphabet)]
+	}
+	return string(buf), nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
+
+func hashRecoveryCode(normalized string) string {
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
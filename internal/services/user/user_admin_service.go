@@ -42,7 +42,7 @@ func GetUserList(query *dto.AdminUserListQueryDTO) (*dto.AdminUserListResponseDT
 	}
 
 	if query.Keyword != "" {
-		dbQuery = dbQuery.Where("username LIKE ? OR email LIKE ?", "%"+query.Keyword+"%", "%"+query.Keyword+"%")
+		dbQuery = dbQuery.Where(database.ILike("username LIKE ? OR email LIKE ?"), "%"+query.Keyword+"%", "%"+query.Keyword+"%")
 	}
 
 	var total int64
@@ -412,11 +412,58 @@ func AdminResetUserPassword(id uint) (*dto.AdminResetUserPasswordResponseDTO, er
 		return nil, errors.Wrap(err, errors.CodeDBUpdateFailed, "重置密码失败")
 	}
 
+	if _, err := RevokeAllUserSessions(user.ID, SessionRevokePassword); err != nil {
+		logger.Warn("重置密码后吊销会话失败: userID=%d, error=%v", user.ID, err)
+	}
+
 	return &dto.AdminResetUserPasswordResponseDTO{
 		NewPassword: newPassword,
 	}, nil
 }
 
+/* RecoverAdminAccount 命令行恢复管理员账号：重设密码、恢复为正常状态并吊销全部会话，可同时清除两步验证；newPassword 为空时生成随机密码 */
+func RecoverAdminAccount(username, newPassword string, resetTwoFactor bool) (*models.User, string, error) {
+	db := database.GetDB()
+
+	var user models.User
+	if err := db.Where("username = ?", username).First(&user).Error; err != nil {
+		return nil, "", errors.New(errors.CodeUserNotFound, "用户不存在")
+	}
+	if !user.IsAdmin() {
+		return nil, "", errors.New(errors.CodeForbidden, "该用户不是管理员")
+	}
+
+	if newPassword == "" {
+		newPassword = utils.GenerateRandomString(12)
+	}
+	hashedPassword, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return nil, "", errors.Wrap(err, errors.CodeInternal, "密码加密失败")
+	}
+
+	if err := db.Model(&user).Updates(map[string]interface{}{
+		"password": hashedPassword,
+		"status":   common.UserStatusNormal,
+	}).Error; err != nil {
+		return nil, "", errors.Wrap(err, errors.CodeDBUpdateFailed, "重置密码失败")
+	}
+	if user.Status != common.UserStatusNormal {
+		syncUserStatusToRedis(user.ID, common.UserStatusNormal)
+		user.Status = common.UserStatusNormal
+	}
+
+	if resetTwoFactor {
+		if err := removeTwoFactor(user.ID); err != nil {
+			return nil, "", err
+		}
+	}
+	if _, err := RevokeAllUserSessions(user.ID, SessionRevokePassword); err != nil {
+		logger.Warn("重置密码后吊销会话失败: userID=%d, error=%v", user.ID, err)
+	}
+
+	return &user, newPassword, nil
+}
+
 func AdminSendUserEmail(emailDTO *dto.AdminSendUserEmailDTO) error {
 	db := database.GetDB()
 
@@ -0,0 +1,60 @@
+package user
+
+import (
+	"strings"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/common"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/logger"
+	"pixelpunk/pkg/storage/tenant"
+	"pixelpunk/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+/* CreateImportedUser 创建从其他图床迁移来的用户，使用默认的存储和带宽限制
+ * passwordHash 是 bcrypt 哈希时保留原密码，否则设置随机密码，用户需通过找回密码重新设置 */
+func CreateImportedUser(username, email, passwordHash string) (*models.User, error) {
+	if !strings.HasPrefix(passwordHash, "$2") {
+		hashed, err := utils.HashPassword(utils.GenerateRandomString(32))
+		if err != nil {
+			return nil, errors.Wrap(err, errors.CodeInternal, "密码加密失败")
+		}
+		passwordHash = hashed
+	}
+
+	user := &models.User{
+		Username:  username,
+		Email:     email,
+		Password:  passwordHash,
+		Role:      common.UserRoleUser,
+		Status:    common.UserStatusNormal,
+		PathAlias: utils.GenerateRandomString(16),
+	}
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBCreateFailed, "创建用户失败")
+		}
+		if err := tx.Create(&models.UserSettings{
+			UserID:         user.ID,
+			StorageLimit:   models.DefaultStorageLimit,
+			BandwidthLimit: models.DefaultBandwidthLimit,
+		}).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBCreateFailed, "创建用户设置失败")
+		}
+		if err := tx.Create(&models.UserUsageStats{UserID: user.ID}).Error; err != nil {
+			return errors.Wrap(err, errors.CodeDBCreateFailed, "创建用户统计失败")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, aliasErr := tenant.ResolveAlias(user.ID); aliasErr != nil {
+		logger.Warn("生成用户路径别名失败(导入): userID=%d, err=%v", user.ID, aliasErr)
+	}
+	return user, nil
+}
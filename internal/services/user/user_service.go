@@ -38,78 +38,109 @@ func GetUserService() *UserService {
 	return userService
 }
 
-func Login(account, password string) (map[string]interface{}, string, error) {
-	db := database.GetDB()
-	var user models.User
-	result := db.Where("username = ? OR email = ?", account, account).First(&user)
-	if result.Error != nil {
-		return nil, "", errors.New(errors.CodeUserNotFound, "用户不存在")
-	}
-
-	securitySettings, err := setting.GetSettingsByGroupAsMap("security")
+/* LoginResult 登录结果：已启用两步验证时仅返回验证挑战，不签发令牌 */
+type LoginResult struct {
+	UserInfo               map[string]interface{}
+	Token                  string
+	TwoFactorRequired      bool   // 需要提交两步验证码
+	Challenge              string // 两步验证挑战ID
+	TwoFactorSetupRequired bool   // 管理员被强制要求启用两步验证但尚未启用
+
+	RefreshToken     string          // 刷新令牌，每次刷新后轮换
+	SessionID        string          // 登录会话ID
+	ExpiresIn        int64           // 访问令牌有效期(秒)
+	RefreshExpiresAt common.JSONTime // 刷新令牌（会话）过期时间
+}
 
-	maxLoginAttempts := 5
-	accountLockoutMinutes := 30
+/* loginSecurityConfig 登录相关的安全配置 */
+type loginSecurityConfig struct {
+	jwtSecret             string
+	expiresHours          int
+	maxLoginAttempts      int
+	accountLockoutMinutes int
+	accessTokenMinutes    int
+}
 
+func loadLoginSecurityConfig() (*loginSecurityConfig, error) {
+	securitySettings, err := setting.GetSettingsByGroupAsMap("security")
 	if err != nil {
-		return nil, "", errors.New(errors.CodeInternal, "安全配置读取失败：security 组缺失")
+		return nil, errors.New(errors.CodeInternal, "安全配置读取失败：security 组缺失")
+	}
+
+	cfg := &loginSecurityConfig{
+		maxLoginAttempts:      5,
+		accountLockoutMinutes: 30,
+		accessTokenMinutes:    defaultAccessTokenMinute,
 	}
 
-	jwtSecret := ""
 	if val, ok := securitySettings.Settings["jwt_secret"]; ok {
 		if secretStr, ok := val.(string); ok {
-			jwtSecret = secretStr
+			cfg.jwtSecret = secretStr
 		}
 	}
-	if strings.TrimSpace(jwtSecret) == "" {
-		return nil, "", errors.New(errors.CodeInternal, "安全配置缺失：jwt_secret 未设置")
+	if strings.TrimSpace(cfg.jwtSecret) == "" {
+		return nil, errors.New(errors.CodeInternal, "安全配置缺失：jwt_secret 未设置")
 	}
 
-	expiresHours := 0
 	if val, ok := securitySettings.Settings["login_expire_hours"]; ok {
 		if hours, ok := val.(float64); ok && hours > 0 {
-			expiresHours = int(hours)
+			cfg.expiresHours = int(hours)
 		}
 	}
-	if expiresHours <= 0 {
-		return nil, "", errors.New(errors.CodeInternal, "安全配置缺失：login_expire_hours 未设置或非法")
+	if cfg.expiresHours <= 0 {
+		return nil, errors.New(errors.CodeInternal, "安全配置缺失：login_expire_hours 未设置或非法")
 	}
 
 	if val, ok := securitySettings.Settings["max_login_attempts"]; ok {
 		if attempts, ok := val.(float64); ok && attempts > 0 {
-			maxLoginAttempts = int(attempts)
+			cfg.maxLoginAttempts = int(attempts)
 		}
 	}
 	if val, ok := securitySettings.Settings["account_lockout_minutes"]; ok {
 		if minutes, ok := val.(float64); ok && minutes > 0 {
-			accountLockoutMinutes = int(minutes)
+			cfg.accountLockoutMinutes = int(minutes)
+		}
+	}
+	if val, ok := securitySettings.Settings["access_token_minutes"]; ok {
+		if minutes, ok := val.(float64); ok && minutes > 0 {
+			cfg.accessTokenMinutes = int(minutes)
 		}
 	}
 
-	lockKey := fmt.Sprintf("user:login:lock:%d", user.ID)
-	if cache.GetCache().Exists(lockKey) {
-		ttl, err := cache.GetCache().TTL(lockKey)
-		if err == nil && ttl > 0 {
-			minutes := int(ttl.Minutes())
-			seconds := int(ttl.Seconds()) % 60
-
-			var timeMsg string
-			if minutes > 0 {
-				timeMsg = fmt.Sprintf("%d分钟", minutes)
-				if seconds > 0 {
-					timeMsg += fmt.Sprintf("%d秒", seconds)
-				}
-			} else {
-				timeMsg = fmt.Sprintf("%d秒", seconds)
-			}
+	return cfg, nil
+}
+
+/* checkLoginLock 检查账户是否处于锁定状态 */
+func checkLoginLock(userID uint) error {
+	lockKey := fmt.Sprintf("user:login:lock:%d", userID)
+	if !cache.GetCache().Exists(lockKey) {
+		return nil
+	}
 
-			return nil, "", errors.New(errors.CodeForbidden, fmt.Sprintf("账户已被锁定，请%s后再试", timeMsg))
+	ttl, err := cache.GetCache().TTL(lockKey)
+	if err == nil && ttl > 0 {
+		minutes := int(ttl.Minutes())
+		seconds := int(ttl.Seconds()) % 60
+
+		var timeMsg string
+		if minutes > 0 {
+			timeMsg = fmt.Sprintf("%d分钟", minutes)
+			if seconds > 0 {
+				timeMsg += fmt.Sprintf("%d秒", seconds)
+			}
+		} else {
+			timeMsg = fmt.Sprintf("%d秒", seconds)
 		}
 
-		return nil, "", errors.New(errors.CodeForbidden, "账户已被锁定，请稍后再试")
+		return errors.New(errors.CodeForbidden, fmt.Sprintf("账户已被锁定，请%s后再试", timeMsg))
 	}
 
-	attemptKey := fmt.Sprintf("user:login:attempts:%d", user.ID)
+	return errors.New(errors.CodeForbidden, "账户已被锁定，请稍后再试")
+}
+
+/* recordLoginFailure 记录一次登录失败（密码或两步验证码），达到上限时锁定账户 */
+func recordLoginFailure(userID uint, cfg *loginSecurityConfig, code errors.ErrorCode, reason string) error {
+	attemptKey := fmt.Sprintf("user:login:attempts:%d", userID)
 	attemptCount := 0
 	if val, err := cache.GetCache().Get(attemptKey); err == nil && val != "" {
 		if count, err := strconv.Atoi(val); err == nil {
@@ -117,41 +148,120 @@ func Login(account, password string) (map[string]interface{}, string, error) {
 		}
 	}
 
+	attemptCount++
+	_ = cache.GetCache().Set(attemptKey, fmt.Sprintf("%d", attemptCount), time.Minute)
+
+	if attemptCount >= cfg.maxLoginAttempts {
+		_ = cache.GetCache().Set(fmt.Sprintf("user:login:lock:%d", userID), "1", time.Duration(cfg.accountLockoutMinutes)*time.Minute)
+		_ = cache.GetCache().Del(attemptKey)
+
+		return errors.New(errors.CodeForbidden,
+			fmt.Sprintf("%s次数过多，账户已被锁定%d分钟", reason, cfg.accountLockoutMinutes))
+	}
+
+	return errors.New(code, fmt.Sprintf("%s，还有%d次尝试机会", reason, cfg.maxLoginAttempts-attemptCount))
+}
+
+func clearLoginFailures(userID uint) {
+	_ = cache.GetCache().Del(fmt.Sprintf("user:login:attempts:%d", userID))
+}
+
+func Login(account, password string, client SessionClient) (*LoginResult, error) {
+	db := database.GetDB()
+	var user models.User
+	result := db.Where("username = ? OR email = ?", account, account).First(&user)
+	if result.Error != nil {
+		return nil, errors.New(errors.CodeUserNotFound, "用户不存在")
+	}
+
+	cfg, err := loadLoginSecurityConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkLoginLock(user.ID); err != nil {
+		return nil, err
+	}
+
 	if !utils.ComparePasswords(user.Password, password) {
-		attemptCount++
-		_ = cache.GetCache().Set(attemptKey, fmt.Sprintf("%d", attemptCount), time.Minute)
+		return nil, recordLoginFailure(user.ID, cfg, errors.CodeWrongPassword, "密码错误")
+	}
+
+	clearLoginFailures(user.ID)
+
+	if !user.IsNormal() {
+		return nil, errors.New(errors.CodeUserDisabled, "账号已被禁用")
+	}
 
-		if attemptCount >= maxLoginAttempts {
-			_ = cache.GetCache().Set(lockKey, "1", time.Duration(accountLockoutMinutes)*time.Minute)
-			_ = cache.GetCache().Del(attemptKey)
+	return completeLogin(&user, cfg, client)
+}
+
+/* CompleteLogin 第三方登录等已完成身份确认的场景下继续登录流程（含两步验证） */
+func CompleteLogin(user *models.User, client SessionClient) (*LoginResult, error) {
+	cfg, err := loadLoginSecurityConfig()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkLoginLock(user.ID); err != nil {
+		return nil, err
+	}
+	return completeLogin(user, cfg, client)
+}
 
-			return nil, "", errors.New(errors.CodeForbidden,
-				fmt.Sprintf("密码错误次数过多，账户已被锁定%d分钟", accountLockoutMinutes))
+func completeLogin(user *models.User, cfg *loginSecurityConfig, client SessionClient) (*LoginResult, error) {
+	if IsTwoFactorEnabled(user.ID) {
+		challenge, err := createTwoFactorChallenge(user.ID)
+		if err != nil {
+			return nil, err
 		}
+		return &LoginResult{TwoFactorRequired: true, Challenge: challenge}, nil
+	}
 
-		return nil, "", errors.New(errors.CodeWrongPassword,
-			fmt.Sprintf("密码错误，还有%d次尝试机会", maxLoginAttempts-attemptCount))
+	res, err := issueLoginToken(user, cfg, client)
+	if err != nil {
+		return nil, err
 	}
+	res.TwoFactorSetupRequired = IsTwoFactorSetupRequired(user.ID, user.Role)
+	return res, nil
+}
 
-	_ = cache.GetCache().Del(attemptKey)
+/* issueLoginToken 创建登录会话并签发访问令牌与刷新令牌 */
+func issueLoginToken(user *models.User, cfg *loginSecurityConfig, client SessionClient) (*LoginResult, error) {
+	session, refreshToken, err := createSession(user.ID, cfg, client)
+	if err != nil {
+		return nil, err
+	}
 
-	if !user.IsNormal() {
-		return nil, "", errors.New(errors.CodeUserDisabled, "账号已被禁用")
+	token, err := signAccessToken(user, session.ID, cfg)
+	if err != nil {
+		return nil, err
 	}
 
-	token, err := auth.GenerateToken(user.ID, user.Username, int(user.Role), jwtSecret, expiresHours)
+	return &LoginResult{
+		UserInfo:         buildLoginUserInfo(user),
+		Token:            token,
+		RefreshToken:     refreshToken,
+		SessionID:        session.ID,
+		ExpiresIn:        int64(cfg.accessTokenTTL().Seconds()),
+		RefreshExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+func signAccessToken(user *models.User, sessionID string, cfg *loginSecurityConfig) (string, error) {
+	token, err := auth.GenerateSessionToken(user.ID, user.Username, int(user.Role), cfg.jwtSecret, sessionID, cfg.accessTokenTTL())
 	if err != nil {
-		return nil, "", errors.New(errors.CodeInternal, "生成token失败")
+		return "", errors.New(errors.CodeInternal, "生成token失败")
 	}
+	return token, nil
+}
 
+func buildLoginUserInfo(user *models.User) map[string]interface{} {
 	avatarFullPath := ""
 	if user.Avatar != "" {
 		avatarFullPath = utils.GetSystemFileURL(user.Avatar)
-	} else {
-		avatarFullPath = ""
 	}
 
-	userInfo := map[string]interface{}{
+	return map[string]interface{}{
 		"id":             user.ID,
 		"username":       user.Username,
 		"email":          user.Email,
@@ -162,8 +272,6 @@ func Login(account, password string) (map[string]interface{}, string, error) {
 		"role":           user.Role,
 		"status":         user.Status,
 	}
-
-	return userInfo, token, nil
 }
 
 func FindUsers() ([]models.User, error) {
@@ -527,6 +635,13 @@ func ResetPassword(email, code, newPassword string) error {
 		return errors.New(errors.CodeUserNotFound, "未找到用户")
 	}
 
+	var user models.User
+	if err := db.Select("id").Where("email = ?", email).First(&user).Error; err == nil {
+		if _, err := RevokeAllUserSessions(user.ID, SessionRevokePassword); err != nil {
+			logger.Warn("重置密码后吊销会话失败: userID=%d, error=%v", user.ID, err)
+		}
+	}
+
 	return nil
 }
 
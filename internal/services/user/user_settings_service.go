@@ -81,3 +81,17 @@ func UpdateUserSettings(userID uint, storageLimit, bandwidthLimit int64, default
 
 	return settings, nil
 }
+
+/* UpdateUserExifPolicy 设置用户的上传元数据策略，为空时使用系统设置 */
+func UpdateUserExifPolicy(userID uint, policy string) error {
+	if policy != "" && !models.IsValidExifPolicy(policy) {
+		return errors.New(errors.CodeInvalidParameter, "无效的元数据策略")
+	}
+	if _, err := GetUserSettings(userID); err != nil {
+		return err
+	}
+	if err := database.DB.Model(&models.UserSettings{}).Where("user_id = ?", userID).Update("exif_policy", policy).Error; err != nil {
+		return errors.Wrap(err, errors.CodeDBUpdateFailed, "更新元数据策略失败")
+	}
+	return nil
+}
@@ -20,7 +20,7 @@ func getGreatestExpr(column string, delta interface{}) clause.Expr {
 		// CASE WHEN (total_bandwidth + ?) < 0 THEN 0 ELSE (total_bandwidth + ?) END
 		return gorm.Expr("CASE WHEN ("+column+" + ?) < 0 THEN 0 ELSE ("+column+" + ?) END", delta, delta)
 	}
-	// MySQL 使用 GREATEST 函数
+	// MySQL 和 PostgreSQL 使用 GREATEST 函数
 	return gorm.Expr("GREATEST("+column+" + ?, 0)", delta)
 }
 
@@ -0,0 +1,119 @@
+package user
+
+import (
+	"fmt"
+	"strings"
+
+	"pixelpunk/internal/controllers/user/dto"
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+
+	"gorm.io/gorm"
+)
+
+const maxWatermarkPolicies = 200
+
+/* ListWatermarkPolicies 获取用户的访问时水印策略 */
+func ListWatermarkPolicies(userID uint) ([]models.WatermarkPolicy, error) {
+	var policies []models.WatermarkPolicy
+	if err := database.DB.Where("user_id = ?", userID).
+		Order("scope ASC, id ASC").
+		Find(&policies).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询水印策略失败")
+	}
+	return policies, nil
+}
+
+/* CreateWatermarkPolicy 创建访问时水印策略，同一目标只能有一条策略 */
+func CreateWatermarkPolicy(userID uint, req *dto.WatermarkPolicyDTO) (*models.WatermarkPolicy, error) {
+	var count int64
+	database.DB.Model(&models.WatermarkPolicy{}).Where("user_id = ?", userID).Count(&count)
+	if count >= maxWatermarkPolicies {
+		return nil, errors.New(errors.CodeInvalidRequest, fmt.Sprintf("每个用户最多创建%d条水印策略", maxWatermarkPolicies))
+	}
+
+	policy := models.WatermarkPolicy{UserID: userID}
+	if err := fillWatermarkPolicy(&policy, req); err != nil {
+		return nil, err
+	}
+	if err := database.DB.Create(&policy).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBCreateFailed, "创建水印策略失败")
+	}
+	return &policy, nil
+}
+
+/* UpdateWatermarkPolicy 更新访问时水印策略 */
+func UpdateWatermarkPolicy(userID, policyID uint, req *dto.WatermarkPolicyDTO) (*models.WatermarkPolicy, error) {
+	var policy models.WatermarkPolicy
+	if err := database.DB.Where("id = ? AND user_id = ?", policyID, userID).First(&policy).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New(errors.CodeNotFound, "水印策略不存在")
+		}
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询水印策略失败")
+	}
+
+	if err := fillWatermarkPolicy(&policy, req); err != nil {
+		return nil, err
+	}
+	if err := database.DB.Save(&policy).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBUpdateFailed, "更新水印策略失败")
+	}
+	return &policy, nil
+}
+
+/* DeleteWatermarkPolicy 删除访问时水印策略 */
+func DeleteWatermarkPolicy(userID, policyID uint) error {
+	result := database.DB.Where("id = ? AND user_id = ?", policyID, userID).Delete(&models.WatermarkPolicy{})
+	if result.Error != nil {
+		return errors.Wrap(result.Error, errors.CodeDBDeleteFailed, "删除水印策略失败")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(errors.CodeNotFound, "水印策略不存在")
+	}
+	return nil
+}
+
+// fillWatermarkPolicy 校验策略目标与水印方案的归属，并写入策略字段
+func fillWatermarkPolicy(policy *models.WatermarkPolicy, req *dto.WatermarkPolicyDTO) error {
+	targetID := strings.TrimSpace(req.TargetID)
+
+	switch req.Scope {
+	case models.WatermarkPolicyScopeFolder:
+		var count int64
+		database.DB.Model(&models.Folder{}).Where("id = ? AND user_id = ?", targetID, policy.UserID).Count(&count)
+		if count == 0 {
+			return errors.New(errors.CodeFolderNotFound, "文件夹不存在")
+		}
+	case models.WatermarkPolicyScopeShare:
+		var count int64
+		database.DB.Model(&models.Share{}).Where("id = ? AND user_id = ?", targetID, policy.UserID).Count(&count)
+		if count == 0 {
+			return errors.New(errors.CodeNotFound, "分享不存在")
+		}
+	case models.WatermarkPolicyScopeAccessLevel:
+		if targetID != "public" && targetID != "protected" && targetID != "private" {
+			return errors.New(errors.CodeInvalidParameter, "访问级别必须是 public、protected 或 private")
+		}
+	default:
+		return errors.New(errors.CodeInvalidParameter, "不支持的策略范围")
+	}
+
+	if _, err := GetWatermarkProfile(policy.UserID, req.ProfileID); err != nil {
+		return err
+	}
+
+	var existing models.WatermarkPolicy
+	err := database.DB.Where("user_id = ? AND scope = ? AND target_id = ? AND id <> ?", policy.UserID, req.Scope, targetID, policy.ID).
+		First(&existing).Error
+	if err == nil {
+		return errors.New(errors.CodeConflict, "该目标已存在水印策略")
+	}
+
+	policy.Scope = req.Scope
+	policy.TargetID = targetID
+	policy.ProfileID = req.ProfileID
+	policy.ApplyToThumbnails = req.ApplyToThumbnails
+	policy.Enabled = req.Enabled == nil || *req.Enabled
+	return nil
+}
@@ -0,0 +1,193 @@
+package user
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"pixelpunk/internal/controllers/user/dto"
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/database"
+	"pixelpunk/pkg/errors"
+	"pixelpunk/pkg/watermark"
+
+	"gorm.io/gorm"
+)
+
+const (
+	maxWatermarkProfiles      = 20
+	maxWatermarkProfileConfig = 2 << 20 // 配置可能内嵌base64水印图片
+)
+
+/* ListWatermarkProfiles 获取用户的水印方案 */
+func ListWatermarkProfiles(userID uint) ([]models.WatermarkProfile, error) {
+	var profiles []models.WatermarkProfile
+	if err := database.DB.Where("user_id = ?", userID).
+		Order("is_default DESC, id ASC").
+		Find(&profiles).Error; err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询水印方案失败")
+	}
+	return profiles, nil
+}
+
+/* GetWatermarkProfile 获取用户自己的水印方案 */
+func GetWatermarkProfile(userID, profileID uint) (*models.WatermarkProfile, error) {
+	var profile models.WatermarkProfile
+	if err := database.DB.Where("id = ? AND user_id = ?", profileID, userID).First(&profile).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New(errors.CodeNotFound, "水印方案不存在")
+		}
+		return nil, errors.Wrap(err, errors.CodeDBQueryFailed, "查询水印方案失败")
+	}
+	return &profile, nil
+}
+
+/* CreateWatermarkProfile 创建水印方案 */
+func CreateWatermarkProfile(userID uint, req *dto.WatermarkProfileDTO) (*models.WatermarkProfile, error) {
+	var count int64
+	database.DB.Model(&models.WatermarkProfile{}).Where("user_id = ?", userID).Count(&count)
+	if count >= maxWatermarkProfiles {
+		return nil, errors.New(errors.CodeInvalidRequest, fmt.Sprintf("每个用户最多保存%d个水印方案", maxWatermarkProfiles))
+	}
+
+	config, err := normalizeWatermarkProfileConfig(req.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := models.WatermarkProfile{
+		UserID:    userID,
+		Name:      strings.TrimSpace(req.Name),
+		Config:    config,
+		IsDefault: req.IsDefault,
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if profile.IsDefault {
+			if err := clearDefaultWatermarkProfile(tx, userID); err != nil {
+				return err
+			}
+		}
+		return tx.Create(&profile).Error
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBCreateFailed, "创建水印方案失败")
+	}
+	return &profile, nil
+}
+
+/* UpdateWatermarkProfile 更新水印方案 */
+func UpdateWatermarkProfile(userID, profileID uint, req *dto.WatermarkProfileDTO) (*models.WatermarkProfile, error) {
+	profile, err := GetWatermarkProfile(userID, profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := normalizeWatermarkProfileConfig(req.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	profile.Name = strings.TrimSpace(req.Name)
+	profile.Config = config
+	profile.IsDefault = req.IsDefault
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if profile.IsDefault {
+			if err := clearDefaultWatermarkProfile(tx, userID); err != nil {
+				return err
+			}
+		}
+		return tx.Save(profile).Error
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeDBUpdateFailed, "更新水印方案失败")
+	}
+	return profile, nil
+}
+
+/* DeleteWatermarkProfile 删除水印方案，引用该方案的访问时水印策略一并删除 */
+func DeleteWatermarkProfile(userID, profileID uint) error {
+	if _, err := GetWatermarkProfile(userID, profileID); err != nil {
+		return err
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND profile_id = ?", userID, profileID).Delete(&models.WatermarkPolicy{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ? AND user_id = ?", profileID, userID).Delete(&models.WatermarkProfile{}).Error
+	})
+	if err != nil {
+		return errors.Wrap(err, errors.CodeDBDeleteFailed, "删除水印方案失败")
+	}
+	return nil
+}
+
+/* SetDefaultWatermarkProfile 设置默认水印方案，profileID 为0时取消默认 */
+func SetDefaultWatermarkProfile(userID, profileID uint) error {
+	if profileID != 0 {
+		if _, err := GetWatermarkProfile(userID, profileID); err != nil {
+			return err
+		}
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := clearDefaultWatermarkProfile(tx, userID); err != nil {
+			return err
+		}
+		if profileID == 0 {
+			return nil
+		}
+		return tx.Model(&models.WatermarkProfile{}).Where("id = ?", profileID).Update("is_default", true).Error
+	})
+	if err != nil {
+		return errors.Wrap(err, errors.CodeDBUpdateFailed, "设置默认水印方案失败")
+	}
+	return nil
+}
+
+/* GetDefaultWatermarkConfig 获取用户默认水印方案的配置，未设置时返回空字符串 */
+func GetDefaultWatermarkConfig(userID uint) (string, error) {
+	if userID == 0 {
+		return "", nil
+	}
+
+	var profile models.WatermarkProfile
+	err := database.DB.Where("user_id = ? AND is_default = ?", userID, true).First(&profile).Error
+	if err == gorm.ErrRecordNotFound {
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.Wrap(err, errors.CodeDBQueryFailed, "查询默认水印方案失败")
+	}
+	return string(profile.Config), nil
+}
+
+func clearDefaultWatermarkProfile(tx *gorm.DB, userID uint) error {
+	return tx.Model(&models.WatermarkProfile{}).
+		Where("user_id = ? AND is_default = ?", userID, true).
+		Update("is_default", false).Error
+}
+
+// normalizeWatermarkProfileConfig 校验水印配置并统一为启用状态
+func normalizeWatermarkProfileConfig(raw json.RawMessage) (json.RawMessage, error) {
+	if len(raw) > maxWatermarkProfileConfig {
+		return nil, errors.New(errors.CodeInvalidParameter, "水印配置过大")
+	}
+
+	config, err := watermark.ParseConfigFromJSON(string(raw))
+	if err != nil {
+		return nil, errors.New(errors.CodeInvalidParameter, err.Error())
+	}
+	config.Enabled = true
+	if err := watermark.ValidateConfig(config); err != nil {
+		return nil, errors.New(errors.CodeInvalidParameter, "水印配置无效: "+err.Error())
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeInternal, "序列化水印配置失败")
+	}
+	return data, nil
+}
@@ -46,7 +46,7 @@ func GetCategoryList(userID uint, keyword, status, sortBy, sortOrder string, pag
 
 	if keyword != "" {
 		keyword = strings.TrimSpace(keyword)
-		query = query.Where("name LIKE ? OR description LIKE ?", "%"+keyword+"%", "%"+keyword+"%")
+		query = query.Where(database.ILike("name LIKE ? OR description LIKE ?"), "%"+keyword+"%", "%"+keyword+"%")
 	}
 
 	if status != "" && (status == "active" || status == "archived") {
@@ -26,7 +26,7 @@ func GetUserTagList(userID uint, keyword, sortBy, sortOrder string, page, pageSi
 
 	if keyword != "" {
 		keyword = strings.TrimSpace(keyword)
-		query = query.Where("global_tag.name LIKE ?", "%"+keyword+"%")
+		query = query.Where(database.ILike("global_tag.name LIKE ?"), "%"+keyword+"%")
 	}
 
 	// 获取总数（需要子查询）
@@ -35,7 +35,7 @@ func GetUserTagList(userID uint, keyword, sortBy, sortOrder string, page, pageSi
 		Joins("JOIN global_tag ON global_tag.id = user_tag_reference.tag_id").
 		Where("user_tag_reference.user_id = ?", userID)
 	if keyword != "" {
-		countQuery = countQuery.Where("global_tag.name LIKE ?", "%"+keyword+"%")
+		countQuery = countQuery.Where(database.ILike("global_tag.name LIKE ?"), "%"+keyword+"%")
 	}
 	if err := countQuery.Count(&total).Error; err != nil {
 		logger.Error("获取标签总数失败: %v", err)
@@ -6,6 +6,7 @@ import (
 	"sync"
 	"time"
 
+	"pixelpunk/internal/cluster"
 	"pixelpunk/internal/controllers/websocket"
 	metrics "pixelpunk/internal/metrics"
 	"pixelpunk/internal/models"
@@ -131,11 +132,18 @@ func InitGlobalVectorQueue() error {
 	if globalVectorQueueService != nil {
 		return nil
 	}
-	ctx, cancel := context.WithCancel(context.Background())
-
-	concurrency := setting.GetIntDirectFromDB("vector", "vector_concurrency", 3)
 	autoProcessingEnabled := setting.GetBoolDirectFromDB("vector", "vector_auto_processing_enabled", true)
 	paused := !autoProcessingEnabled
+
+	// 不运行 worker 的进程只向队列投递任务，由 worker 进程处理
+	if !cluster.RunsWorkers() {
+		InitVectorQueueClient()
+		globalVectorQueueService.paused = paused
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	concurrency := setting.GetIntDirectFromDB("vector", "vector_concurrency", 3)
 	svc := &VectorQueueService{paused: paused, concurrent: concurrency, ctx: ctx, cancel: cancel, reaperStop: make(chan struct{})}
 
 	if cache.IsRedisEnabled() {
@@ -163,6 +171,38 @@ func InitGlobalVectorQueue() error {
 	return nil
 }
 
+// InitVectorQueueClient 只连接向量队列，不启动处理协程，供命令行向服务进程的队列投递任务
+func InitVectorQueueClient() {
+	if globalVectorQueueService != nil {
+		return
+	}
+	svc := &VectorQueueService{reaperStop: make(chan struct{})}
+	if cache.IsRedisEnabled() {
+		if rq := qqueue.NewRedisQueue(); rq != nil {
+			svc.queue = rq.WithPrefix("vector")
+		}
+	}
+	if svc.queue == nil {
+		svc.queue = qqueue.NewDBQueueVector()
+	}
+	globalVectorQueueService = svc
+}
+
+// SyncRuntimeSettings 按数据库中的设置同步暂停状态和并发数，供收不到其他进程设置变更通知的 worker 进程定时调用
+func SyncRuntimeSettings() {
+	svc := globalVectorQueueService
+	if svc == nil {
+		return
+	}
+	enabled := setting.GetBoolDirectFromDB("vector", "vector_auto_processing_enabled", true)
+	if svc.IsPaused() == enabled {
+		svc.SetPaused(!enabled)
+	}
+	if concurrency := setting.GetIntDirectFromDB("vector", "vector_concurrency", 3); concurrency > 0 && concurrency != svc.concurrent {
+		svc.UpdateConcurrency(concurrency)
+	}
+}
+
 func (s *VectorQueueService) worker(id int) {
 	db := database.GetDB()
 	for {
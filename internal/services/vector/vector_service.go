@@ -36,7 +36,7 @@ func GetVectorList(page, pageSize int, status, model, keyword string) ([]models.
 	}
 
 	if keyword != "" {
-		query = query.Where("file_id LIKE ? OR description LIKE ?",
+		query = query.Where(database.ILike("file_id LIKE ? OR description LIKE ?"),
 			"%"+keyword+"%", "%"+keyword+"%")
 	}
 
@@ -82,7 +82,7 @@ func GetVectorWithFileInfo(page, pageSize int, status, model, keyword string) ([
 	}
 
 	if keyword != "" {
-		query = query.Where("file_id LIKE ? OR description LIKE ?",
+		query = query.Where(database.ILike("file_id LIKE ? OR description LIKE ?"),
 			"%"+keyword+"%", "%"+keyword+"%")
 	}
 
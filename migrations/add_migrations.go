@@ -1,55 +1,60 @@
 package migrations
 
 import (
+	"pixelpunk/pkg/database"
 	"pixelpunk/pkg/logger"
+	"pixelpunk/pkg/migrate"
 
 	"gorm.io/gorm"
 )
 
-// 注册迁移任务
-type migrationTask struct {
-	name string
-	fn   func(*gorm.DB) error
+// 注册的迁移列表，版本号只能递增；已发布的迁移不要再修改，需要调整时新增一个版本
+// 表结构的新增列、新增表仍由模型自动同步，改列类型、删列、重建索引等自动同步做不到的变更在这里编写
+// Go 代码步骤的第二个参数是修订号，修改迁移函数的逻辑时必须递增
+// 版本 1、2 不可回滚：初始化的设置可能已被管理员修改，迁移用户身份时已删除 user 表的旧列
+var registry = &migrate.Set{
+	Schema: database.MigrateModels,
+	Migrations: []migrate.Migration{
+		{
+			Version: 1,
+			Name:    "add_system_settings",
+			Up:      []migrate.Step{migrate.Func("初始化系统设置、分类模板和欢迎公告", 1, AddSystemSettings)},
+		},
+		{
+			Version: 2,
+			Name:    "migrate_user_identities",
+			Up:      []migrate.Step{migrate.Func("将 user 表的第三方ID列迁移到 user_identity 表并删除旧列", 1, MigrateUserIdentities)},
+		},
+		{
+			Version: 3,
+			Name:    "backfill_file_taken_at",
+			Up:      []migrate.Step{migrate.Func("按 EXIF 拍摄时间或上传时间填充文件拍摄时间", 1, BackfillFileTakenAt)},
+			Down:    []migrate.Step{migrate.SQL("UPDATE file SET taken_at = NULL, taken_day = 0")},
+		},
+	},
+	// add_system_settings 内部写入的标记，早期版本用来跳过已合并的迁移
+	Legacy: []string{"add_system_settings_v2", "add_image_access_tokens", "update_image_access_tokens"},
 }
 
-// 注册的迁移列表
-var registeredMigrations = []migrationTask{
-	{"add_system_settings", AddSystemSettings},
+// Registry 返回全部已注册的迁移
+func Registry() *migrate.Set {
+	return registry
 }
 
-// RegisterAllMigrations 注册所有迁移函数
+// RegisterAllMigrations 按版本顺序执行待执行的迁移，某个迁移失败时停止，之后的迁移留到下次执行
 func RegisterAllMigrations(db *gorm.DB) error {
-	if err := EnsureMigrationTable(db); err != nil {
-		logger.Error("确保迁移表存在失败: %v", err)
+	if _, err := registry.Up(db, migrate.Options{}); err != nil {
+		logger.Error("数据迁移失败: %v", err)
 		return err
 	}
-
-	for _, task := range registeredMigrations {
-		applied, err := IsMigrationApplied(db, task.name)
-		if err != nil {
-			logger.Error("检查迁移状态失败: %v", err)
-			continue
-		}
-
-		if applied {
-			continue
-		}
-
-		if err := task.fn(db); err != nil {
-			logger.Error("迁移 %s 执行失败: %v", task.name, err)
-		} else {
-			if err := RecordMigration(db, task.name); err != nil {
-				logger.Error("记录迁移状态失败: %s, 错误: %v", task.name, err)
-			}
-		}
-	}
 	return nil
 }
 
+// GetAllMigrationNames 返回当前程序认识的全部迁移记录名称
 func GetAllMigrationNames() []string {
-	names := make([]string, len(registeredMigrations))
-	for i, task := range registeredMigrations {
-		names[i] = task.name
+	names := make([]string, 0, len(registry.Migrations)+len(registry.Legacy))
+	for _, m := range registry.Migrations {
+		names = append(names, m.Name)
 	}
-	return names
+	return append(names, registry.Legacy...)
 }
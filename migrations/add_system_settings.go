@@ -446,7 +446,7 @@ func AddSystemSettings(db *gorm.DB) error {
 			Value:       DefaultSettings.Upload.PreserveEXIF,
 			Type:        "boolean",
 			Group:       "upload",
-			Description: "是否保留EXIF信息",
+			Description: "是否保留EXIF信息（上传和用户都未指定元数据策略时生效，关闭时清除全部元数据）",
 			IsSystem:    true,
 		},
 		{
@@ -564,6 +564,14 @@ func AddSystemSettings(db *gorm.DB) error {
 			Description: "已登录用户默认存储时长",
 			IsSystem:    true,
 		},
+		{
+			Key:         "watermark_burn_on_upload",
+			Value:       DefaultSettings.Upload.WatermarkBurnOnUpload,
+			Type:        "boolean",
+			Group:       "upload",
+			Description: "上传时将水印直接合成到原图（关闭时保存原图，在非所有者访问时叠加水印）",
+			IsSystem:    true,
+		},
 		// 秒传检测设置
 		{
 			Key:         "instant_upload_enabled",
@@ -716,6 +724,30 @@ func AddSystemSettings(db *gorm.DB) error {
 			Description: "域名黑名单",
 			IsSystem:    true,
 		},
+		{
+			Key:         "signed_url_max_expire",
+			Value:       DefaultSettings.Security.SignedURLMaxExpire,
+			Type:        "number",
+			Group:       "security",
+			Description: "签名链接最长有效期(秒)",
+			IsSystem:    true,
+		},
+		{
+			Key:         "enforce_admin_2fa",
+			Value:       DefaultSettings.Security.EnforceAdmin2FA,
+			Type:        "boolean",
+			Group:       "security",
+			Description: "强制管理员启用两步验证",
+			IsSystem:    true,
+		},
+		{
+			Key:         "access_token_minutes",
+			Value:       DefaultSettings.Security.AccessTokenMinutes,
+			Type:        "number",
+			Group:       "security",
+			Description: "访问令牌有效期(分钟)，过期后使用刷新令牌续期",
+			IsSystem:    true,
+		},
 	}
 	allSettings = append(allSettings, securitySettings...)
 
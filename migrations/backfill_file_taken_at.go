@@ -0,0 +1,74 @@
+package migrations
+
+import (
+	"fmt"
+	"time"
+
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+const takenAtBatchSize = 500
+
+// BackfillFileTakenAt 为已有文件填充拍摄时间：优先 EXIF 原始拍摄时间，没有时或元数据策略为清除全部时使用上传时间
+func BackfillFileTakenAt(db *gorm.DB) error {
+	total := 0
+	lastID := ""
+	for {
+		var files []struct {
+			ID         string
+			CreatedAt  time.Time
+			ExifPolicy string
+		}
+		if err := db.Model(&models.File{}).
+			Select("id, created_at, COALESCE(exif_policy, '') AS exif_policy").
+			Where("taken_day = 0 AND id > ?", lastID).
+			Order("id ASC").
+			Limit(takenAtBatchSize).
+			Scan(&files).Error; err != nil {
+			return fmt.Errorf("读取文件失败: %w", err)
+		}
+		if len(files) == 0 {
+			break
+		}
+
+		ids := make([]string, 0, len(files))
+		for _, f := range files {
+			if f.ExifPolicy != models.ExifPolicyStripAll {
+				ids = append(ids, f.ID)
+			}
+		}
+		var exifs []models.FileEXIF
+		if err := db.Select("file_id, date_time_original").
+			Where("file_id IN ? AND date_time_original IS NOT NULL", ids).
+			Find(&exifs).Error; err != nil {
+			return fmt.Errorf("读取EXIF失败: %w", err)
+		}
+		exifTimes := make(map[string]time.Time, len(exifs))
+		for _, e := range exifs {
+			exifTimes[e.FileID] = models.TakenAtFromEXIF(*e.DateTimeOriginal)
+		}
+
+		for _, f := range files {
+			takenAt, ok := exifTimes[f.ID]
+			if !ok {
+				takenAt = f.CreatedAt.In(time.Local)
+			}
+			if err := db.Model(&models.File{}).Where("id = ?", f.ID).Updates(map[string]interface{}{
+				"taken_at":  takenAt,
+				"taken_day": models.TakenDayOf(takenAt),
+			}).Error; err != nil {
+				return fmt.Errorf("更新文件 %s 失败: %w", f.ID, err)
+			}
+		}
+		total += len(files)
+		lastID = files[len(files)-1].ID
+	}
+
+	if total > 0 {
+		logger.Info("已为 %d 个文件填充拍摄时间", total)
+	}
+	return nil
+}
@@ -73,6 +73,8 @@ var DefaultSettings = struct {
 		AllowedFileFormats: []string{
 			"jpg", "jpeg", "png", "gif", "webp", "bmp", "svg", "ico",
 			"apng", "jp2", "tiff", "tif", "tga", "heic", "heif",
+			"mp4", "m4v", "mov", "webm",
+			"pdf", "docx", "xlsx", "pptx", "odt", "ods", "odp", "txt", "md", "csv",
 		},
 		MaxFileSize:                 20,
 		MaxBatchSize:                100,
@@ -94,6 +96,7 @@ var DefaultSettings = struct {
 		AIAnalysisEnabled:           true,
 		UserAllowedStorageDurations: []string{"1h", "3d", "7d", "30d", "permanent"},
 		UserDefaultStorageDuration:  "permanent",
+		WatermarkBurnOnUpload:       false,
 	},
 
 	Theme: ThemeSettings{
@@ -118,6 +121,9 @@ var DefaultSettings = struct {
 		IPBlacklist:           "",
 		DomainWhitelist:       "",
 		DomainBlacklist:       "",
+		SignedURLMaxExpire:    7 * 86400,
+		EnforceAdmin2FA:       false,
+		AccessTokenMinutes:    30,
 	},
 
 	Vector: VectorSettings{
@@ -250,6 +256,7 @@ type UploadSettings struct {
 	AIAnalysisEnabled           bool
 	UserAllowedStorageDurations []string
 	UserDefaultStorageDuration  string
+	WatermarkBurnOnUpload       bool
 }
 
 // ThemeSettings 网站装修设置
@@ -277,6 +284,9 @@ type SecuritySettings struct {
 	IPBlacklist           string
 	DomainWhitelist       string
 	DomainBlacklist       string
+	SignedURLMaxExpire    int
+	EnforceAdmin2FA       bool
+	AccessTokenMinutes    int
 }
 
 // VectorSettings 向量搜索设置
@@ -0,0 +1,90 @@
+package migrations
+
+import (
+	"fmt"
+	"pixelpunk/internal/models"
+	"pixelpunk/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// legacyIdentityColumns 旧版用户表中按提供方单独存放的第三方ID列
+var legacyIdentityColumns = []struct {
+	column   string
+	index    string
+	provider string
+}{
+	{"github_id", "idx_user_github_id", models.IdentityProviderGithub},
+	{"google_id", "idx_user_google_id", models.IdentityProviderGoogle},
+	{"linuxdo_id", "idx_user_linuxdo_id", models.IdentityProviderLinuxdo},
+}
+
+// MigrateUserIdentities 将 user 表的 github_id/google_id/linuxdo_id 迁移到 user_identity 表并删除旧列
+func MigrateUserIdentities(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&models.UserIdentity{}) {
+		if err := db.AutoMigrate(&models.UserIdentity{}); err != nil {
+			return err
+		}
+	}
+
+	for _, legacy := range legacyIdentityColumns {
+		if !db.Migrator().HasColumn(&models.User{}, legacy.column) {
+			continue
+		}
+
+		// PostgreSQL 的 CHAR(n) 会用空格补齐长度，改用 VARCHAR；MySQL 的 CAST 不支持 VARCHAR
+		castType := "CHAR(64)"
+		if db.Dialector.Name() == "postgres" {
+			castType = "VARCHAR(64)"
+		}
+
+		var rows []struct {
+			ID      uint
+			Subject string
+			Email   string
+		}
+		err := db.Table(models.User{}.TableName()).
+			Select(fmt.Sprintf("id, CAST(%s AS %s) AS subject, email", legacy.column, castType)).
+			Where(fmt.Sprintf("%s IS NOT NULL", legacy.column)).
+			Scan(&rows).Error
+		if err != nil {
+			return fmt.Errorf("读取 %s 失败: %w", legacy.column, err)
+		}
+
+		for _, row := range rows {
+			if row.Subject == "" {
+				continue
+			}
+			var count int64
+			db.Model(&models.UserIdentity{}).
+				Where("provider = ? AND issuer = ? AND subject = ?", legacy.provider, "", row.Subject).
+				Count(&count)
+			if count > 0 {
+				continue
+			}
+			identity := models.UserIdentity{
+				UserID:   row.ID,
+				Provider: legacy.provider,
+				Subject:  row.Subject,
+				Email:    row.Email,
+			}
+			if err := db.Create(&identity).Error; err != nil {
+				return fmt.Errorf("迁移用户 %d 的 %s 失败: %w", row.ID, legacy.provider, err)
+			}
+		}
+		logger.Info("已迁移 %d 个 %s 绑定到 user_identity", len(rows), legacy.provider)
+
+		if db.Migrator().HasIndex(&models.User{}, legacy.index) {
+			if err := db.Migrator().DropIndex(&models.User{}, legacy.index); err != nil {
+				logger.Warn("删除索引 %s 失败: %v", legacy.index, err)
+			}
+		}
+		// 模型中已无对应字段，直接执行 ALTER TABLE（MySQL 与 SQLite 3.35+ 均支持）
+		if err := db.Exec("ALTER TABLE ? DROP COLUMN ?", clause.Table{Name: models.User{}.TableName()}, clause.Column{Name: legacy.column}).Error; err != nil {
+			logger.Warn("删除旧列 %s 失败（数据已迁移，可手动删除）: %v", legacy.column, err)
+		}
+	}
+
+	return nil
+}
@@ -3,29 +3,17 @@ package migrations
 import (
 	"time"
 
+	"pixelpunk/pkg/migrate"
+
 	"gorm.io/gorm"
 )
 
 // MigrationRecord 表示一条迁移记录
-type MigrationRecord struct {
-	ID        uint      `gorm:"primaryKey"`
-	Name      string    `gorm:"type:varchar(255);not null;uniqueIndex"`
-	AppliedAt time.Time `gorm:"not null"`
-}
-
-// TableName 设置迁移记录表名
-func (MigrationRecord) TableName() string {
-	return "migration_versions"
-}
+type MigrationRecord = migrate.Record
 
 // EnsureMigrationTable 确保迁移版本表存在
 func EnsureMigrationTable(db *gorm.DB) error {
-	if !db.Migrator().HasTable(&MigrationRecord{}) {
-		if err := db.Migrator().CreateTable(&MigrationRecord{}); err != nil {
-			return err
-		}
-	}
-	return nil
+	return migrate.EnsureTable(db)
 }
 
 // IsMigrationApplied 检查指定名称的迁移是否已应用
@@ -34,12 +34,14 @@ func (c *DynamicAIClient) getConfigFromDB() (*Config, error) {
 
 	var settings []SettingRow
 	if err := db.Table("setting").
-		Where("`group` = ?", "ai").
-		Where("`key` IN (?)", []string{
-			"ai_enabled", "ai_provider", "ai_api_key", "ai_proxy",
-			"ai_model", "ai_max_tokens", "ai_temperature", "ai_timeout",
+		Where(map[string]interface{}{
+			"group": "ai",
+			"key": []string{
+				"ai_enabled", "ai_provider", "ai_api_key", "ai_proxy",
+				"ai_model", "ai_max_tokens", "ai_temperature", "ai_timeout",
+			},
 		}).
-		Select("`key`, `value`, `type`").
+		Select("key", "value", "type").
 		Find(&settings).Error; err != nil {
 		return nil, fmt.Errorf("查询AI配置失败: %v", err)
 	}
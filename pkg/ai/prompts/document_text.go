@@ -0,0 +1,21 @@
+package prompts
+
+import "fmt"
+
+// documentTextNote 文档以首页预览提交时附加的说明，正文以提取的文本为准
+const documentTextNote = "📄 **文档分析说明**：下面的图片是文档「%s」%s的首页预览，提示词末尾附有从文档中提取的正文节选。" +
+	"请以正文内容为主分析这份文档：description 写成 2-4 句的文档摘要，概括主题、要点和用途；标签和关键词描述文档的主题与类型，" +
+	"不要把文档描述为截图、白纸或文字图片。"
+
+// WithDocumentText 在分析提示词前加上文档说明，并在末尾附上正文节选
+func WithDocumentText(prompt, name string, pages int, excerpt string) string {
+	pageInfo := ""
+	if pages > 0 {
+		pageInfo = fmt.Sprintf("（共 %d 页）", pages)
+	}
+	note := fmt.Sprintf(documentTextNote, name, pageInfo)
+	if excerpt == "" {
+		return note + "\n\n" + prompt + "\n\n（未能从文档中提取到正文，请根据首页预览分析）"
+	}
+	return note + "\n\n" + prompt + "\n\n--- 文档正文节选 ---\n" + excerpt + "\n--- 节选结束 ---"
+}
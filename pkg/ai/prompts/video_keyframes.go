@@ -0,0 +1,10 @@
+package prompts
+
+// videoKeyFramesNote 视频以关键帧拼图提交时附加的说明，避免被当作拼贴画或多张图片描述
+const videoKeyFramesNote = "🎬 **视频分析说明**：下面的图片是从同一段视频中均匀截取的关键帧，按从左到右、从上到下的时间顺序拼接而成。" +
+	"请把它们作为一段视频整体分析：描述视频的场景、主体和发生的事情，标签描述视频内容，不要把画面描述为拼图、网格或多张照片。"
+
+// WithVideoKeyFrames 在分析提示词前加上视频关键帧说明
+func WithVideoKeyFrames(prompt string) string {
+	return videoKeyFramesNote + "\n\n" + prompt
+}
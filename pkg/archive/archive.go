@@ -0,0 +1,224 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+var (
+	// ErrUnsupported 不是支持的压缩格式
+	ErrUnsupported = errors.New("仅支持 zip、tar、tar.gz 格式的压缩包")
+	// ErrTooLarge 解压后的总大小超过限制
+	ErrTooLarge = errors.New("解压后的文件总大小超过限制")
+	// ErrTooManyEntries 文件数量超过限制
+	ErrTooManyEntries = errors.New("压缩包中的文件数量超过限制")
+)
+
+// Limits 解压限制，防止压缩炸弹；零值表示不限制
+type Limits struct {
+	MaxTotalSize int64
+	MaxEntries   int
+}
+
+// IsArchive 根据文件名判断是否为支持的压缩包
+func IsArchive(name string) bool {
+	return formatOf(name) != ""
+}
+
+func formatOf(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return "zip"
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return "tgz"
+	case strings.HasSuffix(lower, ".tar"):
+		return "tar"
+	}
+	return ""
+}
+
+// Extract 将压缩包解压到 dst，保留文件的修改时间，返回解压出的文件数
+// 只解压普通文件和目录，符号链接、设备文件等被忽略；路径越出 dst 的条目会导致整体失败
+func Extract(src, dst string, limits Limits) (int, error) {
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return 0, err
+	}
+	x := &extractor{dst: dst, limits: limits}
+	switch formatOf(src) {
+	case "zip":
+		err := x.zip(src)
+		return x.count, err
+	case "tar":
+		f, err := os.Open(src)
+		if err != nil {
+			return 0, err
+		}
+		defer f.Close()
+		err = x.tar(f)
+		return x.count, err
+	case "tgz":
+		f, err := os.Open(src)
+		if err != nil {
+			return 0, err
+		}
+		defer f.Close()
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return 0, fmt.Errorf("读取 gzip 失败: %w", err)
+		}
+		defer gz.Close()
+		err = x.tar(gz)
+		return x.count, err
+	}
+	return 0, ErrUnsupported
+}
+
+type extractor struct {
+	dst    string
+	limits Limits
+	count  int
+	total  int64
+}
+
+func (x *extractor) zip(src string) error {
+	r, err := zip.OpenReader(src)
+	if err != nil {
+		return fmt.Errorf("读取 zip 失败: %w", err)
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		name := zipEntryName(f)
+		mode := f.Mode()
+		if mode.IsDir() {
+			if _, err := x.mkdir(name); err != nil {
+				return err
+			}
+			continue
+		}
+		if !mode.IsRegular() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("读取 %s 失败: %w", name, err)
+		}
+		err = x.write(name, rc, f.Modified)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (x *extractor) tar(r io.Reader) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("读取 tar 失败: %w", err)
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if _, err := x.mkdir(hdr.Name); err != nil {
+				return err
+			}
+		case tar.TypeReg, tar.TypeRegA:
+			if err := x.write(hdr.Name, tr, hdr.ModTime); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// target 将条目名转换为 dst 下的路径，拒绝绝对路径和 ".." 越界
+func (x *extractor) target(name string) (string, error) {
+	name = strings.ReplaceAll(name, "\\", "/")
+	if strings.HasPrefix(name, "/") || (len(name) > 1 && name[1] == ':') {
+		return "", fmt.Errorf("压缩包包含非法路径: %s", name)
+	}
+	for _, seg := range strings.Split(name, "/") {
+		if seg == ".." {
+			return "", fmt.Errorf("压缩包包含非法路径: %s", name)
+		}
+	}
+	rel := path.Clean(name)
+	if rel == "." {
+		return "", nil
+	}
+	return filepath.Join(x.dst, filepath.FromSlash(rel)), nil
+}
+
+func (x *extractor) mkdir(name string) (string, error) {
+	p, err := x.target(name)
+	if err != nil || p == "" {
+		return p, err
+	}
+	return p, os.MkdirAll(p, 0755)
+}
+
+func (x *extractor) write(name string, r io.Reader, modTime time.Time) error {
+	p, err := x.target(name)
+	if err != nil {
+		return err
+	}
+	if p == "" {
+		return nil
+	}
+	x.count++
+	if x.limits.MaxEntries > 0 && x.count > x.limits.MaxEntries {
+		return ErrTooManyEntries
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(p, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	var src io.Reader = r
+	if x.limits.MaxTotalSize > 0 {
+		src = io.LimitReader(r, x.limits.MaxTotalSize-x.total+1)
+	}
+	n, err := io.Copy(out, src)
+	out.Close()
+	if err != nil {
+		return fmt.Errorf("解压 %s 失败: %w", name, err)
+	}
+	x.total += n
+	if x.limits.MaxTotalSize > 0 && x.total > x.limits.MaxTotalSize {
+		return ErrTooLarge
+	}
+	if !modTime.IsZero() {
+		_ = os.Chtimes(p, modTime, modTime)
+	}
+	return nil
+}
+
+// zipEntryName 不是合法 UTF-8 的文件名按 GBK 解码（Windows 中文系统创建的压缩包）
+func zipEntryName(f *zip.File) string {
+	if !utf8.ValidString(f.Name) {
+		if decoded, err := simplifiedchinese.GB18030.NewDecoder().String(f.Name); err == nil && utf8.ValidString(decoded) {
+			return decoded
+		}
+	}
+	return f.Name
+}
@@ -0,0 +1,127 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type entry struct {
+	name string
+	body string
+	dir  bool
+}
+
+var mtime = time.Date(2019, 5, 1, 8, 30, 0, 0, time.UTC)
+
+func writeZip(t *testing.T, path string, entries []entry) {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, e := range entries {
+		name := e.name
+		if e.dir {
+			name += "/"
+		}
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: mtime})
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(e.body))
+	}
+	zw.Close()
+	os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+func writeTarGz(t *testing.T, path string, entries []entry) {
+	t.Helper()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0644, Size: int64(len(e.body)), ModTime: mtime, Typeflag: tar.TypeReg}
+		if e.dir {
+			hdr = &tar.Header{Name: e.name + "/", Mode: 0755, ModTime: mtime, Typeflag: tar.TypeDir}
+		}
+		tw.WriteHeader(hdr)
+		tw.Write([]byte(e.body))
+	}
+	tw.WriteHeader(&tar.Header{Name: "link.jpg", Linkname: "/etc/passwd", Typeflag: tar.TypeSymlink})
+	tw.Close()
+	gw.Close()
+	os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+func TestExtract(t *testing.T) {
+	entries := []entry{
+		{name: "2019", dir: true},
+		{name: "2019/春游/a.jpg", body: "jpeg"},
+		{name: "b.png", body: "png"},
+	}
+	for _, name := range []string{"photos.zip", "photos.tar.gz"} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			src := filepath.Join(dir, name)
+			if name == "photos.zip" {
+				writeZip(t, src, entries)
+			} else {
+				writeTarGz(t, src, entries)
+			}
+
+			dst := filepath.Join(dir, "out")
+			n, err := Extract(src, dst, Limits{})
+			if err != nil {
+				t.Fatalf("Extract: %v", err)
+			}
+			if n != 2 {
+				t.Errorf("extracted %d files, want 2", n)
+			}
+			info, err := os.Stat(filepath.Join(dst, "2019", "春游", "a.jpg"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !info.ModTime().Equal(mtime) {
+				t.Errorf("mtime = %v, want %v", info.ModTime(), mtime)
+			}
+			if _, err := os.Lstat(filepath.Join(dst, "link.jpg")); !os.IsNotExist(err) {
+				t.Errorf("symlink should be skipped")
+			}
+		})
+	}
+}
+
+func TestExtractRejectsTraversal(t *testing.T) {
+	for _, name := range []string{"../evil.jpg", "a/../../evil.jpg", "/etc/evil.jpg", `..\evil.jpg`} {
+		dir := t.TempDir()
+		src := filepath.Join(dir, "evil.zip")
+		writeZip(t, src, []entry{{name: name, body: "x"}})
+		if _, err := Extract(src, filepath.Join(dir, "out"), Limits{}); err == nil {
+			t.Errorf("Extract(%q) succeeded, want error", name)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "evil.jpg")); !os.IsNotExist(err) {
+			t.Errorf("Extract(%q) wrote outside destination", name)
+		}
+	}
+}
+
+func TestExtractLimits(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "big.zip")
+	writeZip(t, src, []entry{{name: "a.jpg", body: string(make([]byte, 4096))}, {name: "b.jpg", body: "x"}})
+
+	if _, err := Extract(src, filepath.Join(dir, "o1"), Limits{MaxTotalSize: 1024}); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("size limit: got %v, want ErrTooLarge", err)
+	}
+	if _, err := Extract(src, filepath.Join(dir, "o2"), Limits{MaxEntries: 1}); !errors.Is(err, ErrTooManyEntries) {
+		t.Errorf("entry limit: got %v, want ErrTooManyEntries", err)
+	}
+	if _, err := Extract(filepath.Join(dir, "a.rar"), filepath.Join(dir, "o3"), Limits{}); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("rar: got %v, want ErrUnsupported", err)
+	}
+}
@@ -83,6 +83,13 @@ const (
 const (
 	ShareItemTypeFolder = "folder"
 	ShareItemTypeFile   = "file"
+	ShareItemTypeAlbum  = "album"
+)
+
+const (
+	ShareEventView     = "view"      // 打开分享页
+	ShareEventFileView = "file_view" // 通过分享查看文件原图
+	ShareEventDownload = "download"  // 通过分享下载文件
 )
 
 const (
@@ -104,6 +111,8 @@ const (
 	MessageTypeAccountRegister         = "account.register"
 	MessageTypeAccountStorageGranted   = "account.storage_granted"
 	MessageTypeAccountBandwidthGranted = "account.bandwidth_granted"
+	MessageTypeAccountDataExportReady  = "account.data_export_ready"
+	MessageTypeAccountDataExportFailed = "account.data_export_failed"
 
 	MessageTypeContentReviewPending  = "content.review_pending"
 	MessageTypeContentReviewApproved = "content.review_approved"
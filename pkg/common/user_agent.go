@@ -0,0 +1,105 @@
+package common
+
+import "strings"
+
+// UserAgentInfo 从User-Agent解析出的客户端族信息
+type UserAgentInfo struct {
+	Browser    string `json:"browser"`
+	OS         string `json:"os"`
+	DeviceType string `json:"device_type"` // desktop/mobile/tablet/bot/other
+}
+
+const (
+	DeviceTypeDesktop = "desktop"
+	DeviceTypeMobile  = "mobile"
+	DeviceTypeTablet  = "tablet"
+	DeviceTypeBot     = "bot"
+	DeviceTypeOther   = "other"
+)
+
+type uaPattern struct {
+	token string
+	name  string
+}
+
+// 匹配顺序很重要：Edge/Opera/微信等基于Chromium的浏览器UA中同样包含Chrome与Safari
+var browserPatterns = []uaPattern{
+	{"micromessenger", "WeChat"},
+	{"qq/", "QQ"},
+	{"edg/", "Edge"},
+	{"edge/", "Edge"},
+	{"opr/", "Opera"},
+	{"opera", "Opera"},
+	{"samsungbrowser", "Samsung Internet"},
+	{"ucbrowser", "UC Browser"},
+	{"yabrowser", "Yandex"},
+	{"firefox/", "Firefox"},
+	{"fxios", "Firefox"},
+	{"crios", "Chrome"},
+	{"chrome/", "Chrome"},
+	{"safari/", "Safari"},
+	{"msie", "Internet Explorer"},
+	{"trident/", "Internet Explorer"},
+	{"postmanruntime", "Postman"},
+	{"curl/", "Curl"},
+	{"wget/", "Wget"},
+	{"python-requests", "Python"},
+	{"go-http-client", "Go"},
+}
+
+var osPatterns = []uaPattern{
+	{"windows nt", "Windows"},
+	{"iphone", "iOS"},
+	{"ipad", "iPadOS"},
+	{"android", "Android"},
+	{"mac os x", "macOS"},
+	{"cros", "ChromeOS"},
+	{"linux", "Linux"},
+}
+
+var botTokens = []string{"bot", "spider", "crawler", "slurp", "facebookexternalhit", "preview"}
+
+// ParseUserAgent 解析User-Agent得到浏览器、操作系统和设备类型
+func ParseUserAgent(ua string) UserAgentInfo {
+	info := UserAgentInfo{Browser: "Unknown", OS: "Unknown", DeviceType: DeviceTypeOther}
+	lower := strings.ToLower(strings.TrimSpace(ua))
+	if lower == "" {
+		return info
+	}
+
+	for _, p := range browserPatterns {
+		if strings.Contains(lower, p.token) {
+			info.Browser = p.name
+			break
+		}
+	}
+
+	for _, p := range osPatterns {
+		if strings.Contains(lower, p.token) {
+			info.OS = p.name
+			break
+		}
+	}
+
+	for _, token := range botTokens {
+		if strings.Contains(lower, token) {
+			info.DeviceType = DeviceTypeBot
+			if info.Browser == "Unknown" {
+				info.Browser = "Bot"
+			}
+			return info
+		}
+	}
+
+	switch {
+	case strings.Contains(lower, "ipad") || strings.Contains(lower, "tablet") ||
+		(strings.Contains(lower, "android") && !strings.Contains(lower, "mobile")):
+		info.DeviceType = DeviceTypeTablet
+	case strings.Contains(lower, "mobile") || strings.Contains(lower, "iphone"):
+		info.DeviceType = DeviceTypeMobile
+	case info.OS == "Windows" || info.OS == "macOS" || info.OS == "Linux" || info.OS == "ChromeOS":
+		info.DeviceType = DeviceTypeDesktop
+	}
+
+	return info
+}
@@ -0,0 +1,56 @@
+package common
+
+import "testing"
+
+// TestParseUserAgent 验证常见浏览器UA的族识别顺序
+func TestParseUserAgent(t *testing.T) {
+	tests := []struct {
+		name   string
+		ua     string
+		expect UserAgentInfo
+	}{
+		{
+			name:   "chrome-windows",
+			ua:     "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+			expect: UserAgentInfo{Browser: "Chrome", OS: "Windows", DeviceType: DeviceTypeDesktop},
+		},
+		{
+			name:   "edge-is-not-chrome",
+			ua:     "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.0.0",
+			expect: UserAgentInfo{Browser: "Edge", OS: "Windows", DeviceType: DeviceTypeDesktop},
+		},
+		{
+			name:   "safari-iphone",
+			ua:     "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1",
+			expect: UserAgentInfo{Browser: "Safari", OS: "iOS", DeviceType: DeviceTypeMobile},
+		},
+		{
+			name:   "wechat-android",
+			ua:     "Mozilla/5.0 (Linux; Android 13; V2185A) AppleWebKit/537.36 Chrome/107.0 Mobile Safari/537.36 MicroMessenger/8.0.40",
+			expect: UserAgentInfo{Browser: "WeChat", OS: "Android", DeviceType: DeviceTypeMobile},
+		},
+		{
+			name:   "android-tablet",
+			ua:     "Mozilla/5.0 (Linux; Android 12; SM-X700) AppleWebKit/537.36 Chrome/110.0 Safari/537.36",
+			expect: UserAgentInfo{Browser: "Chrome", OS: "Android", DeviceType: DeviceTypeTablet},
+		},
+		{
+			name:   "googlebot",
+			ua:     "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			expect: UserAgentInfo{Browser: "Bot", OS: "Unknown", DeviceType: DeviceTypeBot},
+		},
+		{
+			name:   "empty",
+			ua:     "",
+			expect: UserAgentInfo{Browser: "Unknown", OS: "Unknown", DeviceType: DeviceTypeOther},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseUserAgent(tt.ua); got != tt.expect {
+				t.Errorf("ParseUserAgent() = %+v, want %+v", got, tt.expect)
+			}
+		})
+	}
+}
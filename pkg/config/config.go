@@ -23,6 +23,12 @@ type Config struct {
 	Redis    RedisConfig    `yaml:"redis" env:"REDIS"`
 	Upload   UploadConfig   `yaml:"upload" env:"UPLOAD"`
 	Vector   VectorConfig   `yaml:"vector" env:"VECTOR"`
+	GeoIP    GeoIPConfig    `yaml:"geoip" env:"GEOIP"`
+	Video    VideoConfig    `yaml:"video" env:"VIDEO"`
+	Document DocumentConfig `yaml:"document" env:"DOCUMENT"`
+	Import   ImportConfig   `yaml:"import" env:"IMPORT"`
+	Export   ExportConfig   `yaml:"export" env:"EXPORT"`
+	Backup   BackupConfig   `yaml:"backup" env:"BACKUP"`
 }
 
 // 更新服务配置已移除
@@ -31,18 +37,23 @@ type Config struct {
 type AppConfig struct {
 	Port      int    `yaml:"port" env:"PORT"`
 	Mode      string `yaml:"mode" env:"MODE"`
-	Namespace string `yaml:"ns" env:"NS"` // 命名空间，用于缓存隔离，默认: pixelpunk
+	Namespace string `yaml:"ns" env:"NS"`     // 命名空间，用于缓存隔离，默认: pixelpunk
+	Role      string `yaml:"role" env:"ROLE"` // 进程角色: api/worker/cron/all，可用逗号组合，默认: all
+	// 其他 api 进程访问本进程的内部地址，如 http://10.0.0.5:9520，用于转发只保存在本进程的导出文件下载
+	AdvertiseURL string `yaml:"advertise_url" env:"ADVERTISE_URL"`
 }
 
 // DatabaseConfig 数据库配置
 type DatabaseConfig struct {
-	Type     string `yaml:"type" env:"TYPE"` // 数据库类型: mysql/sqlite
+	Type     string `yaml:"type" env:"TYPE"` // 数据库类型: mysql/sqlite/postgres
 	Host     string `yaml:"host" env:"HOST"`
 	Port     int    `yaml:"port" env:"PORT"`
 	Username string `yaml:"username" env:"USERNAME"`
 	Password string `yaml:"password" env:"PASSWORD"`
 	Name     string `yaml:"name" env:"NAME"`
 	Path     string `yaml:"path" env:"PATH"` // SQLite数据库文件路径
+	// 启动时自动同步表结构并执行数据迁移；生产环境可关闭，改为用 pixelpunk migrate 预览后手动执行
+	AutoMigrate bool `yaml:"auto_migrate" env:"AUTO_MIGRATE"`
 }
 
 // RedisConfig Redis配置
@@ -69,6 +80,46 @@ type VectorConfig struct {
 	OpenAIModel   string `yaml:"openai_model" env:"OPENAI_MODEL"`       // 向量化模型
 }
 
+// GeoIPConfig 离线IP地理位置库配置
+type GeoIPConfig struct {
+	DBPath string `yaml:"db_path" env:"DB_PATH"` // MaxMind格式(.mmdb)的国家/城市数据库文件路径
+}
+
+// VideoConfig 视频处理配置
+type VideoConfig struct {
+	FFmpegPath string `yaml:"ffmpeg_path" env:"FFMPEG_PATH"` // ffmpeg 可执行文件路径，找不到时不截取视频帧
+}
+
+// DocumentConfig 文档预览配置
+type DocumentConfig struct {
+	PdftoppmPath string `yaml:"pdftoppm_path" env:"PDFTOPPM_PATH"` // poppler 的 pdftoppm 路径，用于渲染 PDF 首页
+	SofficePath  string `yaml:"soffice_path" env:"SOFFICE_PATH"`   // LibreOffice 路径，为空时 Office 文档不渲染首页
+}
+
+// ImportConfig 服务器端批量导入配置
+type ImportConfig struct {
+	AllowedDirs    string `yaml:"allowed_dirs" env:"ALLOWED_DIRS"`         // 允许导入的服务器目录，多个用逗号分隔，为空时禁止导入服务器目录
+	StagingDir     string `yaml:"staging_dir" env:"STAGING_DIR"`           // 上传的压缩包及其解压文件的暂存目录
+	MaxArchiveSize int64  `yaml:"max_archive_size" env:"MAX_ARCHIVE_SIZE"` // 上传压缩包的大小上限（MB）
+	MaxExtractSize int64  `yaml:"max_extract_size" env:"MAX_EXTRACT_SIZE"` // 压缩包解压后的总大小上限（MB）
+}
+
+// ExportConfig 用户数据导出配置
+type ExportConfig struct {
+	Dir            string `yaml:"dir" env:"DIR"`                         // 导出压缩包的保存目录
+	RetentionHours int    `yaml:"retention_hours" env:"RETENTION_HOURS"` // 导出完成后可下载的时长（小时），过期后删除
+}
+
+// BackupConfig 实例备份配置
+type BackupConfig struct {
+	Dir         string `yaml:"dir" env:"DIR"`                 // 定时备份的保存目录
+	Schedule    string `yaml:"schedule" env:"SCHEDULE"`       // 定时备份的 cron 表达式（含秒），为空时不启用定时备份
+	Keep        int    `yaml:"keep" env:"KEEP"`               // 保留的备份链数量，一条链为一个全量备份及其后的增量备份
+	Incremental bool   `yaml:"incremental" env:"INCREMENTAL"` // 定时备份是否对存储文件做增量备份
+	FullEvery   int    `yaml:"full_every" env:"FULL_EVERY"`   // 每条备份链最多包含的增量备份数，达到后重新做全量备份
+	SkipVector  bool   `yaml:"skip_vector" env:"SKIP_VECTOR"` // 不备份向量索引，恢复后需要重新生成向量
+}
+
 var (
 	config Config
 	once   sync.Once
@@ -79,13 +130,33 @@ func setDefaultConfig(cfg *Config) {
 	cfg.App.Port = 9520
 	cfg.App.Mode = "debug"
 	cfg.App.Namespace = "pixelpunk"
+	cfg.App.Role = "all"
 
 	// 数据库默认配置（用于安装模式）
 	cfg.Database.Port = 3306
+	cfg.Database.AutoMigrate = true
 
 	cfg.Redis.Host = "localhost"
 	cfg.Redis.Port = 6379
 	cfg.Redis.DB = 0
+
+	cfg.GeoIP.DBPath = "data/GeoLite2-Country.mmdb"
+
+	cfg.Video.FFmpegPath = "ffmpeg"
+
+	cfg.Document.PdftoppmPath = "pdftoppm"
+
+	cfg.Import.StagingDir = "data/imports"
+	cfg.Import.MaxArchiveSize = 4096
+	cfg.Import.MaxExtractSize = 16384
+
+	cfg.Export.Dir = "data/exports"
+	cfg.Export.RetentionHours = 72
+
+	cfg.Backup.Dir = "data/backups"
+	cfg.Backup.Keep = 3
+	cfg.Backup.Incremental = true
+	cfg.Backup.FullEvery = 6
 }
 
 // InitConfig 初始化配置
@@ -162,6 +233,24 @@ func loadConfigFromEnv(cfg *Config) {
 	// 处理Vector配置的环境变量
 	loadEnvToStruct(envPrefix+"VECTOR_", &cfg.Vector)
 
+	// 处理GeoIP配置的环境变量
+	loadEnvToStruct(envPrefix+"GEOIP_", &cfg.GeoIP)
+
+	// 处理Video配置的环境变量
+	loadEnvToStruct(envPrefix+"VIDEO_", &cfg.Video)
+
+	// 处理Document配置的环境变量
+	loadEnvToStruct(envPrefix+"DOCUMENT_", &cfg.Document)
+
+	// 处理Import配置的环境变量
+	loadEnvToStruct(envPrefix+"IMPORT_", &cfg.Import)
+
+	// 处理Export配置的环境变量
+	loadEnvToStruct(envPrefix+"EXPORT_", &cfg.Export)
+
+	// 处理Backup配置的环境变量
+	loadEnvToStruct(envPrefix+"BACKUP_", &cfg.Backup)
+
 }
 
 // loadEnvToStruct 加载环境变量到结构体
@@ -274,6 +363,14 @@ func GetUploadConfig() *UploadConfig {
 	if len(config.Upload.AllowedTypes) == 0 {
 		config.Upload.AllowedTypes = []string{
 			"image/jpeg", "image/jpg", "image/png", "image/gif", "image/webp", "image/bmp",
+			"video/mp4", "video/webm", "video/quicktime",
+			"application/pdf", "text/plain", "text/markdown", "text/csv",
+			"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+			"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+			"application/vnd.openxmlformats-officedocument.presentationml.presentation",
+			"application/vnd.oasis.opendocument.text",
+			"application/vnd.oasis.opendocument.spreadsheet",
+			"application/vnd.oasis.opendocument.presentation",
 		}
 	}
 
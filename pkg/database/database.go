@@ -2,16 +2,20 @@ package database
 
 import (
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
 	"pixelpunk/internal/models"
 	"pixelpunk/pkg/common"
 	"pixelpunk/pkg/config"
 	log "pixelpunk/pkg/logger"
+	"strconv"
 	"strings"
 	"time"
 
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -46,6 +50,18 @@ func getDialector(dbType, host, username, password, name, path string, port int)
 		dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Asia%%2FShanghai&sql_mode=%%27STRICT_TRANS_TABLES,NO_ZERO_DATE,NO_ZERO_IN_DATE,ERROR_FOR_DIVISION_BY_ZERO,NO_ENGINE_SUBSTITUTION%%27",
 			username, password, host, port, name)
 		return mysql.Open(dsn), nil
+	case "postgres":
+		if port == 0 {
+			port = 5432
+		}
+		dsn := (&url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(username, password),
+			Host:     net.JoinHostPort(host, strconv.Itoa(port)),
+			Path:     "/" + name,
+			RawQuery: "TimeZone=Asia/Shanghai",
+		}).String()
+		return postgres.Open(dsn), nil
 	case "sqlite":
 		dbPath := path
 		if !filepath.IsAbs(dbPath) {
@@ -114,14 +130,14 @@ func InitDB() {
 
 	// 根据数据库类型检查必要配置
 	switch cfg.Type {
-	case "mysql":
+	case "mysql", "postgres":
 		if cfg.Host == "" || cfg.Username == "" || cfg.Name == "" {
 			if configExists {
-				log.Warn("配置文件存在但MySQL配置不完整，请检查配置文件")
+				log.Warn("配置文件存在但%s配置不完整，请检查配置文件", cfg.Type)
 				log.Warn("如需重新配置，请删除 configs/config.yaml 后重启应用")
 				return
 			} else {
-				log.Info("MySQL数据库配置不完整，进入安装模式")
+				log.Info("%s数据库配置不完整，进入安装模式", cfg.Type)
 				installManager.SetInstallMode(true)
 				installManager.SetSystemInstalled(false)
 				return
@@ -209,7 +225,7 @@ func InitDB() {
 		}
 	}
 
-	if cfg.Type == "mysql" {
+	if cfg.Type == "mysql" || cfg.Type == "postgres" {
 		sqlDB, err := DB.DB()
 		if err == nil {
 			sqlDB.SetMaxOpenConns(150)
@@ -219,7 +235,9 @@ func InitDB() {
 		}
 	}
 
-	if err := autoMigrate(); err != nil {
+	if !cfg.AutoMigrate || skipAutoMigrate {
+		log.Info("已跳过启动时的表结构同步，请使用 pixelpunk migrate up 升级数据库")
+	} else if err := autoMigrate(); err != nil {
 		if configExists {
 			log.Warn("数据库迁移失败: %v", err)
 			log.Warn("请检查数据库权限或表结构，如需重新配置，请删除 configs/config.yaml 后重启应用")
@@ -310,7 +328,7 @@ func ReconnectDatabase() error {
 		}
 	}
 
-	if cfg.Type == "mysql" {
+	if cfg.Type == "mysql" || cfg.Type == "postgres" {
 		sqlDB, err := DB.DB()
 		if err == nil {
 			sqlDB.SetMaxOpenConns(150)
@@ -340,8 +358,21 @@ func ReconnectDatabase() error {
 	return nil
 }
 
+// skipAutoMigrate 为 true 时连接数据库后不同步表结构
+var skipAutoMigrate bool
+
+// DisableAutoMigrate 连接数据库时不同步表结构，由 migrate 子命令自行控制同步和预览
+func DisableAutoMigrate() {
+	skipAutoMigrate = true
+}
+
 func autoMigrate() error {
-	models := []interface{}{
+	return MigrateModels(DB)
+}
+
+// Models 返回自动迁移的全部模型，数据迁移工具按同样的顺序复制数据
+func Models() []interface{} {
+	return []interface{}{
 		&models.User{},
 		&models.File{},
 		&models.FileStats{},
@@ -376,6 +407,7 @@ func autoMigrate() error {
 		&models.GuestUploadLog{},
 		&models.UserBandwidthUsage{},
 		&models.GlobalTag{},
+		&models.GlobalTagAlias{},
 		&models.UserTagReference{},
 		&models.TagCategoryRelation{},
 		&models.FileGlobalTagRelation{},
@@ -389,11 +421,34 @@ func autoMigrate() error {
 		&models.VectorJob{},
 		&models.Announcement{},
 		&models.FileEXIF{},
+		&models.SignedLink{},
+		&models.ImportJob{},
+		&models.ImportJobItem{},
+		&models.LegacyRedirect{},
+		&models.DataExport{},
+		&models.UserTwoFactor{},
+		&models.UserRecoveryCode{},
+		&models.UserSession{},
+		&models.UserIdentity{},
+		&models.AutomationRule{},
+		&models.AutomationRuleLog{},
+		&models.Album{},
+		&models.AlbumItem{},
+		&models.WatermarkProfile{},
+		&models.WatermarkPolicy{},
+		&models.ClusterNode{},
+		&models.ClusterLock{},
 	}
+}
 
-	silentDB := DB.Session(&gorm.Session{Logger: logger.Default.LogMode(logger.Silent)})
+// MigrateModels 在指定连接上自动迁移全部模型
+func MigrateModels(db *gorm.DB) error {
+	silentDB := db.Session(&gorm.Session{Logger: logger.Default.LogMode(logger.Silent)})
 
-	for _, model := range models {
+	for _, model := range Models() {
+		if err := adaptColumnTypes(silentDB, model); err != nil {
+			return err
+		}
 		if err := silentDB.AutoMigrate(model); err != nil {
 			if isIndexError(err) {
 				continue
@@ -405,6 +460,24 @@ func autoMigrate() error {
 	return nil
 }
 
+// adaptColumnTypes PostgreSQL 没有 longtext 类型，迁移前把模型中声明的 longtext 改为 text
+// 解析后的模型结构按连接缓存，AutoMigrate 使用的是同一份，MySQL 和 SQLite 的列类型不受影响
+func adaptColumnTypes(db *gorm.DB, model interface{}) error {
+	if db.Dialector.Name() != TypePostgres {
+		return nil
+	}
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return err
+	}
+	for _, field := range stmt.Schema.Fields {
+		if strings.EqualFold(string(field.DataType), "longtext") {
+			field.DataType = "text"
+		}
+	}
+	return nil
+}
+
 func isIndexError(err error) bool {
 	errorMsg := err.Error()
 	indexErrors := []string{
@@ -0,0 +1,37 @@
+package database
+
+import "strings"
+
+// 支持的数据库类型，与配置文件中 database.type 的取值一致
+const (
+	TypeMySQL    = "mysql"
+	TypeSQLite   = "sqlite"
+	TypePostgres = "postgres"
+)
+
+// Dialect 返回当前连接的数据库类型，未连接时返回空字符串
+func Dialect() string {
+	if DB == nil {
+		return ""
+	}
+	return DB.Dialector.Name()
+}
+
+// IsPostgres 当前连接是否为 PostgreSQL
+func IsPostgres() bool {
+	return Dialect() == TypePostgres
+}
+
+// IsSQLite 当前连接是否为 SQLite
+func IsSQLite() bool {
+	return Dialect() == TypeSQLite
+}
+
+// ILike 把查询条件中的 LIKE 换成不区分大小写的匹配
+// MySQL 的默认排序规则和 SQLite 的 LIKE 对 ASCII 字母本身不区分大小写，PostgreSQL 需要改用 ILIKE
+func ILike(condition string) string {
+	if IsPostgres() {
+		return strings.ReplaceAll(condition, " LIKE ", " ILIKE ")
+	}
+	return condition
+}
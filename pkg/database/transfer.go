@@ -0,0 +1,169 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// TransferProgress 复制完一批数据后的回调，rows 为该表已复制的行数
+type TransferProgress func(table string, rows, total int64)
+
+// OpenTarget 按数据库类型连接迁移目标库，用于把数据复制到另一种数据库
+func OpenTarget(dbType, host string, port int, username, password, name, path string) (*gorm.DB, error) {
+	dialector, err := getDialector(dbType, host, username, password, name, path, port)
+	if err != nil {
+		return nil, err
+	}
+	db, err := gorm.Open(dialector, getGormConfig())
+	if err != nil {
+		return nil, fmt.Errorf("连接目标数据库失败: %v", err)
+	}
+	return db, nil
+}
+
+// TransferData 把 src 中各模型对应的数据表原样复制到 dst
+// dst 需要先完成表结构迁移，且这些表必须为空；复制在一个事务中进行，失败时目标库不会留下部分数据
+func TransferData(src, dst *gorm.DB, models []interface{}, batchSize int, progress TransferProgress) error {
+	schemas := make([]*schema.Schema, len(models))
+	for i, model := range models {
+		stmt := &gorm.Statement{DB: src}
+		if err := stmt.Parse(model); err != nil {
+			return fmt.Errorf("解析模型失败: %v", err)
+		}
+		schemas[i] = stmt.Schema
+
+		var count int64
+		if err := dst.Table(stmt.Schema.Table).Count(&count).Error; err != nil {
+			return fmt.Errorf("读取目标数据表 %s 失败: %v", stmt.Schema.Table, err)
+		}
+		if count > 0 {
+			return fmt.Errorf("目标数据表 %s 不为空，请使用新建的空数据库", stmt.Schema.Table)
+		}
+	}
+
+	return dst.Transaction(func(tx *gorm.DB) error {
+		for i, model := range models {
+			if err := transferTable(src, tx, model, schemas[i], batchSize, progress); err != nil {
+				return fmt.Errorf("复制数据表 %s 失败: %v", schemas[i].Table, err)
+			}
+		}
+		return ResetSequences(tx)
+	})
+}
+
+func transferTable(src, tx *gorm.DB, model interface{}, sch *schema.Schema, batchSize int, progress TransferProgress) error {
+	reader := src.Unscoped().Model(model).Session(&gorm.Session{})
+
+	var total int64
+	if err := reader.Count(&total).Error; err != nil {
+		return err
+	}
+	if total == 0 {
+		if progress != nil {
+			progress(sch.Table, 0, 0)
+		}
+		return nil
+	}
+
+	var copied int64
+	rows := reflect.New(reflect.SliceOf(sch.ModelType))
+	write := func() error {
+		batch := rows.Elem()
+		if batch.Len() == 0 {
+			return nil
+		}
+		// 按列写入 map，不经过模型的默认值和钩子处理，零值和时间字段与源库保持一致
+		records := make([]map[string]interface{}, batch.Len())
+		for i := range records {
+			records[i] = rowValues(sch, batch.Index(i))
+		}
+		if err := tx.Table(sch.Table).Create(&records).Error; err != nil {
+			return err
+		}
+		copied += int64(len(records))
+		if progress != nil {
+			progress(sch.Table, copied, total)
+		}
+		return nil
+	}
+
+	// 没有单一主键的表无法分批，数据量都很小，一次读出
+	if sch.PrioritizedPrimaryField == nil {
+		if err := reader.Find(rows.Interface()).Error; err != nil {
+			return err
+		}
+		return write()
+	}
+	return reader.FindInBatches(rows.Interface(), batchSize, func(*gorm.DB, int) error {
+		return write()
+	}).Error
+}
+
+func rowValues(sch *schema.Schema, row reflect.Value) map[string]interface{} {
+	ctx := context.Background()
+	values := make(map[string]interface{}, len(sch.DBNames))
+	for _, name := range sch.DBNames {
+		field := sch.FieldsByDBName[name]
+		value, _ := field.ValueOf(ctx, row)
+		if strings.EqualFold(string(field.DataType), "json") {
+			value = normalizeJSON(value)
+		}
+		values[name] = value
+	}
+	return values
+}
+
+// normalizeJSON SQLite 不校验 JSON 列，空字符串写入 MySQL 和 PostgreSQL 的 json 列会失败，改为 NULL
+func normalizeJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.RawMessage:
+		if len(bytes.TrimSpace(v)) == 0 {
+			return nil
+		}
+	case *string:
+		if v == nil || strings.TrimSpace(*v) == "" {
+			return nil
+		}
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return nil
+		}
+	}
+	return value
+}
+
+// ResetSequences PostgreSQL 写入带主键值的数据后自增序列不会跟着变化，把每个序列调整到当前最大值之后
+// 复制数据和从备份恢复后都需要调用，其他数据库不需要处理
+func ResetSequences(db *gorm.DB) error {
+	if db.Dialector.Name() != TypePostgres {
+		return nil
+	}
+
+	var columns []struct {
+		Table  string `gorm:"column:table_name"`
+		Column string `gorm:"column:column_name"`
+	}
+	err := db.Raw("SELECT table_name, column_name FROM information_schema.columns " +
+		"WHERE table_schema = current_schema() AND column_default LIKE 'nextval(%'").
+		Scan(&columns).Error
+	if err != nil {
+		return fmt.Errorf("读取自增序列失败: %v", err)
+	}
+
+	for _, c := range columns {
+		err := db.Exec("SELECT setval(pg_get_serial_sequence(?, ?), COALESCE((SELECT MAX(?) FROM ?), 0) + 1, false)",
+			`"`+c.Table+`"`, c.Column, clause.Column{Name: c.Column}, clause.Table{Name: c.Table}).Error
+		if err != nil {
+			return fmt.Errorf("调整数据表 %s 的自增序列失败: %v", c.Table, err)
+		}
+	}
+	return nil
+}
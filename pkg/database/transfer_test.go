@@ -0,0 +1,76 @@
+package database
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type transferItem struct {
+	ID        uint `gorm:"primaryKey"`
+	Name      string
+	Enabled   bool            `gorm:"default:true"`
+	Meta      json.RawMessage `gorm:"type:json"`
+	DeletedAt gorm.DeletedAt
+}
+
+func openTestDB(t *testing.T, name string) *gorm.DB {
+	db, err := OpenTarget(TypeSQLite, "", 0, "", "", "", filepath.Join(t.TempDir(), name))
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	if err := db.AutoMigrate(&transferItem{}); err != nil {
+		t.Fatalf("创建数据表失败: %v", err)
+	}
+	return db
+}
+
+func TestTransferData(t *testing.T) {
+	src := openTestDB(t, "src.db")
+	dst := openTestDB(t, "dst.db")
+
+	items := []transferItem{
+		{ID: 3, Name: "a", Enabled: true, Meta: json.RawMessage(`{"k":1}`)},
+		{ID: 5, Name: "b", Enabled: true},
+		{ID: 9, Name: "c", Enabled: true},
+	}
+	if err := src.Create(&items).Error; err != nil {
+		t.Fatal(err)
+	}
+	// 零值字段和软删除记录都要原样复制
+	src.Model(&transferItem{}).Where("id = ?", 5).Update("enabled", false)
+	src.Delete(&transferItem{}, 9)
+	src.Exec("UPDATE transfer_items SET meta = '' WHERE id = 9")
+
+	var progressed int64
+	err := TransferData(src, dst, []interface{}{&transferItem{}}, 2, func(table string, rows, total int64) {
+		progressed = rows
+	})
+	if err != nil {
+		t.Fatalf("复制数据失败: %v", err)
+	}
+	if progressed != 3 {
+		t.Errorf("进度回调行数 = %d, 期望 3", progressed)
+	}
+
+	var got []transferItem
+	dst.Unscoped().Order("id").Find(&got)
+	if len(got) != 3 {
+		t.Fatalf("复制后行数 = %d, 期望 3", len(got))
+	}
+	if got[0].ID != 3 || string(got[0].Meta) != `{"k":1}` {
+		t.Errorf("第一行 = %+v", got[0])
+	}
+	if got[1].Enabled {
+		t.Error("enabled=false 被默认值覆盖")
+	}
+	if !got[2].DeletedAt.Valid || got[2].Meta != nil {
+		t.Errorf("软删除行 = %+v", got[2])
+	}
+
+	if err := TransferData(src, dst, []interface{}{&transferItem{}}, 2, nil); err == nil {
+		t.Error("目标表不为空时应当拒绝复制")
+	}
+}
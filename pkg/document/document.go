@@ -0,0 +1,151 @@
+package document
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// 文档类型细分，保存在 file_ai_info.document_type
+const (
+	TypePDF          = "pdf"
+	TypeWord         = "word"
+	TypeSpreadsheet  = "spreadsheet"
+	TypePresentation = "presentation"
+	TypeText         = "text"
+)
+
+// MaxTextBytes 提取文本的最大长度，超出部分截断
+const MaxTextBytes = 1 << 20
+
+var (
+	// ErrUnsupported 不支持的文档格式
+	ErrUnsupported = errors.New("document: unsupported format")
+	// ErrCorrupted 文档结构无法解析
+	ErrCorrupted = errors.New("document: corrupted or unreadable")
+)
+
+// Info 文档解析结果
+type Info struct {
+	Format    string // 扩展名（不带点，小写）
+	Type      string // 文档类型细分
+	PageCount int    // 页数：PDF/Word 为页数，演示文稿为幻灯片数，表格为工作表数
+	Title     string // 文档属性中的标题
+	Text      string // 提取的纯文本
+	Language  string // 按文字判断的主要语言（zh/ja/ko/ru/en），无法判断时为空
+	Encrypted bool   // 加密的 PDF 无法提取文本
+}
+
+// formatTypes 支持的扩展名及其类型
+var formatTypes = map[string]string{
+	"pdf":  TypePDF,
+	"docx": TypeWord,
+	"odt":  TypeWord,
+	"xlsx": TypeSpreadsheet,
+	"ods":  TypeSpreadsheet,
+	"pptx": TypePresentation,
+	"odp":  TypePresentation,
+	"txt":  TypeText,
+	"md":   TypeText,
+	"csv":  TypeText,
+}
+
+// TypeOf 返回扩展名对应的文档类型，不支持时返回空字符串
+func TypeOf(formatOrExt string) string {
+	return formatTypes[strings.TrimPrefix(strings.ToLower(formatOrExt), ".")]
+}
+
+// Extract 解析文档的页数、标题和文本，ext 为扩展名（可带点）
+func Extract(data []byte, ext string) (*Info, error) {
+	format := strings.TrimPrefix(strings.ToLower(ext), ".")
+	info := &Info{Format: format, Type: formatTypes[format]}
+
+	var err error
+	switch format {
+	case "pdf":
+		err = extractPDF(data, info)
+	case "docx", "xlsx", "pptx":
+		err = extractOOXML(data, info)
+	case "odt", "ods", "odp":
+		err = extractODF(data, info)
+	case "txt", "md", "csv":
+		err = extractPlainText(data, info)
+	default:
+		return nil, ErrUnsupported
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	info.Text = truncateText(normalizeText(info.Text), MaxTextBytes)
+	info.Title = strings.TrimSpace(info.Title)
+	info.Language = DetectLanguage(info.Text)
+	if info.PageCount <= 0 && info.Type != TypeSpreadsheet {
+		info.PageCount = estimatePages(info.Text)
+	}
+	return info, nil
+}
+
+// Excerpt 返回文本开头不超过 maxRunes 个字符的片段，用于提示词和向量内容
+func Excerpt(text string, maxRunes int) string {
+	if utf8.RuneCountInString(text) <= maxRunes {
+		return text
+	}
+	runes := []rune(text)
+	return strings.TrimSpace(string(runes[:maxRunes])) + "…"
+}
+
+// estimatePages 没有页数信息的文档按每页约 1800 个字符估算
+func estimatePages(text string) int {
+	n := utf8.RuneCountInString(text)
+	if n == 0 {
+		return 1
+	}
+	return int(math.Ceil(float64(n) / 1800))
+}
+
+// normalizeText 统一换行，合并行内多余空白和连续空行
+func normalizeText(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	text = strings.ToValidUTF8(text, "")
+
+	lines := strings.Split(text, "\n")
+	out := make([]string, 0, len(lines))
+	blank := 0
+	for _, line := range lines {
+		line = strings.TrimRight(strings.Join(strings.FieldsFunc(line, isSpaceExceptTab), " "), " ")
+		if strings.TrimSpace(line) == "" {
+			blank++
+			if blank > 1 {
+				continue
+			}
+			line = ""
+		} else {
+			blank = 0
+		}
+		out = append(out, line)
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
+
+func isSpaceExceptTab(r rune) bool {
+	switch r {
+	case ' ', '\u00a0', '\u3000', '\f', '\v', 0:
+		return true
+	}
+	return false
+}
+
+// truncateText 按字节上限截断，保证不截断多字节字符
+func truncateText(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut]
+}
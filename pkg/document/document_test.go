@@ -0,0 +1,204 @@
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image/jpeg"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+func pdfStream(num int, dict string, content []byte) string {
+	return fmt.Sprintf("%d 0 obj\n<< %s /Length %d >>\nstream\n%s\nendstream\nendobj\n", num, dict, len(content), content)
+}
+
+func flate(data string) []byte {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write([]byte(data))
+	zw.Close()
+	return buf.Bytes()
+}
+
+// testPDF 两页：第一页为 Helvetica 文本，第二页为带 ToUnicode 的 Type0 字体，内容流经过 Flate 压缩
+func testPDF() []byte {
+	cmap := "/CIDInit /ProcSet findresource begin 12 dict begin begincmap\n" +
+		"1 begincodespacerange <0000> <FFFF> endcodespacerange\n" +
+		"1 beginbfchar <0001> <4E2D> endbfchar\n" +
+		"1 beginbfrange <0002> <0003> <6587> endbfrange\n" +
+		"endcmap CMapName currentdict /CMap defineresource pop end end"
+	var sb strings.Builder
+	sb.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	sb.WriteString("1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+	sb.WriteString("2 0 obj\n<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 /Resources << /Font << /F1 5 0 R >> >> >>\nendobj\n")
+	sb.WriteString("3 0 obj\n<< /Type /Page /Parent 2 0 R /Contents 6 0 R >>\nendobj\n")
+	sb.WriteString("4 0 obj\n<< /Type /Page /Parent 2 0 R /Contents [7 0 R] /Resources << /Font << /F2 8 0 R >> >> >>\nendobj\n")
+	sb.WriteString("5 0 obj\n<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>\nendobj\n")
+	sb.WriteString(pdfStream(6, "", []byte("BT /F1 12 Tf 72 700 Td (Hello ) Tj [(W) 120 (orld) -300 (again)] TJ 0 -14 Td (Second \\(line\\)) Tj ET")))
+	sb.WriteString(pdfStream(7, "/Filter /FlateDecode", flate("BT /F2 12 Tf 72 700 Td <00010002> Tj T* <0003> Tj ET")))
+	sb.WriteString("8 0 obj\n<< /Type /Font /Subtype /Type0 /BaseFont /SimSun /ToUnicode 9 0 R >>\nendobj\n")
+	sb.WriteString(pdfStream(9, "", []byte(cmap)))
+	sb.WriteString("10 0 obj\n<< /Title <FEFF6D4B8BD5> >>\nendobj\n")
+	sb.WriteString("xref\n0 0\ntrailer\n<< /Root 1 0 R /Info 10 0 R >>\nstartxref\n0\n%%EOF\n")
+	return []byte(sb.String())
+}
+
+func TestExtractPDF(t *testing.T) {
+	info, err := Extract(testPDF(), ".pdf")
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if info.PageCount != 2 || info.Type != TypePDF || info.Title != "测试" {
+		t.Errorf("unexpected info %+v", info)
+	}
+	want := "Hello World again\nSecond (line)\n\n中文\n\u6588"
+	if info.Text != want {
+		t.Errorf("text = %q, want %q", info.Text, want)
+	}
+}
+
+func TestExtractEncryptedPDFCountsPages(t *testing.T) {
+	data := bytes.Replace(testPDF(), []byte("/Root 1 0 R"), []byte("/Root 1 0 R /Encrypt 11 0 R"), 1)
+	info, err := Extract(data, "pdf")
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if !info.Encrypted || info.PageCount != 2 || info.Text != "" {
+		t.Errorf("unexpected info %+v", info)
+	}
+}
+
+func testZip(files map[string]string) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, _ := zw.Create(name)
+		w.Write([]byte(content))
+	}
+	zw.Close()
+	return buf.Bytes()
+}
+
+func TestExtractDOCX(t *testing.T) {
+	data := testZip(map[string]string{
+		"[Content_Types].xml": `<Types/>`,
+		"word/document.xml": `<w:document xmlns:w="w"><w:body>` +
+			`<w:p><w:r><w:t>季度</w:t></w:r><w:r><w:t xml:space="preserve">报告 </w:t></w:r><w:r><w:tab/><w:t>草稿</w:t></w:r></w:p>` +
+			`<w:p><w:r><w:instrText>PAGE</w:instrText><w:t>第二段</w:t></w:r></w:p></w:body></w:document>`,
+		"docProps/app.xml":  `<Properties><Pages>3</Pages></Properties>`,
+		"docProps/core.xml": `<cp:coreProperties xmlns:cp="cp" xmlns:dc="dc"><dc:title>年度总结</dc:title></cp:coreProperties>`,
+	})
+	info, err := Extract(data, "docx")
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if info.Text != "季度报告 \t草稿\n第二段" || info.PageCount != 3 || info.Title != "年度总结" || info.Type != TypeWord {
+		t.Errorf("unexpected info %+v", info)
+	}
+}
+
+func TestExtractXLSXAndPPTX(t *testing.T) {
+	xlsx := testZip(map[string]string{
+		"[Content_Types].xml":  `<Types/>`,
+		"xl/workbook.xml":      `<workbook><sheets><sheet name="销售"/><sheet name="库存"/></sheets></workbook>`,
+		"xl/sharedStrings.xml": `<sst><si><t>产品</t></si><si><r><t>数</t></r><r><t>量</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData>` +
+			`<row><c t="s"><v>0</v></c><c t="s"><v>1</v></c><c/></row>` +
+			`<row><c t="inlineStr"><is><t>苹果</t></is></c><c><v>42</v></c></row></sheetData></worksheet>`,
+		"xl/worksheets/sheet2.xml": `<worksheet><sheetData/></worksheet>`,
+	})
+	info, err := Extract(xlsx, "xlsx")
+	if err != nil {
+		t.Fatalf("xlsx: %v", err)
+	}
+	if info.Text != "销售\n产品\t数量\n苹果\t42\n\n库存" || info.PageCount != 2 {
+		t.Errorf("xlsx: unexpected info %+v", info)
+	}
+
+	pptx := testZip(map[string]string{
+		"[Content_Types].xml":    `<Types/>`,
+		"ppt/slides/slide10.xml": `<p:sld><a:p><a:t>最后</a:t></a:p></p:sld>`,
+		"ppt/slides/slide2.xml":  `<p:sld><a:p><a:t>第二</a:t></a:p></p:sld>`,
+		"ppt/slides/slide1.xml":  `<p:sld><a:p><a:t>标题</a:t><a:br/><a:t>副标题</a:t></a:p></p:sld>`,
+	})
+	info, err = Extract(pptx, "pptx")
+	if err != nil {
+		t.Fatalf("pptx: %v", err)
+	}
+	if info.Text != "标题\n副标题\n\n第二\n\n最后" || info.PageCount != 3 {
+		t.Errorf("pptx: unexpected info %+v", info)
+	}
+}
+
+func TestExtractODT(t *testing.T) {
+	data := testZip(map[string]string{
+		"content.xml": `<office:document-content><office:body><office:text>` +
+			`<text:h>标题</text:h><text:p>第一<text:s/>段<text:tab/>内容</text:p></office:text></office:body></office:document-content>`,
+		"meta.xml": `<office:document-meta><office:meta><dc:title>会议纪要</dc:title>` +
+			`<meta:document-statistic meta:page-count="4"/></office:meta></office:document-meta>`,
+	})
+	info, err := Extract(data, "odt")
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if info.Text != "标题\n第一 段\t内容" || info.PageCount != 4 || info.Title != "会议纪要" {
+		t.Errorf("unexpected info %+v", info)
+	}
+}
+
+func TestExtractPlainTextEncodings(t *testing.T) {
+	gbk, _ := simplifiedchinese.GBK.NewEncoder().Bytes([]byte("你好，世界"))
+	info, err := Extract(gbk, "txt")
+	if err != nil || info.Text != "你好，世界" {
+		t.Fatalf("gbk: %+v, %v", info, err)
+	}
+
+	utf16le := []byte{0xFF, 0xFE, 'h', 0, 'i', 0}
+	if info, _ := Extract(utf16le, "txt"); info.Text != "hi" {
+		t.Errorf("utf-16le: %q", info.Text)
+	}
+
+	if _, err := Extract([]byte("PK\x03\x04\x00\x00binary"), "csv"); err != ErrCorrupted {
+		t.Errorf("binary csv: err = %v, want ErrCorrupted", err)
+	}
+	if _, err := Extract([]byte("x"), "doc"); err != ErrUnsupported {
+		t.Errorf("doc: err = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	cases := map[string]string{
+		"这是一份关于季度销售情况的报告，包含 Q3 revenue 数据":               "zh",
+		"これは四半期の売上に関するレポートです。詳しく説明します":                   "ja",
+		"이 문서는 분기별 매출에 관한 보고서입니다 자세히 설명합니다":              "ko",
+		"Это отчёт о квартальных продажах компании":      "ru",
+		"This is a report about quarterly sales figures": "en",
+		"12345 67890": "",
+	}
+	for text, want := range cases {
+		if got := DetectLanguage(text); got != want {
+			t.Errorf("DetectLanguage(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestPlaceholderPreview(t *testing.T) {
+	for _, info := range []*Info{
+		{Format: "pdf", Type: TypePDF, Text: "第一行\n\n" + strings.Repeat("很长的一行", 40)},
+		{Format: "pptx", Type: TypePresentation},
+		{Format: "xlsx", Type: TypeSpreadsheet, Text: "a\tb"},
+	} {
+		cfg, err := jpeg.DecodeConfig(bytes.NewReader(PlaceholderPreview(info)))
+		if err != nil {
+			t.Fatalf("%s: decode: %v", info.Format, err)
+		}
+		portrait := cfg.Height > cfg.Width
+		if portrait == (info.Type == TypePresentation) {
+			t.Errorf("%s: preview %dx%d has wrong orientation", info.Format, cfg.Width, cfg.Height)
+		}
+	}
+}
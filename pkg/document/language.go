@@ -0,0 +1,50 @@
+package document
+
+import "unicode"
+
+// minLanguageLetters 文字太少时不判断语言
+const minLanguageLetters = 20
+
+// DetectLanguage 按文字所属的书写系统粗略判断主要语言：zh、ja、ko、ru、en，无法判断时返回空字符串
+// 只统计文本开头的一部分；假名出现时判定为日文，拉丁字母统一视为英文
+func DetectLanguage(text string) string {
+	var han, kana, hangul, cyrillic, latin, total int
+	for i, r := range text {
+		if i > 64*1024 {
+			break
+		}
+		switch {
+		case unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r):
+			kana++
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.Is(unicode.Hangul, r):
+			hangul++
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		default:
+			continue
+		}
+		total++
+	}
+	if total < minLanguageLetters {
+		return ""
+	}
+
+	switch {
+	case kana*10 >= total:
+		return "ja"
+	case hangul*3 >= total:
+		return "ko"
+	// 汉字信息密度高，中英混排时占比超过约 1/5 即以中文为主
+	case han*5 >= total:
+		return "zh"
+	case cyrillic*2 >= total:
+		return "ru"
+	case latin*2 >= total:
+		return "en"
+	}
+	return ""
+}
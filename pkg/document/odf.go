@@ -0,0 +1,93 @@
+package document
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// odfRules OpenDocument 的正文：段落、标题换行，表格单元格用制表符分隔
+var odfRules = xmlTextRules{
+	open: map[string]string{"s": " ", "tab": "\t", "line-break": "\n"},
+	close: map[string]string{
+		"p": "\n", "h": "\n",
+		"table-cell": "\t", "table-row": "\n", "table": "\n",
+		"page": "\n",
+	},
+}
+
+// extractODF 解析 odt/ods/odp
+func extractODF(data []byte, info *Info) error {
+	pkg, err := openZipPackage(data)
+	if err != nil {
+		return err
+	}
+	content, ok := pkg.open("content.xml")
+	if !ok {
+		return ErrCorrupted
+	}
+
+	var sb strings.Builder
+	collectXMLText(content, odfRules, &sb)
+	info.Text = sb.String()
+
+	if meta, ok := pkg.open("meta.xml"); ok {
+		dec := xml.NewDecoder(meta)
+		for {
+			tok, err := dec.Token()
+			if err != nil {
+				break
+			}
+			se, ok := tok.(xml.StartElement)
+			if !ok {
+				continue
+			}
+			switch se.Name.Local {
+			case "title":
+				var title string
+				if dec.DecodeElement(&title, &se) == nil {
+					info.Title = title
+				}
+			case "document-statistic":
+				// 文本文档记录了页数，表格和演示文稿按正文中的工作表、幻灯片计数
+				if info.Format == "odt" {
+					info.PageCount, _ = strconv.Atoi(xmlAttr(se, "page-count"))
+				}
+			}
+		}
+	}
+
+	if local := map[string]string{"odp": "page", "ods": "table"}[info.Format]; local != "" {
+		if r, ok := pkg.open("content.xml"); ok {
+			info.PageCount = countODFElements(r, local)
+		}
+	}
+	return nil
+}
+
+// countODFElements 统计 office:body 中指定本地名的顶层元素（幻灯片 draw:page、工作表 table:table）
+func countODFElements(r io.Reader, local string) int {
+	dec := xml.NewDecoder(r)
+	dec.Strict = false
+	count, depth := 0, 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return count
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Local == local {
+				if depth == 0 {
+					count++
+				}
+				depth++
+			}
+		case xml.EndElement:
+			if t.Name.Local == local {
+				depth--
+			}
+		}
+	}
+}
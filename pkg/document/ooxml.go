@@ -0,0 +1,325 @@
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxZipEntryBytes 压缩包内单个 XML 文件解压后的最大长度，防止压缩炸弹
+const maxZipEntryBytes = 32 << 20
+
+var (
+	pptxSlidePath = regexp.MustCompile(`^ppt/slides/slide(\d+)\.xml$`)
+	xlsxSheetPath = regexp.MustCompile(`^xl/worksheets/sheet(\d+)\.xml$`)
+)
+
+// zipPackage Office Open XML 和 OpenDocument 共用的 zip 容器
+type zipPackage struct {
+	files map[string]*zip.File
+}
+
+func openZipPackage(data []byte) (*zipPackage, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, ErrCorrupted
+	}
+	pkg := &zipPackage{files: make(map[string]*zip.File, len(zr.File))}
+	for _, f := range zr.File {
+		pkg.files[f.Name] = f
+	}
+	return pkg, nil
+}
+
+func (p *zipPackage) open(name string) (io.Reader, bool) {
+	f, ok := p.files[name]
+	if !ok {
+		return nil, false
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, false
+	}
+	// 条目内容一次性读出，避免调用方忘记关闭
+	data, _ := io.ReadAll(io.LimitReader(rc, maxZipEntryBytes))
+	rc.Close()
+	return bytes.NewReader(data), true
+}
+
+// numbered 按文件名中的序号排序匹配的条目，如 slide1、slide2、slide10
+func (p *zipPackage) numbered(pattern *regexp.Regexp) []string {
+	type entry struct {
+		name string
+		n    int
+	}
+	var entries []entry
+	for name := range p.files {
+		if m := pattern.FindStringSubmatch(name); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			entries = append(entries, entry{name, n})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].n < entries[j].n })
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e.name
+	}
+	return names
+}
+
+// xmlTextRules 从 XML 中提取文本的规则，元素均按本地名（不含命名空间前缀）匹配
+type xmlTextRules struct {
+	textIn map[string]bool   // 只收集这些元素内的文字，为 nil 时收集全部文字
+	open   map[string]string // 元素开始时输出的内容（如制表符、换行元素）
+	close  map[string]string // 元素结束时输出的内容（如段落换行）
+}
+
+// collectXMLText 按规则提取文本，XML 损坏时返回已提取的部分
+func collectXMLText(r io.Reader, rules xmlTextRules, sb *strings.Builder) {
+	dec := xml.NewDecoder(r)
+	dec.Strict = false
+	depth := 0
+	for sb.Len() < MaxTextBytes {
+		tok, err := dec.Token()
+		if err != nil {
+			return
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if rules.textIn[t.Name.Local] {
+				depth++
+			}
+			sb.WriteString(rules.open[t.Name.Local])
+		case xml.EndElement:
+			if rules.textIn[t.Name.Local] {
+				depth--
+			}
+			sb.WriteString(rules.close[t.Name.Local])
+		case xml.CharData:
+			if rules.textIn == nil || depth > 0 {
+				sb.Write(t)
+			}
+		}
+	}
+}
+
+var (
+	docxRules = xmlTextRules{
+		textIn: map[string]bool{"t": true},
+		open:   map[string]string{"tab": "\t", "br": "\n", "cr": "\n"},
+		close:  map[string]string{"p": "\n"},
+	}
+	pptxRules = xmlTextRules{
+		textIn: map[string]bool{"t": true},
+		open:   map[string]string{"br": "\n"},
+		close:  map[string]string{"p": "\n"},
+	}
+)
+
+// extractOOXML 解析 docx/xlsx/pptx
+func extractOOXML(data []byte, info *Info) error {
+	pkg, err := openZipPackage(data)
+	if err != nil {
+		return err
+	}
+	if _, ok := pkg.files["[Content_Types].xml"]; !ok {
+		return ErrCorrupted
+	}
+
+	if r, ok := pkg.open("docProps/core.xml"); ok {
+		info.Title = xmlElementText(r, "title")
+	}
+
+	var sb strings.Builder
+	switch info.Format {
+	case "docx":
+		r, ok := pkg.open("word/document.xml")
+		if !ok {
+			return ErrCorrupted
+		}
+		collectXMLText(r, docxRules, &sb)
+		if app, ok := pkg.open("docProps/app.xml"); ok {
+			info.PageCount, _ = strconv.Atoi(xmlElementText(app, "Pages"))
+		}
+	case "pptx":
+		slides := pkg.numbered(pptxSlidePath)
+		for _, name := range slides {
+			if r, ok := pkg.open(name); ok {
+				collectXMLText(r, pptxRules, &sb)
+				sb.WriteString("\n")
+			}
+		}
+		info.PageCount = len(slides)
+	case "xlsx":
+		info.PageCount = extractXLSX(pkg, &sb)
+	}
+	info.Text = sb.String()
+	return nil
+}
+
+// extractXLSX 按工作表输出单元格文本（制表符分隔列），返回工作表数量
+func extractXLSX(pkg *zipPackage, sb *strings.Builder) int {
+	var shared []string
+	if r, ok := pkg.open("xl/sharedStrings.xml"); ok {
+		shared = xlsxSharedStrings(r)
+	}
+
+	var names []string
+	if r, ok := pkg.open("xl/workbook.xml"); ok {
+		dec := xml.NewDecoder(r)
+		for {
+			tok, err := dec.Token()
+			if err != nil {
+				break
+			}
+			if se, ok := tok.(xml.StartElement); ok && se.Name.Local == "sheet" {
+				names = append(names, xmlAttr(se, "name"))
+			}
+		}
+	}
+
+	sheets := pkg.numbered(xlsxSheetPath)
+	for i, name := range sheets {
+		r, ok := pkg.open(name)
+		if !ok {
+			continue
+		}
+		if i < len(names) && names[i] != "" {
+			sb.WriteString(names[i])
+			sb.WriteString("\n")
+		}
+		xlsxSheetText(r, shared, sb)
+		sb.WriteString("\n")
+	}
+	return max(len(sheets), len(names))
+}
+
+func xlsxSharedStrings(r io.Reader) []string {
+	var out []string
+	var cur strings.Builder
+	inText, inPhonetic := false, false
+	dec := xml.NewDecoder(r)
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return out
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "si":
+				cur.Reset()
+			case "t":
+				inText = true
+			case "rPh":
+				inPhonetic = true
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "si":
+				out = append(out, cur.String())
+			case "t":
+				inText = false
+			case "rPh":
+				inPhonetic = false
+			}
+		case xml.CharData:
+			if inText && !inPhonetic {
+				cur.Write(t)
+			}
+		}
+	}
+}
+
+// xlsxSheetText 共享字符串类型的单元格按索引取值，其余取单元格的原始值
+func xlsxSheetText(r io.Reader, shared []string, sb *strings.Builder) {
+	var cellType string
+	var value strings.Builder
+	var row []string
+	inValue := false
+	dec := xml.NewDecoder(r)
+	for sb.Len() < MaxTextBytes {
+		tok, err := dec.Token()
+		if err != nil {
+			return
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "row":
+				row = row[:0]
+			case "c":
+				cellType = xmlAttr(t, "t")
+				value.Reset()
+			case "v", "t":
+				inValue = true
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "v", "t":
+				inValue = false
+			case "c":
+				v := value.String()
+				if cellType == "s" {
+					if idx, err := strconv.Atoi(v); err == nil && idx >= 0 && idx < len(shared) {
+						v = shared[idx]
+					}
+				}
+				row = append(row, v)
+			case "row":
+				// 去掉行尾的空单元格，空行不输出
+				for len(row) > 0 && strings.TrimSpace(row[len(row)-1]) == "" {
+					row = row[:len(row)-1]
+				}
+				if len(row) > 0 {
+					sb.WriteString(strings.Join(row, "\t"))
+					sb.WriteString("\n")
+				}
+			}
+		case xml.CharData:
+			if inValue {
+				value.Write(t)
+			}
+		}
+	}
+}
+
+// xmlElementText 返回第一个指定本地名元素的文本
+func xmlElementText(r io.Reader, local string) string {
+	dec := xml.NewDecoder(r)
+	dec.Strict = false
+	inside := false
+	var sb strings.Builder
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return strings.TrimSpace(sb.String())
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			inside = inside || t.Name.Local == local
+		case xml.EndElement:
+			if t.Name.Local == local && inside {
+				return strings.TrimSpace(sb.String())
+			}
+		case xml.CharData:
+			if inside {
+				sb.Write(t)
+			}
+		}
+	}
+}
+
+func xmlAttr(se xml.StartElement, local string) string {
+	for _, a := range se.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
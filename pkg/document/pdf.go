@@ -0,0 +1,416 @@
+package document
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxStreamBytes 单个流解压后的最大长度，防止压缩炸弹
+	maxStreamBytes = 16 << 20
+	// maxPDFDepth 页面树和表单对象的最大嵌套深度
+	maxPDFDepth = 32
+)
+
+var (
+	pdfObjHeader = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+	pdfEncrypt   = regexp.MustCompile(`/Encrypt\s*(\d+\s+\d+\s+R|<<)`)
+	pdfTypePage  = regexp.MustCompile(`/Type\s*/Page[^s]`)
+)
+
+// pdfObject 间接对象：值和（如有）解码后的流数据
+type pdfObject struct {
+	gen    int
+	value  any
+	raw    []byte // 流的原始数据（可能加密、压缩）
+	stream []byte
+}
+
+// pdfDocument 按对象编号索引的 PDF 对象集合
+// 不依赖交叉引用表：顺序扫描所有 "n g obj" 对象并展开对象流，对损坏的 xref 也能工作
+type pdfDocument struct {
+	objects   map[int]*pdfObject
+	trailer   pdfDict
+	root      int
+	decrypted bool
+}
+
+// extractPDF 解析 PDF 的页数、标题和文本
+func extractPDF(data []byte, info *Info) error {
+	if !bytes.Contains(data[:min(len(data), 1024)], []byte("%PDF-")) {
+		return ErrCorrupted
+	}
+	info.Encrypted = pdfEncrypt.Match(data)
+
+	doc := parsePDF(data)
+	if len(doc.objects) == 0 {
+		return ErrCorrupted
+	}
+
+	pages := doc.pages()
+	info.PageCount = len(pages)
+	if info.PageCount == 0 {
+		info.PageCount = doc.fallbackPageCount(data)
+	}
+	if title, ok := doc.info()["Title"].(pdfString); ok {
+		info.Title = decodePDFTextString(title)
+	}
+	if info.Encrypted && !doc.decrypted {
+		return nil
+	}
+
+	var sb strings.Builder
+	for _, page := range pages {
+		if sb.Len() >= MaxTextBytes {
+			break
+		}
+		ex := newTextExtractor(doc, &sb)
+		ex.run(doc.pageContents(page), doc.inheritedResources(page), 0)
+		sb.WriteString("\n\n")
+	}
+	info.Text = sb.String()
+	return nil
+}
+
+// parsePDF 扫描全部对象并解码流；加密文档在可以用空密码解密时先解密
+func parsePDF(data []byte) *pdfDocument {
+	doc := &pdfDocument{objects: map[int]*pdfObject{}}
+
+	for pos := 0; pos < len(data); {
+		loc := pdfObjHeader.FindSubmatchIndex(data[pos:])
+		if loc == nil {
+			break
+		}
+		num, _ := strconv.Atoi(string(data[pos+loc[2] : pos+loc[3]]))
+		gen, _ := strconv.Atoi(string(data[pos+loc[4] : pos+loc[5]]))
+		pos += loc[1]
+
+		lex := &pdfLexer{data: data, pos: pos}
+		obj := &pdfObject{gen: gen, value: lex.value()}
+		pos = lex.pos
+
+		// 紧跟 stream 关键字的是流数据
+		lex.skipSpace()
+		if bytes.HasPrefix(data[lex.pos:], []byte("stream")) {
+			start := lex.pos + len("stream")
+			if start < len(data) && data[start] == '\r' {
+				start++
+			}
+			if start < len(data) && data[start] == '\n' {
+				start++
+			}
+			end := streamEnd(data, start, obj.value)
+			obj.raw = data[start:end]
+			pos = end
+		}
+		// 增量更新中后出现的同号对象覆盖之前的版本
+		doc.objects[num] = obj
+	}
+
+	doc.trailer = findTrailer(data, doc.objects)
+	crypt, encrypted := doc.decrypter()
+	doc.decrypted = crypt != nil
+
+	var objStreams []*pdfObject
+	for num, obj := range doc.objects {
+		d, _ := obj.value.(pdfDict)
+		// 交叉引用流和加密字典本身不加密
+		skipCrypt := crypt == nil || d["Type"] == pdfName("XRef") || pdfRef(num) == doc.trailer["Encrypt"]
+		if encrypted && crypt == nil {
+			obj.raw = nil
+			continue
+		}
+		if !skipCrypt {
+			obj.value = crypt.decryptStrings(obj.value, num, obj.gen)
+		}
+		if obj.raw != nil {
+			raw := obj.raw
+			if !skipCrypt {
+				raw = crypt.decryptStream(raw, num, obj.gen)
+			}
+			obj.stream, obj.raw = decodeStream(raw, obj.value), nil
+		}
+		if d["Type"] == pdfName("ObjStm") {
+			objStreams = append(objStreams, obj)
+		}
+	}
+	for _, stm := range objStreams {
+		doc.expandObjectStream(stm)
+	}
+
+	if ref, ok := doc.trailer["Root"].(pdfRef); ok {
+		doc.root = int(ref)
+	}
+	if _, ok := doc.dict(pdfRef(doc.root))["Pages"]; !ok {
+		doc.root = doc.findCatalog()
+	}
+	return doc
+}
+
+// decrypter 文档加密时返回解密器，需要用户密码时解密器为 nil
+func (doc *pdfDocument) decrypter() (*pdfDecrypter, bool) {
+	encRef, ok := doc.trailer["Encrypt"]
+	if !ok {
+		return nil, false
+	}
+	enc := doc.dict(encRef)
+	if enc == nil {
+		return nil, true
+	}
+	return newPDFDecrypter(enc, doc.trailer), true
+}
+
+// findTrailer 合并全部 trailer 字典，后出现的（增量更新）覆盖之前的键；
+// PDF 1.5 的交叉引用流没有 trailer 关键字，其字典按对象号顺序合并在前
+func findTrailer(data []byte, objects map[int]*pdfObject) pdfDict {
+	var xrefs []int
+	for num, obj := range objects {
+		if d, ok := obj.value.(pdfDict); ok && d["Type"] == pdfName("XRef") {
+			xrefs = append(xrefs, num)
+		}
+	}
+	sort.Ints(xrefs)
+
+	trailer := pdfDict{}
+	for _, num := range xrefs {
+		for k, v := range objects[num].value.(pdfDict) {
+			trailer[k] = v
+		}
+	}
+	for pos := 0; ; {
+		i := bytes.Index(data[pos:], []byte("trailer"))
+		if i < 0 {
+			break
+		}
+		lex := &pdfLexer{data: data, pos: pos + i + len("trailer")}
+		if d, ok := lex.value().(pdfDict); ok {
+			for k, v := range d {
+				trailer[k] = v
+			}
+		}
+		pos = lex.pos
+	}
+	return trailer
+}
+
+// streamEnd 优先按 /Length 定位流结尾，长度是间接引用或不可信时查找 endstream
+func streamEnd(data []byte, start int, value any) int {
+	if d, ok := value.(pdfDict); ok {
+		if n, ok := d["Length"].(float64); ok {
+			end := start + int(n)
+			if n >= 0 && end <= len(data) {
+				rest := bytes.TrimLeft(data[end:min(len(data), end+32)], "\r\n \t")
+				if bytes.HasPrefix(rest, []byte("endstream")) {
+					return end
+				}
+			}
+		}
+	}
+	i := bytes.Index(data[start:], []byte("endstream"))
+	if i < 0 {
+		return len(data)
+	}
+	end := start + i
+	for end > start && (data[end-1] == '\n' || data[end-1] == '\r') {
+		end--
+	}
+	return end
+}
+
+// decodeStream 只支持 FlateDecode 和未压缩的流，其他过滤器（图片等）返回 nil
+func decodeStream(raw []byte, value any) []byte {
+	d, _ := value.(pdfDict)
+	var filters []any
+	switch f := d["Filter"].(type) {
+	case pdfName:
+		filters = []any{f}
+	case pdfArray:
+		filters = f
+	}
+	if len(filters) == 0 {
+		return raw
+	}
+	if len(filters) > 1 || (filters[0] != pdfName("FlateDecode") && filters[0] != pdfName("Fl")) {
+		return nil
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil
+	}
+	defer zr.Close()
+	// 截断或校验和错误的流保留已解出的部分
+	out, _ := io.ReadAll(io.LimitReader(zr, maxStreamBytes))
+	return out
+}
+
+// expandObjectStream 展开对象流（PDF 1.5+）中压缩存放的对象
+func (doc *pdfDocument) expandObjectStream(obj *pdfObject) {
+	d := obj.value.(pdfDict)
+	n, _ := d["N"].(float64)
+	first, _ := d["First"].(float64)
+	if obj.stream == nil || int(first) > len(obj.stream) {
+		return
+	}
+
+	header := &pdfLexer{data: obj.stream[:int(first)]}
+	for i := 0; i < int(n); i++ {
+		num, ok1 := header.token().(float64)
+		offset, ok2 := header.token().(float64)
+		if !ok1 || !ok2 {
+			return
+		}
+		start := int(first) + int(offset)
+		if start >= len(obj.stream) {
+			continue
+		}
+		if _, exists := doc.objects[int(num)]; exists {
+			continue
+		}
+		lex := &pdfLexer{data: obj.stream, pos: start}
+		doc.objects[int(num)] = &pdfObject{value: lex.value()}
+	}
+}
+
+func (doc *pdfDocument) findCatalog() int {
+	root := 0
+	for num, obj := range doc.objects {
+		if d, ok := obj.value.(pdfDict); ok && d["Type"] == pdfName("Catalog") && num > root {
+			root = num
+		}
+	}
+	return root
+}
+
+// resolve 解析间接引用
+func (doc *pdfDocument) resolve(v any) any {
+	for i := 0; i < maxPDFDepth; i++ {
+		ref, ok := v.(pdfRef)
+		if !ok {
+			return v
+		}
+		obj := doc.objects[int(ref)]
+		if obj == nil {
+			return nil
+		}
+		v = obj.value
+	}
+	return nil
+}
+
+func (doc *pdfDocument) dict(v any) pdfDict {
+	d, _ := doc.resolve(v).(pdfDict)
+	return d
+}
+
+func (doc *pdfDocument) stream(v any) []byte {
+	if ref, ok := v.(pdfRef); ok {
+		if obj := doc.objects[int(ref)]; obj != nil {
+			return obj.stream
+		}
+	}
+	return nil
+}
+
+// info 文档信息字典（标题等）
+func (doc *pdfDocument) info() pdfDict {
+	return doc.dict(doc.trailer["Info"])
+}
+
+// pages 按页面树顺序返回所有页面字典
+func (doc *pdfDocument) pages() []pdfDict {
+	catalog := doc.dict(pdfRef(doc.root))
+	var pages []pdfDict
+	visited := map[pdfRef]bool{}
+	var walk func(node any, depth int)
+	walk = func(node any, depth int) {
+		if ref, ok := node.(pdfRef); ok {
+			if visited[ref] {
+				return
+			}
+			visited[ref] = true
+		}
+		d := doc.dict(node)
+		if d == nil || depth > maxPDFDepth {
+			return
+		}
+		kids, hasKids := doc.resolve(d["Kids"]).(pdfArray)
+		if d["Type"] == pdfName("Page") || !hasKids {
+			pages = append(pages, d)
+			return
+		}
+		for _, kid := range kids {
+			walk(kid, depth+1)
+		}
+	}
+	if catalog != nil {
+		walk(catalog["Pages"], 0)
+	}
+	return pages
+}
+
+// fallbackPageCount 页面树无法遍历时（加密或损坏），取页面树根节点的 /Count 或统计页面对象
+func (doc *pdfDocument) fallbackPageCount(data []byte) int {
+	count := 0
+	for _, obj := range doc.objects {
+		if d, ok := obj.value.(pdfDict); ok && d["Type"] == pdfName("Pages") {
+			if n, ok := d["Count"].(float64); ok && int(n) > count {
+				count = int(n)
+			}
+		}
+	}
+	if count == 0 {
+		count = len(pdfTypePage.FindAllIndex(data, -1))
+	}
+	return count
+}
+
+// pageContents 拼接页面的全部内容流
+func (doc *pdfDocument) pageContents(page pdfDict) []byte {
+	switch c := page["Contents"].(type) {
+	case pdfRef:
+		if arr, ok := doc.resolve(c).(pdfArray); ok {
+			return doc.joinStreams(arr)
+		}
+		return doc.stream(c)
+	case pdfArray:
+		return doc.joinStreams(c)
+	}
+	return nil
+}
+
+func (doc *pdfDocument) joinStreams(refs pdfArray) []byte {
+	var buf bytes.Buffer
+	for _, ref := range refs {
+		buf.Write(doc.stream(ref))
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// inheritedResources 页面资源可以从父节点继承
+func (doc *pdfDocument) inheritedResources(page pdfDict) pdfDict {
+	node := page
+	for i := 0; node != nil && i < maxPDFDepth; i++ {
+		if res := doc.dict(node["Resources"]); res != nil {
+			return res
+		}
+		node = doc.dict(node["Parent"])
+	}
+	return nil
+}
+
+// decodePDFTextString 文档信息中的字符串为带 BOM 的 UTF-16BE 或 PDFDocEncoding
+func decodePDFTextString(s pdfString) string {
+	if len(s) >= 2 && s[0] == 0xFE && s[1] == 0xFF {
+		return decodeUTF16BE(s[2:])
+	}
+	if len(s) >= 3 && s[0] == 0xEF && s[1] == 0xBB && s[2] == 0xBF {
+		return string(s[3:])
+	}
+	return decodeWinAnsi(s)
+}
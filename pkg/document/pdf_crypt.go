@@ -0,0 +1,231 @@
+package document
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"crypto/rc4"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"hash"
+)
+
+// pdfPasswordPadding 标准安全处理器的口令填充串
+var pdfPasswordPadding = []byte{
+	0x28, 0xBF, 0x4E, 0x5E, 0x4E, 0x75, 0x8A, 0x41, 0x64, 0x00, 0x4E, 0x56, 0xFF, 0xFA, 0x01, 0x08,
+	0x2E, 0x2E, 0x00, 0xB6, 0xD0, 0x68, 0x3E, 0x80, 0x2F, 0x0C, 0xA9, 0xFE, 0x64, 0x53, 0x69, 0x7A,
+}
+
+// pdfDecrypter 只设置了所有者密码（限制打印、复制）的 PDF 用户密码为空，可以直接解密
+// 支持标准安全处理器的 RC4、AES-128 和 AES-256；需要打开密码的文档返回 nil
+type pdfDecrypter struct {
+	key     []byte
+	aes     bool
+	aes256  bool
+	noStrFn bool // /StrF 为 Identity，字符串不加密
+	noStmFn bool // /StmF 为 Identity，流不加密
+}
+
+func newPDFDecrypter(enc pdfDict, trailer pdfDict) *pdfDecrypter {
+	if enc["Filter"] != pdfName("Standard") {
+		return nil
+	}
+	v, _ := enc["V"].(float64)
+	r, _ := enc["R"].(float64)
+	o, _ := enc["O"].(pdfString)
+	u, _ := enc["U"].(pdfString)
+	p, _ := enc["P"].(float64)
+
+	d := &pdfDecrypter{}
+	if v >= 4 {
+		cf, _ := enc["CF"].(pdfDict)
+		stdCF, _ := cf["StdCF"].(pdfDict)
+		switch stdCF["CFM"] {
+		case pdfName("AESV2"):
+			d.aes = true
+		case pdfName("AESV3"):
+			d.aes, d.aes256 = true, true
+		}
+		d.noStrFn = enc["StrF"] == pdfName("Identity")
+		d.noStmFn = enc["StmF"] == pdfName("Identity")
+	}
+
+	if r >= 5 {
+		ue, _ := enc["UE"].(pdfString)
+		if len(u) < 48 || len(ue) < 32 {
+			return nil
+		}
+		d.aes, d.aes256 = true, true
+		if !bytes.Equal(pdfHash2B(u[32:40], int(r)), u[:32]) {
+			return nil
+		}
+		block, err := aes.NewCipher(pdfHash2B(u[40:48], int(r)))
+		if err != nil {
+			return nil
+		}
+		d.key = make([]byte, 32)
+		cipher.NewCBCDecrypter(block, make([]byte, 16)).CryptBlocks(d.key, ue[:32])
+		return d
+	}
+
+	var id []byte
+	if ids, ok := trailer["ID"].(pdfArray); ok && len(ids) > 0 {
+		id, _ = ids[0].(pdfString)
+	}
+	n := 5
+	if r >= 3 {
+		if length, ok := enc["Length"].(float64); ok && length >= 40 && length <= 128 {
+			n = int(length) / 8
+		} else {
+			n = 16
+		}
+	}
+
+	// 算法 2：由空用户密码计算文件密钥
+	h := md5.New()
+	h.Write(pdfPasswordPadding)
+	h.Write(o)
+	binary.Write(h, binary.LittleEndian, int32(p))
+	h.Write(id)
+	if r >= 4 && enc["EncryptMetadata"] == pdfKeyword("false") {
+		h.Write([]byte{0xFF, 0xFF, 0xFF, 0xFF})
+	}
+	key := h.Sum(nil)
+	if r >= 3 {
+		for i := 0; i < 50; i++ {
+			sum := md5.Sum(key[:n])
+			key = sum[:]
+		}
+	}
+	d.key = key[:n]
+
+	// 算法 4/5：校验 /U，不一致说明需要用户密码
+	var check []byte
+	if r == 2 {
+		check = rc4Crypt(d.key, pdfPasswordPadding)
+		if !bytes.Equal(check, u) {
+			return nil
+		}
+		return d
+	}
+	sum := md5.Sum(append(append([]byte{}, pdfPasswordPadding...), id...))
+	check = sum[:]
+	for i := 0; i < 20; i++ {
+		k := make([]byte, len(d.key))
+		for j := range k {
+			k[j] = d.key[j] ^ byte(i)
+		}
+		check = rc4Crypt(k, check)
+	}
+	if len(u) < 16 || !bytes.Equal(check, u[:16]) {
+		return nil
+	}
+	return d
+}
+
+// objectKey 算法 1：每个对象使用由对象号和生成号派生的密钥，AES-256 直接使用文件密钥
+func (d *pdfDecrypter) objectKey(num, gen int) []byte {
+	if d.aes256 {
+		return d.key
+	}
+	h := md5.New()
+	h.Write(d.key)
+	h.Write([]byte{byte(num), byte(num >> 8), byte(num >> 16), byte(gen), byte(gen >> 8)})
+	if d.aes {
+		h.Write([]byte("sAlT"))
+	}
+	return h.Sum(nil)[:min(len(d.key)+5, 16)]
+}
+
+func (d *pdfDecrypter) decrypt(data []byte, num, gen int) []byte {
+	key := d.objectKey(num, gen)
+	if !d.aes {
+		return rc4Crypt(key, data)
+	}
+	if len(data) < 32 || len(data)%aes.BlockSize != 0 {
+		return nil
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil
+	}
+	out := make([]byte, len(data)-aes.BlockSize)
+	cipher.NewCBCDecrypter(block, data[:aes.BlockSize]).CryptBlocks(out, data[aes.BlockSize:])
+	if pad := int(out[len(out)-1]); pad > 0 && pad <= aes.BlockSize && pad <= len(out) {
+		out = out[:len(out)-pad]
+	}
+	return out
+}
+
+func (d *pdfDecrypter) decryptStream(data []byte, num, gen int) []byte {
+	if d.noStmFn {
+		return data
+	}
+	return d.decrypt(data, num, gen)
+}
+
+// decryptStrings 解密对象值中的全部字符串
+func (d *pdfDecrypter) decryptStrings(v any, num, gen int) any {
+	if d.noStrFn {
+		return v
+	}
+	switch t := v.(type) {
+	case pdfString:
+		return pdfString(d.decrypt(t, num, gen))
+	case pdfArray:
+		for i := range t {
+			t[i] = d.decryptStrings(t[i], num, gen)
+		}
+	case pdfDict:
+		for k := range t {
+			t[k] = d.decryptStrings(t[k], num, gen)
+		}
+	}
+	return v
+}
+
+func rc4Crypt(key, data []byte) []byte {
+	c, err := rc4.NewCipher(key)
+	if err != nil {
+		return nil
+	}
+	out := make([]byte, len(data))
+	c.XORKeyStream(out, data)
+	return out
+}
+
+// pdfHash2B 空密码下的 AES-256 口令散列：R5 为一次 SHA-256，R6 为 ISO 32000-2 的算法 2.B
+func pdfHash2B(salt []byte, r int) []byte {
+	sum := sha256.Sum256(salt)
+	k := sum[:]
+	if r < 6 {
+		return k
+	}
+	for i := 0; ; i++ {
+		k1 := bytes.Repeat(k, 64)
+		block, _ := aes.NewCipher(k[:16])
+		e := make([]byte, len(k1))
+		cipher.NewCBCEncrypter(block, k[16:32]).CryptBlocks(e, k1)
+
+		mod := 0
+		for _, b := range e[:16] {
+			mod += int(b)
+		}
+		var h hash.Hash
+		switch mod % 3 {
+		case 0:
+			h = sha256.New()
+		case 1:
+			h = sha512.New384()
+		default:
+			h = sha512.New()
+		}
+		h.Write(e)
+		k = h.Sum(nil)
+		if i >= 63 && int(e[len(e)-1]) <= i-31 {
+			return k[:32]
+		}
+	}
+}
@@ -0,0 +1,325 @@
+package document
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// PDF 对象的 Go 表示
+type (
+	pdfName  string
+	pdfRef   int
+	pdfDict  map[string]any
+	pdfArray []any
+	// pdfString 字符串的原始字节，编码取决于使用它的字体或上下文
+	pdfString []byte
+	// pdfKeyword 内容流中的操作符以及 true/false/null 等关键字
+	pdfKeyword string
+)
+
+// pdfDelim 结束当前 token 的特殊字符
+type pdfDelim byte
+
+// pdfLexer PDF 语法的词法分析器，同时用于对象和内容流
+type pdfLexer struct {
+	data []byte
+	pos  int
+}
+
+func isPDFSpace(c byte) bool {
+	switch c {
+	case 0, '\t', '\n', '\f', '\r', ' ':
+		return true
+	}
+	return false
+}
+
+func isPDFDelimiter(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+func (l *pdfLexer) skipSpace() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if isPDFSpace(c) {
+			l.pos++
+		} else if c == '%' {
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		} else {
+			return
+		}
+	}
+}
+
+// token 读取下一个基础 token：数字返回 float64，字符串、名称、关键字返回对应类型，
+// 数组和字典的边界返回 pdfDelim；到达末尾返回 nil
+func (l *pdfLexer) token() any {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil
+	}
+	c := l.data[l.pos]
+	switch {
+	case c == '/':
+		l.pos++
+		start := l.pos
+		for l.pos < len(l.data) && !isPDFSpace(l.data[l.pos]) && !isPDFDelimiter(l.data[l.pos]) {
+			l.pos++
+		}
+		return pdfName(decodeNameEscapes(l.data[start:l.pos]))
+	case c == '(':
+		l.pos++
+		return pdfString(l.literalString())
+	case c == '<':
+		if l.pos+1 < len(l.data) && l.data[l.pos+1] == '<' {
+			l.pos += 2
+			return pdfDelim('{')
+		}
+		l.pos++
+		return pdfString(l.hexString())
+	case c == '>':
+		l.pos++
+		if l.pos < len(l.data) && l.data[l.pos] == '>' {
+			l.pos++
+		}
+		return pdfDelim('}')
+	case c == '[' || c == ']' || c == '{' || c == '}':
+		l.pos++
+		if c == '{' || c == '}' {
+			// PostScript 过程（CMap、Type4 函数中），作为关键字处理
+			return pdfKeyword(string(c))
+		}
+		return pdfDelim(c)
+	case c == ')':
+		l.pos++
+		return l.token()
+	}
+
+	start := l.pos
+	for l.pos < len(l.data) && !isPDFSpace(l.data[l.pos]) && !isPDFDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	word := l.data[start:l.pos]
+	if isNumberToken(word) {
+		f, err := strconv.ParseFloat(string(word), 64)
+		if err == nil {
+			return f
+		}
+		return float64(0)
+	}
+	return pdfKeyword(word)
+}
+
+func isNumberToken(b []byte) bool {
+	if len(b) == 0 {
+		return false
+	}
+	digits := 0
+	for i, c := range b {
+		switch {
+		case c >= '0' && c <= '9':
+			digits++
+		case c == '.':
+		case (c == '-' || c == '+') && i == 0:
+		default:
+			return false
+		}
+	}
+	return digits > 0
+}
+
+// value 读取一个完整的值：数组、字典以及 "n g R" 形式的间接引用
+func (l *pdfLexer) value() any {
+	return l.valueFrom(l.token())
+}
+
+func (l *pdfLexer) valueFrom(tok any) any {
+	switch t := tok.(type) {
+	case pdfDelim:
+		switch t {
+		case '[':
+			arr := pdfArray{}
+			for {
+				next := l.token()
+				if next == nil || next == pdfDelim(']') {
+					return arr
+				}
+				arr = append(arr, l.valueFrom(next))
+			}
+		case '{':
+			dict := pdfDict{}
+			for {
+				next := l.token()
+				if next == nil || next == pdfDelim('}') {
+					return dict
+				}
+				key, ok := next.(pdfName)
+				if !ok {
+					continue
+				}
+				dict[string(key)] = l.value()
+			}
+		}
+		return nil
+	case float64:
+		// 向后看两个 token 判断是否为间接引用
+		save := l.pos
+		if gen, ok := l.token().(float64); ok && gen >= 0 {
+			if kw, ok := l.token().(pdfKeyword); ok && kw == "R" {
+				return pdfRef(int(t))
+			}
+		}
+		l.pos = save
+		return t
+	}
+	return tok
+}
+
+func (l *pdfLexer) literalString() []byte {
+	var out []byte
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return out
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				return out
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				// 行尾续行
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						v = v*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					c = byte(v)
+				} else {
+					c = e
+				}
+			}
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+func (l *pdfLexer) hexString() []byte {
+	var out []byte
+	var hi byte
+	half := false
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		if c == '>' {
+			break
+		}
+		v, ok := hexValue(c)
+		if !ok {
+			continue
+		}
+		if half {
+			out = append(out, hi<<4|v)
+		} else {
+			hi = v
+		}
+		half = !half
+	}
+	if half {
+		out = append(out, hi<<4)
+	}
+	return out
+}
+
+func hexValue(c byte) (byte, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
+
+func decodeNameEscapes(b []byte) string {
+	if bytes.IndexByte(b, '#') < 0 {
+		return string(b)
+	}
+	out := make([]byte, 0, len(b))
+	for i := 0; i < len(b); i++ {
+		if b[i] == '#' && i+2 < len(b) {
+			hi, ok1 := hexValue(b[i+1])
+			lo, ok2 := hexValue(b[i+2])
+			if ok1 && ok2 {
+				out = append(out, hi<<4|lo)
+				i += 2
+				continue
+			}
+		}
+		out = append(out, b[i])
+	}
+	return string(out)
+}
+
+// skipInlineImage 跳过内容流中 BI ... ID 之后的内联图片数据，直到 EI
+func (l *pdfLexer) skipInlineImage() {
+	for {
+		tok := l.token()
+		if tok == nil {
+			return
+		}
+		if kw, ok := tok.(pdfKeyword); ok && kw == "ID" {
+			break
+		}
+	}
+	for l.pos+2 <= len(l.data) {
+		i := bytes.Index(l.data[l.pos:], []byte("EI"))
+		if i < 0 {
+			l.pos = len(l.data)
+			return
+		}
+		at := l.pos + i
+		l.pos = at + 2
+		before := at == 0 || isPDFSpace(l.data[at-1])
+		after := l.pos >= len(l.data) || isPDFSpace(l.data[l.pos])
+		if before && after {
+			return
+		}
+	}
+}
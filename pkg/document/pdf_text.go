@@ -0,0 +1,362 @@
+package document
+
+import (
+	"strings"
+	"unicode/utf16"
+)
+
+// tjSpaceThreshold TJ 数组中的负向位移（千分之一字号）超过该值视为单词间距
+const tjSpaceThreshold = -200
+
+// pdfFont 文本解码所需的字体信息
+type pdfFont struct {
+	// cmap ToUnicode 映射：字符编码（原始字节）-> Unicode 文本
+	cmap map[string]string
+	// codeLens 编码空间中出现的字节长度，从短到长尝试
+	codeLens []int
+	// composite Type0 字体，没有 ToUnicode 时无法还原文字
+	composite bool
+}
+
+// textExtractor 执行页面内容流中的文本操作符，按出现顺序输出文字
+type textExtractor struct {
+	doc   *pdfDocument
+	out   *strings.Builder
+	fonts map[pdfRef]*pdfFont
+	font  *pdfFont
+
+	lastY        float64
+	pendingBreak bool
+	pendingSpace bool
+}
+
+func newTextExtractor(doc *pdfDocument, out *strings.Builder) *textExtractor {
+	return &textExtractor{doc: doc, out: out, fonts: map[pdfRef]*pdfFont{}}
+}
+
+// run 执行内容流，depth 为表单对象（Form XObject）的嵌套深度
+func (ex *textExtractor) run(content []byte, resources pdfDict, depth int) {
+	if len(content) == 0 || depth > 4 {
+		return
+	}
+	lex := &pdfLexer{data: content}
+	var operands []any
+	for ex.out.Len() < MaxTextBytes {
+		tok := lex.token()
+		if tok == nil {
+			return
+		}
+		op, isOp := tok.(pdfKeyword)
+		if !isOp {
+			operands = append(operands, lex.valueFrom(tok))
+			continue
+		}
+
+		switch op {
+		case "BI":
+			lex.skipInlineImage()
+		case "Tf":
+			if len(operands) >= 2 {
+				if name, ok := operands[len(operands)-2].(pdfName); ok {
+					ex.font = ex.loadFont(resources, name)
+				}
+			}
+		case "Td", "TD":
+			if len(operands) >= 2 {
+				if ty, ok := operands[len(operands)-1].(float64); ok && ty != 0 {
+					ex.pendingBreak = true
+				}
+			}
+		case "Tm":
+			if len(operands) >= 6 {
+				if y, ok := operands[5].(float64); ok {
+					if y != ex.lastY {
+						ex.pendingBreak = true
+					}
+					ex.lastY = y
+				}
+			}
+		case "T*":
+			ex.pendingBreak = true
+		case "Tj":
+			if len(operands) >= 1 {
+				ex.show(operands[len(operands)-1])
+			}
+		case "'", "\"":
+			ex.pendingBreak = true
+			if len(operands) >= 1 {
+				ex.show(operands[len(operands)-1])
+			}
+		case "TJ":
+			if len(operands) >= 1 {
+				if arr, ok := operands[len(operands)-1].(pdfArray); ok {
+					for _, item := range arr {
+						if n, ok := item.(float64); ok && n < tjSpaceThreshold {
+							ex.pendingSpace = true
+							continue
+						}
+						ex.show(item)
+					}
+				}
+			}
+		case "Do":
+			if len(operands) >= 1 {
+				if name, ok := operands[len(operands)-1].(pdfName); ok {
+					ex.runForm(resources, name, depth)
+				}
+			}
+		}
+		operands = operands[:0]
+	}
+}
+
+// runForm 表单对象可以包含文字（如页眉页脚模板），使用自身的资源字典
+func (ex *textExtractor) runForm(resources pdfDict, name pdfName, depth int) {
+	ref, ok := ex.doc.dict(resources["XObject"])[string(name)].(pdfRef)
+	if !ok {
+		return
+	}
+	obj := ex.doc.objects[int(ref)]
+	if obj == nil {
+		return
+	}
+	d, _ := obj.value.(pdfDict)
+	if d["Subtype"] != pdfName("Form") {
+		return
+	}
+	formResources := ex.doc.dict(d["Resources"])
+	if formResources == nil {
+		formResources = resources
+	}
+	saved := ex.font
+	ex.run(obj.stream, formResources, depth+1)
+	ex.font = saved
+}
+
+func (ex *textExtractor) show(v any) {
+	s, ok := v.(pdfString)
+	if !ok || len(s) == 0 {
+		return
+	}
+	text := ex.decode(s)
+	if text == "" {
+		return
+	}
+	if ex.pendingBreak && ex.out.Len() > 0 {
+		ex.out.WriteByte('\n')
+	} else if ex.pendingSpace {
+		ex.out.WriteByte(' ')
+	}
+	ex.pendingBreak, ex.pendingSpace = false, false
+	ex.out.WriteString(text)
+}
+
+// decode 按当前字体把字符串解码为文本
+func (ex *textExtractor) decode(s pdfString) string {
+	font := ex.font
+	if font == nil || font.cmap == nil {
+		if font != nil && font.composite {
+			return ""
+		}
+		return decodeWinAnsi(s)
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		matched := false
+		for _, n := range font.codeLens {
+			if i+n > len(s) {
+				break
+			}
+			if text, ok := font.cmap[string(s[i:i+n])]; ok {
+				sb.WriteString(text)
+				i += n
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+		// 映射缺失：单字节字体按 WinAnsi 还原，多字节字体跳过该编码
+		if font.composite {
+			i += font.codeLens[len(font.codeLens)-1]
+		} else {
+			sb.WriteString(decodeWinAnsi(s[i : i+1]))
+			i++
+		}
+	}
+	return sb.String()
+}
+
+func (ex *textExtractor) loadFont(resources pdfDict, name pdfName) *pdfFont {
+	fontRef := ex.doc.dict(resources["Font"])[string(name)]
+	ref, isRef := fontRef.(pdfRef)
+	if isRef {
+		if f, ok := ex.fonts[ref]; ok {
+			return f
+		}
+	}
+
+	fd := ex.doc.dict(fontRef)
+	font := &pdfFont{composite: fd["Subtype"] == pdfName("Type0")}
+	if cmapData := ex.doc.stream(fd["ToUnicode"]); len(cmapData) > 0 {
+		font.cmap, font.codeLens = parseToUnicode(cmapData)
+	}
+	if len(font.codeLens) == 0 {
+		if font.composite {
+			font.codeLens = []int{2}
+		} else {
+			font.codeLens = []int{1}
+		}
+	}
+	if isRef {
+		ex.fonts[ref] = font
+	}
+	return font
+}
+
+// parseToUnicode 解析 ToUnicode CMap 的 codespacerange、bfchar 和 bfrange
+func parseToUnicode(data []byte) (map[string]string, []int) {
+	cmap := map[string]string{}
+	lens := map[int]bool{}
+	lex := &pdfLexer{data: data}
+
+	var operands []any
+	for {
+		tok := lex.token()
+		if tok == nil {
+			break
+		}
+		kw, ok := tok.(pdfKeyword)
+		if !ok {
+			operands = append(operands, lex.valueFrom(tok))
+			continue
+		}
+		switch kw {
+		case "endcodespacerange":
+			for i := 0; i+1 < len(operands); i += 2 {
+				if lo, ok := operands[i].(pdfString); ok && len(lo) > 0 {
+					lens[len(lo)] = true
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok1 := operands[i].(pdfString)
+				dst, ok2 := operands[i+1].(pdfString)
+				if ok1 && ok2 {
+					cmap[string(src)] = decodeUTF16BE(dst)
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lo, ok1 := operands[i].(pdfString)
+				hi, ok2 := operands[i+1].(pdfString)
+				if !ok1 || !ok2 || len(lo) != len(hi) || len(lo) == 0 || len(lo) > 4 {
+					continue
+				}
+				addBFRange(cmap, lo, hi, operands[i+2])
+			}
+		}
+		operands = operands[:0]
+	}
+
+	var codeLens []int
+	for n := 1; n <= 4; n++ {
+		if lens[n] {
+			codeLens = append(codeLens, n)
+		}
+	}
+	if len(codeLens) == 0 {
+		for code := range cmap {
+			lens[len(code)] = true
+		}
+		for n := 1; n <= 4; n++ {
+			if lens[n] {
+				codeLens = append(codeLens, n)
+			}
+		}
+	}
+	return cmap, codeLens
+}
+
+// addBFRange 目标为字符串时依次递增最后一个字符，为数组时逐个对应
+func addBFRange(cmap map[string]string, lo, hi pdfString, dst any) {
+	start, end := codeValue(lo), codeValue(hi)
+	if end < start || end-start > 0xFFFF {
+		return
+	}
+	for offset := uint32(0); offset <= end-start; offset++ {
+		key := codeBytes(start+offset, len(lo))
+		switch d := dst.(type) {
+		case pdfString:
+			units := utf16Units(d)
+			if len(units) == 0 {
+				return
+			}
+			units[len(units)-1] += uint16(offset)
+			cmap[key] = string(utf16.Decode(units))
+		case pdfArray:
+			if int(offset) < len(d) {
+				if s, ok := d[int(offset)].(pdfString); ok {
+					cmap[key] = decodeUTF16BE(s)
+				}
+			}
+		}
+	}
+}
+
+func codeValue(b []byte) uint32 {
+	var v uint32
+	for _, c := range b {
+		v = v<<8 | uint32(c)
+	}
+	return v
+}
+
+func codeBytes(v uint32, n int) string {
+	b := make([]byte, n)
+	for i := n - 1; i >= 0; i-- {
+		b[i] = byte(v)
+		v >>= 8
+	}
+	return string(b)
+}
+
+func utf16Units(b []byte) []uint16 {
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	return units
+}
+
+func decodeUTF16BE(b []byte) string {
+	return string(utf16.Decode(utf16Units(b)))
+}
+
+// winAnsiHigh WinAnsiEncoding 中 0x80-0x9F 与 Latin-1 不同的字符
+var winAnsiHigh = map[byte]rune{
+	0x80: '€', 0x82: '‚', 0x83: 'ƒ', 0x84: '„', 0x85: '…', 0x86: '†', 0x87: '‡',
+	0x88: 'ˆ', 0x89: '‰', 0x8A: 'Š', 0x8B: '‹', 0x8C: 'Œ', 0x8E: 'Ž',
+	0x91: '‘', 0x92: '’', 0x93: '“', 0x94: '”', 0x95: '•', 0x96: '–', 0x97: '—',
+	0x98: '˜', 0x99: '™', 0x9A: 'š', 0x9B: '›', 0x9C: 'œ', 0x9E: 'ž', 0x9F: 'Ÿ',
+}
+
+// decodeWinAnsi 没有 ToUnicode 的简单字体按 WinAnsiEncoding 解码，控制字符丢弃
+func decodeWinAnsi(b []byte) string {
+	var sb strings.Builder
+	for _, c := range b {
+		switch {
+		case c >= 0x20 && c < 0x7F, c >= 0xA0:
+			sb.WriteRune(rune(c))
+		case c == '\t' || c == '\n':
+			sb.WriteByte(c)
+		default:
+			if r, ok := winAnsiHigh[c]; ok {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,141 @@
+package document
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	previewMargin     = 28
+	previewBandHeight = 64
+	previewLineHeight = 18
+	previewBarHeight  = 8
+	// previewLineUnits 一整行对应的字符宽度单位（全角字符计 2）
+	previewLineUnits = 64
+)
+
+var (
+	previewBackground = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	previewBar        = color.RGBA{R: 0xd1, G: 0xd5, B: 0xdb, A: 0xff}
+	previewGrid       = color.RGBA{R: 0xe5, G: 0xe7, B: 0xeb, A: 0xff}
+	previewLabel      = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+
+	previewTypeColors = map[string]color.RGBA{
+		TypePDF:          {R: 0xdc, G: 0x26, B: 0x26, A: 0xff},
+		TypeWord:         {R: 0x25, G: 0x63, B: 0xeb, A: 0xff},
+		TypeSpreadsheet:  {R: 0x16, G: 0xa3, B: 0x4a, A: 0xff},
+		TypePresentation: {R: 0xea, G: 0x58, B: 0x0c, A: 0xff},
+		TypeText:         {R: 0x4b, G: 0x55, B: 0x63, A: 0xff},
+	}
+
+	labelFace     font.Face
+	labelFaceOnce sync.Once
+)
+
+// PlaceholderPreview 无法渲染首页时使用的预览图：按类型着色的标题栏加格式名，
+// 正文用灰色线条按提取文本的行长度示意版面，不依赖中文字体
+func PlaceholderPreview(info *Info) []byte {
+	w, h := 480, 680
+	if info.Type == TypePresentation {
+		w, h = 640, 360
+	}
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: previewBackground}, image.Point{}, draw.Src)
+
+	band, ok := previewTypeColors[info.Type]
+	if !ok {
+		band = previewTypeColors[TypeText]
+	}
+	draw.Draw(img, image.Rect(0, 0, w, previewBandHeight), &image.Uniform{C: band}, image.Point{}, draw.Src)
+	drawLabel(img, strings.ToUpper(info.Format), previewMargin, previewBandHeight/2+10)
+
+	body := image.Rect(previewMargin, previewBandHeight+previewMargin, w-previewMargin, h-previewMargin)
+	if info.Type == TypeSpreadsheet {
+		drawGrid(img, body)
+	}
+	drawTextBars(img, body, info.Text)
+
+	var buf bytes.Buffer
+	_ = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85})
+	return buf.Bytes()
+}
+
+func drawLabel(img draw.Image, label string, x, y int) {
+	labelFaceOnce.Do(func() {
+		f, err := opentype.Parse(gobold.TTF)
+		if err != nil {
+			return
+		}
+		labelFace, _ = opentype.NewFace(f, &opentype.FaceOptions{Size: 28, DPI: 72, Hinting: font.HintingFull})
+	})
+	if labelFace == nil || label == "" {
+		return
+	}
+	d := &font.Drawer{Dst: img, Src: &image.Uniform{C: previewLabel}, Face: labelFace, Dot: fixed.P(x, y)}
+	d.DrawString(label)
+}
+
+func drawGrid(img draw.Image, body image.Rectangle) {
+	line := &image.Uniform{C: previewGrid}
+	for y := body.Min.Y; y <= body.Max.Y; y += previewLineHeight + 6 {
+		draw.Draw(img, image.Rect(body.Min.X, y, body.Max.X, y+1), line, image.Point{}, draw.Src)
+	}
+	for x := body.Min.X; x <= body.Max.X; x += 96 {
+		draw.Draw(img, image.Rect(x, body.Min.Y, x+1, body.Max.Y), line, image.Point{}, draw.Src)
+	}
+}
+
+// drawTextBars 每行文本画一条与其长度成比例的横条，超长的行折成多条，空行留出段落间距
+func drawTextBars(img draw.Image, body image.Rectangle, text string) {
+	lines := strings.Split(text, "\n")
+	if strings.TrimSpace(text) == "" {
+		lines = []string{strings.Repeat("x", previewLineUnits), strings.Repeat("x", previewLineUnits*3/4), "",
+			strings.Repeat("x", previewLineUnits), strings.Repeat("x", previewLineUnits/2)}
+	}
+
+	bar := &image.Uniform{C: previewBar}
+	y := body.Min.Y
+	for _, line := range lines {
+		units := displayUnits(line)
+		if units == 0 {
+			y += previewLineHeight / 2
+			continue
+		}
+		for units > 0 {
+			if y+previewBarHeight > body.Max.Y {
+				return
+			}
+			width := body.Dx() * min(units, previewLineUnits) / previewLineUnits
+			draw.Draw(img, image.Rect(body.Min.X, y, body.Min.X+max(width, 12), y+previewBarHeight), bar, image.Point{}, draw.Src)
+			units -= previewLineUnits
+			y += previewLineHeight
+		}
+	}
+}
+
+// displayUnits 行的显示宽度：全角字符计 2，半角计 1，首尾空白不计
+func displayUnits(line string) int {
+	units := 0
+	for _, r := range strings.TrimSpace(line) {
+		if r == '\t' {
+			units += 4
+		} else if unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hangul, r) ||
+			unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) || (r >= 0xFF00 && r <= 0xFFEF) {
+			units += 2
+		} else {
+			units++
+		}
+	}
+	return units
+}
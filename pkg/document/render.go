@@ -0,0 +1,136 @@
+package document
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"pixelpunk/pkg/config"
+	"pixelpunk/pkg/logger"
+)
+
+const (
+	// renderTimeout pdftoppm 渲染单页的超时时间
+	renderTimeout = 30 * time.Second
+	// convertTimeout LibreOffice 转换为 PDF 的超时时间，冷启动较慢
+	convertTimeout = 90 * time.Second
+)
+
+// ErrNoRenderer 没有可用的渲染工具，调用方应使用占位预览图
+var ErrNoRenderer = errors.New("document: no renderer available")
+
+var (
+	pdftoppmPath string
+	sofficePath  string
+	lookupOnce   sync.Once
+)
+
+// lookupTools 按配置查找渲染工具，两者都是可选依赖
+func lookupTools() {
+	lookupOnce.Do(func() {
+		cfg := config.GetConfig().Document
+		if cfg.PdftoppmPath != "" {
+			if path, err := exec.LookPath(cfg.PdftoppmPath); err == nil {
+				pdftoppmPath = path
+				logger.Info("pdftoppm 已启用: %s", path)
+			} else {
+				logger.Info("未找到 pdftoppm（%s），文档缩略图将使用占位图", cfg.PdftoppmPath)
+			}
+		}
+		if cfg.SofficePath != "" && pdftoppmPath != "" {
+			if path, err := exec.LookPath(cfg.SofficePath); err == nil {
+				sofficePath = path
+				logger.Info("LibreOffice 已启用: %s", path)
+			} else {
+				logger.Info("未找到 LibreOffice（%s），Office 文档缩略图将使用占位图", cfg.SofficePath)
+			}
+		}
+	})
+}
+
+// CanRender 是否可以渲染该格式文档的首页
+func CanRender(format string) bool {
+	lookupTools()
+	switch TypeOf(format) {
+	case TypePDF:
+		return pdftoppmPath != ""
+	case TypeWord, TypeSpreadsheet, TypePresentation:
+		return sofficePath != ""
+	}
+	return false
+}
+
+// RenderFirstPage 渲染文档首页为 JPEG，宽度不超过 maxWidth
+// PDF 直接用 pdftoppm 渲染；Office 文档先用 LibreOffice 转为 PDF
+func RenderFirstPage(ctx context.Context, data []byte, format string, maxWidth int) ([]byte, error) {
+	if !CanRender(format) {
+		return nil, ErrNoRenderer
+	}
+	dir, err := os.MkdirTemp("", "pixelpunk-doc-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "input."+format)
+	if err := os.WriteFile(input, data, 0o600); err != nil {
+		return nil, err
+	}
+	if TypeOf(format) != TypePDF {
+		if input, err = convertToPDF(ctx, dir, input); err != nil {
+			return nil, err
+		}
+	}
+	return renderPDFPage(ctx, dir, input, maxWidth)
+}
+
+func convertToPDF(ctx context.Context, dir, input string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, convertTimeout)
+	defer cancel()
+
+	// 独立的用户配置目录，避免与其他 LibreOffice 实例争用配置锁
+	profile := "-env:UserInstallation=file://" + filepath.ToSlash(filepath.Join(dir, "profile"))
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, sofficePath, profile, "--headless", "--norestore",
+		"--convert-to", "pdf", "--outdir", dir, input)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("document: soffice failed: %w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	output := filepath.Join(dir, "input.pdf")
+	if _, err := os.Stat(output); err != nil {
+		return "", fmt.Errorf("document: soffice produced no pdf: %s", bytes.TrimSpace(stderr.Bytes()))
+	}
+	return output, nil
+}
+
+func renderPDFPage(ctx context.Context, dir, input string, maxWidth int) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, renderTimeout)
+	defer cancel()
+
+	outRoot := filepath.Join(dir, "page")
+	args := []string{"-f", "1", "-l", "1", "-singlefile", "-jpeg"}
+	if maxWidth > 0 {
+		args = append(args, "-scale-to-x", strconv.Itoa(maxWidth), "-scale-to-y", "-1")
+	}
+	args = append(args, input, outRoot)
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, pdftoppmPath, args...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("document: pdftoppm failed: %w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	page, err := os.ReadFile(outRoot + ".jpg")
+	if err != nil || len(page) == 0 {
+		return nil, fmt.Errorf("document: pdftoppm produced no image")
+	}
+	return page, nil
+}
@@ -0,0 +1,44 @@
+package document
+
+import (
+	"bytes"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// extractPlainText 纯文本按 UTF-8（可带 BOM）、UTF-16（带 BOM）或 GB18030 解码
+func extractPlainText(data []byte, info *Info) error {
+	if bytes.IndexByte(data[:min(len(data), 8192)], 0) >= 0 && !hasUTF16BOM(data) {
+		return ErrCorrupted
+	}
+	info.Text = decodeText(data)
+	return nil
+}
+
+func hasUTF16BOM(data []byte) bool {
+	return len(data) >= 2 && ((data[0] == 0xFE && data[1] == 0xFF) || (data[0] == 0xFF && data[1] == 0xFE))
+}
+
+func decodeText(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return string(data[3:])
+	case len(data) >= 2 && data[0] == 0xFE && data[1] == 0xFF:
+		return decodeUTF16BE(data[2:])
+	case len(data) >= 2 && data[0] == 0xFF && data[1] == 0xFE:
+		// UTF-16LE：交换字节序后按 BE 解码
+		swapped := make([]byte, len(data)-2)
+		for i := 2; i+1 < len(data); i += 2 {
+			swapped[i-2], swapped[i-1] = data[i+1], data[i]
+		}
+		return decodeUTF16BE(swapped)
+	case utf8.Valid(data):
+		return string(data)
+	}
+	// Windows 中文环境下保存的文本文件常见 GBK/GB18030 编码
+	if decoded, err := simplifiedchinese.GB18030.NewDecoder().Bytes(data); err == nil {
+		return string(decoded)
+	}
+	return string(bytes.ToValidUTF8(data, nil))
+}
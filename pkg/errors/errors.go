@@ -38,6 +38,9 @@ const (
 	CodeTokenExpired      ErrorCode = 1010
 	CodeTokenUsed         ErrorCode = 1011
 
+	CodeTwoFactorInvalid       ErrorCode = 1012
+	CodeTwoFactorSetupRequired ErrorCode = 1013
+
 	CodeDBConnectionFailed ErrorCode = 2000
 	CodeDBQueryFailed      ErrorCode = 2001
 	CodeDBCreateFailed     ErrorCode = 2002
@@ -125,6 +128,9 @@ var errorCodeToHTTPStatus = map[ErrorCode]int{
 	CodeTokenExpired:      400,
 	CodeTokenUsed:         400,
 
+	CodeTwoFactorInvalid:       400,
+	CodeTwoFactorSetupRequired: 403,
+
 	CodeDBConnectionFailed: 500,
 	CodeDBQueryFailed:      500,
 	CodeDBCreateFailed:     500,
@@ -209,6 +215,9 @@ var errorCodeToMessage = map[ErrorCode]string{
 	CodeTokenExpired:      "令牌已过期",
 	CodeTokenUsed:         "令牌已被使用",
 
+	CodeTwoFactorInvalid:       "两步验证码无效",
+	CodeTwoFactorSetupRequired: "请先启用两步验证",
+
 	CodeDBConnectionFailed: "数据库连接失败",
 	CodeDBQueryFailed:      "数据查询失败",
 	CodeDBCreateFailed:     "数据创建失败",
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	exif "github.com/dsoprea/go-exif/v3"
+	exifcommon "github.com/dsoprea/go-exif/v3/common"
 )
 
 // FileEXIFData 统一的 EXIF 数据结构
@@ -233,13 +234,13 @@ func extractFromEntries(entries []exif.ExifTag, result *FileEXIFData) {
 			result.ImageDescription = strings.TrimSpace(tagValue)
 
 		case "GPSLatitude":
-			if lat := parseGPSFromFormatted(tagValue); lat != nil {
+			if lat := parseGPSValue(entry.Value, tagValue); lat != nil {
 				result.GPSLatitude = lat
 			}
 		case "GPSLatitudeRef":
 			result.GPSLatitudeRef = strings.TrimSpace(tagValue)
 		case "GPSLongitude":
-			if lon := parseGPSFromFormatted(tagValue); lon != nil {
+			if lon := parseGPSValue(entry.Value, tagValue); lon != nil {
 				result.GPSLongitude = lon
 			}
 		case "GPSLongitudeRef":
@@ -313,6 +314,27 @@ func parseDateTime(timeStr string) (time.Time, error) {
 	return time.Parse("2006:01:02 15:04:05", timeStr)
 }
 
+// parseGPSValue 解析 GPS 坐标，优先使用原始的度分秒有理数
+// FormattedFirst 只包含第一个分量（如 "31/1"），无法得到完整坐标
+func parseGPSValue(value interface{}, formatted string) *float64 {
+	if rationals, ok := value.([]exifcommon.Rational); ok && len(rationals) > 0 {
+		var val float64
+		scale := 1.0
+		for i, r := range rationals {
+			if i >= 3 {
+				break
+			}
+			if r.Denominator == 0 {
+				return nil
+			}
+			val += float64(r.Numerator) / float64(r.Denominator) / scale
+			scale *= 60
+		}
+		return &val
+	}
+	return parseGPSFromFormatted(formatted)
+}
+
 // parseGPSFromFormatted 从格式化的 GPS 字符串解析坐标
 func parseGPSFromFormatted(s string) *float64 {
 	// 格式可能是: "39.9042" 或 "39deg 54' 15.12\"" 等
@@ -0,0 +1,385 @@
+package exif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+)
+
+// ScrubOptions 元数据清理选项
+type ScrubOptions struct {
+	StripAll    bool // 移除全部 EXIF / XMP / 文本元数据，JPEG 仅保留方向
+	StripGPS    bool // 清除 GPS 信息
+	StripSerial bool // 清除机身、镜头序列号、机主姓名和厂商私有数据
+}
+
+// IsZero 是否无需清理
+func (o ScrubOptions) IsZero() bool {
+	return !o.StripAll && !o.StripGPS && !o.StripSerial
+}
+
+const (
+	tagOrientation        = 0x0112
+	tagExifIFDPointer     = 0x8769
+	tagGPSIFDPointer      = 0x8825
+	tagMakerNote          = 0x927C
+	tagCameraOwnerName    = 0xA430
+	tagBodySerialNumber   = 0xA431
+	tagLensSerialNumber   = 0xA435
+	tagDNGCameraSerialNum = 0xC62F
+
+	maxIFDEntries = 1024
+)
+
+var (
+	exifHeader        = []byte("Exif\x00\x00")
+	xmpHeader         = []byte("http://ns.adobe.com/xap/1.0/\x00")
+	xmpExtendedHeader = []byte("http://ns.adobe.com/xmp/extension/\x00")
+	pngSignature      = []byte("\x89PNG\r\n\x1a\n")
+)
+
+// Scrub 按选项清理图片中的元数据，支持 JPEG / PNG / WebP / TIFF，其他格式原样返回
+// 清理 GPS 或序列号时在原位置清零，不改变 EXIF 结构；XMP 中也可能包含这些信息，只要需要清理就整体移除
+// TIFF 的元数据就是文件结构本身，清理全部时只能清除 GPS 和序列号
+func Scrub(data []byte, opts ScrubOptions) ([]byte, error) {
+	if opts.IsZero() {
+		return data, nil
+	}
+	switch {
+	case len(data) > 3 && data[0] == 0xFF && data[1] == 0xD8 && data[2] == 0xFF:
+		return scrubJPEG(data, opts)
+	case bytes.HasPrefix(data, pngSignature):
+		return scrubPNG(data, opts)
+	case len(data) > 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return scrubWebP(data, opts)
+	case len(data) > 8 && (string(data[:4]) == "II*\x00" || string(data[:4]) == "MM\x00*"):
+		out := append([]byte(nil), data...)
+		scrubTIFF(out, opts)
+		return out, nil
+	}
+	return data, nil
+}
+
+func scrubJPEG(data []byte, opts ScrubOptions) ([]byte, error) {
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:2]...)
+	pos := 2
+
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return nil, errInvalidStructure("JPEG")
+		}
+		marker := data[pos+1]
+		if marker == 0xFF { // 填充字节
+			pos++
+			continue
+		}
+		if marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7) {
+			out = append(out, data[pos:pos+2]...)
+			pos += 2
+			continue
+		}
+		if marker == 0xDA || marker == 0xD9 { // 图像数据开始后原样保留
+			return append(out, data[pos:]...), nil
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos+2 : pos+4]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			return nil, errInvalidStructure("JPEG")
+		}
+		payload := data[pos+4 : end]
+
+		switch {
+		case marker == 0xE1 && bytes.HasPrefix(payload, exifHeader):
+			if opts.StripAll {
+				if orientation := readOrientation(payload[len(exifHeader):]); orientation > 1 {
+					out = appendJPEGSegment(out, 0xE1, buildOrientationEXIF(orientation))
+				}
+			} else {
+				segment := append([]byte(nil), data[pos:end]...)
+				scrubTIFF(segment[4+len(exifHeader):], opts)
+				out = append(out, segment...)
+			}
+		case marker == 0xE1 && (bytes.HasPrefix(payload, xmpHeader) || bytes.HasPrefix(payload, xmpExtendedHeader)):
+			// 丢弃 XMP
+		case opts.StripAll && (marker == 0xED || marker == 0xFE):
+			// 丢弃 IPTC 和注释
+		default:
+			out = append(out, data[pos:end]...)
+		}
+		pos = end
+	}
+	return append(out, data[pos:]...), nil
+}
+
+func appendJPEGSegment(out []byte, marker byte, payload []byte) []byte {
+	out = append(out, 0xFF, marker)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(payload)+2))
+	return append(out, payload...)
+}
+
+func scrubPNG(data []byte, opts ScrubOptions) ([]byte, error) {
+	out := make([]byte, 0, len(data))
+	out = append(out, pngSignature...)
+	pos := len(pngSignature)
+
+	for pos+12 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos : pos+4]))
+		end := pos + 12 + length
+		if length < 0 || end > len(data) {
+			return nil, errInvalidStructure("PNG")
+		}
+		chunkType := string(data[pos+4 : pos+8])
+		chunkData := data[pos+8 : pos+8+length]
+
+		switch chunkType {
+		case "eXIf":
+			if opts.StripAll {
+				break
+			}
+			chunk := append([]byte(nil), data[pos:end]...)
+			tiff := chunk[8 : 8+length]
+			if bytes.HasPrefix(tiff, exifHeader) {
+				tiff = tiff[len(exifHeader):]
+			}
+			scrubTIFF(tiff, opts)
+			binary.BigEndian.PutUint32(chunk[8+length:], crc32.ChecksumIEEE(chunk[4:8+length]))
+			out = append(out, chunk...)
+		case "tEXt", "zTXt", "iTXt":
+			if opts.StripAll || isPNGMetadataKeyword(chunkData) {
+				break
+			}
+			out = append(out, data[pos:end]...)
+		default:
+			out = append(out, data[pos:end]...)
+		}
+
+		pos = end
+		if chunkType == "IEND" {
+			break
+		}
+	}
+	return append(out, data[pos:]...), nil
+}
+
+// isPNGMetadataKeyword 文本块是否保存了 XMP 或 EXIF 原始数据（ImageMagick 等工具会写入）
+func isPNGMetadataKeyword(chunkData []byte) bool {
+	keyword := chunkData
+	if i := bytes.IndexByte(chunkData, 0); i >= 0 {
+		keyword = chunkData[:i]
+	}
+	return string(keyword) == "XML:com.adobe.xmp" || bytes.HasPrefix(keyword, []byte("Raw profile type"))
+}
+
+func scrubWebP(data []byte, opts ScrubOptions) ([]byte, error) {
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:12]...)
+	pos := 12
+	vp8xFlags := -1
+	removedFlags := byte(0)
+
+	for pos+8 <= len(data) {
+		fourCC := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		end := pos + 8 + size + size&1
+		if size < 0 || pos+8+size > len(data) {
+			return nil, errInvalidStructure("WebP")
+		}
+		if end > len(data) {
+			end = len(data)
+		}
+
+		switch fourCC {
+		case "VP8X":
+			vp8xFlags = len(out) + 8
+			out = append(out, data[pos:end]...)
+		case "EXIF":
+			if opts.StripAll {
+				removedFlags |= 0x08
+				break
+			}
+			chunk := append([]byte(nil), data[pos:end]...)
+			tiff := chunk[8 : 8+size]
+			if bytes.HasPrefix(tiff, exifHeader) {
+				tiff = tiff[len(exifHeader):]
+			}
+			scrubTIFF(tiff, opts)
+			out = append(out, chunk...)
+		case "XMP ":
+			removedFlags |= 0x04
+		default:
+			out = append(out, data[pos:end]...)
+		}
+		pos = end
+	}
+	out = append(out, data[pos:]...)
+
+	if vp8xFlags >= 0 && vp8xFlags < len(out) {
+		out[vp8xFlags] &^= removedFlags
+	}
+	binary.LittleEndian.PutUint32(out[4:8], uint32(len(out)-8))
+	return out, nil
+}
+
+// scrubTIFF 在原位置清除 TIFF 结构中的 GPS 和序列号信息
+func scrubTIFF(tiff []byte, opts ScrubOptions) {
+	order, ifd0, ok := tiffHeader(tiff)
+	if !ok {
+		return
+	}
+
+	visited := make(map[uint32]bool)
+	for offset := ifd0; offset != 0 && !visited[offset]; {
+		visited[offset] = true
+		next, ok := walkIFD(tiff, order, offset, func(tag uint16, entry int) {
+			switch tag {
+			case tagGPSIFDPointer:
+				if opts.StripGPS || opts.StripAll {
+					clearIFD(tiff, order, order.Uint32(tiff[entry+8:]), visited)
+				}
+			case tagExifIFDPointer:
+				if opts.StripSerial || opts.StripAll {
+					scrubExifIFD(tiff, order, order.Uint32(tiff[entry+8:]), visited)
+				}
+			case tagDNGCameraSerialNum:
+				if opts.StripSerial || opts.StripAll {
+					clearEntryValue(tiff, order, entry)
+				}
+			}
+		})
+		if !ok {
+			return
+		}
+		offset = next
+	}
+}
+
+func scrubExifIFD(tiff []byte, order binary.ByteOrder, offset uint32, visited map[uint32]bool) {
+	if visited[offset] {
+		return
+	}
+	visited[offset] = true
+	walkIFD(tiff, order, offset, func(tag uint16, entry int) {
+		switch tag {
+		case tagMakerNote, tagCameraOwnerName, tagBodySerialNumber, tagLensSerialNumber:
+			clearEntryValue(tiff, order, entry)
+		}
+	})
+}
+
+// clearIFD 清零 IFD 的全部条目数据，并将条目数置为0
+func clearIFD(tiff []byte, order binary.ByteOrder, offset uint32, visited map[uint32]bool) {
+	if visited[offset] {
+		return
+	}
+	visited[offset] = true
+	count := 0
+	_, ok := walkIFD(tiff, order, offset, func(tag uint16, entry int) {
+		clearEntryValue(tiff, order, entry)
+		count++
+	})
+	if !ok {
+		return
+	}
+	start := int(offset)
+	clear(tiff[start : start+2+count*12+4])
+}
+
+// walkIFD 遍历 IFD 条目，返回下一个 IFD 的偏移
+func walkIFD(tiff []byte, order binary.ByteOrder, offset uint32, fn func(tag uint16, entry int)) (uint32, bool) {
+	start := int(offset)
+	if offset == 0 || start+2 > len(tiff) {
+		return 0, false
+	}
+	count := int(order.Uint16(tiff[start:]))
+	if count > maxIFDEntries || start+2+count*12+4 > len(tiff) {
+		return 0, false
+	}
+	for i := 0; i < count; i++ {
+		entry := start + 2 + i*12
+		fn(order.Uint16(tiff[entry:]), entry)
+	}
+	return order.Uint32(tiff[start+2+count*12:]), true
+}
+
+// clearEntryValue 清零条目的值，条目本身保留
+func clearEntryValue(tiff []byte, order binary.ByteOrder, entry int) {
+	size := tiffTypeSize(order.Uint16(tiff[entry+2:])) * int(order.Uint32(tiff[entry+4:]))
+	if size <= 4 {
+		clear(tiff[entry+8 : entry+12])
+		return
+	}
+	valueOffset := int(order.Uint32(tiff[entry+8:]))
+	if valueOffset < 8 || valueOffset+size > len(tiff) || size < 0 {
+		return
+	}
+	clear(tiff[valueOffset : valueOffset+size])
+}
+
+func tiffTypeSize(typ uint16) int {
+	switch typ {
+	case 1, 2, 6, 7: // BYTE, ASCII, SBYTE, UNDEFINED
+		return 1
+	case 3, 8: // SHORT, SSHORT
+		return 2
+	case 4, 9, 11, 13: // LONG, SLONG, FLOAT, IFD
+		return 4
+	case 5, 10, 12: // RATIONAL, SRATIONAL, DOUBLE
+		return 8
+	}
+	return 0
+}
+
+func tiffHeader(tiff []byte) (binary.ByteOrder, uint32, bool) {
+	if len(tiff) < 8 {
+		return nil, 0, false
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return nil, 0, false
+	}
+	if order.Uint16(tiff[2:]) != 42 {
+		return nil, 0, false
+	}
+	return order, order.Uint32(tiff[4:]), true
+}
+
+// readOrientation 读取 IFD0 中的方向，不存在时返回0
+func readOrientation(tiff []byte) uint16 {
+	order, ifd0, ok := tiffHeader(tiff)
+	if !ok {
+		return 0
+	}
+	var orientation uint16
+	walkIFD(tiff, order, ifd0, func(tag uint16, entry int) {
+		if tag == tagOrientation && order.Uint16(tiff[entry+2:]) == 3 {
+			orientation = order.Uint16(tiff[entry+8:])
+		}
+	})
+	return orientation
+}
+
+// buildOrientationEXIF 生成只包含方向的 EXIF 段，避免清理后图片显示方向错误
+func buildOrientationEXIF(orientation uint16) []byte {
+	buf := append([]byte(nil), exifHeader...)
+	buf = append(buf, 'M', 'M', 0, 42)
+	buf = binary.BigEndian.AppendUint32(buf, 8)
+	buf = binary.BigEndian.AppendUint16(buf, 1)
+	buf = binary.BigEndian.AppendUint16(buf, tagOrientation)
+	buf = binary.BigEndian.AppendUint16(buf, 3)
+	buf = binary.BigEndian.AppendUint32(buf, 1)
+	buf = binary.BigEndian.AppendUint16(buf, orientation)
+	buf = append(buf, 0, 0)
+	return binary.BigEndian.AppendUint32(buf, 0)
+}
+
+func errInvalidStructure(format string) error {
+	return fmt.Errorf("%s 文件结构无效，无法清理元数据", format)
+}
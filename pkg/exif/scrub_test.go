@@ -0,0 +1,167 @@
+package exif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// buildTestTIFF 生成包含相机、方向、序列号和 GPS 的 TIFF 数据（大端序）
+func buildTestTIFF() []byte {
+	be := binary.BigEndian
+	type entry struct {
+		tag, typ uint16
+		count    uint32
+		value    []byte
+	}
+	ascii := func(s string) []byte { return append([]byte(s), 0) }
+	rational := func(vals ...uint32) []byte {
+		var b []byte
+		for _, v := range vals {
+			b = be.AppendUint32(b, v)
+			b = be.AppendUint32(b, 1)
+		}
+		return b
+	}
+
+	buf := []byte{'M', 'M', 0, 42, 0, 0, 0, 8}
+	writeIFD := func(entries []entry) (start int, pointers map[uint16]int) {
+		start = len(buf)
+		pointers = make(map[uint16]int)
+		dataStart := start + 2 + len(entries)*12 + 4
+		var data []byte
+		buf = be.AppendUint16(buf, uint16(len(entries)))
+		for _, e := range entries {
+			buf = be.AppendUint16(buf, e.tag)
+			buf = be.AppendUint16(buf, e.typ)
+			buf = be.AppendUint32(buf, e.count)
+			pointers[e.tag] = len(buf)
+			if len(e.value) <= 4 {
+				buf = append(buf, append(e.value, make([]byte, 4-len(e.value))...)...)
+			} else {
+				buf = be.AppendUint32(buf, uint32(dataStart+len(data)))
+				data = append(data, e.value...)
+			}
+		}
+		buf = be.AppendUint32(buf, 0)
+		buf = append(buf, data...)
+		return start, pointers
+	}
+
+	_, ifd0 := writeIFD([]entry{
+		{0x010F, 2, 6, ascii("Canon")},
+		{tagOrientation, 3, 1, be.AppendUint16(nil, 6)},
+		{tagExifIFDPointer, 4, 1, []byte{0, 0, 0, 0}},
+		{tagGPSIFDPointer, 4, 1, []byte{0, 0, 0, 0}},
+	})
+	exifStart, _ := writeIFD([]entry{
+		{tagBodySerialNumber, 2, 9, ascii("SN123456")},
+	})
+	gpsStart, _ := writeIFD([]entry{
+		{0x0001, 2, 2, ascii("N")},
+		{0x0002, 5, 3, rational(31, 14, 0)},
+		{0x0003, 2, 2, ascii("E")},
+		{0x0004, 5, 3, rational(121, 28, 0)},
+	})
+	be.PutUint32(buf[ifd0[tagExifIFDPointer]:], uint32(exifStart))
+	be.PutUint32(buf[ifd0[tagGPSIFDPointer]:], uint32(gpsStart))
+	return buf
+}
+
+func buildTestJPEG(t *testing.T) []byte {
+	var encoded bytes.Buffer
+	if err := jpeg.Encode(&encoded, image.NewGray(image.Rect(0, 0, 8, 8)), nil); err != nil {
+		t.Fatal(err)
+	}
+	raw := encoded.Bytes()
+	app1 := appendJPEGSegment(nil, 0xE1, append(append([]byte(nil), exifHeader...), buildTestTIFF()...))
+	xmp := appendJPEGSegment(nil, 0xE1, append(append([]byte(nil), xmpHeader...), []byte("<x:xmpmeta/>")...))
+	out := append([]byte(nil), raw[:2]...)
+	out = append(out, app1...)
+	out = append(out, xmp...)
+	return append(out, raw[2:]...)
+}
+
+func buildTestPNG(t *testing.T) []byte {
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, image.NewGray(image.Rect(0, 0, 8, 8))); err != nil {
+		t.Fatal(err)
+	}
+	raw := encoded.Bytes()
+	tiff := buildTestTIFF()
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(tiff)))
+	chunk = append(chunk, "eXIf"...)
+	chunk = append(chunk, tiff...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+	ihdrEnd := len(pngSignature) + 12 + 13
+	out := append([]byte(nil), raw[:ihdrEnd]...)
+	out = append(out, chunk...)
+	return append(out, raw[ihdrEnd:]...)
+}
+
+// TestScrub 验证各清理选项对 EXIF 的影响
+func TestScrub(t *testing.T) {
+	for name, build := range map[string]func(*testing.T) []byte{"jpeg": buildTestJPEG, "png": buildTestPNG} {
+		data := build(t)
+		before, _ := ExtractEXIFFromBytes(data)
+		if before == nil || before.GPSLatitude == nil || before.SerialNumber == "" || before.Make != "Canon" {
+			t.Fatalf("%s: 测试数据的 EXIF 不完整: %+v", name, before)
+		}
+		if *before.GPSLatitude < 31.23 || *before.GPSLatitude > 31.24 || *before.GPSLongitude < 121.46 || *before.GPSLongitude > 121.47 {
+			t.Errorf("%s: GPS 坐标解析错误: %v, %v", name, *before.GPSLatitude, *before.GPSLongitude)
+		}
+
+		out, err := Scrub(data, ScrubOptions{StripGPS: true})
+		if err != nil {
+			t.Fatalf("%s: 清理 GPS 失败: %v", name, err)
+		}
+		after, _ := ExtractEXIFFromBytes(out)
+		if after == nil || after.GPSLatitude != nil || after.GPSLongitude != nil {
+			t.Errorf("%s: GPS 未清除: %+v", name, after)
+		} else if after.SerialNumber == "" || after.Make != "Canon" {
+			t.Errorf("%s: 清理 GPS 时误删其他信息: %+v", name, after)
+		}
+		if bytes.Contains(out, xmpHeader) {
+			t.Errorf("%s: XMP 未移除", name)
+		}
+
+		out, err = Scrub(data, ScrubOptions{StripSerial: true})
+		if err != nil {
+			t.Fatalf("%s: 清理序列号失败: %v", name, err)
+		}
+		after, _ = ExtractEXIFFromBytes(out)
+		if after == nil || after.SerialNumber != "" || after.GPSLatitude == nil {
+			t.Errorf("%s: 序列号清理结果错误: %+v", name, after)
+		}
+
+		out, err = Scrub(data, ScrubOptions{StripAll: true})
+		if err != nil {
+			t.Fatalf("%s: 清理全部失败: %v", name, err)
+		}
+		if after, _ = ExtractEXIFFromBytes(out); after != nil && (after.Make != "" || after.GPSLatitudeRef != "" || after.SerialNumber != "") {
+			t.Errorf("%s: 元数据未全部清除: %+v", name, after)
+		}
+
+		var decodeErr error
+		if name == "jpeg" {
+			if !bytes.Contains(out, buildOrientationEXIF(6)) {
+				t.Error("jpeg: 清理全部后应保留方向")
+			}
+			_, decodeErr = jpeg.Decode(bytes.NewReader(out))
+		} else {
+			_, decodeErr = png.Decode(bytes.NewReader(out))
+		}
+		if decodeErr != nil {
+			t.Errorf("%s: 清理后无法解码: %v", name, decodeErr)
+		}
+	}
+
+	other := []byte("GIF89a")
+	if out, err := Scrub(other, ScrubOptions{StripAll: true}); err != nil || !bytes.Equal(out, other) {
+		t.Error("不支持的格式应原样返回")
+	}
+}
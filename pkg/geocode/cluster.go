@@ -0,0 +1,96 @@
+package geocode
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	// MaxZoom 地图最大缩放级别，达到该级别后不再聚合
+	MaxZoom = 20
+	// clusterCellPx 聚合网格的边长（像素，按256像素的瓦片计算）
+	clusterCellPx = 64.0
+	tileSize      = 256.0
+)
+
+// Point 参与聚合的坐标点
+type Point struct {
+	ID        string
+	Latitude  float64
+	Longitude float64
+}
+
+// Cluster 聚合结果，Count 为1时即单个点
+type Cluster struct {
+	Latitude  float64 `json:"latitude"`  // 聚合内各点的中心
+	Longitude float64 `json:"longitude"` // 聚合内各点的中心
+	Count     int     `json:"count"`
+	ID        string  `json:"id"` // 代表点（聚合内的第一个点）
+	MinLat    float64 `json:"min_lat"`
+	MinLon    float64 `json:"min_lon"`
+	MaxLat    float64 `json:"max_lat"`
+	MaxLon    float64 `json:"max_lon"`
+}
+
+// ClusterPoints 按缩放级别进行网格聚合
+// 将坐标投影到 Web Mercator 像素坐标，落在同一个 64×64 像素网格内的点合并为一个聚合
+// 点的顺序决定代表点，结果按数量降序排列
+func ClusterPoints(points []Point, zoom int) []Cluster {
+	if zoom < 0 {
+		zoom = 0
+	}
+	if zoom > MaxZoom {
+		zoom = MaxZoom
+	}
+
+	type cellKey struct{ x, y int64 }
+	index := make(map[cellKey]int)
+	clusters := make([]Cluster, 0)
+	worldPx := tileSize * math.Exp2(float64(zoom))
+
+	for _, p := range points {
+		x, y := project(p.Latitude, p.Longitude, worldPx)
+		key := cellKey{int64(x / clusterCellPx), int64(y / clusterCellPx)}
+
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(clusters)
+			clusters = append(clusters, Cluster{
+				Latitude:  p.Latitude,
+				Longitude: p.Longitude,
+				Count:     1,
+				ID:        p.ID,
+				MinLat:    p.Latitude,
+				MinLon:    p.Longitude,
+				MaxLat:    p.Latitude,
+				MaxLon:    p.Longitude,
+			})
+			continue
+		}
+
+		c := &clusters[i]
+		c.Count++
+		// 增量计算中心点
+		c.Latitude += (p.Latitude - c.Latitude) / float64(c.Count)
+		c.Longitude += (p.Longitude - c.Longitude) / float64(c.Count)
+		c.MinLat = math.Min(c.MinLat, p.Latitude)
+		c.MinLon = math.Min(c.MinLon, p.Longitude)
+		c.MaxLat = math.Max(c.MaxLat, p.Latitude)
+		c.MaxLon = math.Max(c.MaxLon, p.Longitude)
+	}
+
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return clusters[i].Count > clusters[j].Count
+	})
+	return clusters
+}
+
+// project 经纬度转换为 Web Mercator 像素坐标
+func project(lat, lon, worldPx float64) (x, y float64) {
+	// Web Mercator 在 ±85.05° 之外没有定义
+	lat = math.Max(-85.05112878, math.Min(85.05112878, lat))
+	sinLat := math.Sin(lat * math.Pi / 180)
+	x = (lon + 180) / 360 * worldPx
+	y = (0.5 - math.Log((1+sinLat)/(1-sinLat))/(4*math.Pi)) * worldPx
+	return math.Min(math.Max(x, 0), worldPx-1), math.Min(math.Max(y, 0), worldPx-1)
+}
@@ -0,0 +1,199 @@
+package geocode
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 内置的城市数据只包含各国首都和主要城市，离线可用，精度到城市级别
+//
+//go:embed data/cities.csv
+var citiesCSV []byte
+
+//go:embed data/countries.csv
+var countriesCSV []byte
+
+// MaxDistanceKm 反向地理编码的最大匹配距离，超出时视为无法识别（如海上、无人区）
+const MaxDistanceKm = 200.0
+
+const earthRadiusKm = 6371.0
+
+// Place 坐标对应的地点
+type Place struct {
+	CountryCode string  `json:"country_code"` // ISO 3166-1 二位国家代码
+	Country     string  `json:"country"`      // 国家名称（优先中文）
+	City        string  `json:"city"`         // 最近的城市（优先中文）
+	Latitude    float64 `json:"latitude"`     // 城市中心纬度
+	Longitude   float64 `json:"longitude"`    // 城市中心经度
+
+	cityEn string
+}
+
+// Country 国家信息
+type Country struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+	En   string `json:"en"`
+}
+
+var (
+	places    []Place
+	countries map[string]Country
+	loadOnce  sync.Once
+)
+
+// load 惰性解析内置数据
+func load() {
+	loadOnce.Do(func() {
+		countries = make(map[string]Country)
+		for _, fields := range readCSV(countriesCSV) {
+			if len(fields) < 3 {
+				continue
+			}
+			countries[fields[0]] = Country{Code: fields[0], En: fields[1], Name: fields[2]}
+		}
+
+		for _, fields := range readCSV(citiesCSV) {
+			if len(fields) < 5 {
+				continue
+			}
+			lat, err1 := strconv.ParseFloat(fields[3], 64)
+			lon, err2 := strconv.ParseFloat(fields[4], 64)
+			if err1 != nil || err2 != nil {
+				continue
+			}
+			name := fields[2]
+			if name == "" {
+				name = fields[1]
+			}
+			country := countries[fields[0]]
+			places = append(places, Place{
+				CountryCode: fields[0],
+				Country:     country.Name,
+				City:        name,
+				Latitude:    lat,
+				Longitude:   lon,
+				cityEn:      fields[1],
+			})
+		}
+	})
+}
+
+func readCSV(data []byte) [][]string {
+	var rows [][]string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, ",")
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		rows = append(rows, fields)
+	}
+	return rows
+}
+
+// Reverse 反向地理编码，返回距离坐标最近的城市，超出 MaxDistanceKm 时返回nil
+func Reverse(lat, lon float64) *Place {
+	if !ValidCoordinate(lat, lon) {
+		return nil
+	}
+	load()
+
+	var best *Place
+	bestDistance := MaxDistanceKm
+	// 纬度1度约111km，先按纬度差粗筛
+	maxLatDelta := MaxDistanceKm/111.0 + 0.5
+	for i := range places {
+		p := &places[i]
+		if math.Abs(p.Latitude-lat) > maxLatDelta {
+			continue
+		}
+		if d := Distance(lat, lon, p.Latitude, p.Longitude); d <= bestDistance {
+			best = p
+			bestDistance = d
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	place := *best
+	return &place
+}
+
+// LookupCountry 按国家代码、中文名或英文名查找国家
+func LookupCountry(s string) (Country, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Country{}, false
+	}
+	load()
+
+	if c, ok := countries[strings.ToUpper(s)]; ok {
+		return c, true
+	}
+	for _, c := range countries {
+		if c.Name == s || strings.EqualFold(c.En, s) {
+			return c, true
+		}
+	}
+	return Country{}, false
+}
+
+// NormalizeCity 将城市的中文名或英文名统一为 Place.City 使用的名称，未收录的城市原样返回
+func NormalizeCity(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return s
+	}
+	load()
+
+	for i := range places {
+		if places[i].City == s || strings.EqualFold(places[i].cityEn, s) {
+			return places[i].City
+		}
+	}
+	return s
+}
+
+// ValidCoordinate 坐标是否在合法范围内
+func ValidCoordinate(lat, lon float64) bool {
+	return lat >= -90 && lat <= 90 && lon >= -180 && lon <= 180 &&
+		!math.IsNaN(lat) && !math.IsNaN(lon)
+}
+
+// Distance 计算两点间的球面距离（km）
+func Distance(lat1, lon1, lat2, lon2 float64) float64 {
+	rad := math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLon := (lon2 - lon1) * rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// BoundsAround 返回包含指定半径圆的经纬度矩形，用于数据库粗筛
+// 圆跨越经度180°或覆盖极点时经度范围取全部
+func BoundsAround(lat, lon, radiusKm float64) (minLat, minLon, maxLat, maxLon float64) {
+	latDelta := radiusKm / earthRadiusKm * 180 / math.Pi
+	minLat = math.Max(-90, lat-latDelta)
+	maxLat = math.Min(90, lat+latDelta)
+	if minLat <= -90 || maxLat >= 90 {
+		return minLat, -180, maxLat, 180
+	}
+
+	lonDelta := math.Asin(math.Min(1, math.Sin(radiusKm/earthRadiusKm)/math.Cos(lat*math.Pi/180))) * 180 / math.Pi
+	minLon, maxLon = lon-lonDelta, lon+lonDelta
+	if minLon < -180 || maxLon > 180 {
+		return minLat, -180, maxLat, 180
+	}
+	return minLat, minLon, maxLat, maxLon
+}
@@ -0,0 +1,109 @@
+package geocode
+
+import (
+	"math"
+	"testing"
+)
+
+// TestDataset 内置城市引用的国家必须都存在
+func TestDataset(t *testing.T) {
+	load()
+	if len(places) < 500 {
+		t.Fatalf("城市数据过少: %d", len(places))
+	}
+	for _, p := range places {
+		if p.Country == "" {
+			t.Errorf("城市 %s 的国家代码 %s 不存在", p.City, p.CountryCode)
+		}
+		if !ValidCoordinate(p.Latitude, p.Longitude) {
+			t.Errorf("城市 %s 坐标无效", p.City)
+		}
+	}
+}
+
+func TestReverse(t *testing.T) {
+	cases := []struct {
+		lat, lon float64
+		city     string
+		country  string
+	}{
+		{31.2336, 121.4667, "上海", "CN"},
+		{35.6586, 139.7454, "东京", "JP"},
+		{48.8584, 2.2945, "巴黎", "FR"},
+		{-33.8568, 151.2153, "悉尼", "AU"},
+		{40.7580, -73.9855, "纽约", "US"},
+	}
+	for _, c := range cases {
+		p := Reverse(c.lat, c.lon)
+		if p == nil || p.City != c.city || p.CountryCode != c.country {
+			t.Errorf("Reverse(%v, %v) = %+v, 期望 %s", c.lat, c.lon, p, c.city)
+		}
+	}
+
+	if p := Reverse(0, -140); p != nil {
+		t.Errorf("太平洋中部不应匹配城市: %+v", p)
+	}
+	if p := Reverse(91, 0); p != nil {
+		t.Error("非法坐标应返回nil")
+	}
+}
+
+func TestLookupCountry(t *testing.T) {
+	for _, s := range []string{"cn", "中国", "china"} {
+		if c, ok := LookupCountry(s); !ok || c.Code != "CN" {
+			t.Errorf("LookupCountry(%q) = %+v, %v", s, c, ok)
+		}
+	}
+	if _, ok := LookupCountry("atlantis"); ok {
+		t.Error("未知国家应返回false")
+	}
+
+	if city := NormalizeCity("shanghai"); city != "上海" {
+		t.Errorf("NormalizeCity(shanghai) = %s", city)
+	}
+	if city := NormalizeCity("小镇"); city != "小镇" {
+		t.Errorf("未收录的城市应原样返回: %s", city)
+	}
+}
+
+func TestBoundsAround(t *testing.T) {
+	minLat, minLon, maxLat, maxLon := BoundsAround(31.23, 121.47, 10)
+	if minLat > 31.15 || maxLat < 31.31 || minLon > 121.38 || maxLon < 121.56 {
+		t.Errorf("范围未包含半径: %v %v %v %v", minLat, minLon, maxLat, maxLon)
+	}
+	if d := Distance(31.23, 121.47, maxLat, 121.47); math.Abs(d-10) > 0.01 {
+		t.Errorf("纬度范围与半径不一致: %v", d)
+	}
+
+	_, minLon, _, maxLon = BoundsAround(0, 179.9, 50)
+	if minLon != -180 || maxLon != 180 {
+		t.Error("跨越经度180°时应取全部经度")
+	}
+}
+
+func TestClusterPoints(t *testing.T) {
+	points := []Point{
+		{ID: "a", Latitude: 31.230, Longitude: 121.470},
+		{ID: "b", Latitude: 31.240, Longitude: 121.480},
+		{ID: "c", Latitude: 31.231, Longitude: 121.471},
+		{ID: "d", Latitude: 39.904, Longitude: 116.407},
+	}
+
+	clusters := ClusterPoints(points, 3)
+	if len(clusters) != 2 || clusters[0].Count != 3 || clusters[0].ID != "a" {
+		t.Fatalf("低缩放级别聚合错误: %+v", clusters)
+	}
+	if clusters[0].MinLat != 31.230 || clusters[0].MaxLon != 121.480 {
+		t.Errorf("聚合范围错误: %+v", clusters[0])
+	}
+	if math.Abs(clusters[0].Latitude-31.2337) > 0.001 {
+		t.Errorf("聚合中心错误: %v", clusters[0].Latitude)
+	}
+
+	if clusters = ClusterPoints(points, MaxZoom); len(clusters) != 4 {
+		t.Errorf("最大缩放级别不应聚合: %d", len(clusters))
+	}
+	if clusters = ClusterPoints(nil, 5); len(clusters) != 0 {
+		t.Error("空输入应返回空结果")
+	}
+}
@@ -0,0 +1,105 @@
+package geoip
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"pixelpunk/pkg/config"
+	"pixelpunk/pkg/logger"
+	"sync"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// Location IP对应的地理位置信息
+type Location struct {
+	CountryCode string `json:"country_code"` // ISO 3166-1 二位国家代码
+	Country     string `json:"country"`      // 国家名称（优先中文）
+	City        string `json:"city"`         // 城市名称（仅City库可用）
+}
+
+// mmdbRecord 兼容 GeoLite2-Country / GeoLite2-City 的记录结构
+type mmdbRecord struct {
+	Country struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"country"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+}
+
+var (
+	reader   *maxminddb.Reader
+	initOnce sync.Once
+)
+
+// open 惰性打开数据库文件，文件不存在时静默降级
+func open() {
+	initOnce.Do(func() {
+		path := config.GetConfig().GeoIP.DBPath
+		if path == "" {
+			return
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(".", path)
+		}
+		if _, err := os.Stat(path); err != nil {
+			logger.Info("GeoIP数据库不存在，跳过地理位置解析: %s", path)
+			return
+		}
+
+		r, err := maxminddb.Open(path)
+		if err != nil {
+			logger.Warn("打开GeoIP数据库失败: %v", err)
+			return
+		}
+		reader = r
+		logger.Info("GeoIP数据库加载成功: %s (%s)", path, r.Metadata.DatabaseType)
+	})
+}
+
+// Enabled 是否已加载GeoIP数据库
+func Enabled() bool {
+	open()
+	return reader != nil
+}
+
+// Lookup 查询IP地理位置，数据库未加载或为内网地址时返回nil
+func Lookup(ip string) *Location {
+	open()
+	if reader == nil {
+		return nil
+	}
+
+	parsed := net.ParseIP(ip)
+	if parsed == nil || parsed.IsLoopback() || parsed.IsPrivate() || parsed.IsUnspecified() {
+		return nil
+	}
+
+	var record mmdbRecord
+	if err := reader.Lookup(parsed, &record); err != nil || record.Country.ISOCode == "" {
+		return nil
+	}
+
+	return &Location{
+		CountryCode: record.Country.ISOCode,
+		Country:     localizedName(record.Country.Names),
+		City:        localizedName(record.City.Names),
+	}
+}
+
+func localizedName(names map[string]string) string {
+	if name, ok := names["zh-CN"]; ok {
+		return name
+	}
+	return names["en"]
+}
+
+// Close 关闭数据库
+func Close() error {
+	if reader != nil {
+		return reader.Close()
+	}
+	return nil
+}
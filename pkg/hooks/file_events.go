@@ -0,0 +1,43 @@
+package hooks
+
+import (
+	"sync"
+)
+
+const (
+	FileEventUploaded = "uploaded"  // 文件上传完成
+	FileEventAITagged = "ai_tagged" // AI打标完成
+)
+
+// FileEventHook 定义文件事件钩子函数类型
+type FileEventHook func(event, fileID string)
+
+var (
+	fileEventHooks = make(map[string][]FileEventHook)
+	fileEventMutex sync.RWMutex
+)
+
+// RegisterFileEventHook 注册文件事件钩子
+// event: 事件名，为空表示所有事件
+func RegisterFileEventHook(event string, hook FileEventHook) {
+	fileEventMutex.Lock()
+	defer fileEventMutex.Unlock()
+
+	fileEventHooks[event] = append(fileEventHooks[event], hook)
+}
+
+// TriggerFileEvent 触发文件事件钩子，钩子需自行决定是否异步执行
+func TriggerFileEvent(event, fileID string) {
+	if fileID == "" {
+		return
+	}
+
+	fileEventMutex.RLock()
+	hooks := append([]FileEventHook{}, fileEventHooks[event]...)
+	hooks = append(hooks, fileEventHooks[""]...)
+	fileEventMutex.RUnlock()
+
+	for _, hook := range hooks {
+		hook(event, fileID)
+	}
+}
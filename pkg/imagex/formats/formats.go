@@ -7,8 +7,16 @@ import (
 // 默认支持的扩展名（带点）
 var defaultDotExtensions = []string{
 	".jpg", ".jpeg", ".png", ".gif", ".webp", ".bmp", ".svg", ".ico", ".apng", ".jp2", ".tiff", ".tif", ".tga", ".heic", ".heif",
+	".mp4", ".m4v", ".mov", ".webm",
+	".pdf", ".docx", ".xlsx", ".pptx", ".odt", ".ods", ".odp", ".txt", ".md", ".csv",
 }
 
+// 视频扩展名（带点），上传时不做图片处理
+var videoDotExtensions = []string{".mp4", ".m4v", ".mov", ".webm"}
+
+// 文档扩展名（带点），上传时提取文本并生成首页预览
+var documentDotExtensions = []string{".pdf", ".docx", ".xlsx", ".pptx", ".odt", ".ods", ".odp", ".txt", ".md", ".csv"}
+
 // 扩展名到MIME映射（不带点，小写）
 var extToMIME = map[string]string{
 	"jpg":  "image/jpeg",
@@ -26,6 +34,51 @@ var extToMIME = map[string]string{
 	"tga":  "image/x-tga",
 	"heic": "image/heic",
 	"heif": "image/heif",
+	"mp4":  "video/mp4",
+	"m4v":  "video/mp4",
+	"mov":  "video/quicktime",
+	"webm": "video/webm",
+	"pdf":  "application/pdf",
+	"docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	"xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+	"odt":  "application/vnd.oasis.opendocument.text",
+	"ods":  "application/vnd.oasis.opendocument.spreadsheet",
+	"odp":  "application/vnd.oasis.opendocument.presentation",
+	"txt":  "text/plain; charset=utf-8",
+	"md":   "text/markdown; charset=utf-8",
+	"csv":  "text/csv; charset=utf-8",
+}
+
+// MIME到首选扩展名的映射，用于给没有扩展名的远程文件补全扩展名
+var mimeToExt = map[string]string{
+	"image/jpeg":               "jpg",
+	"image/png":                "png",
+	"image/gif":                "gif",
+	"image/webp":               "webp",
+	"image/bmp":                "bmp",
+	"image/svg+xml":            "svg",
+	"image/x-icon":             "ico",
+	"image/vnd.microsoft.icon": "ico",
+	"image/apng":               "apng",
+	"image/jp2":                "jp2",
+	"image/tiff":               "tiff",
+	"image/x-tga":              "tga",
+	"image/heic":               "heic",
+	"image/heif":               "heif",
+	"video/mp4":                "mp4",
+	"video/quicktime":          "mov",
+	"video/webm":               "webm",
+	"application/pdf":          "pdf",
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document":   "docx",
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":         "xlsx",
+	"application/vnd.openxmlformats-officedocument.presentationml.presentation": "pptx",
+	"application/vnd.oasis.opendocument.text":                                   "odt",
+	"application/vnd.oasis.opendocument.spreadsheet":                            "ods",
+	"application/vnd.oasis.opendocument.presentation":                           "odp",
+	"text/plain":    "txt",
+	"text/markdown": "md",
+	"text/csv":      "csv",
 }
 
 // NormalizeFormat 规格化格式/扩展名（去点、转小写）
@@ -73,3 +126,34 @@ func IsSupported(formatOrExt string) bool {
 	}
 	return false
 }
+
+// IsVideo 检查给定格式/扩展名是否为支持的视频格式
+func IsVideo(formatOrExt string) bool {
+	f := NormalizeFormat(formatOrExt)
+	for _, e := range videoDotExtensions {
+		if strings.TrimPrefix(e, ".") == f {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDocument 检查给定格式/扩展名是否为支持的文档格式
+func IsDocument(formatOrExt string) bool {
+	f := NormalizeFormat(formatOrExt)
+	for _, e := range documentDotExtensions {
+		if strings.TrimPrefix(e, ".") == f {
+			return true
+		}
+	}
+	return false
+}
+
+// ExtensionFromContentType 根据MIME获取首选扩展名（不带点），未知类型返回空字符串
+func ExtensionFromContentType(contentType string) string {
+	mime := strings.ToLower(strings.TrimSpace(contentType))
+	if i := strings.Index(mime, ";"); i >= 0 {
+		mime = strings.TrimSpace(mime[:i])
+	}
+	return mimeToExt[mime]
+}
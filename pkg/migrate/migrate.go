@@ -0,0 +1,146 @@
+// Package migrate 按版本号顺序执行数据库迁移，支持回滚、校验和检查和只输出 SQL 的预览
+// 每个迁移由若干步骤组成，步骤可以是 SQL 语句，也可以是只通过传入连接操作数据库的 Go 代码
+package migrate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Step 迁移中的一个步骤
+type Step struct {
+	desc       string
+	rev        int
+	statements map[string]string // 数据库类型到 SQL 语句，空字符串为所有数据库通用
+	fn         func(*gorm.DB) error
+	previewDB  bool
+}
+
+// SQL 在所有数据库上执行同一条语句
+func SQL(statement string) Step {
+	return Step{statements: map[string]string{"": statement}}
+}
+
+// DialectSQL 按数据库类型（mysql、postgres、sqlite）选择语句，没有列出的数据库跳过该步骤
+func DialectSQL(statements map[string]string) Step {
+	return Step{statements: statements}
+}
+
+// Func 执行 Go 代码，说明和修订号 rev 参与校验和计算
+// 代码本身无法计算校验和，修改代码逻辑时必须递增 rev，已执行该迁移的数据库才能发现变化
+// 预览时默认不执行，只输出说明
+func Func(desc string, rev int, fn func(*gorm.DB) error) Step {
+	return Step{desc: desc, rev: rev, fn: fn}
+}
+
+// Previewable 标记 Go 代码步骤只通过传入的连接读写数据库，预览时可以执行并记录它产生的 SQL
+func (s Step) Previewable() Step {
+	s.previewDB = true
+	return s
+}
+
+func (s Step) statement(dialect string) (string, bool) {
+	if stmt, ok := s.statements[dialect]; ok {
+		return stmt, true
+	}
+	stmt, ok := s.statements[""]
+	return stmt, ok
+}
+
+func (s Step) run(db *gorm.DB) error {
+	if s.fn != nil {
+		return s.fn(db)
+	}
+	stmt, ok := s.statement(db.Dialector.Name())
+	if !ok {
+		return nil
+	}
+	return db.Exec(stmt).Error
+}
+
+// fingerprint 参与校验和计算的步骤内容，多种数据库的语句按类型排序后拼接
+func (s Step) fingerprint() string {
+	if s.fn != nil {
+		return fmt.Sprintf("func:%s@%d", s.desc, s.rev)
+	}
+	dialects := make([]string, 0, len(s.statements))
+	for dialect := range s.statements {
+		dialects = append(dialects, dialect)
+	}
+	sort.Strings(dialects)
+	var b strings.Builder
+	b.WriteString("sql:")
+	for _, dialect := range dialects {
+		b.WriteString(dialect)
+		b.WriteString("=")
+		b.WriteString(strings.TrimSpace(s.statements[dialect]))
+		b.WriteString(";")
+	}
+	return b.String()
+}
+
+// Migration 一个版本的迁移，Down 为空表示不可回滚
+type Migration struct {
+	Version int64
+	Name    string
+	Up      []Step
+	Down    []Step
+	// Transaction 在事务中执行全部步骤和迁移记录；MySQL 的 DDL 会隐式提交，不受事务保护
+	Transaction bool
+}
+
+// Reversible 是否可以回滚
+func (m Migration) Reversible() bool {
+	return len(m.Down) > 0
+}
+
+// Checksum 根据版本号、名称和全部步骤计算校验和，用于发现已执行的迁移在之后被修改
+func (m Migration) Checksum() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d\n%s\n", m.Version, m.Name)
+	for _, step := range m.Up {
+		fmt.Fprintf(h, "up %s\n", step.fingerprint())
+	}
+	for _, step := range m.Down {
+		fmt.Fprintf(h, "down %s\n", step.fingerprint())
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Label 版本号和名称，用于日志和提示
+func (m Migration) Label() string {
+	return fmt.Sprintf("%d_%s", m.Version, m.Name)
+}
+
+// Validate 检查迁移列表：版本号必须为正数且严格递增，名称不能重复
+func Validate(migrations []Migration) error {
+	names := make(map[string]bool, len(migrations))
+	var last int64
+	for _, m := range migrations {
+		if m.Version <= 0 || m.Name == "" {
+			return fmt.Errorf("迁移 %s 的版本号或名称无效", m.Label())
+		}
+		if m.Version <= last {
+			return fmt.Errorf("迁移 %s 的版本号必须大于前一个迁移的版本号 %d", m.Label(), last)
+		}
+		if names[m.Name] {
+			return fmt.Errorf("迁移名称 %s 重复", m.Name)
+		}
+		if len(m.Up) == 0 {
+			return fmt.Errorf("迁移 %s 没有执行步骤", m.Label())
+		}
+		for _, step := range append(append([]Step{}, m.Up...), m.Down...) {
+			if step.fn != nil && step.rev <= 0 {
+				return fmt.Errorf("迁移 %s 的 Go 代码步骤 %q 缺少修订号", m.Label(), step.desc)
+			}
+		}
+		names[m.Name] = true
+		last = m.Version
+	}
+	return nil
+}
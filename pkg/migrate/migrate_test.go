@@ -0,0 +1,219 @@
+package migrate
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	_ "modernc.org/sqlite"
+)
+
+func openTestDB(t *testing.T) *gorm.DB {
+	dsn := filepath.Join(t.TempDir(), "migrate.db")
+	db, err := gorm.Open(sqlite.Dialector{DriverName: "sqlite", DSN: dsn}, &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	return db
+}
+
+func testSet() *Set {
+	return &Set{Migrations: []Migration{
+		{
+			Version: 1,
+			Name:    "create_notes",
+			Up:      []Step{SQL("CREATE TABLE notes (id INTEGER PRIMARY KEY, body TEXT)")},
+			Down:    []Step{SQL("DROP TABLE notes")},
+		},
+		{
+			Version:     2,
+			Name:        "seed_notes",
+			Up:          []Step{SQL("INSERT INTO notes (body) VALUES ('hello')")},
+			Down:        []Step{SQL("DELETE FROM notes")},
+			Transaction: true,
+		},
+		{
+			Version: 3,
+			Name:    "count_notes",
+			Up: []Step{Func("统计笔记", 1, func(db *gorm.DB) error {
+				return db.Exec("UPDATE notes SET body = body || '!'").Error
+			})},
+		},
+	}}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate(testSet().Migrations); err != nil {
+		t.Fatalf("有效的迁移列表校验失败: %v", err)
+	}
+	bad := testSet().Migrations
+	bad[1].Version = 1
+	if err := Validate(bad); err == nil {
+		t.Error("版本号不递增时应当报错")
+	}
+	bad = testSet().Migrations
+	bad[2].Name = "create_notes"
+	if err := Validate(bad); err == nil {
+		t.Error("名称重复时应当报错")
+	}
+	bad = testSet().Migrations
+	bad[2].Up = []Step{Func("统计笔记", 0, func(*gorm.DB) error { return nil })}
+	if err := Validate(bad); err == nil {
+		t.Error("Go 代码步骤缺少修订号时应当报错")
+	}
+}
+
+func TestUpDownAndStatus(t *testing.T) {
+	db := openTestDB(t)
+	set := testSet()
+
+	// 预览不修改数据库
+	preview, err := set.Up(db, Options{DryRun: true})
+	if err != nil {
+		t.Fatalf("预览失败: %v", err)
+	}
+	if len(preview.Migrations) != 3 || !strings.Contains(preview.Migrations[0].SQL[0], "CREATE TABLE notes") {
+		t.Fatalf("预览结果 = %+v", preview.Migrations)
+	}
+	if !strings.HasPrefix(preview.Migrations[2].SQL[0], "-- 统计笔记") {
+		t.Errorf("Go 代码步骤预览 = %v", preview.Migrations[2].SQL)
+	}
+	if db.Migrator().HasTable("notes") || db.Migrator().HasTable(&Record{}) {
+		t.Fatal("预览修改了数据库")
+	}
+
+	if _, err := set.Up(db, Options{Target: 2}); err != nil {
+		t.Fatalf("升级到版本 2 失败: %v", err)
+	}
+	status, err := set.Status(db, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Current != 2 || status.Latest != 3 || status.Pending != 1 {
+		t.Errorf("状态 = current %d, latest %d, pending %d", status.Current, status.Latest, status.Pending)
+	}
+
+	if _, err := set.Up(db, Options{}); err != nil {
+		t.Fatalf("升级失败: %v", err)
+	}
+	var body string
+	db.Raw("SELECT body FROM notes").Scan(&body)
+	if body != "hello!" {
+		t.Errorf("数据 = %q", body)
+	}
+
+	// 版本 3 不可回滚，整个回滚不执行
+	if _, err := set.Down(db, Options{Steps: 2}); err == nil {
+		t.Fatal("包含不可回滚的迁移时应当报错")
+	}
+	var count int64
+	db.Model(&Record{}).Count(&count)
+	if count != 3 {
+		t.Fatalf("回滚失败后迁移记录数 = %d", count)
+	}
+
+	set.Migrations[2].Down = []Step{SQL("UPDATE notes SET body = 'hello'")}
+	if _, err := set.Repair(db); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := set.Down(db, Options{Target: 0}); err != nil {
+		t.Fatalf("全部回滚失败: %v", err)
+	}
+	if db.Migrator().HasTable("notes") {
+		t.Error("回滚后数据表仍然存在")
+	}
+	db.Model(&Record{}).Count(&count)
+	if count != 0 {
+		t.Errorf("回滚后迁移记录数 = %d", count)
+	}
+}
+
+func TestChecksumMismatch(t *testing.T) {
+	db := openTestDB(t)
+	set := testSet()
+	if _, err := set.Up(db, Options{Target: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	set.Migrations[0].Up = []Step{SQL("CREATE TABLE notes (id INTEGER PRIMARY KEY, body TEXT, extra TEXT)")}
+	if _, err := set.Up(db, Options{}); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("迁移被修改后升级应当报错，实际 %v", err)
+	}
+	status, _ := set.Status(db, false)
+	if status.Modified != 1 || status.Migrations[0].State != StateModified {
+		t.Errorf("状态 = %+v", status.Migrations[0])
+	}
+
+	if n, err := set.Repair(db); err != nil || n != 1 {
+		t.Fatalf("Repair = %d, %v", n, err)
+	}
+	if _, err := set.Up(db, Options{}); err != nil {
+		t.Fatalf("Repair 后升级失败: %v", err)
+	}
+
+	// Go 代码步骤只有递增修订号才会改变校验和
+	fn := func(db *gorm.DB) error { return nil }
+	checksum := set.Migrations[2].Checksum()
+	set.Migrations[2].Up = []Step{Func("统计笔记", 1, fn)}
+	if set.Migrations[2].Checksum() != checksum {
+		t.Error("修订号不变时校验和不应变化")
+	}
+	set.Migrations[2].Up = []Step{Func("统计笔记", 2, fn)}
+	if _, err := set.Up(db, Options{}); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("修订号递增后升级应当报错，实际 %v", err)
+	}
+}
+
+func TestLegacyRecordsAdopted(t *testing.T) {
+	db := openTestDB(t)
+	// 早期版本的记录表只有名称和执行时间
+	db.Exec("CREATE TABLE migration_versions (id INTEGER PRIMARY KEY AUTOINCREMENT, name VARCHAR(255) NOT NULL UNIQUE, applied_at DATETIME NOT NULL)")
+	db.Exec("CREATE TABLE notes (id INTEGER PRIMARY KEY, body TEXT)")
+	db.Exec("INSERT INTO migration_versions (name, applied_at) VALUES ('create_notes', CURRENT_TIMESTAMP), ('old_marker', CURRENT_TIMESTAMP)")
+
+	set := testSet()
+	set.Legacy = []string{"old_marker"}
+	result, err := set.Up(db, Options{})
+	if err != nil {
+		t.Fatalf("升级失败: %v", err)
+	}
+	if len(result.Migrations) != 2 {
+		t.Errorf("执行的迁移数 = %d, 期望 2", len(result.Migrations))
+	}
+
+	var record Record
+	db.Where("name = ?", "create_notes").First(&record)
+	if record.Version != 1 || record.Checksum != set.Migrations[0].Checksum() {
+		t.Errorf("早期记录未补齐: %+v", record)
+	}
+	status, _ := set.Status(db, false)
+	if status.Unknown != 0 || len(status.Migrations) != 3 {
+		t.Errorf("状态 = %+v", status)
+	}
+}
+
+func TestPreviewSchema(t *testing.T) {
+	db := openTestDB(t)
+	type note struct {
+		ID   uint
+		Body string
+	}
+	set := &Set{
+		Migrations: testSet().Migrations[:1],
+		Schema:     func(tx *gorm.DB) error { return tx.AutoMigrate(&note{}) },
+	}
+	status, err := set.Status(db, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status.SchemaChanges) == 0 || !strings.Contains(status.SchemaChanges[0], "CREATE TABLE `notes`") {
+		t.Errorf("表结构变更预览 = %v", status.SchemaChanges)
+	}
+	if db.Migrator().HasTable(&note{}) {
+		t.Error("预览创建了数据表")
+	}
+}
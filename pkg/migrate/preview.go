@@ -0,0 +1,46 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+
+	"gorm.io/gorm"
+)
+
+// previewPool 预览用的连接：查询照常执行，写入语句只记录不执行
+// 表结构迁移需要先读取现有的表和列，再决定生成哪些语句，所以不能直接使用 GORM 的 DryRun
+type previewPool struct {
+	gorm.ConnPool
+	dialector  gorm.Dialector
+	statements []string
+}
+
+func (p *previewPool) ExecContext(_ context.Context, query string, args ...interface{}) (sql.Result, error) {
+	p.statements = append(p.statements, p.dialector.Explain(query, args...))
+	return driver.RowsAffected(0), nil
+}
+
+// BeginTx 预览中的事务不开启真实事务，提交和回滚都不做任何事
+func (p *previewPool) BeginTx(context.Context, *sql.TxOptions) (gorm.ConnPool, error) {
+	return p, nil
+}
+
+func (p *previewPool) Commit() error   { return nil }
+func (p *previewPool) Rollback() error { return nil }
+
+// take 取出目前记录的语句并清空
+func (p *previewPool) take() []string {
+	statements := p.statements
+	p.statements = nil
+	return statements
+}
+
+// previewSession 返回写入语句只记录不执行的会话
+func previewSession(db *gorm.DB) (*gorm.DB, *previewPool) {
+	pool := &previewPool{ConnPool: db.Statement.ConnPool, dialector: db.Dialector}
+	// 指定 Context 使会话复制一份 Statement，替换连接不影响原会话；关闭嵌套事务，避免预览结果中出现 SAVEPOINT
+	tx := db.Session(&gorm.Session{NewDB: true, Context: db.Statement.Context, DisableNestedTransaction: true})
+	tx.Statement.ConnPool = pool
+	return tx, pool
+}
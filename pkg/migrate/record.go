@@ -0,0 +1,51 @@
+package migrate
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Record 一条已执行的迁移记录
+// 早期版本只记录名称，Version 为 0、Checksum 为空，下次执行迁移时按名称补齐
+type Record struct {
+	ID         uint      `gorm:"primaryKey" json:"-"`
+	Name       string    `gorm:"type:varchar(255);not null;uniqueIndex" json:"name"`
+	Version    int64     `gorm:"not null;default:0;index" json:"version"`
+	Checksum   string    `gorm:"type:varchar(64);not null;default:''" json:"checksum"`
+	AppliedAt  time.Time `gorm:"not null" json:"applied_at"`
+	DurationMs int64     `gorm:"not null;default:0" json:"duration_ms"`
+}
+
+// TableName 设置迁移记录表名
+func (Record) TableName() string {
+	return "migration_versions"
+}
+
+// EnsureTable 确保迁移记录表存在，并为早期版本创建的表补上版本号和校验和列
+func EnsureTable(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable(&Record{}) {
+		return migrator.CreateTable(&Record{})
+	}
+	if !migrator.HasColumn(&Record{}, "checksum") {
+		return db.AutoMigrate(&Record{})
+	}
+	return nil
+}
+
+// loadRecords 读取全部迁移记录，按名称索引；预览时记录表可能还不存在
+func loadRecords(db *gorm.DB) (map[string]Record, error) {
+	result := map[string]Record{}
+	if !db.Migrator().HasTable(&Record{}) {
+		return result, nil
+	}
+	var records []Record
+	if err := db.Order("id").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range records {
+		result[r.Name] = r
+	}
+	return result, nil
+}
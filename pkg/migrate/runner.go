@@ -0,0 +1,378 @@
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"pixelpunk/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// 迁移状态
+const (
+	StateApplied  = "applied"  // 已执行
+	StatePending  = "pending"  // 待执行
+	StateModified = "modified" // 已执行，但程序中的迁移内容与执行时不一致
+	StateUnknown  = "unknown"  // 数据库中有记录但程序中没有，通常是已被更新版本的程序升级过
+)
+
+// ErrChecksumMismatch 已执行的迁移被修改过，需要人工确认后执行 Repair
+var ErrChecksumMismatch = errors.New("已执行的迁移与当前程序中的内容不一致")
+
+// Set 一组按版本号排列的迁移，以及可选的表结构同步
+type Set struct {
+	Migrations []Migration
+	// Schema 按模型同步表结构（GORM AutoMigrate），在版本迁移之前执行，为空时不处理
+	Schema func(*gorm.DB) error
+	// Legacy 早期版本写入的迁移记录名称，状态中不作为未知迁移显示
+	Legacy []string
+}
+
+// Options 执行选项
+type Options struct {
+	Target     int64 // 升级时执行到该版本为止，为 0 时升级到最新；回滚时回滚所有版本号大于它的迁移
+	Steps      int   // 回滚的迁移个数，大于 0 时回滚忽略 Target
+	DryRun     bool  // 只输出将要执行的 SQL，不修改数据库
+	SyncSchema bool  // 升级前先按模型同步表结构
+}
+
+// MigrationState 单个迁移的状态
+type MigrationState struct {
+	Version    int64      `json:"version"`
+	Name       string     `json:"name"`
+	State      string     `json:"state"`
+	Reversible bool       `json:"reversible"`
+	Checksum   string     `json:"checksum"`
+	AppliedAt  *time.Time `json:"applied_at,omitempty"`
+	DurationMs int64      `json:"duration_ms,omitempty"`
+}
+
+// Status 迁移状态汇总
+type Status struct {
+	Dialect       string           `json:"dialect"`
+	Current       int64            `json:"current"` // 已执行的最高版本
+	Latest        int64            `json:"latest"`  // 程序中的最高版本
+	Pending       int              `json:"pending"`
+	Modified      int              `json:"modified"`
+	Unknown       int              `json:"unknown"`
+	SchemaChanges []string         `json:"schema_changes"` // 按模型同步表结构时将执行的 SQL
+	Migrations    []MigrationState `json:"migrations"`
+}
+
+// Executed 一次执行或预览中处理的迁移
+type Executed struct {
+	Version    int64    `json:"version"`
+	Name       string   `json:"name"`
+	SQL        []string `json:"sql,omitempty"` // 预览时将执行的语句，不能预览的 Go 代码步骤以注释形式给出说明
+	DurationMs int64    `json:"duration_ms"`
+}
+
+// Result 执行结果
+type Result struct {
+	DryRun     bool       `json:"dry_run"`
+	Schema     []string   `json:"schema,omitempty"` // 预览时表结构同步将执行的 SQL
+	Migrations []Executed `json:"migrations"`
+}
+
+func (s *Set) find(name string) (Migration, bool) {
+	for _, m := range s.Migrations {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return Migration{}, false
+}
+
+func (s *Set) isLegacy(name string) bool {
+	for _, legacy := range s.Legacy {
+		if legacy == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Status 返回每个迁移的执行状态；includeSchema 为 true 时同时预览表结构同步将执行的 SQL
+func (s *Set) Status(db *gorm.DB, includeSchema bool) (*Status, error) {
+	if err := Validate(s.Migrations); err != nil {
+		return nil, err
+	}
+	records, err := loadRecords(db)
+	if err != nil {
+		return nil, fmt.Errorf("读取迁移记录失败: %w", err)
+	}
+
+	status := &Status{Dialect: db.Dialector.Name(), SchemaChanges: []string{}, Migrations: []MigrationState{}}
+	for _, m := range s.Migrations {
+		state := MigrationState{Version: m.Version, Name: m.Name, State: StatePending, Reversible: m.Reversible(), Checksum: m.Checksum()}
+		if r, ok := records[m.Name]; ok {
+			appliedAt := r.AppliedAt
+			state.AppliedAt = &appliedAt
+			state.DurationMs = r.DurationMs
+			state.State = StateApplied
+			if r.Checksum != "" && r.Checksum != state.Checksum {
+				state.State = StateModified
+				status.Modified++
+			}
+			if m.Version > status.Current {
+				status.Current = m.Version
+			}
+		} else {
+			status.Pending++
+		}
+		status.Latest = m.Version
+		status.Migrations = append(status.Migrations, state)
+	}
+
+	for _, r := range records {
+		if _, ok := s.find(r.Name); ok || s.isLegacy(r.Name) || r.Version == 0 {
+			continue
+		}
+		appliedAt := r.AppliedAt
+		status.Migrations = append(status.Migrations, MigrationState{
+			Version: r.Version, Name: r.Name, State: StateUnknown, Checksum: r.Checksum,
+			AppliedAt: &appliedAt, DurationMs: r.DurationMs,
+		})
+		status.Unknown++
+		if r.Version > status.Current {
+			status.Current = r.Version
+		}
+	}
+	sort.SliceStable(status.Migrations, func(i, j int) bool {
+		return status.Migrations[i].Version < status.Migrations[j].Version
+	})
+
+	if includeSchema && s.Schema != nil {
+		preview, pool := previewSession(db)
+		if err := s.Schema(preview); err != nil {
+			return nil, fmt.Errorf("预览表结构同步失败: %w", err)
+		}
+		status.SchemaChanges = append(status.SchemaChanges, pool.take()...)
+	}
+	return status, nil
+}
+
+// checkModified 已执行的迁移被修改时拒绝继续，早期版本没有校验和的记录在非预览时补齐
+func (s *Set) checkModified(db *gorm.DB, records map[string]Record, dryRun bool) error {
+	var modified []string
+	for _, m := range s.Migrations {
+		r, ok := records[m.Name]
+		if !ok {
+			continue
+		}
+		if r.Checksum == "" {
+			if dryRun {
+				continue
+			}
+			err := db.Model(&Record{}).Where("id = ?", r.ID).
+				Updates(map[string]interface{}{"version": m.Version, "checksum": m.Checksum()}).Error
+			if err != nil {
+				return fmt.Errorf("补齐迁移 %s 的校验和失败: %w", m.Label(), err)
+			}
+			continue
+		}
+		if r.Checksum != m.Checksum() {
+			modified = append(modified, m.Label())
+		}
+	}
+	if len(modified) > 0 {
+		return fmt.Errorf("%w: %s，确认无误后请执行 repair 更新校验和", ErrChecksumMismatch, strings.Join(modified, ", "))
+	}
+	return nil
+}
+
+// Up 按版本号顺序执行待执行的迁移，遇到失败立即停止，之后的迁移不再执行
+func (s *Set) Up(db *gorm.DB, opts Options) (*Result, error) {
+	if err := Validate(s.Migrations); err != nil {
+		return nil, err
+	}
+	if !opts.DryRun {
+		if err := EnsureTable(db); err != nil {
+			return nil, fmt.Errorf("创建迁移记录表失败: %w", err)
+		}
+	}
+	records, err := loadRecords(db)
+	if err != nil {
+		return nil, fmt.Errorf("读取迁移记录失败: %w", err)
+	}
+	if err := s.checkModified(db, records, opts.DryRun); err != nil {
+		return nil, err
+	}
+
+	result := &Result{DryRun: opts.DryRun, Migrations: []Executed{}}
+	exec, pool := db, (*previewPool)(nil)
+	if opts.DryRun {
+		exec, pool = previewSession(db)
+	}
+
+	if opts.SyncSchema && s.Schema != nil {
+		if err := s.Schema(exec); err != nil {
+			return result, fmt.Errorf("同步表结构失败: %w", err)
+		}
+		if pool != nil {
+			result.Schema = pool.take()
+		}
+	}
+
+	for _, m := range s.Migrations {
+		if _, ok := records[m.Name]; ok {
+			continue
+		}
+		if opts.Target > 0 && m.Version > opts.Target {
+			break
+		}
+		executed, err := s.apply(exec, pool, m, m.Up, func(tx *gorm.DB, duration time.Duration) error {
+			return tx.Create(&Record{
+				Name: m.Name, Version: m.Version, Checksum: m.Checksum(),
+				AppliedAt: time.Now(), DurationMs: duration.Milliseconds(),
+			}).Error
+		})
+		if err != nil {
+			return result, fmt.Errorf("迁移 %s 执行失败: %w", m.Label(), err)
+		}
+		result.Migrations = append(result.Migrations, executed)
+		if !opts.DryRun {
+			logger.Info("已执行迁移 %s，耗时 %dms", m.Label(), executed.DurationMs)
+		}
+	}
+	return result, nil
+}
+
+// Down 从最新的迁移开始依次回滚，要回滚的迁移中只要有一个不可回滚就不执行任何操作
+// 回滚只撤销版本迁移中的步骤，不会撤销按模型同步的表结构
+func (s *Set) Down(db *gorm.DB, opts Options) (*Result, error) {
+	if err := Validate(s.Migrations); err != nil {
+		return nil, err
+	}
+	records, err := loadRecords(db)
+	if err != nil {
+		return nil, fmt.Errorf("读取迁移记录失败: %w", err)
+	}
+	if err := s.checkModified(db, records, true); err != nil {
+		return nil, err
+	}
+
+	var applied []Record
+	for _, r := range records {
+		if r.Version > 0 || s.isRegistered(r.Name) {
+			applied = append(applied, r)
+		}
+	}
+	for i := range applied {
+		if m, ok := s.find(applied[i].Name); ok {
+			applied[i].Version = m.Version
+		}
+	}
+	sort.Slice(applied, func(i, j int) bool { return applied[i].Version > applied[j].Version })
+
+	var targets []Migration
+	for i, r := range applied {
+		if opts.Steps > 0 && i >= opts.Steps {
+			break
+		}
+		if opts.Steps <= 0 && r.Version <= opts.Target {
+			break
+		}
+		m, ok := s.find(r.Name)
+		if !ok {
+			return nil, fmt.Errorf("迁移 %d_%s 不在当前程序中，无法回滚", r.Version, r.Name)
+		}
+		if !m.Reversible() {
+			return nil, fmt.Errorf("迁移 %s 不支持回滚", m.Label())
+		}
+		targets = append(targets, m)
+	}
+
+	result := &Result{DryRun: opts.DryRun, Migrations: []Executed{}}
+	exec, pool := db, (*previewPool)(nil)
+	if opts.DryRun {
+		exec, pool = previewSession(db)
+	}
+	for _, m := range targets {
+		executed, err := s.apply(exec, pool, m, m.Down, func(tx *gorm.DB, _ time.Duration) error {
+			return tx.Where("name = ?", m.Name).Delete(&Record{}).Error
+		})
+		if err != nil {
+			return result, fmt.Errorf("回滚迁移 %s 失败: %w", m.Label(), err)
+		}
+		result.Migrations = append(result.Migrations, executed)
+		if !opts.DryRun {
+			logger.Info("已回滚迁移 %s，耗时 %dms", m.Label(), executed.DurationMs)
+		}
+	}
+	return result, nil
+}
+
+func (s *Set) isRegistered(name string) bool {
+	_, ok := s.find(name)
+	return ok
+}
+
+// apply 执行一组步骤并写入或删除迁移记录；预览时只收集 SQL，不写迁移记录
+func (s *Set) apply(db *gorm.DB, pool *previewPool, m Migration, steps []Step, record func(*gorm.DB, time.Duration) error) (Executed, error) {
+	executed := Executed{Version: m.Version, Name: m.Name}
+	run := func(tx *gorm.DB) error {
+		for _, step := range steps {
+			if pool != nil && step.fn != nil && !step.previewDB {
+				pool.statements = append(pool.statements, "-- "+step.desc+"（Go 代码，预览时不执行）")
+				continue
+			}
+			if err := step.run(tx); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	start := time.Now()
+	if pool != nil {
+		err := run(db)
+		executed.SQL = pool.take()
+		return executed, err
+	}
+	if m.Transaction {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := run(tx); err != nil {
+				return err
+			}
+			return record(tx, time.Since(start))
+		})
+		executed.DurationMs = time.Since(start).Milliseconds()
+		return executed, err
+	}
+	if err := run(db); err != nil {
+		return executed, err
+	}
+	executed.DurationMs = time.Since(start).Milliseconds()
+	if err := record(db, time.Since(start)); err != nil {
+		return executed, fmt.Errorf("写入迁移记录失败: %w", err)
+	}
+	return executed, nil
+}
+
+// Repair 把已执行迁移的校验和更新为当前程序中的内容，返回更新的记录数
+// 只在确认修改不影响已执行的结果（如只调整了注释或说明）后使用
+func (s *Set) Repair(db *gorm.DB) (int, error) {
+	records, err := loadRecords(db)
+	if err != nil {
+		return 0, fmt.Errorf("读取迁移记录失败: %w", err)
+	}
+	updated := 0
+	for _, m := range s.Migrations {
+		r, ok := records[m.Name]
+		if !ok || (r.Checksum == m.Checksum() && r.Version == m.Version) {
+			continue
+		}
+		err := db.Model(&Record{}).Where("id = ?", r.ID).
+			Updates(map[string]interface{}{"version": m.Version, "checksum": m.Checksum()}).Error
+		if err != nil {
+			return updated, fmt.Errorf("更新迁移 %s 的校验和失败: %w", m.Label(), err)
+		}
+		updated++
+	}
+	return updated, nil
+}
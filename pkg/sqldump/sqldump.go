@@ -0,0 +1,321 @@
+// Package sqldump 读取 mysqldump 导出的 SQL 文件中的表数据
+// 只解析 CREATE TABLE 的列名和 INSERT/REPLACE 语句的值，其他语句忽略
+package sqldump
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Record 一行数据，列名到值的映射；NULL 以空字符串表示
+type Record map[string]string
+
+// Table 表的列名和全部行
+type Table struct {
+	Name    string
+	Columns []string
+	Records []Record
+}
+
+// Parse 读取 SQL 导出文件，返回 want 为 true 的表；want 为 nil 时返回全部表
+func Parse(r io.Reader, want func(table string) bool) (map[string]*Table, error) {
+	tables := map[string]*Table{}
+	columns := map[string][]string{}
+	reader := &statementReader{r: bufio.NewReaderSize(r, 64*1024)}
+
+	for {
+		stmt, err := reader.next()
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if stmt != "" {
+			if perr := parseStatement(stmt, want, tables, columns); perr != nil {
+				return nil, perr
+			}
+		}
+		if err == io.EOF {
+			return tables, nil
+		}
+	}
+}
+
+func parseStatement(stmt string, want func(string) bool, tables map[string]*Table, columns map[string][]string) error {
+	upper := strings.ToUpper(stmt[:min(len(stmt), 32)])
+	switch {
+	case strings.HasPrefix(upper, "CREATE TABLE"):
+		name, cols := parseCreateTable(stmt)
+		if name != "" {
+			columns[name] = cols
+		}
+	case strings.HasPrefix(upper, "INSERT"), strings.HasPrefix(upper, "REPLACE"):
+		name, cols, rows, err := parseInsert(stmt)
+		if err != nil {
+			return err
+		}
+		if name == "" || (want != nil && !want(name)) {
+			return nil
+		}
+		if len(cols) == 0 {
+			cols = columns[name]
+		}
+		if len(cols) == 0 {
+			return fmt.Errorf("表 %s 缺少列定义", name)
+		}
+		t := tables[name]
+		if t == nil {
+			t = &Table{Name: name, Columns: cols}
+			tables[name] = t
+		}
+		for _, row := range rows {
+			if len(row) != len(cols) {
+				return fmt.Errorf("表 %s 的数据列数与定义不一致", name)
+			}
+			rec := make(Record, len(cols))
+			for i, col := range cols {
+				rec[col] = row[i]
+			}
+			t.Records = append(t.Records, rec)
+		}
+	}
+	return nil
+}
+
+// parseCreateTable 取出表名和列名，索引和约束定义行被忽略
+func parseCreateTable(stmt string) (string, []string) {
+	open := strings.Index(stmt, "(")
+	if open < 0 {
+		return "", nil
+	}
+	name := identifier(strings.TrimSpace(stmt[len("CREATE TABLE"):open]))
+
+	var cols []string
+	for _, line := range strings.Split(stmt[open+1:], "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "`") && !strings.HasPrefix(line, `"`) {
+			continue
+		}
+		end := strings.IndexAny(line[1:], "`\"")
+		if end < 0 {
+			continue
+		}
+		cols = append(cols, line[1:end+1])
+	}
+	return name, cols
+}
+
+// parseInsert 解析 INSERT [IGNORE] INTO `t` [(`a`,`b`)] VALUES (...),(...)
+func parseInsert(stmt string) (string, []string, [][]string, error) {
+	upper := strings.ToUpper(stmt)
+	into := strings.Index(upper, "INTO")
+	values := strings.Index(upper, "VALUES")
+	if into < 0 || values < 0 || values < into {
+		return "", nil, nil, nil
+	}
+	head := strings.TrimSpace(stmt[into+len("INTO") : values])
+
+	var cols []string
+	if i := strings.Index(head, "("); i >= 0 {
+		for _, c := range strings.Split(strings.TrimSuffix(strings.TrimSpace(head[i+1:]), ")"), ",") {
+			cols = append(cols, identifier(strings.TrimSpace(c)))
+		}
+		head = strings.TrimSpace(head[:i])
+	}
+
+	rows, err := parseValues(stmt[values+len("VALUES"):])
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("解析表 %s 的数据失败: %w", identifier(head), err)
+	}
+	return identifier(head), cols, rows, nil
+}
+
+// identifier 去掉标识符的引号，`db`.`table` 只保留表名
+func identifier(s string) string {
+	if i := strings.LastIndex(s, "."); i >= 0 && !strings.HasSuffix(s, ".") {
+		s = s[i+1:]
+	}
+	return strings.Trim(s, "`\" ")
+}
+
+var errSyntax = errors.New("语法错误")
+
+// parseValues 解析 (v1, 'v2', NULL), (...) 形式的值列表
+func parseValues(s string) ([][]string, error) {
+	var rows [][]string
+	i := 0
+	for {
+		for i < len(s) && (s[i] == ' ' || s[i] == '\n' || s[i] == '\r' || s[i] == '\t' || s[i] == ',') {
+			i++
+		}
+		if i >= len(s) {
+			return rows, nil
+		}
+		if s[i] != '(' {
+			// ON DUPLICATE KEY UPDATE 等尾部子句
+			return rows, nil
+		}
+		i++
+
+		var row []string
+		for {
+			for i < len(s) && (s[i] == ' ' || s[i] == '\n' || s[i] == '\r' || s[i] == '\t') {
+				i++
+			}
+			if i >= len(s) {
+				return nil, errSyntax
+			}
+			val, next, err := parseValue(s, i)
+			if err != nil {
+				return nil, err
+			}
+			row = append(row, val)
+			i = next
+			for i < len(s) && (s[i] == ' ' || s[i] == '\n' || s[i] == '\r' || s[i] == '\t') {
+				i++
+			}
+			if i >= len(s) {
+				return nil, errSyntax
+			}
+			if s[i] == ',' {
+				i++
+				continue
+			}
+			if s[i] == ')' {
+				i++
+				break
+			}
+			return nil, errSyntax
+		}
+		rows = append(rows, row)
+	}
+}
+
+func parseValue(s string, i int) (string, int, error) {
+	// _binary 'xxx'、_utf8mb4'xxx' 等字符集前缀
+	if s[i] == '_' {
+		j := i
+		for j < len(s) && s[j] != '\'' && s[j] != ',' && s[j] != ')' {
+			j++
+		}
+		if j < len(s) && s[j] == '\'' {
+			i = j
+		}
+	}
+	if s[i] == '\'' || s[i] == '"' {
+		return parseQuoted(s, i)
+	}
+
+	j := i
+	for j < len(s) && s[j] != ',' && s[j] != ')' {
+		j++
+	}
+	raw := strings.TrimSpace(s[i:j])
+	if strings.EqualFold(raw, "NULL") {
+		raw = ""
+	}
+	return raw, j, nil
+}
+
+func parseQuoted(s string, i int) (string, int, error) {
+	quote := s[i]
+	var b strings.Builder
+	for j := i + 1; j < len(s); j++ {
+		c := s[j]
+		switch {
+		case c == '\\' && j+1 < len(s):
+			j++
+			switch s[j] {
+			case '0':
+				b.WriteByte(0)
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'b':
+				b.WriteByte('\b')
+			case 'Z':
+				b.WriteByte(26)
+			default:
+				b.WriteByte(s[j])
+			}
+		case c == quote:
+			if j+1 < len(s) && s[j+1] == quote {
+				b.WriteByte(quote)
+				j++
+				continue
+			}
+			return b.String(), j + 1, nil
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, errSyntax
+}
+
+// statementReader 按分号切分语句，忽略引号内的分号和注释行
+type statementReader struct {
+	r *bufio.Reader
+}
+
+func (sr *statementReader) next() (string, error) {
+	var b strings.Builder
+	var quote byte
+	lineStart := true
+
+	for {
+		c, err := sr.r.ReadByte()
+		if err != nil {
+			return strings.TrimSpace(b.String()), io.EOF
+		}
+
+		if quote != 0 {
+			b.WriteByte(c)
+			if c == '\\' && quote != '`' {
+				if n, err := sr.r.ReadByte(); err == nil {
+					b.WriteByte(n)
+				}
+				continue
+			}
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+
+		if lineStart {
+			if c == ' ' || c == '\t' || c == '\r' {
+				continue
+			}
+			if c == '#' || (c == '-' && sr.peek() == '-') {
+				if _, err := sr.r.ReadString('\n'); err != nil {
+					return strings.TrimSpace(b.String()), io.EOF
+				}
+				continue
+			}
+		}
+
+		switch c {
+		case '\'', '"', '`':
+			quote = c
+		case ';':
+			return strings.TrimSpace(b.String()), nil
+		}
+		lineStart = c == '\n'
+		if c == '\n' && b.Len() == 0 {
+			continue
+		}
+		b.WriteByte(c)
+	}
+}
+
+func (sr *statementReader) peek() byte {
+	p, err := sr.r.Peek(1)
+	if err != nil {
+		return 0
+	}
+	return p[0]
+}
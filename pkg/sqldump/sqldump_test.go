@@ -0,0 +1,69 @@
+package sqldump
+
+import (
+	"strings"
+	"testing"
+)
+
+const dump = `-- MySQL dump 10.13  Distrib 8.0.32
+--
+-- Table structure for table ` + "`images`" + `
+--
+
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+CREATE TABLE ` + "`images`" + ` (
+  ` + "`id`" + ` bigint unsigned NOT NULL AUTO_INCREMENT,
+  ` + "`album_id`" + ` bigint unsigned DEFAULT NULL,
+  ` + "`name`" + ` varchar(255) NOT NULL,
+  ` + "`origin_name`" + ` varchar(255) NOT NULL,
+  PRIMARY KEY (` + "`id`" + `),
+  KEY ` + "`images_album_id_foreign`" + ` (` + "`album_id`" + `)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+LOCK TABLES ` + "`images`" + ` WRITE;
+INSERT INTO ` + "`images`" + ` VALUES (1,NULL,'a.jpg','it''s; a \'test\'.jpg'),(2,3,'b.png','猫\\狗\n.png');
+UNLOCK TABLES;
+
+INSERT INTO ` + "`users`" + ` (` + "`id`" + `, ` + "`name`" + `) VALUES (7, _binary 'alice');
+`
+
+func TestParse(t *testing.T) {
+	tables, err := Parse(strings.NewReader(dump), nil)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	images := tables["images"]
+	if images == nil || len(images.Records) != 2 {
+		t.Fatalf("images = %+v", images)
+	}
+	first := images.Records[0]
+	if first["id"] != "1" || first["album_id"] != "" || first["origin_name"] != "it's; a 'test'.jpg" {
+		t.Errorf("first row = %v", first)
+	}
+	if got := images.Records[1]["origin_name"]; got != "猫\\狗\n.png" {
+		t.Errorf("escaped value = %q", got)
+	}
+
+	users := tables["users"]
+	if users == nil || len(users.Records) != 1 || users.Records[0]["name"] != "alice" {
+		t.Errorf("users = %+v", users)
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	tables, err := Parse(strings.NewReader(dump), func(name string) bool { return name == "users" })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := tables["images"]; ok || tables["users"] == nil {
+		t.Errorf("filter not applied: %v", tables)
+	}
+}
+
+func TestParseColumnMismatch(t *testing.T) {
+	_, err := Parse(strings.NewReader("INSERT INTO `t` (`a`,`b`) VALUES (1);"), nil)
+	if err == nil {
+		t.Error("expected error for column mismatch")
+	}
+}
@@ -333,6 +333,11 @@ func (a *LocalAdapter) ensureDirectories() error {
 
 // generateThumbnailBeforeWebP 基于已保存原图路径生成缩略图
 func (a *LocalAdapter) generateThumbnailBeforeWebP(originalFullPath string, req *UploadRequest, physicalRelativePath, logicalRelativePath string) (io.Reader, string, string, error) {
+	// 预生成的缩略图（如视频封面）直接保存
+	if len(req.ThumbnailData) > 0 && req.ThumbnailFormat != "" {
+		return a.saveThumbnail(req.ThumbnailData, req.ThumbnailFormat, req)
+	}
+
 	data, err := os.ReadFile(originalFullPath)
 	if err != nil {
 		return nil, "", "", fmt.Errorf("failed to read source data: %w", err)
@@ -365,12 +370,15 @@ func (a *LocalAdapter) generateThumbnailBeforeWebP(originalFullPath string, req
 	thumbBytes, thumbFormat, _ := pipeline.GenerateOrFallback(data, pipeline.Options{
 		Width: w, Height: h, Quality: q, EnableWebP: true, FallbackOnError: true,
 	})
-	thumbData := bytes.NewReader(thumbBytes)
 	if thumbFormat == "" {
 		thumbFormat = "jpg"
 	}
+	return a.saveThumbnail(thumbBytes, thumbFormat, req)
+}
 
-	// 保存缩略图（按 alias 分片对象键映射到本地路径）
+// saveThumbnail 保存缩略图（按 alias 分片对象键映射到本地路径）
+func (a *LocalAdapter) saveThumbnail(thumbBytes []byte, thumbFormat string, req *UploadRequest) (io.Reader, string, string, error) {
+	thumbData := bytes.NewReader(thumbBytes)
 	thumbFileName := storageutils.MakeThumbName(req.FileName, thumbFormat)
 	thumbKey, _ := tenant.BuildThumbObjectKey(req.UserID, req.FolderPath, thumbFileName)
 	thumbRel := strings.TrimPrefix(thumbKey, "thumbnails/")
@@ -421,7 +429,11 @@ func (a *LocalAdapter) processPreProcessedImage(src io.Reader, req *UploadReques
 func (a *LocalAdapter) validateFile(req *UploadRequest) error {
 	// 如果使用预处理数据，跳过文件验证（假设预处理数据已经是有效的）
 	if len(req.ProcessedData) > 0 {
-		if len(req.ProcessedData) > 20*1024*1024 { // 20MB
+		limit := int64(20 * 1024 * 1024) // 20MB
+		if ext := filepath.Ext(req.FileName); formats.IsVideo(ext) || formats.IsDocument(ext) {
+			limit = iox.DefaultMaxReadBytes
+		}
+		if int64(len(req.ProcessedData)) > limit {
 			return fmt.Errorf("processed data size %d exceeds maximum limit", len(req.ProcessedData))
 		}
 		return nil
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -586,6 +587,48 @@ func (v *FileValidator) validateFileHeaderWithTolerance(header []byte, headerSiz
 			return fmt.Errorf("无效的HEIC/HEIF文件，文件头不匹配: %s", filename)
 		}
 
+	case ".mp4", ".m4v", ".mov":
+		if headerSize < 8 {
+			return fmt.Errorf("文件头长度不足，无法验证MP4/MOV格式: %s", filename)
+		}
+		// ISO BMFF/QuickTime: 偏移4处为首个box类型，通常是 ftyp，老的 QuickTime 文件可能直接以 moov/mdat 等开头
+		switch string(header[4:8]) {
+		case "ftyp", "moov", "mdat", "wide", "free", "skip":
+		default:
+			return fmt.Errorf("无效的MP4/MOV文件，文件头不匹配: %s", filename)
+		}
+
+	case ".webm":
+		if headerSize < 4 {
+			return fmt.Errorf("文件头长度不足，无法验证WebM格式: %s", filename)
+		}
+		// WebM: EBML 头 1A 45 DF A3
+		if header[0] != 0x1A || header[1] != 0x45 || header[2] != 0xDF || header[3] != 0xA3 {
+			return fmt.Errorf("无效的WebM文件，文件头不匹配: %s", filename)
+		}
+
+	case ".pdf":
+		// PDF: %PDF- 标记，个别生成器会在前面写入少量字节
+		if !bytes.Contains(header[:headerSize], []byte("%PDF-")) {
+			return fmt.Errorf("无效的PDF文件，文件头不匹配: %s", filename)
+		}
+
+	case ".docx", ".xlsx", ".pptx", ".odt", ".ods", ".odp":
+		if headerSize < 4 {
+			return fmt.Errorf("文件头长度不足，无法验证Office文档格式: %s", filename)
+		}
+		// OOXML 与 OpenDocument 都是 ZIP 包: 50 4B 03 04
+		if string(header[:4]) != "PK\x03\x04" {
+			return fmt.Errorf("无效的Office文档，文件头不匹配: %s", filename)
+		}
+
+	case ".txt", ".md", ".csv":
+		// 文本文件不应包含 NUL 字节，UTF-16 编码的文本以 BOM 开头
+		isUTF16 := headerSize >= 2 && ((header[0] == 0xFF && header[1] == 0xFE) || (header[0] == 0xFE && header[1] == 0xFF))
+		if !isUTF16 && bytes.IndexByte(header[:headerSize], 0) >= 0 {
+			return fmt.Errorf("无效的文本文件，包含二进制内容: %s", filename)
+		}
+
 	case ".svg":
 		// SVG 是文本格式，检查是否以 XML 声明或 <svg 开头
 		headerStr := string(header[:headerSize])
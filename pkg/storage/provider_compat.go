@@ -3,7 +3,9 @@ package storage
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
+	"net/http"
 	"path/filepath"
 	"strings"
 
@@ -17,8 +19,17 @@ type RemoteReadProvider interface {
 	IsDirectAccess() bool
 	GetRemoteContent(objectPath string, isThumb bool, userID uint) (io.ReadCloser, string, error)
 	GetFileURL(relativePath string, isThumb bool) (string, error)
+	// SupportsSignedURL reports whether the adapter can mint native presigned URLs.
+	SupportsSignedURL() bool
+	// GetSignedURL returns a native presigned URL valid for expires seconds.
+	GetSignedURL(objectPath string, isThumb bool, userID uint, expires int64) (string, error)
+	// GetRemoteRange returns bytes start..end (inclusive) of a remote object.
+	GetRemoteRange(objectPath string, isThumb bool, userID uint, start, end int64) (io.ReadCloser, error)
 }
 
+// rangePresignTTL is the lifetime of the presigned URL used to forward a Range request.
+const rangePresignTTL = 60
+
 type providerImpl struct {
 	ad        adapter.StorageAdapter
 	channelID string
@@ -46,6 +57,19 @@ func (p *providerImpl) GetFileURL(relativePath string, isThumb bool) (string, er
 	return "", errors.New("provider_compat已废弃，请使用FileID-based新架构")
 }
 
+func (p *providerImpl) SupportsSignedURL() bool { return p.ad.GetCapabilities().SupportsSignedURL }
+
+func (p *providerImpl) GetSignedURL(objectPath string, isThumb bool, userID uint, expires int64) (string, error) {
+	if !p.SupportsSignedURL() {
+		return "", errors.New("storage adapter does not support signed URLs")
+	}
+	key := pathutil.EnsureObjectKey(userID, objectPath, isThumb)
+	if key == "" {
+		key = strings.TrimPrefix(objectPath, "/")
+	}
+	return p.ad.GetURL(key, &adapter.URLOptions{IsThumbnail: isThumb, Expires: expires})
+}
+
 func (p *providerImpl) GetRemoteContent(objectPath string, isThumb bool, userID uint) (io.ReadCloser, string, error) {
 	// Normalize logical path to object key
 	key := pathutil.EnsureObjectKey(userID, objectPath, isThumb)
@@ -65,6 +89,59 @@ func (p *providerImpl) GetRemoteContent(objectPath string, isThumb bool, userID
 	return reader, ctype, nil
 }
 
+// GetRemoteRange forwards the byte range to the storage service through a short-lived
+// presigned URL when the adapter supports one; otherwise it reads the object from the
+// beginning and skips the bytes before start.
+func (p *providerImpl) GetRemoteRange(objectPath string, isThumb bool, userID uint, start, end int64) (io.ReadCloser, error) {
+	if p.SupportsSignedURL() {
+		if signedURL, err := p.GetSignedURL(objectPath, isThumb, userID, rangePresignTTL); err == nil {
+			if body, err := fetchRange(signedURL, start, end); err == nil {
+				return body, nil
+			}
+		}
+	}
+
+	reader, _, err := p.GetRemoteContent(objectPath, isThumb, userID)
+	if err != nil {
+		return nil, err
+	}
+	return skipToRange(reader, start, end)
+}
+
+func fetchRange(url string, start, end int64) (io.ReadCloser, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		return resp.Body, nil
+	case http.StatusOK:
+		// the service ignored the Range header and sent the whole object
+		return skipToRange(resp.Body, start, end)
+	default:
+		resp.Body.Close()
+		return nil, fmt.Errorf("range request failed: %s", resp.Status)
+	}
+}
+
+// skipToRange discards the bytes before start and limits the reader to end.
+func skipToRange(rc io.ReadCloser, start, end int64) (io.ReadCloser, error) {
+	if _, err := io.CopyN(io.Discard, rc, start); err != nil {
+		rc.Close()
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(rc, end-start+1), rc}, nil
+}
+
 // GetStorageProviderByChannelID returns a minimal provider backed by current StorageManager adapter.
 func GetStorageProviderByChannelID(channelID string) (RemoteReadProvider, error) {
 	mgr := New(&CompatChannelRepository{}).GetManager()
@@ -0,0 +1,244 @@
+package urlfetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"syscall"
+	"time"
+
+	"pixelpunk/pkg/imagex/formats"
+)
+
+var (
+	// ErrInvalidURL 地址格式不正确或协议不是 http/https
+	ErrInvalidURL = errors.New("远程地址无效")
+	// ErrBlockedAddress 地址指向内网、回环或保留地址
+	ErrBlockedAddress = errors.New("禁止访问内网地址")
+	// ErrTooLarge 响应内容超过大小限制
+	ErrTooLarge = errors.New("远程文件超过大小限制")
+	// ErrBadStatus 远程服务器返回非 2xx 状态码
+	ErrBadStatus = errors.New("远程服务器返回错误状态")
+)
+
+const (
+	defaultTimeout      = 30 * time.Second
+	defaultMaxRedirects = 3
+	userAgent           = "PixelPunk-Fetcher/1.0"
+)
+
+// cgnatRange 运营商级NAT地址段，net.IP.IsPrivate 不包含
+var cgnatRange = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// Options 抓取限制，零值使用默认超时和重定向次数，MaxSize 为 0 表示不限制
+type Options struct {
+	MaxSize      int64
+	Timeout      time.Duration
+	MaxRedirects int
+}
+
+// Result 抓取结果，ContentType 为按内容识别的类型，识别不出时取响应头
+type Result struct {
+	Data        []byte
+	ContentType string
+	FileName    string
+	FinalURL    string
+}
+
+// IsInternalIP 判断是否为内网、回环、链路本地、组播或保留地址
+func IsInternalIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return true
+	}
+	if v4 := ip.To4(); v4 != nil {
+		return v4[0] == 0 || cgnatRange.Contains(v4)
+	}
+	return false
+}
+
+// DenyInternalAddress 用作 net.Dialer.Control，在建立连接时校验实际解析到的地址，防止DNS重绑定
+func DenyInternalAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || IsInternalIP(ip) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, host)
+	}
+	return nil
+}
+
+// ValidateURL 静态校验协议和主机名，连接时还会再次校验解析结果
+func ValidateURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return nil, ErrInvalidURL
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("%w: 仅支持http或https", ErrInvalidURL)
+	}
+	if u.User != nil {
+		return nil, fmt.Errorf("%w: 不支持在地址中携带账号密码", ErrInvalidURL)
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		return nil, fmt.Errorf("%w: 缺少主机名", ErrInvalidURL)
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") || strings.HasSuffix(host, ".local") || strings.HasSuffix(host, ".internal") {
+		return nil, ErrBlockedAddress
+	}
+	if ip := net.ParseIP(host); ip != nil && IsInternalIP(ip) {
+		return nil, ErrBlockedAddress
+	}
+	return u, nil
+}
+
+// transport 不走代理，保证连接时校验的是目标服务器的真实地址
+var transport = &http.Transport{
+	Proxy: nil,
+	DialContext: (&net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: DenyInternalAddress,
+	}).DialContext,
+	TLSHandshakeTimeout:   10 * time.Second,
+	ResponseHeaderTimeout: 20 * time.Second,
+	MaxIdleConns:          20,
+	IdleConnTimeout:       60 * time.Second,
+}
+
+// Fetch 下载远程文件，重定向的每一跳都重新校验，超过 MaxSize 立即中止
+func Fetch(ctx context.Context, raw string, opts Options) (*Result, error) {
+	u, err := ValidateURL(raw)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultTimeout
+	}
+	if opts.MaxRedirects <= 0 {
+		opts.MaxRedirects = defaultMaxRedirects
+	}
+
+	client := &http.Client{
+		Timeout:   opts.Timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > opts.MaxRedirects {
+				return fmt.Errorf("重定向次数超过%d次", opts.MaxRedirects)
+			}
+			_, err := ValidateURL(req.URL.String())
+			return err
+		},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, ErrInvalidURL
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "image/*,video/*,application/pdf,*/*;q=0.8")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if errors.Is(err, ErrBlockedAddress) || errors.Is(err, ErrInvalidURL) {
+			return nil, unwrapSentinel(err)
+		}
+		return nil, fmt.Errorf("请求远程地址失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%w: %d", ErrBadStatus, resp.StatusCode)
+	}
+	if opts.MaxSize > 0 && resp.ContentLength > opts.MaxSize {
+		return nil, ErrTooLarge
+	}
+
+	var body io.Reader = resp.Body
+	if opts.MaxSize > 0 {
+		body = io.LimitReader(resp.Body, opts.MaxSize+1)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("读取远程内容失败: %w", err)
+	}
+	if opts.MaxSize > 0 && int64(len(data)) > opts.MaxSize {
+		return nil, ErrTooLarge
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("远程文件为空")
+	}
+
+	contentType := SniffContentType(data, resp.Header.Get("Content-Type"))
+	return &Result{
+		Data:        data,
+		ContentType: contentType,
+		FileName:    FileName(resp.Request.URL, resp.Header.Get("Content-Disposition"), contentType),
+		FinalURL:    resp.Request.URL.String(),
+	}, nil
+}
+
+// unwrapSentinel 去掉 net/http 包装的 "Get ...: dial tcp ..." 前缀，只保留可读的原因
+func unwrapSentinel(err error) error {
+	if errors.Is(err, ErrBlockedAddress) {
+		return ErrBlockedAddress
+	}
+	return ErrInvalidURL
+}
+
+// SniffContentType 按内容识别类型；内容无法确定具体类型（zip、纯文本等）时使用服务器声明的类型
+func SniffContentType(data []byte, declared string) string {
+	sniffed := http.DetectContentType(data)
+	sniffed, _, _ = mime.ParseMediaType(sniffed)
+	declared, _, _ = mime.ParseMediaType(declared)
+
+	switch sniffed {
+	case "application/octet-stream", "application/zip", "text/plain", "text/xml":
+		if declared != "" && declared != "application/octet-stream" {
+			return declared
+		}
+	}
+	return sniffed
+}
+
+// FileName 依次从 Content-Disposition、地址路径中取文件名，扩展名与识别出的类型不符时替换
+func FileName(u *url.URL, disposition, contentType string) string {
+	name := ""
+	if disposition != "" {
+		if _, params, err := mime.ParseMediaType(disposition); err == nil {
+			name = params["filename"]
+		}
+	}
+	if name == "" && u != nil {
+		name = path.Base(u.Path)
+	}
+	name = strings.TrimSpace(path.Base(strings.ReplaceAll(name, "\\", "/")))
+	if name == "" || name == "." || name == "/" {
+		name = "remote"
+	}
+
+	ext := strings.ToLower(path.Ext(name))
+	want := formats.ExtensionFromContentType(contentType)
+	switch {
+	case want == "":
+		return name
+	case ext == "":
+		return name + "." + want
+	case strings.HasPrefix(contentType, "text/"):
+		// 纯文本无法从内容区分 txt、md、csv，保留原扩展名
+		return name
+	case formats.GetContentType(ext) == formats.GetContentType(want):
+		return name
+	}
+	return strings.TrimSuffix(name, path.Ext(name)) + "." + want
+}
@@ -0,0 +1,110 @@
+package urlfetch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestValidateURL(t *testing.T) {
+	cases := map[string]error{
+		"https://example.com/a.jpg":      nil,
+		"http://8.8.8.8/a.png":           nil,
+		"ftp://example.com/a.jpg":        ErrInvalidURL,
+		"https://user:pw@example.com/a":  ErrInvalidURL,
+		"http://localhost/a.jpg":         ErrBlockedAddress,
+		"http://nas.local/a.jpg":         ErrBlockedAddress,
+		"http://127.0.0.1:8080/a.jpg":    ErrBlockedAddress,
+		"http://169.254.169.254/latest":  ErrBlockedAddress,
+		"http://[::1]/a.jpg":             ErrBlockedAddress,
+		"http://[::ffff:10.0.0.1]/a.jpg": ErrBlockedAddress,
+	}
+	for raw, want := range cases {
+		_, err := ValidateURL(raw)
+		if want == nil && err != nil {
+			t.Errorf("ValidateURL(%s) = %v, want nil", raw, err)
+		}
+		if want != nil && !errors.Is(err, want) {
+			t.Errorf("ValidateURL(%s) = %v, want %v", raw, err, want)
+		}
+	}
+}
+
+// 拨号时按实际连接的地址拦截，域名解析到回环地址同样会被拒绝
+func TestDenyInternalAddress(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("secret"))
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	if err := DenyInternalAddress("tcp", u.Host, nil); !errors.Is(err, ErrBlockedAddress) {
+		t.Fatalf("DenyInternalAddress(%s) = %v, want ErrBlockedAddress", u.Host, err)
+	}
+	if err := DenyInternalAddress("tcp", "93.184.216.34:443", nil); err != nil {
+		t.Fatalf("DenyInternalAddress(public) = %v", err)
+	}
+
+	_, err := fetchWithTransport(t, srv.URL)
+	if !errors.Is(err, ErrBlockedAddress) {
+		t.Fatalf("Fetch(%s) = %v, want ErrBlockedAddress", srv.URL, err)
+	}
+}
+
+func TestSniffContentType(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	if got := SniffContentType(png, "text/html"); got != "image/png" {
+		t.Errorf("png sniff = %s", got)
+	}
+	if got := SniffContentType([]byte("a,b\n1,2\n"), "text/csv; charset=utf-8"); got != "text/csv" {
+		t.Errorf("csv sniff = %s", got)
+	}
+	if got := SniffContentType([]byte{0, 1, 2, 3}, "image/heic"); got != "image/heic" {
+		t.Errorf("heic sniff = %s", got)
+	}
+}
+
+func TestFileName(t *testing.T) {
+	u, _ := url.Parse("https://cdn.example.com/photos/%E5%9B%BE%E7%89%87.jpeg?w=100")
+	cases := []struct {
+		url         *url.URL
+		disposition string
+		contentType string
+		want        string
+	}{
+		{u, "", "image/jpeg", "图片.jpeg"},
+		{u, "", "image/png", "图片.png"},
+		{u, `attachment; filename="report.pdf"`, "application/pdf", "report.pdf"},
+		{mustParse("https://example.com/"), "", "image/webp", "remote.webp"},
+		{mustParse("https://example.com/download"), "", "video/mp4", "download.mp4"},
+		{mustParse("https://example.com/notes.md"), "", "text/plain", "notes.md"},
+		{mustParse("https://example.com/a.bin"), "", "application/octet-stream", "a.bin"},
+	}
+	for _, c := range cases {
+		if got := FileName(c.url, c.disposition, c.contentType); got != c.want {
+			t.Errorf("FileName(%s, %q, %s) = %s, want %s", c.url, c.disposition, c.contentType, got, c.want)
+		}
+	}
+}
+
+// fetchWithTransport 绕过静态校验直接走受保护的拨号器，模拟域名解析到内网的情况
+func fetchWithTransport(t *testing.T, raw string) (*http.Response, error) {
+	t.Helper()
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, raw, nil)
+	resp, err := transport.RoundTrip(req)
+	if err == nil {
+		resp.Body.Close()
+	}
+	return resp, err
+}
+
+func mustParse(raw string) *url.URL {
+	u, err := url.Parse(raw)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
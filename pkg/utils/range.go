@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrRangeNotSatisfiable Range 请求超出内容范围
+var ErrRangeNotSatisfiable = errors.New("range not satisfiable")
+
+// ParseByteRange 解析单段 Range 请求头（bytes=start-end、bytes=start-、bytes=-suffix），end 为闭区间
+// 没有 Range、格式无法识别或为多段范围时 ok 为 false，按完整内容响应
+func ParseByteRange(header string, size int64) (start, end int64, ok bool, err error) {
+	spec, found := strings.CutPrefix(strings.TrimSpace(header), "bytes=")
+	if !found || strings.Contains(spec, ",") || size <= 0 {
+		return 0, 0, false, nil
+	}
+	first, last, found := strings.Cut(strings.TrimSpace(spec), "-")
+	if !found {
+		return 0, 0, false, nil
+	}
+
+	if first == "" {
+		suffix, convErr := strconv.ParseInt(last, 10, 64)
+		if convErr != nil || suffix < 0 {
+			return 0, 0, false, nil
+		}
+		if suffix == 0 {
+			return 0, 0, false, ErrRangeNotSatisfiable
+		}
+		return max(size-suffix, 0), size - 1, true, nil
+	}
+
+	start, convErr := strconv.ParseInt(first, 10, 64)
+	if convErr != nil || start < 0 {
+		return 0, 0, false, nil
+	}
+	if start >= size {
+		return 0, 0, false, ErrRangeNotSatisfiable
+	}
+	end = size - 1
+	if last != "" {
+		end, convErr = strconv.ParseInt(last, 10, 64)
+		if convErr != nil || end < start {
+			return 0, 0, false, nil
+		}
+		end = min(end, size-1)
+	}
+	return start, end, true, nil
+}
+
+// ServeReaderRange 以支持 Range 的方式返回不可寻址的内容（如远程存储的代理流），用于视频拖动播放
+// openRange 非空时直接读取请求的范围（如转发给存储服务），否则读取并丢弃范围起点之前的数据；
+// size 未知时不支持 Range，按完整内容响应
+func ServeReaderRange(c *gin.Context, r io.Reader, size int64, contentType string, openRange func(start, end int64) (io.ReadCloser, error)) {
+	c.Header("Content-Type", contentType)
+	if size <= 0 {
+		c.Status(http.StatusOK)
+		io.Copy(c.Writer, r)
+		return
+	}
+	c.Header("Accept-Ranges", "bytes")
+
+	start, end, ok, err := ParseByteRange(c.GetHeader("Range"), size)
+	if err != nil {
+		c.Header("Content-Range", fmt.Sprintf("bytes */%d", size))
+		c.AbortWithStatus(http.StatusRequestedRangeNotSatisfiable)
+		return
+	}
+	if !ok {
+		c.Header("Content-Length", strconv.FormatInt(size, 10))
+		c.Status(http.StatusOK)
+		io.Copy(c.Writer, r)
+		return
+	}
+
+	if openRange != nil {
+		rangeReader, err := openRange(start, end)
+		if err != nil {
+			c.AbortWithStatus(http.StatusBadGateway)
+			return
+		}
+		defer rangeReader.Close()
+		r = rangeReader
+	} else if _, err := io.CopyN(io.Discard, r, start); err != nil {
+		c.AbortWithStatus(http.StatusBadGateway)
+		return
+	}
+	c.Header("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, size))
+	c.Header("Content-Length", strconv.FormatInt(end-start+1, 10))
+	c.Status(http.StatusPartialContent)
+	io.CopyN(c.Writer, r, end-start+1)
+}
+
+// IsContinuationRange Range 请求是否为已开始下载的续传（单段、从中间某个字节开始）
+// 后缀范围（bytes=-N）、从第一个字节开始、多段或无法满足的范围都按新的下载处理
+func IsContinuationRange(header string, size int64) bool {
+	spec, found := strings.CutPrefix(strings.TrimSpace(header), "bytes=")
+	if !found || strings.HasPrefix(strings.TrimSpace(spec), "-") {
+		return false
+	}
+	start, _, ok, err := ParseByteRange(header, size)
+	return err == nil && ok && start > 0
+}
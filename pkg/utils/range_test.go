@@ -0,0 +1,53 @@
+package utils
+
+import "testing"
+
+func TestParseByteRange(t *testing.T) {
+	cases := []struct {
+		header     string
+		start, end int64
+		ok         bool
+		err        error
+	}{
+		{"", 0, 0, false, nil},
+		{"bytes=0-99", 0, 99, true, nil},
+		{"bytes=500-", 500, 999, true, nil},
+		{"bytes=900-2000", 900, 999, true, nil},
+		{"bytes=-100", 900, 999, true, nil},
+		{"bytes=-5000", 0, 999, true, nil},
+		{"bytes=0-1,5-9", 0, 0, false, nil},
+		{"bytes=9-1", 0, 0, false, nil},
+		{"items=0-1", 0, 0, false, nil},
+		{"bytes=1000-", 0, 0, false, ErrRangeNotSatisfiable},
+		{"bytes=-0", 0, 0, false, ErrRangeNotSatisfiable},
+	}
+	for _, tc := range cases {
+		start, end, ok, err := ParseByteRange(tc.header, 1000)
+		if start != tc.start || end != tc.end || ok != tc.ok || err != tc.err {
+			t.Errorf("ParseByteRange(%q) = %d, %d, %v, %v; want %d, %d, %v, %v", tc.header, start, end, ok, err, tc.start, tc.end, tc.ok, tc.err)
+		}
+	}
+}
+
+func TestIsContinuationRange(t *testing.T) {
+	cases := map[string]bool{
+		"":              false,
+		"bytes=0-":      false,
+		"bytes=0-1023":  false,
+		"bytes=0-9,20-": false,
+		"bytes=5-9,0-":  false,
+		"bytes=1-":      true,
+		"bytes=1024-":   true,
+		"bytes=100-199": true,
+		"bytes=-500":    false,
+		"bytes=-4096":   false,
+		"bytes=4096-":   false,
+		"bytes=abc-":    false,
+		"items=5-":      false,
+	}
+	for header, want := range cases {
+		if got := IsContinuationRange(header, 4096); got != want {
+			t.Errorf("IsContinuationRange(%q) = %v; want %v", header, got, want)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package utils
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func newTestSignedParams() *SignedURLParams {
+	return &SignedURLParams{
+		Resource:     SignedResource("f", "abc123"),
+		Expires:      time.Now().Add(time.Hour).Unix(),
+		LinkID:       "link1",
+		IP:           "203.0.113.0/24",
+		MaxDownloads: 3,
+		Width:        800,
+		Height:       600,
+		Quality:      75,
+		Format:       "webp",
+	}
+}
+
+// 生成的查询参数解析后得到相同的字段，签名可以通过校验
+func TestSignedURLRoundTrip(t *testing.T) {
+	signer := NewURLSigner("test-secret")
+	p := newTestSignedParams()
+	q := p.Query(signer.SignParams(p))
+
+	if !IsSignedURLQuery(q) {
+		t.Fatal("query should carry a signature")
+	}
+	parsed, sig, err := ParseSignedURLParams(p.Resource, q)
+	if err != nil {
+		t.Fatalf("ParseSignedURLParams error: %v", err)
+	}
+	if *parsed != *p {
+		t.Errorf("parsed = %+v, want %+v", *parsed, *p)
+	}
+	if !signer.VerifyParams(parsed, sig) {
+		t.Error("signature of untouched query should verify")
+	}
+	if NewURLSigner("other-secret").VerifyParams(parsed, sig) {
+		t.Error("signature should not verify with another secret")
+	}
+}
+
+// 修改任一参与签名的字段后签名失效
+func TestSignedURLTamper(t *testing.T) {
+	signer := NewURLSigner("test-secret")
+	p := newTestSignedParams()
+	q := p.Query(signer.SignParams(p))
+
+	cases := []struct {
+		name     string
+		resource string
+		key      string
+		value    string
+	}{
+		{"resource", SignedResource("f", "other"), "", ""},
+		{"resource kind", SignedResource("t", "abc123"), "", ""},
+		{"expiry", "", SignedParamExpires, "9999999999"},
+		{"link id", "", SignedParamLinkID, "link2"},
+		{"ip", "", SignedParamIP, "0.0.0.0/0"},
+		{"ip removed", "", SignedParamIP, ""},
+		{"max downloads", "", SignedParamMax, "100"},
+		{"max downloads removed", "", SignedParamMax, ""},
+		{"width", "", SignedParamWidth, "1600"},
+		{"height", "", SignedParamHeight, "1200"},
+		{"quality", "", SignedParamQuality, "100"},
+		{"format", "", SignedParamFormat, "png"},
+		{"format removed", "", SignedParamFormat, ""},
+	}
+	for _, tc := range cases {
+		tampered := url.Values{}
+		for k, v := range q {
+			tampered[k] = append([]string(nil), v...)
+		}
+		if tc.key != "" {
+			if tc.value == "" {
+				tampered.Del(tc.key)
+			} else {
+				tampered.Set(tc.key, tc.value)
+			}
+		}
+		resource := p.Resource
+		if tc.resource != "" {
+			resource = tc.resource
+		}
+
+		parsed, sig, err := ParseSignedURLParams(resource, tampered)
+		if err != nil {
+			t.Errorf("%s: ParseSignedURLParams error: %v", tc.name, err)
+			continue
+		}
+		if signer.VerifyParams(parsed, sig) {
+			t.Errorf("%s: tampered query should not verify", tc.name)
+		}
+	}
+}
+
+func TestParseSignedURLParamsErrors(t *testing.T) {
+	signer := NewURLSigner("test-secret")
+	p := newTestSignedParams()
+	valid := p.Query(signer.SignParams(p))
+
+	cases := []struct {
+		name  string
+		key   string
+		value string
+	}{
+		{"missing version", SignedParamVersion, ""},
+		{"unknown version", SignedParamVersion, "2"},
+		{"missing link id", SignedParamLinkID, ""},
+		{"missing expiry", SignedParamExpires, ""},
+		{"invalid expiry", SignedParamExpires, "tomorrow"},
+		{"invalid width", SignedParamWidth, "wide"},
+		{"negative max downloads", SignedParamMax, "-1"},
+	}
+	for _, tc := range cases {
+		q := url.Values{}
+		for k, v := range valid {
+			q[k] = append([]string(nil), v...)
+		}
+		if tc.value == "" {
+			q.Del(tc.key)
+		} else {
+			q.Set(tc.key, tc.value)
+		}
+		if _, _, err := ParseSignedURLParams(p.Resource, q); err == nil {
+			t.Errorf("%s: expected an error", tc.name)
+		}
+	}
+}
+
+func TestSignedURLMissingSignature(t *testing.T) {
+	signer := NewURLSigner("test-secret")
+	p := newTestSignedParams()
+	q := p.Query(signer.SignParams(p))
+	q.Del(SignedParamSignature)
+
+	if IsSignedURLQuery(q) {
+		t.Error("query without sig should not be treated as signed")
+	}
+	parsed, sig, err := ParseSignedURLParams(p.Resource, q)
+	if err != nil {
+		t.Fatalf("ParseSignedURLParams error: %v", err)
+	}
+	if sig != "" || signer.VerifyParams(parsed, sig) {
+		t.Error("empty signature should never verify")
+	}
+}
+
+// 签名只保证字段未被修改，过期由调用方比较 Expires；已过期的链接签名仍然有效但 Expires 早于当前时间
+func TestSignedURLExpiry(t *testing.T) {
+	signer := NewURLSigner("test-secret")
+	p := newTestSignedParams()
+	p.Expires = time.Now().Add(-time.Minute).Unix()
+	q := p.Query(signer.SignParams(p))
+
+	parsed, sig, err := ParseSignedURLParams(p.Resource, q)
+	if err != nil {
+		t.Fatalf("ParseSignedURLParams error: %v", err)
+	}
+	if !signer.VerifyParams(parsed, sig) {
+		t.Error("expired link should still carry a valid signature")
+	}
+	if parsed.Expires >= time.Now().Unix() {
+		t.Errorf("parsed expiry %d should be in the past", parsed.Expires)
+	}
+
+	// 延长过期时间会使签名失效
+	q.Set(SignedParamExpires, "9999999999")
+	parsed, sig, _ = ParseSignedURLParams(p.Resource, q)
+	if signer.VerifyParams(parsed, sig) {
+		t.Error("extending the expiry should invalidate the signature")
+	}
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+// RFC 6238 附录B的SHA1测试向量（取低6位）
+func TestGenerateTOTPCode(t *testing.T) {
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+	cases := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tc := range cases {
+		got, err := GenerateTOTPCode(secret, TOTPStep(time.Unix(tc.unix, 0)))
+		if err != nil {
+			t.Fatalf("GenerateTOTPCode(%d) error: %v", tc.unix, err)
+		}
+		if got != tc.want {
+			t.Errorf("GenerateTOTPCode(%d) = %s, want %s", tc.unix, got, tc.want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1700000000, 0)
+	step := TOTPStep(now)
+
+	prev, _ := GenerateTOTPCode(secret, step-1)
+	if got, ok := ValidateTOTP(secret, prev, now, 0); !ok || got != step-1 {
+		t.Fatalf("上一个时间步的验证码应通过校验")
+	}
+
+	if _, ok := ValidateTOTP(secret, prev, now, step-1); ok {
+		t.Fatalf("已使用的时间步不应再次通过校验")
+	}
+
+	stale, _ := GenerateTOTPCode(secret, step-3)
+	if _, ok := ValidateTOTP(secret, stale, now, 0); ok {
+		t.Fatalf("超出偏移窗口的验证码不应通过校验")
+	}
+
+	if _, ok := ValidateTOTP(secret, "12345", now, 0); ok {
+		t.Fatalf("位数不正确的验证码不应通过校验")
+	}
+}
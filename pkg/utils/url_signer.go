@@ -239,12 +239,12 @@ func GetSigningSecret() string {
 func getSecretFromDatabase() string {
 	// 优先查找专用的URL签名密钥
 	var setting models.Setting
-	if err := database.DB.Where("`key` = ?", "url_signing_secret").First(&setting).Error; err == nil {
+	if err := database.DB.Where(map[string]interface{}{"key": "url_signing_secret"}).First(&setting).Error; err == nil {
 		return setting.GetStringValue()
 	}
 
 	// 如果没有，使用JWT密钥作为基础
-	if err := database.DB.Where("`key` = ?", "jwt_secret").First(&setting).Error; err == nil {
+	if err := database.DB.Where(map[string]interface{}{"key": "jwt_secret"}).First(&setting).Error; err == nil {
 		jwtSecret := setting.GetStringValue()
 		if jwtSecret != "" {
 			return jwtSecret + "-url-signing"
@@ -37,9 +37,11 @@ func (c *DynamicOpenAIClient) getConfigFromDB() (apiKey, baseURL, model string,
 
 	var settings []SettingRow
 	if err := db.Table("setting").
-		Where("`group` = ?", "vector").
-		Where("`key` IN (?)", []string{"vector_api_key", "vector_base_url", "vector_model", "vector_timeout"}).
-		Select("`key`, `value`, `type`").
+		Where(map[string]interface{}{
+			"group": "vector",
+			"key":   []string{"vector_api_key", "vector_base_url", "vector_model", "vector_timeout"},
+		}).
+		Select("key", "value", "type").
 		Find(&settings).Error; err != nil {
 		return "", "", "", 0, 0, fmt.Errorf("查询向量配置失败: %v", err)
 	}
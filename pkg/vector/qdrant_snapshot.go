@@ -0,0 +1,108 @@
+package vector
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+)
+
+// snapshotHTTPClient 快照下载和上传的大小与集合规模相关，不使用普通请求的超时
+var snapshotHTTPClient = &http.Client{}
+
+// CreateSnapshot 为集合创建快照，返回快照名称
+func (q *QdrantClient) CreateSnapshot() (string, error) {
+	resp, err := snapshotHTTPClient.Post(fmt.Sprintf("%s/collections/%s/snapshots?wait=true", q.baseURL, q.collection), "application/json", nil)
+	if err != nil {
+		return "", fmt.Errorf("创建快照失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("创建快照失败，状态码: %d, 响应: %s", resp.StatusCode, string(body))
+	}
+
+	var snapshotResp struct {
+		Result struct {
+			Name string `json:"name"`
+		} `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&snapshotResp); err != nil {
+		return "", fmt.Errorf("解析快照响应失败: %w", err)
+	}
+	if snapshotResp.Result.Name == "" {
+		return "", fmt.Errorf("快照响应缺少名称")
+	}
+	return snapshotResp.Result.Name, nil
+}
+
+// DownloadSnapshot 下载快照内容到 w
+func (q *QdrantClient) DownloadSnapshot(name string, w io.Writer) error {
+	resp, err := snapshotHTTPClient.Get(fmt.Sprintf("%s/collections/%s/snapshots/%s", q.baseURL, q.collection, url.PathEscape(name)))
+	if err != nil {
+		return fmt.Errorf("下载快照失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("下载快照失败，状态码: %d, 响应: %s", resp.StatusCode, string(body))
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("下载快照失败: %w", err)
+	}
+	return nil
+}
+
+// DeleteSnapshot 删除 Qdrant 服务器上的快照
+func (q *QdrantClient) DeleteSnapshot(name string) error {
+	req, err := http.NewRequest("DELETE", fmt.Sprintf("%s/collections/%s/snapshots/%s", q.baseURL, q.collection, url.PathEscape(name)), nil)
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	resp, err := q.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("删除快照失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("删除快照失败，状态码: %d, 响应: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// RestoreSnapshot 上传快照并用它替换集合中的全部数据
+func (q *QdrantClient) RestoreSnapshot(r io.Reader) error {
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+	go func() {
+		part, err := writer.CreateFormFile("snapshot", q.collection+".snapshot")
+		if err == nil {
+			_, err = io.Copy(part, r)
+		}
+		if err == nil {
+			err = writer.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	resp, err := snapshotHTTPClient.Post(
+		fmt.Sprintf("%s/collections/%s/snapshots/upload?wait=true&priority=snapshot", q.baseURL, q.collection),
+		writer.FormDataContentType(), pr)
+	if err != nil {
+		pr.CloseWithError(err)
+		return fmt.Errorf("恢复快照失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("恢复快照失败，状态码: %d, 响应: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package video
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+
+	"pixelpunk/pkg/config"
+	"pixelpunk/pkg/logger"
+)
+
+// frameTimeout 截取单帧的超时时间
+const frameTimeout = 30 * time.Second
+
+var (
+	ffmpegPath string
+	lookupOnce sync.Once
+)
+
+// FFmpegPath 返回可用的 ffmpeg 路径，未安装时返回空字符串
+// ffmpeg 是可选依赖：没有它时视频仍可上传和播放，只是没有真实的封面和关键帧分析
+func FFmpegPath() string {
+	lookupOnce.Do(func() {
+		name := config.GetConfig().Video.FFmpegPath
+		if name == "" {
+			name = "ffmpeg"
+		}
+		path, err := exec.LookPath(name)
+		if err != nil {
+			logger.Info("未找到 ffmpeg（%s），视频封面将使用占位图", name)
+			return
+		}
+		ffmpegPath = path
+		logger.Info("ffmpeg 已启用: %s", path)
+	})
+	return ffmpegPath
+}
+
+// Available 是否可以截取视频帧
+func Available() bool {
+	return FFmpegPath() != ""
+}
+
+// ExtractFrame 截取 input 在 at 秒处的一帧，返回 JPEG 数据，宽度不超过 maxWidth（0 表示原尺寸）
+func ExtractFrame(ctx context.Context, input string, at float64, maxWidth int) ([]byte, error) {
+	path := FFmpegPath()
+	if path == "" {
+		return nil, fmt.Errorf("video: ffmpeg not available")
+	}
+	ctx, cancel := context.WithTimeout(ctx, frameTimeout)
+	defer cancel()
+
+	args := []string{
+		"-hide_banner", "-loglevel", "error",
+		"-ss", strconv.FormatFloat(at, 'f', 3, 64),
+		"-i", input,
+		"-frames:v", "1",
+	}
+	if maxWidth > 0 {
+		args = append(args, "-vf", fmt.Sprintf("scale='min(%d,iw)':-2", maxWidth))
+	}
+	args = append(args, "-f", "image2", "-c:v", "mjpeg", "-q:v", "3", "pipe:1")
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("video: ffmpeg failed: %w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	if stdout.Len() == 0 {
+		return nil, fmt.Errorf("video: ffmpeg produced no frame at %.3fs", at)
+	}
+	return stdout.Bytes(), nil
+}
+
+// ExtractFrames 按 times 依次截取多帧，单帧失败时跳过，全部失败才返回错误
+func ExtractFrames(ctx context.Context, input string, times []float64, maxWidth int) ([][]byte, error) {
+	frames := make([][]byte, 0, len(times))
+	var lastErr error
+	for _, at := range times {
+		frame, err := ExtractFrame(ctx, input, at, maxWidth)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		frames = append(frames, frame)
+	}
+	if len(frames) == 0 {
+		if lastErr == nil {
+			lastErr = fmt.Errorf("video: no frames requested")
+		}
+		return nil, lastErr
+	}
+	return frames, nil
+}
+
+// PosterTime 封面截取的时间点：跳过片头黑场，取时长的10%，最多第3秒
+func PosterTime(duration float64) float64 {
+	if duration <= 0 {
+		return 0
+	}
+	return min(duration*0.1, 3)
+}
+
+// KeyFrameTimes 在时长内均匀选取 n 个时间点，避开首尾各5%
+func KeyFrameTimes(duration float64, n int) []float64 {
+	if n <= 0 {
+		return nil
+	}
+	if duration <= 0 {
+		return []float64{0}
+	}
+	start, span := duration*0.05, duration*0.9
+	times := make([]float64, n)
+	for i := range times {
+		times[i] = start + span*(float64(i)+0.5)/float64(n)
+	}
+	return times
+}
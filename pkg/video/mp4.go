@@ -0,0 +1,201 @@
+package video
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// mp4Epoch ISO BMFF 时间字段的起点
+var mp4Epoch = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// mp4Codecs 样本描述 fourcc 到通用编码名称的映射
+var mp4Codecs = map[string]string{
+	"avc1": "h264", "avc3": "h264",
+	"hvc1": "hevc", "hev1": "hevc",
+	"av01": "av1",
+	"vp08": "vp8", "vp09": "vp9",
+	"mp4v": "mpeg4",
+	"apcn": "prores", "apch": "prores", "apcs": "prores", "apco": "prores", "ap4h": "prores",
+	"mp4a": "aac",
+	"Opus": "opus",
+	"fLaC": "flac",
+	"ac-3": "ac3", "ec-3": "eac3",
+	".mp3": "mp3",
+	"alac": "alac",
+	"sowt": "pcm", "twos": "pcm", "lpcm": "pcm",
+}
+
+// box 一个 ISO BMFF box 的类型和内容
+type box struct {
+	typ  string
+	body []byte
+}
+
+// readBoxes 依次读取 data 中的 box，遇到截断的 box 时停止
+func readBoxes(data []byte) []box {
+	var boxes []box
+	for len(data) >= 8 {
+		size := uint64(binary.BigEndian.Uint32(data[0:4]))
+		typ := string(data[4:8])
+		header := uint64(8)
+		switch size {
+		case 0:
+			size = uint64(len(data))
+		case 1:
+			if len(data) < 16 {
+				return boxes
+			}
+			size = binary.BigEndian.Uint64(data[8:16])
+			header = 16
+		}
+		if size < header || size > uint64(len(data)) {
+			// 截断的 box（例如只上传了一部分的文件），保留可读的部分
+			boxes = append(boxes, box{typ: typ, body: data[min(header, uint64(len(data))):]})
+			return boxes
+		}
+		boxes = append(boxes, box{typ: typ, body: data[header:size]})
+		data = data[size:]
+	}
+	return boxes
+}
+
+func findBox(boxes []box, typ string) []byte {
+	for _, b := range boxes {
+		if b.typ == typ {
+			return b.body
+		}
+	}
+	return nil
+}
+
+// probeMP4 解析 MP4/MOV：mvhd 取时长和创建时间，各 trak 取尺寸、旋转和编码
+func probeMP4(data []byte) (*Info, error) {
+	top := readBoxes(data)
+	info := &Info{Container: ContainerMP4}
+	if ftyp := findBox(top, "ftyp"); len(ftyp) >= 4 && string(ftyp[0:4]) == "qt  " {
+		info.Container = ContainerMOV
+	}
+
+	moov := findBox(top, "moov")
+	if moov == nil {
+		return nil, fmt.Errorf("video: moov box not found")
+	}
+	children := readBoxes(moov)
+	if mvhd := findBox(children, "mvhd"); mvhd != nil {
+		created, timescale, duration := parseTimeHeader(mvhd)
+		if timescale > 0 {
+			info.Duration = float64(duration) / float64(timescale)
+		}
+		if created > 0 {
+			t := mp4Epoch.Add(time.Duration(created) * time.Second)
+			info.CreatedAt = &t
+		}
+	}
+
+	for _, b := range children {
+		if b.typ == "trak" {
+			parseTrak(b.body, info)
+		}
+	}
+	return info, nil
+}
+
+// parseTimeHeader 解析 mvhd/mdhd 的创建时间、时间刻度和时长，兼容版本0和版本1
+func parseTimeHeader(b []byte) (created, timescale, duration uint64) {
+	if len(b) < 4 {
+		return 0, 0, 0
+	}
+	if b[0] == 1 {
+		if len(b) < 32 {
+			return 0, 0, 0
+		}
+		return binary.BigEndian.Uint64(b[4:12]), uint64(binary.BigEndian.Uint32(b[20:24])), binary.BigEndian.Uint64(b[24:32])
+	}
+	if len(b) < 20 {
+		return 0, 0, 0
+	}
+	return uint64(binary.BigEndian.Uint32(b[4:8])), uint64(binary.BigEndian.Uint32(b[12:16])), uint64(binary.BigEndian.Uint32(b[16:20]))
+}
+
+// parseTrak 解析一个轨道，只取第一个视频轨道和第一个音频轨道
+func parseTrak(trak []byte, info *Info) {
+	boxes := readBoxes(trak)
+	mdia := readBoxes(findBox(boxes, "mdia"))
+	handler := ""
+	if hdlr := findBox(mdia, "hdlr"); len(hdlr) >= 12 {
+		handler = string(hdlr[8:12])
+	}
+	stbl := readBoxes(findBox(readBoxes(findBox(mdia, "minf")), "stbl"))
+	entryType, entry := firstSampleEntry(findBox(stbl, "stsd"))
+
+	switch handler {
+	case "vide":
+		if info.VideoCodec != "" {
+			return
+		}
+		info.VideoCodec = codecName(entryType)
+		if tkhd := findBox(boxes, "tkhd"); tkhd != nil {
+			info.Width, info.Height, info.Rotation = parseTrackHeader(tkhd)
+		}
+		// tkhd 尺寸缺失时回退到样本描述中的编码尺寸
+		if (info.Width == 0 || info.Height == 0) && len(entry) >= 28 {
+			info.Width = int(binary.BigEndian.Uint16(entry[24:26]))
+			info.Height = int(binary.BigEndian.Uint16(entry[26:28]))
+		}
+	case "soun":
+		if info.AudioCodec != "" {
+			return
+		}
+		info.AudioCodec = codecName(entryType)
+		if len(entry) >= 28 {
+			info.Channels = int(binary.BigEndian.Uint16(entry[16:18]))
+			info.SampleRate = int(binary.BigEndian.Uint16(entry[24:26]))
+		}
+	}
+}
+
+// firstSampleEntry 返回 stsd 中第一个样本描述的类型和内容
+func firstSampleEntry(stsd []byte) (string, []byte) {
+	if len(stsd) < 8 || binary.BigEndian.Uint32(stsd[4:8]) == 0 {
+		return "", nil
+	}
+	entries := readBoxes(stsd[8:])
+	if len(entries) == 0 {
+		return "", nil
+	}
+	return entries[0].typ, entries[0].body
+}
+
+// parseTrackHeader 解析 tkhd 的显示尺寸（16.16 定点数）和变换矩阵中的旋转角度
+func parseTrackHeader(b []byte) (width, height, rotation int) {
+	offset := 76 // 版本0：version/flags 4 + 时间与ID字段 20 + 保留/层/音量 16 + 矩阵 36
+	if len(b) > 0 && b[0] == 1 {
+		offset = 88
+	}
+	if len(b) < offset+8 {
+		return 0, 0, 0
+	}
+	matrix := b[offset-36 : offset]
+	a := int32(binary.BigEndian.Uint32(matrix[0:4]))
+	c := int32(binary.BigEndian.Uint32(matrix[4:8]))
+	switch {
+	case a == 0 && c > 0:
+		rotation = 90
+	case a == 0 && c < 0:
+		rotation = 270
+	case a < 0:
+		rotation = 180
+	}
+	width = int(binary.BigEndian.Uint32(b[offset:offset+4]) >> 16)
+	height = int(binary.BigEndian.Uint32(b[offset+4:offset+8]) >> 16)
+	return width, height, rotation
+}
+
+func codecName(fourcc string) string {
+	if name, ok := mp4Codecs[fourcc]; ok {
+		return name
+	}
+	return strings.TrimSpace(fourcc)
+}
@@ -0,0 +1,89 @@
+package video
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"math"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+// placeholderWidth 占位封面的宽度，高度按视频宽高比计算
+const placeholderWidth = 640
+
+var (
+	placeholderBackground = color.RGBA{R: 0x1f, G: 0x23, B: 0x2b, A: 0xff}
+	placeholderIcon       = color.RGBA{R: 0xe6, G: 0xe8, B: 0xeb, A: 0xff}
+)
+
+// PlaceholderPoster 没有 ffmpeg 时使用的封面：深色背景加居中的播放图标，保持视频宽高比
+func PlaceholderPoster(width, height int) []byte {
+	w, h := placeholderWidth, placeholderWidth*9/16
+	if width > 0 && height > 0 {
+		h = int(math.Round(float64(placeholderWidth) * float64(height) / float64(width)))
+		h = max(min(h, placeholderWidth*2), placeholderWidth/4)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: placeholderBackground}, image.Point{}, draw.Src)
+
+	// 播放三角形：边长取短边的 1/4，向右指向
+	size := float64(min(w, h)) / 4
+	cx, cy := float64(w)/2, float64(h)/2
+	left, right := cx-size*0.4, cx+size*0.6
+	for y := int(cy - size/2); y <= int(cy+size/2); y++ {
+		// 三角形在 y 处的右边界随离中线的距离线性收缩
+		ratio := 1 - math.Abs(float64(y)-cy)/(size/2)
+		edge := left + (right-left)*ratio
+		for x := int(left); x <= int(edge); x++ {
+			img.Set(x, y, placeholderIcon)
+		}
+	}
+
+	var buf bytes.Buffer
+	_ = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85})
+	return buf.Bytes()
+}
+
+// ContactSheet 将多帧拼成一张网格图（每行 cols 帧），用于一次性提交给 AI 分析
+// 每帧缩放为 cellWidth 宽，格子高度按第一帧的宽高比计算
+func ContactSheet(frames [][]byte, cols, cellWidth int) ([]byte, error) {
+	images := make([]image.Image, 0, len(frames))
+	for _, frame := range frames {
+		img, _, err := image.Decode(bytes.NewReader(frame))
+		if err != nil {
+			continue
+		}
+		images = append(images, img)
+	}
+	if len(images) == 0 {
+		return nil, fmt.Errorf("video: no decodable frames")
+	}
+	if len(images) == 1 {
+		return encodeJPEG(images[0])
+	}
+
+	cols = max(1, min(cols, len(images)))
+	rows := (len(images) + cols - 1) / cols
+	first := images[0].Bounds()
+	cellHeight := cellWidth * first.Dy() / max(first.Dx(), 1)
+	sheet := image.NewRGBA(image.Rect(0, 0, cols*cellWidth, rows*cellHeight))
+	draw.Draw(sheet, sheet.Bounds(), &image.Uniform{C: color.Black}, image.Point{}, draw.Src)
+	for i, img := range images {
+		x, y := i%cols*cellWidth, i/cols*cellHeight
+		xdraw.ApproxBiLinear.Scale(sheet, image.Rect(x, y, x+cellWidth, y+cellHeight), img, img.Bounds(), draw.Src, nil)
+	}
+	return encodeJPEG(sheet)
+}
+
+func encodeJPEG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
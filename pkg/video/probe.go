@@ -0,0 +1,75 @@
+package video
+
+import (
+	"bytes"
+	"errors"
+	"time"
+)
+
+// 容器格式
+const (
+	ContainerMP4  = "mp4"
+	ContainerMOV  = "mov"
+	ContainerWebM = "webm"
+)
+
+var (
+	// ErrUnsupported 不是可识别的 MP4/MOV/WebM 容器
+	ErrUnsupported = errors.New("video: unsupported container")
+	// ErrNoVideoTrack 容器中没有视频轨道
+	ErrNoVideoTrack = errors.New("video: no video track")
+)
+
+// Info 从容器头部解析出的视频元数据
+type Info struct {
+	Container  string
+	Duration   float64 // 秒
+	Width      int     // 显示宽度，已按旋转角度交换
+	Height     int     // 显示高度，已按旋转角度交换
+	Rotation   int     // 0、90、180、270
+	VideoCodec string
+	AudioCodec string
+	Bitrate    int // 平均码率（bps），按文件大小与时长估算
+	SampleRate int
+	Channels   int
+	CreatedAt  *time.Time // 容器记录的创建时间，通常为拍摄时间
+}
+
+// Probe 解析视频容器，只读取头部结构，不解码任何帧
+func Probe(data []byte) (*Info, error) {
+	var (
+		info *Info
+		err  error
+	)
+	switch {
+	case len(data) >= 4 && bytes.Equal(data[:4], []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		info, err = probeWebM(data)
+	case len(data) >= 8 && isBMFFBox(string(data[4:8])):
+		info, err = probeMP4(data)
+	default:
+		return nil, ErrUnsupported
+	}
+	if err != nil {
+		return nil, err
+	}
+	if info.VideoCodec == "" {
+		return nil, ErrNoVideoTrack
+	}
+
+	if info.Rotation == 90 || info.Rotation == 270 {
+		info.Width, info.Height = info.Height, info.Width
+	}
+	if info.Duration > 0 {
+		info.Bitrate = int(float64(len(data)) * 8 / info.Duration)
+	}
+	return info, nil
+}
+
+// isBMFFBox 文件开头允许出现的 ISO BMFF/QuickTime 顶层 box
+func isBMFFBox(boxType string) bool {
+	switch boxType {
+	case "ftyp", "moov", "mdat", "wide", "free", "skip":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+package video
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/jpeg"
+	"math"
+	"testing"
+)
+
+func mp4Box(typ string, parts ...[]byte) []byte {
+	body := bytes.Join(parts, nil)
+	b := make([]byte, 8, 8+len(body))
+	binary.BigEndian.PutUint32(b, uint32(8+len(body)))
+	copy(b[4:], typ)
+	return append(b, body...)
+}
+
+func u16(v uint16) []byte { return binary.BigEndian.AppendUint16(nil, v) }
+func u32(v uint32) []byte { return binary.BigEndian.AppendUint32(nil, v) }
+
+// testTkhd 版本0的 tkhd，matrix 的 a、b 分量决定旋转角度
+func testTkhd(width, height uint32, a, b int32) []byte {
+	body := make([]byte, 40)
+	body = append(body, u32(uint32(a))...)
+	body = append(body, u32(uint32(b))...)
+	body = append(body, make([]byte, 28)...)
+	body = append(body, u32(width<<16)...)
+	body = append(body, u32(height<<16)...)
+	return mp4Box("tkhd", body)
+}
+
+func testTrak(handler string, tkhd []byte, entry []byte) []byte {
+	hdlr := mp4Box("hdlr", make([]byte, 8), []byte(handler), make([]byte, 12))
+	stsd := mp4Box("stsd", u32(0), u32(1), entry)
+	return mp4Box("trak", tkhd, mp4Box("mdia", hdlr, mp4Box("minf", mp4Box("stbl", stsd))))
+}
+
+func testMP4(brand string) []byte {
+	// mvhd 版本0：创建时间 2020-01-01（距1904年的秒数），时间刻度1000，时长12.5秒
+	created := uint32(3660681600)
+	mvhd := mp4Box("mvhd", u32(0), u32(created), u32(created), u32(1000), u32(12500), make([]byte, 80))
+	video := testTrak("vide", testTkhd(1920, 1080, 0, 0x10000), mp4Box("avc1", make([]byte, 78)))
+	audioEntry := append(make([]byte, 16), u16(2)...)
+	audioEntry = append(audioEntry, make([]byte, 6)...)
+	audioEntry = append(audioEntry, u32(48000<<16)...)
+	audio := testTrak("soun", mp4Box("tkhd", make([]byte, 84)), mp4Box("mp4a", audioEntry))
+	return bytes.Join([][]byte{
+		mp4Box("ftyp", []byte(brand), u32(0)),
+		mp4Box("mdat", make([]byte, 1000)),
+		mp4Box("moov", mvhd, video, audio),
+	}, nil)
+}
+
+func TestProbeMP4(t *testing.T) {
+	info, err := Probe(testMP4("isom"))
+	if err != nil {
+		t.Fatalf("Probe: %v", err)
+	}
+	if info.Container != ContainerMP4 || info.Duration != 12.5 || info.VideoCodec != "h264" || info.AudioCodec != "aac" {
+		t.Errorf("unexpected info %+v", info)
+	}
+	// 旋转90度后显示尺寸交换
+	if info.Rotation != 90 || info.Width != 1080 || info.Height != 1920 {
+		t.Errorf("rotation %d size %dx%d, want 90 1080x1920", info.Rotation, info.Width, info.Height)
+	}
+	if info.SampleRate != 48000 || info.Channels != 2 {
+		t.Errorf("audio %d Hz %d ch, want 48000 Hz 2 ch", info.SampleRate, info.Channels)
+	}
+	if info.CreatedAt == nil || info.CreatedAt.Year() != 2020 {
+		t.Errorf("created at %v, want 2020", info.CreatedAt)
+	}
+	if info.Bitrate == 0 {
+		t.Error("bitrate should be estimated from size and duration")
+	}
+}
+
+func TestProbeMOV(t *testing.T) {
+	info, err := Probe(testMP4("qt  "))
+	if err != nil || info.Container != ContainerMOV {
+		t.Fatalf("Probe = %+v, %v, want mov", info, err)
+	}
+}
+
+func ebml(id uint64, parts ...[]byte) []byte {
+	body := bytes.Join(parts, nil)
+	var out []byte
+	for shift := 24; shift >= 0; shift -= 8 {
+		if b := byte(id >> shift); b != 0 || len(out) > 0 {
+			out = append(out, b)
+		}
+	}
+	// 长度统一用8字节编码
+	size := make([]byte, 8)
+	binary.BigEndian.PutUint64(size, uint64(len(body)))
+	size[0] = 0x01
+	out = append(out, size...)
+	return append(out, body...)
+}
+
+func f64(v float64) []byte { return binary.BigEndian.AppendUint64(nil, math.Float64bits(v)) }
+
+func TestProbeWebM(t *testing.T) {
+	header := ebml(ebmlHeader, ebml(ebmlDocType, []byte("webm")))
+	info := ebml(mkvInfo, ebml(mkvTimecodeScale, []byte{0x0F, 0x42, 0x40}), ebml(mkvDuration, f64(8000)))
+	video := ebml(mkvTrackEntry,
+		ebml(mkvTrackType, []byte{1}),
+		ebml(mkvCodecID, []byte("V_VP9")),
+		ebml(mkvVideo, ebml(mkvPixelWidth, u16(1280)), ebml(mkvPixelHeight, u16(720))))
+	audio := ebml(mkvTrackEntry,
+		ebml(mkvTrackType, []byte{2}),
+		ebml(mkvCodecID, []byte("A_OPUS")),
+		ebml(mkvAudio, ebml(mkvSamplingFreq, f64(48000)), ebml(mkvChannels, []byte{2})))
+	// 未知长度的 Cluster（MediaRecorder 录制的文件常见）
+	cluster := append([]byte{0x1F, 0x43, 0xB6, 0x75, 0x01, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}, make([]byte, 64)...)
+	segment := ebml(mkvSegment, info, ebml(mkvTracks, video, audio), cluster)
+
+	got, err := Probe(append(header, segment...))
+	if err != nil {
+		t.Fatalf("Probe: %v", err)
+	}
+	if got.Container != ContainerWebM || got.Duration != 8 || got.Width != 1280 || got.Height != 720 {
+		t.Errorf("unexpected info %+v", got)
+	}
+	if got.VideoCodec != "vp9" || got.AudioCodec != "opus" || got.SampleRate != 48000 || got.Channels != 2 {
+		t.Errorf("unexpected tracks %+v", got)
+	}
+}
+
+func TestProbeRejectsNonVideo(t *testing.T) {
+	if _, err := Probe([]byte("\x89PNG\r\n\x1a\n0000")); err != ErrUnsupported {
+		t.Errorf("png: err = %v, want ErrUnsupported", err)
+	}
+	audioOnly := mp4Box("moov", testTrak("soun", mp4Box("tkhd", make([]byte, 84)), mp4Box("mp4a", make([]byte, 28))))
+	if _, err := Probe(append(mp4Box("ftyp", []byte("M4A "), u32(0)), audioOnly...)); err != ErrNoVideoTrack {
+		t.Errorf("audio only: err = %v, want ErrNoVideoTrack", err)
+	}
+}
+
+func TestKeyFrameTimes(t *testing.T) {
+	times := KeyFrameTimes(100, 4)
+	want := []float64{16.25, 38.75, 61.25, 83.75}
+	for i := range want {
+		if math.Abs(times[i]-want[i]) > 1e-9 {
+			t.Fatalf("KeyFrameTimes = %v, want %v", times, want)
+		}
+	}
+	if PosterTime(600) != 3 || PosterTime(10) != 1 {
+		t.Errorf("PosterTime = %v, %v", PosterTime(600), PosterTime(10))
+	}
+}
+
+func TestPlaceholderPosterKeepsAspectRatio(t *testing.T) {
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(PlaceholderPoster(1080, 1920)))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if cfg.Width != 640 || cfg.Height != 1138 {
+		t.Errorf("poster %dx%d, want 640x1138", cfg.Width, cfg.Height)
+	}
+
+	frame := PlaceholderPoster(0, 0)
+	sheet, err := ContactSheet([][]byte{frame, frame, frame}, 2, 160)
+	if err != nil {
+		t.Fatalf("ContactSheet: %v", err)
+	}
+	if cfg, _ := jpeg.DecodeConfig(bytes.NewReader(sheet)); cfg.Width != 320 || cfg.Height != 180 {
+		t.Errorf("sheet %dx%d, want 320x180", cfg.Width, cfg.Height)
+	}
+}
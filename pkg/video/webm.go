@@ -0,0 +1,218 @@
+package video
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// EBML/Matroska 元素 ID（保留长度标记位）
+const (
+	ebmlHeader        = 0x1A45DFA3
+	ebmlDocType       = 0x4282
+	mkvSegment        = 0x18538067
+	mkvInfo           = 0x1549A966
+	mkvTimecodeScale  = 0x2AD7B1
+	mkvDuration       = 0x4489
+	mkvDateUTC        = 0x4461
+	mkvTracks         = 0x1654AE6B
+	mkvTrackEntry     = 0xAE
+	mkvTrackType      = 0x83
+	mkvCodecID        = 0x86
+	mkvVideo          = 0xE0
+	mkvPixelWidth     = 0xB0
+	mkvPixelHeight    = 0xBA
+	mkvAudio          = 0xE1
+	mkvSamplingFreq   = 0xB5
+	mkvChannels       = 0x9F
+	mkvCluster        = 0x1F43B675
+	mkvTrackTypeVideo = 1
+	mkvTrackTypeAudio = 2
+)
+
+// mkvEpoch Matroska DateUTC 的起点
+var mkvEpoch = time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+
+var mkvCodecs = map[string]string{
+	"V_VP8":            "vp8",
+	"V_VP9":            "vp9",
+	"V_AV1":            "av1",
+	"V_MPEG4/ISO/AVC":  "h264",
+	"V_MPEGH/ISO/HEVC": "hevc",
+	"A_OPUS":           "opus",
+	"A_VORBIS":         "vorbis",
+	"A_AAC":            "aac",
+	"A_FLAC":           "flac",
+	"A_MPEG/L3":        "mp3",
+}
+
+// element 一个 EBML 元素的 ID 和内容
+type element struct {
+	id   uint64
+	body []byte
+}
+
+// readVint 读取 EBML 变长整数，keepMarker 为 true 时保留长度标记位（用于元素 ID）
+func readVint(b []byte, keepMarker bool) (value uint64, n int, unknown bool) {
+	if len(b) == 0 || b[0] == 0 {
+		return 0, 0, false
+	}
+	n = 1
+	for mask := byte(0x80); b[0]&mask == 0; mask >>= 1 {
+		n++
+	}
+	if n > 8 || len(b) < n {
+		return 0, 0, false
+	}
+	value = uint64(b[0])
+	if !keepMarker {
+		value &= uint64(0xFF >> n)
+	}
+	allOnes := value == uint64(0xFF>>n)
+	for i := 1; i < n; i++ {
+		value = value<<8 | uint64(b[i])
+		allOnes = allOnes && b[i] == 0xFF
+	}
+	return value, n, !keepMarker && allOnes
+}
+
+// readElements 依次读取 data 中的元素，未知长度（直播录制的文件常见）或截断的元素取到 data 末尾
+func readElements(data []byte) []element {
+	var elements []element
+	for len(data) > 0 {
+		id, idLen, _ := readVint(data, true)
+		if idLen == 0 {
+			return elements
+		}
+		size, sizeLen, unknown := readVint(data[idLen:], false)
+		if sizeLen == 0 {
+			return elements
+		}
+		start := idLen + sizeLen
+		end := uint64(len(data))
+		if !unknown && uint64(start)+size < end {
+			end = uint64(start) + size
+		}
+		elements = append(elements, element{id: id, body: data[start:end]})
+		data = data[end:]
+	}
+	return elements
+}
+
+func findElement(elements []element, id uint64) []byte {
+	for _, e := range elements {
+		if e.id == id {
+			return e.body
+		}
+	}
+	return nil
+}
+
+func ebmlUint(b []byte) uint64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
+
+func ebmlFloat(b []byte) float64 {
+	switch len(b) {
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b)))
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
+
+// probeWebM 解析 WebM：Segment/Info 取时长和创建时间，Segment/Tracks 取尺寸和编码
+func probeWebM(data []byte) (*Info, error) {
+	top := readElements(data)
+	header := findElement(top, ebmlHeader)
+	if header == nil {
+		return nil, ErrUnsupported
+	}
+	info := &Info{Container: ContainerWebM}
+	if docType := string(findElement(readElements(header), ebmlDocType)); docType != "webm" && docType != "matroska" {
+		return nil, fmt.Errorf("video: unsupported EBML doctype %q", docType)
+	}
+
+	segment := findElement(top, mkvSegment)
+	if segment == nil {
+		return nil, fmt.Errorf("video: segment not found")
+	}
+	for _, e := range readSegmentHead(segment) {
+		switch e.id {
+		case mkvInfo:
+			parseSegmentInfo(e.body, info)
+		case mkvTracks:
+			for _, track := range readElements(e.body) {
+				if track.id == mkvTrackEntry {
+					parseTrackEntry(track.body, info)
+				}
+			}
+		}
+	}
+	return info, nil
+}
+
+// readSegmentHead 读取 Segment 的子元素，遇到第一个 Cluster 即停止，元数据都在它之前
+func readSegmentHead(segment []byte) []element {
+	var elements []element
+	for _, e := range readElements(segment) {
+		if e.id == mkvCluster {
+			break
+		}
+		elements = append(elements, e)
+	}
+	return elements
+}
+
+func parseSegmentInfo(b []byte, info *Info) {
+	children := readElements(b)
+	scale := uint64(1000000)
+	if v := findElement(children, mkvTimecodeScale); v != nil {
+		scale = ebmlUint(v)
+	}
+	if v := findElement(children, mkvDuration); v != nil {
+		info.Duration = ebmlFloat(v) * float64(scale) / 1e9
+	}
+	if v := findElement(children, mkvDateUTC); len(v) == 8 {
+		t := mkvEpoch.Add(time.Duration(int64(binary.BigEndian.Uint64(v))))
+		info.CreatedAt = &t
+	}
+}
+
+// parseTrackEntry 解析一个轨道，只取第一个视频轨道和第一个音频轨道
+func parseTrackEntry(b []byte, info *Info) {
+	children := readElements(b)
+	codec := string(findElement(children, mkvCodecID))
+	switch ebmlUint(findElement(children, mkvTrackType)) {
+	case mkvTrackTypeVideo:
+		if info.VideoCodec != "" {
+			return
+		}
+		info.VideoCodec = mkvCodecName(codec)
+		video := readElements(findElement(children, mkvVideo))
+		info.Width = int(ebmlUint(findElement(video, mkvPixelWidth)))
+		info.Height = int(ebmlUint(findElement(video, mkvPixelHeight)))
+	case mkvTrackTypeAudio:
+		if info.AudioCodec != "" {
+			return
+		}
+		info.AudioCodec = mkvCodecName(codec)
+		audio := readElements(findElement(children, mkvAudio))
+		info.SampleRate = int(ebmlFloat(findElement(audio, mkvSamplingFreq)))
+		info.Channels = int(ebmlUint(findElement(audio, mkvChannels)))
+	}
+}
+
+func mkvCodecName(codecID string) string {
+	if name, ok := mkvCodecs[codecID]; ok {
+		return name
+	}
+	return strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(codecID, "V_"), "A_"))
+}
@@ -60,7 +60,7 @@ func DefaultManagerConfig() *ManagerConfig {
 		WatermarkPath:       "internal/static", // 静态资源目录
 		CacheEnabled:        false,
 		MaxImageSize:        4096,
-		EnableTextWatermark: false,
+		EnableTextWatermark: true,
 	}
 }
 
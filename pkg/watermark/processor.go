@@ -21,7 +21,7 @@ type Processor struct {
 	watermarkImagePath  string
 	maxImageSize        int
 	defaultFontSize     float64
-	enableTextWatermark bool // 预留开关（当前不支持文字水印）
+	enableTextWatermark bool // 文字水印开关
 }
 
 func NewProcessor() *Processor {
@@ -68,7 +68,7 @@ func (p *Processor) ProcessImage(file image.Image, config *WatermarkConfig) (ima
 	draw.Draw(newImg, bounds, file, bounds.Min, draw.Over)
 
 	if err := p.applyImageWatermark(newImg, config); err != nil {
-		return nil, fmt.Errorf("应用文件水印失败: %w", err)
+		return nil, fmt.Errorf("应用水印失败: %w", err)
 	}
 
 	return newImg, nil
@@ -122,9 +122,22 @@ func (p *Processor) hasTransparency(file image.Image) bool {
 }
 
 func (p *Processor) applyImageWatermark(dst *image.RGBA, config *WatermarkConfig) error {
-	watermarkImg, err := p.loadWatermarkImage(config)
-	if err != nil {
-		return fmt.Errorf("加载水印文件失败: %w", err)
+	var watermarkImg image.Image
+	scale := config.Scale
+	if config.Type == TypeText {
+		textImg, err := p.renderTextWatermark(config)
+		if err != nil {
+			return fmt.Errorf("渲染文字水印失败: %w", err)
+		}
+		watermarkImg = textImg
+		// 文字按缩放后的字号渲染，不再二次缩放
+		scale = 1.0
+	} else {
+		img, err := p.loadWatermarkImage(config)
+		if err != nil {
+			return fmt.Errorf("加载水印文件失败: %w", err)
+		}
+		watermarkImg = img
 	}
 
 	wmBounds := watermarkImg.Bounds()
@@ -133,7 +146,6 @@ func (p *Processor) applyImageWatermark(dst *image.RGBA, config *WatermarkConfig
 
 	// 前端已经生成了缩放后的水印图片，后端不再缩放，直接使用原始尺寸
 	// 如果 Scale 未设置或为 0，使用 1.0（不缩放）
-	scale := config.Scale
 	if scale <= 0 {
 		scale = 1.0
 	}
@@ -148,23 +160,26 @@ func (p *Processor) applyImageWatermark(dst *image.RGBA, config *WatermarkConfig
 
 	scaled := p.scaleImageNearest(watermarkImg, wmWidth, wmHeight)
 	finalSrc := image.Image(scaled)
-
-	var drawRect image.Rectangle
 	if config.Rotation != 0 {
-		rotated := p.rotateImageGeneric(scaled, float64(config.Rotation))
-		finalSrc = rotated
-		rotW := rotated.Bounds().Dx()
-		rotH := rotated.Bounds().Dy()
-		bounds := dst.Bounds()
-		// 使用旋转后的尺寸计算位置，避免被裁切；新算法支持锚点+偏移
-		pos := p.calculatePositionWithConfig(bounds.Max.X, bounds.Max.Y, rotW, rotH, config)
-		drawRect = image.Rect(pos.X, pos.Y, pos.X+rotW, pos.Y+rotH)
-	} else {
-		bounds := dst.Bounds()
-		pos := p.calculatePositionWithConfig(bounds.Max.X, bounds.Max.Y, wmWidth, wmHeight, config)
-		drawRect = image.Rect(pos.X, pos.Y, pos.X+wmWidth, pos.Y+wmHeight)
+		finalSrc = p.rotateImageGeneric(scaled, float64(config.Rotation))
 	}
 
+	if config.Mode == ModeTiled {
+		p.drawTiledWatermark(dst, trimTransparent(finalSrc), config)
+		return nil
+	}
+
+	// 使用旋转后的尺寸计算位置，避免被裁切；新算法支持锚点+偏移
+	srcW := finalSrc.Bounds().Dx()
+	srcH := finalSrc.Bounds().Dy()
+	bounds := dst.Bounds()
+	pos := p.calculatePositionWithConfig(bounds.Max.X, bounds.Max.Y, srcW, srcH, config)
+	p.drawWatermark(dst, finalSrc, image.Rect(pos.X, pos.Y, pos.X+srcW, pos.Y+srcH), config)
+	return nil
+}
+
+// drawWatermark 在指定区域绘制水印（含阴影）
+func (p *Processor) drawWatermark(dst *image.RGBA, src image.Image, drawRect image.Rectangle, config *WatermarkConfig) {
 	if config.Shadow {
 		shadowRect := image.Rect(drawRect.Min.X+config.ShadowOffsetX, drawRect.Min.Y+config.ShadowOffsetY, drawRect.Max.X+config.ShadowOffsetX, drawRect.Max.Y+config.ShadowOffsetY)
 		// 解析前端传入的阴影颜色，默认黑色
@@ -185,11 +200,83 @@ func (p *Processor) applyImageWatermark(dst *image.RGBA, config *WatermarkConfig
 		if shadowOpacity > 1.0 {
 			shadowOpacity = 1.0
 		}
-		p.drawShadowWithMask(dst, finalSrc, shadowRect, shadowCol, shadowOpacity)
+		p.drawShadowWithMask(dst, src, shadowRect, shadowCol, shadowOpacity)
 	}
 
-	p.drawImageWithOpacity(dst, finalSrc, drawRect, config.Opacity)
-	return nil
+	p.drawImageWithOpacity(dst, src, drawRect, config.Opacity)
+}
+
+// minTileStep 平铺水印的最小步长，避免极小水印产生过多绘制
+const minTileStep = 16
+
+// drawTiledWatermark 平铺水印铺满整张图片，奇数行错开半个步长；平铺模式忽略位置与偏移
+func (p *Processor) drawTiledWatermark(dst *image.RGBA, src image.Image, config *WatermarkConfig) {
+	for _, rect := range tileRects(dst.Bounds(), src.Bounds().Dx(), src.Bounds().Dy(), config.TileSpacingX, config.TileSpacingY) {
+		p.drawWatermark(dst, src, rect, config)
+	}
+}
+
+// tileRects 计算平铺水印的各个绘制区域，间距为0时使用水印尺寸的一半
+func tileRects(bounds image.Rectangle, w, h, spacingX, spacingY int) []image.Rectangle {
+	if w <= 0 || h <= 0 {
+		return nil
+	}
+	if spacingX <= 0 {
+		spacingX = w / 2
+	}
+	if spacingY <= 0 {
+		spacingY = h / 2
+	}
+	stepX := maxInt(w+spacingX, minTileStep)
+	stepY := maxInt(h+spacingY, minTileStep)
+
+	var rects []image.Rectangle
+	row := 0
+	for y := bounds.Min.Y - stepY/2; y < bounds.Max.Y; y += stepY {
+		startX := bounds.Min.X
+		if row%2 == 0 {
+			startX -= stepX / 2
+		}
+		for x := startX; x < bounds.Max.X; x += stepX {
+			rects = append(rects, image.Rect(x, y, x+w, y+h))
+		}
+		row++
+	}
+	return rects
+}
+
+// trimTransparent 裁掉四周完全透明的区域（旋转后的水印外接正方形留白较多）
+func trimTransparent(img image.Image) image.Image {
+	b := img.Bounds()
+	minX, minY, maxX, maxY := b.Max.X, b.Max.Y, b.Min.X, b.Min.Y
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a == 0 {
+				continue
+			}
+			if x < minX {
+				minX = x
+			}
+			if x >= maxX {
+				maxX = x + 1
+			}
+			if y < minY {
+				minY = y
+			}
+			if y >= maxY {
+				maxY = y + 1
+			}
+		}
+	}
+	if minX >= maxX || minY >= maxY {
+		return img
+	}
+	if sub, ok := img.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		return sub.SubImage(image.Rect(minX, minY, maxX, maxY))
+	}
+	return img
 }
 
 func (p *Processor) drawShadowWithMask(dst *image.RGBA, src image.Image, rect image.Rectangle, shadow color.RGBA, opacity float64) {
@@ -312,19 +399,9 @@ func (p *Processor) loadFromBase64(base64Data string) (image.Image, error) {
 }
 
 func (p *Processor) loadFromFile(fileURL string) (image.Image, error) {
-	filePath := strings.TrimPrefix(fileURL, "/")
-
-	// 安全检查：防止路径遍历攻击
-	if strings.Contains(filePath, "..") {
-		return nil, fmt.Errorf("非法文件路径：包含 '..' 路径遍历")
-	}
-
-	fullPath := fmt.Sprintf("%s/%s", p.watermarkImagePath, filePath)
-	cleanPath := strings.ReplaceAll(fullPath, "\\", "/")
-
-	absBasePath := p.watermarkImagePath
-	if !strings.HasPrefix(cleanPath, absBasePath) {
-		return nil, fmt.Errorf("非法文件路径：超出允许目录范围")
+	cleanPath, err := p.resolveAssetPath(fileURL)
+	if err != nil {
+		return nil, err
 	}
 
 	fileData, err := readFileBytes(cleanPath)
@@ -340,6 +417,25 @@ func (p *Processor) loadFromFile(fileURL string) (image.Image, error) {
 	return img, nil
 }
 
+// resolveAssetPath 将水印图片/字体的相对路径解析到水印目录下
+func (p *Processor) resolveAssetPath(fileURL string) (string, error) {
+	filePath := strings.TrimPrefix(fileURL, "/")
+
+	// 安全检查：防止路径遍历攻击
+	if strings.Contains(filePath, "..") {
+		return "", fmt.Errorf("非法文件路径：包含 '..' 路径遍历")
+	}
+
+	fullPath := fmt.Sprintf("%s/%s", p.watermarkImagePath, filePath)
+	cleanPath := strings.ReplaceAll(fullPath, "\\", "/")
+
+	absBasePath := p.watermarkImagePath
+	if !strings.HasPrefix(cleanPath, absBasePath) {
+		return "", fmt.Errorf("非法文件路径：超出允许目录范围")
+	}
+	return cleanPath, nil
+}
+
 func readFileBytes(path string) ([]byte, error) {
 	file, err := os.Open(path)
 	if err != nil {
@@ -465,12 +561,24 @@ func (p *Processor) ValidateConfig(config *WatermarkConfig) error {
 	if !config.Enabled {
 		return nil
 	}
-	if config.Type != TypeImage && config.Type != TypeFile {
-		return fmt.Errorf("仅支持图片水印")
+	switch config.Type {
+	case TypeImage:
+		if config.FileBase64 == "" && len(config.FileData) == 0 && config.GeneratedImage == "" && config.GeneratedFile == "" && config.FileURL == "" {
+			return fmt.Errorf("图片水印必须指定水印数据源（fileBase64[前端生成]/fileURL[后端文件]/其他）")
+		}
+	case TypeText:
+		if err := p.validateTextConfig(config); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("仅支持图片水印或文字水印")
 	}
 
-	if config.FileBase64 == "" && len(config.FileData) == 0 && config.GeneratedImage == "" && config.GeneratedFile == "" && config.FileURL == "" {
-		return fmt.Errorf("图片水印必须指定水印数据源（fileBase64[前端生成]/fileURL[后端文件]/其他）")
+	if config.Mode != "" && config.Mode != ModeSingle && config.Mode != ModeTiled {
+		return fmt.Errorf("水印模式仅支持 single 或 tiled")
+	}
+	if config.TileSpacingX < 0 || config.TileSpacingY < 0 || config.TileSpacingX > p.maxImageSize || config.TileSpacingY > p.maxImageSize {
+		return fmt.Errorf("平铺间距无效")
 	}
 	if config.Opacity < 0 || config.Opacity > 1 {
 		return fmt.Errorf("透明度必须在0-1之间")
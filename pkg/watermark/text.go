@@ -0,0 +1,228 @@
+package watermark
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// 文字水印模板变量
+const (
+	VarUsername = "username"
+	VarFileID   = "file_id"
+	VarFilename = "filename"
+	VarDate     = "date"
+	VarDatetime = "datetime"
+)
+
+const (
+	maxTextLength    = 200
+	minFontSize      = 8
+	maxFontSize      = 512
+	maxStrokeWidth   = 10
+	defaultTextColor = "#ffffff"
+)
+
+var (
+	builtinFontOnce sync.Once
+	builtinRegular  *opentype.Font
+	builtinBold     *opentype.Font
+	builtinFontErr  error
+
+	customFonts sync.Map // 字体文件路径 -> *opentype.Font
+)
+
+// ApplyTemplateVariables 替换文字中的 {name} 模板变量
+// 未提供的 date/datetime 使用当前时间，未知变量保持原样
+func ApplyTemplateVariables(text string, vars map[string]string) string {
+	if !strings.Contains(text, "{") {
+		return text
+	}
+
+	now := time.Now()
+	values := map[string]string{
+		VarDate:     now.Format("2006-01-02"),
+		VarDatetime: now.Format("2006-01-02 15:04"),
+	}
+	for k, v := range vars {
+		values[k] = v
+	}
+
+	pairs := make([]string, 0, len(values)*2)
+	for k, v := range values {
+		pairs = append(pairs, "{"+k+"}", v)
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+func (p *Processor) validateTextConfig(config *WatermarkConfig) error {
+	if !p.enableTextWatermark {
+		return fmt.Errorf("文字水印未启用")
+	}
+	if strings.TrimSpace(config.Text) == "" {
+		return fmt.Errorf("文字水印内容不能为空")
+	}
+	if utf8.RuneCountInString(config.Text) > maxTextLength {
+		return fmt.Errorf("文字水印内容不能超过%d个字符", maxTextLength)
+	}
+	if config.FontSize != 0 && (config.FontSize < minFontSize || config.FontSize > maxFontSize) {
+		return fmt.Errorf("字号必须在%d-%d之间", minFontSize, maxFontSize)
+	}
+	if config.StrokeWidth < 0 || config.StrokeWidth > maxStrokeWidth {
+		return fmt.Errorf("描边宽度必须在0-%d之间", maxStrokeWidth)
+	}
+	if config.FontColor != "" {
+		if _, err := p.parseColor(config.FontColor); err != nil {
+			return fmt.Errorf("文字颜色无效: %w", err)
+		}
+	}
+	if config.StrokeWidth > 0 && config.StrokeColor != "" {
+		if _, err := p.parseColor(config.StrokeColor); err != nil {
+			return fmt.Errorf("描边颜色无效: %w", err)
+		}
+	}
+	if config.FontFile != "" {
+		ext := strings.ToLower(filepath.Ext(config.FontFile))
+		if ext != ".ttf" && ext != ".otf" {
+			return fmt.Errorf("字体文件仅支持 ttf 或 otf")
+		}
+		if _, err := p.resolveAssetPath(config.FontFile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderTextWatermark 将文字渲染为透明背景的图片，字号已包含 Scale
+func (p *Processor) renderTextWatermark(config *WatermarkConfig) (*image.NRGBA, error) {
+	text := strings.TrimSpace(ApplyTemplateVariables(config.Text, config.Variables))
+	if text == "" {
+		return nil, fmt.Errorf("文字水印内容为空")
+	}
+
+	f, err := p.loadFont(config)
+	if err != nil {
+		return nil, err
+	}
+
+	size := config.FontSize
+	if size <= 0 {
+		size = p.defaultFontSize
+	}
+	if config.Scale > 0 {
+		size *= config.Scale
+	}
+
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingNone})
+	if err != nil {
+		return nil, fmt.Errorf("创建字体失败: %w", err)
+	}
+	defer face.Close()
+
+	fill := p.colorOrDefault(config.FontColor, defaultTextColor)
+	stroke := config.StrokeWidth
+	strokeCol := p.colorOrDefault(config.StrokeColor, "#000000")
+
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	metrics := face.Metrics()
+	lineHeight := metrics.Height.Ceil()
+	ascent := metrics.Ascent.Ceil()
+
+	width := 0
+	for _, line := range lines {
+		if w := font.MeasureString(face, line).Ceil(); w > width {
+			width = w
+		}
+	}
+
+	pad := stroke + 1
+	imgW := width + pad*2
+	imgH := lineHeight*len(lines) + pad*2
+	if width <= 0 || lineHeight <= 0 {
+		return nil, fmt.Errorf("文字水印尺寸无效")
+	}
+	if imgW > p.maxImageSize || imgH > p.maxImageSize {
+		return nil, fmt.Errorf("文字水印尺寸过大")
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, imgW, imgH))
+	drawLines := func(src image.Image, dx, dy int) {
+		d := &font.Drawer{Dst: img, Src: src, Face: face}
+		for i, line := range lines {
+			d.Dot = fixed.P(pad+dx, pad+ascent+i*lineHeight+dy)
+			d.DrawString(line)
+		}
+	}
+
+	// 描边：在描边半径内的各个偏移位置先绘制描边色，再绘制文字本身
+	if stroke > 0 {
+		src := image.NewUniform(strokeCol)
+		for dy := -stroke; dy <= stroke; dy++ {
+			for dx := -stroke; dx <= stroke; dx++ {
+				if (dx == 0 && dy == 0) || dx*dx+dy*dy > stroke*stroke {
+					continue
+				}
+				drawLines(src, dx, dy)
+			}
+		}
+	}
+	drawLines(image.NewUniform(fill), 0, 0)
+
+	return img, nil
+}
+
+func (p *Processor) colorOrDefault(colorStr, fallback string) color.Color {
+	if c, err := p.parseColor(colorStr); err == nil {
+		return c
+	}
+	c, _ := p.parseColor(fallback)
+	return c
+}
+
+func (p *Processor) loadFont(config *WatermarkConfig) (*opentype.Font, error) {
+	if config.FontFile == "" {
+		builtinFontOnce.Do(func() {
+			if builtinRegular, builtinFontErr = opentype.Parse(goregular.TTF); builtinFontErr != nil {
+				return
+			}
+			builtinBold, builtinFontErr = opentype.Parse(gobold.TTF)
+		})
+		if builtinFontErr != nil {
+			return nil, fmt.Errorf("加载内置字体失败: %w", builtinFontErr)
+		}
+		if config.Bold {
+			return builtinBold, nil
+		}
+		return builtinRegular, nil
+	}
+
+	path, err := p.resolveAssetPath(config.FontFile)
+	if err != nil {
+		return nil, err
+	}
+	if cached, ok := customFonts.Load(path); ok {
+		return cached.(*opentype.Font), nil
+	}
+
+	data, err := readFileBytes(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取字体文件失败 %s: %w", path, err)
+	}
+	f, err := opentype.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("解析字体文件失败 %s: %w", path, err)
+	}
+	customFonts.Store(path, f)
+	return f, nil
+}
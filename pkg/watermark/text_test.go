@@ -0,0 +1,119 @@
+package watermark
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestApplyTemplateVariables 验证模板变量替换
+func TestApplyTemplateVariables(t *testing.T) {
+	got := ApplyTemplateVariables("© {username} {file_id} {unknown}", map[string]string{
+		VarUsername: "alice",
+		VarFileID:   "abc123",
+	})
+	if got != "© alice abc123 {unknown}" {
+		t.Errorf("模板变量替换错误: %q", got)
+	}
+
+	today := time.Now().Format("2006-01-02")
+	if got := ApplyTemplateVariables("{date}", nil); got != today {
+		t.Errorf("默认日期错误: 期望 %q, 实际 %q", today, got)
+	}
+	if got := ApplyTemplateVariables("{date}", map[string]string{VarDate: "2024-01-02"}); got != "2024-01-02" {
+		t.Errorf("自定义日期未生效: %q", got)
+	}
+}
+
+// TestTileRects 验证平铺区域覆盖整张图片
+func TestTileRects(t *testing.T) {
+	bounds := image.Rect(0, 0, 500, 300)
+	rects := tileRects(bounds, 100, 40, 50, 20)
+	if len(rects) == 0 {
+		t.Fatal("平铺区域为空")
+	}
+
+	var covered image.Rectangle
+	for i, r := range rects {
+		if r.Dx() != 100 || r.Dy() != 40 {
+			t.Fatalf("平铺区域尺寸错误: %v", r)
+		}
+		if i == 0 {
+			covered = r
+		} else {
+			covered = covered.Union(r)
+		}
+	}
+	if !bounds.In(covered) {
+		t.Errorf("平铺区域未覆盖整张图片: %v", covered)
+	}
+
+	if rects := tileRects(bounds, 1, 1, 0, 0); len(rects) > (500/minTileStep+2)*(300/minTileStep+2) {
+		t.Errorf("极小水印的平铺数量过多: %d", len(rects))
+	}
+}
+
+// TestTextWatermark 验证文字水印的校验与渲染
+func TestTextWatermark(t *testing.T) {
+	processor := NewProcessor()
+	cfg := &WatermarkConfig{
+		Enabled:     true,
+		Type:        TypeText,
+		Text:        "{username}",
+		FontColor:   "#ff0000",
+		StrokeColor: "#000000",
+		StrokeWidth: 2,
+		Opacity:     1,
+		Position:    PositionMiddleCenter,
+		Variables:   map[string]string{VarUsername: "pixelpunk"},
+	}
+
+	if err := processor.ValidateConfig(cfg); err == nil {
+		t.Error("未启用文字水印时应校验失败")
+	}
+	processor.SetEnableTextWatermark(true)
+	if err := processor.ValidateConfig(cfg); err != nil {
+		t.Fatalf("校验失败: %v", err)
+	}
+
+	bad := *cfg
+	bad.Text = strings.Repeat("a", maxTextLength+1)
+	if err := processor.ValidateConfig(&bad); err == nil {
+		t.Error("超长文字应校验失败")
+	}
+	bad = *cfg
+	bad.FontFile = "../font.ttf"
+	if err := processor.ValidateConfig(&bad); err == nil {
+		t.Error("路径遍历的字体文件应校验失败")
+	}
+
+	src := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	draw.Draw(src, src.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+
+	for _, mode := range []WatermarkMode{ModeSingle, ModeTiled} {
+		cfg.Mode = mode
+		out, err := processor.ProcessImage(src, cfg)
+		if err != nil {
+			t.Fatalf("%s: 处理失败: %v", mode, err)
+		}
+		if !hasRedPixel(out) {
+			t.Errorf("%s: 未绘制文字", mode)
+		}
+	}
+}
+
+func hasRedPixel(img image.Image) bool {
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, g, bl, _ := img.At(x, y).RGBA()
+			if r>>8 > 200 && g>>8 < 80 && bl>>8 < 80 {
+				return true
+			}
+		}
+	}
+	return false
+}
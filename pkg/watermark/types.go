@@ -9,6 +9,14 @@ type WatermarkType string
 const (
 	TypeImage WatermarkType = "image"
 	TypeFile  WatermarkType = "image" // 向后兼容别名
+	TypeText  WatermarkType = "text"
+)
+
+type WatermarkMode string
+
+const (
+	ModeSingle WatermarkMode = "single" // 单个水印，按锚点定位
+	ModeTiled  WatermarkMode = "tiled"  // 平铺水印，重复铺满整张图片
 )
 
 type WatermarkPosition string
@@ -34,6 +42,23 @@ type WatermarkConfig struct {
 	FileBase64 string `json:"fileBase64"` // 文件base64数据（前端生成的水印也用这个字段）
 	FileData   []byte `json:"-"`          // 文件字节数据，不序列化
 
+	// 文字水印配置，Text 支持 {username}、{date}、{datetime}、{file_id}、{filename} 等模板变量
+	Text        string  `json:"text,omitempty"`
+	FontSize    float64 `json:"fontSize,omitempty"`    // 字号（px），为0时使用默认字号
+	FontColor   string  `json:"fontColor,omitempty"`   // 文字颜色，默认白色
+	FontFile    string  `json:"fontFile,omitempty"`    // 水印目录下的 TTF/OTF 字体，为空时使用内置字体（不含中文字形）
+	Bold        bool    `json:"bold,omitempty"`        // 使用内置粗体
+	StrokeColor string  `json:"strokeColor,omitempty"` // 描边颜色
+	StrokeWidth int     `json:"strokeWidth,omitempty"` // 描边宽度（px），0 表示不描边
+
+	// 平铺模式配置，间距为相邻水印之间的距离（px），为0时使用水印尺寸的一半
+	Mode         WatermarkMode `json:"mode,omitempty"`
+	TileSpacingX int           `json:"tileSpacingX,omitempty"`
+	TileSpacingY int           `json:"tileSpacingY,omitempty"`
+
+	// 模板变量取值，由调用方在处理前填充，不序列化
+	Variables map[string]string `json:"-"`
+
 	// 位置配置 - 锚点+边距模式（语义：距离参考边缘的距离）
 	Position   WatermarkPosition `json:"position"`
 	OffsetX    float64           `json:"offsetX"`              // 距离参考边缘的距离
@@ -60,7 +85,7 @@ type ProcessorInterface interface {
 	SetWatermarkImagePath(path string)
 	ValidateConfig(config *WatermarkConfig) error
 	GetSupportedFormats() []string
-	SetEnableTextWatermark(enabled bool) // 预留的文字水印开关（当前无效）
+	SetEnableTextWatermark(enabled bool) // 文字水印开关
 }
 
 type Position struct {
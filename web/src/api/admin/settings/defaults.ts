@@ -155,6 +155,22 @@ export const securityDefaults: Setting[] = [
     description: '域名黑名单',
     is_system: true,
   },
+  {
+    key: 'signed_url_max_expire',
+    value: 604800,
+    type: 'number',
+    group: 'security',
+    description: '签名链接最长有效期(秒)',
+    is_system: true,
+  },
+  {
+    key: 'enforce_admin_2fa',
+    value: false,
+    type: 'boolean',
+    group: 'security',
+    description: '强制管理员启用两步验证',
+    is_system: true,
+  },
   {
     key: 'hide_remote_url',
     value: true,
@@ -320,6 +336,14 @@ export const uploadDefaults: Setting[] = [
     description: '检测上传图片是否重复实现秒传',
     is_system: true,
   },
+  {
+    key: 'watermark_burn_on_upload',
+    value: false,
+    type: 'boolean',
+    group: 'upload',
+    description: '上传时将水印直接合成到原图',
+    is_system: true,
+  },
   {
     key: 'strict_file_validation',
     value: true,
@@ -37,6 +37,13 @@ export interface GithubOAuthLoginRequest {
 
 export interface OAuthLoginResponse {
   token: string
+  refresh_token?: string
+  session_id?: string
+  expires_in?: number
+  refresh_expires_at?: string
+  two_factor_setup_required?: boolean
+  two_factor_required?: boolean // 为 true 时仅返回 challenge，需提交两步验证码完成登录
+  challenge?: string
   userInfo: {
     id: number
     username: string
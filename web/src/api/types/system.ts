@@ -15,7 +15,7 @@ export interface InstallStatus {
 }
 
 export interface DatabaseTestRequest {
-  type: 'mysql' | 'sqlite'
+  type: 'mysql' | 'sqlite' | 'postgres'
   host?: string
   port?: number
   username?: string
@@ -26,7 +26,7 @@ export interface DatabaseTestRequest {
 
 export interface InstallRequest {
   database: {
-    type: 'mysql' | 'sqlite'
+    type: 'mysql' | 'sqlite' | 'postgres'
     host?: string
     port?: number
     username?: string
@@ -29,6 +29,39 @@ export interface UserRegisterRequest {
 export interface LoginResponse {
   token: string
   userInfo: UserInfo
+  refresh_token?: string
+  session_id?: string
+  expires_in?: number
+  refresh_expires_at?: string
+  two_factor_setup_required?: boolean // 管理员被强制要求启用两步验证但尚未启用
+}
+
+/* 账号已启用两步验证时，登录接口只返回挑战码，需再提交验证码完成登录 */
+export interface TwoFactorChallengeResponse {
+  two_factor_required: true
+  challenge: string
+}
+
+export interface TwoFactorLoginRequest {
+  challenge: string
+  code: string
+}
+
+/* ==================== 两步验证类型 ==================== */
+export interface TwoFactorStatus {
+  enabled: boolean
+  enabled_at: string | null
+  recovery_codes_remaining: number
+  enforced: boolean
+}
+
+export interface TwoFactorSetup {
+  secret: string
+  provisioning_uri: string
+}
+
+export interface TwoFactorRecoveryCodes {
+  recovery_codes: string[]
 }
 
 /* ==================== 验证码相关类型 ==================== */
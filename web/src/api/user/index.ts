@@ -6,6 +6,11 @@ import type {
   ResetPasswordRequest,
   SendCodeRequest,
   SendCodeResponse,
+  TwoFactorChallengeResponse,
+  TwoFactorLoginRequest,
+  TwoFactorRecoveryCodes,
+  TwoFactorSetup,
+  TwoFactorStatus,
   UserInfo,
   UserLoginRequest,
   UserRegisterRequest,
@@ -16,10 +21,20 @@ export function register(data: UserRegisterRequest): Promise<ApiResult<UserInfo>
   return post<UserInfo>('/user/register', data)
 }
 
-export function login(data: UserLoginRequest): Promise<ApiResult<LoginResponse>> {
-  return post<LoginResponse>('/user/login', data, {
+export function login(data: UserLoginRequest): Promise<ApiResult<LoginResponse | TwoFactorChallengeResponse>> {
+  return post<LoginResponse | TwoFactorChallengeResponse>('/user/login', data, {
     autoShowError: false,
     useResultMode: true,
+    skipAuthRefresh: true,
+  })
+}
+
+/* 提交两步验证码（或恢复码）完成登录 */
+export function loginTwoFactor(data: TwoFactorLoginRequest): Promise<ApiResult<LoginResponse>> {
+  return post<LoginResponse>('/user/login/2fa', data, {
+    autoShowError: false,
+    useResultMode: true,
+    skipAuthRefresh: true,
   })
 }
 
@@ -80,9 +95,31 @@ export function resetAccessControlConfig(): Promise<ApiResult<null>> {
   return post<null>('/user/personal/access-control/reset')
 }
 
+export function getTwoFactorStatus(): Promise<ApiResult<TwoFactorStatus>> {
+  return get<TwoFactorStatus>('/user/personal/2fa/status')
+}
+
+/* 生成新的TOTP密钥，需调用 enableTwoFactor 验证后才生效 */
+export function setupTwoFactor(): Promise<ApiResult<TwoFactorSetup>> {
+  return post<TwoFactorSetup>('/user/personal/2fa/setup')
+}
+
+export function enableTwoFactor(data: { code: string }): Promise<ApiResult<TwoFactorRecoveryCodes>> {
+  return post<TwoFactorRecoveryCodes>('/user/personal/2fa/enable', data)
+}
+
+export function disableTwoFactor(data: { code: string }): Promise<ApiResult<null>> {
+  return post<null>('/user/personal/2fa/disable', data)
+}
+
+export function regenerateRecoveryCodes(data: { code: string }): Promise<ApiResult<TwoFactorRecoveryCodes>> {
+  return post<TwoFactorRecoveryCodes>('/user/personal/2fa/recovery-codes', data)
+}
+
 export default {
   register,
   login,
+  loginTwoFactor,
   sendRegistrationCode,
   sendResetPasswordCode,
   resetPassword,
@@ -91,4 +128,9 @@ export default {
   getAccessControlConfig,
   createOrUpdateAccessControl,
   resetAccessControlConfig,
+  getTwoFactorStatus,
+  setupTwoFactor,
+  enableTwoFactor,
+  disableTwoFactor,
+  regenerateRecoveryCodes,
 }
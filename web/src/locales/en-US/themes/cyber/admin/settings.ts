@@ -454,6 +454,12 @@ export const settings = {
       enabled: 'Preserve file metadata',
       disabled: 'Clear file metadata',
     },
+    watermarkBurnOnUpload: {
+      label: 'Burn watermark on upload',
+      description: 'Write the watermark into the stored original on upload instead of overlaying it when non-owners view the file',
+      enabled: 'Watermark burned into original',
+      disabled: 'Overlay watermark on access',
+    },
     singleFile: 'Single file',
     multiFile: 'Multiple files',
     dailyUploadLimit: {
@@ -642,9 +648,13 @@ export const settings = {
       lockoutTimeHint: 'Set account lockout duration in minutes',
       expireTime: 'Login validity period',
       expireTimeHint: 'Set login state validity in hours, 0 for no expiry',
+      signedUrlMaxExpire: 'Signed Link Lifetime',
+      signedUrlMaxExpireHint: 'Maximum validity of signed links in seconds',
       jwtSecret: 'Login security key',
       jwtSecretPlaceholder: 'System will auto-generate security key',
       jwtSecretHint: 'Used to encrypt login credentials, leave empty to auto-generate',
+      enforceAdmin2fa: 'Enforce dual-factor for admins',
+      enforceAdmin2faHint: 'When enabled, admin nodes must engage dual-factor in Security Shield before entering the control hub',
     },
     storage: {
       title: 'Third-party storage config',
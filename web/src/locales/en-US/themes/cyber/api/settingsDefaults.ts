@@ -21,6 +21,8 @@ export const settingsDefaults = {
     ip_blacklist: 'IP access blacklist',
     domain_whitelist: 'Domain access whitelist',
     domain_blacklist: 'Domain access blacklist',
+    signed_url_max_expire: 'Maximum signed link lifetime (seconds)',
+    enforce_admin_2fa: 'Require admin nodes to enable two-factor authentication',
     hide_remote_url: 'Hide remote storage URL (global priority, fallback when channel not configured)',
   },
   upload: {
@@ -31,6 +33,7 @@ export const settingsDefaults = {
     thumbnail_max_width: 'Thumbnail max width',
     thumbnail_max_height: 'Thumbnail max height',
     preserve_exif: 'Preserve metadata',
+    watermark_burn_on_upload: 'Burn watermark into the original on upload',
     daily_upload_limit: 'User daily upload limit',
     client_max_concurrent_uploads: 'Client max concurrent transfers',
     chunked_upload_enabled: 'Chunked transfer feature toggle',
@@ -49,6 +49,17 @@ export const auth = {
       failure: 'OAuth login failed, please try again',
       stateValidationFailed: 'Security validation failed, please login again',
     },
+    twoFactor: {
+      codeLabel: 'One-time token',
+      codePlaceholder: 'Enter the 6-digit token or a recovery code',
+      hint: 'This node runs dual-factor protection. Pull a token from your authenticator, or submit a recovery code.',
+      submit: 'Verify and connect',
+      submitting: 'Verifying...',
+      back: 'Back to login',
+      codeRequired: 'Please enter a token',
+      failure: 'Token verification failed, please try again',
+      setupRequired: 'Admin nodes must engage dual-factor first. Complete binding in Security Shield',
+    },
   },
   register: {
     usernameLabel: 'Node designation',
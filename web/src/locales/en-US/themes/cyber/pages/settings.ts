@@ -98,6 +98,58 @@ export const settings = {
         success: 'Email switched successfully',
       },
     },
+    twoFactor: {
+      title: 'Dual-Factor Protocol',
+      description: 'Besides your access key, uplink requires a one-time token from an authenticator app, blocking intrusions from leaked keys.',
+      manageHint: 'To disengage the protocol or reset recovery codes, submit a current token or an unused recovery code.',
+      status: {
+        enabled: 'Protocol online',
+        disabled: 'Protocol offline',
+        enabledAt: 'Engaged on {time}. ',
+        recoveryRemaining: '{count} recovery codes remaining',
+        enforced: 'Admin nodes must run the dual-factor protocol. Complete binding before entering the control hub.',
+      },
+      setup: {
+        intro: 'Enter the seed key below into your authenticator app (e.g. Google Authenticator, Microsoft Authenticator, 1Password), or open the binding link on your mobile terminal, then submit the 6-digit token it shows.',
+        uriHint: 'On a terminal with an authenticator installed you can open it directly:',
+      },
+      labels: {
+        secret: 'Seed key',
+        uri: 'Binding link',
+        code: 'One-time token',
+        manageCode: 'Token or recovery code',
+      },
+      placeholders: {
+        code: 'Enter the 6-digit token',
+        manageCode: 'Enter a token or recovery code',
+      },
+      recovery: {
+        title: 'Emergency recovery codes',
+        hint: 'Each recovery code works once and replaces a token if your authenticator goes dark. Shown only once, store them offline now.',
+      },
+      actions: {
+        start: 'Engage dual-factor',
+        enable: 'Verify and engage',
+        enabling: 'Verifying...',
+        cancel: 'Cancel',
+        disable: 'Disengage dual-factor',
+        disabling: 'Disengaging...',
+        regenerate: 'Reset recovery codes',
+        regenerating: 'Generating...',
+        copy: 'Copy',
+        copyAll: 'Copy all',
+        openApp: 'Launch authenticator',
+        saved: 'Backup complete',
+      },
+      messages: {
+        codeRequired: 'Please enter a token',
+        enabled: 'Dual-factor engaged. Keep your recovery codes safe',
+        disabled: 'Dual-factor disengaged',
+        regenerated: 'Recovery codes reset. Old codes are void',
+        copied: 'Copied to clipboard',
+        copyFailed: 'Copy failed, please copy manually',
+      },
+    },
   },
   preferences: {
     title: 'Preference Tuning',
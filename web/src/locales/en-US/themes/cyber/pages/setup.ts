@@ -32,6 +32,7 @@ export const setup = {
     title: 'DATABASE',
     mysql: 'MySQL',
     sqlite: 'SQLite',
+    postgres: 'PostgreSQL',
     fields: {
       path: 'PATH',
       host: 'HOST',
@@ -51,6 +52,7 @@ export const setup = {
     validation: {
       mysqlRequired: 'Please complete MySQL connection info',
       sqliteRequired: 'Please specify SQLite file path',
+      postgresRequired: 'Please complete PostgreSQL connection information',
       connectionFailed: 'Connection failed',
       unknownError: 'Unknown error',
     },
@@ -58,6 +60,7 @@ export const setup = {
       success: {
         sqlite: 'SQLite Ready',
         mysql: 'MySQL connection successful',
+        postgres: 'PostgreSQL connection successful',
       },
       testing: 'Testing...',
       button: 'Database Config Test',
@@ -76,6 +79,7 @@ export const setup = {
     validation: {
       mysqlInfoRequired: 'Please fill in complete MySQL info',
       sqlitePathRequired: 'Please fill in SQLite path',
+      postgresInfoRequired: 'Please fill in complete PostgreSQL information',
       usernameRequired: 'Please enter administrator username (at least 3 characters)',
       passwordRequired: 'Please enter administrator password',
       passwordTooShort: 'Administrator password must be at least 6 characters',
@@ -457,6 +457,12 @@ export const settings = {
       enabled: 'Preserve metadata',
       disabled: 'Strip metadata',
     },
+    watermarkBurnOnUpload: {
+      label: 'Burn Watermark on Upload',
+      description: 'Write the watermark into the original on upload instead of applying it when non-owners view the file',
+      enabled: 'Burn into original',
+      disabled: 'Apply on access',
+    },
     singleFile: 'Single',
     multiFile: 'Multiple',
     dailyUploadLimit: {
@@ -645,9 +651,13 @@ export const settings = {
       lockoutTimeHint: 'Account lock duration in minutes',
       expireTime: 'Session Lifetime',
       expireTimeHint: 'Login validity in hours (0 = never expires)',
+      signedUrlMaxExpire: 'Signed Link Lifetime',
+      signedUrlMaxExpireHint: 'Maximum validity of signed links in seconds',
       jwtSecret: 'JWT Secret',
       jwtSecretPlaceholder: 'Secret generated automatically if left blank',
       jwtSecretHint: 'Used to sign authentication tokens',
+      enforceAdmin2fa: 'Require 2FA for administrators',
+      enforceAdmin2faHint: 'When enabled, administrators must turn on two-factor authentication in their security settings before using the admin console',
     },
     storage: {
       title: 'Third-party Storage',
@@ -21,6 +21,8 @@ export const settingsDefaults = {
     ip_blacklist: 'IP Blacklist',
     domain_whitelist: 'Domain Whitelist',
     domain_blacklist: 'Domain Blacklist',
+    signed_url_max_expire: 'Maximum signed link lifetime (seconds)',
+    enforce_admin_2fa: 'Require administrators to enable two-factor authentication',
     hide_remote_url: 'Hide Third-Party Storage URL (Global priority, fallback when channel not set)',
   },
   upload: {
@@ -31,6 +33,7 @@ export const settingsDefaults = {
     thumbnail_max_width: 'Thumbnail Max Width',
     thumbnail_max_height: 'Thumbnail Max Height',
     preserve_exif: 'Preserve EXIF Information',
+    watermark_burn_on_upload: 'Burn Watermark into Original on Upload',
     daily_upload_limit: 'User Daily Upload Count Limit',
     client_max_concurrent_uploads: 'Client Max Concurrent Uploads',
     chunked_upload_enabled: 'Chunked Upload Feature Toggle',
@@ -50,6 +50,17 @@ export const auth = {
       failure: 'OAuth login failed, please try again',
       stateValidationFailed: 'Security verification failed, please login again',
     },
+    twoFactor: {
+      codeLabel: 'Verification code',
+      codePlaceholder: 'Enter the 6-digit code or a recovery code',
+      hint: 'This account uses two-factor authentication. Open your authenticator app for a code, or use a recovery code.',
+      submit: 'Verify and sign in',
+      submitting: 'Verifying...',
+      back: 'Back to sign in',
+      codeRequired: 'Please enter a verification code',
+      failure: 'Verification failed, please try again',
+      setupRequired: 'Administrator accounts must enable two-factor authentication. Set it up in Security settings',
+    },
   },
   register: {
     usernameLabel: 'Username',
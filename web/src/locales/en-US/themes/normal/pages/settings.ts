@@ -98,6 +98,58 @@ export const settings = {
         success: 'Email changed successfully',
       },
     },
+    twoFactor: {
+      title: 'Two-Factor Authentication',
+      description: 'In addition to your password, sign-in requires a one-time code from an authenticator app, protecting your account if your password leaks.',
+      manageHint: 'To turn off two-factor authentication or regenerate recovery codes, enter a current code or an unused recovery code.',
+      status: {
+        enabled: 'Enabled',
+        disabled: 'Disabled',
+        enabledAt: 'Enabled on {time}. ',
+        recoveryRemaining: '{count} recovery codes remaining',
+        enforced: 'Administrators are required to use two-factor authentication. Set it up before accessing the admin console.',
+      },
+      setup: {
+        intro: 'Add the secret below to your authenticator app (e.g. Google Authenticator, Microsoft Authenticator, 1Password), or open the setup link on your phone, then enter the 6-digit code shown by the app.',
+        uriHint: 'On a device with an authenticator app installed you can open it directly:',
+      },
+      labels: {
+        secret: 'Secret',
+        uri: 'Setup link',
+        code: 'Verification code',
+        manageCode: 'Code or recovery code',
+      },
+      placeholders: {
+        code: 'Enter the 6-digit code',
+        manageCode: 'Enter a code or recovery code',
+      },
+      recovery: {
+        title: 'Recovery codes',
+        hint: 'Each recovery code works once and can replace a verification code if you lose your authenticator. They are shown only this time, so save them now.',
+      },
+      actions: {
+        start: 'Enable two-factor authentication',
+        enable: 'Verify and enable',
+        enabling: 'Verifying...',
+        cancel: 'Cancel',
+        disable: 'Turn off two-factor authentication',
+        disabling: 'Turning off...',
+        regenerate: 'Regenerate recovery codes',
+        regenerating: 'Generating...',
+        copy: 'Copy',
+        copyAll: 'Copy all',
+        openApp: 'Open authenticator app',
+        saved: 'I have saved them',
+      },
+      messages: {
+        codeRequired: 'Please enter a verification code',
+        enabled: 'Two-factor authentication enabled. Keep your recovery codes safe',
+        disabled: 'Two-factor authentication turned off',
+        regenerated: 'Recovery codes regenerated. Old codes no longer work',
+        copied: 'Copied to clipboard',
+        copyFailed: 'Copy failed, please copy manually',
+      },
+    },
   },
   preferences: {
     title: 'Preferences',
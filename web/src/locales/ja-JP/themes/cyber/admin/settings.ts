@@ -457,6 +457,12 @@ export const settings = {
       enabled: 'ファイルメタデータを保持',
       disabled: 'ファイルメタデータをクリア',
     },
+    watermarkBurnOnUpload: {
+      label: 'アップロード時に透かしを合成',
+      description: '透かしをアップロード時に元画像へ書き込みます。オフの場合は所有者以外のアクセス時に重ねて表示します',
+      enabled: '元画像に透かしを合成',
+      disabled: 'アクセス時に透かしを重ねる',
+    },
     singleFile: '単一ファイル',
     multiFile: '複数ファイル',
     dailyUploadLimit: {
@@ -645,9 +651,13 @@ export const settings = {
       lockoutTimeHint: 'アカウントロックアウト期間を分で設定',
       expireTime: 'ログイン有効期間',
       expireTimeHint: 'ログイン状態有効性を時間で設定、0は期限なし',
+      signedUrlMaxExpire: '署名リンク有効期間',
+      signedUrlMaxExpireHint: '署名リンクの最大有効期間（秒）',
       jwtSecret: 'ログインセキュリティキー',
       jwtSecretPlaceholder: 'システムが自動的にセキュリティキーを生成します',
       jwtSecretHint: 'ログイン資格情報を暗号化するために使用、空白のままにすると自動生成',
+      enforceAdmin2fa: '管理者ノードの二重認証を強制',
+      enforceAdmin2faHint: '有効にすると、管理者ノードはセキュリティ設定で二重認証を起動するまでコントロールハブに入れません',
     },
     storage: {
       title: 'サードパーティストレージ設定',
@@ -21,6 +21,8 @@ export const settingsDefaults = {
     ip_blacklist: 'IPアクセスブラックリスト',
     domain_whitelist: 'ドメインアクセスホワイトリスト',
     domain_blacklist: 'ドメインアクセスブラックリスト',
+    signed_url_max_expire: '署名リンクの最大有効期間（秒）',
+    enforce_admin_2fa: '管理者ノードに二段階認証を必須にする',
     hide_remote_url: 'リモートストレージURLを非表示（グローバル優先、チャンネル未設定時のフォールバック）',
   },
   upload: {
@@ -31,6 +33,7 @@ export const settingsDefaults = {
     thumbnail_max_width: 'サムネイル最大幅',
     thumbnail_max_height: 'サムネイル最大高さ',
     preserve_exif: 'メタデータを保持',
+    watermark_burn_on_upload: 'アップロード時に透かしを元画像へ合成',
     daily_upload_limit: 'ユーザー1日あたりのアップロード制限',
     client_max_concurrent_uploads: 'クライアント最大同時転送数',
     chunked_upload_enabled: 'チャンク転送機能トグル',
@@ -49,6 +49,17 @@ export const auth = {
       failure: 'OAuthログイン失敗、もう一度お試しください',
       stateValidationFailed: 'セキュリティ検証失敗、もう一度ログインしてください',
     },
+    twoFactor: {
+      codeLabel: 'ワンタイムトークン',
+      codePlaceholder: '6桁のトークンまたはリカバリーコード',
+      hint: 'このノードは二重認証で保護されています。認証アプリのトークン、またはリカバリーコードを送信してください。',
+      submit: '検証して接続',
+      submitting: '検証中...',
+      back: 'ログインに戻る',
+      codeRequired: 'トークンを入力してください',
+      failure: 'トークンの検証に失敗しました。もう一度お試しください',
+      setupRequired: '管理者ノードは先に二重認証を起動する必要があります。セキュリティ設定でバインドしてください',
+    },
   },
   register: {
     usernameLabel: 'ノード指定',
@@ -98,6 +98,58 @@ export const settings = {
         success: 'メールを正常に切り替えました',
       },
     },
+    twoFactor: {
+      title: '二重認証プロトコル',
+      description: '接続時にアクセスキーに加えて認証アプリのワンタイムトークンが必要になり、キー漏洩による侵入を遮断します。',
+      manageHint: 'プロトコルの停止やリカバリーコードのリセットには、現在のトークンまたは未使用のリカバリーコードが必要です。',
+      status: {
+        enabled: 'プロトコル稼働中',
+        disabled: 'プロトコル停止中',
+        enabledAt: '{time} に起動、',
+        recoveryRemaining: '残りのリカバリーコード {count} 個',
+        enforced: '管理者ノードは二重認証が必須です。コントロールハブに入る前にバインドを完了してください。',
+      },
+      setup: {
+        intro: '認証アプリ（Google Authenticator、Microsoft Authenticator、1Password など）に以下のシードキーを入力するか、モバイル端末でバインドリンクを開き、表示された6桁のトークンを送信してください。',
+        uriHint: '認証アプリを導入済みの端末では直接開けます：',
+      },
+      labels: {
+        secret: 'シードキー',
+        uri: 'バインドリンク',
+        code: 'ワンタイムトークン',
+        manageCode: 'トークンまたはリカバリーコード',
+      },
+      placeholders: {
+        code: '6桁のトークンを入力',
+        manageCode: 'トークンまたはリカバリーコードを入力',
+      },
+      recovery: {
+        title: '緊急リカバリーコード',
+        hint: '各リカバリーコードは1回のみ使用でき、認証アプリが使えない時にトークンの代わりになります。表示は1回限り、今すぐオフラインで保管してください。',
+      },
+      actions: {
+        start: '二重認証を起動',
+        enable: '検証して起動',
+        enabling: '検証中...',
+        cancel: 'キャンセル',
+        disable: '二重認証を停止',
+        disabling: '停止中...',
+        regenerate: 'リカバリーコードをリセット',
+        regenerating: '生成中...',
+        copy: 'コピー',
+        copyAll: 'すべてコピー',
+        openApp: '認証アプリを起動',
+        saved: 'バックアップ完了',
+      },
+      messages: {
+        codeRequired: 'トークンを入力してください',
+        enabled: '二重認証を起動しました。リカバリーコードを安全に保管してください',
+        disabled: '二重認証を停止しました',
+        regenerated: 'リカバリーコードをリセットしました。旧コードは無効です',
+        copied: 'クリップボードに書き込みました',
+        copyFailed: 'コピーに失敗しました。手動でコピーしてください',
+      },
+    },
   },
   preferences: {
     title: '設定調整',
@@ -454,6 +454,12 @@ export const settings = {
       enabled: 'メタデータを保持',
       disabled: 'メタデータを削除',
     },
+    watermarkBurnOnUpload: {
+      label: 'アップロード時に透かしを合成',
+      description: 'アップロード時に透かしを元画像へ書き込みます。オフの場合は所有者以外の閲覧時に適用します',
+      enabled: '元画像に合成',
+      disabled: '閲覧時に適用',
+    },
     singleFile: '単一',
     multiFile: '複数',
     dailyUploadLimit: {
@@ -642,9 +648,13 @@ export const settings = {
       lockoutTimeHint: 'アカウントロック期間（分）',
       expireTime: 'セッション有効期間',
       expireTimeHint: 'ログイン有効性（時間）（0 = 期限切れなし）',
+      signedUrlMaxExpire: '署名リンク有効期間',
+      signedUrlMaxExpireHint: '署名リンクの最大有効期間（秒）',
       jwtSecret: 'JWTシークレット',
       jwtSecretPlaceholder: '空欄の場合は自動生成',
       jwtSecretHint: '認証トークンの署名に使用',
+      enforceAdmin2fa: '管理者の二段階認証を必須にする',
+      enforceAdmin2faHint: '有効にすると、管理者はセキュリティ設定で二段階認証を有効にするまで管理画面を利用できません',
     },
     storage: {
       title: 'サードパーティストレージ',
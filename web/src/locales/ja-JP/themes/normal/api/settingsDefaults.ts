@@ -21,6 +21,8 @@ export const settingsDefaults = {
     ip_blacklist: 'IPブラックリスト',
     domain_whitelist: 'ドメインホワイトリスト',
     domain_blacklist: 'ドメインブラックリスト',
+    signed_url_max_expire: '署名リンクの最大有効期間（秒）',
+    enforce_admin_2fa: '管理者に二段階認証を必須にする',
     hide_remote_url: 'サードパーティストレージURLを非表示（グローバル優先、チャンネル未設定時のフォールバック）',
   },
   upload: {
@@ -31,6 +33,7 @@ export const settingsDefaults = {
     thumbnail_max_width: 'サムネイル最大幅',
     thumbnail_max_height: 'サムネイル最大高さ',
     preserve_exif: 'EXIF情報を保持',
+    watermark_burn_on_upload: 'アップロード時に透かしを元画像へ合成',
     daily_upload_limit: 'ユーザー1日あたりのアップロード数制限',
     client_max_concurrent_uploads: 'クライアント最大並行アップロード数',
     chunked_upload_enabled: 'チャンクアップロード機能切り替え',
@@ -49,6 +49,17 @@ export const auth = {
       failure: 'OAuthログインに失敗しました。もう一度お試しください',
       stateValidationFailed: 'セキュリティ検証に失敗しました。もう一度ログインしてください',
     },
+    twoFactor: {
+      codeLabel: '認証コード',
+      codePlaceholder: '6桁の認証コードまたはリカバリーコード',
+      hint: 'このアカウントは二段階認証が有効です。認証アプリのコードを入力するか、リカバリーコードを使用してください。',
+      submit: '確認してログイン',
+      submitting: '確認中...',
+      back: 'ログインに戻る',
+      codeRequired: '認証コードを入力してください',
+      failure: '認証に失敗しました。もう一度お試しください',
+      setupRequired: '管理者アカウントは二段階認証の設定が必要です。セキュリティ設定で設定してください',
+    },
   },
   register: {
     usernameLabel: 'ユーザー名',
@@ -98,6 +98,58 @@ export const settings = {
         success: 'メールが正常に変更されました',
       },
     },
+    twoFactor: {
+      title: '二段階認証',
+      description: 'ログイン時にパスワードに加えて認証アプリのワンタイムコードが必要になり、パスワード漏洩による不正ログインを防ぎます。',
+      manageHint: '二段階認証の無効化やリカバリーコードの再生成には、現在の認証コードまたは未使用のリカバリーコードが必要です。',
+      status: {
+        enabled: '有効',
+        disabled: '無効',
+        enabledAt: '{time} に有効化、',
+        recoveryRemaining: '残りのリカバリーコード {count} 個',
+        enforced: '管理者アカウントは二段階認証が必須です。管理画面を利用する前に設定を完了してください。',
+      },
+      setup: {
+        intro: '認証アプリ（Google Authenticator、Microsoft Authenticator、1Password など）に以下のシークレットを手動で追加するか、スマートフォンで登録リンクを開き、表示された6桁のコードを入力してください。',
+        uriHint: '認証アプリがインストールされた端末では直接開けます：',
+      },
+      labels: {
+        secret: 'シークレット',
+        uri: '登録リンク',
+        code: '認証コード',
+        manageCode: '認証コードまたはリカバリーコード',
+      },
+      placeholders: {
+        code: '6桁の認証コードを入力',
+        manageCode: '認証コードまたはリカバリーコードを入力',
+      },
+      recovery: {
+        title: 'リカバリーコード',
+        hint: '各リカバリーコードは1回のみ使用でき、認証アプリが使えない場合に認証コードの代わりになります。表示はこの1回限りなので、今すぐ安全に保存してください。',
+      },
+      actions: {
+        start: '二段階認証を有効にする',
+        enable: '確認して有効化',
+        enabling: '確認中...',
+        cancel: 'キャンセル',
+        disable: '二段階認証を無効にする',
+        disabling: '無効化中...',
+        regenerate: 'リカバリーコードを再生成',
+        regenerating: '生成中...',
+        copy: 'コピー',
+        copyAll: 'すべてコピー',
+        openApp: '認証アプリを開く',
+        saved: '保存しました',
+      },
+      messages: {
+        codeRequired: '認証コードを入力してください',
+        enabled: '二段階認証を有効にしました。リカバリーコードを安全に保管してください',
+        disabled: '二段階認証を無効にしました',
+        regenerated: 'リカバリーコードを再生成しました。以前のコードは使用できません',
+        copied: 'クリップボードにコピーしました',
+        copyFailed: 'コピーに失敗しました。手動でコピーしてください',
+      },
+    },
   },
   preferences: {
     title: '設定',
@@ -32,6 +32,7 @@ export const setup = {
     title: 'データベース',
     mysql: 'MySQL',
     sqlite: 'SQLite',
+    postgres: 'PostgreSQL',
     fields: {
       path: 'パス',
       host: 'ホスト',
@@ -51,6 +52,7 @@ export const setup = {
     validation: {
       mysqlRequired: 'MySQL接続情報を入力してください',
       sqliteRequired: 'SQLiteファイルパスを指定してください',
+      postgresRequired: 'PostgreSQL接続情報を入力してください',
       connectionFailed: '接続失敗',
       unknownError: '不明なエラー',
     },
@@ -58,6 +60,7 @@ export const setup = {
       success: {
         sqlite: 'SQLite準備完了',
         mysql: 'MySQL接続成功',
+        postgres: 'PostgreSQL接続成功',
       },
       testing: 'テスト中...',
       button: 'データベース設定をテスト',
@@ -76,6 +79,7 @@ export const setup = {
     validation: {
       mysqlInfoRequired: 'MySQL情報を完全に入力してください',
       sqlitePathRequired: 'SQLiteパスを入力してください',
+      postgresInfoRequired: 'PostgreSQL情報を完全に入力してください',
       usernameRequired: '管理者ユーザー名を入力してください（3文字以上）',
       passwordRequired: '管理者パスワードを入力してください',
       passwordTooShort: '管理者パスワードは6文字以上である必要があります',
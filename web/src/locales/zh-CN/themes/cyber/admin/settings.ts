@@ -454,6 +454,12 @@ export const settings = {
       enabled: '保留文件节点元数据',
       disabled: '清除文件节点元数据',
     },
+    watermarkBurnOnUpload: {
+      label: '上传时合成水印',
+      description: '上传时将水印直接写入原图节点；关闭时保存原图，在非所有者访问时叠加水印',
+      enabled: '水印写入原图节点',
+      disabled: '访问时叠加水印',
+    },
     singleFile: '单文件节点',
     multiFile: '多文件节点',
     dailyUploadLimit: {
@@ -642,9 +648,13 @@ export const settings = {
       lockoutTimeHint: '设置账户锁定节点时长，单位为分钟',
       expireTime: '登录有效节点期',
       expireTimeHint: '设置登录状态的有效节点时间，单位为小时，0表示不超时',
+      signedUrlMaxExpire: '签名链接有效期',
+      signedUrlMaxExpireHint: '签名链接允许的最长有效期，单位为秒',
       jwtSecret: '登录安全节点密钥',
       jwtSecretPlaceholder: '系统将自动生成安全节点密钥',
       jwtSecretHint: '用于加密登录凭证节点，留空则自动生成',
+      enforceAdmin2fa: '管理员强制双重认证',
+      enforceAdmin2faHint: '开启后，管理员节点必须先在安全防护中激活双重认证才能进入控制中枢',
     },
     storage: {
       title: '三方存储节点配置',
@@ -21,6 +21,8 @@ export const settingsDefaults = {
     ip_blacklist: 'IP访问黑名单',
     domain_whitelist: '域名访问白名单',
     domain_blacklist: '域名访问黑名单',
+    signed_url_max_expire: '签名链接最长有效期(秒)',
+    enforce_admin_2fa: '强制管理员节点启用双重认证',
     hide_remote_url: '隐藏外部存储地址（全局优先，渠道未配置时回退）',
   },
   upload: {
@@ -31,6 +33,7 @@ export const settingsDefaults = {
     thumbnail_max_width: '预览图最大宽度',
     thumbnail_max_height: '预览图最大高度',
     preserve_exif: '是否保留元数据',
+    watermark_burn_on_upload: '上传时将水印合成到原图',
     daily_upload_limit: '用户每日上传量限制',
     client_max_concurrent_uploads: '客户端最大并发传输数',
     chunked_upload_enabled: '分片传输功能开关',
@@ -49,6 +49,17 @@ export const auth = {
       failure: 'OAuth 登录失败，请重新尝试',
       stateValidationFailed: '安全验证失败，请重新登录',
     },
+    twoFactor: {
+      codeLabel: '动态口令',
+      codePlaceholder: '输入认证器中的6位口令或恢复码',
+      hint: '该节点已启用双重认证，请从认证器获取动态口令；认证器失联时可提交恢复码。',
+      submit: '校验并接入',
+      submitting: '校验中...',
+      back: '返回重新接入',
+      codeRequired: '请输入动态口令',
+      failure: '口令校验失败，请重试',
+      setupRequired: '管理员节点需先激活双重认证，请在安全防护中完成绑定',
+    },
   },
   register: {
     usernameLabel: '节点代号',
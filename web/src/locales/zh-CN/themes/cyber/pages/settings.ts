@@ -98,6 +98,58 @@ export const settings = {
         success: '邮箱已成功切换',
       },
     },
+    twoFactor: {
+      title: '双重认证协议',
+      description: '接入时除访问密钥外还需提交认证器生成的动态口令，阻断密钥泄露引发的入侵。',
+      manageHint: '停用协议或重置恢复码前，需提交当前动态口令或一个未使用的恢复码。',
+      status: {
+        enabled: '协议运行中',
+        disabled: '协议未激活',
+        enabledAt: '激活于 {time}，',
+        recoveryRemaining: '剩余 {count} 个可用恢复码',
+        enforced: '系统要求管理员节点激活双重认证，完成绑定后方可进入控制中枢。',
+      },
+      setup: {
+        intro: '在认证器应用（如 Google Authenticator、Microsoft Authenticator、1Password）中手动录入以下密钥，或在移动终端直接打开绑定链接，然后提交应用显示的6位口令完成激活。',
+        uriHint: '在已安装认证器的终端上可直接打开：',
+      },
+      labels: {
+        secret: '种子密钥',
+        uri: '绑定链接',
+        code: '动态口令',
+        manageCode: '动态口令或恢复码',
+      },
+      placeholders: {
+        code: '输入6位动态口令',
+        manageCode: '输入动态口令或恢复码',
+      },
+      recovery: {
+        title: '应急恢复码',
+        hint: '每个恢复码仅可使用一次，认证器失联时可代替动态口令接入。此列表仅显示一次，请立即离线保存。',
+      },
+      actions: {
+        start: '激活双重认证',
+        enable: '校验并激活',
+        enabling: '校验中...',
+        cancel: '取消',
+        disable: '停用双重认证',
+        disabling: '停用中...',
+        regenerate: '重置恢复码',
+        regenerating: '生成中...',
+        copy: '复制',
+        copyAll: '复制全部',
+        openApp: '唤起认证器',
+        saved: '已完成备份',
+      },
+      messages: {
+        codeRequired: '请输入动态口令',
+        enabled: '双重认证已激活，请妥善保存恢复码',
+        disabled: '双重认证已停用',
+        regenerated: '恢复码已重置，旧恢复码全部失效',
+        copied: '已写入剪贴板',
+        copyFailed: '复制失败，请手动复制',
+      },
+    },
   },
   preferences: {
     title: '偏好调谐',
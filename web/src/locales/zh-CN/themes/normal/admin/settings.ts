@@ -454,6 +454,12 @@ export const settings = {
       enabled: '保留文件元数据',
       disabled: '清除文件元数据',
     },
+    watermarkBurnOnUpload: {
+      label: '上传时合成水印',
+      description: '上传时将水印直接写入原图；关闭时保存原图，在非所有者访问时叠加水印',
+      enabled: '水印写入原图',
+      disabled: '访问时叠加水印',
+    },
     singleFile: '单文件',
     multiFile: '多文件',
     dailyUploadLimit: {
@@ -642,9 +648,13 @@ export const settings = {
       lockoutTimeHint: '设置账户锁定时长，单位为分钟',
       expireTime: '登录有效期',
       expireTimeHint: '设置登录状态的有效时间，单位为小时，0表示不超时',
+      signedUrlMaxExpire: '签名链接有效期',
+      signedUrlMaxExpireHint: '签名链接允许的最长有效期，单位为秒',
       jwtSecret: '登录安全密钥',
       jwtSecretPlaceholder: '系统将自动生成安全密钥',
       jwtSecretHint: '用于加密登录凭证，留空则自动生成',
+      enforceAdmin2fa: '管理员强制两步验证',
+      enforceAdmin2faHint: '开启后，管理员账号必须先在个人安全设置中启用两步验证才能访问管理后台',
     },
     storage: {
       title: '三方存储配置',
@@ -49,6 +49,17 @@ export const auth = {
       failure: 'OAuth 登录失败，请重试',
       stateValidationFailed: '安全验证失败，请重新登录',
     },
+    twoFactor: {
+      codeLabel: '两步验证码',
+      codePlaceholder: '输入验证器中的6位验证码或恢复码',
+      hint: '该账号已启用两步验证，请打开验证器应用获取验证码；无法使用验证器时可输入恢复码。',
+      submit: '验证并登录',
+      submitting: '验证中...',
+      back: '返回重新登录',
+      codeRequired: '请输入验证码',
+      failure: '验证失败，请重试',
+      setupRequired: '管理员账号需要先启用两步验证，请在安全设置中完成绑定',
+    },
   },
   register: {
     usernameLabel: '用户名',
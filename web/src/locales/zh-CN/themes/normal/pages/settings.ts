@@ -98,6 +98,58 @@ export const settings = {
         success: '邮箱已成功更换',
       },
     },
+    twoFactor: {
+      title: '两步验证',
+      description: '登录时除密码外还需输入验证器应用生成的动态验证码，可有效防止密码泄露导致的账号被盗。',
+      manageHint: '关闭两步验证或重新生成恢复码前，需要输入当前动态验证码或一个未使用的恢复码。',
+      status: {
+        enabled: '已启用',
+        disabled: '未启用',
+        enabledAt: '启用于 {time}，',
+        recoveryRemaining: '剩余 {count} 个可用恢复码',
+        enforced: '系统要求管理员账号启用两步验证，请先完成绑定后再访问管理后台。',
+      },
+      setup: {
+        intro: '请在验证器应用（如 Google Authenticator、Microsoft Authenticator、1Password）中手动添加以下密钥，或在手机上直接打开绑定链接，然后输入应用显示的6位验证码完成启用。',
+        uriHint: '在已安装验证器应用的设备上可直接打开：',
+      },
+      labels: {
+        secret: '密钥',
+        uri: '绑定链接',
+        code: '验证码',
+        manageCode: '验证码或恢复码',
+      },
+      placeholders: {
+        code: '输入6位动态验证码',
+        manageCode: '输入动态验证码或恢复码',
+      },
+      recovery: {
+        title: '恢复码',
+        hint: '每个恢复码只能使用一次，在无法使用验证器时可代替验证码登录。此列表只显示这一次，请立即妥善保存。',
+      },
+      actions: {
+        start: '启用两步验证',
+        enable: '验证并启用',
+        enabling: '验证中...',
+        cancel: '取消',
+        disable: '关闭两步验证',
+        disabling: '关闭中...',
+        regenerate: '重新生成恢复码',
+        regenerating: '生成中...',
+        copy: '复制',
+        copyAll: '复制全部',
+        openApp: '打开验证器应用',
+        saved: '我已保存',
+      },
+      messages: {
+        codeRequired: '请输入验证码',
+        enabled: '两步验证已启用，请妥善保存恢复码',
+        disabled: '两步验证已关闭',
+        regenerated: '恢复码已重新生成，旧恢复码已失效',
+        copied: '已复制到剪贴板',
+        copyFailed: '复制失败，请手动复制',
+      },
+    },
   },
   preferences: {
     title: '偏好设置',
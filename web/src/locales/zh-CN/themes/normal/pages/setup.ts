@@ -32,6 +32,7 @@ export const setup = {
     title: 'DATABASE',
     mysql: 'MySQL',
     sqlite: 'SQLite',
+    postgres: 'PostgreSQL',
     fields: {
       path: 'PATH',
       host: 'HOST',
@@ -51,6 +52,7 @@ export const setup = {
     validation: {
       mysqlRequired: '请完善MySQL连接信息',
       sqliteRequired: '请指定SQLite文件路径',
+      postgresRequired: '请完善PostgreSQL连接信息',
       connectionFailed: '连接失败',
       unknownError: '未知错误',
     },
@@ -58,6 +60,7 @@ export const setup = {
       success: {
         sqlite: 'SQLite就绪',
         mysql: 'MySQL连接成功',
+        postgres: 'PostgreSQL连接成功',
       },
       testing: '测试中...',
       button: '数据库配置测试',
@@ -76,6 +79,7 @@ export const setup = {
     validation: {
       mysqlInfoRequired: '请填写完整的MySQL信息',
       sqlitePathRequired: '请填写SQLite路径',
+      postgresInfoRequired: '请填写完整的PostgreSQL信息',
       usernameRequired: '请输入管理员用户名（至少3位）',
       passwordRequired: '请输入管理员密码',
       passwordTooShort: '管理员密码至少6位',
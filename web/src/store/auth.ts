@@ -1,8 +1,13 @@
 import { defineStore } from 'pinia'
 import { userApi } from '@/api'
-import type { UserInfo, UserLoginRequest, UserRegisterRequest } from '@/api/types/index'
+import type { LoginResponse, UserInfo, UserLoginRequest, UserRegisterRequest } from '@/api/types/index'
 import { StorageUtil } from '@/utils/storage/storage'
-import { TOKEN_EXPIRES, TOKEN_KEY, USER_INFO_KEY } from '@/constants'
+import { REFRESH_TOKEN_KEY, TOKEN_EXPIRES, TOKEN_KEY, USER_INFO_KEY } from '@/constants'
+import { refreshAccessToken } from '@/utils/network/http'
+
+/* 访问令牌到期前提前刷新的时间(秒)，保证文件访问用的 Cookie 始终有效 */
+const TOKEN_REFRESH_AHEAD = 60
+let refreshTimer: ReturnType<typeof setTimeout> | null = null
 
 interface AuthState {
   user: UserInfo | null
@@ -41,15 +46,20 @@ export const useAuthStore = defineStore('auth', {
 
       this.checkAuth()
       this.refreshCookie()
+      if (this.token) {
+        this.scheduleTokenRefresh(this.token)
+      }
       this.initialized = true
     },
 
+    /* 账号启用两步验证时返回挑战码，由调用方继续调用 loginTwoFactor 完成登录 */
     async login(loginData: UserLoginRequest) {
       const result = await userApi.login(loginData)
       if (result.success) {
-        const { userInfo, token } = result.data
-        this.setUserInfo(userInfo)
-        this.setToken(token)
+        if ('two_factor_required' in result.data) {
+          return result.data
+        }
+        this.applyLoginResult(result.data)
         return result.data
       }
       // 优先使用后端返回的错误消息，fallback使用默认消息
@@ -60,6 +70,23 @@ export const useAuthStore = defineStore('auth', {
       throw error
     },
 
+    async loginTwoFactor(challenge: string, code: string) {
+      const result = await userApi.loginTwoFactor({ challenge, code })
+      if (result.success) {
+        this.applyLoginResult(result.data)
+        return result.data
+      }
+      const error = new Error(result.message || 'Two-factor verification failed')
+      ;(error as any).response = { data: result }
+      throw error
+    },
+
+    applyLoginResult(data: LoginResponse) {
+      this.setUserInfo(data.userInfo)
+      this.setRefreshToken(data.refresh_token)
+      this.setToken(data.token)
+    },
+
     async register(registerData: UserRegisterRequest) {
       const result = await userApi.register(registerData)
       if (result.success) {
@@ -90,15 +117,48 @@ export const useAuthStore = defineStore('auth', {
         const maxAgeSeconds = TOKEN_EXPIRES * 3600
         document.cookie = `token=${token}; path=/; max-age=${maxAgeSeconds}; SameSite=Lax`
       }
+      this.scheduleTokenRefresh(token)
+    },
+
+    /* 保存刷新令牌，有效期与登录有效期一致，由服务端校验 */
+    setRefreshToken(refreshToken?: string) {
+      if (refreshToken) {
+        StorageUtil.set<string>(REFRESH_TOKEN_KEY, refreshToken, TOKEN_EXPIRES)
+      }
+    },
+
+    /* 在访问令牌到期前刷新；没有刷新令牌的旧登录状态到期后重新登录 */
+    scheduleTokenRefresh(token: string) {
+      if (typeof window === 'undefined') {
+        return
+      }
+      if (refreshTimer) {
+        clearTimeout(refreshTimer)
+        refreshTimer = null
+      }
+      if (!StorageUtil.get<string>(REFRESH_TOKEN_KEY)) {
+        return
+      }
+      const remaining = this.getTokenExpireTime(token)
+      const delay = Math.max(remaining - TOKEN_REFRESH_AHEAD, 0) * 1000
+      refreshTimer = setTimeout(() => {
+        refreshTimer = null
+        refreshAccessToken(token)
+      }, delay)
     },
 
     logout() {
+      if (refreshTimer) {
+        clearTimeout(refreshTimer)
+        refreshTimer = null
+      }
       this.user = null
       this.token = null
       this.isAuthenticated = false
       this.avatarUrl = null
       this.initialized = false
       StorageUtil.remove(TOKEN_KEY)
+      StorageUtil.remove(REFRESH_TOKEN_KEY)
       StorageUtil.remove(USER_INFO_KEY)
       StorageUtil.remove('rememberedLogin')
 
@@ -84,6 +84,8 @@ export interface ExtendedRequestConfig extends ErrorHandlingConfig {
   loadingMode?: 'auto' | 'manual' | 'shared' // loading管理模式
   loadingGroup?: string // loading分组标识，同组共享loading状态
   smartLoading?: boolean // 是否开启智能loading检测，默认true
+  skipAuthRefresh?: boolean // 401时不尝试刷新访问令牌
+  _authRetried?: boolean // 内部使用：已刷新令牌并重试过一次
   _detectedLoadingRefs?: Array<{ value: boolean }> // 内部使用：检测到的loading引用
 }
 
@@ -101,6 +103,7 @@ export const ErrorCodes = {
   INVALID_VERIFY_CODE: 1006, // 无效的验证码
   EMAIL_EXISTS: 1007, // 邮箱已存在
   EMAIL_SEND_FAILED: 1008, // 邮件发送失败
+  TWO_FACTOR_SETUP_REQUIRED: 1013, // 管理员需先启用两步验证
 
   SYSTEM_NOT_INSTALLED: 7001, // 系统未安装，需要先完成安装配置
   INSTALLATION_FAILED: 7002, // 系统安装失败
@@ -1,6 +1,6 @@
 import axios, { type AxiosInstance, type AxiosRequestConfig, type AxiosResponse, type CancelTokenSource } from 'axios'
 import { StorageUtil } from '../storage'
-import { HTTP_STATUS, REQUEST_TIMEOUT, TOKEN_KEY } from '@/constants'
+import { HTTP_STATUS, REFRESH_TOKEN_KEY, REQUEST_TIMEOUT, TOKEN_KEY } from '@/constants'
 // Avoid importing router here to prevent circular dependencies with '@/router'
 import {
   ErrorCodes,
@@ -279,6 +279,55 @@ const instance: AxiosInstance = axios.create({
   },
 })
 
+/* 访问令牌有效期较短，过期后用刷新令牌换取新令牌（刷新令牌同时轮换）
+ * 同一时间只发起一次刷新；多个标签页共享存储，借助 Web Locks 串行刷新，避免旧刷新令牌被重复使用导致会话被吊销 */
+let refreshPromise: Promise<boolean> | null = null
+
+const doRefreshAccessToken = async (expiredToken: string | null): Promise<boolean> => {
+  const currentToken = StorageUtil.get<string>(TOKEN_KEY)
+  if (currentToken && currentToken !== expiredToken) {
+    return true // 其他标签页已完成刷新
+  }
+
+  const refreshToken = StorageUtil.get<string>(REFRESH_TOKEN_KEY)
+  if (!refreshToken) {
+    return false
+  }
+
+  try {
+    const response = await axios.post(`${apiBaseUrl}/auth/refresh`, { refresh_token: refreshToken })
+    const data = response.data?.data
+    if (response.data?.code !== ErrorCodes.SUCCESS || !data?.token) {
+      return false
+    }
+
+    const { useAuthStore } = await import('@/store/auth')
+    const authStore = useAuthStore()
+    authStore.setRefreshToken(data.refresh_token)
+    authStore.setToken(data.token)
+    if (data.userInfo) {
+      authStore.setUserInfo(data.userInfo)
+    }
+    return true
+  } catch (_error) {
+    return false
+  }
+}
+
+/* expiredToken 为失效的访问令牌；若存储中的令牌已不同，说明别处已刷新，无需再次轮换 */
+export const refreshAccessToken = (expiredToken: string | null = StorageUtil.get<string>(TOKEN_KEY)): Promise<boolean> => {
+  if (!refreshPromise) {
+    const locks = typeof navigator !== 'undefined' ? navigator.locks : undefined
+    const task = locks
+      ? locks.request('pixelpunk-token-refresh', () => doRefreshAccessToken(expiredToken))
+      : doRefreshAccessToken(expiredToken)
+    refreshPromise = task.finally(() => {
+      refreshPromise = null
+    })
+  }
+  return refreshPromise
+}
+
 function createApiResult<T = any>(success: boolean, code: number, message: string, data: T, request_id?: string): ApiResult<T> {
   return {
     success,
@@ -537,6 +586,20 @@ instance.interceptors.response.use(
     let message = ''
     let errorCode = 999 // 默认网络错误码
 
+    if (
+      error.response?.status === HTTP_STATUS.UNAUTHORIZED &&
+      config &&
+      !config.skipAuthRefresh &&
+      !config._authRetried &&
+      StorageUtil.get<string>(REFRESH_TOKEN_KEY)
+    ) {
+      const authHeader = String(config.headers?.Authorization || '')
+      if (await refreshAccessToken(authHeader.replace(/^Bearer\s+/i, '') || null)) {
+        config._authRetried = true
+        return instance(config)
+      }
+    }
+
     if (error.response) {
       errorCode = error.response.status
 
@@ -561,6 +624,11 @@ instance.interceptors.response.use(
           break
         case HTTP_STATUS.FORBIDDEN:
           message = await getTranslation('constants.api.errors.forbidden')
+          if (error.response.data?.code === ErrorCodes.TWO_FACTOR_SETUP_REQUIRED && window.location.pathname !== '/settings') {
+            import('@/router').then((module) => {
+              module.default.push({ path: '/settings', hash: '#security' })
+            })
+          }
           break
         case HTTP_STATUS.NOT_FOUND:
           message = await getTranslation('constants.api.errors.notFound')